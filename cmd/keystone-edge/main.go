@@ -114,7 +114,11 @@ func main() {
 
 	// Initialize cloud sync worker
 	var syncWorker *services.SyncWorker
-	if cfg.Sync.Enabled && cfg.Sync.DPConfigPath != "" && s3Client != nil {
+	syncTargetConfigured := cfg.Sync.DPConfigPath != ""
+	if cfg.Sync.Destination == config.SyncDestinationS3 {
+		syncTargetConfigured = cfg.Sync.S3.Target != ""
+	}
+	if cfg.Sync.Enabled && syncTargetConfigured && s3Client != nil {
 		syncWorker = services.NewSyncWorker(db.DB, nil, s3Client, cfg.Storage.Bucket, services.SyncWorkerConfig{
 			BatchSize:       cfg.Sync.BatchSize,
 			MaxConcurrent:   cfg.Sync.MaxConcurrent,
@@ -127,9 +131,13 @@ func main() {
 		}, &cfg.Sync)
 
 		syncWorker.Start()
-		logger.Printf("[SYNC] Cloud sync worker started: dp_config=%s auto_scan=%t", cfg.Sync.DPConfigPath, cfg.Sync.AutoScanEnabled)
+		if cfg.Sync.Destination == config.SyncDestinationS3 {
+			logger.Printf("[SYNC] Cloud sync worker started: destination=s3 target=%s auto_scan=%t", cfg.Sync.S3.Target, cfg.Sync.AutoScanEnabled)
+		} else {
+			logger.Printf("[SYNC] Cloud sync worker started: dp_config=%s auto_scan=%t", cfg.Sync.DPConfigPath, cfg.Sync.AutoScanEnabled)
+		}
 	} else {
-		logger.Println("[SYNC] Cloud sync disabled (KEYSTONE_SYNC_ENABLED=false, missing KEYSTONE_SYNC_DP_CONFIG, or S3 unavailable)")
+		logger.Println("[SYNC] Cloud sync disabled (KEYSTONE_SYNC_ENABLED=false, missing KEYSTONE_SYNC_DP_CONFIG / KEYSTONE_SYNC_S3_TARGET, or S3 unavailable)")
 	}
 
 	// Initialize and start HTTP server
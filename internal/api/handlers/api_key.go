@@ -0,0 +1,657 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	apiKeyVersion = "kak_v1"
+	// apiKeyPrefixLength is how much of a key is stored in clear to let
+	// admins tell keys apart; it covers the version and 4 random characters.
+	apiKeyPrefixLength  = len(apiKeyVersion) + 5
+	maxAPIKeyNameLength = 100
+	maxAPIKeyAllowedIPs = 50
+	// apiKeyLastUsedResolution throttles last_used_at writes for busy keys.
+	apiKeyLastUsedResolution = time.Minute
+)
+
+// API key statuses reported by the admin API.
+const (
+	APIKeyStatusActive  = "active"
+	APIKeyStatusExpired = "expired"
+	APIKeyStatusRevoked = "revoked"
+)
+
+var errAPIKeyNotFound = errors.New("api key not found")
+
+// APIKeyHandler manages API keys for service integrations and validates them
+// for JWTAuth.
+type APIKeyHandler struct {
+	db *sqlx.DB
+	// permissions resolves the caller's own grants, which bound the scopes
+	// they may put on a key.
+	permissions middleware.PermissionResolver
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler. A nil permissions resolver
+// falls back to the built-in role defaults.
+func NewAPIKeyHandler(db *sqlx.DB, permissions middleware.PermissionResolver) *APIKeyHandler {
+	return &APIKeyHandler{db: db, permissions: permissions}
+}
+
+// APIKeyResponse represents an API key. Key is only set on create and rotate.
+type APIKeyResponse struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Key           string   `json:"key,omitempty"`
+	KeyPrefix     string   `json:"key_prefix"`
+	Scopes        []string `json:"scopes"`
+	AllowedIPs    []string `json:"allowed_ips"`
+	Status        string   `json:"status"`
+	ExpiresAt     string   `json:"expires_at,omitempty"`
+	CreatedBy     string   `json:"created_by,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	LastRotatedAt string   `json:"last_rotated_at,omitempty"`
+	LastUsedAt    string   `json:"last_used_at,omitempty"`
+	LastUsedIP    string   `json:"last_used_ip,omitempty"`
+	RevokedAt     string   `json:"revoked_at,omitempty"`
+}
+
+// APIKeyListResponse represents the response for listing API keys.
+type APIKeyListResponse struct {
+	Items   []APIKeyResponse `json:"items"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasNext bool             `json:"hasNext,omitempty"`
+	HasPrev bool             `json:"hasPrev,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request body for creating an API key.
+type CreateAPIKeyRequest struct {
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
+}
+
+// RegisterRoutes registers API key admin routes.
+func (h *APIKeyHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/admin/api-keys", h.ListAPIKeys)
+	apiV1.POST("/admin/api-keys", h.CreateAPIKey)
+	apiV1.GET("/admin/api-keys/:id", h.GetAPIKey)
+	apiV1.POST("/admin/api-keys/:id/rotate", h.RotateAPIKey)
+	apiV1.DELETE("/admin/api-keys/:id", h.RevokeAPIKey)
+}
+
+func generateAPIKey() (string, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+	return apiKeyVersion + "_" + base64.RawURLEncoding.EncodeToString(randomBytes), nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeAPIKeyAllowedIPs validates IP addresses and CIDR ranges, returning
+// them in canonical form.
+func normalizeAPIKeyAllowedIPs(entries []string) ([]string, error) {
+	if len(entries) > maxAPIKeyAllowedIPs {
+		return nil, fmt.Errorf("allowed_ips must have at most %d entries", maxAPIKeyAllowedIPs)
+	}
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			out = append(out, network.String())
+			continue
+		}
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid allowed ip %q", entry)
+		}
+		out = append(out, ip.String())
+	}
+	return out, nil
+}
+
+// apiKeyIPAllowed reports whether clientIP matches the allowlist; an empty
+// allowlist allows every address.
+func apiKeyIPAllowed(allowed []string, clientIP string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	ip := net.ParseIP(strings.TrimSpace(clientIP))
+	if ip == nil {
+		return false
+	}
+	for _, entry := range allowed {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSONStrings decodes a JSON string array column; NULL and empty values
+// decode to an empty slice.
+func decodeJSONStrings(raw sql.NullString) ([]string, error) {
+	out := []string{}
+	if !raw.Valid || strings.TrimSpace(raw.String) == "" {
+		return out, nil
+	}
+	if err := json.Unmarshal([]byte(raw.String), &out); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []string{}
+	}
+	return out, nil
+}
+
+// ValidateAPIKey implements middleware.APIKeyValidator. Unknown, revoked and
+// expired keys yield middleware.ErrAPIKeyInvalid; keys used outside their IP
+// allowlist yield middleware.ErrAPIKeyIPNotAllowed.
+func (h *APIKeyHandler) ValidateAPIKey(ctx context.Context, key, clientIP string) (*auth.Claims, error) {
+	if !strings.HasPrefix(key, apiKeyVersion+"_") {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+	var row struct {
+		ID         int64          `db:"id"`
+		Scopes     sql.NullString `db:"scopes"`
+		AllowedIPs sql.NullString `db:"allowed_ips"`
+		ExpiresAt  sql.NullTime   `db:"expires_at"`
+		LastUsedAt sql.NullTime   `db:"last_used_at"`
+	}
+	err := h.db.GetContext(ctx, &row, `
+		SELECT id, scopes, allowed_ips, expires_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL
+		LIMIT 1
+	`, hashAPIKey(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query api key: %w", err)
+	}
+	now := time.Now().UTC()
+	if row.ExpiresAt.Valid && !now.Before(row.ExpiresAt.Time) {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+	allowed, err := decodeJSONStrings(row.AllowedIPs)
+	if err != nil {
+		return nil, fmt.Errorf("decode allowed ips of api key %d: %w", row.ID, err)
+	}
+	if !apiKeyIPAllowed(allowed, clientIP) {
+		return nil, middleware.ErrAPIKeyIPNotAllowed
+	}
+	scopes, err := decodeJSONStrings(row.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("decode scopes of api key %d: %w", row.ID, err)
+	}
+
+	if !row.LastUsedAt.Valid || now.Sub(row.LastUsedAt.Time) >= apiKeyLastUsedResolution {
+		// #nosec G701 -- static SQL with placeholder-bound api key usage values.
+		if _, err := h.db.ExecContext(ctx, `
+			UPDATE api_keys
+			SET last_used_at = ?, last_used_ip = ?
+			WHERE id = ?
+		`, now, clientIP, row.ID); err != nil {
+			logger.Printf("[API_KEY] Failed to record use of api key %d: %v", row.ID, err)
+		}
+	}
+	return auth.NewAPIKeyClaims(row.ID, scopes), nil
+}
+
+type apiKeyRow struct {
+	ID            int64          `db:"id"`
+	Name          string         `db:"name"`
+	KeyPrefix     string         `db:"key_prefix"`
+	Scopes        sql.NullString `db:"scopes"`
+	AllowedIPs    sql.NullString `db:"allowed_ips"`
+	ExpiresAt     sql.NullTime   `db:"expires_at"`
+	CreatedBy     sql.NullString `db:"created_by"`
+	CreatedAt     sql.NullTime   `db:"created_at"`
+	LastRotatedAt sql.NullTime   `db:"last_rotated_at"`
+	LastUsedAt    sql.NullTime   `db:"last_used_at"`
+	LastUsedIP    sql.NullString `db:"last_used_ip"`
+	RevokedAt     sql.NullTime   `db:"revoked_at"`
+}
+
+const apiKeyColumns = `id, name, key_prefix, scopes, allowed_ips, expires_at, created_by, created_at,
+	last_rotated_at, last_used_at, last_used_ip, revoked_at`
+
+func formatOptionalTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
+
+func apiKeyResponseFromRow(row apiKeyRow, now time.Time) APIKeyResponse {
+	scopes, err := decodeJSONStrings(row.Scopes)
+	if err != nil {
+		logger.Printf("[API_KEY] Invalid scopes for api key %d: %v", row.ID, err)
+		scopes = []string{}
+	}
+	allowedIPs, err := decodeJSONStrings(row.AllowedIPs)
+	if err != nil {
+		logger.Printf("[API_KEY] Invalid allowed ips for api key %d: %v", row.ID, err)
+		allowedIPs = []string{}
+	}
+	status := APIKeyStatusActive
+	switch {
+	case row.RevokedAt.Valid:
+		status = APIKeyStatusRevoked
+	case row.ExpiresAt.Valid && !now.Before(row.ExpiresAt.Time):
+		status = APIKeyStatusExpired
+	}
+	return APIKeyResponse{
+		ID:            fmt.Sprintf("%d", row.ID),
+		Name:          row.Name,
+		KeyPrefix:     row.KeyPrefix,
+		Scopes:        scopes,
+		AllowedIPs:    allowedIPs,
+		Status:        status,
+		ExpiresAt:     formatOptionalTime(row.ExpiresAt),
+		CreatedBy:     row.CreatedBy.String,
+		CreatedAt:     formatOptionalTime(row.CreatedAt),
+		LastRotatedAt: formatOptionalTime(row.LastRotatedAt),
+		LastUsedAt:    formatOptionalTime(row.LastUsedAt),
+		LastUsedIP:    row.LastUsedIP.String,
+		RevokedAt:     formatOptionalTime(row.RevokedAt),
+	}
+}
+
+func (h *APIKeyHandler) getAPIKey(ctx context.Context, id int64) (apiKeyRow, error) {
+	var row apiKeyRow
+	err := h.db.GetContext(ctx, &row, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = ?", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apiKeyRow{}, errAPIKeyNotFound
+	}
+	return row, err
+}
+
+func parseAPIKeyID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api key id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ListAPIKeys lists API keys.
+//
+// @Summary      List API keys
+// @Description  Lists API keys without their secret values; revoked keys are omitted unless include_revoked=true
+// @Tags         api-keys
+// @Produce      json
+// @Param        include_revoked query bool false "Include revoked keys"
+// @Param        limit           query int  false "Max results (default 50, max 100)"
+// @Param        offset          query int  false "Pagination offset (default 0)"
+// @Success      200 {object} APIKeyListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+	where := "WHERE revoked_at IS NULL"
+	if raw := strings.TrimSpace(c.Query("include_revoked")); raw != "" {
+		includeRevoked, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "include_revoked must be a boolean"})
+			return
+		}
+		if includeRevoked {
+			where = ""
+		}
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(*) FROM api_keys "+where); err != nil {
+		logger.Printf("[API_KEY] Failed to count api keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list api keys"})
+		return
+	}
+	var rows []apiKeyRow
+	// #nosec G202 -- where is one of two static clauses.
+	if err := h.db.Select(&rows, "SELECT "+apiKeyColumns+" FROM api_keys "+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		pagination.Limit, pagination.Offset); err != nil {
+		logger.Printf("[API_KEY] Failed to query api keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list api keys"})
+		return
+	}
+	now := time.Now().UTC()
+	items := make([]APIKeyResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, apiKeyResponseFromRow(row, now))
+	}
+	c.JSON(http.StatusOK, APIKeyListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// GetAPIKey gets an API key.
+//
+// @Summary      Get API key
+// @Description  Gets an API key without its secret value
+// @Tags         api-keys
+// @Produce      json
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  APIKeyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/api-keys/{id} [get]
+func (h *APIKeyHandler) GetAPIKey(c *gin.Context) {
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+	row, err := h.getAPIKey(c.Request.Context(), id)
+	if errors.Is(err, errAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to query api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get api key"})
+		return
+	}
+	c.JSON(http.StatusOK, apiKeyResponseFromRow(row, time.Now().UTC()))
+}
+
+// CreateAPIKey mints an API key. The key is returned once and only its hash
+// is stored.
+//
+// @Summary      Create API key
+// @Description  Creates an API key with permission scopes, an optional expiry and an optional IP allowlist; the key is only returned in this response. Callers can only grant scopes they hold globally.
+// @Tags         api-keys
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CreateAPIKeyRequest  true  "API key payload"
+// @Success      201   {object}  APIKeyResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name is required and must be at most %d characters", maxAPIKeyNameLength)})
+		return
+	}
+	scopes, err := normalizePermissionNames(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(scopes) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one scope is required"})
+		return
+	}
+	if !h.requireHeldScopes(c, scopes) {
+		return
+	}
+	allowedIPs, err := normalizeAPIKeyAllowedIPs(req.AllowedIPs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now().UTC()
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil && strings.TrimSpace(*req.ExpiresAt) != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(*req.ExpiresAt))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC3339 timestamp"})
+			return
+		}
+		if !t.After(now) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+		expiresAt = sql.NullTime{Time: t.UTC(), Valid: true}
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to generate api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to encode scopes: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+	var allowedIPsJSON sql.NullString
+	if len(allowedIPs) > 0 {
+		raw, err := json.Marshal(allowedIPs)
+		if err != nil {
+			logger.Printf("[API_KEY] Failed to encode allowed ips: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+			return
+		}
+		allowedIPsJSON = sql.NullString{String: string(raw), Valid: true}
+	}
+	createdBy := requestTransitionActor(c).TriggeredByID
+
+	res, err := h.db.Exec(`
+		INSERT INTO api_keys (name, key_prefix, key_hash, key_version, scopes, allowed_ips, expires_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, name, key[:apiKeyPrefixLength], hashAPIKey(key), apiKeyVersion, string(scopesJSON), allowedIPsJSON, expiresAt, createdBy, now)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to insert api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to fetch inserted id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+	row, err := h.getAPIKey(c.Request.Context(), id)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to load created api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create api key"})
+		return
+	}
+	logger.Printf("[API_KEY] Created api key %d (%s) with scopes %v by %s", id, name, scopes, createdBy)
+	resp := apiKeyResponseFromRow(row, now)
+	resp.Key = key
+	c.JSON(http.StatusCreated, resp)
+}
+
+// RotateAPIKey replaces the secret of an API key, keeping its name, scopes,
+// expiry and allowlist. The previous secret stops working immediately.
+//
+// @Summary      Rotate API key
+// @Description  Issues a new secret for an API key and invalidates the old one; the new key is only returned in this response
+// @Tags         api-keys
+// @Produce      json
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  APIKeyResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+	// Rotation hands out a working secret, so it needs the key's scopes too.
+	current, err := h.getAPIKey(c.Request.Context(), id)
+	if errors.Is(err, errAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to query api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+	currentScopes, err := decodeJSONStrings(current.Scopes)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to decode scopes of api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+	if !h.requireHeldScopes(c, currentScopes) {
+		return
+	}
+	key, err := generateAPIKey()
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to generate api key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+	now := time.Now().UTC()
+	// #nosec G701 -- static SQL with placeholder-bound api key rotation values.
+	res, err := h.db.Exec(`
+		UPDATE api_keys
+		SET key_prefix = ?, key_hash = ?, key_version = ?, last_rotated_at = ?
+		WHERE id = ? AND revoked_at IS NULL
+	`, key[:apiKeyPrefixLength], hashAPIKey(key), apiKeyVersion, now, id)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to rotate api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+	row, err := h.getAPIKey(c.Request.Context(), id)
+	if errors.Is(err, errAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to load rotated api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to rotate api key"})
+		return
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "api key is revoked"})
+		return
+	}
+	logger.Printf("[API_KEY] Rotated api key %d by %s", id, requestTransitionActor(c).TriggeredByID)
+	resp := apiKeyResponseFromRow(row, now)
+	resp.Key = key
+	c.JSON(http.StatusOK, resp)
+}
+
+// requireHeldScopes rejects scopes the caller does not hold globally, so a
+// key can never carry more than its creator. "*" needs "*". It writes the
+// error response and returns false on rejection.
+func (h *APIKeyHandler) requireHeldScopes(c *gin.Context, scopes []string) bool {
+	claims := middleware.GetClaims(c)
+	if claims == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return false
+	}
+	grants, err := middleware.ResolvePermissionGrants(c.Request.Context(), h.permissions, claims)
+	if err != nil {
+		logger.Printf("[API_KEY] Failed to resolve permissions for role=%s operator=%s: %v", claims.Role, claims.OperatorID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve permissions"})
+		return false
+	}
+	held := make(map[string]bool, len(grants))
+	for _, g := range grants {
+		if g.ScopeType == "" || g.ScopeType == middleware.PermissionScopeGlobal {
+			held[g.Permission] = true
+		}
+	}
+	if held[middleware.PermissionAll] {
+		return true
+	}
+	for _, scope := range scopes {
+		if !held[scope] {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("cannot grant scope %q: caller does not hold it globally", scope)})
+			return false
+		}
+	}
+	return true
+}
+
+// RevokeAPIKey revokes an API key. Revoking an already revoked key succeeds.
+//
+// @Summary      Revoke API key
+// @Description  Revokes an API key; requests using it are rejected immediately
+// @Tags         api-keys
+// @Param        id   path      string  true  "API key ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, ok := parseAPIKeyID(c)
+	if !ok {
+		return
+	}
+	if _, err := h.getAPIKey(c.Request.Context(), id); err != nil {
+		if errors.Is(err, errAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "api key not found"})
+			return
+		}
+		logger.Printf("[API_KEY] Failed to query api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke api key"})
+		return
+	}
+	if _, err := h.db.Exec("UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", time.Now().UTC(), id); err != nil {
+		logger.Printf("[API_KEY] Failed to revoke api key %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke api key"})
+		return
+	}
+	logger.Printf("[API_KEY] Revoked api key %d by %s", id, requestTransitionActor(c).TriggeredByID)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,204 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupAPIKeyTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := db.Exec(`CREATE TABLE api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		key_prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		key_version TEXT NOT NULL DEFAULT 'kak_v1',
+		scopes TEXT NOT NULL,
+		allowed_ips TEXT NULL,
+		expires_at TIMESTAMP NULL,
+		created_by TEXT,
+		created_at TIMESTAMP,
+		last_rotated_at TIMESTAMP NULL,
+		last_used_at TIMESTAMP NULL,
+		last_used_ip TEXT,
+		revoked_at TIMESTAMP NULL
+	)`); err != nil {
+		t.Fatalf("create api_keys: %v", err)
+	}
+	return db
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupAPIKeyTestDB(t)
+	h := NewAPIKeyHandler(db, nil)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.ClaimsKey, auth.NewAdminClaims())
+		c.Next()
+	})
+	h.RegisterRoutes(router.Group("/api/v1"))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	ctx := context.Background()
+
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	for _, body := range []string{
+		`{"name":"","scopes":["order:write"]}`,
+		`{"name":"mes","scopes":[]}`,
+		`{"name":"mes","scopes":["order:fly"]}`,
+		`{"name":"mes","scopes":["order:write"],"allowed_ips":["not-an-ip"]}`,
+		`{"name":"mes","scopes":["order:write"],"expires_at":"` + past + `"}`,
+	} {
+		if rec := do(http.MethodPost, "/api/v1/admin/api-keys", body); rec.Code != http.StatusBadRequest {
+			t.Fatalf("create %s = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec := do(http.MethodPost, "/api/v1/admin/api-keys", `{"name":"mes","scopes":["order:write","sync:trigger"],"allowed_ips":["10.0.0.0/8","192.168.1.7"]}`)
+	var created APIKeyResponse
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &created) != nil {
+		t.Fatalf("create = %d %s", rec.Code, rec.Body.String())
+	}
+	if created.Key == "" || created.KeyPrefix != created.Key[:apiKeyPrefixLength] || created.Status != APIKeyStatusActive || len(created.AllowedIPs) != 2 {
+		t.Fatalf("created = %+v", created)
+	}
+	var stored string
+	if err := db.Get(&stored, "SELECT key_hash FROM api_keys WHERE id = ?", created.ID); err != nil || stored != hashAPIKey(created.Key) {
+		t.Fatalf("stored hash = %q, %v", stored, err)
+	}
+
+	claims, err := h.ValidateAPIKey(ctx, created.Key, "10.1.2.3")
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if claims.Role != auth.RoleAPIKey || claims.OperatorID != "api_key:"+created.ID || len(claims.Scopes) != 2 {
+		t.Fatalf("claims = %+v", claims)
+	}
+	if _, err := h.ValidateAPIKey(ctx, created.Key, "172.16.0.1"); !errors.Is(err, middleware.ErrAPIKeyIPNotAllowed) {
+		t.Fatalf("outside allowlist err = %v", err)
+	}
+	if _, err := h.ValidateAPIKey(ctx, "kak_v1_bogus", "10.1.2.3"); !errors.Is(err, middleware.ErrAPIKeyInvalid) {
+		t.Fatalf("unknown key err = %v", err)
+	}
+
+	var got APIKeyResponse
+	if rec := do(http.MethodGet, "/api/v1/admin/api-keys/"+created.ID, ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &got) != nil {
+		t.Fatalf("get = %d %s", rec.Code, rec.Body.String())
+	}
+	if got.Key != "" || got.LastUsedAt == "" || got.LastUsedIP != "10.1.2.3" {
+		t.Fatalf("get = %+v", got)
+	}
+
+	var rotated APIKeyResponse
+	if rec := do(http.MethodPost, "/api/v1/admin/api-keys/"+created.ID+"/rotate", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &rotated) != nil {
+		t.Fatalf("rotate = %d %s", rec.Code, rec.Body.String())
+	}
+	if rotated.Key == created.Key || rotated.LastRotatedAt == "" {
+		t.Fatalf("rotated = %+v", rotated)
+	}
+	if _, err := h.ValidateAPIKey(ctx, created.Key, "10.1.2.3"); !errors.Is(err, middleware.ErrAPIKeyInvalid) {
+		t.Fatalf("old key after rotate err = %v", err)
+	}
+	if _, err := h.ValidateAPIKey(ctx, rotated.Key, "10.1.2.3"); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+
+	if _, err := db.Exec("UPDATE api_keys SET expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute).UTC(), created.ID); err != nil {
+		t.Fatalf("expire key: %v", err)
+	}
+	if _, err := h.ValidateAPIKey(ctx, rotated.Key, "10.1.2.3"); !errors.Is(err, middleware.ErrAPIKeyInvalid) {
+		t.Fatalf("expired key err = %v", err)
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/api-keys/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("revoke = %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/api-keys/"+created.ID+"/rotate", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("rotate revoked = %d, want 409", rec.Code)
+	}
+	var list APIKeyListResponse
+	if rec := do(http.MethodGet, "/api/v1/admin/api-keys", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &list) != nil || list.Total != 0 {
+		t.Fatalf("list active = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/api-keys?include_revoked=true", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &list) != nil || list.Total != 1 || list.Items[0].Status != APIKeyStatusRevoked {
+		t.Fatalf("list all = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateAPIKeyOnlyGrantsHeldScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupAPIKeyTestDB(t)
+	h := NewAPIKeyHandler(db, middleware.StaticPermissionResolver{
+		"admin":      {middleware.PermissionAll},
+		"integrator": {middleware.PermissionAPIKeyManage, middleware.PermissionOrderWrite},
+	})
+	do := func(claims *auth.Claims, method, path, body string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.ClaimsKey, claims)
+			c.Next()
+		})
+		h.RegisterRoutes(router.Group("/api/v1"))
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	integrator := &auth.Claims{Role: "integrator", OperatorID: "op-5"}
+
+	for _, body := range []string{
+		`{"name":"root","scopes":["*"]}`,
+		`{"name":"rbac","scopes":["rbac:manage"]}`,
+		`{"name":"mixed","scopes":["order:write","sync:trigger"]}`,
+	} {
+		if rec := do(integrator, http.MethodPost, "/api/v1/admin/api-keys", body); rec.Code != http.StatusForbidden {
+			t.Fatalf("non-admin create %s = %d %s, want 403", body, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := do(integrator, http.MethodPost, "/api/v1/admin/api-keys", `{"name":"mes","scopes":["order:write"]}`); rec.Code != http.StatusCreated {
+		t.Fatalf("non-admin create held scope = %d %s", rec.Code, rec.Body.String())
+	}
+
+	// A key cannot mint a key broader than itself either.
+	scoped := &auth.Claims{Role: auth.RoleAPIKey, OperatorID: "api_key:9", Scopes: []string{middleware.PermissionAPIKeyManage}}
+	if rec := do(scoped, http.MethodPost, "/api/v1/admin/api-keys", `{"name":"mes","scopes":["order:write"]}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("api key create = %d, want 403", rec.Code)
+	}
+
+	rec := do(auth.NewAdminClaims(), http.MethodPost, "/api/v1/admin/api-keys", `{"name":"root","scopes":["*"]}`)
+	var root APIKeyResponse
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &root) != nil {
+		t.Fatalf("admin create = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(integrator, http.MethodPost, "/api/v1/admin/api-keys/"+root.ID+"/rotate", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin rotate of root key = %d, want 403", rec.Code)
+	}
+}
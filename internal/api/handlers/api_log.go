@@ -0,0 +1,400 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	defaultAPILogBatchSize     = 100
+	defaultAPILogFlushInterval = 2 * time.Second
+	defaultAPILogRetentionDays = 90
+	apiLogPurgeInterval        = time.Hour
+	// apiLogQueueBatches bounds the in-memory queue to this many batches;
+	// entries arriving when it is full are dropped rather than blocking requests.
+	apiLogQueueBatches = 20
+)
+
+// APILogHandler writes API requests to api_logs off the request path, purges
+// rows past retention and serves the audit log query API.
+type APILogHandler struct {
+	db            *sqlx.DB
+	batchSize     int
+	flushInterval time.Duration
+	retentionDays int
+
+	queue   chan middleware.APILogEntry
+	dropped atomic.Int64
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAPILogHandler creates an APILogHandler. Call Start to begin writing.
+func NewAPILogHandler(db *sqlx.DB, cfg *config.MonitoringConfig) *APILogHandler {
+	h := &APILogHandler{
+		db:            db,
+		batchSize:     defaultAPILogBatchSize,
+		flushInterval: defaultAPILogFlushInterval,
+		retentionDays: defaultAPILogRetentionDays,
+	}
+	if cfg != nil {
+		if cfg.APILogBatchSize > 0 {
+			h.batchSize = cfg.APILogBatchSize
+		}
+		if cfg.APILogFlushIntervalSec > 0 {
+			h.flushInterval = time.Duration(cfg.APILogFlushIntervalSec) * time.Second
+		}
+		if cfg.APILogRetentionDays > 0 {
+			h.retentionDays = cfg.APILogRetentionDays
+		}
+	}
+	h.queue = make(chan middleware.APILogEntry, h.batchSize*apiLogQueueBatches)
+	return h
+}
+
+// Record queues entry for writing. It never blocks: when the queue is full
+// the entry is dropped and counted.
+func (h *APILogHandler) Record(entry middleware.APILogEntry) {
+	select {
+	case h.queue <- entry:
+	default:
+		if h.dropped.Add(1)%1000 == 1 {
+			logger.Printf("[API-LOG] Queue full, dropping entries (dropped=%d)", h.dropped.Load())
+		}
+	}
+}
+
+// Start launches the writer and purge loops. It is a no-op when already running.
+func (h *APILogHandler) Start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+	go h.run(ctx, h.done)
+	logger.Printf("[API-LOG] Started (batch=%d, flush=%s, retention_days=%d)", h.batchSize, h.flushInterval, h.retentionDays)
+}
+
+// Stop ends the loops after flushing queued entries, or when ctx ends.
+func (h *APILogHandler) Stop(ctx context.Context) error {
+	h.mu.Lock()
+	cancel, done := h.cancel, h.done
+	h.cancel, h.done = nil, nil
+	h.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("api log writer stop: %w", ctx.Err())
+	}
+}
+
+func (h *APILogHandler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	flush := time.NewTicker(h.flushInterval)
+	defer flush.Stop()
+	purge := time.NewTicker(apiLogPurgeInterval)
+	defer purge.Stop()
+
+	batch := make([]middleware.APILogEntry, 0, h.batchSize)
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		h.writeBatch(context.WithoutCancel(ctx), batch)
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-h.queue:
+					batch = append(batch, entry)
+					if len(batch) >= h.batchSize {
+						write()
+					}
+				default:
+					write()
+					return
+				}
+			}
+		case entry := <-h.queue:
+			batch = append(batch, entry)
+			if len(batch) >= h.batchSize {
+				write()
+			}
+		case <-flush.C:
+			write()
+		case <-purge.C:
+			if n, err := h.purgeExpired(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+				logger.Printf("[API-LOG] Purge failed: %v", err)
+			} else if n > 0 {
+				logger.Printf("[API-LOG] Purged %d entries older than %d days", n, h.retentionDays)
+			}
+		}
+	}
+}
+
+const apiLogInsertColumns = "(request_id, client_request_id, method, path, status_code, response_time_ms, user_id, user_role, ip_address, user_agent, error_message, occurred_at)"
+
+// writeBatch inserts entries in one statement. If the batch is rejected it
+// falls back to row-by-row inserts so one bad entry does not lose the rest.
+func (h *APILogHandler) writeBatch(ctx context.Context, entries []middleware.APILogEntry) {
+	if h.db == nil || len(entries) == 0 {
+		return
+	}
+	placeholders := make([]string, 0, len(entries))
+	args := make([]any, 0, len(entries)*12)
+	for _, e := range entries {
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, apiLogEntryArgs(e)...)
+	}
+	// #nosec G202 -- placeholders are static; all values are bound.
+	query := "INSERT INTO api_logs " + apiLogInsertColumns + " VALUES " + strings.Join(placeholders, ", ")
+	if _, err := h.db.ExecContext(ctx, query, args...); err == nil {
+		return
+	} else if len(entries) == 1 {
+		logger.Printf("[API-LOG] Failed to write entry request_id=%s: %v", entries[0].RequestID, err)
+		return
+	}
+	for _, e := range entries {
+		// #nosec G701 -- static SQL with placeholder-bound api log values.
+		if _, err := h.db.ExecContext(ctx, "INSERT INTO api_logs "+apiLogInsertColumns+" VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", apiLogEntryArgs(e)...); err != nil {
+			logger.Printf("[API-LOG] Failed to write entry request_id=%s: %v", e.RequestID, err)
+		}
+	}
+}
+
+func apiLogEntryArgs(e middleware.APILogEntry) []any {
+	return []any{
+		e.RequestID,
+		sql.NullString{String: e.ClientRequestID, Valid: e.ClientRequestID != ""},
+		e.Method,
+		truncateAPILogValue(e.Path, 500),
+		e.StatusCode,
+		e.ResponseTimeMs,
+		sql.NullString{String: truncateAPILogValue(e.UserID, 100), Valid: e.UserID != ""},
+		sql.NullString{String: truncateAPILogValue(e.UserRole, 50), Valid: e.UserRole != ""},
+		sql.NullString{String: truncateAPILogValue(e.IPAddress, 50), Valid: e.IPAddress != ""},
+		sql.NullString{String: e.UserAgent, Valid: e.UserAgent != ""},
+		sql.NullString{String: e.ErrorMessage, Valid: e.ErrorMessage != ""},
+		e.OccurredAt,
+	}
+}
+
+func truncateAPILogValue(v string, n int) string {
+	if len(v) <= n {
+		return v
+	}
+	return v[:n]
+}
+
+// purgeExpired deletes entries older than the retention window.
+func (h *APILogHandler) purgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	if h.db == nil {
+		return 0, nil
+	}
+	cutoff := now.AddDate(0, 0, -h.retentionDays)
+	res, err := h.db.ExecContext(ctx, "DELETE FROM api_logs WHERE occurred_at < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("purge api logs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// APILogResponse is one audited API request.
+type APILogResponse struct {
+	ID              int64  `json:"id"`
+	RequestID       string `json:"request_id"`
+	ClientRequestID string `json:"client_request_id,omitempty"`
+	Method          string `json:"method"`
+	Path            string `json:"path"`
+	StatusCode      int    `json:"status_code"`
+	ResponseTimeMs  *int64 `json:"response_time_ms,omitempty"`
+	UserID          string `json:"user_id,omitempty"`
+	UserRole        string `json:"user_role,omitempty"`
+	IPAddress       string `json:"ip_address,omitempty"`
+	UserAgent       string `json:"user_agent,omitempty"`
+	ErrorMessage    string `json:"error_message,omitempty"`
+	OccurredAt      string `json:"occurred_at"`
+}
+
+// APILogListResponse is the paginated audit log.
+type APILogListResponse struct {
+	Items   []APILogResponse `json:"items"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+	HasNext bool             `json:"hasNext,omitempty"`
+	HasPrev bool             `json:"hasPrev,omitempty"`
+}
+
+type apiLogRow struct {
+	ID              int64          `db:"id"`
+	RequestID       string         `db:"request_id"`
+	ClientRequestID sql.NullString `db:"client_request_id"`
+	Method          string         `db:"method"`
+	Path            string         `db:"path"`
+	StatusCode      int            `db:"status_code"`
+	ResponseTimeMs  sql.NullInt64  `db:"response_time_ms"`
+	UserID          sql.NullString `db:"user_id"`
+	UserRole        sql.NullString `db:"user_role"`
+	IPAddress       sql.NullString `db:"ip_address"`
+	UserAgent       sql.NullString `db:"user_agent"`
+	ErrorMessage    sql.NullString `db:"error_message"`
+	OccurredAt      time.Time      `db:"occurred_at"`
+}
+
+// RegisterRoutes registers the audit log query API. apiV1 should be
+// restricted to admins.
+func (h *APILogHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/admin/api-logs", h.ListAPILogs)
+}
+
+// ListAPILogs lists audited API requests, newest first.
+//
+// @Summary      List API audit log
+// @Description  Lists API requests recorded in api_logs. path matches as a prefix.
+// @Tags         admin
+// @Produce      json
+// @Param        user_id           query string false "User (operator ID or role)"
+// @Param        path              query string false "Path prefix, e.g. /api/v1/batches"
+// @Param        method            query string false "HTTP method"
+// @Param        status            query int    false "HTTP status code"
+// @Param        request_id        query string false "Request ID"
+// @Param        client_request_id query string false "Caller-supplied X-Request-ID"
+// @Param        from              query string false "Occurred at or after (RFC3339)"
+// @Param        to                query string false "Occurred before (RFC3339)"
+// @Param        limit             query int    false "Max results (default 50, max 100)"
+// @Param        offset            query int    false "Pagination offset (default 0)"
+// @Success      200 {object} APILogListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/api-logs [get]
+func (h *APILogHandler) ListAPILogs(c *gin.Context) {
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	where := []string{"1 = 1"}
+	args := []any{}
+	if v := strings.TrimSpace(c.Query("user_id")); v != "" {
+		where = append(where, "user_id = ?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(c.Query("path")); v != "" {
+		where = append(where, "path LIKE ?")
+		args = append(args, v+"%")
+	}
+	if v := strings.TrimSpace(c.Query("method")); v != "" {
+		where = append(where, "method = ?")
+		args = append(args, strings.ToUpper(v))
+	}
+	if v := strings.TrimSpace(c.Query("status")); v != "" {
+		status, err := strconv.Atoi(v)
+		if err != nil || status < 100 || status > 599 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+			return
+		}
+		where = append(where, "status_code = ?")
+		args = append(args, status)
+	}
+	if v := strings.TrimSpace(c.Query("request_id")); v != "" {
+		where = append(where, "request_id = ?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(c.Query("client_request_id")); v != "" {
+		where = append(where, "client_request_id = ?")
+		args = append(args, v)
+	}
+	for _, bound := range []struct{ param, op string }{{"from", ">="}, {"to", "<"}} {
+		v := strings.TrimSpace(c.Query(bound.param))
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + bound.param + " (RFC3339)"})
+			return
+		}
+		where = append(where, "occurred_at "+bound.op+" ?")
+		args = append(args, t.UTC())
+	}
+	whereSQL := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	// #nosec G202 -- where clauses are static; filter values are bound.
+	if err := h.db.Get(&total, "SELECT COUNT(1) FROM api_logs"+whereSQL, args...); err != nil {
+		logger.Printf("[API-LOG] Failed to count api logs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list api logs"})
+		return
+	}
+	var rows []apiLogRow
+	// #nosec G202 -- where clauses are static; filter values are bound.
+	if err := h.db.Select(&rows, `
+		SELECT id, request_id, client_request_id, method, path, status_code, response_time_ms, user_id, user_role, ip_address, user_agent, error_message, occurred_at
+		FROM api_logs`+whereSQL+`
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[API-LOG] Failed to query api logs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list api logs"})
+		return
+	}
+
+	items := make([]APILogResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, APILogResponse{
+			ID:              row.ID,
+			RequestID:       row.RequestID,
+			ClientRequestID: row.ClientRequestID.String,
+			Method:          row.Method,
+			Path:            row.Path,
+			StatusCode:      row.StatusCode,
+			ResponseTimeMs:  nullableInt64(row.ResponseTimeMs),
+			UserID:          row.UserID.String,
+			UserRole:        row.UserRole.String,
+			IPAddress:       row.IPAddress.String,
+			UserAgent:       row.UserAgent.String,
+			ErrorMessage:    row.ErrorMessage.String,
+			OccurredAt:      row.OccurredAt.UTC().Format(time.RFC3339),
+		})
+	}
+	c.JSON(http.StatusOK, APILogListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupAPILogTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := db.Exec(`CREATE TABLE api_logs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		request_id TEXT NOT NULL UNIQUE,
+		client_request_id TEXT,
+		method TEXT NOT NULL,
+		path TEXT NOT NULL,
+		status_code INTEGER NOT NULL,
+		response_time_ms INTEGER,
+		user_id TEXT,
+		user_role TEXT,
+		ip_address TEXT,
+		user_agent TEXT,
+		error_message TEXT,
+		occurred_at TIMESTAMP
+	)`); err != nil {
+		t.Fatalf("create api_logs: %v", err)
+	}
+	return db
+}
+
+func TestAPILogWriterBatchesAndServesQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupAPILogTestDB(t)
+	h := NewAPILogHandler(db, &config.MonitoringConfig{APILogBatchSize: 2, APILogFlushIntervalSec: 60, APILogRetentionDays: 30})
+	h.Start()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	h.Record(middleware.APILogEntry{RequestID: "r1", ClientRequestID: "job-7", Method: "DELETE", Path: "/api/v1/batches/5", StatusCode: 204, UserID: "op-1", UserRole: "data_collector", OccurredAt: now.Add(-2 * time.Minute)})
+	h.Record(middleware.APILogEntry{RequestID: "r2", Method: "GET", Path: "/api/v1/batches", StatusCode: 200, UserID: "admin", UserRole: "admin", OccurredAt: now.Add(-time.Minute)})
+	// A rejected entry must not lose the other entry of its batch.
+	h.Record(middleware.APILogEntry{RequestID: "r1", Method: "GET", Path: "/api/v1/tasks", StatusCode: 200, OccurredAt: now})
+	h.Record(middleware.APILogEntry{RequestID: "r3", Method: "POST", Path: "/api/v1/orders", StatusCode: 400, OccurredAt: now.AddDate(0, 0, -31)})
+	if err := h.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	var count int
+	if err := db.Get(&count, "SELECT COUNT(1) FROM api_logs"); err != nil || count != 3 {
+		t.Fatalf("written = %d, %v; want 3", count, err)
+	}
+
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api/v1"))
+	list := func(query string) (int, APILogListResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-logs"+query, nil))
+		var resp APILogListResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+	if code, resp := list(""); code != http.StatusOK || resp.Total != 3 || resp.Items[0].RequestID != "r2" {
+		t.Fatalf("list all = %d %+v", code, resp)
+	}
+	if code, resp := list("?user_id=op-1&path=/api/v1/batches&method=delete"); code != http.StatusOK || resp.Total != 1 || resp.Items[0].StatusCode != 204 {
+		t.Fatalf("filtered = %d %+v", code, resp)
+	}
+	if code, resp := list("?client_request_id=job-7"); code != http.StatusOK || resp.Total != 1 || resp.Items[0].RequestID != "r1" || resp.Items[0].ClientRequestID != "job-7" {
+		t.Fatalf("client request id filter = %d %+v", code, resp)
+	}
+	if code, resp := list("?status=400"); code != http.StatusOK || resp.Total != 1 || resp.Items[0].RequestID != "r3" {
+		t.Fatalf("status filter = %d %+v", code, resp)
+	}
+	if code, _ := list("?status=abc"); code != http.StatusBadRequest {
+		t.Fatalf("bad status = %d, want 400", code)
+	}
+
+	purged, err := h.purgeExpired(context.Background(), now)
+	if err != nil || purged != 1 {
+		t.Fatalf("purged = %d, %v; want 1", purged, err)
+	}
+}
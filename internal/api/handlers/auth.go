@@ -5,6 +5,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"net/http"
 	"strconv"
@@ -161,7 +162,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	_, _ = h.db.Exec("UPDATE data_collectors SET last_login_at = NOW() WHERE id = ?", row.ID)
 
 	// Best-effort: sync workstation status on login.
-	h.syncWorkstationStatusOnLogin(row.ID)
+	h.syncWorkstationStatusOnLogin(row.ID, stateTransitionActor{TriggeredBy: transitionTriggerUser, TriggeredByID: row.OperatorID})
 
 	claims := auth.NewCollectorClaims(row.ID, row.OperatorID)
 	token, err := auth.GenerateToken(claims, h.cfg)
@@ -198,11 +199,10 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 						WHERE data_collector_id = ? AND is_current = TRUE AND deleted_at IS NULL
 					LIMIT 1
 				`, claims.CollectorID); err == nil {
-					if _, err := h.db.Exec(`
-						UPDATE workstations
-						SET status = 'offline', updated_at = NOW()
-						WHERE id = ? AND deleted_at IS NULL
-					`, wsID); err != nil {
+					actor := stateTransitionActor{TriggeredBy: transitionTriggerUser, TriggeredByID: claims.OperatorID}
+					if _, err := execStateTransition(c.Request.Context(), h.db, stateEntityWorkstation, "offline", actor, map[string]any{"reason": "logout"},
+						"status = 'offline', updated_at = NOW()", nil,
+						"id = ? AND deleted_at IS NULL", wsID); err != nil {
 						logger.Printf("[AUTH] Failed to update workstation status on logout (ws=%d): %v", wsID, err)
 					}
 				} else if err != sql.ErrNoRows {
@@ -216,7 +216,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 }
 
 // Me returns the current authenticated identity.
-// Requires JWTAuth middleware; works for both admin and data_collector roles.
+// Requires JWTAuth middleware; works for admin, data_collector and API key callers.
 func (h *AuthHandler) Me(c *gin.Context) {
 	claims := middleware.GetClaims(c)
 	if claims == nil {
@@ -236,6 +236,19 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
+	if claims.Role == auth.RoleAPIKey {
+		c.JSON(http.StatusOK, gin.H{
+			"collector_id":   nil,
+			"operator_id":    claims.OperatorID,
+			"name":           "API key",
+			"role":           auth.RoleAPIKey,
+			"scopes":         claims.Scopes,
+			"workstation_id": nil,
+			"robot_id":       nil,
+		})
+		return
+	}
+
 	// data_collector path
 	var row struct {
 		ID         int64  `db:"id"`
@@ -317,7 +330,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 }
 
 // MeStationBreak sets the collector's workstation status to break (unless the workstation is offline).
-// Requires RequireRole("data_collector") middleware.
+// Requires RequirePermission(..., task:collect) middleware.
 func (h *AuthHandler) MeStationBreak(c *gin.Context) {
 	claims := middleware.GetClaims(c)
 	if claims == nil {
@@ -357,11 +370,9 @@ func (h *AuthHandler) MeStationBreak(c *gin.Context) {
 		return
 	}
 	if cur != "break" {
-		if _, err := h.db.Exec(`
-			UPDATE workstations
-			SET status = 'break', updated_at = NOW()
-			WHERE id = ? AND deleted_at IS NULL
-		`, wsID); err != nil {
+		if _, err := execStateTransition(c.Request.Context(), h.db, stateEntityWorkstation, "break", requestTransitionActor(c), nil,
+			"status = 'break', updated_at = NOW()", nil,
+			"id = ? AND deleted_at IS NULL", wsID); err != nil {
 			logger.Printf("[AUTH] MeStationBreak: failed to update workstation %d: %v", wsID, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update workstation"})
 			return
@@ -376,7 +387,7 @@ func (h *AuthHandler) MeStationBreak(c *gin.Context) {
 
 // MeStationEndBreak sets the collector's workstation to active or inactive depending on whether
 // an active batch exists. Does not override offline (returns 409).
-// Requires RequireRole("data_collector") middleware.
+// Requires RequirePermission(..., task:collect) middleware.
 func (h *AuthHandler) MeStationEndBreak(c *gin.Context) {
 	claims := middleware.GetClaims(c)
 	if claims == nil {
@@ -432,11 +443,9 @@ func (h *AuthHandler) MeStationEndBreak(c *gin.Context) {
 	if hasActiveBatch {
 		newStatus = "active"
 	}
-	if _, err := h.db.Exec(`
-		UPDATE workstations
-		SET status = ?, updated_at = NOW()
-		WHERE id = ? AND deleted_at IS NULL
-	`, newStatus, wsID); err != nil {
+	if _, err := execStateTransition(c.Request.Context(), h.db, stateEntityWorkstation, newStatus, requestTransitionActor(c), nil,
+		"status = ?, updated_at = NOW()", []any{newStatus},
+		"id = ? AND deleted_at IS NULL", wsID); err != nil {
 		logger.Printf("[AUTH] MeStationEndBreak: failed to update workstation %d: %v", wsID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update workstation"})
 		return
@@ -450,7 +459,7 @@ func (h *AuthHandler) MeStationEndBreak(c *gin.Context) {
 
 // syncWorkstationStatusOnLogin is a best-effort helper that syncs workstation
 // status to active/inactive based on whether an active batch exists.
-func (h *AuthHandler) syncWorkstationStatusOnLogin(collectorID int64) {
+func (h *AuthHandler) syncWorkstationStatusOnLogin(collectorID int64, actor stateTransitionActor) {
 	var wsID int64
 	if err := h.db.Get(&wsID, `
 		SELECT id
@@ -478,11 +487,9 @@ func (h *AuthHandler) syncWorkstationStatusOnLogin(collectorID int64) {
 	if hasActiveBatch {
 		newStatus = "active"
 	}
-	if _, err := h.db.Exec(`
-		UPDATE workstations
-		SET status = ?, updated_at = NOW()
-		WHERE id = ? AND deleted_at IS NULL
-	`, newStatus, wsID); err != nil {
+	if _, err := execStateTransition(context.Background(), h.db, stateEntityWorkstation, newStatus, actor, map[string]any{"reason": "login"},
+		"status = ?, updated_at = NOW()", []any{newStatus},
+		"id = ? AND deleted_at IS NULL", wsID); err != nil {
 		logger.Printf("[AUTH] Failed to update workstation status on login (ws=%d): %v", wsID, err)
 	}
 }
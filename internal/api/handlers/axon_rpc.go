@@ -248,7 +248,7 @@ func (h *RecorderHandler) Config(c *gin.Context) {
 		return
 	}
 
-	advanceTaskPendingToReady(h.db, c.Param("device_id"), taskID, "config")
+	advanceTaskPendingToReady(h.db, requestTransitionActor(c), c.Param("device_id"), taskID, "config")
 }
 
 func (h *RecorderHandler) overrideTaskConfigCallbackURLs(params map[string]interface{}) {
@@ -333,7 +333,7 @@ func (h *RecorderHandler) Begin(c *gin.Context) {
 	// pending is allowed because recorder may preserve ready state across a transient
 	// WebSocket disconnect while Keystone has already rolled the task back.
 	if taskID != "" && h.db != nil {
-		rowsAffected, _, err := advanceTaskPendingOrReadyToInProgress(h.db, taskID)
+		rowsAffected, _, err := advanceTaskPendingOrReadyToInProgress(h.db, requestTransitionActor(c), taskID)
 		if err != nil {
 			logger.Printf("%s failed to advance task pending/ready->in_progress after begin: err=%v", recorderTaskLogPrefix(c.Param("device_id"), taskID), err)
 			return
@@ -513,19 +513,13 @@ func (h *RecorderHandler) Cancel(c *gin.Context) {
 	if taskID != "" && h.db != nil {
 		deviceID := c.Param("device_id")
 		now := time.Now().UTC()
-		res, err := h.db.Exec(
-			`UPDATE tasks
-			 SET
-			   status = 'pending',
-			   updated_at = ?
-			 WHERE task_id = ? AND status IN ('ready', 'in_progress') AND deleted_at IS NULL`,
-			now, taskID,
-		)
+		n, err := execStateTransition(c.Request.Context(), h.db, stateEntityTask, "pending", requestTransitionActor(c), map[string]any{"reason": "recorder_cancel", "device_id": deviceID},
+			"status = 'pending', updated_at = ?", []any{now},
+			"task_id = ? AND status IN ('ready', 'in_progress') AND deleted_at IS NULL", taskID)
 		if err != nil {
 			logger.Printf("%s failed to revert task after cancel RPC: err=%v", recorderTaskLogPrefix(deviceID, taskID), err)
 			return
 		}
-		n, _ := res.RowsAffected()
 		if n == 0 {
 			logger.Printf("%s task revert skipped after cancel RPC (not found or not ready/in_progress)", recorderTaskLogPrefix(deviceID, taskID))
 		}
@@ -568,19 +562,14 @@ func (h *RecorderHandler) Clear(c *gin.Context) {
 	// Best-effort: failures should not change the RPC response.
 	if taskID != "" && h.db != nil {
 		now := time.Now().UTC()
-		res, err := h.db.Exec(
-			`UPDATE tasks
-			 SET
-			   status = 'pending',
-			   updated_at = ?
-			 WHERE task_id = ? AND status = 'ready' AND deleted_at IS NULL`,
-			now, taskID,
-		)
+		n, err := execStateTransition(c.Request.Context(), h.db, stateEntityTask, "pending", requestTransitionActor(c), map[string]any{"reason": "recorder_clear", "device_id": c.Param("device_id")},
+			"status = 'pending', updated_at = ?", []any{now},
+			"task_id = ? AND status = 'ready' AND deleted_at IS NULL", taskID)
 		if err != nil {
 			logger.Printf("%s failed to revert task ready->pending after clear: err=%v", recorderTaskLogPrefix(c.Param("device_id"), taskID), err)
 			return
 		}
-		if n, _ := res.RowsAffected(); n == 0 {
+		if n == 0 {
 			logger.Printf("%s task ready->pending skipped after clear (not found or not ready)", recorderTaskLogPrefix(c.Param("device_id"), taskID))
 		}
 	}
@@ -1091,7 +1080,7 @@ func (h *RecorderHandler) handleMessage(deviceID string, rc *services.RecorderCo
 		taskID := stringValue(data, "task_id")
 		// #nosec G706 -- Set aside for now
 		logger.Printf("%s config applied", recorderTaskLogPrefix(deviceID, taskID))
-		advanceTaskPendingToReady(h.db, deviceID, taskID, "config_applied")
+		advanceTaskPendingToReady(h.db, axonTransitionActor(deviceID), deviceID, taskID, "config_applied")
 	default:
 		// #nosec G706 -- Set aside for now
 		logger.Printf("%s unknown message type %q", recorderLogPrefix(deviceID), msgType)
@@ -1326,9 +1315,9 @@ func (h *RecorderHandler) reconcileRecorderTaskState(deviceID string, state serv
 	currentState := strings.ToLower(strings.TrimSpace(state.CurrentState))
 	switch currentState {
 	case "ready":
-		advanceTaskPendingToReady(h.db, deviceID, taskID, source+"_ready")
+		advanceTaskPendingToReady(h.db, axonTransitionActor(deviceID), deviceID, taskID, source+"_ready")
 	case "recording", "paused":
-		rowsAffected, previousStatus, err := advanceTaskPendingOrReadyToInProgress(h.db, taskID)
+		rowsAffected, previousStatus, err := advanceTaskPendingOrReadyToInProgress(h.db, axonTransitionActor(deviceID), taskID)
 		if err != nil {
 			logger.Printf("%s failed to advance task pending/ready->in_progress after %s %s: err=%v", recorderTaskLogPrefix(deviceID, taskID), source, currentState, err)
 			return
@@ -1339,51 +1328,42 @@ func (h *RecorderHandler) reconcileRecorderTaskState(deviceID string, state serv
 	}
 }
 
-func advanceTaskPendingToReady(db *sqlx.DB, deviceID, taskID, source string) {
+func advanceTaskPendingToReady(db *sqlx.DB, actor stateTransitionActor, deviceID, taskID, source string) {
 	taskID = strings.TrimSpace(taskID)
 	if db == nil || taskID == "" {
 		return
 	}
 	previousStatus, _, _ := currentTaskStatus(db, taskID)
 	now := time.Now().UTC()
-	res, err := db.Exec(
-		`UPDATE tasks
-		 SET
+	n, err := execStateTransition(context.Background(), db, stateEntityTask, "ready", actor, map[string]any{"reason": source}, `
 		   status = 'ready',
 		   ready_at = CASE WHEN ready_at IS NULL THEN ? ELSE ready_at END,
-		   updated_at = ?
-		 WHERE task_id = ? AND status = 'pending' AND deleted_at IS NULL`,
-		now, now, taskID,
-	)
+		   updated_at = ?`, []any{now, now},
+		"task_id = ? AND status = 'pending' AND deleted_at IS NULL", taskID)
 	if err != nil {
 		logger.Printf("%s failed to advance task pending->ready after %s: err=%v", recorderTaskLogPrefix(deviceID, taskID), source, err)
 		return
 	}
-	if n, _ := res.RowsAffected(); n > 0 {
+	if n > 0 {
 		logger.Printf("%s task status updated: %s -> ready reason=%s", recorderTaskLogPrefix(deviceID, taskID), taskStatusLogValue(previousStatus, "unknown"), source)
 	}
 }
 
-func advanceTaskPendingOrReadyToInProgress(db *sqlx.DB, taskID string) (int64, string, error) {
+func advanceTaskPendingOrReadyToInProgress(db *sqlx.DB, actor stateTransitionActor, taskID string) (int64, string, error) {
 	if db == nil {
 		return 0, "", nil
 	}
 	taskID = strings.TrimSpace(taskID)
 	previousStatus, _, _ := currentTaskStatus(db, taskID)
 	now := time.Now().UTC()
-	res, err := db.Exec(
-		`UPDATE tasks
-		 SET
+	rowsAffected, err := execStateTransition(context.Background(), db, stateEntityTask, "in_progress", actor, nil, `
 		   status = 'in_progress',
 		   started_at = CASE WHEN started_at IS NULL THEN ? ELSE started_at END,
-		   updated_at = ?
-		 WHERE task_id = ? AND status IN ('pending', 'ready') AND deleted_at IS NULL`,
-		now, now, taskID,
-	)
+		   updated_at = ?`, []any{now, now},
+		"task_id = ? AND status IN ('pending', 'ready') AND deleted_at IS NULL", taskID)
 	if err != nil {
 		return 0, previousStatus, err
 	}
-	rowsAffected, _ := res.RowsAffected()
 	return rowsAffected, previousStatus, nil
 }
 
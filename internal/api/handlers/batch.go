@@ -561,7 +561,10 @@ func (h *BatchHandler) DeleteBatch(c *gin.Context) {
 			return
 		}
 		if remCompleted > 0 && remNonCompleted == 0 {
-			if _, err := tx.Exec(`UPDATE batches SET status = 'completed', ended_at = COALESCE(ended_at, ?), updated_at = ? WHERE id = ? AND deleted_at IS NULL AND status IN ('pending', 'cancelled')`, now, now, id); err != nil {
+			if _, err := transitionStateRows(tx, stateEntityBatch, "completed", requestTransitionActor(c), map[string]any{"reason": "batch_delete_cleanup"},
+				"id = ? AND deleted_at IS NULL AND status IN ('pending', 'cancelled')", []any{id},
+				execAffected(tx, `UPDATE batches SET status = 'completed', ended_at = COALESCE(ended_at, ?), updated_at = ? WHERE id = ? AND deleted_at IS NULL AND status IN ('pending', 'cancelled')`, now, now, id),
+			); err != nil {
 				logger.Printf("[BATCH] Failed to advance batch to completed after cleanup: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete batch"})
 				return
@@ -673,9 +676,12 @@ func getBatchProgress(q sqlx.Queryer, batchID int64) (batchProgressCounts, error
 	return progress, err
 }
 
+// collectorClaims returns the caller's claims when they carry a collector
+// identity. The route already requires task:collect, so any role granted it
+// qualifies; accounts without a collector (admins, API keys) do not.
 func collectorClaims(c *gin.Context) *auth.Claims {
 	claims := middleware.GetClaims(c)
-	if claims == nil || claims.Role != "data_collector" {
+	if claims == nil || claims.CollectorID <= 0 {
 		return nil
 	}
 	return claims
@@ -785,9 +791,10 @@ func (h *BatchHandler) PatchBatch(c *gin.Context) {
 	}
 
 	// Update batch status (idempotent; only transitions to cancelled).
-	if _, err := tx.Exec(
-		"UPDATE batches SET status = 'cancelled', updated_at = ? WHERE id = ? AND deleted_at IS NULL",
-		now, id,
+	actor := requestTransitionActor(c)
+	if _, err := transitionStateRows(tx, stateEntityBatch, "cancelled", actor, nil,
+		"id = ? AND deleted_at IS NULL", []any{id},
+		execAffected(tx, "UPDATE batches SET status = 'cancelled', updated_at = ? WHERE id = ? AND deleted_at IS NULL", now, id),
 	); err != nil {
 		logger.Printf("[BATCH] Failed to patch batch: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch batch"})
@@ -797,12 +804,15 @@ func (h *BatchHandler) PatchBatch(c *gin.Context) {
 	// If cancelling a pending/active batch, also cancel its pending/ready/in_progress tasks.
 	// This prevents orphan runnable tasks under a cancelled batch.
 	if cur.Status == "pending" || cur.Status == "active" {
-		if _, err := tx.Exec(
-			`UPDATE tasks
-			 SET status = 'cancelled', updated_at = ?
-			 WHERE batch_id = ? AND deleted_at IS NULL
-			   AND status IN ('pending', 'ready', 'in_progress')`,
-			now, id,
+		if _, err := transitionStateRows(tx, stateEntityTask, "cancelled", actor, map[string]any{"reason": "batch_cancelled", "batch_id": id},
+			"batch_id = ? AND deleted_at IS NULL AND status IN ('pending', 'ready', 'in_progress')", []any{id},
+			execAffected(tx,
+				`UPDATE tasks
+				 SET status = 'cancelled', updated_at = ?
+				 WHERE batch_id = ? AND deleted_at IS NULL
+				   AND status IN ('pending', 'ready', 'in_progress')`,
+				now, id,
+			),
 		); err != nil {
 			logger.Printf("[BATCH] Failed to cascade cancel tasks for batch %d: %v", id, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch batch"})
@@ -816,7 +826,7 @@ func (h *BatchHandler) PatchBatch(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch batch"})
 		return
 	}
-	if err := syncWorkstationStatusFromBatchesTx(tx, patchWsID); err != nil {
+	if err := syncWorkstationStatusFromBatchesTx(tx, patchWsID, actor); err != nil {
 		logger.Printf("[BATCH] Failed to sync workstation status after patch batch %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to patch batch"})
 		return
@@ -870,7 +880,7 @@ func (h *BatchHandler) CompleteTasks(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
 			return
 		}
-		c.JSON(http.StatusForbidden, gin.H{"error": "data collector role required"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "collector identity required"})
 		return
 	}
 
@@ -1039,7 +1049,9 @@ func (h *BatchHandler) CompleteTasks(c *gin.Context) {
 		return
 	}
 
+	actor := requestTransitionActor(c)
 	completedTasks := make([]CompleteTasksTask, 0, quantity)
+	completedRows := make([]stateRow, 0, quantity)
 	completedAt := now.Format(time.RFC3339)
 	for _, task := range tasks {
 		res, err := tx.Exec(`
@@ -1071,6 +1083,12 @@ func (h *BatchHandler) CompleteTasks(c *gin.Context) {
 			Status:      "completed",
 			CompletedAt: completedAt,
 		})
+		completedRows = append(completedRows, stateRow{ID: task.ID, State: "pending"})
+	}
+	if err := recordStateTransitions(tx, stateEntityTask, completedRows, "completed", actor, map[string]any{"reason": "batch_complete_tasks", "batch_id": batch.ID}); err != nil {
+		logger.Printf("[BATCH] CompleteTasks: failed to record task transitions for batch %d: %v", batch.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete tasks"})
+		return
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -1079,9 +1097,9 @@ func (h *BatchHandler) CompleteTasks(c *gin.Context) {
 		return
 	}
 
-	tryAdvanceBatchStatus(h.db, batch.ID)
+	tryAdvanceBatchStatus(h.db, batch.ID, actor)
 	if batch.OrderID > 0 {
-		tryAdvanceOrderStatus(h.db, batch.OrderID, h.recorderHub, h.recorderRPCTimeout)
+		tryAdvanceOrderStatus(h.db, batch.OrderID, h.recorderHub, h.recorderRPCTimeout, actor)
 	}
 
 	var refreshed struct {
@@ -1906,7 +1924,7 @@ func (h *BatchHandler) AdjustBatchTasks(c *gin.Context) {
 
 	// If task deletions/inserts made the batch terminal, advance status accordingly.
 	// Example: target reduced from 2 -> 1 after 1 task completed; remaining tasks may all be terminal.
-	tryAdvanceBatchStatus(h.db, batchNumID)
+	tryAdvanceBatchStatus(h.db, batchNumID, requestTransitionActor(c))
 
 	c.JSON(http.StatusOK, AdjustBatchTasksResponse{
 		CreatedTasks:   createdTasks,
@@ -1986,6 +2004,12 @@ func (h *BatchHandler) RecallBatch(c *gin.Context) {
 		return
 	}
 
+	actor := requestTransitionActor(c)
+	if err := recordStateTransitions(tx, stateEntityBatch, []stateRow{{ID: id, State: cur.Status}}, "recalled", actor, nil); err != nil {
+		logger.Printf("[BATCH] Failed to record recall transition for batch %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recall batch"})
+		return
+	}
 	if _, err := tx.Exec(
 		"UPDATE batches SET status = 'recalled', updated_at = ? WHERE id = ? AND deleted_at IS NULL",
 		now, id,
@@ -1995,12 +2019,15 @@ func (h *BatchHandler) RecallBatch(c *gin.Context) {
 		return
 	}
 
-	if _, err := tx.Exec(
-		`UPDATE tasks
-		 SET status = 'cancelled', updated_at = ?
-		 WHERE batch_id = ? AND deleted_at IS NULL
-		   AND status IN ('pending', 'ready', 'in_progress')`,
-		now, id,
+	if _, err := transitionStateRows(tx, stateEntityTask, "cancelled", actor, map[string]any{"reason": "batch_recalled", "batch_id": id},
+		"batch_id = ? AND deleted_at IS NULL AND status IN ('pending', 'ready', 'in_progress')", []any{id},
+		execAffected(tx,
+			`UPDATE tasks
+			 SET status = 'cancelled', updated_at = ?
+			 WHERE batch_id = ? AND deleted_at IS NULL
+			   AND status IN ('pending', 'ready', 'in_progress')`,
+			now, id,
+		),
 	); err != nil {
 		logger.Printf("[BATCH] Failed to cascade cancel tasks for recall batch %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recall batch"})
@@ -2032,7 +2059,7 @@ func (h *BatchHandler) RecallBatch(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recall batch"})
 		return
 	}
-	if err := syncWorkstationStatusFromBatchesTx(tx, recallWsID); err != nil {
+	if err := syncWorkstationStatusFromBatchesTx(tx, recallWsID, actor); err != nil {
 		logger.Printf("[BATCH] Failed to sync workstation status after recall batch %d: %v", id, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to recall batch"})
 		return
@@ -2183,7 +2210,7 @@ func (h *BatchHandler) ListBatchTasks(c *gin.Context) {
 // for this workstation is active; otherwise inactive. If the workstation is offline (e.g. data
 // collector logged out) or on break (operator pause), status is left unchanged so those states win
 // over batch-driven updates.
-func syncWorkstationStatusFromBatchesTx(tx *sqlx.Tx, workstationID int64) error {
+func syncWorkstationStatusFromBatchesTx(tx *sqlx.Tx, workstationID int64, actor stateTransitionActor) error {
 	if workstationID <= 0 {
 		return nil
 	}
@@ -2217,18 +2244,20 @@ func syncWorkstationStatusFromBatchesTx(tx *sqlx.Tx, workstationID int64) error
 		return nil
 	}
 	now := time.Now().UTC()
-	_, err := tx.Exec(`
+	if _, err := tx.Exec(`
 		UPDATE workstations
 		SET status = ?, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
-	`, newStatus, now, workstationID)
-	return err
+	`, newStatus, now, workstationID); err != nil {
+		return err
+	}
+	return recordStateTransitions(tx, stateEntityWorkstation, []stateRow{{ID: workstationID, State: curStatus}}, newStatus, actor, map[string]any{"reason": "batch_status_sync"})
 }
 
 // finalizeOpenBatchesAfterOrderCompletedTx runs inside the same transaction as the order -> completed transition.
 // It cancels runnable tasks on open batches, marks those batches completed, and re-syncs affected workstations.
 // Before mutating tasks, it returns rows for notifyRecorderCancelTasksWithHub (ready -> clear, in_progress -> cancel).
-func finalizeOpenBatchesAfterOrderCompletedTx(tx *sqlx.Tx, orderID int64, now time.Time) ([]orderCompletionRecorderNotify, error) {
+func finalizeOpenBatchesAfterOrderCompletedTx(tx *sqlx.Tx, orderID int64, now time.Time, actor stateTransitionActor) ([]orderCompletionRecorderNotify, error) {
 	var wsIDs []int64
 	if err := tx.Select(&wsIDs, `
 		SELECT DISTINCT workstation_id
@@ -2272,33 +2301,38 @@ func finalizeOpenBatchesAfterOrderCompletedTx(tx *sqlx.Tx, orderID int64, now ti
 		recorderNotifies = append(recorderNotifies, orderCompletionRecorderNotify{BatchID: bid, Rows: rows})
 	}
 
-	if _, err := tx.Exec(`
-		UPDATE tasks
-		SET status = 'cancelled', updated_at = ?
-		WHERE order_id = ?
+	metadata := map[string]any{"reason": "order_completed", "order_id": orderID}
+	openTasks := `order_id = ?
 		  AND deleted_at IS NULL
 		  AND status IN ('pending', 'ready', 'in_progress')
 		  AND batch_id IN (
 		    SELECT id FROM batches
 		    WHERE order_id = ? AND deleted_at IS NULL AND status IN ('pending', 'active')
-		  )
-	`, now, orderID, orderID); err != nil {
+		  )`
+	if _, err := transitionStateRows(tx, stateEntityTask, "cancelled", actor, metadata, openTasks, []any{orderID, orderID},
+		execAffected(tx, `
+			UPDATE tasks
+			SET status = 'cancelled', updated_at = ?
+			WHERE `+openTasks, now, orderID, orderID),
+	); err != nil {
 		return nil, err
 	}
 
-	if _, err := tx.Exec(`
-		UPDATE batches
-		SET status = 'completed',
-		    started_at = COALESCE(started_at, ?),
-		    ended_at = COALESCE(ended_at, ?),
-		    updated_at = ?
-		WHERE order_id = ? AND deleted_at IS NULL AND status IN ('pending', 'active')
-	`, now, now, now, orderID); err != nil {
+	openBatches := "order_id = ? AND deleted_at IS NULL AND status IN ('pending', 'active')"
+	if _, err := transitionStateRows(tx, stateEntityBatch, "completed", actor, metadata, openBatches, []any{orderID},
+		execAffected(tx, `
+			UPDATE batches
+			SET status = 'completed',
+			    started_at = COALESCE(started_at, ?),
+			    ended_at = COALESCE(ended_at, ?),
+			    updated_at = ?
+			WHERE `+openBatches, now, now, now, orderID),
+	); err != nil {
 		return nil, err
 	}
 
 	for _, wsID := range wsIDs {
-		if err := syncWorkstationStatusFromBatchesTx(tx, wsID); err != nil {
+		if err := syncWorkstationStatusFromBatchesTx(tx, wsID, actor); err != nil {
 			return nil, err
 		}
 	}
@@ -2312,7 +2346,7 @@ func finalizeOpenBatchesAfterOrderCompletedTx(tx *sqlx.Tx, orderID int64, now ti
 // Task cancellation to reach an all-cancelled set is done via PATCH batch (cancel), which sets
 // the batch to cancelled already; this helper does not advance batch to cancelled.
 // This function uses its own transaction and is safe to call after the task update commits.
-func tryAdvanceBatchStatus(db *sqlx.DB, batchID int64) {
+func tryAdvanceBatchStatus(db *sqlx.DB, batchID int64, actor stateTransitionActor) {
 	tx, err := db.Beginx()
 	if err != nil {
 		logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to begin tx for batch %d: %v", batchID, err)
@@ -2360,6 +2394,10 @@ func tryAdvanceBatchStatus(db *sqlx.DB, batchID int64) {
 			logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to advance batch %d to active: %v", batchID, err)
 			return
 		}
+		if err := recordStateTransitions(tx, stateEntityBatch, []stateRow{{ID: batchID, State: "pending"}}, "active", actor, map[string]any{"reason": "task_terminal"}); err != nil {
+			logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to record batch %d transition: %v", batchID, err)
+			return
+		}
 		logger.Printf("[BATCH] Batch %d advanced: pending -> active", batchID)
 		info.Status = "active"
 		fallthrough
@@ -2391,6 +2429,10 @@ func tryAdvanceBatchStatus(db *sqlx.DB, batchID int64) {
 				logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to advance batch %d to completed: %v", batchID, err)
 				return
 			}
+			if err := recordStateTransitions(tx, stateEntityBatch, []stateRow{{ID: batchID, State: "active"}}, "completed", actor, map[string]any{"reason": "all_tasks_terminal"}); err != nil {
+				logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to record batch %d transition: %v", batchID, err)
+				return
+			}
 			logger.Printf("[BATCH] Batch %d advanced: active -> completed (all %d tasks in terminal state)", batchID, totalCount)
 		}
 
@@ -2406,7 +2448,7 @@ func tryAdvanceBatchStatus(db *sqlx.DB, batchID int64) {
 		}
 		return
 	}
-	if err := syncWorkstationStatusFromBatchesTx(tx, wsID); err != nil {
+	if err := syncWorkstationStatusFromBatchesTx(tx, wsID, actor); err != nil {
 		logger.Printf("[BATCH] tryAdvanceBatchStatus: failed to sync workstation status for batch %d: %v", batchID, err)
 		return
 	}
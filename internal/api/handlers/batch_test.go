@@ -771,6 +771,28 @@ func TestBatchHandlerCompleteTasks_CompletesSelectedGroupPendingTasks(t *testing
 	}
 }
 
+func TestBatchHandlerCompleteTasks_AllowsAnyRoleWithCollectorIdentity(t *testing.T) {
+	db := newTestBatchHandlerDB(t)
+	defer db.Close()
+	seedBatchCompleteNextFixtures(t, db)
+
+	body := `{"quantity":1,"sop_id":40,"subscene_id":50}`
+	admin := newTestCollectorBatchRouter(t, db, auth.NewAdminClaims())
+	w := httptest.NewRecorder()
+	admin.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/batches/1/complete-tasks", bytes.NewBufferString(body)))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("admin status=%d want=%d body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+
+	// The route grants task:collect; the role name does not matter.
+	r := newTestCollectorBatchRouter(t, db, &auth.Claims{CollectorID: 100, OperatorID: "op-100", Role: "shift_lead"})
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/batches/1/complete-tasks", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status=%d want=%d body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
 func TestBatchHandlerCompleteTasks_RejectsOtherCollectorWorkstation(t *testing.T) {
 	db := newTestBatchHandlerDB(t)
 	defer db.Close()
@@ -1132,6 +1154,7 @@ func newTestBatchHandlerDB(t *testing.T) *sqlx.DB {
 		}
 	}
 
+	createStateTransitionsTestTable(t, db)
 	return db
 }
 
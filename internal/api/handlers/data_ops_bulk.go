@@ -641,9 +641,8 @@ func (h *DataOpsHandler) runBulkEpisodeQA(runID string, ids []int64) {
 		go func() {
 			defer wg.Done()
 			for episodeID := range jobs {
-				ctx, cancel := context.WithTimeout(context.Background(), defaultEpisodeQATimeout)
-				result, err := runner.RunEpisodeQASuite(ctx, episodeID, qaRunModeManual)
-				cancel()
+				// RunEpisodeQASuite bounds each episode by the configured QA timeout.
+				result, err := runner.RunEpisodeQASuite(context.Background(), episodeID, qaRunModeManual)
 				if err != nil {
 					if isBulkQASkippedError(err) {
 						results <- dataOpsBulkQAEpisodeResult{episodeID: episodeID, outcome: dataOpsBulkQAEpisodeSkipped}
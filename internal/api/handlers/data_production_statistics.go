@@ -574,19 +574,18 @@ func (h *DataProductionStatisticsHandler) writeBreakdown(c *gin.Context, q dataP
 	})
 }
 
+// scopeDataProductionStatsQueryToCurrentCollector limits q to the caller's
+// own collections. The route already requires statistics:read_own, so any
+// role granted it qualifies as long as the caller has a collector identity.
 func scopeDataProductionStatsQueryToCurrentCollector(c *gin.Context, q *dataProductionStatsQuery) bool {
 	claims := middleware.GetClaims(c)
 	if claims == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
 		return false
 	}
-	if claims.Role != "data_collector" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
-		return false
-	}
 
 	operatorID := strings.TrimSpace(claims.OperatorID)
-	if operatorID == "" {
+	if claims.CollectorID <= 0 || operatorID == "" {
 		c.JSON(http.StatusForbidden, gin.H{"error": "collector identity missing"})
 		return false
 	}
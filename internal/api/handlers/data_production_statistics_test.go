@@ -266,9 +266,11 @@ func TestScopeDataProductionStatsQueryToCurrentCollectorOverridesCollectorFilter
 	gin.SetMode(gin.TestMode)
 
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	// Any role granted statistics:read_own is scoped by its collector identity.
 	c.Set(middleware.ClaimsKey, &auth.Claims{
-		OperatorID: "dc-001",
-		Role:       "data_collector",
+		CollectorID: 1,
+		OperatorID:  "dc-001",
+		Role:        "shift_lead",
 	})
 	q := dataProductionStatsQuery{CollectorOperatorIDs: []string{"dc-999"}}
 
@@ -280,6 +282,19 @@ func TestScopeDataProductionStatsQueryToCurrentCollectorOverridesCollectorFilter
 	}
 }
 
+func TestScopeDataProductionStatsQueryToCurrentCollectorRequiresCollectorIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.ClaimsKey, auth.NewAdminClaims())
+	var q dataProductionStatsQuery
+
+	if scopeDataProductionStatsQueryToCurrentCollector(c, &q) || w.Code != http.StatusForbidden {
+		t.Fatalf("admin scope applied, status = %d", w.Code)
+	}
+}
+
 func mustParseStatsTimeForTest(t *testing.T, raw string) time.Time {
 	t.Helper()
 	value, err := parseStatsTime(raw)
@@ -43,38 +43,16 @@ func NewEpisodeHandler(db *sqlx.DB, s3Client *s3.Client, bucket string, authCfg
 	}
 }
 
-func (h *EpisodeHandler) requireBearerJWT(c *gin.Context) bool {
-	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
-	parts := strings.SplitN(authHeader, " ", 2)
-	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid authorization header"})
-		return false
-	}
-	if _, err := auth.ParseToken(parts[1], h.authCfg); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+// allowEpisodePresign limits dashboard display tokens to MCAP previews.
+// DashboardAuth authenticates the request before it reaches the handler.
+func allowEpisodePresign(c *gin.Context, kind string) bool {
+	if c.GetBool(middleware.DashboardDisplayKey) && kind != "mcap" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "display token can only presign mcap previews"})
 		return false
 	}
 	return true
 }
 
-func (h *EpisodeHandler) requireEpisodePresignAuth(c *gin.Context, kind string) bool {
-	if h.authCfg == nil {
-		return true
-	}
-	if middleware.IsDashboardDisplayRequest(c) {
-		if kind != "mcap" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "display token can only presign mcap previews"})
-			return false
-		}
-		if !middleware.IsDashboardDisplayToken(c, h.authCfg) {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid display token"})
-			return false
-		}
-		return true
-	}
-	return h.requireBearerJWT(c)
-}
-
 // episodeRow represents an episode row from the database.
 type episodeRow struct {
 	ID                 int64           `db:"id"`
@@ -137,6 +115,8 @@ type Episode struct {
 	CreatedAt          string   `json:"created_at"`
 	Labels             []string `json:"labels"`
 	Metadata           any      `json:"metadata,omitempty"`
+	// Annotations are only returned by GetEpisode.
+	Annotations []EpisodeAnnotation `json:"annotations,omitempty"`
 }
 
 // EpisodeListResponse represents the response for listing episodes
@@ -153,6 +133,11 @@ type EpisodeListResponse struct {
 func (h *EpisodeHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
 	apiV1.GET("", h.ListEpisodes)
 	apiV1.GET("/:id", h.GetEpisode)
+}
+
+// RegisterPresignRoutes registers the presign route. apiV1 should be guarded
+// by DashboardAuth so display tokens can fetch MCAP previews.
+func (h *EpisodeHandler) RegisterPresignRoutes(apiV1 *gin.RouterGroup) {
 	apiV1.GET("/:id/presign", h.GetEpisodePresignedURL)
 }
 
@@ -538,7 +523,7 @@ func (h *EpisodeHandler) GetEpisodePresignedURL(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be mcap or sidecar"})
 		return
 	}
-	if !h.requireEpisodePresignAuth(c, kind) {
+	if !allowEpisodePresign(c, kind) {
 		return
 	}
 
@@ -660,6 +645,13 @@ func (h *EpisodeHandler) GetEpisode(c *gin.Context) {
 		return
 	}
 
+	annotations, err := loadEpisodeAnnotations(c.Request.Context(), h.db, row.ID)
+	if err != nil {
+		logger.Printf("[EPISODE] Failed to query episode annotations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to query episode"})
+		return
+	}
+
 	c.JSON(http.StatusOK, Episode{
 		ID:                 row.ID,
 		EpisodeID:          row.EpisodeID,
@@ -689,5 +681,6 @@ func (h *EpisodeHandler) GetEpisode(c *gin.Context) {
 		CreatedAt:          row.CreatedAt.UTC().Format(time.RFC3339),
 		Labels:             episodeLabelsFromDB(row.LabelsJSON),
 		Metadata:           parseJSONRaw(row.Metadata.String),
+		Annotations:        annotations,
 	})
 }
@@ -0,0 +1,388 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	annotationSeverityInfo     = "info"
+	annotationSeverityMinor    = "minor"
+	annotationSeverityMajor    = "major"
+	annotationSeverityCritical = "critical"
+
+	maxAnnotationTagLen = 64
+)
+
+var (
+	errAnnotationEpisodeNotFound = errors.New("episode not found")
+	errAnnotationNotFound        = errors.New("annotation not found")
+)
+
+// EpisodeAnnotation is an inspector note on a time range of an episode.
+// Offsets are seconds from the episode start; end_sec is exclusive.
+type EpisodeAnnotation struct {
+	ID          int64   `json:"id"`
+	EpisodeID   int64   `json:"episode_id"`
+	InspectorID int64   `json:"inspector_id"`
+	StartSec    float64 `json:"start_sec"`
+	EndSec      float64 `json:"end_sec"`
+	Tag         string  `json:"tag"`
+	Note        string  `json:"note,omitempty"`
+	Severity    string  `json:"severity"`
+	CreatedAt   string  `json:"created_at,omitempty"`
+	UpdatedAt   string  `json:"updated_at,omitempty"`
+}
+
+// EpisodeAnnotationRequest is the request body for creating or replacing an
+// annotation. Operators may omit inspector_id; see InspectionRequest.
+type EpisodeAnnotationRequest struct {
+	InspectorID int64   `json:"inspector_id,omitempty" example:"3"`
+	StartSec    float64 `json:"start_sec" example:"121.5"`
+	EndSec      float64 `json:"end_sec" example:"123.5"`
+	Tag         string  `json:"tag" example:"camera_glitch"`
+	Note        string  `json:"note,omitempty"`
+	Severity    string  `json:"severity,omitempty" example:"major"`
+}
+
+// EpisodeAnnotationListResponse is the annotation list response.
+type EpisodeAnnotationListResponse struct {
+	Items []EpisodeAnnotation `json:"items"`
+	Total int                 `json:"total"`
+}
+
+type episodeAnnotationRow struct {
+	ID          int64          `db:"id"`
+	EpisodeID   int64          `db:"episode_id"`
+	InspectorID int64          `db:"inspector_id"`
+	StartSec    float64        `db:"start_sec"`
+	EndSec      float64        `db:"end_sec"`
+	Tag         string         `db:"tag"`
+	Note        sql.NullString `db:"note"`
+	Severity    string         `db:"severity"`
+	CreatedAt   sql.NullTime   `db:"created_at"`
+	UpdatedAt   sql.NullTime   `db:"updated_at"`
+}
+
+const episodeAnnotationColumns = `id, episode_id, inspector_id, start_sec, end_sec, tag, note, severity, created_at, updated_at`
+
+// loadEpisodeAnnotations returns an episode's annotations ordered by start offset.
+func loadEpisodeAnnotations(ctx context.Context, q sqlx.QueryerContext, episodeID int64) ([]EpisodeAnnotation, error) {
+	var rows []episodeAnnotationRow
+	if err := sqlx.SelectContext(ctx, q, &rows, `
+		SELECT `+episodeAnnotationColumns+`
+		FROM episode_annotations
+		WHERE episode_id = ? AND deleted_at IS NULL
+		ORDER BY start_sec ASC, id ASC
+	`, episodeID); err != nil {
+		return nil, err
+	}
+	items := make([]EpisodeAnnotation, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, episodeAnnotationFromRow(row))
+	}
+	return items, nil
+}
+
+func episodeAnnotationFromRow(row episodeAnnotationRow) EpisodeAnnotation {
+	annotation := EpisodeAnnotation{
+		ID:          row.ID,
+		EpisodeID:   row.EpisodeID,
+		InspectorID: row.InspectorID,
+		StartSec:    row.StartSec,
+		EndSec:      row.EndSec,
+		Tag:         row.Tag,
+		Note:        row.Note.String,
+		Severity:    row.Severity,
+	}
+	if row.CreatedAt.Valid {
+		annotation.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if row.UpdatedAt.Valid {
+		annotation.UpdatedAt = row.UpdatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return annotation
+}
+
+// validateEpisodeAnnotationRequest normalizes req in place. durationSec, when
+// known, bounds the range.
+func validateEpisodeAnnotationRequest(req *EpisodeAnnotationRequest, durationSec sql.NullFloat64) error {
+	if req.InspectorID <= 0 {
+		return fmt.Errorf("inspector_id is required")
+	}
+	if math.IsNaN(req.StartSec) || math.IsNaN(req.EndSec) || req.StartSec < 0 {
+		return fmt.Errorf("start_sec must not be negative")
+	}
+	if req.EndSec <= req.StartSec {
+		return fmt.Errorf("end_sec must be greater than start_sec")
+	}
+	if durationSec.Valid && durationSec.Float64 > 0 && req.EndSec > durationSec.Float64 {
+		return fmt.Errorf("end_sec exceeds episode duration %.3fs", durationSec.Float64)
+	}
+	req.Tag = strings.TrimSpace(req.Tag)
+	if req.Tag == "" {
+		return fmt.Errorf("tag is required")
+	}
+	if len(req.Tag) > maxAnnotationTagLen {
+		return fmt.Errorf("tag exceeds %d characters", maxAnnotationTagLen)
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	req.Severity = strings.TrimSpace(strings.ToLower(req.Severity))
+	switch req.Severity {
+	case "":
+		req.Severity = annotationSeverityMinor
+	case annotationSeverityInfo, annotationSeverityMinor, annotationSeverityMajor, annotationSeverityCritical:
+	default:
+		return fmt.Errorf("severity must be info, minor, major or critical")
+	}
+	return nil
+}
+
+// RegisterAnnotationRoutes registers inspector annotation routes.
+func (h *EpisodeQAHandler) RegisterAnnotationRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/episodes/:id/annotations", h.ListEpisodeAnnotations)
+	apiV1.POST("/qa/episodes/:id/annotations", h.CreateEpisodeAnnotation)
+	apiV1.PUT("/qa/episodes/:id/annotations/:annotation_id", h.UpdateEpisodeAnnotation)
+	apiV1.DELETE("/qa/episodes/:id/annotations/:annotation_id", h.DeleteEpisodeAnnotation)
+}
+
+// ListEpisodeAnnotations lists an episode's time-ranged annotations.
+//
+// @Summary      List episode annotations
+// @Description  Lists inspector annotations on an episode ordered by start offset
+// @Tags         qa
+// @Produce      json
+// @Param        id path int true "Episode ID"
+// @Success      200 {object} EpisodeAnnotationListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/episodes/{id}/annotations [get]
+func (h *EpisodeQAHandler) ListEpisodeAnnotations(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	episodeID, ok := parseEpisodeIDParam(c)
+	if !ok {
+		return
+	}
+	if _, err := loadAnnotatedEpisodeDuration(c.Request.Context(), h.db, episodeID); err != nil {
+		h.writeAnnotationError(c, episodeID, err)
+		return
+	}
+	items, err := loadEpisodeAnnotations(c.Request.Context(), h.db, episodeID)
+	if err != nil {
+		h.writeAnnotationError(c, episodeID, err)
+		return
+	}
+	c.JSON(http.StatusOK, EpisodeAnnotationListResponse{Items: items, Total: len(items)})
+}
+
+// CreateEpisodeAnnotation attaches a time-ranged annotation to an episode.
+//
+// @Summary      Create episode annotation
+// @Description  Attaches a time range with a tag, note and severity so downstream training can trim bad segments instead of dropping the episode
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                      true "Episode ID"
+// @Param        body body EpisodeAnnotationRequest true "Annotation"
+// @Success      201 {object} EpisodeAnnotation
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/episodes/{id}/annotations [post]
+func (h *EpisodeQAHandler) CreateEpisodeAnnotation(c *gin.Context) {
+	h.saveEpisodeAnnotation(c, 0)
+}
+
+// UpdateEpisodeAnnotation replaces an episode annotation.
+//
+// @Summary      Update episode annotation
+// @Description  Replaces the range, tag, note and severity of an annotation
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id            path int                      true "Episode ID"
+// @Param        annotation_id path int                      true "Annotation ID"
+// @Param        body          body EpisodeAnnotationRequest true "Annotation"
+// @Success      200 {object} EpisodeAnnotation
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/episodes/{id}/annotations/{annotation_id} [put]
+func (h *EpisodeQAHandler) UpdateEpisodeAnnotation(c *gin.Context) {
+	annotationID, ok := parseAnnotationIDParam(c)
+	if !ok {
+		return
+	}
+	h.saveEpisodeAnnotation(c, annotationID)
+}
+
+// saveEpisodeAnnotation creates an annotation when annotationID is zero and
+// replaces it otherwise.
+func (h *EpisodeQAHandler) saveEpisodeAnnotation(c *gin.Context, annotationID int64) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	episodeID, ok := parseEpisodeIDParam(c)
+	if !ok {
+		return
+	}
+	var req EpisodeAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inspectorID, ok := h.resolveRequestInspector(c, req.InspectorID)
+	if !ok {
+		return
+	}
+	req.InspectorID = inspectorID
+
+	ctx := c.Request.Context()
+	duration, err := loadAnnotatedEpisodeDuration(ctx, h.db, episodeID)
+	if err != nil {
+		h.writeAnnotationError(c, episodeID, err)
+		return
+	}
+	if err := validateEpisodeAnnotationRequest(&req, duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := activeInspectorLevel(ctx, h.db, req.InspectorID); err != nil {
+		h.writeAnnotationError(c, episodeID, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	note := sql.NullString{String: req.Note, Valid: req.Note != ""}
+	status := http.StatusOK
+	if annotationID == 0 {
+		// #nosec G701 -- static SQL with placeholder-bound annotation values.
+		result, err := h.db.ExecContext(ctx, `
+			INSERT INTO episode_annotations (episode_id, inspector_id, start_sec, end_sec, tag, note, severity, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, episodeID, req.InspectorID, req.StartSec, req.EndSec, req.Tag, note, req.Severity, now, now)
+		if err == nil {
+			annotationID, err = result.LastInsertId()
+		}
+		if err != nil {
+			h.writeAnnotationError(c, episodeID, fmt.Errorf("insert annotation: %w", err))
+			return
+		}
+		status = http.StatusCreated
+	} else {
+		// #nosec G701 -- static SQL with placeholder-bound annotation values.
+		result, err := h.db.ExecContext(ctx, `
+			UPDATE episode_annotations
+			SET inspector_id = ?, start_sec = ?, end_sec = ?, tag = ?, note = ?, severity = ?, updated_at = ?
+			WHERE id = ? AND episode_id = ? AND deleted_at IS NULL
+		`, req.InspectorID, req.StartSec, req.EndSec, req.Tag, note, req.Severity, now, annotationID, episodeID)
+		if err != nil {
+			h.writeAnnotationError(c, episodeID, fmt.Errorf("update annotation: %w", err))
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			h.writeAnnotationError(c, episodeID, errAnnotationNotFound)
+			return
+		}
+	}
+
+	var row episodeAnnotationRow
+	if err := h.db.GetContext(ctx, &row, "SELECT "+episodeAnnotationColumns+" FROM episode_annotations WHERE id = ?", annotationID); err != nil {
+		h.writeAnnotationError(c, episodeID, fmt.Errorf("reload annotation: %w", err))
+		return
+	}
+	logger.Printf("[EPISODE-QA] Annotation saved: episode=%d, annotation=%d, tag=%s, range=%.3f-%.3f", episodeID, annotationID, row.Tag, row.StartSec, row.EndSec)
+	c.JSON(status, episodeAnnotationFromRow(row))
+}
+
+// DeleteEpisodeAnnotation soft deletes an episode annotation.
+//
+// @Summary      Delete episode annotation
+// @Description  Soft deletes an annotation
+// @Tags         qa
+// @Produce      json
+// @Param        id            path int true "Episode ID"
+// @Param        annotation_id path int true "Annotation ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/episodes/{id}/annotations/{annotation_id} [delete]
+func (h *EpisodeQAHandler) DeleteEpisodeAnnotation(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	episodeID, ok := parseEpisodeIDParam(c)
+	if !ok {
+		return
+	}
+	annotationID, ok := parseAnnotationIDParam(c)
+	if !ok {
+		return
+	}
+	// #nosec G701 -- static SQL with placeholder-bound annotation values.
+	result, err := h.db.ExecContext(c.Request.Context(), `
+		UPDATE episode_annotations SET deleted_at = ?
+		WHERE id = ? AND episode_id = ? AND deleted_at IS NULL
+	`, time.Now().UTC(), annotationID, episodeID)
+	if err != nil {
+		h.writeAnnotationError(c, episodeID, fmt.Errorf("delete annotation: %w", err))
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		h.writeAnnotationError(c, episodeID, errAnnotationNotFound)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func loadAnnotatedEpisodeDuration(ctx context.Context, q sqlx.QueryerContext, episodeID int64) (sql.NullFloat64, error) {
+	var duration sql.NullFloat64
+	err := sqlx.GetContext(ctx, q, &duration, "SELECT duration_sec FROM episodes WHERE id = ? AND deleted_at IS NULL", episodeID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return duration, errAnnotationEpisodeNotFound
+	}
+	return duration, err
+}
+
+func (h *EpisodeQAHandler) writeAnnotationError(c *gin.Context, episodeID int64, err error) {
+	switch {
+	case errors.Is(err, errAnnotationEpisodeNotFound), errors.Is(err, errAnnotationNotFound), errors.Is(err, errInspectorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, errInspectorInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.Printf("[EPISODE-QA] Annotation request failed: episode=%d, err=%v", episodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process annotation"})
+	}
+}
+
+func parseAnnotationIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("annotation_id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid annotation id"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEpisodeAnnotationWorkflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupInspectionTestDB(t)
+	execQAProfileTestSQL(t, db, `
+		ALTER TABLE episodes ADD COLUMN duration_sec REAL;
+		UPDATE episodes SET duration_sec = 600 WHERE id = 1;
+		CREATE TABLE episode_annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			inspector_id INTEGER NOT NULL,
+			start_sec REAL NOT NULL,
+			end_sec REAL NOT NULL,
+			tag TEXT NOT NULL,
+			note TEXT,
+			severity TEXT NOT NULL,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+	`)
+	h := &EpisodeQAHandler{db: db}
+	router := gin.New()
+	h.RegisterAnnotationRoutes(router.Group("/api/v1"))
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	const base = "/api/v1/qa/episodes/1/annotations"
+
+	for name, tc := range map[string]struct {
+		path string
+		body map[string]any
+		want int
+	}{
+		"inverted range":     {base, map[string]any{"inspector_id": 3, "start_sec": 5, "end_sec": 4, "tag": "glitch"}, http.StatusBadRequest},
+		"past duration":      {base, map[string]any{"inspector_id": 3, "start_sec": 590, "end_sec": 601, "tag": "glitch"}, http.StatusBadRequest},
+		"missing tag":        {base, map[string]any{"inspector_id": 3, "start_sec": 1, "end_sec": 2}, http.StatusBadRequest},
+		"bad severity":       {base, map[string]any{"inspector_id": 3, "start_sec": 1, "end_sec": 2, "tag": "glitch", "severity": "fatal"}, http.StatusBadRequest},
+		"inactive inspector": {base, map[string]any{"inspector_id": 4, "start_sec": 1, "end_sec": 2, "tag": "glitch"}, http.StatusBadRequest},
+		"unknown episode":    {"/api/v1/qa/episodes/99/annotations", map[string]any{"inspector_id": 3, "start_sec": 1, "end_sec": 2, "tag": "glitch"}, http.StatusNotFound},
+	} {
+		if rec := do(http.MethodPost, tc.path, tc.body); rec.Code != tc.want {
+			t.Fatalf("%s = %d %s, want %d", name, rec.Code, rec.Body.String(), tc.want)
+		}
+	}
+
+	rec := do(http.MethodPost, base, map[string]any{"inspector_id": 3, "start_sec": 121.5, "end_sec": 123.5, "tag": " camera_glitch ", "note": "frozen frame", "severity": "Major"})
+	var created EpisodeAnnotation
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("create = %d %s", rec.Code, rec.Body.String())
+	}
+	if created.Tag != "camera_glitch" || created.Severity != annotationSeverityMajor || created.EpisodeID != 1 {
+		t.Fatalf("created = %+v", created)
+	}
+	rec = do(http.MethodPost, base, map[string]any{"inspector_id": 3, "start_sec": 10, "end_sec": 12, "tag": "blur"})
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create second = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPut, fmt.Sprintf("%s/%d", base, created.ID), map[string]any{"inspector_id": 3, "start_sec": 121, "end_sec": 124, "tag": "camera_glitch", "severity": "critical"})
+	var updated EpisodeAnnotation
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("update = %d %s", rec.Code, rec.Body.String())
+	}
+	if updated.StartSec != 121 || updated.EndSec != 124 || updated.Severity != annotationSeverityCritical || updated.Note != "" {
+		t.Fatalf("updated = %+v", updated)
+	}
+	if rec := do(http.MethodPut, "/api/v1/qa/episodes/2/annotations/"+fmt.Sprint(created.ID), map[string]any{"inspector_id": 3, "start_sec": 1, "end_sec": 2, "tag": "x"}); rec.Code != http.StatusNotFound {
+		t.Fatalf("update on other episode = %d, want 404", rec.Code)
+	}
+
+	rec = do(http.MethodGet, base, nil)
+	var list EpisodeAnnotationListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+	if list.Total != 2 || list.Items[0].Tag != "blur" || list.Items[1].ID != created.ID {
+		t.Fatalf("list = %+v", list.Items)
+	}
+
+	if rec := do(http.MethodDelete, fmt.Sprintf("%s/%d", base, created.ID), nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, fmt.Sprintf("%s/%d", base, created.ID), nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete = %d, want 404", rec.Code)
+	}
+	annotations, err := loadEpisodeAnnotations(t.Context(), db, 1)
+	if err != nil || len(annotations) != 1 || annotations[0].Tag != "blur" {
+		t.Fatalf("annotations after delete = %+v, %v", annotations, err)
+	}
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEpisodeIntegrityScrubberScrubOnce(t *testing.T) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	recent := time.Now().UTC().Add(-time.Hour)
+	stale := time.Now().UTC().AddDate(0, 0, -40)
+	if _, err := db.Exec(`
+		CREATE TABLE episodes (
+			id INTEGER PRIMARY KEY,
+			mcap_path TEXT,
+			sidecar_path TEXT,
+			qa_status TEXT,
+			quality_flag TEXT,
+			checksum TEXT,
+			labels TEXT,
+			integrity_checked_at TIMESTAMP NULL,
+			updated_at TIMESTAMP NULL,
+			deleted_at TIMESTAMP NULL
+		);
+		INSERT INTO episodes (id, mcap_path, checksum, labels, integrity_checked_at) VALUES
+			(1, 'a.mcap', 'abc', NULL, NULL),
+			(2, 'b.mcap', 'abc', '["sensor_issue"]', ?),
+			(3, 'c.mcap', 'abc', NULL, ?),
+			(4, 'd.mcap', NULL, NULL, NULL),
+			(5, 'e.mcap', 'abc', NULL, NULL);
+	`, stale, recent); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	s := NewEpisodeIntegrityScrubber(&EpisodeQAHandler{db: db}, &config.QAConfig{ScrubBatchSize: 3})
+	var visited []int64
+	s.verify = func(_ context.Context, row episodeQACheckRow) (episodeQACheckOutcome, error) {
+		visited = append(visited, row.ID)
+		switch row.ID {
+		case 2:
+			return checksumMatchFailure("Checksum mismatch: corrupted", nil), nil
+		case 5:
+			return episodeQACheckOutcome{}, errors.New("storage unavailable")
+		}
+		return episodeQACheckOutcome{CheckName: episodeQACheckChecksumMatch, Passed: true, Score: 1}, nil
+	}
+
+	verified, flagged, err := s.scrubOnce(context.Background())
+	if err != nil {
+		t.Fatalf("scrubOnce: %v", err)
+	}
+	// Never-checked episodes go first, then the stale one; 3 is recent and 4
+	// has no checksum.
+	if verified != 2 || flagged != 1 || len(visited) != 3 || visited[0] != 1 || visited[1] != 5 || visited[2] != 2 {
+		t.Fatalf("verified=%d flagged=%d visited=%v", verified, flagged, visited)
+	}
+
+	var row struct {
+		QualityFlag sql.NullString `db:"quality_flag"`
+		Labels      sql.NullString `db:"labels"`
+	}
+	if err := db.Get(&row, "SELECT quality_flag, labels FROM episodes WHERE id = 2"); err != nil {
+		t.Fatalf("query flagged: %v", err)
+	}
+	if row.QualityFlag.String != "Checksum mismatch: corrupted" || row.Labels.String != `["sensor_issue","integrity_mismatch"]` {
+		t.Fatalf("flagged row = %+v", row)
+	}
+
+	var unchecked int
+	if err := db.Get(&unchecked, "SELECT COUNT(*) FROM episodes WHERE integrity_checked_at IS NULL"); err != nil {
+		t.Fatalf("count unchecked: %v", err)
+	}
+	if unchecked != 1 {
+		t.Fatalf("unchecked = %d, want 1 (the episode without checksum)", unchecked)
+	}
+
+	visited = nil
+	if verified, _, err := s.scrubOnce(context.Background()); err != nil || verified != 0 || len(visited) != 0 {
+		t.Fatalf("second scrub verified=%d visited=%v err=%v", verified, visited, err)
+	}
+}
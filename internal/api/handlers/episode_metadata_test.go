@@ -59,6 +59,14 @@ func TestGetEpisodeReturnsMetadata(t *testing.T) {
 	if recording["recorder_version"] != "axon_recorder 0.5.0" {
 		t.Fatalf("recorder.recording.recorder_version=%v want axon_recorder 0.5.0", recording["recorder_version"])
 	}
+	annotations, ok := body["annotations"].([]any)
+	if !ok || len(annotations) != 2 {
+		t.Fatalf("annotations=%#v want 2 live annotations", body["annotations"])
+	}
+	first, _ := annotations[0].(map[string]any)
+	if first["tag"] != "occlusion" || first["start_sec"] != 1.5 || first["severity"] != "major" {
+		t.Fatalf("first annotation=%#v want occlusion at 1.5s", first)
+	}
 }
 
 func TestListEpisodesOmitsMetadata(t *testing.T) {
@@ -162,6 +170,19 @@ func openEpisodeMetadataTestDB(t *testing.T) *sqlx.DB {
 			id INTEGER PRIMARY KEY,
 			inspector_id TEXT
 		)`,
+		`CREATE TABLE episode_annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			inspector_id INTEGER NOT NULL,
+			start_sec REAL NOT NULL,
+			end_sec REAL NOT NULL,
+			tag TEXT NOT NULL,
+			note TEXT,
+			severity TEXT NOT NULL,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		)`,
 	} {
 		if _, err := db.Exec(stmt); err != nil {
 			t.Fatalf("create schema: %v", err)
@@ -194,4 +215,12 @@ func seedEpisodeMetadataTestRow(t *testing.T, db *sqlx.DB) {
 	`, metadata); err != nil {
 		t.Fatalf("seed episode: %v", err)
 	}
+	if _, err := db.Exec(`
+		INSERT INTO episode_annotations (episode_id, inspector_id, start_sec, end_sec, tag, note, severity, deleted_at) VALUES
+			(1, 3, 8, 9.5, 'dropout', NULL, 'minor', NULL),
+			(1, 3, 1.5, 3, 'occlusion', 'hand over camera', 'major', NULL),
+			(1, 3, 0, 1, 'deleted', NULL, 'info', '2026-06-24T01:00:00Z')
+	`); err != nil {
+		t.Fatalf("seed annotations: %v", err)
+	}
 }
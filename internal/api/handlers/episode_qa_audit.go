@@ -0,0 +1,931 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	qaAuditStatusPending   = "pending"
+	qaAuditStatusAgreed    = "agreed"
+	qaAuditStatusDisagreed = "disagreed"
+
+	// auditRejectedEpisodeLabel is appended to episodes.labels when a second
+	// review rejects an auto-approved episode.
+	auditRejectedEpisodeLabel = "audit_rejected"
+)
+
+// Binding bits rank audit policies that bind the same number of dimensions;
+// a robot type is narrower than a factory.
+const (
+	qaAuditPolicyBindsFactory = 1 << iota
+	qaAuditPolicyBindsRobotType
+)
+
+var errQAAuditAlreadyReviewed = errors.New("audit already reviewed")
+
+// QAAuditPolicyRequest is the request body for creating or replacing an
+// audit sampling policy.
+type QAAuditPolicyRequest struct {
+	FactoryID   *int64  `json:"factory_id,omitempty"`
+	RobotTypeID *int64  `json:"robot_type_id,omitempty"`
+	SampleRate  float64 `json:"sample_rate" example:"0.05"`
+	Enabled     *bool   `json:"enabled,omitempty"`
+}
+
+// QAAuditPolicyResponse is one audit sampling policy.
+type QAAuditPolicyResponse struct {
+	ID          int64   `json:"id"`
+	FactoryID   *int64  `json:"factory_id,omitempty"`
+	RobotTypeID *int64  `json:"robot_type_id,omitempty"`
+	SampleRate  float64 `json:"sample_rate"`
+	Enabled     bool    `json:"enabled"`
+	CreatedAt   string  `json:"created_at,omitempty"`
+	UpdatedAt   string  `json:"updated_at,omitempty"`
+}
+
+// QAAuditPolicyListResponse is the audit policy list response.
+type QAAuditPolicyListResponse struct {
+	Items []QAAuditPolicyResponse `json:"items"`
+	Total int                     `json:"total"`
+}
+
+// QAAuditCheckResult is one check outcome of the approving QA run.
+type QAAuditCheckResult struct {
+	CheckName string  `json:"check_name"`
+	Passed    bool    `json:"passed"`
+	Score     float64 `json:"score"`
+}
+
+// QAAuditResponse is one sampled auto-approved episode.
+type QAAuditResponse struct {
+	ID           int64                `json:"id"`
+	EpisodeID    int64                `json:"episode_id"`
+	PolicyID     *int64               `json:"policy_id,omitempty"`
+	FactoryID    *int64               `json:"factory_id,omitempty"`
+	RobotTypeID  *int64               `json:"robot_type_id,omitempty"`
+	SampleRate   float64              `json:"sample_rate"`
+	QAScore      *float64             `json:"qa_score,omitempty"`
+	CheckResults []QAAuditCheckResult `json:"check_results"`
+	Status       string               `json:"status"`
+	InspectorID  *int64               `json:"inspector_id,omitempty"`
+	Decision     *string              `json:"decision,omitempty"`
+	Reason       *string              `json:"reason,omitempty"`
+	FailedTags   []string             `json:"failed_tags,omitempty"`
+	SampledAt    string               `json:"sampled_at"`
+	ReviewedAt   string               `json:"reviewed_at,omitempty"`
+}
+
+// QAAuditListResponse is the audit list response.
+type QAAuditListResponse struct {
+	Items   []QAAuditResponse `json:"items"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	HasNext bool              `json:"hasNext,omitempty"`
+	HasPrev bool              `json:"hasPrev,omitempty"`
+}
+
+// QAAuditReviewRequest is an inspector's second review of a sampled episode.
+// Operators review as their own inspector record; inspector_id is only
+// required from callers without one and must otherwise match it.
+type QAAuditReviewRequest struct {
+	InspectorID int64    `json:"inspector_id,omitempty" example:"3"`
+	Decision    string   `json:"decision" example:"rejected"`
+	Reason      string   `json:"reason,omitempty"`
+	FailedTags  []string `json:"failed_tags,omitempty"`
+}
+
+// QACheckFalseApproveRate is the estimated false-approve rate of one check:
+// how often a second review rejected an auto-approved episode the check
+// passed. Estimates weight each audit by 1/sample_rate.
+type QACheckFalseApproveRate struct {
+	CheckName          string   `json:"check_name"`
+	Reviewed           int      `json:"reviewed"`
+	Passed             int      `json:"passed"`
+	PassedDisagreed    int      `json:"passed_disagreed"`
+	FalseApproveRate   float64  `json:"false_approve_rate"`
+	MeanScoreAgreed    *float64 `json:"mean_score_agreed,omitempty"`
+	MeanScoreDisagreed *float64 `json:"mean_score_disagreed,omitempty"`
+}
+
+// QAFalseApproveRateResponse reports false-approve estimates from reviewed audits.
+type QAFalseApproveRateResponse struct {
+	Reviewed         int                       `json:"reviewed"`
+	Disagreed        int                       `json:"disagreed"`
+	FalseApproveRate float64                   `json:"false_approve_rate"`
+	Checks           []QACheckFalseApproveRate `json:"checks"`
+}
+
+type qaAuditPolicyRow struct {
+	ID          int64         `db:"id"`
+	FactoryID   sql.NullInt64 `db:"factory_id"`
+	RobotTypeID sql.NullInt64 `db:"robot_type_id"`
+	SampleRate  float64       `db:"sample_rate"`
+	Enabled     bool          `db:"enabled"`
+	CreatedAt   sql.NullTime  `db:"created_at"`
+	UpdatedAt   sql.NullTime  `db:"updated_at"`
+}
+
+const qaAuditPolicyColumns = `id, factory_id, robot_type_id, sample_rate, enabled, created_at, updated_at`
+
+type qaAuditRow struct {
+	ID           int64           `db:"id"`
+	EpisodeID    int64           `db:"episode_id"`
+	PolicyID     sql.NullInt64   `db:"policy_id"`
+	FactoryID    sql.NullInt64   `db:"factory_id"`
+	RobotTypeID  sql.NullInt64   `db:"robot_type_id"`
+	SampleRate   float64         `db:"sample_rate"`
+	QAScore      sql.NullFloat64 `db:"qa_score"`
+	CheckResults sql.NullString  `db:"check_results"`
+	Status       string          `db:"status"`
+	InspectorID  sql.NullInt64   `db:"inspector_id"`
+	Decision     sql.NullString  `db:"decision"`
+	Reason       sql.NullString  `db:"reason"`
+	FailedTags   sql.NullString  `db:"failed_tags"`
+	SampledAt    time.Time       `db:"sampled_at"`
+	ReviewedAt   sql.NullTime    `db:"reviewed_at"`
+}
+
+const qaAuditColumns = `id, episode_id, policy_id, factory_id, robot_type_id, sample_rate, qa_score, check_results, status, inspector_id, decision, reason, failed_tags, sampled_at, reviewed_at`
+
+func (h *EpisodeQAHandler) auditSamplingEnabled() bool {
+	return h.db != nil && h.qaCfg != nil && h.qaCfg.AuditEnabled
+}
+
+func (h *EpisodeQAHandler) auditDraw() float64 {
+	if h.auditRand != nil {
+		return h.auditRand()
+	}
+	return rand.Float64()
+}
+
+// sampleAutoApprovedEpisode sends an auto-approved episode to second review
+// with the probability of its most specific audit policy.
+func (h *EpisodeQAHandler) sampleAutoApprovedEpisode(ctx context.Context, episodeID int64, score float64, outcomes []episodeQACheckOutcome) error {
+	var binding struct {
+		FactoryID   sql.NullInt64 `db:"factory_id"`
+		RobotTypeID sql.NullInt64 `db:"robot_type_id"`
+	}
+	err := h.db.GetContext(ctx, &binding, `
+		SELECT e.factory_id, r.robot_type_id
+		FROM episodes e
+		LEFT JOIN workstations ws ON ws.id = e.workstation_id
+		LEFT JOIN robots r ON r.id = ws.robot_id
+		WHERE e.id = ?
+		LIMIT 1
+	`, episodeID)
+	if err != nil {
+		return fmt.Errorf("query episode audit binding: %w", err)
+	}
+
+	var policies []qaAuditPolicyRow
+	if err := h.db.SelectContext(ctx, &policies, `
+		SELECT `+qaAuditPolicyColumns+`
+		FROM qa_audit_policies
+		WHERE deleted_at IS NULL AND enabled = TRUE
+		  AND (factory_id IS NULL OR factory_id = ?)
+		  AND (robot_type_id IS NULL OR robot_type_id = ?)
+	`, binding.FactoryID, binding.RobotTypeID); err != nil {
+		return fmt.Errorf("query qa audit policies: %w", err)
+	}
+	policyID := sql.NullInt64{}
+	rate := h.qaCfg.AuditSampleRate
+	if policy, ok := pickMostSpecificQAAuditPolicy(policies); ok {
+		policyID = sql.NullInt64{Int64: policy.ID, Valid: true}
+		rate = policy.SampleRate
+	}
+	if rate <= 0 || h.auditDraw() >= rate {
+		return nil
+	}
+	rate = min(rate, 1)
+
+	results := make([]QAAuditCheckResult, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		results = append(results, QAAuditCheckResult{CheckName: outcome.CheckName, Passed: outcome.Passed, Score: outcome.Score})
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("marshal audit check results: %w", err)
+	}
+	// #nosec G701 -- static SQL with placeholder-bound audit values.
+	if _, err := h.db.ExecContext(ctx, `
+		INSERT INTO qa_audits (episode_id, policy_id, factory_id, robot_type_id, sample_rate, qa_score, check_results, status, sampled_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, episodeID, policyID, binding.FactoryID, binding.RobotTypeID, rate, score, string(resultsJSON), qaAuditStatusPending, time.Now().UTC()); err != nil {
+		return fmt.Errorf("insert qa audit: %w", err)
+	}
+	logger.Printf("[EPISODE-QA] Auto-approved episode sampled for audit: episode=%d, rate=%.4f", episodeID, rate)
+	return nil
+}
+
+func qaAuditPolicySpecificity(row qaAuditPolicyRow) (int, int) {
+	count, bits := 0, 0
+	if row.FactoryID.Valid {
+		count++
+		bits |= qaAuditPolicyBindsFactory
+	}
+	if row.RobotTypeID.Valid {
+		count++
+		bits |= qaAuditPolicyBindsRobotType
+	}
+	return count, bits
+}
+
+// pickMostSpecificQAAuditPolicy returns the most specific matching policy;
+// ties go to the newest policy.
+func pickMostSpecificQAAuditPolicy(rows []qaAuditPolicyRow) (qaAuditPolicyRow, bool) {
+	if len(rows) == 0 {
+		return qaAuditPolicyRow{}, false
+	}
+	best := rows[0]
+	bestCount, bestBits := qaAuditPolicySpecificity(best)
+	for _, row := range rows[1:] {
+		count, bits := qaAuditPolicySpecificity(row)
+		if count > bestCount ||
+			(count == bestCount && bits > bestBits) ||
+			(count == bestCount && bits == bestBits && row.ID > best.ID) {
+			best, bestCount, bestBits = row, count, bits
+		}
+	}
+	return best, true
+}
+
+// RegisterAuditPolicyRoutes registers audit sampling policy CRUD routes. The
+// group is expected to enforce admin authentication.
+func (h *EpisodeQAHandler) RegisterAuditPolicyRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/audit-policies", h.ListQAAuditPolicies)
+	apiV1.POST("/qa/audit-policies", h.CreateQAAuditPolicy)
+	apiV1.PUT("/qa/audit-policies/:id", h.UpdateQAAuditPolicy)
+	apiV1.DELETE("/qa/audit-policies/:id", h.DeleteQAAuditPolicy)
+}
+
+// RegisterAuditRoutes registers the second-review queue and reporting routes.
+func (h *EpisodeQAHandler) RegisterAuditRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/audits", h.ListQAAudits)
+	apiV1.GET("/qa/audits/false-approve-rates", h.GetQAFalseApproveRates)
+	apiV1.POST("/qa/audits/:id/review", h.ReviewQAAudit)
+}
+
+// ListQAAuditPolicies lists audit sampling policies.
+//
+// @Summary      List QA audit policies
+// @Description  Lists per-factory / per-robot-type audit sampling policies
+// @Tags         qa
+// @Produce      json
+// @Success      200 {object} QAAuditPolicyListResponse
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audit-policies [get]
+func (h *EpisodeQAHandler) ListQAAuditPolicies(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var rows []qaAuditPolicyRow
+	if err := h.db.Select(&rows, "SELECT "+qaAuditPolicyColumns+" FROM qa_audit_policies WHERE deleted_at IS NULL ORDER BY id ASC"); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa audit policies: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa audit policies"})
+		return
+	}
+	items := make([]QAAuditPolicyResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, qaAuditPolicyResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, QAAuditPolicyListResponse{Items: items, Total: len(items)})
+}
+
+// CreateQAAuditPolicy creates an audit sampling policy.
+//
+// @Summary      Create QA audit policy
+// @Description  Creates an audit sampling policy bound to a factory, a robot type, both, or neither
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        body body QAAuditPolicyRequest true "Audit policy payload"
+// @Success      201 {object} QAAuditPolicyResponse
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audit-policies [post]
+func (h *EpisodeQAHandler) CreateQAAuditPolicy(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var req QAAuditPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if !h.validateQAAuditPolicyRequest(c, req, 0) {
+		return
+	}
+
+	now := time.Now().UTC()
+	// #nosec G701 -- static SQL with placeholder-bound audit policy values.
+	result, err := h.db.Exec(`
+		INSERT INTO qa_audit_policies (factory_id, robot_type_id, sample_rate, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sqlNullInt64FromPtr(req.FactoryID), sqlNullInt64FromPtr(req.RobotTypeID), req.SampleRate, req.Enabled == nil || *req.Enabled, now, now)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to insert qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa audit policy"})
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch inserted qa audit policy id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa audit policy"})
+		return
+	}
+	row, err := h.loadQAAuditPolicy(id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch created qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get created qa audit policy"})
+		return
+	}
+	c.JSON(http.StatusCreated, qaAuditPolicyResponseFromRow(row))
+}
+
+// UpdateQAAuditPolicy replaces an audit sampling policy.
+//
+// @Summary      Update QA audit policy
+// @Description  Replaces an audit sampling policy's bindings, rate and enabled flag
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                  true "QA audit policy ID"
+// @Param        body body QAAuditPolicyRequest true "Audit policy payload"
+// @Success      200 {object} QAAuditPolicyResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audit-policies/{id} [put]
+func (h *EpisodeQAHandler) UpdateQAAuditPolicy(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAAuditIDParam(c, "invalid qa audit policy id")
+	if !ok {
+		return
+	}
+	var req QAAuditPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	current, err := h.loadQAAuditPolicy(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa audit policy not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update qa audit policy"})
+		return
+	}
+	if !h.validateQAAuditPolicyRequest(c, req, id) {
+		return
+	}
+
+	enabled := current.Enabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	// #nosec G701 -- static SQL with placeholder-bound audit policy values.
+	if _, err := h.db.Exec(`
+		UPDATE qa_audit_policies
+		SET factory_id = ?, robot_type_id = ?, sample_rate = ?, enabled = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, sqlNullInt64FromPtr(req.FactoryID), sqlNullInt64FromPtr(req.RobotTypeID), req.SampleRate, enabled, time.Now().UTC(), id); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to update qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update qa audit policy"})
+		return
+	}
+	row, err := h.loadQAAuditPolicy(id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch updated qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get updated qa audit policy"})
+		return
+	}
+	c.JSON(http.StatusOK, qaAuditPolicyResponseFromRow(row))
+}
+
+// DeleteQAAuditPolicy soft deletes an audit sampling policy.
+//
+// @Summary      Delete QA audit policy
+// @Description  Soft deletes an audit sampling policy; matching episodes fall back to the next most specific policy
+// @Tags         qa
+// @Produce      json
+// @Param        id path int true "QA audit policy ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audit-policies/{id} [delete]
+func (h *EpisodeQAHandler) DeleteQAAuditPolicy(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAAuditIDParam(c, "invalid qa audit policy id")
+	if !ok {
+		return
+	}
+	// #nosec G701 -- static SQL with placeholder-bound audit policy values.
+	result, err := h.db.Exec("UPDATE qa_audit_policies SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to delete qa audit policy: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete qa audit policy"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa audit policy not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// validateQAAuditPolicyRequest writes the error response itself and returns
+// false on failure. selfID excludes the policy being replaced from the
+// binding conflict check.
+func (h *EpisodeQAHandler) validateQAAuditPolicyRequest(c *gin.Context, req QAAuditPolicyRequest, selfID int64) bool {
+	if req.SampleRate < 0 || req.SampleRate > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sample_rate must be in [0, 1]"})
+		return false
+	}
+	bindings := []struct {
+		field string
+		table string
+		id    *int64
+	}{
+		{"factory_id", "factories", req.FactoryID},
+		{"robot_type_id", "robot_types", req.RobotTypeID},
+	}
+	for _, binding := range bindings {
+		if binding.id == nil {
+			continue
+		}
+		if *binding.id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + binding.field})
+			return false
+		}
+		var exists bool
+		// #nosec G202 -- table name comes from the fixed binding list above.
+		if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM "+binding.table+" WHERE id = ? AND deleted_at IS NULL)", *binding.id); err != nil {
+			logger.Printf("[EPISODE-QA] Failed to check qa audit policy %s: %v", binding.field, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate qa audit policy"})
+			return false
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": binding.field + " not found"})
+			return false
+		}
+	}
+
+	var conflict bool
+	err := h.db.Get(&conflict, `
+		SELECT EXISTS(
+			SELECT 1 FROM qa_audit_policies
+			WHERE deleted_at IS NULL AND id <> ?
+			  AND (factory_id = ? OR (factory_id IS NULL AND ? IS NULL))
+			  AND (robot_type_id = ? OR (robot_type_id IS NULL AND ? IS NULL))
+		)
+	`, selfID,
+		sqlNullInt64FromPtr(req.FactoryID), sqlNullInt64FromPtr(req.FactoryID),
+		sqlNullInt64FromPtr(req.RobotTypeID), sqlNullInt64FromPtr(req.RobotTypeID))
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to check qa audit policy binding conflict: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate qa audit policy"})
+		return false
+	}
+	if conflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "a qa audit policy with the same factory_id and robot_type_id already exists"})
+		return false
+	}
+	return true
+}
+
+func (h *EpisodeQAHandler) loadQAAuditPolicy(id int64) (qaAuditPolicyRow, error) {
+	var row qaAuditPolicyRow
+	err := h.db.Get(&row, "SELECT "+qaAuditPolicyColumns+" FROM qa_audit_policies WHERE id = ? AND deleted_at IS NULL", id)
+	return row, err
+}
+
+// ListQAAudits lists sampled auto-approved episodes.
+//
+// @Summary      List QA audits
+// @Description  Lists audit samples, oldest first. Defaults to the pending second-review queue.
+// @Tags         qa
+// @Produce      json
+// @Param        status        query string false "pending (default), agreed, disagreed or all"
+// @Param        factory_id    query int    false "Filter by factory"
+// @Param        robot_type_id query int    false "Filter by robot type"
+// @Param        limit         query int    false "Max results (default 50, max 100)"
+// @Param        offset        query int    false "Pagination offset (default 0)"
+// @Success      200 {object} QAAuditListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audits [get]
+func (h *EpisodeQAHandler) ListQAAudits(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+	where, args, ok := qaAuditFilters(c)
+	if !ok {
+		return
+	}
+	switch status := strings.TrimSpace(strings.ToLower(c.DefaultQuery("status", qaAuditStatusPending))); status {
+	case "all":
+	case qaAuditStatusPending, qaAuditStatusAgreed, qaAuditStatusDisagreed:
+		where += " AND status = ?"
+		args = append(args, status)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, agreed, disagreed or all"})
+		return
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(1) FROM qa_audits"+where, args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to count qa audits: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa audits"})
+		return
+	}
+	var rows []qaAuditRow
+	query := "SELECT " + qaAuditColumns + " FROM qa_audits" + where + " ORDER BY sampled_at ASC, id ASC LIMIT ? OFFSET ?"
+	if err := h.db.Select(&rows, query, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa audits: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa audits"})
+		return
+	}
+	items := make([]QAAuditResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, qaAuditResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, QAAuditListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// ReviewQAAudit records the second review of a sampled episode.
+//
+// @Summary      Review QA audit
+// @Description  Records an inspector's second review. A rejection is recorded as a disagreement with the auto-approval and labels the episode audit_rejected.
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                  true "QA audit ID"
+// @Param        body body QAAuditReviewRequest true "Review decision"
+// @Success      200 {object} QAAuditResponse
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audits/{id}/review [post]
+func (h *EpisodeQAHandler) ReviewQAAudit(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAAuditIDParam(c, "invalid qa audit id")
+	if !ok {
+		return
+	}
+	var req QAAuditReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inspectorID, ok := h.resolveRequestInspector(c, req.InspectorID)
+	if !ok {
+		return
+	}
+	req.InspectorID = inspectorID
+	req.Decision = strings.TrimSpace(strings.ToLower(req.Decision))
+	req.Reason = strings.TrimSpace(req.Reason)
+	// Second reviews follow the same decision rules as inspections.
+	failedTags, err := validateInspectionDecision(req.Decision, req.Reason, req.FailedTags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	row, err := h.recordQAAuditReview(c.Request.Context(), id, req, failedTags)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa audit not found"})
+		return
+	case errors.Is(err, errInspectorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectorInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errQAAuditAlreadyReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		logger.Printf("[EPISODE-QA] Failed to record qa audit review: audit=%d, err=%v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record qa audit review"})
+		return
+	}
+	logger.Printf("[EPISODE-QA] Audit reviewed: audit=%d, episode=%d, status=%s", row.ID, row.EpisodeID, row.Status)
+	c.JSON(http.StatusOK, qaAuditResponseFromRow(*row))
+}
+
+// recordQAAuditReview stores the review and, on disagreement, flags the
+// episode in the same transaction.
+func (h *EpisodeQAHandler) recordQAAuditReview(ctx context.Context, id int64, req QAAuditReviewRequest, failedTags []string) (*qaAuditRow, error) {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin audit review transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var row qaAuditRow
+	if err := tx.GetContext(ctx, &row, "SELECT "+qaAuditColumns+" FROM qa_audits WHERE id = ?"+forUpdateClause(tx), id); err != nil {
+		return nil, err
+	}
+	if row.Status != qaAuditStatusPending {
+		return nil, errQAAuditAlreadyReviewed
+	}
+	if _, err := activeInspectorLevel(ctx, tx, req.InspectorID); err != nil {
+		return nil, err
+	}
+
+	status := qaAuditStatusAgreed
+	if req.Decision == inspectionDecisionRejected {
+		status = qaAuditStatusDisagreed
+	}
+	tagsJSON := sql.NullString{}
+	if len(failedTags) > 0 {
+		encoded, err := json.Marshal(failedTags)
+		if err != nil {
+			return nil, fmt.Errorf("marshal failed tags: %w", err)
+		}
+		tagsJSON = sql.NullString{String: string(encoded), Valid: true}
+	}
+	now := time.Now().UTC()
+	// #nosec G701 -- static SQL with placeholder-bound audit values.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE qa_audits
+		SET status = ?, inspector_id = ?, decision = ?, reason = ?, failed_tags = ?, reviewed_at = ?
+		WHERE id = ?
+	`, status, req.InspectorID, req.Decision, sql.NullString{String: req.Reason, Valid: req.Reason != ""}, tagsJSON, now, id); err != nil {
+		return nil, fmt.Errorf("update qa audit: %w", err)
+	}
+
+	if status == qaAuditStatusDisagreed {
+		var labelsJSON sql.NullString
+		if err := tx.GetContext(ctx, &labelsJSON, "SELECT labels FROM episodes WHERE id = ?"+forUpdateClause(tx), row.EpisodeID); err != nil {
+			return nil, fmt.Errorf("query episode %d labels: %w", row.EpisodeID, err)
+		}
+		labels := episodeLabelsFromDB(labelsJSON)
+		if !slices.Contains(labels, auditRejectedEpisodeLabel) {
+			labels = append(labels, auditRejectedEpisodeLabel)
+		}
+		encoded, err := json.Marshal(labels)
+		if err != nil {
+			return nil, fmt.Errorf("marshal episode labels: %w", err)
+		}
+		// #nosec G701 -- static SQL with placeholder-bound episode values.
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE episodes
+			SET labels = ?, quality_flag = ?, updated_at = ?
+			WHERE id = ?
+		`, string(encoded), "Audit rejected auto-approval: "+req.Reason, now, row.EpisodeID); err != nil {
+			return nil, fmt.Errorf("flag audited episode %d: %w", row.EpisodeID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit audit review: %w", err)
+	}
+
+	row.Status = status
+	row.InspectorID = sql.NullInt64{Int64: req.InspectorID, Valid: true}
+	row.Decision = sql.NullString{String: req.Decision, Valid: true}
+	row.Reason = sql.NullString{String: req.Reason, Valid: req.Reason != ""}
+	row.FailedTags = tagsJSON
+	row.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+	return &row, nil
+}
+
+// GetQAFalseApproveRates estimates false-approve rates from reviewed audits.
+//
+// @Summary      QA false-approve rates
+// @Description  Estimates how often auto-approved episodes are rejected on second review, overall and per QA check (among audits the check passed). Each audit is weighted by 1/sample_rate so differently sampled factories and robot types combine correctly.
+// @Tags         qa
+// @Produce      json
+// @Param        factory_id    query int false "Filter by factory"
+// @Param        robot_type_id query int false "Filter by robot type"
+// @Success      200 {object} QAFalseApproveRateResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/audits/false-approve-rates [get]
+func (h *EpisodeQAHandler) GetQAFalseApproveRates(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	where, args, ok := qaAuditFilters(c)
+	if !ok {
+		return
+	}
+	var rows []qaAuditRow
+	query := "SELECT " + qaAuditColumns + " FROM qa_audits" + where + " AND status IN (?, ?)"
+	if err := h.db.Select(&rows, query, append(args, qaAuditStatusAgreed, qaAuditStatusDisagreed)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query reviewed qa audits: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute false-approve rates"})
+		return
+	}
+	c.JSON(http.StatusOK, computeQAFalseApproveRates(rows))
+}
+
+// computeQAFalseApproveRates aggregates reviewed audits. A check's rate is
+// the weighted share of disagreements among audits where the check passed.
+func computeQAFalseApproveRates(rows []qaAuditRow) QAFalseApproveRateResponse {
+	type checkAgg struct {
+		rate                          QACheckFalseApproveRate
+		passedWeight, disagreedWeight float64
+		agreedScore, disagreedScore   float64
+		agreedCount, disagreedCount   int
+	}
+	resp := QAFalseApproveRateResponse{Checks: []QACheckFalseApproveRate{}}
+	checks := map[string]*checkAgg{}
+	totalWeight, disagreedWeight := 0.0, 0.0
+	for _, row := range rows {
+		weight := 1.0
+		if row.SampleRate > 0 {
+			weight = 1 / row.SampleRate
+		}
+		disagreed := row.Status == qaAuditStatusDisagreed
+		resp.Reviewed++
+		totalWeight += weight
+		if disagreed {
+			resp.Disagreed++
+			disagreedWeight += weight
+		}
+		for _, result := range qaAuditCheckResults(row.CheckResults) {
+			agg := checks[result.CheckName]
+			if agg == nil {
+				agg = &checkAgg{rate: QACheckFalseApproveRate{CheckName: result.CheckName}}
+				checks[result.CheckName] = agg
+			}
+			agg.rate.Reviewed++
+			if disagreed {
+				agg.disagreedScore += result.Score
+				agg.disagreedCount++
+			} else {
+				agg.agreedScore += result.Score
+				agg.agreedCount++
+			}
+			if !result.Passed {
+				continue
+			}
+			agg.rate.Passed++
+			agg.passedWeight += weight
+			if disagreed {
+				agg.rate.PassedDisagreed++
+				agg.disagreedWeight += weight
+			}
+		}
+	}
+	if totalWeight > 0 {
+		resp.FalseApproveRate = roundQAFloat(disagreedWeight / totalWeight)
+	}
+	for _, agg := range checks {
+		if agg.passedWeight > 0 {
+			agg.rate.FalseApproveRate = roundQAFloat(agg.disagreedWeight / agg.passedWeight)
+		}
+		if agg.agreedCount > 0 {
+			mean := roundQAFloat(agg.agreedScore / float64(agg.agreedCount))
+			agg.rate.MeanScoreAgreed = &mean
+		}
+		if agg.disagreedCount > 0 {
+			mean := roundQAFloat(agg.disagreedScore / float64(agg.disagreedCount))
+			agg.rate.MeanScoreDisagreed = &mean
+		}
+		resp.Checks = append(resp.Checks, agg.rate)
+	}
+	sort.Slice(resp.Checks, func(i, j int) bool {
+		if resp.Checks[i].FalseApproveRate != resp.Checks[j].FalseApproveRate {
+			return resp.Checks[i].FalseApproveRate > resp.Checks[j].FalseApproveRate
+		}
+		return resp.Checks[i].CheckName < resp.Checks[j].CheckName
+	})
+	return resp
+}
+
+// qaAuditFilters parses the factory_id and robot_type_id filters. It writes
+// the error response itself and returns false on failure.
+func qaAuditFilters(c *gin.Context) (string, []any, bool) {
+	where := " WHERE 1 = 1"
+	args := []any{}
+	for _, filter := range []string{"factory_id", "robot_type_id"} {
+		raw := strings.TrimSpace(c.Query(filter))
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + filter})
+			return "", nil, false
+		}
+		where += " AND " + filter + " = ?"
+		args = append(args, id)
+	}
+	return where, args, true
+}
+
+func parseQAAuditIDParam(c *gin.Context, message string) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return 0, false
+	}
+	return id, true
+}
+
+func qaAuditCheckResults(raw sql.NullString) []QAAuditCheckResult {
+	results := []QAAuditCheckResult{}
+	if !raw.Valid || strings.TrimSpace(raw.String) == "" {
+		return results
+	}
+	if err := json.Unmarshal([]byte(raw.String), &results); err != nil || results == nil {
+		return []QAAuditCheckResult{}
+	}
+	return results
+}
+
+func qaAuditPolicyResponseFromRow(row qaAuditPolicyRow) QAAuditPolicyResponse {
+	resp := QAAuditPolicyResponse{
+		ID:          row.ID,
+		FactoryID:   nullableInt64(row.FactoryID),
+		RobotTypeID: nullableInt64(row.RobotTypeID),
+		SampleRate:  row.SampleRate,
+		Enabled:     row.Enabled,
+	}
+	if row.CreatedAt.Valid {
+		resp.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if row.UpdatedAt.Valid {
+		resp.UpdatedAt = row.UpdatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+func qaAuditResponseFromRow(row qaAuditRow) QAAuditResponse {
+	resp := QAAuditResponse{
+		ID:           row.ID,
+		EpisodeID:    row.EpisodeID,
+		PolicyID:     nullableInt64(row.PolicyID),
+		FactoryID:    nullableInt64(row.FactoryID),
+		RobotTypeID:  nullableInt64(row.RobotTypeID),
+		SampleRate:   row.SampleRate,
+		QAScore:      nullableFloat64(row.QAScore),
+		CheckResults: qaAuditCheckResults(row.CheckResults),
+		Status:       row.Status,
+		InspectorID:  nullableInt64(row.InspectorID),
+		Decision:     nullableString(row.Decision),
+		Reason:       nullableString(row.Reason),
+		SampledAt:    row.SampledAt.UTC().Format(time.RFC3339),
+	}
+	if row.FailedTags.Valid && strings.TrimSpace(row.FailedTags.String) != "" {
+		_ = json.Unmarshal([]byte(row.FailedTags.String), &resp.FailedTags)
+	}
+	if row.ReviewedAt.Valid {
+		resp.ReviewedAt = row.ReviewedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,297 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupQAAuditTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	execQAProfileTestSQL(t, db, `
+		CREATE TABLE factories (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NULL);
+		CREATE TABLE robot_types (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NULL);
+		CREATE TABLE robots (id INTEGER PRIMARY KEY, robot_type_id INTEGER, deleted_at TIMESTAMP NULL);
+		CREATE TABLE workstations (id INTEGER PRIMARY KEY, robot_id INTEGER, deleted_at TIMESTAMP NULL);
+		CREATE TABLE inspectors (id INTEGER PRIMARY KEY, inspector_id TEXT, certification_level TEXT DEFAULT 'level_1', status TEXT DEFAULT 'active', deleted_at TIMESTAMP NULL);
+		CREATE TABLE episodes (
+			id INTEGER PRIMARY KEY,
+			workstation_id INTEGER,
+			factory_id INTEGER,
+			qa_status TEXT,
+			qa_score REAL,
+			auto_approved BOOLEAN,
+			quality_flag TEXT,
+			labels TEXT,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+		CREATE TABLE qa_checks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			check_name TEXT NOT NULL,
+			passed BOOLEAN NOT NULL,
+			score REAL NOT NULL,
+			weight REAL NOT NULL DEFAULT 1,
+			details TEXT,
+			check_metadata TEXT,
+			script_id INTEGER NULL,
+			script_version TEXT NULL,
+			checked_at TIMESTAMP
+		);
+		CREATE TABLE qa_audit_policies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			factory_id INTEGER NULL,
+			robot_type_id INTEGER NULL,
+			sample_rate REAL NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+		CREATE TABLE qa_audits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL UNIQUE,
+			policy_id INTEGER NULL,
+			factory_id INTEGER NULL,
+			robot_type_id INTEGER NULL,
+			sample_rate REAL NOT NULL,
+			qa_score REAL NULL,
+			check_results TEXT,
+			status TEXT NOT NULL DEFAULT 'pending',
+			inspector_id INTEGER NULL,
+			decision TEXT NULL,
+			reason TEXT NULL,
+			failed_tags TEXT NULL,
+			sampled_at TIMESTAMP NOT NULL,
+			reviewed_at TIMESTAMP NULL
+		);
+		INSERT INTO factories (id) VALUES (1), (2);
+		INSERT INTO robot_types (id) VALUES (7), (8);
+		INSERT INTO robots (id, robot_type_id) VALUES (1, 7), (2, 8);
+		INSERT INTO workstations (id, robot_id) VALUES (10, 1), (20, 2);
+		INSERT INTO inspectors (id, inspector_id, status) VALUES (3, 'op-3', 'active'), (4, 'op-4', 'inactive');
+		INSERT INTO episodes (id, workstation_id, factory_id, qa_status, labels) VALUES
+			(1, 10, 1, 'qa_running', '["pick"]'),
+			(2, 20, 1, 'approved', NULL),
+			(3, 20, 2, 'approved', NULL);
+	`)
+	createStateTransitionsTestTable(t, db)
+	return db
+}
+
+func TestQAAuditSamplingAndReview(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupQAAuditTestDB(t)
+	h := &EpisodeQAHandler{
+		db:        db,
+		qaCfg:     &config.QAConfig{AutoApproveThreshold: 0.9, AuditEnabled: true, AuditSampleRate: 0},
+		auditRand: func() float64 { return 0.7 },
+	}
+	router := gin.New()
+	h.RegisterAuditRoutes(router.Group("/api/v1"))
+	h.RegisterAuditPolicyRoutes(router.Group("/api/v1"))
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, body := range []map[string]any{
+		{"sample_rate": 0.8},
+		{"factory_id": 1, "sample_rate": 0.5},
+		{"factory_id": 1, "robot_type_id": 7, "sample_rate": 1},
+	} {
+		if rec := do(http.MethodPost, "/api/v1/qa/audit-policies", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create policy %v = %d %s", body, rec.Code, rec.Body.String())
+		}
+	}
+	for name, tc := range map[string]struct {
+		body map[string]any
+		want int
+	}{
+		"duplicate binding": {map[string]any{"factory_id": 1, "sample_rate": 0.2}, http.StatusConflict},
+		"rate above one":    {map[string]any{"sample_rate": 1.5}, http.StatusBadRequest},
+		"unknown factory":   {map[string]any{"factory_id": 9, "sample_rate": 0.2}, http.StatusBadRequest},
+	} {
+		if rec := do(http.MethodPost, "/api/v1/qa/audit-policies", tc.body); rec.Code != tc.want {
+			t.Fatalf("%s = %d %s, want %d", name, rec.Code, rec.Body.String(), tc.want)
+		}
+	}
+
+	// Episode 1 matches the factory+robot type policy (rate 1) and is
+	// sampled through the auto-approve path.
+	outcomes := []episodeQACheckOutcome{
+		{CheckName: episodeQACheckMcapMagic, Passed: true, Score: 1},
+		{CheckName: episodeQACheckImageIntegrity, Passed: true, Score: 0.95},
+	}
+	claim := episodeQARunClaim{EpisodeID: 1, OriginalStatus: qaStatusPendingQA, MutableStatus: true}
+	result, err := h.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeAuto, episodeQASuitePlan{}, outcomes, time.Now().UTC())
+	if err != nil || result.QAStatus != qaStatusApproved {
+		t.Fatalf("persist = %+v, %v", result, err)
+	}
+	// Episode 2 only matches the factory policy (0.5 < draw 0.7); episode 3
+	// falls back to the global policy (0.8 > draw 0.7).
+	for _, id := range []int64{2, 3} {
+		if err := h.sampleAutoApprovedEpisode(context.Background(), id, 0.92, outcomes[:1]); err != nil {
+			t.Fatalf("sample episode %d: %v", id, err)
+		}
+	}
+
+	rec := do(http.MethodGet, "/api/v1/qa/audits", nil)
+	var audits QAAuditListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &audits); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list audits = %d %s", rec.Code, rec.Body.String())
+	}
+	if audits.Total != 2 || audits.Items[0].EpisodeID != 1 || audits.Items[1].EpisodeID != 3 {
+		t.Fatalf("audits = %+v", audits.Items)
+	}
+	if first := audits.Items[0]; first.SampleRate != 1 || first.RobotTypeID == nil || *first.RobotTypeID != 7 || len(first.CheckResults) != 2 || first.Status != qaAuditStatusPending {
+		t.Fatalf("first audit = %+v", first)
+	}
+	if audits.Items[1].SampleRate != 0.8 || audits.Items[1].PolicyID == nil || *audits.Items[1].PolicyID != 1 {
+		t.Fatalf("fallback audit = %+v", audits.Items[1])
+	}
+
+	reviewPath := func(id int64) string { return fmt.Sprintf("/api/v1/qa/audits/%d/review", id) }
+	for name, tc := range map[string]struct {
+		body any
+		want int
+	}{
+		"reject without reason": {map[string]any{"inspector_id": 3, "decision": "rejected"}, http.StatusBadRequest},
+		"inactive inspector":    {map[string]any{"inspector_id": 4, "decision": "approved"}, http.StatusBadRequest},
+		"unknown audit":         {map[string]any{"inspector_id": 3, "decision": "approved"}, http.StatusNotFound},
+	} {
+		path := reviewPath(audits.Items[0].ID)
+		if name == "unknown audit" {
+			path = reviewPath(99)
+		}
+		if rec := do(http.MethodPost, path, tc.body); rec.Code != tc.want {
+			t.Fatalf("%s = %d %s, want %d", name, rec.Code, rec.Body.String(), tc.want)
+		}
+	}
+
+	rec = do(http.MethodPost, reviewPath(audits.Items[0].ID), map[string]any{
+		"inspector_id": 3, "decision": "rejected", "reason": "gripper slipped", "failed_tags": []string{"grasp", "grasp"},
+	})
+	var reviewed QAAuditResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reviewed); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("review = %d %s", rec.Code, rec.Body.String())
+	}
+	if reviewed.Status != qaAuditStatusDisagreed || len(reviewed.FailedTags) != 1 || reviewed.ReviewedAt == "" {
+		t.Fatalf("reviewed = %+v", reviewed)
+	}
+	if rec := do(http.MethodPost, reviewPath(audits.Items[0].ID), map[string]any{"inspector_id": 3, "decision": "approved"}); rec.Code != http.StatusConflict {
+		t.Fatalf("second review = %d, want 409", rec.Code)
+	}
+	var episode struct {
+		Labels      sql.NullString `db:"labels"`
+		QualityFlag sql.NullString `db:"quality_flag"`
+	}
+	if err := db.Get(&episode, "SELECT labels, quality_flag FROM episodes WHERE id = 1"); err != nil {
+		t.Fatalf("load episode: %v", err)
+	}
+	if labels := episodeLabelsFromDB(episode.Labels); len(labels) != 2 || labels[1] != auditRejectedEpisodeLabel {
+		t.Fatalf("labels = %v", labels)
+	}
+	if episode.QualityFlag.String != "Audit rejected auto-approval: gripper slipped" {
+		t.Fatalf("quality_flag = %q", episode.QualityFlag.String)
+	}
+
+	// An operator reviews as their own inspector record and cannot name another.
+	operator := gin.New()
+	operator.Use(func(c *gin.Context) {
+		c.Set(middleware.ClaimsKey, auth.NewCollectorClaims(11, "op-3"))
+		c.Next()
+	})
+	h.RegisterAuditRoutes(operator.Group("/api/v1"))
+	asOperator := func(body map[string]any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, reviewPath(audits.Items[1].ID), bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		operator.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := asOperator(map[string]any{"inspector_id": 4, "decision": "approved"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("review as another inspector = %d %s, want 403", rec.Code, rec.Body.String())
+	}
+	rec = asOperator(map[string]any{"decision": "approved"})
+	if err := json.Unmarshal(rec.Body.Bytes(), &reviewed); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("agree review = %d %s", rec.Code, rec.Body.String())
+	}
+	if reviewed.InspectorID == nil || *reviewed.InspectorID != 3 {
+		t.Fatalf("agree review inspector = %v, want caller's inspector 3", reviewed.InspectorID)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/qa/audits/false-approve-rates", nil)
+	var rates QAFalseApproveRateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &rates); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("rates = %d %s", rec.Code, rec.Body.String())
+	}
+	// Weights: disagreed audit 1/1.0, agreed audit 1/0.8 = 1.25.
+	if rates.Reviewed != 2 || rates.Disagreed != 1 || rates.FalseApproveRate != roundQAFloat(1/2.25) {
+		t.Fatalf("overall rates = %+v", rates)
+	}
+	if len(rates.Checks) != 2 || rates.Checks[0].CheckName != episodeQACheckImageIntegrity || rates.Checks[0].FalseApproveRate != 1 {
+		t.Fatalf("check rates = %+v", rates.Checks)
+	}
+	magic := rates.Checks[1]
+	if magic.Passed != 2 || magic.PassedDisagreed != 1 || magic.FalseApproveRate != roundQAFloat(1/2.25) || magic.MeanScoreAgreed == nil || magic.MeanScoreDisagreed == nil {
+		t.Fatalf("mcap_magic rate = %+v", magic)
+	}
+	rec = do(http.MethodGet, "/api/v1/qa/audits/false-approve-rates?factory_id=2", nil)
+	if err := json.Unmarshal(rec.Body.Bytes(), &rates); err != nil || rates.Reviewed != 1 || rates.Disagreed != 0 {
+		t.Fatalf("filtered rates = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPickMostSpecificQAAuditPolicy(t *testing.T) {
+	valid := func(id int64) sql.NullInt64 { return sql.NullInt64{Int64: id, Valid: true} }
+	rows := []qaAuditPolicyRow{
+		{ID: 1},
+		{ID: 2, FactoryID: valid(1)},
+		{ID: 3, RobotTypeID: valid(7)},
+		{ID: 4},
+	}
+	if got, ok := pickMostSpecificQAAuditPolicy(rows); !ok || got.ID != 3 {
+		t.Fatalf("picked = %+v, want robot type policy 3", got)
+	}
+	if got, _ := pickMostSpecificQAAuditPolicy([]qaAuditPolicyRow{rows[0], rows[3]}); got.ID != 4 {
+		t.Fatalf("picked = %+v, want newest global policy 4", got)
+	}
+	if _, ok := pickMostSpecificQAAuditPolicy(nil); ok {
+		t.Fatal("picked a policy from an empty list")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
@@ -21,7 +22,6 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/minio/minio-go/v7"
 
-	"archebase.com/keystone-edge/internal/auth"
 	"archebase.com/keystone-edge/internal/config"
 	"archebase.com/keystone-edge/internal/logger"
 	"archebase.com/keystone-edge/internal/storage/s3"
@@ -42,14 +42,28 @@ const (
 	qaStatusRejected          = "rejected"
 	qaStatusFailed            = "failed"
 
-	defaultEpisodeQAQueueSize = 256
-	defaultEpisodeQATimeout   = 2 * time.Minute
-	maxEpisodeQASidecarBytes  = 4 * 1024 * 1024
+	defaultEpisodeQAQueueSize            = 256
+	defaultEpisodeQATimeout              = 2 * time.Minute
+	defaultEpisodeQAAutoApproveThreshold = 0.90
+	maxEpisodeQASidecarBytes             = 4 * 1024 * 1024
+	// maxEpisodeQACheckWeight is the largest value qa_checks.weight DECIMAL(4,3) holds.
+	maxEpisodeQACheckWeight = 9.999
 )
 
 var (
 	mcapMagicBytes = []byte{0x89, 0x4d, 0x43, 0x41, 0x50, 0x30, 0x0d, 0x0a}
 
+	// episodeQACheckAliases maps the short names documented for KEYSTONE_QA_CHECKS.
+	episodeQACheckAliases = map[string]string{
+		"duration":   episodeQACheckRecordingNotEmpty,
+		"topics":     episodeQACheckRequiredTopics,
+		"gaps":       episodeQACheckMessageGaps,
+		"images":     episodeQACheckImageIntegrity,
+		"checksum":   episodeQACheckChecksumMatch,
+		"writer":     episodeQACheckWriterHealth,
+		"timestamps": episodeQACheckTimestamps,
+	}
+
 	errEpisodeQANotFound       = errors.New("episode not found")
 	errEpisodeQAAlreadyRunning = errors.New("episode qa already running")
 	errEpisodeQAAutoSkipped    = errors.New("episode auto qa skipped")
@@ -63,8 +77,16 @@ type EpisodeQAHandler struct {
 	db      *sqlx.DB
 	s3      *s3.Client
 	bucket  string
-	authCfg *config.AuthConfig
+	qaCfg   *config.QAConfig
 	queue   chan int64
+	suite   []string
+	weights map[string]float64
+	// registry holds the runnable checks; nil falls back to the builtins.
+	registry *QACheckRegistry
+	// syncEnqueuer receives inspector-approved episodes; nil disables the handoff.
+	syncEnqueuer inspectionSyncEnqueuer
+	// auditRand draws audit samples; nil uses math/rand.
+	auditRand func() float64
 }
 
 // EpisodeQARunRequest is the request body for running an episode QA suite.
@@ -79,8 +101,11 @@ type EpisodeQACheckRecordResponse struct {
 	CheckName     string         `json:"check_name"`
 	Passed        bool           `json:"passed"`
 	Score         float64        `json:"score"`
+	Weight        float64        `json:"weight"`
 	Details       string         `json:"details"`
 	CheckMetadata map[string]any `json:"check_metadata,omitempty"`
+	ScriptID      int64          `json:"script_id,omitempty"`
+	ScriptVersion string         `json:"script_version,omitempty"`
 	CheckedAt     string         `json:"checked_at"`
 }
 
@@ -89,7 +114,9 @@ type EpisodeQASuiteResponse struct {
 	EpisodeID int64                          `json:"episode_id"`
 	QAStatus  string                         `json:"qa_status"`
 	Passed    bool                           `json:"passed"`
+	Score     float64                        `json:"score"`
 	Mode      QARunMode                      `json:"mode"`
+	ProfileID int64                          `json:"profile_id,omitempty"`
 	Checks    []EpisodeQACheckRecordResponse `json:"checks"`
 }
 
@@ -131,6 +158,10 @@ type episodeQACheckOutcome struct {
 	Score     float64
 	Details   string
 	Metadata  map[string]any
+	// ScriptID and ScriptVersion identify the qa_scripts row that produced
+	// an external script outcome.
+	ScriptID      int64
+	ScriptVersion string
 }
 
 type episodeQACheckRow struct {
@@ -145,6 +176,7 @@ type episodeQARunClaim struct {
 	EpisodeID      int64
 	OriginalStatus string
 	MutableStatus  bool
+	Mode           QARunMode
 }
 
 type episodeQACheckDBRow struct {
@@ -153,8 +185,11 @@ type episodeQACheckDBRow struct {
 	CheckName     string         `db:"check_name"`
 	Passed        bool           `db:"passed"`
 	Score         float64        `db:"score"`
+	Weight        float64        `db:"weight"`
 	Details       sql.NullString `db:"details"`
 	CheckMetadata sql.NullString `db:"check_metadata"`
+	ScriptID      sql.NullInt64  `db:"script_id"`
+	ScriptVersion sql.NullString `db:"script_version"`
 	CheckedAt     sql.NullTime   `db:"checked_at"`
 }
 
@@ -176,21 +211,86 @@ type episodeQAListRow struct {
 	LatestCheckCheckedAt sql.NullTime    `db:"latest_check_checked_at"`
 }
 
-// NewEpisodeQAHandler creates the QA handler and starts the in-memory auto-QA worker.
-func NewEpisodeQAHandler(db *sqlx.DB, s3Client *s3.Client, bucket string, authCfg *config.AuthConfig) *EpisodeQAHandler {
+// NewEpisodeQAHandler creates the QA handler and starts the in-memory auto-QA
+// worker pool sized by qaCfg.MaxWorkers.
+func NewEpisodeQAHandler(db *sqlx.DB, s3Client *s3.Client, bucket string, qaCfg *config.QAConfig) *EpisodeQAHandler {
 	h := &EpisodeQAHandler{
-		db:      db,
-		s3:      s3Client,
-		bucket:  strings.TrimSpace(bucket),
-		authCfg: authCfg,
-		queue:   make(chan int64, defaultEpisodeQAQueueSize),
+		db:       db,
+		s3:       s3Client,
+		bucket:   strings.TrimSpace(bucket),
+		qaCfg:    qaCfg,
+		queue:    make(chan int64, defaultEpisodeQAQueueSize),
+		registry: newBuiltinQACheckRegistry(),
 	}
 	if db != nil {
-		go h.runAutoWorker()
+		if err := h.registry.RegisterResolver(qaScriptCheckPrefix, h.resolveQAScriptCheck); err != nil {
+			logger.Printf("[EPISODE-QA] Failed to register qa script checks: %v", err)
+		}
+	}
+	if qaCfg != nil {
+		suite, unknown := resolveEpisodeQASuite(qaCfg.Checks, h.registry)
+		for _, name := range unknown {
+			logger.Printf("[EPISODE-QA] Ignoring unregistered check in KEYSTONE_QA_CHECKS: %q", name)
+		}
+		h.suite = suite
+		h.weights = resolveEpisodeQACheckWeights(qaCfg.CheckWeights)
+	}
+	if db != nil && h.autoQAEnabled() {
+		for i := 0; i < h.autoWorkerCount(); i++ {
+			go h.runAutoWorker()
+		}
 	}
 	return h
 }
 
+// RegisterQACheck adds a check to this handler's registry. Checks named in
+// KEYSTONE_QA_CHECKS become part of the default suite once registered. Call
+// it during startup, before episodes are enqueued.
+func (h *EpisodeQAHandler) RegisterQACheck(check QACheck) error {
+	if h.registry == nil {
+		h.registry = newBuiltinQACheckRegistry()
+	}
+	if err := h.registry.Register(check); err != nil {
+		return err
+	}
+	if h.qaCfg != nil {
+		h.suite, _ = resolveEpisodeQASuite(h.qaCfg.Checks, h.registry)
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) checkRegistry() *QACheckRegistry {
+	if h.registry == nil {
+		return builtinQAChecks
+	}
+	return h.registry
+}
+
+func (h *EpisodeQAHandler) autoQAEnabled() bool {
+	return h.qaCfg == nil || h.qaCfg.Enabled
+}
+
+func (h *EpisodeQAHandler) autoWorkerCount() int {
+	if h.qaCfg == nil || h.qaCfg.MaxWorkers <= 0 {
+		return 1
+	}
+	return h.qaCfg.MaxWorkers
+}
+
+func (h *EpisodeQAHandler) episodeQATimeout() time.Duration {
+	if h.qaCfg == nil || h.qaCfg.TimeoutPerEpisode <= 0 {
+		return defaultEpisodeQATimeout
+	}
+	return time.Duration(h.qaCfg.TimeoutPerEpisode) * time.Second
+}
+
+func (h *EpisodeQAHandler) autoApproveThreshold() float64 {
+	if h.qaCfg == nil || h.qaCfg.AutoApproveThreshold <= 0 {
+		return defaultEpisodeQAAutoApproveThreshold
+	}
+	return h.qaCfg.AutoApproveThreshold
+}
+
 // RegisterRoutes registers QA center routes under /api/v1/qa.
 func (h *EpisodeQAHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
 	qa := apiV1.Group("/qa")
@@ -201,7 +301,7 @@ func (h *EpisodeQAHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
 
 // EnqueueEpisode schedules lightweight automatic QA for a newly created episode.
 func (h *EpisodeQAHandler) EnqueueEpisode(episodeID int64) {
-	if h == nil || h.queue == nil || episodeID <= 0 {
+	if h == nil || h.queue == nil || episodeID <= 0 || !h.autoQAEnabled() {
 		return
 	}
 	select {
@@ -213,29 +313,10 @@ func (h *EpisodeQAHandler) EnqueueEpisode(episodeID int64) {
 
 func (h *EpisodeQAHandler) runAutoWorker() {
 	for episodeID := range h.queue {
-		ctx, cancel := context.WithTimeout(context.Background(), defaultEpisodeQATimeout)
-		if _, err := h.RunEpisodeQASuite(ctx, episodeID, qaRunModeAuto); err != nil && !errors.Is(err, errEpisodeQAAutoSkipped) {
+		if _, err := h.RunEpisodeQASuite(context.Background(), episodeID, qaRunModeAuto); err != nil && !errors.Is(err, errEpisodeQAAutoSkipped) {
 			logger.Printf("[EPISODE-QA] Auto QA failed: episode=%d, err=%v", episodeID, err)
 		}
-		cancel()
-	}
-}
-
-func (h *EpisodeQAHandler) requireBearerJWT(c *gin.Context) bool {
-	if h.authCfg == nil {
-		return true
-	}
-	authHeader := strings.TrimSpace(c.GetHeader("Authorization"))
-	parts := strings.SplitN(authHeader, " ", 2)
-	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid authorization header"})
-		return false
 	}
-	if _, err := auth.ParseToken(parts[1], h.authCfg); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-		return false
-	}
-	return true
 }
 
 // ListQAEpisodes lists episodes for the QA center.
@@ -258,9 +339,6 @@ func (h *EpisodeQAHandler) ListQAEpisodes(c *gin.Context) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
 		return
 	}
-	if !h.requireBearerJWT(c) {
-		return
-	}
 
 	page := parsePositiveIntQuery(c, "page", 1)
 	pageSize := parsePositiveIntQuery(c, "page_size", 20)
@@ -385,9 +463,6 @@ func (h *EpisodeQAHandler) ListEpisodeQAChecks(c *gin.Context) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
 		return
 	}
-	if !h.requireBearerJWT(c) {
-		return
-	}
 
 	episodeID, ok := parseEpisodeIDParam(c)
 	if !ok {
@@ -405,7 +480,7 @@ func (h *EpisodeQAHandler) ListEpisodeQAChecks(c *gin.Context) {
 
 	var rows []episodeQACheckDBRow
 	if err := h.db.SelectContext(c.Request.Context(), &rows, `
-		SELECT id, episode_id, check_name, passed, score, details, check_metadata, checked_at
+		SELECT id, episode_id, check_name, passed, score, weight, details, check_metadata, script_id, script_version, checked_at
 		FROM qa_checks
 		WHERE episode_id = ?
 		ORDER BY checked_at DESC, id DESC
@@ -425,7 +500,7 @@ func (h *EpisodeQAHandler) ListEpisodeQAChecks(c *gin.Context) {
 // RunEpisodeQASuiteHTTP runs the full QA suite for one episode.
 //
 // @Summary      Run episode QA suite
-// @Description  Runs the configured QA suite for one episode. The MVP suite is mcap_magic.
+// @Description  Runs the episode's QA suite, taken from its most specific QA profile or KEYSTONE_QA_CHECKS, and scores it against the auto-approve threshold.
 // @Tags         qa
 // @Accept       json
 // @Produce      json
@@ -442,9 +517,6 @@ func (h *EpisodeQAHandler) RunEpisodeQASuiteHTTP(c *gin.Context) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
 		return
 	}
-	if !h.requireBearerJWT(c) {
-		return
-	}
 
 	episodeID, ok := parseEpisodeIDParam(c)
 	if !ok {
@@ -503,40 +575,159 @@ func (h *EpisodeQAHandler) RunEpisodeQASuite(ctx context.Context, episodeID int6
 		return nil, err
 	}
 
-	checks := defaultEpisodeQASuite(row)
-	outcomes := make([]episodeQACheckOutcome, 0, len(checks))
+	plan, err := h.resolveEpisodeQASuitePlan(ctx, row)
+	if err != nil {
+		h.releaseEpisodeQARun(ctx, claim)
+		return nil, err
+	}
+	outcomes := make([]episodeQACheckOutcome, 0, len(plan.Checks))
 	checkedAt := time.Now().UTC()
-	for _, checkName := range checks {
-		outcome, err := h.runEpisodeQACheck(ctx, checkName, row)
+	runCtx, cancel := context.WithTimeout(ctx, h.episodeQATimeout())
+	defer cancel()
+	scan, err := h.runEpisodeQASuiteScan(runCtx, row, plan)
+	if err != nil {
+		h.releaseEpisodeQARun(context.WithoutCancel(ctx), claim)
+		return nil, err
+	}
+	for _, checkName := range plan.Checks {
+		var outcome episodeQACheckOutcome
+		if consumer, ok := scan.consumers[checkName]; ok {
+			outcome, err = consumer.finish(scan.pass)
+			if outcome.CheckName == "" {
+				outcome.CheckName = checkName
+			}
+		} else {
+			outcome, err = h.runEpisodeQACheck(runCtx, checkName, row, plan.Params[checkName])
+		}
 		if err != nil {
-			h.releaseEpisodeQARun(ctx, claim)
+			// The run context may have timed out; the claim must still be released.
+			h.releaseEpisodeQARun(context.WithoutCancel(ctx), claim)
 			return nil, err
 		}
 		outcomes = append(outcomes, outcome)
 	}
 
-	result, err := h.persistEpisodeQASuiteResult(ctx, claim, mode, outcomes, checkedAt)
+	result, err := h.persistEpisodeQASuiteResult(ctx, claim, mode, plan, outcomes, checkedAt)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// defaultEpisodeQASuite is the suite run when none is configured: the
+// documented KEYSTONE_QA_CHECKS default. Other checks are opt-in.
 func defaultEpisodeQASuite(_ episodeQACheckRow) []string {
-	return []string{episodeQACheckMcapMagic, episodeQACheckRecordingNotEmpty}
+	suite, _ := resolveEpisodeQASuite(config.DefaultQAChecks, builtinQAChecks)
+	return suite
 }
 
-func normalizeEpisodeQACheckName(raw string) string {
-	return strings.TrimSpace(strings.ToLower(raw))
+// episodeQASuite returns the configured suite, or the default when none is configured.
+func (h *EpisodeQAHandler) episodeQASuite(row episodeQACheckRow) []string {
+	if len(h.suite) == 0 {
+		return defaultEpisodeQASuite(row)
+	}
+	return append([]string(nil), h.suite...)
 }
 
-func isSupportedEpisodeQACheckName(checkName string) bool {
-	switch checkName {
-	case episodeQACheckMcapMagic, episodeQACheckRecordingNotEmpty:
-		return true
-	default:
-		return false
+// resolveEpisodeQASuite maps configured check names and aliases to supported
+// checks, keeping order and dropping duplicates. Unknown names are returned
+// separately.
+func resolveEpisodeQASuite(names []string, registry *QACheckRegistry) ([]string, []string) {
+	seen := map[string]struct{}{}
+	suite := make([]string, 0, len(names))
+	var unknown []string
+	for _, raw := range names {
+		name := canonicalEpisodeQACheckName(raw)
+		if name == "" {
+			continue
+		}
+		if _, ok := registry.Lookup(name); !ok {
+			unknown = append(unknown, raw)
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		suite = append(suite, name)
+	}
+	return suite, unknown
+}
+
+func resolveEpisodeQACheckWeights(raw map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(raw))
+	for name, weight := range raw {
+		name = canonicalEpisodeQACheckName(name)
+		if name == "" || weight < 0 {
+			continue
+		}
+		weights[name] = math.Min(weight, maxEpisodeQACheckWeight)
+	}
+	return weights
+}
+
+func canonicalEpisodeQACheckName(raw string) string {
+	name := normalizeEpisodeQACheckName(raw)
+	if alias, ok := episodeQACheckAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func (h *EpisodeQAHandler) episodeQACheckWeight(checkName string) float64 {
+	if weight, ok := h.weights[checkName]; ok {
+		return weight
 	}
+	return 1
+}
+
+// weightedEpisodeQAScore is the weighted mean of check scores, rounded to the
+// precision of episodes.qa_score. When every weight is zero it falls back to
+// the plain mean.
+func weightedEpisodeQAScore(outcomes []episodeQACheckOutcome, weightOf func(string) float64) float64 {
+	if len(outcomes) == 0 {
+		return 0
+	}
+	sum, weightSum, plainSum := 0.0, 0.0, 0.0
+	for _, outcome := range outcomes {
+		weight := weightOf(outcome.CheckName)
+		sum += outcome.Score * weight
+		weightSum += weight
+		plainSum += outcome.Score
+	}
+	if weightSum <= 0 {
+		return roundQAFloat(plainSum / float64(len(outcomes)))
+	}
+	return roundQAFloat(sum / weightSum)
+}
+
+// decideEpisodeQAStatus maps suite outcomes to a qa_status and quality_flag.
+// Blocking failures mark the episode failed; otherwise the weighted score is
+// compared against the auto-approve threshold.
+func decideEpisodeQAStatus(outcomes []episodeQACheckOutcome, score, threshold float64, isBlocking func(string) bool) (string, string) {
+	firstFailure := ""
+	for _, outcome := range outcomes {
+		if outcome.Passed {
+			continue
+		}
+		if isBlocking(outcome.CheckName) {
+			return qaStatusFailed, outcome.Details
+		}
+		if firstFailure == "" {
+			firstFailure = outcome.Details
+		}
+	}
+	if score >= threshold {
+		return qaStatusApproved, firstFailure
+	}
+	if firstFailure == "" {
+		firstFailure = fmt.Sprintf("QA score %.3f is below auto-approve threshold %.3f", score, threshold)
+	}
+	return qaStatusNeedsInspection, firstFailure
+}
+
+func normalizeEpisodeQACheckName(raw string) string {
+	return strings.TrimSpace(strings.ToLower(raw))
 }
 
 func (h *EpisodeQAHandler) loadEpisodeForQACheck(ctx context.Context, episodeID int64) (episodeQACheckRow, error) {
@@ -577,6 +768,7 @@ func (h *EpisodeQAHandler) claimEpisodeQARun(ctx context.Context, row episodeQAC
 	claim := episodeQARunClaim{
 		EpisodeID:      row.ID,
 		OriginalStatus: row.QAStatus,
+		Mode:           mode,
 	}
 
 	if row.QAStatus == qaStatusRunning {
@@ -589,19 +781,12 @@ func (h *EpisodeQAHandler) claimEpisodeQARun(ctx context.Context, row episodeQAC
 		return claim, nil
 	}
 
-	// #nosec G701 -- static SQL with placeholder-bound status and episode values.
-	res, err := h.db.ExecContext(ctx, `
-		UPDATE episodes
-		SET qa_status = ?
-		WHERE id = ? AND deleted_at IS NULL AND COALESCE(qa_status, '') = ?
-	`, qaStatusRunning, row.ID, row.QAStatus)
+	affected, err := execStateTransition(ctx, h.db, stateEntityEpisode, qaStatusRunning, qaRunTransitionActor(mode), nil,
+		"qa_status = ?", []any{qaStatusRunning},
+		"id = ? AND deleted_at IS NULL AND COALESCE(qa_status, '') = ?", row.ID, row.QAStatus)
 	if err != nil {
 		return claim, fmt.Errorf("claim episode qa run: %w", err)
 	}
-	affected, err := res.RowsAffected()
-	if err != nil {
-		return claim, fmt.Errorf("read claim rows affected: %w", err)
-	}
 	if affected == 0 {
 		fresh, err := h.loadEpisodeForQACheck(ctx, row.ID)
 		if err != nil {
@@ -620,16 +805,18 @@ func (h *EpisodeQAHandler) claimEpisodeQARun(ctx context.Context, row episodeQAC
 	return claim, nil
 }
 
+// qaRunTransitionActor attributes QA status changes to the QA runner.
+func qaRunTransitionActor(mode QARunMode) stateTransitionActor {
+	return systemTransitionActor("episode_qa:" + string(mode))
+}
+
 func (h *EpisodeQAHandler) releaseEpisodeQARun(ctx context.Context, claim episodeQARunClaim) {
 	if h == nil || h.db == nil || !claim.MutableStatus {
 		return
 	}
-	// #nosec G701 -- static SQL with placeholder-bound status and episode values.
-	if _, err := h.db.ExecContext(ctx, `
-		UPDATE episodes
-		SET qa_status = ?
-		WHERE id = ? AND deleted_at IS NULL AND qa_status = ?
-	`, claim.OriginalStatus, claim.EpisodeID, qaStatusRunning); err != nil {
+	if _, err := execStateTransition(ctx, h.db, stateEntityEpisode, claim.OriginalStatus, qaRunTransitionActor(claim.Mode), map[string]any{"reason": "qa_run_aborted"},
+		"qa_status = ?", []any{claim.OriginalStatus},
+		"id = ? AND deleted_at IS NULL AND qa_status = ?", claim.EpisodeID, qaStatusRunning); err != nil {
 		logger.Printf("[EPISODE-QA] Failed to release QA run: episode=%d, err=%v", claim.EpisodeID, err)
 	}
 }
@@ -643,19 +830,19 @@ func isManualQAProtectedStatus(status string) bool {
 	}
 }
 
-func (h *EpisodeQAHandler) runEpisodeQACheck(ctx context.Context, checkName string, row episodeQACheckRow) (episodeQACheckOutcome, error) {
-	checkName = normalizeEpisodeQACheckName(checkName)
-	if !isSupportedEpisodeQACheckName(checkName) {
-		return episodeQACheckOutcome{}, fmt.Errorf("unsupported qa check %q", checkName)
+func (h *EpisodeQAHandler) runEpisodeQACheck(ctx context.Context, checkName string, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	check, ok := h.checkRegistry().Lookup(checkName)
+	if !ok {
+		return episodeQACheckOutcome{}, fmt.Errorf("unsupported qa check %q", normalizeEpisodeQACheckName(checkName))
 	}
-	switch checkName {
-	case episodeQACheckMcapMagic:
-		return h.runMcapMagicQACheck(ctx, row)
-	case episodeQACheckRecordingNotEmpty:
-		return h.runRecordingNotEmptyQACheck(ctx, row)
-	default:
-		return episodeQACheckOutcome{}, fmt.Errorf("unsupported qa check %q", checkName)
+	outcome, err := check.Run(ctx, h, row, params)
+	if err != nil {
+		return outcome, err
 	}
+	if outcome.CheckName == "" {
+		outcome.CheckName = normalizeEpisodeQACheckName(check.Name())
+	}
+	return outcome, nil
 }
 
 func (h *EpisodeQAHandler) runMcapMagicQACheck(ctx context.Context, row episodeQACheckRow) (episodeQACheckOutcome, error) {
@@ -940,7 +1127,7 @@ func spacedHex(data []byte) string {
 	return strings.Join(parts, " ")
 }
 
-func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, claim episodeQARunClaim, mode QARunMode, outcomes []episodeQACheckOutcome, checkedAt time.Time) (*EpisodeQASuiteResponse, error) {
+func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, claim episodeQARunClaim, mode QARunMode, plan episodeQASuitePlan, outcomes []episodeQACheckOutcome, checkedAt time.Time) (*EpisodeQASuiteResponse, error) {
 	if h.db == nil {
 		return nil, fmt.Errorf("database is not configured")
 	}
@@ -953,16 +1140,11 @@ func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, clai
 
 	checks := make([]EpisodeQACheckRecordResponse, 0, len(outcomes))
 	allPassed := true
-	scoreSum := 0.0
-	failureDetails := ""
 	for _, outcome := range outcomes {
 		if !outcome.Passed {
 			allPassed = false
-			if failureDetails == "" {
-				failureDetails = outcome.Details
-			}
 		}
-		scoreSum += outcome.Score
+		weight := h.planCheckWeight(plan, outcome.CheckName)
 
 		metadataJSON, err := json.Marshal(outcome.Metadata)
 		if err != nil {
@@ -971,9 +1153,12 @@ func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, clai
 
 		// #nosec G701 -- static SQL with placeholder-bound QA check values.
 		res, err := tx.ExecContext(ctx, `
-			INSERT INTO qa_checks (episode_id, check_name, passed, score, details, check_metadata, checked_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?)
-		`, claim.EpisodeID, outcome.CheckName, outcome.Passed, outcome.Score, outcome.Details, string(metadataJSON), checkedAt)
+			INSERT INTO qa_checks (episode_id, check_name, passed, score, weight, details, check_metadata, script_id, script_version, checked_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, claim.EpisodeID, outcome.CheckName, outcome.Passed, outcome.Score, weight, outcome.Details, string(metadataJSON),
+			sql.NullInt64{Int64: outcome.ScriptID, Valid: outcome.ScriptID > 0},
+			sql.NullString{String: outcome.ScriptVersion, Valid: outcome.ScriptID > 0},
+			checkedAt)
 		if err != nil {
 			return nil, fmt.Errorf("insert qa_check: %w", err)
 		}
@@ -987,44 +1172,34 @@ func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, clai
 			CheckName:     outcome.CheckName,
 			Passed:        outcome.Passed,
 			Score:         outcome.Score,
+			Weight:        weight,
 			Details:       outcome.Details,
 			CheckMetadata: outcome.Metadata,
+			ScriptID:      outcome.ScriptID,
+			ScriptVersion: outcome.ScriptVersion,
 			CheckedAt:     checkedAt.Format(time.RFC3339),
 		})
 	}
 
-	score := 0.0
-	if len(outcomes) > 0 {
-		score = scoreSum / float64(len(outcomes))
-	}
-
-	finalStatus := claim.OriginalStatus
-	if allPassed {
-		finalStatus = qaStatusApproved
-	} else if failureDetails != "" {
-		finalStatus = qaStatusFailed
+	score := weightedEpisodeQAScore(outcomes, func(checkName string) float64 {
+		return h.planCheckWeight(plan, checkName)
+	})
+	threshold := h.autoApproveThreshold()
+	if plan.Threshold > 0 {
+		threshold = plan.Threshold
 	}
+	finalStatus, qualityFlag := decideEpisodeQAStatus(outcomes, score, threshold, h.checkRegistry().isBlocking)
+	flag := sql.NullString{String: qualityFlag, Valid: qualityFlag != ""}
 
 	if claim.MutableStatus {
-		if allPassed {
-			if mode == qaRunModeAuto {
-				// #nosec G701 -- static SQL with placeholder-bound episode QA values.
-				if _, err := tx.ExecContext(ctx, `
-					UPDATE episodes
-					SET qa_status = ?, qa_score = ?, quality_flag = NULL, auto_approved = ?
-					WHERE id = ? AND deleted_at IS NULL AND qa_status = ?
-				`, qaStatusApproved, score, 1, claim.EpisodeID, qaStatusRunning); err != nil {
-					return nil, fmt.Errorf("mark episode qa approved: %w", err)
-				}
-			} else {
-				// #nosec G701 -- static SQL with placeholder-bound episode QA values.
-				if _, err := tx.ExecContext(ctx, `
-					UPDATE episodes
-					SET qa_status = ?, qa_score = ?, quality_flag = NULL
-					WHERE id = ? AND deleted_at IS NULL AND qa_status = ?
-				`, qaStatusApproved, score, claim.EpisodeID, qaStatusRunning); err != nil {
-					return nil, fmt.Errorf("mark episode qa approved: %w", err)
-				}
+		if finalStatus == qaStatusApproved && mode == qaRunModeAuto {
+			// #nosec G701 -- static SQL with placeholder-bound episode QA values.
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE episodes
+				SET qa_status = ?, qa_score = ?, quality_flag = ?, auto_approved = ?
+				WHERE id = ? AND deleted_at IS NULL AND qa_status = ?
+			`, finalStatus, score, flag, 1, claim.EpisodeID, qaStatusRunning); err != nil {
+				return nil, fmt.Errorf("mark episode qa %s: %w", finalStatus, err)
 			}
 		} else {
 			// #nosec G701 -- static SQL with placeholder-bound episode QA values.
@@ -1032,18 +1207,22 @@ func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, clai
 				UPDATE episodes
 				SET qa_status = ?, qa_score = ?, quality_flag = ?
 				WHERE id = ? AND deleted_at IS NULL AND qa_status = ?
-			`, qaStatusFailed, score, failureDetails, claim.EpisodeID, qaStatusRunning); err != nil {
-				return nil, fmt.Errorf("mark episode qa failed: %w", err)
+			`, finalStatus, score, flag, claim.EpisodeID, qaStatusRunning); err != nil {
+				return nil, fmt.Errorf("mark episode qa %s: %w", finalStatus, err)
 			}
 		}
+		if err := recordStateTransitions(tx, stateEntityEpisode, []stateRow{{ID: claim.EpisodeID, State: qaStatusRunning}}, finalStatus,
+			qaRunTransitionActor(mode), map[string]any{"score": score, "quality_flag": qualityFlag}); err != nil {
+			return nil, err
+		}
 	} else {
-		if failureDetails != "" {
+		if flag.Valid {
 			// #nosec G701 -- static SQL with placeholder-bound episode QA values.
 			if _, err := tx.ExecContext(ctx, `
 				UPDATE episodes
 				SET qa_score = ?, quality_flag = ?
 				WHERE id = ? AND deleted_at IS NULL
-			`, score, failureDetails, claim.EpisodeID); err != nil {
+			`, score, flag, claim.EpisodeID); err != nil {
 				return nil, fmt.Errorf("write protected episode qa failure details: %w", err)
 			}
 		}
@@ -1054,11 +1233,19 @@ func (h *EpisodeQAHandler) persistEpisodeQASuiteResult(ctx context.Context, clai
 		return nil, fmt.Errorf("commit qa check transaction: %w", err)
 	}
 
+	if claim.MutableStatus && finalStatus == qaStatusApproved && mode == qaRunModeAuto && h.auditSamplingEnabled() {
+		if err := h.sampleAutoApprovedEpisode(ctx, claim.EpisodeID, score, outcomes); err != nil {
+			logger.Printf("[EPISODE-QA] Failed to sample auto-approved episode for audit: episode=%d, err=%v", claim.EpisodeID, err)
+		}
+	}
+
 	return &EpisodeQASuiteResponse{
 		EpisodeID: claim.EpisodeID,
 		QAStatus:  finalStatus,
 		Passed:    allPassed,
+		Score:     score,
 		Mode:      mode,
+		ProfileID: plan.ProfileID,
 		Checks:    checks,
 	}, nil
 }
@@ -1161,8 +1348,11 @@ func qaCheckRecordFromDBRow(row episodeQACheckDBRow) EpisodeQACheckRecordRespons
 		CheckName:     row.CheckName,
 		Passed:        row.Passed,
 		Score:         row.Score,
+		Weight:        row.Weight,
 		Details:       nullStringValue(row.Details),
 		CheckMetadata: parseQACheckMetadata(row.CheckMetadata),
+		ScriptID:      row.ScriptID.Int64,
+		ScriptVersion: nullStringValue(row.ScriptVersion),
 		CheckedAt:     checkedAt,
 	}
 }
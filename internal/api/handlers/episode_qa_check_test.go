@@ -12,6 +12,8 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	_ "modernc.org/sqlite"
+
+	"archebase.com/keystone-edge/internal/config"
 )
 
 func TestEvaluateMcapMagicCheck(t *testing.T) {
@@ -172,7 +174,7 @@ func TestEvaluateRecordingNotEmptyCheck(t *testing.T) {
 
 func TestDefaultEpisodeQASuiteIncludesRecordingNotEmpty(t *testing.T) {
 	got := defaultEpisodeQASuite(episodeQACheckRow{})
-	want := []string{episodeQACheckMcapMagic, episodeQACheckRecordingNotEmpty}
+	want := []string{episodeQACheckRequiredTopics, episodeQACheckRecordingNotEmpty, episodeQACheckMessageGaps, episodeQACheckImageIntegrity}
 	if len(got) != len(want) {
 		t.Fatalf("suite length = %d, want %d: %v", len(got), len(want), got)
 	}
@@ -210,7 +212,7 @@ func TestPersistEpisodeQACheckFailureMarksEpisodeFailed(t *testing.T) {
 		OriginalStatus: qaStatusApproved,
 		MutableStatus:  true,
 	}
-	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, []episodeQACheckOutcome{outcome}, time.Now().UTC())
+	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, episodeQASuitePlan{}, []episodeQACheckOutcome{outcome}, time.Now().UTC())
 	if err != nil {
 		t.Fatalf("persist qa check: %v", err)
 	}
@@ -267,7 +269,7 @@ func TestPersistEpisodeQACheckManualSuccessRestoresFailedEpisode(t *testing.T) {
 		OriginalStatus: qaStatusFailed,
 		MutableStatus:  true,
 	}
-	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, []episodeQACheckOutcome{outcome}, time.Now().UTC())
+	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, episodeQASuitePlan{}, []episodeQACheckOutcome{outcome}, time.Now().UTC())
 	if err != nil {
 		t.Fatalf("persist qa check: %v", err)
 	}
@@ -316,7 +318,7 @@ func TestPersistEpisodeQACheckAutoSuccessAutoApprovesEpisode(t *testing.T) {
 		OriginalStatus: qaStatusPendingQA,
 		MutableStatus:  true,
 	}
-	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeAuto, []episodeQACheckOutcome{outcome}, time.Now().UTC())
+	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeAuto, episodeQASuitePlan{}, []episodeQACheckOutcome{outcome}, time.Now().UTC())
 	if err != nil {
 		t.Fatalf("persist qa check: %v", err)
 	}
@@ -369,7 +371,7 @@ func TestPersistEpisodeQACheckDoesNotOverrideProtectedManualStatus(t *testing.T)
 		OriginalStatus: qaStatusNeedsInspection,
 		MutableStatus:  false,
 	}
-	if _, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, []episodeQACheckOutcome{outcome}, time.Now().UTC()); err != nil {
+	if _, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeManual, episodeQASuitePlan{}, []episodeQACheckOutcome{outcome}, time.Now().UTC()); err != nil {
 		t.Fatalf("persist qa check: %v", err)
 	}
 
@@ -409,6 +411,121 @@ func TestClaimEpisodeQARunReturnsConflictWhenRunning(t *testing.T) {
 	}
 }
 
+func TestPersistEpisodeQASuiteBelowThresholdNeedsInspection(t *testing.T) {
+	db := setupEpisodeQACheckTestDB(t)
+	handler := &EpisodeQAHandler{
+		db:      db,
+		qaCfg:   &config.QAConfig{AutoApproveThreshold: 0.9},
+		weights: map[string]float64{episodeQACheckImageIntegrity: 3},
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO episodes (id, qa_status, quality_flag, auto_approved, deleted_at)
+		VALUES (1, 'qa_running', NULL, 0, NULL)
+	`)
+	if err != nil {
+		t.Fatalf("insert episode: %v", err)
+	}
+
+	outcomes := []episodeQACheckOutcome{
+		{CheckName: episodeQACheckMcapMagic, Passed: true, Score: 1, Details: "MCAP head and tail magic matched"},
+		{CheckName: episodeQACheckImageIntegrity, Passed: false, Score: 0.8, Details: "Image integrity check failed: 4 of 20 sampled frames did not decode on /cam"},
+	}
+	claim := episodeQARunClaim{EpisodeID: 1, OriginalStatus: qaStatusPendingQA, MutableStatus: true}
+	result, err := handler.persistEpisodeQASuiteResult(context.Background(), claim, qaRunModeAuto, episodeQASuitePlan{}, outcomes, time.Now().UTC())
+	if err != nil {
+		t.Fatalf("persist qa suite: %v", err)
+	}
+	if result.QAStatus != qaStatusNeedsInspection {
+		t.Fatalf("result qa_status = %q, want needs_inspection", result.QAStatus)
+	}
+	if result.Score != 0.85 {
+		t.Fatalf("result score = %v, want weighted 0.85", result.Score)
+	}
+
+	var episode struct {
+		QaStatus     string  `db:"qa_status"`
+		QaScore      float64 `db:"qa_score"`
+		QualityFlag  string  `db:"quality_flag"`
+		AutoApproved bool    `db:"auto_approved"`
+	}
+	if err := db.Get(&episode, "SELECT qa_status, qa_score, quality_flag, auto_approved FROM episodes WHERE id = 1"); err != nil {
+		t.Fatalf("query episode: %v", err)
+	}
+	if episode.QaStatus != qaStatusNeedsInspection || episode.QaScore != 0.85 || episode.AutoApproved {
+		t.Fatalf("unexpected episode: %+v", episode)
+	}
+	if episode.QualityFlag != outcomes[1].Details {
+		t.Fatalf("quality_flag = %q, want %q", episode.QualityFlag, outcomes[1].Details)
+	}
+
+	var weight float64
+	if err := db.Get(&weight, "SELECT weight FROM qa_checks WHERE episode_id = 1 AND check_name = ?", episodeQACheckImageIntegrity); err != nil {
+		t.Fatalf("query qa_check weight: %v", err)
+	}
+	if weight != 3 {
+		t.Fatalf("qa_check weight = %v, want 3", weight)
+	}
+}
+
+func TestDecideEpisodeQAStatus(t *testing.T) {
+	soft := episodeQACheckOutcome{CheckName: episodeQACheckMessageGaps, Passed: false, Score: 0.9, Details: "gap"}
+	blocking := episodeQACheckOutcome{CheckName: episodeQACheckMcapStructure, Passed: false, Score: 0.95, Details: "corrupt chunk"}
+	ok := episodeQACheckOutcome{CheckName: episodeQACheckMcapMagic, Passed: true, Score: 1}
+
+	tests := []struct {
+		name       string
+		outcomes   []episodeQACheckOutcome
+		score      float64
+		wantStatus string
+		wantFlag   string
+	}{
+		{name: "all passed above threshold", outcomes: []episodeQACheckOutcome{ok}, score: 1, wantStatus: qaStatusApproved},
+		{name: "soft failure above threshold keeps flag", outcomes: []episodeQACheckOutcome{ok, soft}, score: 0.95, wantStatus: qaStatusApproved, wantFlag: "gap"},
+		{name: "soft failure below threshold", outcomes: []episodeQACheckOutcome{ok, soft}, score: 0.85, wantStatus: qaStatusNeedsInspection, wantFlag: "gap"},
+		{name: "blocking failure", outcomes: []episodeQACheckOutcome{ok, blocking}, score: 0.975, wantStatus: qaStatusFailed, wantFlag: "corrupt chunk"},
+		{
+			name:       "passing checks below threshold",
+			outcomes:   []episodeQACheckOutcome{ok},
+			score:      0.5,
+			wantStatus: qaStatusNeedsInspection,
+			wantFlag:   "QA score 0.500 is below auto-approve threshold 0.900",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, flag := decideEpisodeQAStatus(tt.outcomes, tt.score, 0.9, builtinQAChecks.isBlocking)
+			if status != tt.wantStatus || flag != tt.wantFlag {
+				t.Fatalf("got (%q, %q), want (%q, %q)", status, flag, tt.wantStatus, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func TestResolveEpisodeQASuite(t *testing.T) {
+	suite, unknown := resolveEpisodeQASuite([]string{"mcap_magic", "Topics", " gaps ", "images", "duration", "required_topics", "bogus", ""}, builtinQAChecks)
+	want := []string{episodeQACheckMcapMagic, episodeQACheckRequiredTopics, episodeQACheckMessageGaps, episodeQACheckImageIntegrity, episodeQACheckRecordingNotEmpty}
+	if len(suite) != len(want) {
+		t.Fatalf("suite = %v, want %v", suite, want)
+	}
+	for i := range want {
+		if suite[i] != want[i] {
+			t.Fatalf("suite[%d] = %q, want %q", i, suite[i], want[i])
+		}
+	}
+	if len(unknown) != 1 || unknown[0] != "bogus" {
+		t.Fatalf("unknown = %v, want [bogus]", unknown)
+	}
+
+	weights := resolveEpisodeQACheckWeights(map[string]float64{"images": 2, "mcap_magic": 50, "gaps": -1})
+	if weights[episodeQACheckImageIntegrity] != 2 || weights[episodeQACheckMcapMagic] != maxEpisodeQACheckWeight {
+		t.Fatalf("weights = %v", weights)
+	}
+	if _, ok := weights[episodeQACheckMessageGaps]; ok {
+		t.Fatalf("negative weight should be ignored: %v", weights)
+	}
+}
+
 func setupEpisodeQACheckTestDB(t *testing.T) *sqlx.DB {
 	t.Helper()
 
@@ -439,8 +556,11 @@ func setupEpisodeQACheckTestDB(t *testing.T) *sqlx.DB {
 			check_name TEXT NOT NULL,
 			passed BOOLEAN NOT NULL,
 			score REAL NOT NULL,
+			weight REAL NOT NULL DEFAULT 1,
 			details TEXT,
 			check_metadata TEXT,
+			script_id INTEGER NULL,
+			script_version TEXT NULL,
 			checked_at TIMESTAMP
 		);
 	`)
@@ -448,5 +568,6 @@ func setupEpisodeQACheckTestDB(t *testing.T) *sqlx.DB {
 		t.Fatalf("create schema: %v", err)
 	}
 
+	createStateTransitionsTestTable(t, db)
 	return db
 }
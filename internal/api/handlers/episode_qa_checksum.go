@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	episodeQACheckChecksumMatch = "checksum_match"
+
+	checksumSourceEpisode = "episode"
+	checksumSourceSidecar = "sidecar"
+)
+
+// checksumMatchParams are QA profile parameters for checksum_match.
+type checksumMatchParams struct {
+	// RequireChecksum fails episodes without a recorded checksum instead of
+	// skipping them.
+	RequireChecksum *bool `json:"require_checksum"`
+}
+
+func validateChecksumMatchParams(params json.RawMessage) error {
+	var p checksumMatchParams
+	return decodeQACheckParams(params, &p)
+}
+
+// normalizeSHA256Hex accepts "<hex>" or "sha256:<hex>" in any case and
+// returns the lowercase digest, or "" when raw is not a SHA-256 digest.
+func normalizeSHA256Hex(raw string) string {
+	digest := strings.ToLower(strings.TrimSpace(raw))
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) != sha256.Size*2 {
+		return ""
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return ""
+	}
+	return digest
+}
+
+// loadEpisodeExpectedChecksum returns the recorded MCAP digest, preferring
+// episodes.checksum and falling back to the sidecar's recording block.
+func (h *EpisodeQAHandler) loadEpisodeExpectedChecksum(ctx context.Context, row episodeQACheckRow) (string, string, error) {
+	if h.db != nil {
+		var checksum sql.NullString
+		err := h.db.GetContext(ctx, &checksum, "SELECT checksum FROM episodes WHERE id = ? AND deleted_at IS NULL", row.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return "", "", fmt.Errorf("query episode checksum: %w", err)
+		}
+		if digest := normalizeSHA256Hex(checksum.String); digest != "" {
+			return digest, checksumSourceEpisode, nil
+		}
+	}
+	if sidecar, ok := h.readEpisodeSidecar(ctx, row); ok {
+		if digest := normalizeSHA256Hex(sidecar.Recording.ChecksumSHA256); digest != "" {
+			return digest, checksumSourceSidecar, nil
+		}
+	}
+	return "", "", nil
+}
+
+func (h *EpisodeQAHandler) prepareChecksumMatchScan(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error) {
+	var p checksumMatchParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return nil, err
+	}
+	expected, source, err := h.loadEpisodeExpectedChecksum(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if expected == "" {
+		requireChecksum := p.RequireChecksum != nil && *p.RequireChecksum
+		return &mcapScanConsumer{
+			finish: func(*mcapScanPass) (episodeQACheckOutcome, error) {
+				return evaluateChecksumMatch("", "", "", requireChecksum, map[string]any{}), nil
+			},
+		}, nil
+	}
+	return &mcapScanConsumer{
+		wantsSHA256: true,
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			metadata := pass.checkMetadata()
+			if pass.reason != "" {
+				metadata["expected_sha256"] = expected
+				metadata["expected_source"] = source
+				return checksumMatchFailure("Checksum check failed: "+pass.reason, metadata), nil
+			}
+			return evaluateChecksumMatch(expected, source, pass.sha256, false, metadata), nil
+		},
+	}, nil
+}
+
+// evaluateChecksumMatch compares the recorded and computed digests. Episodes
+// without a recorded digest pass as skipped unless requireChecksum is set.
+func evaluateChecksumMatch(expected, source, actual string, requireChecksum bool, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	if expected == "" {
+		metadata["status"] = "skipped"
+		if requireChecksum {
+			return checksumMatchFailure("Checksum check failed: no recorded SHA-256 checksum", metadata)
+		}
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckChecksumMatch,
+			Passed:    true,
+			Score:     1,
+			Details:   "No recorded SHA-256 checksum; checksum verification skipped",
+			Metadata:  metadata,
+		}
+	}
+
+	metadata["expected_sha256"] = expected
+	metadata["expected_source"] = source
+	metadata["actual_sha256"] = actual
+	if actual != expected {
+		metadata["status"] = "mismatch"
+		return checksumMatchFailure(fmt.Sprintf("Checksum mismatch: stored MCAP sha256 %s does not match recorded %s", actual, expected), metadata)
+	}
+	metadata["status"] = "ok"
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckChecksumMatch,
+		Passed:    true,
+		Score:     1,
+		Details:   "Stored MCAP matches recorded SHA-256 checksum",
+		Metadata:  metadata,
+	}
+}
+
+func checksumMatchFailure(details string, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckChecksumMatch,
+		Passed:    false,
+		Score:     0,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
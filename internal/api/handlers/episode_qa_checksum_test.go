@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSHA256Hex(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	tests := map[string]string{
+		digest:                               digest,
+		" SHA256:" + strings.ToUpper(digest): digest,
+		"sha256:" + digest[:10]:              "",
+		strings.Repeat("zz", 32):             "",
+		"":                                   "",
+	}
+	for in, want := range tests {
+		if got := normalizeSHA256Hex(in); got != want {
+			t.Fatalf("normalizeSHA256Hex(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEvaluateChecksumMatch(t *testing.T) {
+	expected := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+	tests := []struct {
+		name       string
+		expected   string
+		actual     string
+		require    bool
+		wantPassed bool
+		wantStatus string
+		wantDetail string
+	}{
+		{"match", expected, expected, false, true, "ok", "matches"},
+		{"mismatch", expected, other, false, false, "mismatch", "Checksum mismatch"},
+		{"no checksum skipped", "", "", false, true, "skipped", "skipped"},
+		{"no checksum required", "", "", true, false, "skipped", "no recorded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := evaluateChecksumMatch(tt.expected, checksumSourceEpisode, tt.actual, tt.require, nil)
+			if outcome.CheckName != episodeQACheckChecksumMatch || outcome.Passed != tt.wantPassed {
+				t.Fatalf("outcome = %+v", outcome)
+			}
+			if outcome.Metadata["status"] != tt.wantStatus || !strings.Contains(outcome.Details, tt.wantDetail) {
+				t.Fatalf("status = %v, details = %q", outcome.Metadata["status"], outcome.Details)
+			}
+		})
+	}
+	if !builtinQAChecks.isBlocking(episodeQACheckChecksumMatch) {
+		t.Fatal("checksum_match should be blocking")
+	}
+	if err := validateChecksumMatchParams([]byte(`{"require_checksum":true}`)); err != nil {
+		t.Fatalf("validate params: %v", err)
+	}
+	if err := validateChecksumMatchParams([]byte(`{"bogus":1}`)); err == nil {
+		t.Fatal("unknown param accepted")
+	}
+}
@@ -0,0 +1,352 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+const (
+	episodeQACheckMessageGaps = "message_gaps"
+
+	defaultEpisodeQAGapMinRateRatio = 0.8
+	defaultEpisodeQAGapMaxSeconds   = 1.0
+
+	// maxEpisodeQAGapIntervalSamples bounds the intervals kept per topic to
+	// estimate its nominal rate; longer recordings are decimated evenly.
+	maxEpisodeQAGapIntervalSamples = 4096
+)
+
+// messageGapThresholds are the pass criteria for the message gap check.
+type messageGapThresholds struct {
+	MinRateRatio  float64
+	MaxGapSeconds float64
+	// ExpectedHz is keyed by normalized topic name.
+	ExpectedHz map[string]float64
+}
+
+// messageGapParams are QA profile parameters for message_gaps; set fields
+// override the configured thresholds.
+type messageGapParams struct {
+	MinRateRatio  *float64           `json:"min_rate_ratio"`
+	MaxGapSeconds *float64           `json:"max_gap_sec"`
+	ExpectedHz    map[string]float64 `json:"expected_hz"`
+}
+
+func validateMessageGapParams(params json.RawMessage) error {
+	var p messageGapParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return err
+	}
+	if p.MinRateRatio != nil && (*p.MinRateRatio <= 0 || *p.MinRateRatio > 1) {
+		return fmt.Errorf("min_rate_ratio must be in (0, 1]")
+	}
+	if p.MaxGapSeconds != nil && *p.MaxGapSeconds <= 0 {
+		return fmt.Errorf("max_gap_sec must be greater than 0")
+	}
+	for topic, hz := range p.ExpectedHz {
+		if normalizeTopicName(topic) == "" || hz <= 0 {
+			return fmt.Errorf("expected_hz entries need a topic and a rate greater than 0")
+		}
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) messageGapThresholds(params json.RawMessage) (messageGapThresholds, error) {
+	thresholds := messageGapThresholds{
+		MinRateRatio:  defaultEpisodeQAGapMinRateRatio,
+		MaxGapSeconds: defaultEpisodeQAGapMaxSeconds,
+		ExpectedHz:    map[string]float64{},
+	}
+	if h.qaCfg != nil {
+		if h.qaCfg.GapMinRateRatio > 0 {
+			thresholds.MinRateRatio = h.qaCfg.GapMinRateRatio
+		}
+		if h.qaCfg.GapMaxSeconds > 0 {
+			thresholds.MaxGapSeconds = h.qaCfg.GapMaxSeconds
+		}
+		for topic, hz := range h.qaCfg.ExpectedTopicHz {
+			if name := normalizeTopicName(topic); name != "" && hz > 0 {
+				thresholds.ExpectedHz[name] = hz
+			}
+		}
+	}
+
+	if err := validateMessageGapParams(params); err != nil {
+		return thresholds, err
+	}
+	var p messageGapParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return thresholds, err
+	}
+	if p.MinRateRatio != nil {
+		thresholds.MinRateRatio = *p.MinRateRatio
+	}
+	if p.MaxGapSeconds != nil {
+		thresholds.MaxGapSeconds = *p.MaxGapSeconds
+	}
+	for topic, hz := range p.ExpectedHz {
+		thresholds.ExpectedHz[normalizeTopicName(topic)] = hz
+	}
+	return thresholds, nil
+}
+
+// topicTimingStats summarizes message log times on one topic.
+type topicTimingStats struct {
+	Topic            string
+	MessageCount     int64
+	FirstLogTime     uint64
+	LastLogTime      uint64
+	MaxGapNs         uint64
+	MaxGapAt         uint64
+	MedianIntervalNs uint64
+}
+
+// topicTimingAccumulator folds log times into topicTimingStats in one pass.
+type topicTimingAccumulator struct {
+	stats     topicTimingStats
+	prev      uint64
+	intervals []uint64
+	stride    int
+	skip      int
+}
+
+func newTopicTimingAccumulator(topic string) *topicTimingAccumulator {
+	return &topicTimingAccumulator{stats: topicTimingStats{Topic: topic}, stride: 1}
+}
+
+func (a *topicTimingAccumulator) add(logTime uint64) {
+	s := &a.stats
+	s.MessageCount++
+	if s.MessageCount == 1 {
+		s.FirstLogTime, s.LastLogTime, a.prev = logTime, logTime, logTime
+		return
+	}
+	if logTime < s.FirstLogTime {
+		s.FirstLogTime = logTime
+	}
+	if logTime > s.LastLogTime {
+		s.LastLogTime = logTime
+	}
+	// Out-of-order messages do not open or close gaps.
+	if logTime < a.prev {
+		return
+	}
+	delta := logTime - a.prev
+	a.prev = logTime
+	if delta > s.MaxGapNs {
+		s.MaxGapNs = delta
+		s.MaxGapAt = logTime
+	}
+	a.sampleInterval(delta)
+}
+
+func (a *topicTimingAccumulator) sampleInterval(delta uint64) {
+	if a.skip > 0 {
+		a.skip--
+		return
+	}
+	a.intervals = append(a.intervals, delta)
+	a.skip = a.stride - 1
+	if len(a.intervals) < maxEpisodeQAGapIntervalSamples {
+		return
+	}
+	kept := a.intervals[:0]
+	for i := 0; i < len(a.intervals); i += 2 {
+		kept = append(kept, a.intervals[i])
+	}
+	a.intervals = kept
+	a.stride *= 2
+	a.skip = a.stride - 1
+}
+
+func (a *topicTimingAccumulator) result() topicTimingStats {
+	stats := a.stats
+	if len(a.intervals) > 0 {
+		sorted := append([]uint64(nil), a.intervals...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats.MedianIntervalNs = sorted[len(sorted)/2]
+	}
+	return stats
+}
+
+// topicTimingCollector accumulates message log times per topic during a
+// scan. Corrupt chunks are skipped and counted.
+type topicTimingCollector struct {
+	topics        map[uint16]string
+	accumulators  map[string]*topicTimingAccumulator
+	corruptChunks int
+}
+
+func newTopicTimingCollector() *topicTimingCollector {
+	return &topicTimingCollector{topics: map[uint16]string{}, accumulators: map[string]*topicTimingAccumulator{}}
+}
+
+func (c *topicTimingCollector) visitor() mcap.Visitor {
+	return mcap.Visitor{
+		Channel: func(ch *mcap.Channel) error {
+			c.topics[ch.ID] = ch.Topic
+			return nil
+		},
+		Message: func(m *mcap.Message) error {
+			topic, ok := c.topics[m.ChannelID]
+			if !ok {
+				topic = fmt.Sprintf("channel:%d", m.ChannelID)
+			}
+			acc, ok := c.accumulators[topic]
+			if !ok {
+				acc = newTopicTimingAccumulator(topic)
+				c.accumulators[topic] = acc
+			}
+			acc.add(m.LogTime)
+			return nil
+		},
+		Chunk: func(_ *mcap.Chunk, chunkErr error) error {
+			if chunkErr != nil {
+				c.corruptChunks++
+			}
+			return nil
+		},
+	}
+}
+
+func (c *topicTimingCollector) stats() []topicTimingStats {
+	stats := make([]topicTimingStats, 0, len(c.accumulators))
+	for _, acc := range c.accumulators {
+		stats = append(stats, acc.result())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Topic < stats[j].Topic })
+	return stats
+}
+
+func (h *EpisodeQAHandler) prepareMessageGapsScan(_ context.Context, _ episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error) {
+	thresholds, err := h.messageGapThresholds(params)
+	if err != nil {
+		return nil, err
+	}
+	collector := newTopicTimingCollector()
+	return &mcapScanConsumer{
+		visitor: func(*mcap.Summary) mcap.Visitor { return collector.visitor() },
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			metadata := pass.checkMetadata()
+			if pass.reason != "" {
+				return messageGapsFailure("Message gap check failed: "+pass.reason, 0, metadata), nil
+			}
+			metadata["corrupt_chunk_count"] = collector.corruptChunks
+			if pass.scanErr != nil {
+				metadata["scan_error"] = pass.scanErr.Error()
+				return messageGapsFailure("Message gap check failed: "+pass.scanErr.Error(), 0, metadata), nil
+			}
+			return evaluateMessageGapsCheck(collector.stats(), thresholds, metadata), nil
+		},
+	}, nil
+}
+
+func evaluateMessageGapsCheck(stats []topicTimingStats, thresholds messageGapThresholds, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["min_rate_ratio"] = thresholds.MinRateRatio
+	metadata["max_gap_sec"] = thresholds.MaxGapSeconds
+
+	items := make([]map[string]any, 0, len(stats))
+	failedTopics := []string{}
+	assessed := 0
+	for _, s := range stats {
+		item := map[string]any{
+			"topic":         s.Topic,
+			"message_count": s.MessageCount,
+		}
+		items = append(items, item)
+
+		durationSec := float64(s.LastLogTime-s.FirstLogTime) / 1e9
+		if s.MessageCount < 2 || durationSec <= 0 {
+			item["status"] = "insufficient_messages"
+			continue
+		}
+		assessed++
+
+		effectiveHz := float64(s.MessageCount-1) / durationSec
+		expectedHz, source := thresholds.ExpectedHz[normalizeTopicName(s.Topic)], "config"
+		if expectedHz <= 0 && s.MedianIntervalNs > 0 {
+			expectedHz, source = 1e9/float64(s.MedianIntervalNs), "inferred"
+		}
+		// A gap of two nominal periods is never flagged, so slow topics are
+		// not failed for their normal spacing.
+		gapLimit := thresholds.MaxGapSeconds
+		if expectedHz > 0 && 2/expectedHz > gapLimit {
+			gapLimit = 2 / expectedHz
+		}
+		maxGapSec := float64(s.MaxGapNs) / 1e9
+
+		item["duration_sec"] = roundQAFloat(durationSec)
+		item["effective_hz"] = roundQAFloat(effectiveHz)
+		item["max_gap_sec"] = roundQAFloat(maxGapSec)
+		item["max_gap_at"] = s.MaxGapAt
+		item["gap_limit_sec"] = roundQAFloat(gapLimit)
+		if expectedHz > 0 {
+			item["expected_hz"] = roundQAFloat(expectedHz)
+			item["expected_hz_source"] = source
+		}
+
+		reasons := []string{}
+		if expectedHz > 0 && effectiveHz < thresholds.MinRateRatio*expectedHz {
+			reasons = append(reasons, "rate_below_threshold")
+		}
+		if maxGapSec > gapLimit {
+			reasons = append(reasons, "gap_exceeds_threshold")
+		}
+		if len(reasons) > 0 {
+			item["status"] = "failed"
+			item["reasons"] = reasons
+			failedTopics = append(failedTopics, s.Topic)
+			continue
+		}
+		item["status"] = "ok"
+	}
+	metadata["topics"] = items
+	metadata["assessed_topic_count"] = assessed
+	metadata["failed_topics"] = failedTopics
+
+	if len(stats) == 0 {
+		return messageGapsFailure("Message gap check failed: no messages found", 0, metadata)
+	}
+	if len(failedTopics) > 0 {
+		score := float64(assessed-len(failedTopics)) / float64(assessed)
+		return messageGapsFailure(fmt.Sprintf("Message gap check failed: %d of %d topics dropped messages: %s", len(failedTopics), assessed, strings.Join(failedTopics, ", ")), score, metadata)
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckMessageGaps,
+		Passed:    true,
+		Score:     1,
+		Details:   fmt.Sprintf("Message rates and gaps within thresholds on %d topics", assessed),
+		Metadata:  metadata,
+	}
+}
+
+func messageGapsFailure(details string, score float64, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckMessageGaps,
+		Passed:    false,
+		Score:     score,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
+
+// roundQAFloat keeps persisted check metadata readable.
+func roundQAFloat(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func timingStatsFor(topic string, logTimes ...uint64) topicTimingStats {
+	acc := newTopicTimingAccumulator(topic)
+	for _, ts := range logTimes {
+		acc.add(ts)
+	}
+	return acc.result()
+}
+
+// evenLogTimes returns n log times spaced by intervalNs, skipping the given indexes.
+func evenLogTimes(n int, intervalNs uint64, drop map[int]bool) []uint64 {
+	out := make([]uint64, 0, n)
+	for i := 0; i < n; i++ {
+		if drop[i] {
+			continue
+		}
+		out = append(out, uint64(i)*intervalNs)
+	}
+	return out
+}
+
+func TestTopicTimingAccumulator(t *testing.T) {
+	got := timingStatsFor("/camera", 0, 100, 200, 150, 700, 800)
+	if got.MessageCount != 6 {
+		t.Fatalf("message count = %d, want 6", got.MessageCount)
+	}
+	if got.FirstLogTime != 0 || got.LastLogTime != 800 {
+		t.Fatalf("range = %d..%d, want 0..800", got.FirstLogTime, got.LastLogTime)
+	}
+	if got.MaxGapNs != 500 || got.MaxGapAt != 700 {
+		t.Fatalf("max gap = %d at %d, want 500 at 700", got.MaxGapNs, got.MaxGapAt)
+	}
+	if got.MedianIntervalNs != 100 {
+		t.Fatalf("median interval = %d, want 100", got.MedianIntervalNs)
+	}
+}
+
+func TestTopicTimingAccumulatorDecimatesIntervals(t *testing.T) {
+	acc := newTopicTimingAccumulator("/imu")
+	for i := 0; i < maxEpisodeQAGapIntervalSamples*3; i++ {
+		acc.add(uint64(i) * 5)
+	}
+	if len(acc.intervals) >= maxEpisodeQAGapIntervalSamples {
+		t.Fatalf("kept %d intervals, want fewer than %d", len(acc.intervals), maxEpisodeQAGapIntervalSamples)
+	}
+	if got := acc.result().MedianIntervalNs; got != 5 {
+		t.Fatalf("median interval = %d, want 5", got)
+	}
+}
+
+func TestEvaluateMessageGapsCheck(t *testing.T) {
+	const frame = uint64(33_333_333) // ~30 Hz
+	thresholds := messageGapThresholds{MinRateRatio: 0.8, MaxGapSeconds: 1, ExpectedHz: map[string]float64{}}
+
+	// Dropping 45 consecutive frames opens a 1.5 s gap.
+	burst := map[int]bool{}
+	for i := 100; i < 145; i++ {
+		burst[i] = true
+	}
+	// Dropping every other frame halves the rate without a long gap.
+	halved := map[int]bool{}
+	for i := 1; i < 300; i += 2 {
+		halved[i] = true
+	}
+
+	tests := []struct {
+		name       string
+		stats      []topicTimingStats
+		thresholds messageGapThresholds
+		wantPassed bool
+		wantScore  float64
+		wantFailed []string
+	}{
+		{
+			name:       "steady topics pass",
+			stats:      []topicTimingStats{timingStatsFor("/camera", evenLogTimes(300, frame, nil)...), timingStatsFor("/slow", evenLogTimes(10, 1_500_000_000, nil)...)},
+			thresholds: thresholds,
+			wantPassed: true,
+			wantScore:  1,
+			wantFailed: []string{},
+		},
+		{
+			name:       "long gap fails topic",
+			stats:      []topicTimingStats{timingStatsFor("/camera", evenLogTimes(300, frame, burst)...), timingStatsFor("/joint_states", evenLogTimes(300, frame, nil)...)},
+			thresholds: thresholds,
+			wantPassed: false,
+			wantScore:  0.5,
+			wantFailed: []string{"/camera"},
+		},
+		{
+			name:  "configured rate catches uniform drop",
+			stats: []topicTimingStats{timingStatsFor("/camera", evenLogTimes(300, frame, halved)...)},
+			thresholds: messageGapThresholds{
+				MinRateRatio:  0.8,
+				MaxGapSeconds: 1,
+				ExpectedHz:    map[string]float64{"/camera": 30},
+			},
+			wantPassed: false,
+			wantScore:  0,
+			wantFailed: []string{"/camera"},
+		},
+		{
+			name:       "single message topic is not assessed",
+			stats:      []topicTimingStats{timingStatsFor("/camera", evenLogTimes(300, frame, nil)...), timingStatsFor("/calib", 5)},
+			thresholds: thresholds,
+			wantPassed: true,
+			wantScore:  1,
+			wantFailed: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateMessageGapsCheck(tt.stats, tt.thresholds, nil)
+			if got.CheckName != episodeQACheckMessageGaps {
+				t.Fatalf("check name = %q", got.CheckName)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Fatalf("passed = %v, want %v (%s)", got.Passed, tt.wantPassed, got.Details)
+			}
+			if got.Score != tt.wantScore {
+				t.Fatalf("score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if !reflect.DeepEqual(got.Metadata["failed_topics"], tt.wantFailed) {
+				t.Fatalf("failed_topics = %v, want %v", got.Metadata["failed_topics"], tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestEvaluateMessageGapsCheckFailsWithoutMessages(t *testing.T) {
+	got := evaluateMessageGapsCheck(nil, messageGapThresholds{MinRateRatio: 0.8, MaxGapSeconds: 1}, nil)
+	if got.Passed || got.Score != 0 {
+		t.Fatalf("passed = %v, score = %v, want failure with zero score", got.Passed, got.Score)
+	}
+}
@@ -0,0 +1,529 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG decoder for image.Decode
+	_ "image/png"  // register PNG decoder for image.Decode
+	"sort"
+	"strings"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+const (
+	episodeQACheckImageIntegrity = "image_integrity"
+
+	defaultEpisodeQAImageSampleSize = 20
+	// defaultEpisodeQAImageMaxPixels bounds decode allocations for frames
+	// with corrupt or hostile headers (about 8K x 5K).
+	defaultEpisodeQAImageMaxPixels = 40000000
+
+	// compressedDepthHeaderBytes is the compressed_depth_image_transport
+	// config header that precedes the PNG payload.
+	compressedDepthHeaderBytes = 12
+)
+
+var (
+	compressedImageSchemaNames = map[string]struct{}{
+		"sensor_msgs/msg/CompressedImage": {},
+		"sensor_msgs/CompressedImage":     {},
+	}
+
+	errCompressedImageTruncated = errors.New("compressed image message truncated")
+)
+
+// imageIntegrityThresholds are the sampling and pass criteria for the image check.
+type imageIntegrityThresholds struct {
+	SampleSize     int
+	MaxFailureRate float64
+	MaxPixels      int64
+	// TopicMaxFailureRate is keyed by normalized topic name.
+	TopicMaxFailureRate map[string]float64
+}
+
+// imageIntegrityParams are QA profile parameters for image_integrity; set
+// fields override the configured thresholds.
+type imageIntegrityParams struct {
+	SampleSize          *int               `json:"sample_size"`
+	MaxFailureRate      *float64           `json:"max_failure_rate"`
+	MaxPixels           *int64             `json:"max_pixels"`
+	TopicMaxFailureRate map[string]float64 `json:"topic_max_failure_rate"`
+}
+
+func validateImageIntegrityParams(params json.RawMessage) error {
+	var p imageIntegrityParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return err
+	}
+	if p.SampleSize != nil && *p.SampleSize <= 0 {
+		return fmt.Errorf("sample_size must be greater than 0")
+	}
+	if p.MaxFailureRate != nil && (*p.MaxFailureRate < 0 || *p.MaxFailureRate > 1) {
+		return fmt.Errorf("max_failure_rate must be in [0, 1]")
+	}
+	if p.MaxPixels != nil && *p.MaxPixels <= 0 {
+		return fmt.Errorf("max_pixels must be greater than 0")
+	}
+	for topic, rate := range p.TopicMaxFailureRate {
+		if normalizeTopicName(topic) == "" || rate < 0 || rate > 1 {
+			return fmt.Errorf("topic_max_failure_rate entries need a topic and a rate in [0, 1]")
+		}
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) imageIntegrityThresholds(params json.RawMessage) (imageIntegrityThresholds, error) {
+	thresholds := imageIntegrityThresholds{
+		SampleSize:          defaultEpisodeQAImageSampleSize,
+		MaxPixels:           defaultEpisodeQAImageMaxPixels,
+		TopicMaxFailureRate: map[string]float64{},
+	}
+	if h.qaCfg != nil {
+		if h.qaCfg.ImageSampleSize > 0 {
+			thresholds.SampleSize = h.qaCfg.ImageSampleSize
+		}
+		if h.qaCfg.ImageMaxFailureRate > 0 {
+			thresholds.MaxFailureRate = h.qaCfg.ImageMaxFailureRate
+		}
+		if h.qaCfg.ImageMaxPixels > 0 {
+			thresholds.MaxPixels = int64(h.qaCfg.ImageMaxPixels)
+		}
+		for topic, rate := range h.qaCfg.ImageTopicMaxFailureRate {
+			if name := normalizeTopicName(topic); name != "" && rate >= 0 {
+				thresholds.TopicMaxFailureRate[name] = rate
+			}
+		}
+	}
+
+	if err := validateImageIntegrityParams(params); err != nil {
+		return thresholds, err
+	}
+	var p imageIntegrityParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return thresholds, err
+	}
+	if p.SampleSize != nil {
+		thresholds.SampleSize = *p.SampleSize
+	}
+	if p.MaxFailureRate != nil {
+		thresholds.MaxFailureRate = *p.MaxFailureRate
+	}
+	if p.MaxPixels != nil {
+		thresholds.MaxPixels = *p.MaxPixels
+	}
+	for topic, rate := range p.TopicMaxFailureRate {
+		thresholds.TopicMaxFailureRate[normalizeTopicName(topic)] = rate
+	}
+	return thresholds, nil
+}
+
+func (t imageIntegrityThresholds) maxFailureRate(topic string) float64 {
+	if rate, ok := t.TopicMaxFailureRate[normalizeTopicName(topic)]; ok {
+		return rate
+	}
+	return t.MaxFailureRate
+}
+
+// compressedImageFrame is the subset of sensor_msgs/CompressedImage the check needs.
+type compressedImageFrame struct {
+	StampSec  int64
+	StampNsec uint32
+	Format    string
+	Data      []byte
+}
+
+// imageSample is the decode result for one sampled message.
+type imageSample struct {
+	Index   int64
+	LogTime uint64
+	Stamp   string
+	Err     string
+}
+
+// imageTopicSampler picks about target evenly spaced messages from a topic
+// whose length may be unknown, doubling its stride when the sample overflows.
+type imageTopicSampler struct {
+	topic    string
+	encoding string
+	format   string
+	target   int
+	stride   int64
+	seen     int64
+	samples  []imageSample
+}
+
+func newImageTopicSampler(topic, encoding string, target int, messageCount uint64) *imageTopicSampler {
+	stride := int64(1)
+	if target > 0 && messageCount > uint64(target) {
+		stride = int64(messageCount / uint64(target))
+	}
+	return &imageTopicSampler{topic: topic, encoding: encoding, target: target, stride: stride}
+}
+
+// next reports the index of the current message and whether to decode it.
+func (s *imageTopicSampler) next() (int64, bool) {
+	index := s.seen
+	s.seen++
+	return index, index%s.stride == 0
+}
+
+func (s *imageTopicSampler) record(sample imageSample) {
+	s.samples = append(s.samples, sample)
+	if len(s.samples) <= s.target {
+		return
+	}
+	s.stride *= 2
+	kept := s.samples[:0]
+	for _, item := range s.samples {
+		if item.Index%s.stride == 0 {
+			kept = append(kept, item)
+		}
+	}
+	s.samples = kept
+}
+
+// imageTopicResult is the sampled decode outcome for one image topic.
+type imageTopicResult struct {
+	Topic           string
+	MessageEncoding string
+	Format          string
+	MessageCount    int64
+	Samples         []imageSample
+	Unsupported     bool
+}
+
+func (s *imageTopicSampler) result() imageTopicResult {
+	return imageTopicResult{
+		Topic:           s.topic,
+		MessageEncoding: s.encoding,
+		Format:          s.format,
+		MessageCount:    s.seen,
+		Samples:         s.samples,
+		Unsupported:     s.encoding != "cdr" && s.encoding != "ros1",
+	}
+}
+
+// imageSampleCollector decodes a sample of frames from every
+// CompressedImage channel during a scan. counts are the summary's per-channel
+// message counts, used to spread samples evenly when known.
+type imageSampleCollector struct {
+	sampleSize int
+	maxPixels  int64
+	counts     map[uint16]uint64
+	schemas    map[uint16]string
+	samplers   map[uint16]*imageTopicSampler
+}
+
+func newImageSampleCollector(sampleSize int, maxPixels int64, summary *mcap.Summary) *imageSampleCollector {
+	c := &imageSampleCollector{
+		sampleSize: sampleSize,
+		maxPixels:  maxPixels,
+		schemas:    map[uint16]string{},
+		samplers:   map[uint16]*imageTopicSampler{},
+	}
+	if summary != nil && summary.Statistics != nil {
+		c.counts = summary.Statistics.ChannelMessageCounts
+	}
+	return c
+}
+
+func (c *imageSampleCollector) visitor() mcap.Visitor {
+	return mcap.Visitor{
+		Schema: func(s *mcap.Schema) error {
+			c.schemas[s.ID] = s.Name
+			return nil
+		},
+		Channel: func(ch *mcap.Channel) error {
+			if _, ok := compressedImageSchemaNames[c.schemas[ch.SchemaID]]; !ok {
+				return nil
+			}
+			if _, ok := c.samplers[ch.ID]; !ok {
+				c.samplers[ch.ID] = newImageTopicSampler(ch.Topic, ch.MessageEncoding, c.sampleSize, c.counts[ch.ID])
+			}
+			return nil
+		},
+		Message: func(m *mcap.Message) error {
+			sampler, ok := c.samplers[m.ChannelID]
+			if !ok {
+				return nil
+			}
+			index, decode := sampler.next()
+			if !decode || (sampler.encoding != "cdr" && sampler.encoding != "ros1") {
+				return nil
+			}
+			sample := imageSample{Index: index, LogTime: m.LogTime}
+			frame, err := decodeCompressedImageMessage(sampler.encoding, m.Data)
+			if err == nil {
+				sample.Stamp = fmt.Sprintf("%d.%09d", frame.StampSec, frame.StampNsec)
+				if sampler.format == "" {
+					sampler.format = frame.Format
+				}
+				err = decodeCompressedImage(frame, c.maxPixels)
+			}
+			if err != nil {
+				sample.Err = err.Error()
+			}
+			sampler.record(sample)
+			return nil
+		},
+	}
+}
+
+func (c *imageSampleCollector) results() []imageTopicResult {
+	results := make([]imageTopicResult, 0, len(c.samplers))
+	for _, sampler := range c.samplers {
+		results = append(results, sampler.result())
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Topic < results[j].Topic })
+	return results
+}
+
+// decodeCompressedImageMessage parses a serialized sensor_msgs/CompressedImage.
+func decodeCompressedImageMessage(encoding string, data []byte) (compressedImageFrame, error) {
+	var frame compressedImageFrame
+	switch encoding {
+	case "cdr":
+		if len(data) < 4 {
+			return frame, errCompressedImageTruncated
+		}
+		// Encapsulation kinds 0x0001/0x0003 are little endian, 0x0000/0x0002 big endian.
+		var order binary.ByteOrder = binary.BigEndian
+		if data[1]&0x01 != 0 {
+			order = binary.LittleEndian
+		}
+		r := &rosMessageReader{buf: data[4:], order: order, aligned: true}
+		frame.StampSec = int64(int32(r.uint32()))
+		frame.StampNsec = r.uint32()
+		r.string() // header.frame_id
+		frame.Format = r.string()
+		frame.Data = r.bytes()
+		return frame, r.err
+	case "ros1":
+		r := &rosMessageReader{buf: data, order: binary.LittleEndian}
+		r.uint32() // header.seq
+		frame.StampSec = int64(r.uint32())
+		frame.StampNsec = r.uint32()
+		r.string() // header.frame_id
+		frame.Format = r.string()
+		frame.Data = r.bytes()
+		return frame, r.err
+	default:
+		return frame, fmt.Errorf("unsupported message encoding %q", encoding)
+	}
+}
+
+// rosMessageReader decodes the primitive fields used by CompressedImage in
+// ROS 1 and CDR serialization. CDR aligns primitives to their size.
+type rosMessageReader struct {
+	buf     []byte
+	pos     int
+	order   binary.ByteOrder
+	aligned bool
+	err     error
+}
+
+func (r *rosMessageReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if r.aligned {
+		r.pos = (r.pos + 3) &^ 3
+	}
+	if r.pos+4 > len(r.buf) {
+		r.err = errCompressedImageTruncated
+		return 0
+	}
+	v := r.order.Uint32(r.buf[r.pos:])
+	r.pos += 4
+	return v
+}
+
+func (r *rosMessageReader) bytes() []byte {
+	n := int(r.uint32())
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.buf)-r.pos {
+		r.err = errCompressedImageTruncated
+		return nil
+	}
+	v := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return v
+}
+
+func (r *rosMessageReader) string() string {
+	v := r.bytes()
+	if r.aligned {
+		// CDR strings carry a trailing NUL in their length.
+		v = bytes.TrimSuffix(v, []byte{0})
+	}
+	return string(v)
+}
+
+// decodeCompressedImage fully decodes the frame so truncated or corrupt
+// entropy data is caught, not just a valid header. The header is checked
+// first so a frame claiming more than maxPixels pixels is rejected before
+// the decoder allocates for it.
+func decodeCompressedImage(frame compressedImageFrame, maxPixels int64) error {
+	data := frame.Data
+	if strings.Contains(strings.ToLower(frame.Format), "compresseddepth") {
+		if len(data) < compressedDepthHeaderBytes {
+			return errCompressedImageTruncated
+		}
+		data = data[compressedDepthHeaderBytes:]
+	}
+	if len(data) == 0 {
+		return errors.New("empty image data")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("decode image header: %w", err)
+	}
+	if pixels := int64(cfg.Width) * int64(cfg.Height); maxPixels > 0 && pixels > maxPixels {
+		return fmt.Errorf("image is %dx%d, above the %d pixel limit", cfg.Width, cfg.Height, maxPixels)
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("decode image: %w", err)
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) prepareImageIntegrityScan(_ context.Context, _ episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error) {
+	thresholds, err := h.imageIntegrityThresholds(params)
+	if err != nil {
+		return nil, err
+	}
+	var collector *imageSampleCollector
+	return &mcapScanConsumer{
+		visitor: func(summary *mcap.Summary) mcap.Visitor {
+			collector = newImageSampleCollector(thresholds.SampleSize, thresholds.MaxPixels, summary)
+			return collector.visitor()
+		},
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			metadata := pass.checkMetadata()
+			if pass.reason != "" {
+				return imageIntegrityFailure("Image integrity check failed: "+pass.reason, 0, metadata), nil
+			}
+			if pass.scanErr != nil {
+				metadata["scan_error"] = pass.scanErr.Error()
+				return imageIntegrityFailure("Image integrity check failed: "+pass.scanErr.Error(), 0, metadata), nil
+			}
+			return evaluateImageIntegrityCheck(collector.results(), thresholds, metadata), nil
+		},
+	}, nil
+}
+
+func evaluateImageIntegrityCheck(results []imageTopicResult, thresholds imageIntegrityThresholds, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["sample_size"] = thresholds.SampleSize
+
+	items := make([]map[string]any, 0, len(results))
+	failedTopics := []string{}
+	totalSampled, totalFailed := 0, 0
+	for _, result := range results {
+		item := map[string]any{
+			"topic":            result.Topic,
+			"message_encoding": result.MessageEncoding,
+			"message_count":    result.MessageCount,
+		}
+		if result.Format != "" {
+			item["format"] = result.Format
+		}
+		items = append(items, item)
+
+		if result.Unsupported {
+			item["status"] = "unsupported_encoding"
+			continue
+		}
+		if len(result.Samples) == 0 {
+			item["status"] = "no_messages"
+			continue
+		}
+
+		failed := 0
+		for _, sample := range result.Samples {
+			if sample.Err == "" {
+				continue
+			}
+			if failed == 0 {
+				item["example_bad_log_time"] = sample.LogTime
+				if sample.Stamp != "" {
+					item["example_bad_stamp"] = sample.Stamp
+				}
+				item["example_error"] = sample.Err
+			}
+			failed++
+		}
+		failureRate := float64(failed) / float64(len(result.Samples))
+		maxRate := thresholds.maxFailureRate(result.Topic)
+		totalSampled += len(result.Samples)
+		totalFailed += failed
+
+		item["sampled"] = len(result.Samples)
+		item["failed"] = failed
+		item["failure_rate"] = roundQAFloat(failureRate)
+		item["max_failure_rate"] = maxRate
+		if failureRate > maxRate {
+			item["status"] = "failed"
+			failedTopics = append(failedTopics, result.Topic)
+			continue
+		}
+		item["status"] = "ok"
+	}
+	metadata["topics"] = items
+	metadata["sampled_frames"] = totalSampled
+	metadata["failed_frames"] = totalFailed
+	metadata["failed_topics"] = failedTopics
+
+	if len(results) == 0 {
+		metadata["skipped"] = true
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckImageIntegrity,
+			Passed:    true,
+			Score:     1,
+			Details:   "Image integrity check skipped: no CompressedImage topics",
+			Metadata:  metadata,
+		}
+	}
+
+	score := 1.0
+	if totalSampled > 0 {
+		score = float64(totalSampled-totalFailed) / float64(totalSampled)
+	}
+	if len(failedTopics) > 0 {
+		return imageIntegrityFailure(fmt.Sprintf("Image integrity check failed: %d of %d sampled frames did not decode on %s", totalFailed, totalSampled, strings.Join(failedTopics, ", ")), score, metadata)
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckImageIntegrity,
+		Passed:    true,
+		Score:     score,
+		Details:   fmt.Sprintf("Image integrity verified: %d sampled frames on %d topics", totalSampled, len(results)),
+		Metadata:  metadata,
+	}
+}
+
+func imageIntegrityFailure(details string, score float64, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckImageIntegrity,
+		Passed:    false,
+		Score:     score,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
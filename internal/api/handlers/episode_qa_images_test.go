@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testImageBytes(t *testing.T, format string) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for x := 0; x < 16; x++ {
+		img.Set(x, x, color.RGBA{R: 255, A: 255})
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// cdrCompressedImage serializes a little-endian CDR sensor_msgs/msg/CompressedImage.
+func cdrCompressedImage(sec int32, nsec uint32, frameID, format string, data []byte) []byte {
+	buf := []byte{0x00, 0x01, 0x00, 0x00}
+	body := []byte{}
+	align := func() {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	putU32 := func(v uint32) {
+		align()
+		body = binary.LittleEndian.AppendUint32(body, v)
+	}
+	putString := func(s string) {
+		putU32(uint32(len(s) + 1))
+		body = append(body, s...)
+		body = append(body, 0)
+	}
+	putU32(uint32(sec))
+	putU32(nsec)
+	putString(frameID)
+	putString(format)
+	putU32(uint32(len(data)))
+	body = append(body, data...)
+	return append(buf, body...)
+}
+
+// ros1CompressedImage serializes a ROS 1 sensor_msgs/CompressedImage.
+func ros1CompressedImage(sec, nsec uint32, frameID, format string, data []byte) []byte {
+	var body []byte
+	body = binary.LittleEndian.AppendUint32(body, 7)
+	body = binary.LittleEndian.AppendUint32(body, sec)
+	body = binary.LittleEndian.AppendUint32(body, nsec)
+	for _, s := range []string{frameID, format} {
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(s)))
+		body = append(body, s...)
+	}
+	body = binary.LittleEndian.AppendUint32(body, uint32(len(data)))
+	return append(body, data...)
+}
+
+func TestDecodeCompressedImageMessage(t *testing.T) {
+	jpg := testImageBytes(t, "jpeg")
+	pngData := testImageBytes(t, "png")
+
+	tests := []struct {
+		name     string
+		encoding string
+		data     []byte
+		wantSec  int64
+		wantFmt  string
+		wantErr  bool
+	}{
+		{name: "cdr jpeg", encoding: "cdr", data: cdrCompressedImage(12, 500, "cam", "jpeg", jpg), wantSec: 12, wantFmt: "jpeg"},
+		{name: "ros1 png", encoding: "ros1", data: ros1CompressedImage(7, 1, "camera_link", "png", pngData), wantSec: 7, wantFmt: "png"},
+		{
+			name:     "compressed depth header",
+			encoding: "cdr",
+			data:     cdrCompressedImage(1, 0, "depth", "16UC1; compressedDepth png", append(make([]byte, compressedDepthHeaderBytes), pngData...)),
+			wantSec:  1,
+			wantFmt:  "16UC1; compressedDepth png",
+		},
+		{name: "truncated jpeg", encoding: "cdr", data: cdrCompressedImage(3, 0, "cam", "jpeg", jpg[:len(jpg)/2]), wantSec: 3, wantFmt: "jpeg", wantErr: true},
+		{name: "truncated message", encoding: "cdr", data: cdrCompressedImage(3, 0, "cam", "jpeg", jpg)[:20], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := decodeCompressedImageMessage(tt.encoding, tt.data)
+			if err == nil {
+				if frame.StampSec != tt.wantSec || frame.Format != tt.wantFmt {
+					t.Fatalf("frame = sec %d format %q, want sec %d format %q", frame.StampSec, frame.Format, tt.wantSec, tt.wantFmt)
+				}
+				err = decodeCompressedImage(frame, defaultEpisodeQAImageMaxPixels)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeCompressedImageRejectsOversizedHeader(t *testing.T) {
+	// 16x16 fits the default cap but not a 100 pixel one.
+	frame := compressedImageFrame{Format: "png", Data: testImageBytes(t, "png")}
+	if err := decodeCompressedImage(frame, defaultEpisodeQAImageMaxPixels); err != nil {
+		t.Fatalf("decodeCompressedImage() error = %v", err)
+	}
+	if err := decodeCompressedImage(frame, 100); err == nil || !strings.Contains(err.Error(), "pixel limit") {
+		t.Fatalf("decodeCompressedImage() error = %v, want pixel limit error", err)
+	}
+
+	// A PNG header claiming 60000x60000 must fail without a full decode.
+	huge := testImageBytes(t, "png")
+	binary.BigEndian.PutUint32(huge[16:], 60000)
+	binary.BigEndian.PutUint32(huge[20:], 60000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+	err := decodeCompressedImage(compressedImageFrame{Format: "png", Data: huge}, defaultEpisodeQAImageMaxPixels)
+	if err == nil || !strings.Contains(err.Error(), "pixel limit") {
+		t.Fatalf("decodeCompressedImage() error = %v, want pixel limit error", err)
+	}
+}
+
+func TestImageTopicSamplerSpreadsSamples(t *testing.T) {
+	sampler := newImageTopicSampler("/camera", "cdr", 4, 0)
+	for i := 0; i < 100; i++ {
+		if index, ok := sampler.next(); ok {
+			sampler.record(imageSample{Index: index})
+		}
+	}
+	if len(sampler.samples) > 4 {
+		t.Fatalf("kept %d samples, want at most 4", len(sampler.samples))
+	}
+	var indexes []int64
+	for _, s := range sampler.samples {
+		indexes = append(indexes, s.Index)
+	}
+	if !reflect.DeepEqual(indexes, []int64{0, 32, 64, 96}) {
+		t.Fatalf("sample indexes = %v", indexes)
+	}
+
+	known := newImageTopicSampler("/camera", "cdr", 4, 100)
+	if known.stride != 25 {
+		t.Fatalf("stride with known count = %d, want 25", known.stride)
+	}
+}
+
+func TestEvaluateImageIntegrityCheck(t *testing.T) {
+	samples := func(bad ...int) []imageSample {
+		out := make([]imageSample, 10)
+		for i := range out {
+			out[i] = imageSample{Index: int64(i), LogTime: uint64(1000 + i)}
+		}
+		for _, i := range bad {
+			out[i].Err = "decode image: unexpected EOF"
+			out[i].Stamp = "1.000000005"
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		results    []imageTopicResult
+		thresholds imageIntegrityThresholds
+		wantPassed bool
+		wantScore  float64
+		wantFailed []string
+	}{
+		{
+			name:       "clean frames pass",
+			results:    []imageTopicResult{{Topic: "/cam/a", MessageEncoding: "cdr", Samples: samples()}},
+			thresholds: imageIntegrityThresholds{SampleSize: 10},
+			wantPassed: true,
+			wantScore:  1,
+			wantFailed: []string{},
+		},
+		{
+			name: "one bad frame fails strict topic",
+			results: []imageTopicResult{
+				{Topic: "/cam/a", MessageEncoding: "cdr", Samples: samples(3)},
+				{Topic: "/cam/b", MessageEncoding: "cdr", Samples: samples()},
+			},
+			thresholds: imageIntegrityThresholds{SampleSize: 10},
+			wantPassed: false,
+			wantScore:  0.95,
+			wantFailed: []string{"/cam/a"},
+		},
+		{
+			name:       "per-topic threshold tolerates failures",
+			results:    []imageTopicResult{{Topic: "/cam/a", MessageEncoding: "cdr", Samples: samples(3)}},
+			thresholds: imageIntegrityThresholds{SampleSize: 10, TopicMaxFailureRate: map[string]float64{"/cam/a": 0.2}},
+			wantPassed: true,
+			wantScore:  0.9,
+			wantFailed: []string{},
+		},
+		{
+			name:       "no image topics skip",
+			thresholds: imageIntegrityThresholds{SampleSize: 10},
+			wantPassed: true,
+			wantScore:  1,
+			wantFailed: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateImageIntegrityCheck(tt.results, tt.thresholds, nil)
+			if got.CheckName != episodeQACheckImageIntegrity {
+				t.Fatalf("check name = %q", got.CheckName)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Fatalf("passed = %v, want %v (%s)", got.Passed, tt.wantPassed, got.Details)
+			}
+			if got.Score != tt.wantScore {
+				t.Fatalf("score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if !reflect.DeepEqual(got.Metadata["failed_topics"], tt.wantFailed) {
+				t.Fatalf("failed_topics = %v, want %v", got.Metadata["failed_topics"], tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestEvaluateImageIntegrityCheckRecordsExample(t *testing.T) {
+	got := evaluateImageIntegrityCheck([]imageTopicResult{{
+		Topic:           "/cam/a",
+		MessageEncoding: "cdr",
+		Samples: []imageSample{
+			{Index: 0, LogTime: 10},
+			{Index: 5, LogTime: 55, Stamp: "0.000000055", Err: "decode image: invalid JPEG format"},
+		},
+	}}, imageIntegrityThresholds{SampleSize: 2}, nil)
+
+	items := got.Metadata["topics"].([]map[string]any)
+	if items[0]["example_bad_log_time"] != uint64(55) || items[0]["example_bad_stamp"] != "0.000000055" {
+		t.Fatalf("example = %v / %v", items[0]["example_bad_log_time"], items[0]["example_bad_stamp"])
+	}
+	if items[0]["failure_rate"] != 0.5 {
+		t.Fatalf("failure_rate = %v, want 0.5", items[0]["failure_rate"])
+	}
+}
@@ -0,0 +1,719 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/middleware"
+	"archebase.com/keystone-edge/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	inspectionDecisionApproved = "approved"
+	inspectionDecisionRejected = "rejected"
+
+	maxInspectionFailedTags   = 32
+	maxInspectionFailedTagLen = 100
+)
+
+var (
+	errInspectionEpisodeNotQueued = errors.New("episode is not awaiting inspection")
+	errInspectorNotFound          = errors.New("inspector not found")
+	errInspectorInactive          = errors.New("inspector is not active")
+	errInspectionNeedsSenior      = errors.New("episode qa_score requires a senior inspector")
+	errInspectorNotCaller         = errors.New("inspector_id does not match the authenticated inspector")
+	errCallerNotInspector         = errors.New("caller is not a registered inspector")
+)
+
+// inspectionSyncEnqueuer is the part of the sync worker used to hand approved
+// episodes over for upload.
+type inspectionSyncEnqueuer interface {
+	AutoScanEnabled() bool
+	EnqueueEpisode(ctx context.Context, episodeID int64) error
+}
+
+// SetSyncWorker wires the sync worker that receives inspector-approved
+// episodes when auto-scan is enabled. A nil worker disables the handoff.
+func (h *EpisodeQAHandler) SetSyncWorker(worker *services.SyncWorker) {
+	if h == nil || worker == nil {
+		return
+	}
+	h.syncEnqueuer = worker
+}
+
+// InspectionQueueItemResponse is one episode awaiting inspection.
+type InspectionQueueItemResponse struct {
+	ID           int64    `json:"id"`
+	EpisodeID    string   `json:"episode_id"`
+	TaskID       int64    `json:"task_id"`
+	TaskPublicID *string  `json:"task_public_id,omitempty"`
+	SceneID      int64    `json:"scene_id"`
+	SceneName    *string  `json:"scene_name,omitempty"`
+	RobotType    *string  `json:"robot_type,omitempty"`
+	QAScore      *float64 `json:"qa_score,omitempty"`
+	QualityFlag  *string  `json:"quality_flag,omitempty"`
+	CreatedAt    string   `json:"created_at"`
+	// LeasedBy and LeaseExpiresAt are set while an inspector holds the episode.
+	LeasedBy       *int64  `json:"leased_by,omitempty"`
+	LeaseExpiresAt *string `json:"lease_expires_at,omitempty"`
+}
+
+// InspectionQueueResponse is the needs_inspection queue, lowest QA score first.
+type InspectionQueueResponse struct {
+	Items   []InspectionQueueItemResponse `json:"items"`
+	Total   int                           `json:"total"`
+	Limit   int                           `json:"limit"`
+	Offset  int                           `json:"offset"`
+	HasNext bool                          `json:"hasNext,omitempty"`
+	HasPrev bool                          `json:"hasPrev,omitempty"`
+}
+
+// InspectionRequest is an inspector's decision on one episode. Operators
+// act as their own inspector record; inspector_id is only required from
+// callers without one (admins and API keys) and must otherwise match it.
+type InspectionRequest struct {
+	EpisodeID   int64    `json:"episode_id" example:"42"`
+	InspectorID int64    `json:"inspector_id,omitempty" example:"3"`
+	Decision    string   `json:"decision" example:"rejected"`
+	Reason      string   `json:"reason" example:"gripper camera occluded for most of the episode"`
+	FailedTags  []string `json:"failed_tags,omitempty"`
+}
+
+// InspectionResponse is one recorded inspection.
+type InspectionResponse struct {
+	ID           int64    `json:"id"`
+	EpisodeID    int64    `json:"episode_id"`
+	InspectorID  int64    `json:"inspector_id"`
+	Decision     string   `json:"decision"`
+	Reason       string   `json:"reason"`
+	FailedTags   []string `json:"failed_tags"`
+	DurationSec  *int64   `json:"duration_sec,omitempty"`
+	InspectedAt  string   `json:"inspected_at,omitempty"`
+	QAStatus     string   `json:"qa_status,omitempty"`
+	SyncEnqueued bool     `json:"sync_enqueued,omitempty"`
+}
+
+// InspectionListResponse is the inspection history list response.
+type InspectionListResponse struct {
+	Items   []InspectionResponse `json:"items"`
+	Total   int                  `json:"total"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	HasNext bool                 `json:"hasNext,omitempty"`
+	HasPrev bool                 `json:"hasPrev,omitempty"`
+}
+
+type inspectionQueueRow struct {
+	ID           int64           `db:"id"`
+	EpisodeID    string          `db:"episode_id"`
+	TaskID       int64           `db:"task_id"`
+	TaskPublicID sql.NullString  `db:"task_public_id"`
+	SceneID      int64           `db:"scene_id"`
+	SceneName    sql.NullString  `db:"scene_name"`
+	RobotType    sql.NullString  `db:"robot_type"`
+	QAScore      sql.NullFloat64 `db:"qa_score"`
+	QualityFlag  sql.NullString  `db:"quality_flag"`
+	CreatedAt    time.Time       `db:"created_at"`
+	LeasedBy     sql.NullInt64   `db:"leased_by"`
+	LeaseExpires sql.NullTime    `db:"lease_expires_at"`
+}
+
+type inspectionRow struct {
+	ID          int64          `db:"id"`
+	EpisodeID   int64          `db:"episode_id"`
+	InspectorID int64          `db:"inspector_id"`
+	Decision    string         `db:"decision"`
+	Reason      string         `db:"reason"`
+	FailedTags  sql.NullString `db:"failed_tags"`
+	DurationSec sql.NullInt64  `db:"duration_sec"`
+	InspectedAt sql.NullTime   `db:"inspected_at"`
+}
+
+const inspectionColumns = `id, episode_id, inspector_id, decision, reason, failed_tags, duration_sec, inspected_at`
+
+// inspectionQueueSelect selects queue items; the first placeholder is the
+// time active leases are evaluated at.
+const inspectionQueueSelect = `
+	SELECT
+		e.id,
+		e.episode_id,
+		e.task_id,
+		t.task_id AS task_public_id,
+		e.scene_id,
+		e.scene_name,
+		COALESCE(rt.name, rt.model, '') AS robot_type,
+		e.qa_score,
+		e.quality_flag,
+		e.created_at,
+		l.inspector_id AS leased_by,
+		l.expires_at AS lease_expires_at
+	FROM episodes e
+	LEFT JOIN tasks t ON t.id = e.task_id AND t.deleted_at IS NULL
+	LEFT JOIN workstations ws ON ws.id = e.workstation_id AND ws.deleted_at IS NULL
+	LEFT JOIN robots r ON r.id = ws.robot_id AND r.deleted_at IS NULL
+	LEFT JOIN robot_types rt ON rt.id = r.robot_type_id AND rt.deleted_at IS NULL
+	LEFT JOIN inspection_leases l ON l.episode_id = e.id AND l.expires_at > ?
+`
+
+// RegisterInspectionRoutes registers the inspector review workflow routes.
+func (h *EpisodeQAHandler) RegisterInspectionRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/inspections/queue", h.ListInspectionQueue)
+	apiV1.GET("/qa/inspections", h.ListInspections)
+	apiV1.POST("/qa/inspections", h.SubmitInspection)
+	apiV1.GET("/qa/inspections/:id", h.GetInspection)
+	apiV1.POST("/qa/inspections/claim", h.ClaimInspection)
+	apiV1.POST("/qa/inspections/leases/:episode_id/renew", h.RenewInspectionLease)
+	apiV1.POST("/qa/inspections/leases/:episode_id/release", h.ReleaseInspectionLease)
+}
+
+// ListInspectionQueue lists episodes awaiting inspection.
+//
+// @Summary      List inspection queue
+// @Description  Lists needs_inspection episodes, lowest QA score first, then oldest first. Leased episodes report their holder.
+// @Tags         qa
+// @Produce      json
+// @Param        scene_id query int false "Filter by scene"
+// @Param        limit    query int false "Max results (default 50, max 100)"
+// @Param        offset   query int false "Pagination offset (default 0)"
+// @Success      200 {object} InspectionQueueResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections/queue [get]
+func (h *EpisodeQAHandler) ListInspectionQueue(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	where := " WHERE e.qa_status = ? AND e.deleted_at IS NULL"
+	args := []any{qaStatusNeedsInspection}
+	if raw := strings.TrimSpace(c.Query("scene_id")); raw != "" {
+		sceneID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || sceneID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scene_id"})
+			return
+		}
+		where += " AND e.scene_id = ?"
+		args = append(args, sceneID)
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(1) FROM episodes e"+where, args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to count inspection queue: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list inspection queue"})
+		return
+	}
+
+	// The ORDER BY follows idx_inspection_queue (qa_status, qa_score, created_at).
+	query := inspectionQueueSelect + where + `
+		ORDER BY e.qa_score ASC, e.created_at ASC, e.id ASC
+		LIMIT ? OFFSET ?
+	`
+	queryArgs := append([]any{time.Now().UTC()}, args...)
+	var rows []inspectionQueueRow
+	if err := h.db.Select(&rows, query, append(queryArgs, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query inspection queue: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list inspection queue"})
+		return
+	}
+
+	items := make([]InspectionQueueItemResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, inspectionQueueItemFromRow(row))
+	}
+	c.JSON(http.StatusOK, InspectionQueueResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// ListInspections lists recorded inspections.
+//
+// @Summary      List inspections
+// @Description  Lists recorded inspection decisions, newest first
+// @Tags         qa
+// @Produce      json
+// @Param        episode_id   query int    false "Filter by episode"
+// @Param        inspector_id query int    false "Filter by inspector"
+// @Param        decision     query string false "Filter by decision (approved, rejected)"
+// @Param        limit        query int    false "Max results (default 50, max 100)"
+// @Param        offset       query int    false "Pagination offset (default 0)"
+// @Success      200 {object} InspectionListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections [get]
+func (h *EpisodeQAHandler) ListInspections(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	where := " WHERE 1 = 1"
+	args := []any{}
+	for _, filter := range []string{"episode_id", "inspector_id"} {
+		raw := strings.TrimSpace(c.Query(filter))
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + filter})
+			return
+		}
+		where += " AND " + filter + " = ?"
+		args = append(args, id)
+	}
+	if raw := strings.TrimSpace(strings.ToLower(c.Query("decision"))); raw != "" {
+		if raw != inspectionDecisionApproved && raw != inspectionDecisionRejected {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "decision must be approved or rejected"})
+			return
+		}
+		where += " AND decision = ?"
+		args = append(args, raw)
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(1) FROM inspections"+where, args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to count inspections: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list inspections"})
+		return
+	}
+	var rows []inspectionRow
+	query := "SELECT " + inspectionColumns + " FROM inspections" + where + " ORDER BY inspected_at DESC, id DESC LIMIT ? OFFSET ?"
+	if err := h.db.Select(&rows, query, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query inspections: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list inspections"})
+		return
+	}
+
+	items := make([]InspectionResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, inspectionResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, InspectionListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// GetInspection gets one recorded inspection.
+//
+// @Summary      Get inspection
+// @Description  Gets a recorded inspection decision by ID
+// @Tags         qa
+// @Produce      json
+// @Param        id  path      int  true  "Inspection ID"
+// @Success      200 {object} InspectionResponse
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections/{id} [get]
+func (h *EpisodeQAHandler) GetInspection(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid inspection id"})
+		return
+	}
+
+	var row inspectionRow
+	err = h.db.GetContext(c.Request.Context(), &row, "SELECT "+inspectionColumns+" FROM inspections WHERE id = ?", id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "inspection not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to get inspection: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get inspection"})
+		return
+	}
+	c.JSON(http.StatusOK, inspectionResponseFromRow(row))
+}
+
+// SubmitInspection records an inspector's decision on a queued episode.
+//
+// @Summary      Submit inspection
+// @Description  Approves or rejects a needs_inspection episode, ending the inspector's lease and recording its duration. Episodes leased to another inspector cannot be decided. Approval moves qa_status to inspector_approved and, when sync auto-scan is enabled, hands the episode to the sync worker; rejection moves it to rejected.
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        body body InspectionRequest true "Inspection decision"
+// @Success      201 {object} InspectionResponse
+// @Failure      400 {object} map[string]string
+// @Failure      403 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections [post]
+func (h *EpisodeQAHandler) SubmitInspection(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var req InspectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inspectorID, ok := h.resolveRequestInspector(c, req.InspectorID)
+	if !ok {
+		return
+	}
+	req.InspectorID = inspectorID
+	failedTags, err := validateInspectionRequest(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := h.recordInspection(c.Request.Context(), req, failedTags)
+	switch {
+	case errors.Is(err, errEpisodeQANotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectorInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectionNeedsSenior):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectionEpisodeNotQueued), errors.Is(err, errInspectionLeaseHeld):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		logger.Printf("[EPISODE-QA] Failed to record inspection: episode=%d, err=%v", req.EpisodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record inspection"})
+		return
+	}
+
+	if resp.Decision == inspectionDecisionApproved {
+		resp.SyncEnqueued = h.handOffApprovedEpisode(c.Request.Context(), resp.EpisodeID)
+	}
+	logger.Printf("[EPISODE-QA] Inspection recorded: episode=%d, inspector=%d, decision=%s", resp.EpisodeID, resp.InspectorID, resp.Decision)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// validateInspectionRequest normalizes req in place and returns the cleaned
+// failed tags. Rejections need a reason; failed tags only apply to them.
+func validateInspectionRequest(req *InspectionRequest) ([]string, error) {
+	if req.EpisodeID <= 0 {
+		return nil, fmt.Errorf("episode_id is required")
+	}
+	if req.InspectorID <= 0 {
+		return nil, fmt.Errorf("inspector_id is required")
+	}
+	req.Decision = strings.TrimSpace(strings.ToLower(req.Decision))
+	req.Reason = strings.TrimSpace(req.Reason)
+	return validateInspectionDecision(req.Decision, req.Reason, req.FailedTags)
+}
+
+// resolveRequestInspector returns the inspector a request acts as and writes
+// the error response when there is none. An operator acts as the inspector
+// registered under their operator ID, and a body inspector_id must name that
+// same inspector. Admins and API keys have no inspector record of their own
+// and name one in the body; so do requests when auth is disabled.
+func (h *EpisodeQAHandler) resolveRequestInspector(c *gin.Context, requested int64) (int64, bool) {
+	claims := middleware.GetClaims(c)
+	if claims == nil || strings.TrimSpace(claims.OperatorID) == "" {
+		if requested <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "inspector_id is required"})
+			return 0, false
+		}
+		return requested, true
+	}
+
+	var inspectorID int64
+	// #nosec G701 -- static SQL with placeholder-bound operator ID.
+	err := h.db.GetContext(c.Request.Context(), &inspectorID,
+		"SELECT id FROM inspectors WHERE inspector_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1", claims.OperatorID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusForbidden, gin.H{"error": errCallerNotInspector.Error()})
+		return 0, false
+	case err != nil:
+		logger.Printf("[EPISODE-QA] Failed to resolve inspector: operator=%s, err=%v", claims.OperatorID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve inspector"})
+		return 0, false
+	}
+	if requested > 0 && requested != inspectorID {
+		c.JSON(http.StatusForbidden, gin.H{"error": errInspectorNotCaller.Error()})
+		return 0, false
+	}
+	return inspectorID, true
+}
+
+// validateInspectionDecision checks a normalized decision, reason and failed
+// tags, returning the cleaned tags.
+func validateInspectionDecision(decision, reason string, failedTags []string) ([]string, error) {
+	switch decision {
+	case inspectionDecisionApproved:
+		if len(failedTags) > 0 {
+			return nil, fmt.Errorf("failed_tags are only allowed when rejecting")
+		}
+		return nil, nil
+	case inspectionDecisionRejected:
+		if reason == "" {
+			return nil, fmt.Errorf("reason is required when rejecting")
+		}
+	default:
+		return nil, fmt.Errorf("decision must be approved or rejected")
+	}
+	return normalizeInspectionFailedTags(failedTags)
+}
+
+// normalizeInspectionFailedTags trims and de-duplicates failed tags.
+func normalizeInspectionFailedTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	seen := map[string]struct{}{}
+	for _, value := range raw {
+		tag := strings.TrimSpace(value)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxInspectionFailedTagLen {
+			return nil, fmt.Errorf("failed tag %q exceeds %d characters", tag, maxInspectionFailedTagLen)
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxInspectionFailedTags {
+		return nil, fmt.Errorf("at most %d failed_tags are allowed", maxInspectionFailedTags)
+	}
+	return tags, nil
+}
+
+// recordInspection writes the inspections row and the episode's inspection
+// columns in one transaction, guarded on the episode still being queued and
+// not leased to someone else. The submitter's lease is consumed and its age
+// becomes duration_sec.
+func (h *EpisodeQAHandler) recordInspection(ctx context.Context, req InspectionRequest, failedTags []string) (*InspectionResponse, error) {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin inspection transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var episode struct {
+		QAStatus string          `db:"qa_status"`
+		QAScore  sql.NullFloat64 `db:"qa_score"`
+	}
+	err = tx.GetContext(ctx, &episode, "SELECT COALESCE(qa_status, '') AS qa_status, qa_score FROM episodes WHERE id = ? AND deleted_at IS NULL"+forUpdateClause(tx), req.EpisodeID)
+	if err == sql.ErrNoRows {
+		return nil, errEpisodeQANotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query episode: %w", err)
+	}
+	if episode.QAStatus != qaStatusNeedsInspection {
+		return nil, fmt.Errorf("%w: qa_status is %q", errInspectionEpisodeNotQueued, episode.QAStatus)
+	}
+	level, err := activeInspectorLevel(ctx, tx, req.InspectorID)
+	if err != nil {
+		return nil, err
+	}
+	if level != inspectorLevelSenior && (!episode.QAScore.Valid || episode.QAScore.Float64 < h.inspectionSeniorScore()) {
+		return nil, errInspectionNeedsSenior
+	}
+	now := time.Now().UTC()
+	lease, err := loadInspectionLease(ctx, tx, req.EpisodeID)
+	if err != nil {
+		return nil, err
+	}
+	durationSec := sql.NullInt64{}
+	if lease != nil {
+		if lease.InspectorID != req.InspectorID {
+			if lease.ExpiresAt.After(now) {
+				return nil, errInspectionLeaseHeld
+			}
+		} else {
+			durationSec = sql.NullInt64{Int64: int64(max(now.Sub(lease.ClaimedAt), 0) / time.Second), Valid: true}
+		}
+		// #nosec G701 -- static SQL with placeholder-bound lease values.
+		if _, err := tx.ExecContext(ctx, "DELETE FROM inspection_leases WHERE id = ?", lease.ID); err != nil {
+			return nil, fmt.Errorf("end inspection lease: %w", err)
+		}
+	}
+
+	tagsJSON := sql.NullString{}
+	if len(failedTags) > 0 {
+		encoded, err := json.Marshal(failedTags)
+		if err != nil {
+			return nil, fmt.Errorf("marshal failed tags: %w", err)
+		}
+		tagsJSON = sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	// inspections.episode_id is unique; an episode re-queued after a reset
+	// overwrites its previous inspection.
+	var inspectionID int64
+	err = tx.GetContext(ctx, &inspectionID, "SELECT id FROM inspections WHERE episode_id = ?", req.EpisodeID)
+	switch {
+	case err == sql.ErrNoRows:
+		// #nosec G701 -- static SQL with placeholder-bound inspection values.
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO inspections (episode_id, inspector_id, decision, reason, failed_tags, duration_sec, inspected_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, req.EpisodeID, req.InspectorID, req.Decision, req.Reason, tagsJSON, durationSec, now)
+		if err != nil {
+			return nil, fmt.Errorf("insert inspection: %w", err)
+		}
+		if inspectionID, err = res.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("read inspection id: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("query existing inspection: %w", err)
+	default:
+		// #nosec G701 -- static SQL with placeholder-bound inspection values.
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE inspections
+			SET inspector_id = ?, decision = ?, reason = ?, failed_tags = ?, duration_sec = ?, inspected_at = ?
+			WHERE id = ?
+		`, req.InspectorID, req.Decision, req.Reason, tagsJSON, durationSec, now, inspectionID); err != nil {
+			return nil, fmt.Errorf("update inspection: %w", err)
+		}
+	}
+
+	nextStatus := qaStatusRejected
+	if req.Decision == inspectionDecisionApproved {
+		nextStatus = qaStatusInspectorApproved
+	}
+	// #nosec G701 -- static SQL with placeholder-bound episode values.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE episodes
+		SET qa_status = ?, inspector_id = ?, inspection_decision = ?, inspection_reason = ?, inspected_at = ?, updated_at = ?
+		WHERE id = ?
+	`, nextStatus, req.InspectorID, req.Decision, req.Reason, now, now, req.EpisodeID); err != nil {
+		return nil, fmt.Errorf("update episode inspection: %w", err)
+	}
+	if err := recordStateTransitions(tx, stateEntityEpisode, []stateRow{{ID: req.EpisodeID, State: episode.QAStatus}}, nextStatus,
+		inspectorTransitionActor(req.InspectorID), map[string]any{"inspection_id": inspectionID, "reason": req.Reason}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit inspection: %w", err)
+	}
+
+	if failedTags == nil {
+		failedTags = []string{}
+	}
+	return &InspectionResponse{
+		ID:          inspectionID,
+		EpisodeID:   req.EpisodeID,
+		InspectorID: req.InspectorID,
+		Decision:    req.Decision,
+		Reason:      req.Reason,
+		FailedTags:  failedTags,
+		DurationSec: nullableInt64(durationSec),
+		InspectedAt: now.Format(time.RFC3339),
+		QAStatus:    nextStatus,
+	}, nil
+}
+
+func (h *EpisodeQAHandler) loadInspectionQueueItem(ctx context.Context, episodeID int64) (*InspectionQueueItemResponse, error) {
+	var row inspectionQueueRow
+	if err := h.db.GetContext(ctx, &row, inspectionQueueSelect+" WHERE e.id = ? AND e.deleted_at IS NULL", time.Now().UTC(), episodeID); err != nil {
+		return nil, fmt.Errorf("query queued episode: %w", err)
+	}
+	item := inspectionQueueItemFromRow(row)
+	return &item, nil
+}
+
+func inspectionQueueItemFromRow(row inspectionQueueRow) InspectionQueueItemResponse {
+	item := InspectionQueueItemResponse{
+		ID:           row.ID,
+		EpisodeID:    row.EpisodeID,
+		TaskID:       row.TaskID,
+		TaskPublicID: nullableString(row.TaskPublicID),
+		SceneID:      row.SceneID,
+		SceneName:    nullableString(row.SceneName),
+		RobotType:    nullableString(row.RobotType),
+		QAScore:      nullableFloat64(row.QAScore),
+		QualityFlag:  nullableString(row.QualityFlag),
+		CreatedAt:    row.CreatedAt.UTC().Format(time.RFC3339),
+		LeasedBy:     nullableInt64(row.LeasedBy),
+	}
+	if row.LeaseExpires.Valid {
+		expiresAt := row.LeaseExpires.Time.UTC().Format(time.RFC3339)
+		item.LeaseExpiresAt = &expiresAt
+	}
+	return item
+}
+
+// handOffApprovedEpisode queues an inspector-approved episode for cloud sync
+// when auto-scan is on. Failures are logged only: the auto-scan poll picks
+// up inspector_approved episodes on its next pass.
+func (h *EpisodeQAHandler) handOffApprovedEpisode(ctx context.Context, episodeID int64) bool {
+	if h.syncEnqueuer == nil || !h.syncEnqueuer.AutoScanEnabled() {
+		return false
+	}
+	if err := h.syncEnqueuer.EnqueueEpisode(ctx, episodeID); err != nil {
+		if errors.Is(err, services.ErrEpisodeAlreadyEnqueued) {
+			return true
+		}
+		logger.Printf("[EPISODE-QA] Failed to enqueue inspector-approved episode for sync: episode=%d, err=%v", episodeID, err)
+		return false
+	}
+	return true
+}
+
+func inspectionResponseFromRow(row inspectionRow) InspectionResponse {
+	tags := []string{}
+	if row.FailedTags.Valid && strings.TrimSpace(row.FailedTags.String) != "" {
+		if err := json.Unmarshal([]byte(row.FailedTags.String), &tags); err != nil || tags == nil {
+			tags = []string{}
+		}
+	}
+	resp := InspectionResponse{
+		ID:          row.ID,
+		EpisodeID:   row.EpisodeID,
+		InspectorID: row.InspectorID,
+		Decision:    row.Decision,
+		Reason:      row.Reason,
+		FailedTags:  tags,
+		DurationSec: nullableInt64(row.DurationSec),
+	}
+	if row.InspectedAt.Valid {
+		resp.InspectedAt = row.InspectedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,422 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	inspectorLevel1      = "level_1"
+	inspectorLevel2      = "level_2"
+	inspectorLevelSenior = "senior"
+
+	defaultInspectionLeaseTTL    = 10 * time.Minute
+	defaultInspectionSeniorScore = 0.5
+	// maxInspectionClaimCandidates bounds how many queued episodes one claim
+	// tries before giving up to concurrent claimers.
+	maxInspectionClaimCandidates = 5
+)
+
+var (
+	errInspectionLeaseHeld    = errors.New("episode is leased to another inspector")
+	errInspectionLeaseNotHeld = errors.New("inspector does not hold an active lease on this episode")
+	errInspectionQueueEmpty   = errors.New("no episode available for this inspector")
+)
+
+// InspectionLeaseRequest identifies the inspector claiming, renewing or
+// releasing a lease. Operators may omit it; see InspectionRequest.
+type InspectionLeaseRequest struct {
+	InspectorID int64 `json:"inspector_id,omitempty" example:"3"`
+}
+
+// InspectionLeaseResponse is an inspector's lease on one queued episode.
+type InspectionLeaseResponse struct {
+	EpisodeID   int64                        `json:"episode_id"`
+	InspectorID int64                        `json:"inspector_id"`
+	ClaimedAt   string                       `json:"claimed_at"`
+	ExpiresAt   string                       `json:"expires_at"`
+	Episode     *InspectionQueueItemResponse `json:"episode,omitempty"`
+}
+
+type inspectionLeaseRow struct {
+	ID          int64        `db:"id"`
+	EpisodeID   int64        `db:"episode_id"`
+	InspectorID int64        `db:"inspector_id"`
+	ClaimedAt   time.Time    `db:"claimed_at"`
+	ExpiresAt   time.Time    `db:"expires_at"`
+	RenewedAt   sql.NullTime `db:"renewed_at"`
+}
+
+const inspectionLeaseColumns = `id, episode_id, inspector_id, claimed_at, expires_at, renewed_at`
+
+func (h *EpisodeQAHandler) inspectionLeaseTTL() time.Duration {
+	if h.qaCfg == nil || h.qaCfg.InspectionLeaseTTLSec <= 0 {
+		return defaultInspectionLeaseTTL
+	}
+	return time.Duration(h.qaCfg.InspectionLeaseTTLSec) * time.Second
+}
+
+func (h *EpisodeQAHandler) inspectionSeniorScore() float64 {
+	if h.qaCfg == nil || h.qaCfg.InspectionSeniorScore < 0 {
+		return defaultInspectionSeniorScore
+	}
+	return h.qaCfg.InspectionSeniorScore
+}
+
+// inspectionClaimOrder routes queued episodes by certification level. Senior
+// inspectors are the only ones offered episodes below the senior score and
+// work the queue hardest first; level_2 takes the hardest of the rest and
+// level_1 the easiest, so the levels drain opposite ends instead of racing
+// for the same episode.
+func inspectionClaimOrder(level string, seniorScore float64) (string, []any) {
+	switch level {
+	case inspectorLevelSenior:
+		return " ORDER BY e.qa_score ASC, e.created_at ASC, e.id ASC", nil
+	case inspectorLevel2:
+		return " AND e.qa_score >= ? ORDER BY e.qa_score ASC, e.created_at ASC, e.id ASC", []any{seniorScore}
+	default:
+		return " AND e.qa_score >= ? ORDER BY e.qa_score DESC, e.created_at ASC, e.id ASC", []any{seniorScore}
+	}
+}
+
+// ClaimInspection leases the next queued episode to an inspector.
+//
+// @Summary      Claim next inspection
+// @Description  Leases the next needs_inspection episode to the inspector for KEYSTONE_QA_INSPECTION_LEASE_TTL seconds. An inspector holding an active lease gets that lease back. Episodes below KEYSTONE_QA_INSPECTION_SENIOR_SCORE are only assigned to senior inspectors.
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        body body InspectionLeaseRequest true "Claiming inspector"
+// @Success      200 {object} InspectionLeaseResponse
+// @Success      204 "No episode available"
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections/claim [post]
+func (h *EpisodeQAHandler) ClaimInspection(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var req InspectionLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inspectorID, ok := h.resolveRequestInspector(c, req.InspectorID)
+	if !ok {
+		return
+	}
+	req.InspectorID = inspectorID
+
+	lease, err := h.claimNextInspection(c.Request.Context(), req.InspectorID)
+	switch {
+	case errors.Is(err, errInspectionQueueEmpty):
+		c.Status(http.StatusNoContent)
+		return
+	case errors.Is(err, errInspectorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, errInspectorInactive):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		logger.Printf("[EPISODE-QA] Failed to claim inspection: inspector=%d, err=%v", req.InspectorID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to claim inspection"})
+		return
+	}
+
+	resp := inspectionLeaseResponseFromRow(*lease)
+	item, err := h.loadInspectionQueueItem(c.Request.Context(), lease.EpisodeID)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to load claimed episode: episode=%d, err=%v", lease.EpisodeID, err)
+	} else {
+		resp.Episode = item
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// RenewInspectionLease extends an inspector's lease.
+//
+// @Summary      Renew inspection lease
+// @Description  Extends the inspector's active lease on an episode by the lease TTL. Expired leases cannot be renewed.
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        episode_id path int                    true "Episode ID"
+// @Param        body       body InspectionLeaseRequest true "Lease holder"
+// @Success      200 {object} InspectionLeaseResponse
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections/leases/{episode_id}/renew [post]
+func (h *EpisodeQAHandler) RenewInspectionLease(c *gin.Context) {
+	h.handleInspectionLease(c, func(ctx context.Context, episodeID, inspectorID int64) (*inspectionLeaseRow, error) {
+		return h.renewInspectionLease(ctx, episodeID, inspectorID)
+	})
+}
+
+// ReleaseInspectionLease gives up an inspector's lease.
+//
+// @Summary      Release inspection lease
+// @Description  Returns a leased episode to the queue without a decision.
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        episode_id path int                    true "Episode ID"
+// @Param        body       body InspectionLeaseRequest true "Lease holder"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      409 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/inspections/leases/{episode_id}/release [post]
+func (h *EpisodeQAHandler) ReleaseInspectionLease(c *gin.Context) {
+	h.handleInspectionLease(c, func(ctx context.Context, episodeID, inspectorID int64) (*inspectionLeaseRow, error) {
+		return nil, h.releaseInspectionLease(ctx, episodeID, inspectorID)
+	})
+}
+
+func (h *EpisodeQAHandler) handleInspectionLease(c *gin.Context, op func(ctx context.Context, episodeID, inspectorID int64) (*inspectionLeaseRow, error)) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	episodeID, err := strconv.ParseInt(strings.TrimSpace(c.Param("episode_id")), 10, 64)
+	if err != nil || episodeID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid episode id"})
+		return
+	}
+	var req InspectionLeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	inspectorID, ok := h.resolveRequestInspector(c, req.InspectorID)
+	if !ok {
+		return
+	}
+	req.InspectorID = inspectorID
+
+	lease, err := op(c.Request.Context(), episodeID, req.InspectorID)
+	if errors.Is(err, errInspectionLeaseNotHeld) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to update inspection lease: episode=%d, inspector=%d, err=%v", episodeID, req.InspectorID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update inspection lease"})
+		return
+	}
+	if lease == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, inspectionLeaseResponseFromRow(*lease))
+}
+
+// claimNextInspection returns the inspector's active lease, or leases the
+// first routable queued episode that nobody else holds.
+func (h *EpisodeQAHandler) claimNextInspection(ctx context.Context, inspectorID int64) (*inspectionLeaseRow, error) {
+	level, err := activeInspectorLevel(ctx, h.db, inspectorID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+
+	var held inspectionLeaseRow
+	err = h.db.GetContext(ctx, &held, `
+		SELECT l.id, l.episode_id, l.inspector_id, l.claimed_at, l.expires_at, l.renewed_at
+		FROM inspection_leases l
+		INNER JOIN episodes e ON e.id = l.episode_id
+		WHERE l.inspector_id = ? AND l.expires_at > ? AND e.qa_status = ? AND e.deleted_at IS NULL
+		ORDER BY l.claimed_at ASC, l.id ASC
+		LIMIT 1
+	`, inspectorID, now, qaStatusNeedsInspection)
+	if err == nil {
+		return &held, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("query held lease: %w", err)
+	}
+
+	order, orderArgs := inspectionClaimOrder(level, h.inspectionSeniorScore())
+	args := append([]any{now, qaStatusNeedsInspection}, orderArgs...)
+	var candidates []int64
+	if err := h.db.SelectContext(ctx, &candidates, `
+		SELECT e.id
+		FROM episodes e
+		LEFT JOIN inspection_leases l ON l.episode_id = e.id AND l.expires_at > ?
+		WHERE e.qa_status = ? AND e.deleted_at IS NULL AND l.id IS NULL
+	`+order+" LIMIT ?", append(args, maxInspectionClaimCandidates)...); err != nil {
+		return nil, fmt.Errorf("query inspection candidates: %w", err)
+	}
+
+	for _, episodeID := range candidates {
+		lease, err := h.acquireInspectionLease(ctx, episodeID, inspectorID, now)
+		if errors.Is(err, errInspectionLeaseHeld) || errors.Is(err, errInspectionEpisodeNotQueued) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		logger.Printf("[EPISODE-QA] Inspection claimed: episode=%d, inspector=%d, level=%s", episodeID, inspectorID, level)
+		return lease, nil
+	}
+	return nil, errInspectionQueueEmpty
+}
+
+// acquireInspectionLease leases one episode, locking the episode row so a
+// concurrent claim or decision on it waits for this one.
+func (h *EpisodeQAHandler) acquireInspectionLease(ctx context.Context, episodeID, inspectorID int64, now time.Time) (*inspectionLeaseRow, error) {
+	tx, err := h.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin lease transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var qaStatus string
+	err = tx.GetContext(ctx, &qaStatus, "SELECT COALESCE(qa_status, '') FROM episodes WHERE id = ? AND deleted_at IS NULL"+forUpdateClause(tx), episodeID)
+	if err == sql.ErrNoRows || (err == nil && qaStatus != qaStatusNeedsInspection) {
+		return nil, errInspectionEpisodeNotQueued
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query episode: %w", err)
+	}
+	existing, err := loadInspectionLease(ctx, tx, episodeID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ExpiresAt.After(now) && existing.InspectorID != inspectorID {
+		return nil, errInspectionLeaseHeld
+	}
+
+	lease := inspectionLeaseRow{EpisodeID: episodeID, InspectorID: inspectorID, ClaimedAt: now, ExpiresAt: now.Add(h.inspectionLeaseTTL())}
+	if existing == nil {
+		// #nosec G701 -- static SQL with placeholder-bound lease values.
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO inspection_leases (episode_id, inspector_id, claimed_at, expires_at)
+			VALUES (?, ?, ?, ?)
+		`, lease.EpisodeID, lease.InspectorID, lease.ClaimedAt, lease.ExpiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("insert inspection lease: %w", err)
+		}
+		if lease.ID, err = res.LastInsertId(); err != nil {
+			return nil, fmt.Errorf("read inspection lease id: %w", err)
+		}
+	} else {
+		lease.ID = existing.ID
+		// #nosec G701 -- static SQL with placeholder-bound lease values.
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE inspection_leases
+			SET inspector_id = ?, claimed_at = ?, expires_at = ?, renewed_at = NULL
+			WHERE id = ?
+		`, lease.InspectorID, lease.ClaimedAt, lease.ExpiresAt, lease.ID); err != nil {
+			return nil, fmt.Errorf("take over inspection lease: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit inspection lease: %w", err)
+	}
+	return &lease, nil
+}
+
+func (h *EpisodeQAHandler) renewInspectionLease(ctx context.Context, episodeID, inspectorID int64) (*inspectionLeaseRow, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(h.inspectionLeaseTTL())
+	// #nosec G701 -- static SQL with placeholder-bound lease values.
+	res, err := h.db.ExecContext(ctx, `
+		UPDATE inspection_leases
+		SET expires_at = ?, renewed_at = ?
+		WHERE episode_id = ? AND inspector_id = ? AND expires_at > ?
+	`, expiresAt, now, episodeID, inspectorID, now)
+	if err != nil {
+		return nil, fmt.Errorf("renew inspection lease: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("read renew rows affected: %w", err)
+	} else if affected == 0 {
+		return nil, errInspectionLeaseNotHeld
+	}
+	lease, err := loadInspectionLease(ctx, h.db, episodeID)
+	if err != nil {
+		return nil, err
+	}
+	if lease == nil {
+		return nil, errInspectionLeaseNotHeld
+	}
+	return lease, nil
+}
+
+func (h *EpisodeQAHandler) releaseInspectionLease(ctx context.Context, episodeID, inspectorID int64) error {
+	// #nosec G701 -- static SQL with placeholder-bound lease values.
+	res, err := h.db.ExecContext(ctx, "DELETE FROM inspection_leases WHERE episode_id = ? AND inspector_id = ? AND expires_at > ?", episodeID, inspectorID, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("release inspection lease: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("read release rows affected: %w", err)
+	}
+	if affected == 0 {
+		return errInspectionLeaseNotHeld
+	}
+	return nil
+}
+
+func loadInspectionLease(ctx context.Context, q sqlx.QueryerContext, episodeID int64) (*inspectionLeaseRow, error) {
+	var lease inspectionLeaseRow
+	err := sqlx.GetContext(ctx, q, &lease, "SELECT "+inspectionLeaseColumns+" FROM inspection_leases WHERE episode_id = ?", episodeID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query inspection lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// activeInspectorLevel returns the certification level of an active inspector.
+func activeInspectorLevel(ctx context.Context, q sqlx.QueryerContext, inspectorID int64) (string, error) {
+	var row struct {
+		Status             sql.NullString `db:"status"`
+		CertificationLevel sql.NullString `db:"certification_level"`
+	}
+	err := sqlx.GetContext(ctx, q, &row, "SELECT status, certification_level FROM inspectors WHERE id = ? AND deleted_at IS NULL", inspectorID)
+	if err == sql.ErrNoRows {
+		return "", errInspectorNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("query inspector: %w", err)
+	}
+	if row.Status.Valid && row.Status.String != "active" {
+		return "", errInspectorInactive
+	}
+	if row.CertificationLevel.String == "" {
+		return inspectorLevel1, nil
+	}
+	return row.CertificationLevel.String, nil
+}
+
+func inspectionLeaseResponseFromRow(row inspectionLeaseRow) InspectionLeaseResponse {
+	return InspectionLeaseResponse{
+		EpisodeID:   row.EpisodeID,
+		InspectorID: row.InspectorID,
+		ClaimedAt:   row.ClaimedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:   row.ExpiresAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestInspectionLeaseClaimAndRouting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupInspectionTestDB(t)
+	execQAProfileTestSQL(t, db, `
+		INSERT INTO inspectors (id, certification_level) VALUES (5, 'senior'), (6, 'level_2'), (8, 'level_2'), (9, 'level_1');
+		INSERT INTO episodes (id, episode_id, task_id, scene_id, workstation_id, qa_status, qa_score, created_at) VALUES
+			(5, 'ep-5', 1, 5, 10, 'needs_inspection', 0.3, '2026-03-05 08:00:00');
+	`)
+	h := &EpisodeQAHandler{db: db}
+	router := gin.New()
+	h.RegisterInspectionRoutes(router.Group("/api/v1"))
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	claim := func(inspectorID int64) InspectionLeaseResponse {
+		t.Helper()
+		rec := do(http.MethodPost, "/api/v1/qa/inspections/claim", map[string]any{"inspector_id": inspectorID})
+		var lease InspectionLeaseResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &lease); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("claim by %d = %d %s", inspectorID, rec.Code, rec.Body.String())
+		}
+		return lease
+	}
+	leaseAction := func(episodeID, inspectorID int64, action string) int {
+		return do(http.MethodPost, fmt.Sprintf("/api/v1/qa/inspections/leases/%d/%s", episodeID, action), map[string]any{"inspector_id": inspectorID}).Code
+	}
+
+	// level_1 takes the easiest episode, level_2 the hardest non-senior one,
+	// senior the lowest score overall.
+	if lease := claim(3); lease.EpisodeID != 3 || lease.Episode == nil || lease.Episode.EpisodeID != "ep-3" {
+		t.Fatalf("level_1 lease = %+v", lease)
+	}
+	if lease := claim(3); lease.EpisodeID != 3 {
+		t.Fatalf("repeat claim should return the held lease, got %+v", lease)
+	}
+	if lease := claim(6); lease.EpisodeID != 2 {
+		t.Fatalf("level_2 lease = %+v", lease)
+	}
+	if lease := claim(5); lease.EpisodeID != 5 {
+		t.Fatalf("senior lease = %+v", lease)
+	}
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections/claim", map[string]any{"inspector_id": 4}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("inactive claim status = %d", rec.Code)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 5, "inspector_id": 3, "decision": "approved"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-senior decision on low score = %d", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 3, "inspector_id": 6, "decision": "approved"}); rec.Code != http.StatusConflict {
+		t.Fatalf("decision on another inspector's lease = %d", rec.Code)
+	}
+	if code := leaseAction(3, 3, "renew"); code != http.StatusOK {
+		t.Fatalf("renew status = %d", code)
+	}
+	if code := leaseAction(3, 6, "renew"); code != http.StatusConflict {
+		t.Fatalf("renew by non-holder status = %d", code)
+	}
+
+	// An expired lease is lost: another inspector takes the episode over and
+	// the previous holder can no longer renew it.
+	past := time.Now().UTC().Add(-time.Minute)
+	if _, err := db.Exec("UPDATE inspection_leases SET expires_at = ? WHERE episode_id = 2", past); err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+	if lease := claim(8); lease.EpisodeID != 2 || lease.InspectorID != 8 {
+		t.Fatalf("takeover lease = %+v", lease)
+	}
+	if code := leaseAction(2, 6, "renew"); code != http.StatusConflict {
+		t.Fatalf("renew of lost lease status = %d", code)
+	}
+
+	if _, err := db.Exec("UPDATE inspection_leases SET claimed_at = ? WHERE episode_id = 3", time.Now().UTC().Add(-90*time.Second)); err != nil {
+		t.Fatalf("backdate lease: %v", err)
+	}
+	rec := do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 3, "inspector_id": 3, "decision": "approved"})
+	var decided InspectionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &decided); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("submit = %d %s", rec.Code, rec.Body.String())
+	}
+	if decided.DurationSec == nil || *decided.DurationSec < 89 || *decided.DurationSec > 95 {
+		t.Fatalf("duration_sec = %v", decided.DurationSec)
+	}
+	var leases int
+	if err := db.Get(&leases, "SELECT COUNT(1) FROM inspection_leases WHERE episode_id = 3"); err != nil || leases != 0 {
+		t.Fatalf("lease rows after decision = %d, %v", leases, err)
+	}
+
+	if code := leaseAction(5, 5, "release"); code != http.StatusNoContent {
+		t.Fatalf("release status = %d", code)
+	}
+	if code := leaseAction(5, 5, "release"); code != http.StatusConflict {
+		t.Fatalf("second release status = %d", code)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/qa/inspections/queue", nil)
+	var queue InspectionQueueResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &queue); err != nil {
+		t.Fatalf("decode queue: %v", err)
+	}
+	leasedBy := map[int64]int64{}
+	for _, item := range queue.Items {
+		if item.LeasedBy != nil {
+			leasedBy[item.ID] = *item.LeasedBy
+		}
+	}
+	if len(leasedBy) != 1 || leasedBy[2] != 8 {
+		t.Fatalf("queue leases = %v", leasedBy)
+	}
+
+	if lease := claim(3); lease.EpisodeID != 1 {
+		t.Fatalf("next level_1 lease = %+v", lease)
+	}
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections/claim", map[string]any{"inspector_id": 9}); rec.Code != http.StatusNoContent {
+		t.Fatalf("claim with only senior episodes left = %d %s", rec.Code, rec.Body.String())
+	}
+}
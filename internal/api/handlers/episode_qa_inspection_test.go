@@ -0,0 +1,251 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/middleware"
+	"archebase.com/keystone-edge/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+type fakeInspectionSyncEnqueuer struct {
+	autoScan bool
+	err      error
+	enqueued []int64
+}
+
+func (f *fakeInspectionSyncEnqueuer) AutoScanEnabled() bool { return f.autoScan }
+
+func (f *fakeInspectionSyncEnqueuer) EnqueueEpisode(_ context.Context, episodeID int64) error {
+	f.enqueued = append(f.enqueued, episodeID)
+	return f.err
+}
+
+func setupInspectionTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	execQAProfileTestSQL(t, db, `
+		CREATE TABLE tasks (id INTEGER PRIMARY KEY, task_id TEXT, deleted_at TIMESTAMP NULL);
+		CREATE TABLE workstations (id INTEGER PRIMARY KEY, robot_id INTEGER, deleted_at TIMESTAMP NULL);
+		CREATE TABLE robots (id INTEGER PRIMARY KEY, robot_type_id INTEGER, deleted_at TIMESTAMP NULL);
+		CREATE TABLE robot_types (id INTEGER PRIMARY KEY, name TEXT, model TEXT, deleted_at TIMESTAMP NULL);
+		CREATE TABLE inspectors (id INTEGER PRIMARY KEY, inspector_id TEXT, certification_level TEXT DEFAULT 'level_1', status TEXT DEFAULT 'active', deleted_at TIMESTAMP NULL);
+		CREATE TABLE episodes (
+			id INTEGER PRIMARY KEY,
+			episode_id TEXT NOT NULL,
+			task_id INTEGER NOT NULL,
+			scene_id INTEGER NOT NULL,
+			scene_name TEXT,
+			workstation_id INTEGER,
+			qa_status TEXT,
+			qa_score REAL,
+			quality_flag TEXT,
+			inspector_id INTEGER NULL,
+			inspection_decision TEXT NULL,
+			inspection_reason TEXT,
+			inspected_at TIMESTAMP NULL,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+		CREATE TABLE inspections (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL UNIQUE,
+			inspector_id INTEGER NOT NULL,
+			decision TEXT NOT NULL,
+			reason TEXT NOT NULL,
+			failed_tags TEXT,
+			duration_sec INTEGER,
+			inspected_at TIMESTAMP
+		);
+		CREATE TABLE inspection_leases (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL UNIQUE,
+			inspector_id INTEGER NOT NULL,
+			claimed_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			renewed_at TIMESTAMP NULL
+		);
+		INSERT INTO tasks (id, task_id) VALUES (1, 'task-1');
+		INSERT INTO robot_types (id, name) VALUES (7, 'dual-arm');
+		INSERT INTO robots (id, robot_type_id) VALUES (1, 7);
+		INSERT INTO workstations (id, robot_id) VALUES (10, 1);
+		INSERT INTO inspectors (id, inspector_id, status) VALUES (3, 'op-3', 'active'), (4, 'op-4', 'inactive');
+		INSERT INTO episodes (id, episode_id, task_id, scene_id, workstation_id, qa_status, qa_score, created_at) VALUES
+			(1, 'ep-1', 1, 5, 10, 'needs_inspection', 0.8, '2026-03-01 08:00:00'),
+			(2, 'ep-2', 1, 5, 10, 'needs_inspection', 0.6, '2026-03-02 08:00:00'),
+			(3, 'ep-3', 1, 6, 10, 'needs_inspection', 0.8, '2026-02-01 08:00:00'),
+			(4, 'ep-4', 1, 5, 10, 'approved', 0.95, '2026-01-01 08:00:00');
+	`)
+	createStateTransitionsTestTable(t, db)
+	return db
+}
+
+func TestInspectionWorkflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupInspectionTestDB(t)
+	h := &EpisodeQAHandler{db: db}
+	syncer := &fakeInspectionSyncEnqueuer{autoScan: true, err: services.ErrEpisodeAlreadyEnqueued}
+	h.syncEnqueuer = syncer
+	router := gin.New()
+	h.RegisterInspectionRoutes(router.Group("/api/v1"))
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/qa/inspections/queue", nil)
+	var queue InspectionQueueResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &queue); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("queue = %d %s", rec.Code, rec.Body.String())
+	}
+	if queue.Total != 3 || len(queue.Items) != 3 || queue.Items[0].ID != 2 || queue.Items[1].ID != 3 || queue.Items[2].ID != 1 {
+		t.Fatalf("queue order = %+v", queue.Items)
+	}
+	if queue.Items[0].RobotType == nil || *queue.Items[0].RobotType != "dual-arm" {
+		t.Fatalf("queue robot type = %+v", queue.Items[0])
+	}
+
+	for name, tc := range map[string]struct {
+		body any
+		want int
+	}{
+		"reject without reason": {map[string]any{"episode_id": 1, "inspector_id": 3, "decision": "rejected"}, http.StatusBadRequest},
+		"tags on approval":      {map[string]any{"episode_id": 1, "inspector_id": 3, "decision": "approved", "failed_tags": []string{"blur"}}, http.StatusBadRequest},
+		"unknown decision":      {map[string]any{"episode_id": 1, "inspector_id": 3, "decision": "maybe"}, http.StatusBadRequest},
+		"inactive inspector":    {map[string]any{"episode_id": 1, "inspector_id": 4, "decision": "approved"}, http.StatusBadRequest},
+		"missing inspector":     {map[string]any{"episode_id": 1, "inspector_id": 9, "decision": "approved"}, http.StatusNotFound},
+		"missing episode":       {map[string]any{"episode_id": 99, "inspector_id": 3, "decision": "approved"}, http.StatusNotFound},
+		"not queued":            {map[string]any{"episode_id": 4, "inspector_id": 3, "decision": "approved"}, http.StatusConflict},
+	} {
+		if rec := do(http.MethodPost, "/api/v1/qa/inspections", tc.body); rec.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d: %s", name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+
+	rec = do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{
+		"episode_id": 2, "inspector_id": 3, "decision": "Rejected", "reason": " camera occluded ", "failed_tags": []string{"occlusion", " occlusion", "", "drop"},
+	})
+	var rejected InspectionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &rejected); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("reject = %d %s", rec.Code, rec.Body.String())
+	}
+	if rejected.QAStatus != qaStatusRejected || rejected.Reason != "camera occluded" || len(rejected.FailedTags) != 2 || rejected.SyncEnqueued {
+		t.Fatalf("rejected = %+v", rejected)
+	}
+
+	rec = do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 1, "inspector_id": 3, "decision": "approved"})
+	var approved InspectionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &approved); err != nil || rec.Code != http.StatusCreated {
+		t.Fatalf("approve = %d %s", rec.Code, rec.Body.String())
+	}
+	if approved.QAStatus != qaStatusInspectorApproved || !approved.SyncEnqueued || len(syncer.enqueued) != 1 || syncer.enqueued[0] != 1 {
+		t.Fatalf("approved = %+v, enqueued = %v", approved, syncer.enqueued)
+	}
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 1, "inspector_id": 3, "decision": "approved"}); rec.Code != http.StatusConflict {
+		t.Fatalf("second approval status = %d", rec.Code)
+	}
+
+	var episode struct {
+		QAStatus    string `db:"qa_status"`
+		InspectorID int64  `db:"inspector_id"`
+		Decision    string `db:"inspection_decision"`
+		Reason      string `db:"inspection_reason"`
+	}
+	if err := db.Get(&episode, "SELECT qa_status, inspector_id, inspection_decision, inspection_reason FROM episodes WHERE id = 2"); err != nil {
+		t.Fatalf("query episode: %v", err)
+	}
+	if episode.QAStatus != qaStatusRejected || episode.InspectorID != 3 || episode.Decision != inspectionDecisionRejected || episode.Reason != "camera occluded" {
+		t.Fatalf("episode = %+v", episode)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/qa/inspections?decision=rejected", nil)
+	var list InspectionListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+	if list.Total != 1 || list.Items[0].EpisodeID != 2 || list.Items[0].FailedTags[1] != "drop" {
+		t.Fatalf("list = %+v", list)
+	}
+	if rec := do(http.MethodGet, "/api/v1/qa/inspections/99", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("get missing status = %d", rec.Code)
+	}
+
+	syncer.autoScan = false
+	if rec := do(http.MethodPost, "/api/v1/qa/inspections", map[string]any{"episode_id": 3, "inspector_id": 3, "decision": "approved"}); rec.Code != http.StatusCreated || len(syncer.enqueued) != 1 {
+		t.Fatalf("approval without auto-scan = %d, enqueued = %v", rec.Code, syncer.enqueued)
+	}
+}
+
+func TestInspectionInspectorComesFromClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupInspectionTestDB(t)
+	h := &EpisodeQAHandler{db: db}
+	h.syncEnqueuer = &fakeInspectionSyncEnqueuer{}
+	do := func(claims *auth.Claims, path string, body any) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(middleware.ClaimsKey, claims)
+			c.Next()
+		})
+		h.RegisterInspectionRoutes(router.Group("/api/v1"))
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatalf("encode: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	operator := auth.NewCollectorClaims(11, "op-3")
+
+	if rec := do(operator, "/api/v1/qa/inspections", map[string]any{"episode_id": 1, "inspector_id": 4, "decision": "approved"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("submit as another inspector = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(auth.NewCollectorClaims(12, "op-9"), "/api/v1/qa/inspections/claim", map[string]any{}); rec.Code != http.StatusForbidden {
+		t.Fatalf("claim by non-inspector = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec := do(operator, "/api/v1/qa/inspections/claim", map[string]any{})
+	var lease InspectionLeaseResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &lease); err != nil || rec.Code != http.StatusOK || lease.InspectorID != 3 {
+		t.Fatalf("claim = %d %s", rec.Code, rec.Body.String())
+	}
+	rec = do(operator, "/api/v1/qa/inspections", map[string]any{"episode_id": lease.EpisodeID, "decision": "approved"})
+	var resp InspectionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || rec.Code != http.StatusCreated || resp.InspectorID != 3 {
+		t.Fatalf("submit = %d %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(auth.NewAdminClaims(), "/api/v1/qa/inspections", map[string]any{"episode_id": 1, "decision": "approved"}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("admin without inspector_id = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+
+	"archebase.com/keystone-edge/internal/mcap"
+	"archebase.com/keystone-edge/internal/storage/s3"
+)
+
+const (
+	episodeQACheckMcapStructure = "mcap_structure"
+
+	// maxEpisodeQAMcapChunkFailures caps the per-chunk failures persisted in check_metadata.
+	maxEpisodeQAMcapChunkFailures = 50
+)
+
+// s3RangeReader serves MCAP byte ranges from MinIO with ranged GETs so QA
+// never downloads a whole recording.
+type s3RangeReader struct {
+	ctx    context.Context
+	client *s3.Client
+	bucket string
+	object string
+}
+
+func (r s3RangeReader) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	if length <= 0 {
+		return io.NopCloser(bytes.NewReader(nil)), nil
+	}
+	var opts minio.GetObjectOptions
+	if err := opts.SetRange(offset, offset+length-1); err != nil {
+		return nil, fmt.Errorf("set range %d-%d: %w", offset, offset+length-1, err)
+	}
+	return r.client.GetObject(r.ctx, r.bucket, r.object, opts)
+}
+
+// openEpisodeMcapReader stats the episode MCAP object and returns a ranged reader.
+// When the object is missing or unusable the reader is nil and reason explains
+// why, so the calling check can fail with its own outcome.
+func (h *EpisodeQAHandler) openEpisodeMcapReader(ctx context.Context, row episodeQACheckRow) (*mcap.Reader, map[string]any, string, error) {
+	src, size, metadata, reason, err := h.openEpisodeMcapSource(ctx, row)
+	if err != nil || reason != "" {
+		return nil, metadata, reason, err
+	}
+	return mcap.NewReader(src, size), metadata, "", nil
+}
+
+// openEpisodeMcapSource stats the episode MCAP object and returns its ranged
+// source and size, with the same reason semantics as openEpisodeMcapReader.
+func (h *EpisodeQAHandler) openEpisodeMcapSource(ctx context.Context, row episodeQACheckRow) (mcap.RangeReader, int64, map[string]any, string, error) {
+	bucket, objectName, ok := resolveEpisodeMcapLocation(h.bucket, row.McapPath)
+	if !ok {
+		return nil, 0, map[string]any{"mcap_path": row.McapPath}, "invalid mcap_path", nil
+	}
+	metadata := map[string]any{
+		"bucket": bucket,
+		"object": objectName,
+	}
+
+	stat, err := h.s3.StatObject(ctx, bucket, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if isS3NotFound(err) {
+			return nil, 0, metadata, "object not found", nil
+		}
+		return nil, 0, nil, "", fmt.Errorf("stat mcap object: %w", err)
+	}
+	metadata["file_size_bytes"] = stat.Size
+
+	return s3RangeReader{ctx: ctx, client: h.s3, bucket: bucket, object: objectName}, stat.Size, metadata, "", nil
+}
+
+// isMcapFormatError reports whether err describes a malformed recording rather
+// than a storage or transport failure.
+func isMcapFormatError(err error) bool {
+	for _, target := range []error{
+		mcap.ErrBadMagic,
+		mcap.ErrTruncated,
+		mcap.ErrUnexpectedOpcode,
+		mcap.ErrCRCMismatch,
+		mcap.ErrUnsupportedCompression,
+		mcap.ErrRecordTooLarge,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// mcapChunkFailure is one chunk that could not be verified.
+type mcapChunkFailure struct {
+	Offset           int64
+	Length           int64
+	Compression      string
+	MessageStartTime uint64
+	MessageEndTime   uint64
+	Error            string
+}
+
+// mcapStructureReport collects everything the structural walk found.
+type mcapStructureReport struct {
+	Summary         *mcap.Summary
+	SummaryErr      error
+	ScanErr         error
+	ChunkCount      int
+	ChunkIndexCount int
+	ChunkFailures   []mcapChunkFailure
+}
+
+func (h *EpisodeQAHandler) prepareMcapStructureScan(_ context.Context, _ episodeQACheckRow, _ json.RawMessage) (*mcapScanConsumer, error) {
+	collector := newMcapChunkCollector()
+	return &mcapScanConsumer{
+		visitor: func(*mcap.Summary) mcap.Visitor { return collector.visitor() },
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			metadata := pass.checkMetadata()
+			if pass.reason != "" {
+				return mcapStructureFailure("MCAP structure check failed: "+pass.reason, 0, metadata), nil
+			}
+			return evaluateMcapStructureCheck(collector.report(pass.summary, pass.summaryErr, pass.scanErr), metadata), nil
+		},
+	}, nil
+}
+
+// mcapChunkCollector records the chunks a scan passes over so the summary's
+// chunk index can be cross-checked against the chunks actually present.
+type mcapChunkCollector struct {
+	scanned  map[int64]int64
+	count    int
+	failures []mcapChunkFailure
+}
+
+func newMcapChunkCollector() *mcapChunkCollector {
+	return &mcapChunkCollector{scanned: map[int64]int64{}}
+}
+
+func (c *mcapChunkCollector) visitor() mcap.Visitor {
+	return mcap.Visitor{
+		Chunk: func(chunk *mcap.Chunk, chunkErr error) error {
+			c.count++
+			c.scanned[chunk.Offset] = chunk.Length
+			if chunkErr != nil {
+				c.failures = append(c.failures, mcapChunkFailureFrom(chunk, chunkErr))
+			}
+			return nil
+		},
+	}
+}
+
+// report cross-checks the summary's chunk index against the scanned chunks.
+func (c *mcapChunkCollector) report(summary *mcap.Summary, summaryErr, scanErr error) mcapStructureReport {
+	report := mcapStructureReport{
+		Summary:       summary,
+		SummaryErr:    summaryErr,
+		ScanErr:       scanErr,
+		ChunkCount:    c.count,
+		ChunkFailures: append([]mcapChunkFailure(nil), c.failures...),
+	}
+	if summary != nil {
+		report.ChunkIndexCount = len(summary.ChunkIndexes)
+	}
+
+	if summary != nil && scanErr == nil {
+		for _, idx := range summary.ChunkIndexes {
+			offset := int64(idx.ChunkStartOffset)
+			length, ok := c.scanned[offset]
+			switch {
+			case !ok:
+				report.ChunkFailures = append(report.ChunkFailures, mcapChunkFailure{
+					Offset:           offset,
+					Length:           int64(idx.ChunkLength),
+					Compression:      idx.Compression,
+					MessageStartTime: idx.MessageStartTime,
+					MessageEndTime:   idx.MessageEndTime,
+					Error:            "chunk index points at an offset with no chunk record",
+				})
+			case length != int64(idx.ChunkLength):
+				report.ChunkFailures = append(report.ChunkFailures, mcapChunkFailure{
+					Offset:           offset,
+					Length:           int64(idx.ChunkLength),
+					Compression:      idx.Compression,
+					MessageStartTime: idx.MessageStartTime,
+					MessageEndTime:   idx.MessageEndTime,
+					Error:            fmt.Sprintf("chunk index length %d does not match chunk record length %d", idx.ChunkLength, length),
+				})
+			}
+		}
+	}
+	return report
+}
+
+func mcapChunkFailureFrom(chunk *mcap.Chunk, err error) mcapChunkFailure {
+	return mcapChunkFailure{
+		Offset:           chunk.Offset,
+		Length:           chunk.Length,
+		Compression:      chunk.Compression,
+		MessageStartTime: chunk.MessageStartTime,
+		MessageEndTime:   chunk.MessageEndTime,
+		Error:            err.Error(),
+	}
+}
+
+func evaluateMcapStructureCheck(report mcapStructureReport, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	summary := report.Summary
+	metadata["summary_present"] = summary.HasSummarySection()
+	metadata["chunk_count"] = report.ChunkCount
+	metadata["chunk_index_count"] = report.ChunkIndexCount
+	metadata["failed_chunk_count"] = len(report.ChunkFailures)
+	if summary != nil {
+		metadata["profile"] = summary.Header.Profile
+		metadata["library"] = summary.Header.Library
+		metadata["channel_count"] = len(summary.Channels)
+		metadata["schema_count"] = len(summary.Schemas)
+		if summary.Statistics != nil {
+			metadata["message_count"] = summary.Statistics.MessageCount
+		}
+	}
+	if report.SummaryErr != nil {
+		metadata["summary_error"] = report.SummaryErr.Error()
+	}
+	if report.ScanErr != nil {
+		metadata["scan_error"] = report.ScanErr.Error()
+	}
+	if len(report.ChunkFailures) > 0 {
+		failures := report.ChunkFailures
+		if len(failures) > maxEpisodeQAMcapChunkFailures {
+			failures = failures[:maxEpisodeQAMcapChunkFailures]
+			metadata["chunk_failures_truncated"] = true
+		}
+		items := make([]map[string]any, 0, len(failures))
+		for _, failure := range failures {
+			items = append(items, map[string]any{
+				"offset":             failure.Offset,
+				"length":             failure.Length,
+				"compression":        failure.Compression,
+				"message_start_time": failure.MessageStartTime,
+				"message_end_time":   failure.MessageEndTime,
+				"error":              failure.Error,
+			})
+		}
+		metadata["chunk_failures"] = items
+	}
+
+	// Header, footer or data section damage makes the whole file unreadable.
+	if summary == nil && report.SummaryErr != nil {
+		return mcapStructureFailure("MCAP structure check failed: "+report.SummaryErr.Error(), 0, metadata)
+	}
+	if report.ScanErr != nil {
+		return mcapStructureFailure("MCAP structure check failed: "+report.ScanErr.Error(), 0, metadata)
+	}
+
+	total := report.ChunkCount
+	if report.ChunkIndexCount > total {
+		total = report.ChunkIndexCount
+	}
+	score := 1.0
+	if total > 0 {
+		bad := len(report.ChunkFailures)
+		if bad > total {
+			bad = total
+		}
+		score = float64(total-bad) / float64(total)
+	}
+
+	if len(report.ChunkFailures) > 0 {
+		return mcapStructureFailure(fmt.Sprintf("MCAP structure check failed: %d of %d chunks failed verification", len(report.ChunkFailures), total), score, metadata)
+	}
+	if report.SummaryErr != nil {
+		// Chunks are intact but the index that readers seek with is not.
+		return mcapStructureFailure("MCAP structure check failed: "+report.SummaryErr.Error(), score/2, metadata)
+	}
+
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckMcapStructure,
+		Passed:    true,
+		Score:     1,
+		Details:   fmt.Sprintf("MCAP structure verified: %d chunks", report.ChunkCount),
+		Metadata:  metadata,
+	}
+}
+
+func mcapStructureFailure(details string, score float64, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckMcapStructure,
+		Passed:    false,
+		Score:     score,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
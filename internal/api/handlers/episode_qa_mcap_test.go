@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"fmt"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+func TestEvaluateMcapStructureCheck(t *testing.T) {
+	summary := &mcap.Summary{
+		Header:   &mcap.Header{Profile: "ros2", Library: "axon"},
+		Footer:   &mcap.Footer{SummaryStart: 1024},
+		Channels: map[uint16]*mcap.Channel{1: {ID: 1, Topic: "/camera"}},
+		Schemas:  map[uint16]*mcap.Schema{1: {ID: 1}},
+	}
+
+	tests := []struct {
+		name       string
+		report     mcapStructureReport
+		wantPassed bool
+		wantScore  float64
+		wantDetail string
+	}{
+		{
+			name:       "all chunks verified",
+			report:     mcapStructureReport{Summary: summary, ChunkCount: 4, ChunkIndexCount: 4},
+			wantPassed: true,
+			wantScore:  1,
+			wantDetail: "MCAP structure verified: 4 chunks",
+		},
+		{
+			name: "one corrupt chunk scales score",
+			report: mcapStructureReport{
+				Summary:         summary,
+				ChunkCount:      4,
+				ChunkIndexCount: 4,
+				ChunkFailures:   []mcapChunkFailure{{Offset: 4096, Error: "mcap: crc mismatch"}},
+			},
+			wantPassed: false,
+			wantScore:  0.75,
+			wantDetail: "MCAP structure check failed: 1 of 4 chunks failed verification",
+		},
+		{
+			name: "unreadable footer fails with zero score",
+			report: mcapStructureReport{
+				SummaryErr: fmt.Errorf("%w: trailing magic", mcap.ErrBadMagic),
+				ScanErr:    fmt.Errorf("%w: data section ended", mcap.ErrTruncated),
+				ChunkCount: 3,
+			},
+			wantPassed: false,
+			wantScore:  0,
+			wantDetail: "MCAP structure check failed: mcap: invalid magic: trailing magic",
+		},
+		{
+			name: "summary crc mismatch fails",
+			report: mcapStructureReport{
+				Summary:    summary,
+				SummaryErr: fmt.Errorf("%w: summary crc", mcap.ErrCRCMismatch),
+				ChunkCount: 2,
+			},
+			wantPassed: false,
+			wantScore:  0.5,
+			wantDetail: "MCAP structure check failed: mcap: crc mismatch: summary crc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateMcapStructureCheck(tt.report, nil)
+			if got.CheckName != episodeQACheckMcapStructure {
+				t.Fatalf("check name = %q", got.CheckName)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Fatalf("passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			if got.Score != tt.wantScore {
+				t.Fatalf("score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if got.Details != tt.wantDetail {
+				t.Fatalf("details = %q, want %q", got.Details, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestEvaluateMcapStructureCheckCapsChunkFailures(t *testing.T) {
+	failures := make([]mcapChunkFailure, maxEpisodeQAMcapChunkFailures+5)
+	for i := range failures {
+		failures[i] = mcapChunkFailure{Offset: int64(i * 100), Error: "mcap: crc mismatch"}
+	}
+	got := evaluateMcapStructureCheck(mcapStructureReport{
+		Summary:       &mcap.Summary{Header: &mcap.Header{}},
+		ChunkCount:    len(failures),
+		ChunkFailures: failures,
+	}, nil)
+
+	items, ok := got.Metadata["chunk_failures"].([]map[string]any)
+	if !ok {
+		t.Fatalf("chunk_failures metadata = %T", got.Metadata["chunk_failures"])
+	}
+	if len(items) != maxEpisodeQAMcapChunkFailures {
+		t.Fatalf("chunk_failures len = %d, want %d", len(items), maxEpisodeQAMcapChunkFailures)
+	}
+	if got.Metadata["failed_chunk_count"] != len(failures) {
+		t.Fatalf("failed_chunk_count = %v, want %d", got.Metadata["failed_chunk_count"], len(failures))
+	}
+	if got.Metadata["chunk_failures_truncated"] != true {
+		t.Fatalf("chunk_failures_truncated = %v, want true", got.Metadata["chunk_failures_truncated"])
+	}
+}
@@ -0,0 +1,687 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const maxQAProfileNameLength = 100
+
+// Binding bits rank profiles that bind the same number of dimensions; a SOP
+// is narrower than a scene, which is narrower than a robot type.
+const (
+	qaProfileBindsRobotType = 1 << iota
+	qaProfileBindsScene
+	qaProfileBindsSOP
+)
+
+// QAProfileCheck is one check in a QA profile.
+type QAProfileCheck struct {
+	Name string `json:"name" example:"message_gaps"`
+	// Weight overrides KEYSTONE_QA_CHECK_WEIGHTS for this check.
+	Weight *float64 `json:"weight,omitempty" example:"2"`
+	// Params are check-specific thresholds, validated by the check.
+	Params json.RawMessage `json:"params,omitempty" swaggertype:"object"`
+}
+
+// QAProfileRequest is the request body for creating or replacing a QA profile.
+type QAProfileRequest struct {
+	Name                 string           `json:"name" example:"dual-arm default"`
+	Description          string           `json:"description,omitempty"`
+	RobotTypeID          *int64           `json:"robot_type_id,omitempty"`
+	SceneID              *int64           `json:"scene_id,omitempty"`
+	SOPID                *int64           `json:"sop_id,omitempty"`
+	Checks               []QAProfileCheck `json:"checks"`
+	AutoApproveThreshold *float64         `json:"auto_approve_threshold,omitempty" example:"0.9"`
+	Enabled              *bool            `json:"enabled,omitempty"`
+}
+
+// QAProfileResponse is one QA profile.
+type QAProfileResponse struct {
+	ID                   int64            `json:"id"`
+	Name                 string           `json:"name"`
+	Description          string           `json:"description,omitempty"`
+	RobotTypeID          *int64           `json:"robot_type_id,omitempty"`
+	SceneID              *int64           `json:"scene_id,omitempty"`
+	SOPID                *int64           `json:"sop_id,omitempty"`
+	Checks               []QAProfileCheck `json:"checks"`
+	AutoApproveThreshold *float64         `json:"auto_approve_threshold,omitempty"`
+	Enabled              bool             `json:"enabled"`
+	CreatedAt            string           `json:"created_at,omitempty"`
+	UpdatedAt            string           `json:"updated_at,omitempty"`
+}
+
+// QAProfileListResponse is the QA profile list response.
+type QAProfileListResponse struct {
+	Items   []QAProfileResponse `json:"items"`
+	Total   int                 `json:"total"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+	HasNext bool                `json:"hasNext,omitempty"`
+	HasPrev bool                `json:"hasPrev,omitempty"`
+}
+
+type qaProfileRow struct {
+	ID                   int64           `db:"id"`
+	Name                 string          `db:"name"`
+	Description          sql.NullString  `db:"description"`
+	RobotTypeID          sql.NullInt64   `db:"robot_type_id"`
+	SceneID              sql.NullInt64   `db:"scene_id"`
+	SOPID                sql.NullInt64   `db:"sop_id"`
+	Checks               string          `db:"checks"`
+	AutoApproveThreshold sql.NullFloat64 `db:"auto_approve_threshold"`
+	Enabled              bool            `db:"enabled"`
+	CreatedAt            sql.NullTime    `db:"created_at"`
+	UpdatedAt            sql.NullTime    `db:"updated_at"`
+}
+
+const qaProfileColumns = `id, name, description, robot_type_id, scene_id, sop_id, checks, auto_approve_threshold, enabled, created_at, updated_at`
+
+// episodeQASuitePlan is the resolved suite for one episode run.
+type episodeQASuitePlan struct {
+	ProfileID   int64
+	ProfileName string
+	Checks      []string
+	Params      map[string]json.RawMessage
+	// Weights override the configured check weights.
+	Weights map[string]float64
+	// Threshold overrides the configured auto-approve threshold when > 0.
+	Threshold float64
+}
+
+func (h *EpisodeQAHandler) planCheckWeight(plan episodeQASuitePlan, checkName string) float64 {
+	if weight, ok := plan.Weights[checkName]; ok {
+		return weight
+	}
+	return h.episodeQACheckWeight(checkName)
+}
+
+// RegisterProfileRoutes registers QA profile CRUD routes. The group is
+// expected to enforce admin authentication.
+func (h *EpisodeQAHandler) RegisterProfileRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/profiles", h.ListQAProfiles)
+	apiV1.POST("/qa/profiles", h.CreateQAProfile)
+	apiV1.GET("/qa/profiles/:id", h.GetQAProfile)
+	apiV1.PUT("/qa/profiles/:id", h.UpdateQAProfile)
+	apiV1.DELETE("/qa/profiles/:id", h.DeleteQAProfile)
+}
+
+// ListQAProfiles lists QA profiles.
+//
+// @Summary      List QA profiles
+// @Description  Lists QA profiles with pagination, optionally filtered by binding
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        robot_type_id query int false "Filter by bound robot type"
+// @Param        scene_id      query int false "Filter by bound scene"
+// @Param        sop_id        query int false "Filter by bound SOP"
+// @Param        limit         query int false "Max results (default 50, max 100)"
+// @Param        offset        query int false "Pagination offset (default 0)"
+// @Success      200 {object} QAProfileListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/profiles [get]
+func (h *EpisodeQAHandler) ListQAProfiles(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	whereClause := "WHERE deleted_at IS NULL"
+	args := []any{}
+	for _, column := range []string{"robot_type_id", "scene_id", "sop_id"} {
+		raw := strings.TrimSpace(c.Query(column))
+		if raw == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + column})
+			return
+		}
+		whereClause += " AND " + column + " = ?"
+		args = append(args, id)
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(*) FROM qa_profiles "+whereClause, args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to count qa profiles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa profiles"})
+		return
+	}
+
+	var rows []qaProfileRow
+	query := "SELECT " + qaProfileColumns + " FROM qa_profiles " + whereClause + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	if err := h.db.Select(&rows, query, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa profiles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa profiles"})
+		return
+	}
+
+	items := make([]QAProfileResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, qaProfileResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, QAProfileListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// GetQAProfile gets one QA profile.
+//
+// @Summary      Get QA profile
+// @Description  Gets a QA profile by ID
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "QA profile ID"
+// @Success      200  {object}  QAProfileResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /qa/profiles/{id} [get]
+func (h *EpisodeQAHandler) GetQAProfile(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAProfileIDParam(c)
+	if !ok {
+		return
+	}
+	row, err := h.loadQAProfile(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa profile not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get qa profile"})
+		return
+	}
+	c.JSON(http.StatusOK, qaProfileResponseFromRow(row))
+}
+
+// CreateQAProfile creates a QA profile.
+//
+// @Summary      Create QA profile
+// @Description  Creates a QA profile binding a check suite, weights and thresholds to a robot type, scene and/or SOP
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        body  body      QAProfileRequest  true  "QA profile payload"
+// @Success      201   {object}  QAProfileResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /qa/profiles [post]
+func (h *EpisodeQAHandler) CreateQAProfile(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var req QAProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	checksJSON, ok := h.validateQAProfileRequest(c, &req, 0)
+	if !ok {
+		return
+	}
+
+	enabled := req.Enabled == nil || *req.Enabled
+	now := time.Now().UTC()
+	// #nosec G701 -- static SQL with placeholder-bound QA profile values.
+	result, err := h.db.Exec(`
+		INSERT INTO qa_profiles (name, description, robot_type_id, scene_id, sop_id, checks, auto_approve_threshold, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, req.Name, sql.NullString{String: req.Description, Valid: req.Description != ""}, sqlNullInt64FromPtr(req.RobotTypeID), sqlNullInt64FromPtr(req.SceneID), sqlNullInt64FromPtr(req.SOPID),
+		checksJSON, sqlNullFloat64FromPtr(req.AutoApproveThreshold), enabled, now, now)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to insert qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa profile"})
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch inserted qa profile id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa profile"})
+		return
+	}
+
+	row, err := h.loadQAProfile(id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch created qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get created qa profile"})
+		return
+	}
+	c.JSON(http.StatusCreated, qaProfileResponseFromRow(row))
+}
+
+// UpdateQAProfile replaces a QA profile.
+//
+// @Summary      Update QA profile
+// @Description  Replaces a QA profile's bindings, checks and threshold
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int               true  "QA profile ID"
+// @Param        body  body      QAProfileRequest  true  "QA profile payload"
+// @Success      200   {object}  QAProfileResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /qa/profiles/{id} [put]
+func (h *EpisodeQAHandler) UpdateQAProfile(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAProfileIDParam(c)
+	if !ok {
+		return
+	}
+	var req QAProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	current, err := h.loadQAProfile(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa profile not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update qa profile"})
+		return
+	}
+	checksJSON, ok := h.validateQAProfileRequest(c, &req, id)
+	if !ok {
+		return
+	}
+
+	enabled := current.Enabled
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	// #nosec G701 -- static SQL with placeholder-bound QA profile values.
+	if _, err := h.db.Exec(`
+		UPDATE qa_profiles
+		SET name = ?, description = ?, robot_type_id = ?, scene_id = ?, sop_id = ?, checks = ?, auto_approve_threshold = ?, enabled = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, req.Name, sql.NullString{String: req.Description, Valid: req.Description != ""}, sqlNullInt64FromPtr(req.RobotTypeID), sqlNullInt64FromPtr(req.SceneID), sqlNullInt64FromPtr(req.SOPID),
+		checksJSON, sqlNullFloat64FromPtr(req.AutoApproveThreshold), enabled, time.Now().UTC(), id); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to update qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update qa profile"})
+		return
+	}
+
+	row, err := h.loadQAProfile(id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch updated qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get updated qa profile"})
+		return
+	}
+	c.JSON(http.StatusOK, qaProfileResponseFromRow(row))
+}
+
+// DeleteQAProfile soft deletes a QA profile.
+//
+// @Summary      Delete QA profile
+// @Description  Soft deletes a QA profile; matching episodes fall back to the next most specific profile
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "QA profile ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/profiles/{id} [delete]
+func (h *EpisodeQAHandler) DeleteQAProfile(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAProfileIDParam(c)
+	if !ok {
+		return
+	}
+	// #nosec G701 -- static SQL with placeholder-bound QA profile values.
+	result, err := h.db.Exec("UPDATE qa_profiles SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to delete qa profile: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete qa profile"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa profile not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func parseQAProfileIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid qa profile id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *EpisodeQAHandler) loadQAProfile(id int64) (qaProfileRow, error) {
+	var row qaProfileRow
+	err := h.db.Get(&row, "SELECT "+qaProfileColumns+" FROM qa_profiles WHERE id = ? AND deleted_at IS NULL", id)
+	return row, err
+}
+
+// validateQAProfileRequest normalizes req in place and returns the checks
+// JSON to store. It writes the error response itself and returns false on
+// failure. selfID excludes the profile being replaced from the binding
+// conflict check.
+func (h *EpisodeQAHandler) validateQAProfileRequest(c *gin.Context, req *QAProfileRequest, selfID int64) (string, bool) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return "", false
+	}
+	if len(req.Name) > maxQAProfileNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name must be at most %d characters", maxQAProfileNameLength)})
+		return "", false
+	}
+	if req.AutoApproveThreshold != nil && (*req.AutoApproveThreshold <= 0 || *req.AutoApproveThreshold > 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "auto_approve_threshold must be in (0, 1]"})
+		return "", false
+	}
+
+	checks, err := normalizeQAProfileChecks(req.Checks, h.checkRegistry())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	req.Checks = checks
+	checksJSON, err := json.Marshal(checks)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checks"})
+		return "", false
+	}
+
+	bindings := []struct {
+		field string
+		table string
+		id    *int64
+	}{
+		{"robot_type_id", "robot_types", req.RobotTypeID},
+		{"scene_id", "scenes", req.SceneID},
+		{"sop_id", "sops", req.SOPID},
+	}
+	for _, binding := range bindings {
+		if binding.id == nil {
+			continue
+		}
+		if *binding.id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + binding.field})
+			return "", false
+		}
+		var exists bool
+		// #nosec G202 -- table name comes from the fixed binding list above.
+		if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM "+binding.table+" WHERE id = ? AND deleted_at IS NULL)", *binding.id); err != nil {
+			logger.Printf("[EPISODE-QA] Failed to check qa profile %s: %v", binding.field, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate qa profile"})
+			return "", false
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": binding.field + " not found"})
+			return "", false
+		}
+	}
+
+	var conflict bool
+	err = h.db.Get(&conflict, `
+		SELECT EXISTS(
+			SELECT 1 FROM qa_profiles
+			WHERE deleted_at IS NULL AND id <> ?
+			  AND (robot_type_id = ? OR (robot_type_id IS NULL AND ? IS NULL))
+			  AND (scene_id = ? OR (scene_id IS NULL AND ? IS NULL))
+			  AND (sop_id = ? OR (sop_id IS NULL AND ? IS NULL))
+		)
+	`, selfID,
+		sqlNullInt64FromPtr(req.RobotTypeID), sqlNullInt64FromPtr(req.RobotTypeID),
+		sqlNullInt64FromPtr(req.SceneID), sqlNullInt64FromPtr(req.SceneID),
+		sqlNullInt64FromPtr(req.SOPID), sqlNullInt64FromPtr(req.SOPID))
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to check qa profile binding conflict: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate qa profile"})
+		return "", false
+	}
+	if conflict {
+		c.JSON(http.StatusConflict, gin.H{"error": "a qa profile with the same robot_type_id, scene_id and sop_id already exists"})
+		return "", false
+	}
+	return string(checksJSON), true
+}
+
+// normalizeQAProfileChecks canonicalizes check names and validates weights
+// and params against the registered checks.
+func normalizeQAProfileChecks(checks []QAProfileCheck, registry *QACheckRegistry) ([]QAProfileCheck, error) {
+	if len(checks) == 0 {
+		return nil, errors.New("checks must not be empty")
+	}
+	seen := map[string]struct{}{}
+	out := make([]QAProfileCheck, 0, len(checks))
+	for _, item := range checks {
+		name := canonicalEpisodeQACheckName(item.Name)
+		check, ok := registry.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown qa check %q (registered: %s)", item.Name, strings.Join(registry.Names(), ", "))
+		}
+		if _, dup := seen[name]; dup {
+			return nil, fmt.Errorf("qa check %q is listed more than once", name)
+		}
+		seen[name] = struct{}{}
+		if item.Weight != nil && (*item.Weight < 0 || *item.Weight > maxEpisodeQACheckWeight) {
+			return nil, fmt.Errorf("weight for %q must be in [0, %.3f]", name, maxEpisodeQACheckWeight)
+		}
+		if err := check.ValidateParams(item.Params); err != nil {
+			return nil, fmt.Errorf("params for %q: %w", name, err)
+		}
+		if isEmptyQACheckParams(item.Params) {
+			item.Params = nil
+		}
+		item.Name = name
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func qaProfileResponseFromRow(row qaProfileRow) QAProfileResponse {
+	resp := QAProfileResponse{
+		ID:                   row.ID,
+		Name:                 row.Name,
+		Description:          nullStringValue(row.Description),
+		RobotTypeID:          nullableInt64(row.RobotTypeID),
+		SceneID:              nullableInt64(row.SceneID),
+		SOPID:                nullableInt64(row.SOPID),
+		Checks:               []QAProfileCheck{},
+		AutoApproveThreshold: nullableFloat64(row.AutoApproveThreshold),
+		Enabled:              row.Enabled,
+	}
+	if err := json.Unmarshal([]byte(row.Checks), &resp.Checks); err != nil {
+		logger.Printf("[EPISODE-QA] Invalid checks JSON on qa profile %d: %v", row.ID, err)
+	}
+	if row.CreatedAt.Valid {
+		resp.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if row.UpdatedAt.Valid {
+		resp.UpdatedAt = row.UpdatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// qaProfileSpecificity orders matching profiles: more bound dimensions win,
+// then the narrower dimension.
+func qaProfileSpecificity(row qaProfileRow) (int, int) {
+	count, bits := 0, 0
+	if row.RobotTypeID.Valid {
+		count++
+		bits |= qaProfileBindsRobotType
+	}
+	if row.SceneID.Valid {
+		count++
+		bits |= qaProfileBindsScene
+	}
+	if row.SOPID.Valid {
+		count++
+		bits |= qaProfileBindsSOP
+	}
+	return count, bits
+}
+
+// pickMostSpecificQAProfile returns the most specific of the matching
+// profiles; ties go to the newest profile.
+func pickMostSpecificQAProfile(rows []qaProfileRow) (qaProfileRow, bool) {
+	if len(rows) == 0 {
+		return qaProfileRow{}, false
+	}
+	best := rows[0]
+	bestCount, bestBits := qaProfileSpecificity(best)
+	for _, row := range rows[1:] {
+		count, bits := qaProfileSpecificity(row)
+		if count > bestCount ||
+			(count == bestCount && bits > bestBits) ||
+			(count == bestCount && bits == bestBits && row.ID > best.ID) {
+			best, bestCount, bestBits = row, count, bits
+		}
+	}
+	return best, true
+}
+
+// resolveEpisodeQASuitePlan picks the episode's most specific enabled QA
+// profile, falling back to KEYSTONE_QA_CHECKS when none matches.
+func (h *EpisodeQAHandler) resolveEpisodeQASuitePlan(ctx context.Context, row episodeQACheckRow) (episodeQASuitePlan, error) {
+	fallback := episodeQASuitePlan{Checks: h.episodeQASuite(row)}
+	if h.db == nil {
+		return fallback, nil
+	}
+
+	var binding struct {
+		SceneID     sql.NullInt64 `db:"scene_id"`
+		SOPID       sql.NullInt64 `db:"sop_id"`
+		RobotTypeID sql.NullInt64 `db:"robot_type_id"`
+	}
+	err := h.db.GetContext(ctx, &binding, `
+		SELECT e.scene_id, e.sop_id, r.robot_type_id
+		FROM episodes e
+		LEFT JOIN workstations ws ON ws.id = e.workstation_id
+		LEFT JOIN robots r ON r.id = ws.robot_id
+		WHERE e.id = ?
+		LIMIT 1
+	`, row.ID)
+	if err == sql.ErrNoRows {
+		return fallback, errEpisodeQANotFound
+	}
+	if err != nil {
+		return fallback, fmt.Errorf("query episode qa profile binding: %w", err)
+	}
+
+	var profiles []qaProfileRow
+	err = h.db.SelectContext(ctx, &profiles, `
+		SELECT `+qaProfileColumns+`
+		FROM qa_profiles
+		WHERE deleted_at IS NULL AND enabled = TRUE
+		  AND (robot_type_id IS NULL OR robot_type_id = ?)
+		  AND (scene_id IS NULL OR scene_id = ?)
+		  AND (sop_id IS NULL OR sop_id = ?)
+	`, binding.RobotTypeID, binding.SceneID, binding.SOPID)
+	if err != nil {
+		return fallback, fmt.Errorf("query qa profiles: %w", err)
+	}
+	profile, ok := pickMostSpecificQAProfile(profiles)
+	if !ok {
+		return fallback, nil
+	}
+
+	plan, err := h.qaSuitePlanFromProfile(profile)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Ignoring qa profile %d for episode %d: %v", profile.ID, row.ID, err)
+		return fallback, nil
+	}
+	return plan, nil
+}
+
+func (h *EpisodeQAHandler) qaSuitePlanFromProfile(profile qaProfileRow) (episodeQASuitePlan, error) {
+	var checks []QAProfileCheck
+	if err := json.Unmarshal([]byte(profile.Checks), &checks); err != nil {
+		return episodeQASuitePlan{}, fmt.Errorf("decode checks: %w", err)
+	}
+	checks, err := normalizeQAProfileChecks(checks, h.checkRegistry())
+	if err != nil {
+		return episodeQASuitePlan{}, err
+	}
+
+	plan := episodeQASuitePlan{
+		ProfileID:   profile.ID,
+		ProfileName: profile.Name,
+		Checks:      make([]string, 0, len(checks)),
+		Params:      map[string]json.RawMessage{},
+		Weights:     map[string]float64{},
+	}
+	for _, check := range checks {
+		plan.Checks = append(plan.Checks, check.Name)
+		if check.Params != nil {
+			plan.Params[check.Name] = check.Params
+		}
+		if check.Weight != nil {
+			plan.Weights[check.Name] = *check.Weight
+		}
+	}
+	if profile.AutoApproveThreshold.Valid {
+		plan.Threshold = profile.AutoApproveThreshold.Float64
+	}
+	return plan, nil
+}
+
+func sqlNullInt64FromPtr(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+func sqlNullFloat64FromPtr(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
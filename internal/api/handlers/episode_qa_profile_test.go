@@ -0,0 +1,301 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+type stubQACheck struct {
+	name string
+}
+
+func (c stubQACheck) Name() string                           { return c.name }
+func (c stubQACheck) Blocking() bool                         { return false }
+func (c stubQACheck) ValidateParams(_ json.RawMessage) error { return nil }
+func (c stubQACheck) Run(_ context.Context, _ *EpisodeQAHandler, _ episodeQACheckRow, _ json.RawMessage) (episodeQACheckOutcome, error) {
+	return episodeQACheckOutcome{Passed: true, Score: 1}, nil
+}
+
+func TestQACheckRegistry(t *testing.T) {
+	r := newBuiltinQACheckRegistry()
+	if !r.isBlocking(episodeQACheckMcapMagic) || r.isBlocking(episodeQACheckMessageGaps) {
+		t.Fatalf("unexpected blocking flags")
+	}
+	if err := r.Register(stubQACheck{name: "Arm_Joint_Limits"}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if _, ok := r.Lookup("arm_joint_limits"); !ok {
+		t.Fatalf("registered check not found")
+	}
+	if err := r.Register(stubQACheck{name: "arm_joint_limits"}); err == nil {
+		t.Fatalf("duplicate registration succeeded")
+	}
+	if err := r.Register(stubQACheck{name: "gaps"}); err == nil {
+		t.Fatalf("alias registration succeeded")
+	}
+
+	h := NewEpisodeQAHandler(nil, nil, "", &config.QAConfig{Checks: []string{"mcap_magic", "arm_joint_limits"}})
+	if got := h.episodeQASuite(episodeQACheckRow{}); !reflect.DeepEqual(got, []string{"mcap_magic"}) {
+		t.Fatalf("suite before register = %v", got)
+	}
+	if err := h.RegisterQACheck(stubQACheck{name: "arm_joint_limits"}); err != nil {
+		t.Fatalf("RegisterQACheck: %v", err)
+	}
+	if got := h.episodeQASuite(episodeQACheckRow{}); !reflect.DeepEqual(got, []string{"mcap_magic", "arm_joint_limits"}) {
+		t.Fatalf("suite after register = %v", got)
+	}
+	outcome, err := h.runEpisodeQACheck(context.Background(), "arm_joint_limits", episodeQACheckRow{}, nil)
+	if err != nil || outcome.CheckName != "arm_joint_limits" {
+		t.Fatalf("run custom check = %+v, %v", outcome, err)
+	}
+}
+
+func TestNormalizeQAProfileChecks(t *testing.T) {
+	weight := 2.0
+	checks, err := normalizeQAProfileChecks([]QAProfileCheck{
+		{Name: "Gaps", Weight: &weight, Params: json.RawMessage(`{"max_gap_sec": 0.5}`)},
+		{Name: "mcap_magic", Params: json.RawMessage(`{}`)},
+	}, builtinQAChecks)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if checks[0].Name != episodeQACheckMessageGaps || checks[1].Params != nil {
+		t.Fatalf("normalized checks = %+v", checks)
+	}
+
+	bad := []struct {
+		name   string
+		checks []QAProfileCheck
+	}{
+		{name: "empty"},
+		{name: "unknown", checks: []QAProfileCheck{{Name: "bogus"}}},
+		{name: "duplicate", checks: []QAProfileCheck{{Name: "topics"}, {Name: "required_topics"}}},
+		{name: "unknown param", checks: []QAProfileCheck{{Name: "gaps", Params: json.RawMessage(`{"max_gap": 1}`)}}},
+		{name: "bad param value", checks: []QAProfileCheck{{Name: "images", Params: json.RawMessage(`{"max_failure_rate": 2}`)}}},
+		{name: "params on paramless check", checks: []QAProfileCheck{{Name: "mcap_magic", Params: json.RawMessage(`{"x": 1}`)}}},
+	}
+	for _, tt := range bad {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := normalizeQAProfileChecks(tt.checks, builtinQAChecks); err == nil {
+				t.Fatalf("expected error")
+			}
+		})
+	}
+}
+
+func TestPickMostSpecificQAProfile(t *testing.T) {
+	id := func(v int64) sql.NullInt64 { return sql.NullInt64{Int64: v, Valid: true} }
+	rows := []qaProfileRow{
+		{ID: 1},
+		{ID: 2, RobotTypeID: id(7)},
+		{ID: 3, SceneID: id(3)},
+		{ID: 4, RobotTypeID: id(7), SceneID: id(3)},
+		{ID: 5, SOPID: id(9)},
+	}
+	tests := []struct {
+		name string
+		rows []qaProfileRow
+		want int64
+	}{
+		{name: "most bindings wins", rows: rows, want: 4},
+		{name: "scene beats robot type", rows: rows[:3:3], want: 3},
+		{name: "sop beats scene", rows: []qaProfileRow{rows[2], rows[4]}, want: 5},
+		{name: "newest wins ties", rows: []qaProfileRow{rows[1], {ID: 6, RobotTypeID: id(7)}}, want: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := pickMostSpecificQAProfile(tt.rows)
+			if !ok || got.ID != tt.want {
+				t.Fatalf("picked %d, want %d", got.ID, tt.want)
+			}
+		})
+	}
+	if _, ok := pickMostSpecificQAProfile(nil); ok {
+		t.Fatalf("picked a profile from an empty list")
+	}
+}
+
+func TestResolveEpisodeQASuitePlan(t *testing.T) {
+	db := setupQAProfileTestDB(t)
+	execQAProfileTestSQL(t, db, `INSERT INTO qa_profiles (id, name, robot_type_id, scene_id, sop_id, checks, auto_approve_threshold) VALUES
+		(1, 'global', NULL, NULL, NULL, '[{"name":"mcap_magic"}]', NULL),
+		(2, 'dual-arm', 7, NULL, NULL, '[{"name":"mcap_magic"},{"name":"image_integrity"}]', NULL),
+		(3, 'dual-arm kitchen', 7, 3, NULL, '[{"name":"mcap_magic"},{"name":"message_gaps","weight":3,"params":{"max_gap_sec":0.25}}]', 0.8)`)
+	execQAProfileTestSQL(t, db, `INSERT INTO qa_profiles (id, name, robot_type_id, checks, enabled) VALUES (4, 'disabled', 7, '[{"name":"mcap_structure"}]', 0)`)
+
+	h := &EpisodeQAHandler{db: db}
+	plan, err := h.resolveEpisodeQASuitePlan(context.Background(), episodeQACheckRow{ID: 1})
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if plan.ProfileID != 3 || !reflect.DeepEqual(plan.Checks, []string{"mcap_magic", "message_gaps"}) {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if plan.Threshold != 0.8 || plan.Weights["message_gaps"] != 3 || h.planCheckWeight(plan, "mcap_magic") != 1 {
+		t.Fatalf("plan threshold/weights = %v / %v", plan.Threshold, plan.Weights)
+	}
+	thresholds, err := h.messageGapThresholds(plan.Params["message_gaps"])
+	if err != nil || thresholds.MaxGapSeconds != 0.25 {
+		t.Fatalf("gap thresholds = %+v, %v", thresholds, err)
+	}
+
+	// Episode 2 is on another scene, so only the robot type profile matches.
+	plan, err = h.resolveEpisodeQASuitePlan(context.Background(), episodeQACheckRow{ID: 2})
+	if err != nil || plan.ProfileID != 2 {
+		t.Fatalf("plan = %+v, %v", plan, err)
+	}
+
+	// Episode 3's robot has another type; the unbound profile applies.
+	plan, err = h.resolveEpisodeQASuitePlan(context.Background(), episodeQACheckRow{ID: 3})
+	if err != nil || plan.ProfileID != 1 {
+		t.Fatalf("plan = %+v, %v", plan, err)
+	}
+
+	execQAProfileTestSQL(t, db, `UPDATE qa_profiles SET deleted_at = CURRENT_TIMESTAMP WHERE id = 1`)
+	plan, err = h.resolveEpisodeQASuitePlan(context.Background(), episodeQACheckRow{ID: 3})
+	if err != nil || plan.ProfileID != 0 || !reflect.DeepEqual(plan.Checks, defaultEpisodeQASuite(episodeQACheckRow{})) {
+		t.Fatalf("fallback plan = %+v, %v", plan, err)
+	}
+}
+
+func TestQAProfileCRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupQAProfileTestDB(t)
+	router := gin.New()
+	(&EpisodeQAHandler{db: db}).RegisterProfileRoutes(router.Group("/api/v1"))
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	robotType := int64(7)
+	create := map[string]any{
+		"name":          "dual-arm",
+		"robot_type_id": robotType,
+		"checks":        []map[string]any{{"name": "magic"}, {"name": "topics", "params": map[string]any{"topics": []string{"/joint_states"}}}},
+	}
+	rec := do(http.MethodPost, "/api/v1/qa/profiles", create)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown check status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	create["checks"] = []map[string]any{{"name": "mcap_magic"}, {"name": "topics", "params": map[string]any{"topics": []string{"/joint_states"}}}}
+	rec = do(http.MethodPost, "/api/v1/qa/profiles", create)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var created QAProfileResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if created.Checks[1].Name != episodeQACheckRequiredTopics || !created.Enabled || created.RobotTypeID == nil || *created.RobotTypeID != 7 {
+		t.Fatalf("created = %+v", created)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/qa/profiles", create); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate binding status = %d", rec.Code)
+	}
+	create["robot_type_id"] = 99
+	if rec := do(http.MethodPost, "/api/v1/qa/profiles", create); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing robot type status = %d", rec.Code)
+	}
+
+	update := map[string]any{"name": "dual-arm v2", "robot_type_id": robotType, "checks": []map[string]any{{"name": "gaps"}}, "auto_approve_threshold": 0.95}
+	rec = do(http.MethodPut, "/api/v1/qa/profiles/1", update)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var updated QAProfileResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if updated.Name != "dual-arm v2" || updated.AutoApproveThreshold == nil || *updated.AutoApproveThreshold != 0.95 {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/qa/profiles?robot_type_id=7", nil); rec.Code != http.StatusOK || !bytes.Contains(rec.Body.Bytes(), []byte(`"total":1`)) {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodDelete, "/api/v1/qa/profiles/1", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/qa/profiles/1", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted status = %d", rec.Code)
+	}
+}
+
+func setupQAProfileTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Fatalf("close sqlite: %v", err)
+		}
+	})
+
+	execQAProfileTestSQL(t, db, `
+		CREATE TABLE robot_types (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NULL);
+		CREATE TABLE scenes (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NULL);
+		CREATE TABLE sops (id INTEGER PRIMARY KEY, deleted_at TIMESTAMP NULL);
+		CREATE TABLE robots (id INTEGER PRIMARY KEY, robot_type_id INTEGER NOT NULL);
+		CREATE TABLE workstations (id INTEGER PRIMARY KEY, robot_id INTEGER NOT NULL);
+		CREATE TABLE episodes (id INTEGER PRIMARY KEY, scene_id INTEGER NOT NULL, sop_id INTEGER, workstation_id INTEGER);
+		CREATE TABLE qa_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			robot_type_id INTEGER NULL,
+			scene_id INTEGER NULL,
+			sop_id INTEGER NULL,
+			checks TEXT NOT NULL,
+			auto_approve_threshold REAL NULL,
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+		INSERT INTO robot_types (id) VALUES (7), (8);
+		INSERT INTO scenes (id) VALUES (3), (4);
+		INSERT INTO robots (id, robot_type_id) VALUES (1, 7), (2, 8);
+		INSERT INTO workstations (id, robot_id) VALUES (10, 1), (20, 2);
+		INSERT INTO episodes (id, scene_id, sop_id, workstation_id) VALUES (1, 3, NULL, 10), (2, 4, NULL, 10), (3, 3, NULL, 20);
+	`)
+	return db
+}
+
+func execQAProfileTestSQL(t *testing.T, db *sqlx.DB, query string) {
+	t.Helper()
+	if _, err := db.Exec(query); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
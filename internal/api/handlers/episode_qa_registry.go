@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// QACheck is one episode QA check. Checks are registered by name and selected
+// through KEYSTONE_QA_CHECKS or the episode's QA profile.
+type QACheck interface {
+	// Name is the check_name persisted on qa_checks rows.
+	Name() string
+	// Blocking reports whether a failure means the recording itself is
+	// unusable, which fails the episode regardless of its score.
+	Blocking() bool
+	// ValidateParams rejects profile parameters the check cannot use.
+	ValidateParams(params json.RawMessage) error
+	// Run executes the check against one episode. params are the check's
+	// parameters from the QA profile and may be empty.
+	Run(ctx context.Context, h *EpisodeQAHandler, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error)
+}
+
+// QACheckResolver resolves check names under a registered prefix, for checks
+// defined at runtime such as external scripts.
+type QACheckResolver func(name string) (QACheck, bool)
+
+// QACheckRegistry holds the checks the QA engine can run.
+type QACheckRegistry struct {
+	mu        sync.RWMutex
+	checks    map[string]QACheck
+	resolvers map[string]QACheckResolver
+}
+
+// NewQACheckRegistry creates an empty registry.
+func NewQACheckRegistry() *QACheckRegistry {
+	return &QACheckRegistry{checks: map[string]QACheck{}, resolvers: map[string]QACheckResolver{}}
+}
+
+// Register adds a check. Names are case-insensitive and must be unique.
+func (r *QACheckRegistry) Register(check QACheck) error {
+	if check == nil {
+		return fmt.Errorf("qa check is nil")
+	}
+	name := normalizeEpisodeQACheckName(check.Name())
+	if name == "" {
+		return fmt.Errorf("qa check name is required")
+	}
+	if _, ok := episodeQACheckAliases[name]; ok {
+		return fmt.Errorf("qa check name %q is reserved as an alias", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.checks[name]; ok {
+		return fmt.Errorf("qa check %q is already registered", name)
+	}
+	r.checks[name] = check
+	return nil
+}
+
+// RegisterResolver resolves every check name starting with prefix through
+// resolve. Exact registrations take precedence.
+func (r *QACheckRegistry) RegisterResolver(prefix string, resolve QACheckResolver) error {
+	prefix = normalizeEpisodeQACheckName(prefix)
+	if prefix == "" || resolve == nil {
+		return fmt.Errorf("qa check resolver needs a prefix and a resolve func")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.resolvers[prefix]; ok {
+		return fmt.Errorf("qa check resolver %q is already registered", prefix)
+	}
+	r.resolvers[prefix] = resolve
+	return nil
+}
+
+// Lookup returns the check registered under name.
+func (r *QACheckRegistry) Lookup(name string) (QACheck, bool) {
+	name = normalizeEpisodeQACheckName(name)
+	r.mu.RLock()
+	check, ok := r.checks[name]
+	var resolve QACheckResolver
+	if !ok {
+		for prefix, fn := range r.resolvers {
+			if strings.HasPrefix(name, prefix) {
+				resolve = fn
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+	if ok || resolve == nil {
+		return check, ok
+	}
+	// Resolvers may hit the database, so they run outside the lock.
+	return resolve(name)
+}
+
+// Names returns the registered check names in sorted order. Resolver
+// prefixes are listed with a trailing "*".
+func (r *QACheckRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	for prefix := range r.resolvers {
+		names = append(names, prefix+"*")
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *QACheckRegistry) isBlocking(name string) bool {
+	check, ok := r.Lookup(name)
+	return ok && check.Blocking()
+}
+
+// qaCheckFunc runs one check bound to a handler.
+type qaCheckFunc func(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error)
+
+// builtinQACheck adapts a compiled-in handler check to QACheck. method, or
+// scan for checks that stream the MCAP data section, binds the check to the
+// handler running it.
+type builtinQACheck struct {
+	name     string
+	blocking bool
+	validate func(params json.RawMessage) error
+	method   func(h *EpisodeQAHandler) qaCheckFunc
+	scan     func(h *EpisodeQAHandler) qaScanFunc
+}
+
+func (c builtinQACheck) Name() string   { return c.name }
+func (c builtinQACheck) Blocking() bool { return c.blocking }
+
+func (c builtinQACheck) ValidateParams(params json.RawMessage) error {
+	if c.validate == nil {
+		if !isEmptyQACheckParams(params) {
+			return fmt.Errorf("qa check %q does not take params", c.name)
+		}
+		return nil
+	}
+	return c.validate(params)
+}
+
+func (c builtinQACheck) Run(ctx context.Context, h *EpisodeQAHandler, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	if c.scan == nil {
+		return c.method(h)(ctx, row, params)
+	}
+	consumer, err := c.scan(h)(ctx, row, params)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	return h.runMcapScanCheck(ctx, row, consumer)
+}
+
+func (c builtinQACheck) PrepareScan(ctx context.Context, h *EpisodeQAHandler, row episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error) {
+	if c.scan == nil {
+		return nil, nil
+	}
+	return c.scan(h)(ctx, row, params)
+}
+
+// withoutQAParams adapts a check that takes no profile parameters.
+func withoutQAParams(run func(ctx context.Context, row episodeQACheckRow) (episodeQACheckOutcome, error)) qaCheckFunc {
+	return func(ctx context.Context, row episodeQACheckRow, _ json.RawMessage) (episodeQACheckOutcome, error) {
+		return run(ctx, row)
+	}
+}
+
+// builtinQAChecks backs handlers built without NewEpisodeQAHandler.
+var builtinQAChecks = newBuiltinQACheckRegistry()
+
+func newBuiltinQACheckRegistry() *QACheckRegistry {
+	r := NewQACheckRegistry()
+	for _, check := range []QACheck{
+		builtinQACheck{name: episodeQACheckMcapMagic, blocking: true, method: func(h *EpisodeQAHandler) qaCheckFunc { return withoutQAParams(h.runMcapMagicQACheck) }},
+		builtinQACheck{name: episodeQACheckMcapStructure, blocking: true, scan: func(h *EpisodeQAHandler) qaScanFunc { return h.prepareMcapStructureScan }},
+		builtinQACheck{name: episodeQACheckRecordingNotEmpty, blocking: true, method: func(h *EpisodeQAHandler) qaCheckFunc { return withoutQAParams(h.runRecordingNotEmptyQACheck) }},
+		builtinQACheck{name: episodeQACheckRequiredTopics, validate: validateRequiredTopicsParams, method: func(h *EpisodeQAHandler) qaCheckFunc { return h.runRequiredTopicsQACheck }},
+		builtinQACheck{name: episodeQACheckMessageGaps, validate: validateMessageGapParams, scan: func(h *EpisodeQAHandler) qaScanFunc { return h.prepareMessageGapsScan }},
+		builtinQACheck{name: episodeQACheckImageIntegrity, validate: validateImageIntegrityParams, scan: func(h *EpisodeQAHandler) qaScanFunc { return h.prepareImageIntegrityScan }},
+		builtinQACheck{name: episodeQACheckTimestamps, validate: validateTimestampParams, scan: func(h *EpisodeQAHandler) qaScanFunc { return h.prepareTimestampsScan }},
+		builtinQACheck{name: episodeQACheckWriterHealth, validate: validateWriterHealthParams, method: func(h *EpisodeQAHandler) qaCheckFunc { return h.runWriterHealthQACheck }},
+		builtinQACheck{name: episodeQACheckChecksumMatch, blocking: true, validate: validateChecksumMatchParams, scan: func(h *EpisodeQAHandler) qaScanFunc { return h.prepareChecksumMatchScan }},
+	} {
+		if err := r.Register(check); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func isEmptyQACheckParams(params json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(params)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte("{}"))
+}
+
+// decodeQACheckParams strictly decodes profile parameters into out.
+func decodeQACheckParams(params json.RawMessage, out any) error {
+	if isEmptyQACheckParams(params) {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("invalid params: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
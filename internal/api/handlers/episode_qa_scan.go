@@ -0,0 +1,288 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+// mcapScanQACheck is a QACheck whose work happens while the episode MCAP is
+// streamed. RunEpisodeQASuite feeds every such check of a run from one pass
+// over the recording instead of letting each check read it again.
+type mcapScanQACheck interface {
+	QACheck
+	// PrepareScan returns the check's share of the pass, or nil when the
+	// check does not scan.
+	PrepareScan(ctx context.Context, h *EpisodeQAHandler, row episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error)
+}
+
+// qaScanFunc prepares one check's share of the pass, bound to a handler.
+type qaScanFunc func(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error)
+
+// mcapScanConsumer is what one check needs from the shared pass.
+type mcapScanConsumer struct {
+	// visitor returns the check's callbacks once the summary has been read
+	// (summary may be nil). Nil when the check needs no data-section records.
+	visitor func(summary *mcap.Summary) mcap.Visitor
+	// wantsSHA256 requests the digest of the whole object.
+	wantsSHA256 bool
+	// finish turns the pass into the check outcome.
+	finish func(pass *mcapScanPass) (episodeQACheckOutcome, error)
+}
+
+// mcapScanPass is the shared result of streaming one episode MCAP. Storage
+// failures abort the pass; malformed content is recorded here.
+type mcapScanPass struct {
+	// reason explains why the object could not be read; the other fields
+	// are then empty.
+	reason     string
+	metadata   map[string]any
+	summary    *mcap.Summary
+	summaryErr error
+	scanErr    error
+	sha256     string
+}
+
+// checkMetadata returns a copy of the object metadata for one check's outcome.
+func (p *mcapScanPass) checkMetadata() map[string]any {
+	out := make(map[string]any, len(p.metadata)+4)
+	for k, v := range p.metadata {
+		out[k] = v
+	}
+	return out
+}
+
+// episodeQASuiteScan is the shared MCAP pass of one suite run and the scan
+// checks it fed, keyed by check name.
+type episodeQASuiteScan struct {
+	pass      *mcapScanPass
+	consumers map[string]*mcapScanConsumer
+}
+
+// runEpisodeQASuiteScan prepares every scan check in the plan and streams the
+// recording once for all of them.
+func (h *EpisodeQAHandler) runEpisodeQASuiteScan(ctx context.Context, row episodeQACheckRow, plan episodeQASuitePlan) (episodeQASuiteScan, error) {
+	scan := episodeQASuiteScan{consumers: map[string]*mcapScanConsumer{}}
+	ordered := make([]*mcapScanConsumer, 0, len(plan.Checks))
+	for _, checkName := range plan.Checks {
+		check, ok := h.checkRegistry().Lookup(checkName)
+		if !ok {
+			continue
+		}
+		scanCheck, ok := check.(mcapScanQACheck)
+		if !ok {
+			continue
+		}
+		consumer, err := scanCheck.PrepareScan(ctx, h, row, plan.Params[checkName])
+		if err != nil {
+			return scan, err
+		}
+		if consumer != nil {
+			scan.consumers[checkName] = consumer
+			ordered = append(ordered, consumer)
+		}
+	}
+	if len(ordered) == 0 {
+		return scan, nil
+	}
+	pass, err := h.runMcapScan(ctx, row, ordered)
+	if err != nil {
+		return scan, err
+	}
+	scan.pass = pass
+	return scan, nil
+}
+
+// runMcapScanCheck runs a single scan check on its own pass.
+func (h *EpisodeQAHandler) runMcapScanCheck(ctx context.Context, row episodeQACheckRow, consumer *mcapScanConsumer) (episodeQACheckOutcome, error) {
+	pass, err := h.runMcapScan(ctx, row, []*mcapScanConsumer{consumer})
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	return consumer.finish(pass)
+}
+
+// runMcapScan stats the episode MCAP once, reads its summary, streams the
+// data section through every consumer's visitor and, when asked, hashes the
+// object from the same stream.
+func (h *EpisodeQAHandler) runMcapScan(ctx context.Context, row episodeQACheckRow, consumers []*mcapScanConsumer) (*mcapScanPass, error) {
+	needsScan, wantsHash := mcapScanNeeds(consumers)
+	if !needsScan && !wantsHash {
+		return &mcapScanPass{metadata: map[string]any{}}, nil
+	}
+	if h.s3 == nil {
+		return nil, fmt.Errorf("storage is not configured")
+	}
+
+	src, size, metadata, reason, err := h.openEpisodeMcapSource(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return &mcapScanPass{metadata: metadata, reason: reason}, nil
+	}
+	pass, err := scanMcapSource(src, size, metadata, consumers)
+	if err != nil {
+		return nil, err
+	}
+	if wantsHash && pass.sha256 == "" {
+		// Nothing streamed the whole data section (no scan check ran, or a
+		// bad header stopped the scan), so hash the object on its own.
+		digest, reason, err := h.hashMcapObject(ctx, metadata)
+		if err != nil {
+			return nil, err
+		}
+		pass.reason = reason
+		pass.sha256 = digest
+	}
+	return pass, nil
+}
+
+func mcapScanNeeds(consumers []*mcapScanConsumer) (needsScan, wantsHash bool) {
+	for _, c := range consumers {
+		needsScan = needsScan || c.visitor != nil
+		wantsHash = wantsHash || c.wantsSHA256
+	}
+	return needsScan, wantsHash
+}
+
+// scanMcapSource runs the shared pass over src. The digest is only set when
+// the data-section stream covered the whole object.
+func scanMcapSource(src mcap.RangeReader, size int64, metadata map[string]any, consumers []*mcapScanConsumer) (*mcapScanPass, error) {
+	pass := &mcapScanPass{metadata: metadata}
+	needsScan, wantsHash := mcapScanNeeds(consumers)
+	if !needsScan {
+		return pass, nil
+	}
+
+	var hashed *hashingScanSource
+	if wantsHash {
+		hashed = &hashingScanSource{src: src, size: size, sum: sha256.New()}
+		src = hashed
+	}
+	reader := mcap.NewReader(src, size)
+
+	summary, err := reader.ReadSummary()
+	if err != nil {
+		if !isMcapFormatError(err) {
+			return nil, fmt.Errorf("read mcap summary: %w", err)
+		}
+		pass.summaryErr = err
+	}
+	pass.summary = summary
+
+	visitors := make([]mcap.Visitor, 0, len(consumers))
+	for _, c := range consumers {
+		if c.visitor != nil {
+			visitors = append(visitors, c.visitor(summary))
+		}
+	}
+	if hashed != nil {
+		hashed.armed = true
+	}
+	if err := reader.Scan(mcap.MergeVisitors(visitors...)); err != nil {
+		if !isMcapFormatError(err) {
+			return nil, fmt.Errorf("scan mcap: %w", err)
+		}
+		pass.scanErr = err
+	}
+	if hashed != nil && hashed.complete() {
+		pass.sha256 = hex.EncodeToString(hashed.sum.Sum(nil))
+	}
+	return pass, nil
+}
+
+// hashMcapObject streams the object named in metadata through SHA-256.
+func (h *EpisodeQAHandler) hashMcapObject(ctx context.Context, metadata map[string]any) (string, string, error) {
+	bucket, _ := metadata["bucket"].(string)
+	objectName, _ := metadata["object"].(string)
+	obj, err := h.s3.GetObject(ctx, bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		if isS3NotFound(err) {
+			return "", "object not found", nil
+		}
+		return "", "", fmt.Errorf("get mcap object: %w", err)
+	}
+	defer func() { _ = obj.Close() }()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, obj); err != nil {
+		if isS3NotFound(err) {
+			return "", "object not found", nil
+		}
+		return "", "", fmt.Errorf("read mcap object: %w", err)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), "", nil
+}
+
+// hashingScanSource passes small ranged reads through to src but serves the
+// data-section scan from one sequential read of the whole object, hashing
+// every byte so checksum_match needs no read of its own. It is armed just
+// before Scan, whose data range is the only one that starts past the header
+// and runs to the end of the object.
+type hashingScanSource struct {
+	src   mcap.RangeReader
+	size  int64
+	sum   hash.Hash
+	armed bool
+	read  int64
+}
+
+func (s *hashingScanSource) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	if !s.armed || offset <= 0 || offset+length != s.size {
+		return s.src.ReadRange(offset, length)
+	}
+	s.armed = false
+	rc, err := s.src.ReadRange(0, s.size)
+	if err != nil {
+		return nil, err
+	}
+	body := &hashingReadCloser{src: s, rc: rc, r: io.TeeReader(rc, s.sum)}
+	// Bytes before the data section are hashed but not handed to the scan.
+	if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+		_ = rc.Close()
+		return nil, err
+	}
+	return body, nil
+}
+
+func (s *hashingScanSource) complete() bool {
+	return s.read == s.size
+}
+
+// hashingReadCloser counts hashed bytes and hashes whatever the scan left
+// unread (summary and footer) when it is closed.
+type hashingReadCloser struct {
+	src *hashingScanSource
+	rc  io.ReadCloser
+	r   io.Reader
+}
+
+func (b *hashingReadCloser) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.src.read += int64(n)
+	return n, err
+}
+
+func (b *hashingReadCloser) Close() error {
+	_, drainErr := io.Copy(io.Discard, b)
+	closeErr := b.rc.Close()
+	if drainErr != nil {
+		// The digest is incomplete; runMcapScan falls back to a plain read.
+		b.src.read = -1
+		return drainErr
+	}
+	return closeErr
+}
@@ -0,0 +1,184 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+// countingRangeSource records the ranged reads served from an in-memory MCAP.
+type countingRangeSource struct {
+	src   mcap.RangeReader
+	reads []int64
+}
+
+func (s *countingRangeSource) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	s.reads = append(s.reads, length)
+	return s.src.ReadRange(offset, length)
+}
+
+func appendQAMcapRecord(out []byte, op byte, body []byte) []byte {
+	out = append(out, op)
+	out = binary.LittleEndian.AppendUint64(out, uint64(len(body)))
+	return append(out, body...)
+}
+
+func appendQAMcapString(b []byte, s string) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// buildQAScanTestMcap writes one uncompressed chunk of 10 Hz messages on
+// /joint_states plus a summary section indexing it.
+func buildQAScanTestMcap(t *testing.T) []byte {
+	t.Helper()
+	var file []byte
+	file = append(file, mcap.Magic...)
+	header := appendQAMcapString(nil, "ros2")
+	header = appendQAMcapString(header, "keystone-test")
+	file = appendQAMcapRecord(file, mcap.OpHeader, header)
+
+	schema := binary.LittleEndian.AppendUint16(nil, 1)
+	schema = appendQAMcapString(schema, "sensor_msgs/msg/JointState")
+	schema = appendQAMcapString(schema, "ros2msg")
+	schema = appendQAMcapString(schema, "")
+	schemaRec := appendQAMcapRecord(nil, mcap.OpSchema, schema)
+
+	channel := binary.LittleEndian.AppendUint16(nil, 1)
+	channel = binary.LittleEndian.AppendUint16(channel, 1)
+	channel = appendQAMcapString(channel, "/joint_states")
+	channel = appendQAMcapString(channel, "cdr")
+	channel = binary.LittleEndian.AppendUint32(channel, 0)
+	channelRec := appendQAMcapRecord(nil, mcap.OpChannel, channel)
+
+	records := append(append([]byte(nil), schemaRec...), channelRec...)
+	const start, step, count = uint64(1_000_000_000), uint64(100_000_000), 20
+	for i := uint64(0); i < count; i++ {
+		msg := binary.LittleEndian.AppendUint16(nil, 1)
+		msg = binary.LittleEndian.AppendUint32(msg, uint32(i))
+		msg = binary.LittleEndian.AppendUint64(msg, start+i*step)
+		msg = binary.LittleEndian.AppendUint64(msg, start+i*step)
+		msg = append(msg, byte(i), 0xAA)
+		records = appendQAMcapRecord(records, mcap.OpMessage, msg)
+	}
+	end := start + (count-1)*step
+
+	chunk := binary.LittleEndian.AppendUint64(nil, start)
+	chunk = binary.LittleEndian.AppendUint64(chunk, end)
+	chunk = binary.LittleEndian.AppendUint64(chunk, uint64(len(records)))
+	chunk = binary.LittleEndian.AppendUint32(chunk, crc32.ChecksumIEEE(records))
+	chunk = appendQAMcapString(chunk, mcap.CompressionNone)
+	chunk = binary.LittleEndian.AppendUint64(chunk, uint64(len(records)))
+	chunk = append(chunk, records...)
+	chunkOffset := len(file)
+	file = appendQAMcapRecord(file, mcap.OpChunk, chunk)
+	chunkLength := len(file) - chunkOffset
+	file = appendQAMcapRecord(file, mcap.OpDataEnd, binary.LittleEndian.AppendUint32(nil, 0))
+
+	idx := binary.LittleEndian.AppendUint64(nil, start)
+	idx = binary.LittleEndian.AppendUint64(idx, end)
+	idx = binary.LittleEndian.AppendUint64(idx, uint64(chunkOffset))
+	idx = binary.LittleEndian.AppendUint64(idx, uint64(chunkLength))
+	idx = binary.LittleEndian.AppendUint32(idx, 0)
+	idx = binary.LittleEndian.AppendUint64(idx, 0)
+	idx = appendQAMcapString(idx, mcap.CompressionNone)
+	idx = binary.LittleEndian.AppendUint64(idx, uint64(len(records)))
+	idx = binary.LittleEndian.AppendUint64(idx, uint64(len(records)))
+
+	summaryStart := uint64(len(file))
+	summary := append(append([]byte(nil), schemaRec...), channelRec...)
+	summary = appendQAMcapRecord(summary, mcap.OpChunkIndex, idx)
+	file = append(file, summary...)
+
+	footer := []byte{mcap.OpFooter}
+	footer = binary.LittleEndian.AppendUint64(footer, 20)
+	footer = binary.LittleEndian.AppendUint64(footer, summaryStart)
+	footer = binary.LittleEndian.AppendUint64(footer, 0)
+	crc := crc32.ChecksumIEEE(append(append([]byte(nil), summary...), footer...))
+	file = append(file, binary.LittleEndian.AppendUint32(footer, crc)...)
+	return append(file, mcap.Magic...)
+}
+
+func TestScanMcapSourceFeedsEveryCheckFromOneStream(t *testing.T) {
+	data := buildQAScanTestMcap(t)
+	src := &countingRangeSource{src: mcap.ReaderAtRange(bytes.NewReader(data))}
+	h := &EpisodeQAHandler{}
+	ctx := context.Background()
+	row := episodeQACheckRow{ID: 1}
+
+	structure, err := h.prepareMcapStructureScan(ctx, row, nil)
+	if err != nil {
+		t.Fatalf("prepare structure: %v", err)
+	}
+	gaps, err := h.prepareMessageGapsScan(ctx, row, nil)
+	if err != nil {
+		t.Fatalf("prepare gaps: %v", err)
+	}
+	checksum := &mcapScanConsumer{
+		wantsSHA256: true,
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			return evaluateChecksumMatch(hex.EncodeToString(sha256Of(data)), checksumSourceEpisode, pass.sha256, false, pass.checkMetadata()), nil
+		},
+	}
+
+	pass, err := scanMcapSource(src, int64(len(data)), map[string]any{}, []*mcapScanConsumer{structure, gaps, checksum})
+	if err != nil {
+		t.Fatalf("scanMcapSource: %v", err)
+	}
+
+	var full int
+	for _, length := range src.reads {
+		if length == int64(len(data)) {
+			full++
+		}
+	}
+	if full != 1 {
+		t.Fatalf("expected exactly one full-object read, got reads %v", src.reads)
+	}
+	for name, consumer := range map[string]*mcapScanConsumer{"structure": structure, "gaps": gaps, "checksum": checksum} {
+		outcome, err := consumer.finish(pass)
+		if err != nil {
+			t.Fatalf("%s finish: %v", name, err)
+		}
+		if !outcome.Passed {
+			t.Fatalf("%s failed: %s (%v)", name, outcome.Details, outcome.Metadata)
+		}
+	}
+}
+
+func TestScanMcapSourceLeavesDigestUnsetWithoutHashRequest(t *testing.T) {
+	data := buildQAScanTestMcap(t)
+	src := &countingRangeSource{src: mcap.ReaderAtRange(bytes.NewReader(data))}
+	structure, err := (&EpisodeQAHandler{}).prepareMcapStructureScan(context.Background(), episodeQACheckRow{}, nil)
+	if err != nil {
+		t.Fatalf("prepare structure: %v", err)
+	}
+	pass, err := scanMcapSource(src, int64(len(data)), map[string]any{}, []*mcapScanConsumer{structure})
+	if err != nil {
+		t.Fatalf("scanMcapSource: %v", err)
+	}
+	if pass.sha256 != "" {
+		t.Fatalf("sha256 = %q, want empty", pass.sha256)
+	}
+	for _, length := range src.reads {
+		if length == int64(len(data)) {
+			t.Fatalf("unexpected full-object read without a hash request: %v", src.reads)
+		}
+	}
+}
+
+func sha256Of(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
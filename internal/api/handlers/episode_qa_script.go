@@ -0,0 +1,813 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// qaScriptCheckPrefix names external script checks: "script:<name>" runs
+	// the newest enabled version, "script:<name>@<version>" pins one.
+	qaScriptCheckPrefix = "script:"
+
+	qaScriptInputFile = "file"
+	qaScriptInputURL  = "url"
+
+	defaultQAScriptTimeoutSec    = 120
+	defaultQAScriptMemoryLimitMB = 2048
+	defaultQAScriptCPULimitSec   = 120
+
+	// qaScriptLookupTimeout bounds the database lookup behind check resolution.
+	qaScriptLookupTimeout = 5 * time.Second
+	// qaScriptURLExpiryMargin keeps presigned URLs valid past the script timeout.
+	qaScriptURLExpiryMargin = 5 * time.Minute
+)
+
+var (
+	qaScriptNamePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	qaScriptVersionPattern = regexp.MustCompile(`^[0-9a-z][0-9a-z._+-]{0,49}$`)
+)
+
+// QAScriptRequest is the request body for registering a QA script version.
+type QAScriptRequest struct {
+	Name        string `json:"name" example:"gripper_force_range"`
+	Version     string `json:"version" example:"1.0.0"`
+	Description string `json:"description,omitempty"`
+	// Executable is relative to KEYSTONE_QA_SCRIPT_DIR.
+	Executable    string   `json:"executable" example:"gripper_force_range/v1/check.py"`
+	Args          []string `json:"args,omitempty"`
+	InputMode     string   `json:"input_mode,omitempty" example:"file"`
+	TimeoutSec    *int     `json:"timeout_sec,omitempty"`
+	MemoryLimitMB *int     `json:"memory_limit_mb,omitempty"`
+	CPULimitSec   *int     `json:"cpu_limit_sec,omitempty"`
+	Enabled       *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateQAScriptRequest updates a QA script version. The executable, its
+// arguments and input mode are fixed per version; register a new version to
+// change them.
+type UpdateQAScriptRequest struct {
+	Description   *string `json:"description,omitempty"`
+	TimeoutSec    *int    `json:"timeout_sec,omitempty"`
+	MemoryLimitMB *int    `json:"memory_limit_mb,omitempty"`
+	CPULimitSec   *int    `json:"cpu_limit_sec,omitempty"`
+	Enabled       *bool   `json:"enabled,omitempty"`
+}
+
+// QAScriptResponse is one registered QA script version.
+type QAScriptResponse struct {
+	ID            int64    `json:"id"`
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	CheckName     string   `json:"check_name"`
+	Description   string   `json:"description,omitempty"`
+	Executable    string   `json:"executable"`
+	Args          []string `json:"args,omitempty"`
+	SHA256        string   `json:"sha256"`
+	InputMode     string   `json:"input_mode"`
+	TimeoutSec    *int64   `json:"timeout_sec,omitempty"`
+	MemoryLimitMB *int64   `json:"memory_limit_mb,omitempty"`
+	CPULimitSec   *int64   `json:"cpu_limit_sec,omitempty"`
+	Enabled       bool     `json:"enabled"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at,omitempty"`
+}
+
+// QAScriptListResponse is the QA script list response.
+type QAScriptListResponse struct {
+	Items   []QAScriptResponse `json:"items"`
+	Total   int                `json:"total"`
+	Limit   int                `json:"limit"`
+	Offset  int                `json:"offset"`
+	HasNext bool               `json:"hasNext,omitempty"`
+	HasPrev bool               `json:"hasPrev,omitempty"`
+}
+
+type qaScriptRow struct {
+	ID            int64          `db:"id"`
+	Name          string         `db:"name"`
+	Version       string         `db:"version"`
+	Description   sql.NullString `db:"description"`
+	Executable    string         `db:"executable"`
+	Args          sql.NullString `db:"args"`
+	SHA256        string         `db:"sha256"`
+	InputMode     string         `db:"input_mode"`
+	TimeoutSec    sql.NullInt64  `db:"timeout_sec"`
+	MemoryLimitMB sql.NullInt64  `db:"memory_limit_mb"`
+	CPULimitSec   sql.NullInt64  `db:"cpu_limit_sec"`
+	Enabled       bool           `db:"enabled"`
+	CreatedAt     sql.NullTime   `db:"created_at"`
+	UpdatedAt     sql.NullTime   `db:"updated_at"`
+}
+
+const qaScriptColumns = `id, name, version, description, executable, args, sha256, input_mode, timeout_sec, memory_limit_mb, cpu_limit_sec, enabled, created_at, updated_at`
+
+// qaScriptInput is the JSON document a script reads on stdin. Paths are
+// local temp copies in file mode; URLs are presigned in url mode.
+type qaScriptInput struct {
+	EpisodeID     int64           `json:"episode_id"`
+	InputMode     string          `json:"input_mode"`
+	Bucket        string          `json:"bucket"`
+	McapObject    string          `json:"mcap_object"`
+	SidecarObject string          `json:"sidecar_object,omitempty"`
+	McapPath      string          `json:"mcap_path,omitempty"`
+	SidecarPath   string          `json:"sidecar_path,omitempty"`
+	McapURL       string          `json:"mcap_url,omitempty"`
+	SidecarURL    string          `json:"sidecar_url,omitempty"`
+	Params        json.RawMessage `json:"params,omitempty"`
+}
+
+// qaScriptCheck runs one registered script version as a QA check.
+type qaScriptCheck struct {
+	name   string
+	script qaScriptRow
+}
+
+func (c qaScriptCheck) Name() string   { return c.name }
+func (c qaScriptCheck) Blocking() bool { return false }
+
+// ValidateParams accepts any JSON object; the script interprets it.
+func (c qaScriptCheck) ValidateParams(params json.RawMessage) error {
+	if isEmptyQACheckParams(params) {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(params, &obj); err != nil {
+		return fmt.Errorf("invalid params: script params must be a JSON object")
+	}
+	return nil
+}
+
+func (c qaScriptCheck) Run(ctx context.Context, h *EpisodeQAHandler, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	return h.runQAScriptCheck(ctx, c.name, c.script, row, params)
+}
+
+// parseQAScriptCheckName splits "script:<name>[@<version>]".
+func parseQAScriptCheckName(checkName string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(normalizeEpisodeQACheckName(checkName), qaScriptCheckPrefix)
+	if !ok {
+		return "", "", false
+	}
+	name, version, pinned := strings.Cut(rest, "@")
+	if !qaScriptNamePattern.MatchString(name) || (pinned && !qaScriptVersionPattern.MatchString(version)) {
+		return "", "", false
+	}
+	return name, version, true
+}
+
+// resolveQAScriptCheck is the registry resolver for script checks.
+func (h *EpisodeQAHandler) resolveQAScriptCheck(checkName string) (QACheck, bool) {
+	name, version, ok := parseQAScriptCheckName(checkName)
+	if !ok || h.db == nil {
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), qaScriptLookupTimeout)
+	defer cancel()
+
+	query := "SELECT " + qaScriptColumns + " FROM qa_scripts WHERE name = ? AND enabled = TRUE AND deleted_at IS NULL"
+	args := []any{name}
+	if version != "" {
+		query += " AND version = ?"
+		args = append(args, version)
+	}
+	var script qaScriptRow
+	if err := h.db.GetContext(ctx, &script, query+" ORDER BY id DESC LIMIT 1", args...); err != nil {
+		if err != sql.ErrNoRows {
+			logger.Printf("[EPISODE-QA] Failed to resolve qa script %q: %v", checkName, err)
+		}
+		return nil, false
+	}
+	return qaScriptCheck{name: normalizeEpisodeQACheckName(checkName), script: script}, true
+}
+
+func (h *EpisodeQAHandler) qaScriptDir() string {
+	if h.qaCfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(h.qaCfg.ScriptDir)
+}
+
+func (h *EpisodeQAHandler) qaScriptLimits(script qaScriptRow) qaScriptLimits {
+	limits := qaScriptLimits{
+		Timeout:  defaultQAScriptTimeoutSec * time.Second,
+		MemoryMB: defaultQAScriptMemoryLimitMB,
+		CPUSec:   defaultQAScriptCPULimitSec,
+	}
+	if h.qaCfg != nil {
+		if h.qaCfg.ScriptTimeoutSec > 0 {
+			limits.Timeout = time.Duration(h.qaCfg.ScriptTimeoutSec) * time.Second
+		}
+		if h.qaCfg.ScriptMemoryLimitMB > 0 {
+			limits.MemoryMB = h.qaCfg.ScriptMemoryLimitMB
+		}
+		if h.qaCfg.ScriptCPULimitSec > 0 {
+			limits.CPUSec = h.qaCfg.ScriptCPULimitSec
+		}
+	}
+	if script.TimeoutSec.Valid && script.TimeoutSec.Int64 > 0 {
+		limits.Timeout = time.Duration(script.TimeoutSec.Int64) * time.Second
+	}
+	if script.MemoryLimitMB.Valid && script.MemoryLimitMB.Int64 > 0 {
+		limits.MemoryMB = int(script.MemoryLimitMB.Int64)
+	}
+	if script.CPULimitSec.Valid && script.CPULimitSec.Int64 > 0 {
+		limits.CPUSec = int(script.CPULimitSec.Int64)
+	}
+	return limits
+}
+
+func (h *EpisodeQAHandler) runQAScriptCheck(ctx context.Context, checkName string, script qaScriptRow, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	if h.s3 == nil {
+		return episodeQACheckOutcome{}, fmt.Errorf("storage is not configured")
+	}
+	limits := h.qaScriptLimits(script)
+	metadata := map[string]any{
+		"script": map[string]any{
+			"id":         script.ID,
+			"name":       script.Name,
+			"version":    script.Version,
+			"sha256":     script.SHA256,
+			"input_mode": script.InputMode,
+		},
+		"timeout_sec":     limits.Timeout.Seconds(),
+		"memory_limit_mb": limits.MemoryMB,
+		"cpu_limit_sec":   limits.CPUSec,
+	}
+	fail := func(details string) episodeQACheckOutcome {
+		return episodeQACheckOutcome{
+			CheckName:     checkName,
+			Details:       details,
+			Metadata:      metadata,
+			ScriptID:      script.ID,
+			ScriptVersion: script.Version,
+		}
+	}
+
+	execPath, err := resolveQAScriptExecutable(h.qaScriptDir(), script.Executable)
+	if err != nil {
+		return fail("Script executable unavailable: " + err.Error()), nil
+	}
+	digest, err := fileSHA256(execPath)
+	if err != nil {
+		return fail("Script executable unavailable: " + err.Error()), nil
+	}
+	if digest != script.SHA256 {
+		return fail(fmt.Sprintf("Script %s@%s executable changed since registration", script.Name, script.Version)), nil
+	}
+
+	workDir, err := os.MkdirTemp("", "keystone-qa-script-*")
+	if err != nil {
+		return episodeQACheckOutcome{}, fmt.Errorf("create qa script work dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	input, reason, err := h.prepareQAScriptInput(ctx, script, row, workDir, limits.Timeout+qaScriptURLExpiryMargin)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	if reason != "" {
+		return fail("Script check failed: " + reason), nil
+	}
+	if !isEmptyQACheckParams(params) {
+		input.Params = params
+	}
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return episodeQACheckOutcome{}, fmt.Errorf("marshal qa script input: %w", err)
+	}
+
+	res, err := runQAScriptProcess(ctx, qaScriptProcessSpec{
+		Path:   execPath,
+		Args:   parseQAScriptArgs(script.Args),
+		Dir:    workDir,
+		Limits: limits,
+	}, payload)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	return evaluateQAScriptRun(checkName, script, res, limits.Timeout, metadata), nil
+}
+
+// prepareQAScriptInput stages the episode for the script. A non-empty reason
+// means the episode itself is unusable; errors are storage failures.
+func (h *EpisodeQAHandler) prepareQAScriptInput(ctx context.Context, script qaScriptRow, row episodeQACheckRow, workDir string, urlExpiry time.Duration) (qaScriptInput, string, error) {
+	input := qaScriptInput{EpisodeID: row.ID, InputMode: script.InputMode}
+	bucket, mcapObject, ok := resolveEpisodeMcapLocation(h.bucket, row.McapPath)
+	if !ok {
+		return input, "invalid mcap_path", nil
+	}
+	input.Bucket, input.McapObject = bucket, mcapObject
+	sidecarBucket, sidecarObject, hasSidecar := resolveEpisodeMcapLocation(h.bucket, row.SidecarPath)
+	if hasSidecar {
+		input.SidecarObject = sidecarObject
+	}
+
+	if script.InputMode == qaScriptInputURL {
+		u, err := h.s3.PresignedGetObject(ctx, bucket, mcapObject, urlExpiry, nil)
+		if err != nil {
+			return input, "", fmt.Errorf("presign mcap object: %w", err)
+		}
+		input.McapURL = u.String()
+		if hasSidecar {
+			u, err := h.s3.PresignedGetObject(ctx, sidecarBucket, sidecarObject, urlExpiry, nil)
+			if err != nil {
+				return input, "", fmt.Errorf("presign sidecar object: %w", err)
+			}
+			input.SidecarURL = u.String()
+		}
+		return input, "", nil
+	}
+
+	input.McapPath = filepath.Join(workDir, "episode.mcap")
+	if err := h.s3.FGetObject(ctx, bucket, mcapObject, input.McapPath, minio.GetObjectOptions{}); err != nil {
+		if isS3NotFound(err) {
+			return input, "mcap object not found", nil
+		}
+		return input, "", fmt.Errorf("download mcap object: %w", err)
+	}
+	if hasSidecar {
+		sidecarPath := filepath.Join(workDir, "sidecar.json")
+		err := h.s3.FGetObject(ctx, sidecarBucket, sidecarObject, sidecarPath, minio.GetObjectOptions{})
+		switch {
+		case err == nil:
+			input.SidecarPath = sidecarPath
+		case !isS3NotFound(err):
+			return input, "", fmt.Errorf("download sidecar object: %w", err)
+		}
+	}
+	return input, "", nil
+}
+
+// resolveQAScriptExecutable resolves rel inside dir, following symlinks, and
+// rejects anything that escapes dir or is not an executable regular file.
+func resolveQAScriptExecutable(dir, rel string) (string, error) {
+	if dir == "" {
+		return "", errors.New("KEYSTONE_QA_SCRIPT_DIR is not configured")
+	}
+	rel = strings.TrimSpace(rel)
+	if rel == "" || filepath.IsAbs(rel) || !filepath.IsLocal(rel) {
+		return "", errors.New("executable must be a relative path inside the script directory")
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("script directory: %w", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(root, rel))
+	if err != nil {
+		return "", fmt.Errorf("executable not found: %s", rel)
+	}
+	if inside, err := filepath.Rel(root, path); err != nil || !filepath.IsLocal(inside) {
+		return "", errors.New("executable resolves outside the script directory")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("executable not found: %s", rel)
+	}
+	if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+		return "", fmt.Errorf("%s is not an executable file", rel)
+	}
+	return path, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	// #nosec G304 -- path was resolved inside the configured script directory.
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+func parseQAScriptArgs(raw sql.NullString) []string {
+	if !raw.Valid || raw.String == "" {
+		return nil
+	}
+	var args []string
+	if err := json.Unmarshal([]byte(raw.String), &args); err != nil {
+		return nil
+	}
+	return args
+}
+
+// RegisterScriptRoutes registers QA script management routes. The group is
+// expected to enforce admin authentication.
+func (h *EpisodeQAHandler) RegisterScriptRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/qa/scripts", h.ListQAScripts)
+	apiV1.POST("/qa/scripts", h.CreateQAScript)
+	apiV1.GET("/qa/scripts/:id", h.GetQAScript)
+	apiV1.PUT("/qa/scripts/:id", h.UpdateQAScript)
+	apiV1.DELETE("/qa/scripts/:id", h.DeleteQAScript)
+}
+
+// ListQAScripts lists registered QA script versions.
+//
+// @Summary      List QA scripts
+// @Description  Lists registered external QA script versions, newest first
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        name   query string false "Filter by script name"
+// @Param        limit  query int    false "Max results (default 50, max 100)"
+// @Param        offset query int    false "Pagination offset (default 0)"
+// @Success      200 {object} QAScriptListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/scripts [get]
+func (h *EpisodeQAHandler) ListQAScripts(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	whereClause := "WHERE deleted_at IS NULL"
+	args := []any{}
+	if name := strings.TrimSpace(c.Query("name")); name != "" {
+		whereClause += " AND name = ?"
+		args = append(args, name)
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(*) FROM qa_scripts "+whereClause, args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to count qa scripts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa scripts"})
+		return
+	}
+	var rows []qaScriptRow
+	query := "SELECT " + qaScriptColumns + " FROM qa_scripts " + whereClause + " ORDER BY name ASC, id DESC LIMIT ? OFFSET ?"
+	if err := h.db.Select(&rows, query, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa scripts: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list qa scripts"})
+		return
+	}
+
+	items := make([]QAScriptResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, qaScriptResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, QAScriptListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// GetQAScript gets one QA script version.
+//
+// @Summary      Get QA script
+// @Description  Gets a registered QA script version by ID
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "QA script ID"
+// @Success      200  {object}  QAScriptResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /qa/scripts/{id} [get]
+func (h *EpisodeQAHandler) GetQAScript(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAScriptIDParam(c)
+	if !ok {
+		return
+	}
+	row, err := h.loadQAScript(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa script not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to query qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get qa script"})
+		return
+	}
+	c.JSON(http.StatusOK, qaScriptResponseFromRow(row))
+}
+
+// CreateQAScript registers a QA script version.
+//
+// @Summary      Register QA script
+// @Description  Registers an executable under KEYSTONE_QA_SCRIPT_DIR as a QA script version and pins its SHA-256
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        body  body      QAScriptRequest  true  "QA script payload"
+// @Success      201   {object}  QAScriptResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /qa/scripts [post]
+func (h *EpisodeQAHandler) CreateQAScript(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	var req QAScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Version = strings.TrimSpace(req.Version)
+	req.Description = strings.TrimSpace(req.Description)
+	req.InputMode = strings.TrimSpace(req.InputMode)
+	if req.InputMode == "" {
+		req.InputMode = qaScriptInputFile
+	}
+	switch {
+	case !qaScriptNamePattern.MatchString(req.Name):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1-64 lowercase letters, digits, '_' or '-'"})
+		return
+	case !qaScriptVersionPattern.MatchString(req.Version):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be 1-50 lowercase letters, digits, '.', '_', '+' or '-'"})
+		return
+	case req.InputMode != qaScriptInputFile && req.InputMode != qaScriptInputURL:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "input_mode must be file or url"})
+		return
+	}
+	if msg := validateQAScriptLimits(req.TimeoutSec, req.MemoryLimitMB, req.CPULimitSec); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	execPath, err := resolveQAScriptExecutable(h.qaScriptDir(), req.Executable)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	digest, err := fileSHA256(execPath)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to hash qa script %s: %v", execPath, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read qa script executable"})
+		return
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM qa_scripts WHERE name = ? AND version = ? AND deleted_at IS NULL)", req.Name, req.Version); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to check qa script version: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa script"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "qa script version already exists"})
+		return
+	}
+
+	var argsJSON sql.NullString
+	if len(req.Args) > 0 {
+		data, err := json.Marshal(req.Args)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid args"})
+			return
+		}
+		argsJSON = sql.NullString{String: string(data), Valid: true}
+	}
+	enabled := req.Enabled == nil || *req.Enabled
+	now := time.Now().UTC()
+	// #nosec G701 -- static SQL with placeholder-bound QA script values.
+	result, err := h.db.Exec(`
+		INSERT INTO qa_scripts (name, version, description, executable, args, sha256, input_mode, timeout_sec, memory_limit_mb, cpu_limit_sec, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, req.Name, req.Version, sql.NullString{String: req.Description, Valid: req.Description != ""}, strings.TrimSpace(req.Executable), argsJSON, digest, req.InputMode,
+		sqlNullInt64FromIntPtr(req.TimeoutSec), sqlNullInt64FromIntPtr(req.MemoryLimitMB), sqlNullInt64FromIntPtr(req.CPULimitSec), enabled, now, now)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to insert qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa script"})
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch inserted qa script id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create qa script"})
+		return
+	}
+
+	row, err := h.loadQAScript(id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch created qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get created qa script"})
+		return
+	}
+	c.JSON(http.StatusCreated, qaScriptResponseFromRow(row))
+}
+
+// UpdateQAScript updates a QA script version.
+//
+// @Summary      Update QA script
+// @Description  Updates a QA script version's description, limits or enabled flag
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                    true  "QA script ID"
+// @Param        body  body      UpdateQAScriptRequest  true  "QA script payload"
+// @Success      200   {object}  QAScriptResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /qa/scripts/{id} [put]
+func (h *EpisodeQAHandler) UpdateQAScript(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAScriptIDParam(c)
+	if !ok {
+		return
+	}
+	var req UpdateQAScriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if msg := validateQAScriptLimits(req.TimeoutSec, req.MemoryLimitMB, req.CPULimitSec); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	updates := []string{}
+	args := []any{}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		updates = append(updates, "description = ?")
+		args = append(args, sql.NullString{String: description, Valid: description != ""})
+	}
+	for _, field := range []struct {
+		column string
+		value  *int
+	}{
+		{"timeout_sec", req.TimeoutSec},
+		{"memory_limit_mb", req.MemoryLimitMB},
+		{"cpu_limit_sec", req.CPULimitSec},
+	} {
+		if field.value == nil {
+			continue
+		}
+		// 0 clears the override so the configured default applies.
+		updates = append(updates, field.column+" = ?")
+		args = append(args, sql.NullInt64{Int64: int64(*field.value), Valid: *field.value > 0})
+	}
+	if req.Enabled != nil {
+		updates = append(updates, "enabled = ?")
+		args = append(args, *req.Enabled)
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
+		return
+	}
+	updates = append(updates, "updated_at = ?")
+	args = append(args, time.Now().UTC(), id)
+
+	// #nosec G701 -- column names come from the fixed list above.
+	if _, err := h.db.Exec("UPDATE qa_scripts SET "+strings.Join(updates, ", ")+" WHERE id = ? AND deleted_at IS NULL", args...); err != nil {
+		logger.Printf("[EPISODE-QA] Failed to update qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update qa script"})
+		return
+	}
+	row, err := h.loadQAScript(id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa script not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to fetch updated qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get updated qa script"})
+		return
+	}
+	c.JSON(http.StatusOK, qaScriptResponseFromRow(row))
+}
+
+// DeleteQAScript soft deletes a QA script version.
+//
+// @Summary      Delete QA script
+// @Description  Soft deletes a QA script version; unpinned profile checks move to the newest remaining version
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "QA script ID"
+// @Success      204
+// @Failure      400 {object} map[string]string
+// @Failure      404 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /qa/scripts/{id} [delete]
+func (h *EpisodeQAHandler) DeleteQAScript(c *gin.Context) {
+	if h.db == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "database is not configured"})
+		return
+	}
+	id, ok := parseQAScriptIDParam(c)
+	if !ok {
+		return
+	}
+	// #nosec G701 -- static SQL with placeholder-bound QA script values.
+	result, err := h.db.Exec("UPDATE qa_scripts SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL", time.Now().UTC(), id)
+	if err != nil {
+		logger.Printf("[EPISODE-QA] Failed to delete qa script: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete qa script"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "qa script not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func parseQAScriptIDParam(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid qa script id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *EpisodeQAHandler) loadQAScript(id int64) (qaScriptRow, error) {
+	var row qaScriptRow
+	err := h.db.Get(&row, "SELECT "+qaScriptColumns+" FROM qa_scripts WHERE id = ? AND deleted_at IS NULL", id)
+	return row, err
+}
+
+func validateQAScriptLimits(timeoutSec, memoryMB, cpuSec *int) string {
+	for _, limit := range []struct {
+		field string
+		value *int
+	}{
+		{"timeout_sec", timeoutSec},
+		{"memory_limit_mb", memoryMB},
+		{"cpu_limit_sec", cpuSec},
+	} {
+		if limit.value != nil && *limit.value < 0 {
+			return limit.field + " must not be negative"
+		}
+	}
+	return ""
+}
+
+func sqlNullInt64FromIntPtr(v *int) sql.NullInt64 {
+	if v == nil || *v <= 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*v), Valid: true}
+}
+
+func qaScriptResponseFromRow(row qaScriptRow) QAScriptResponse {
+	resp := QAScriptResponse{
+		ID:            row.ID,
+		Name:          row.Name,
+		Version:       row.Version,
+		CheckName:     qaScriptCheckPrefix + row.Name + "@" + row.Version,
+		Description:   nullStringValue(row.Description),
+		Executable:    row.Executable,
+		Args:          parseQAScriptArgs(row.Args),
+		SHA256:        row.SHA256,
+		InputMode:     row.InputMode,
+		TimeoutSec:    nullableInt64(row.TimeoutSec),
+		MemoryLimitMB: nullableInt64(row.MemoryLimitMB),
+		CPULimitSec:   nullableInt64(row.CPULimitSec),
+		Enabled:       row.Enabled,
+	}
+	if row.CreatedAt.Valid {
+		resp.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if row.UpdatedAt.Valid {
+		resp.UpdatedAt = row.UpdatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
@@ -0,0 +1,18 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+//go:build !unix
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// newQAScriptCommand refuses to run scripts where their limits cannot be enforced.
+func newQAScriptCommand(_ context.Context, _ string, _ []string, _ qaScriptLimits) (*exec.Cmd, error) {
+	return nil, errors.New("external qa scripts require a unix host")
+}
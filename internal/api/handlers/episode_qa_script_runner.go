@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	maxQAScriptStdoutBytes = 1 << 20
+	maxQAScriptStderrBytes = 64 << 10
+	// maxQAScriptStderrTail is how much stderr is kept on failed outcomes.
+	maxQAScriptStderrTail = 4 << 10
+	// qaScriptWaitDelay bounds how long output pipes held open by a killed
+	// script's children can delay Wait.
+	qaScriptWaitDelay = 5 * time.Second
+)
+
+// qaScriptLimits bound one script run.
+type qaScriptLimits struct {
+	Timeout  time.Duration
+	MemoryMB int
+	CPUSec   int
+}
+
+// qaScriptProcessSpec describes one script invocation.
+type qaScriptProcessSpec struct {
+	Path   string
+	Args   []string
+	Dir    string
+	Limits qaScriptLimits
+}
+
+// qaScriptProcessResult is what a finished script left behind.
+type qaScriptProcessResult struct {
+	Stdout          []byte
+	Stderr          []byte
+	StdoutTruncated bool
+	ExitCode        int
+	State           string
+	TimedOut        bool
+	StartErr        string
+	Duration        time.Duration
+}
+
+// qaScriptResult is the JSON a script prints on stdout.
+type qaScriptResult struct {
+	Passed   *bool          `json:"passed"`
+	Score    *float64       `json:"score"`
+	Details  string         `json:"details"`
+	Metadata map[string]any `json:"metadata"`
+}
+
+// cappedBuffer keeps the first limit bytes written and discards the rest
+// without failing the writer, so a chatty script is not killed by EPIPE.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+			b.truncated = true
+		} else {
+			b.buf.Write(p)
+		}
+	} else if len(p) > 0 {
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+// runQAScriptProcess runs the script with input on stdin under spec.Limits.
+// It only returns an error when ctx itself ends; script failures, timeouts
+// and start errors are reported in the result.
+func runQAScriptProcess(ctx context.Context, spec qaScriptProcessSpec, input []byte) (qaScriptProcessResult, error) {
+	runCtx, cancel := context.WithTimeout(ctx, spec.Limits.Timeout)
+	defer cancel()
+
+	cmd, err := newQAScriptCommand(runCtx, spec.Path, spec.Args, spec.Limits)
+	if err != nil {
+		return qaScriptProcessResult{StartErr: err.Error()}, nil
+	}
+	cmd.Dir = spec.Dir
+	cmd.Env = []string{
+		"PATH=/usr/local/bin:/usr/bin:/bin",
+		"HOME=" + spec.Dir,
+		"TMPDIR=" + spec.Dir,
+		"LANG=C.UTF-8",
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	stdout := &cappedBuffer{limit: maxQAScriptStdoutBytes}
+	stderr := &cappedBuffer{limit: maxQAScriptStderrBytes}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = qaScriptWaitDelay
+
+	started := time.Now()
+	err = cmd.Run()
+	result := qaScriptProcessResult{
+		Stdout:          stdout.buf.Bytes(),
+		Stderr:          stderr.buf.Bytes(),
+		StdoutTruncated: stdout.truncated,
+		Duration:        time.Since(started),
+	}
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+		result.State = cmd.ProcessState.String()
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		result.TimedOut = true
+		return result, nil
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		result.StartErr = err.Error()
+	}
+	return result, nil
+}
+
+// parseQAScriptResult decodes the script's stdout. Scripts that log to
+// stdout may print the result object as their last line.
+func parseQAScriptResult(stdout []byte) (qaScriptResult, error) {
+	var result qaScriptResult
+	trimmed := bytes.TrimSpace(stdout)
+	if len(trimmed) == 0 {
+		return result, errors.New("empty output")
+	}
+	if err := json.Unmarshal(trimmed, &result); err != nil {
+		last := trimmed
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			last = bytes.TrimSpace(trimmed[i+1:])
+		}
+		result = qaScriptResult{}
+		if lastErr := json.Unmarshal(last, &result); lastErr != nil {
+			return result, fmt.Errorf("output is not a JSON object: %w", err)
+		}
+	}
+	if result.Passed == nil {
+		return result, errors.New(`result is missing "passed"`)
+	}
+	return result, nil
+}
+
+// evaluateQAScriptRun maps a finished script run to a check outcome.
+func evaluateQAScriptRun(checkName string, script qaScriptRow, res qaScriptProcessResult, timeout time.Duration, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["duration_ms"] = res.Duration.Milliseconds()
+	metadata["exit_code"] = res.ExitCode
+	outcome := episodeQACheckOutcome{
+		CheckName:     checkName,
+		Metadata:      metadata,
+		ScriptID:      script.ID,
+		ScriptVersion: script.Version,
+	}
+	label := script.Name + "@" + script.Version
+
+	fail := func(details string) episodeQACheckOutcome {
+		if tail := qaScriptStderrTail(res.Stderr); tail != "" {
+			metadata["stderr"] = tail
+		}
+		outcome.Details = details
+		return outcome
+	}
+	switch {
+	case res.StartErr != "":
+		return fail(fmt.Sprintf("Script %s failed to start: %s", label, res.StartErr))
+	case res.TimedOut:
+		return fail(fmt.Sprintf("Script %s timed out after %s", label, timeout))
+	case res.ExitCode != 0:
+		return fail(fmt.Sprintf("Script %s exited abnormally: %s", label, res.State))
+	}
+
+	if res.StdoutTruncated {
+		return fail(fmt.Sprintf("Script %s output exceeds %d bytes", label, maxQAScriptStdoutBytes))
+	}
+	result, err := parseQAScriptResult(res.Stdout)
+	if err != nil {
+		return fail(fmt.Sprintf("Script %s returned an invalid result: %v", label, err))
+	}
+
+	outcome.Passed = *result.Passed
+	switch {
+	case result.Score != nil:
+		outcome.Score = roundQAFloat(math.Min(math.Max(*result.Score, 0), 1))
+	case outcome.Passed:
+		outcome.Score = 1
+	}
+	if result.Metadata != nil {
+		metadata["result"] = result.Metadata
+	}
+	outcome.Details = strings.TrimSpace(result.Details)
+	if outcome.Details == "" {
+		status := "failed"
+		if outcome.Passed {
+			status = "passed"
+		}
+		outcome.Details = fmt.Sprintf("Script %s %s", label, status)
+	}
+	return outcome
+}
+
+func qaScriptStderrTail(stderr []byte) string {
+	tail := bytes.TrimSpace(stderr)
+	if len(tail) > maxQAScriptStderrTail {
+		tail = tail[len(tail)-maxQAScriptStderrTail:]
+	}
+	return string(tail)
+}
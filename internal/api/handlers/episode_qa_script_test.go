@@ -0,0 +1,245 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+//go:build unix
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func writeQAScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return path
+}
+
+func TestRunQAScriptProcess(t *testing.T) {
+	dir := t.TempDir()
+	script := qaScriptRow{ID: 4, Name: "force", Version: "1.0.0"}
+	limits := qaScriptLimits{Timeout: 5 * time.Second, MemoryMB: 256, CPUSec: 5}
+
+	tests := []struct {
+		name       string
+		body       string
+		timeout    time.Duration
+		wantPassed bool
+		wantScore  float64
+		wantDetail string
+	}{
+		{
+			name:       "passes with score and reads stdin",
+			body:       `read input; echo "log line"; echo '{"passed":true,"score":0.9,"details":"ok"}'; echo "$input" >&2`,
+			wantPassed: true,
+			wantScore:  0.9,
+			wantDetail: "ok",
+		},
+		{
+			name:       "failed result keeps script details",
+			body:       `echo '{"passed":false,"score":1.7,"details":"force out of range"}'`,
+			wantScore:  1,
+			wantDetail: "force out of range",
+		},
+		{
+			name:       "non-zero exit",
+			body:       `echo boom >&2; exit 3`,
+			wantDetail: "exited abnormally",
+		},
+		{
+			name:       "invalid output",
+			body:       `echo not json`,
+			wantDetail: "invalid result",
+		},
+		{
+			name:       "missing passed",
+			body:       `echo '{"score":1}'`,
+			wantDetail: `missing "passed"`,
+		},
+		{
+			name:       "timeout",
+			body:       `sleep 10`,
+			timeout:    200 * time.Millisecond,
+			wantDetail: "timed out",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeQAScript(t, dir, "s"+string(rune('a'+i))+".sh", tt.body)
+			runLimits := limits
+			if tt.timeout > 0 {
+				runLimits.Timeout = tt.timeout
+			}
+			res, err := runQAScriptProcess(context.Background(), qaScriptProcessSpec{Path: path, Dir: dir, Limits: runLimits}, []byte(`{"episode_id":1}`+"\n"))
+			if err != nil {
+				t.Fatalf("runQAScriptProcess: %v", err)
+			}
+			outcome := evaluateQAScriptRun("script:force", script, res, runLimits.Timeout, nil)
+			if outcome.Passed != tt.wantPassed || outcome.Score != tt.wantScore {
+				t.Fatalf("outcome = %+v", outcome)
+			}
+			if !strings.Contains(outcome.Details, tt.wantDetail) {
+				t.Fatalf("details = %q, want %q", outcome.Details, tt.wantDetail)
+			}
+			if outcome.ScriptID != 4 || outcome.ScriptVersion != "1.0.0" || outcome.CheckName != "script:force" {
+				t.Fatalf("script fields = %+v", outcome)
+			}
+		})
+	}
+}
+
+func TestParseQAScriptCheckName(t *testing.T) {
+	tests := []struct {
+		in          string
+		wantName    string
+		wantVersion string
+		wantOK      bool
+	}{
+		{"script:force", "force", "", true},
+		{" Script:Force@1.2.0 ", "force", "1.2.0", true},
+		{"script:", "", "", false},
+		{"script:force@", "", "", false},
+		{"script:../x", "", "", false},
+		{"topics", "", "", false},
+	}
+	for _, tt := range tests {
+		name, version, ok := parseQAScriptCheckName(tt.in)
+		if name != tt.wantName || version != tt.wantVersion || ok != tt.wantOK {
+			t.Fatalf("parseQAScriptCheckName(%q) = %q, %q, %v", tt.in, name, version, ok)
+		}
+	}
+}
+
+func TestResolveQAScriptExecutable(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "scripts")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeQAScript(t, dir, "ok.sh", "exit 0")
+	outside := writeQAScript(t, root, "outside.sh", "exit 0")
+	if err := os.Symlink(outside, filepath.Join(dir, "link.sh")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plain.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := resolveQAScriptExecutable(dir, "ok.sh"); err != nil {
+		t.Fatalf("ok.sh: %v", err)
+	}
+	for _, rel := range []string{"", "../outside.sh", outside, "link.sh", "plain.txt", "missing.sh"} {
+		if _, err := resolveQAScriptExecutable(dir, rel); err == nil {
+			t.Fatalf("resolveQAScriptExecutable(%q) accepted", rel)
+		}
+	}
+	if _, err := resolveQAScriptExecutable("", "ok.sh"); err == nil {
+		t.Fatal("empty script dir accepted")
+	}
+}
+
+func TestQAScriptCRUDAndResolve(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupQAProfileTestDB(t)
+	execQAProfileTestSQL(t, db, `
+		CREATE TABLE qa_scripts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			version TEXT NOT NULL,
+			description TEXT,
+			executable TEXT NOT NULL,
+			args TEXT,
+			sha256 TEXT NOT NULL,
+			input_mode TEXT NOT NULL DEFAULT 'file',
+			timeout_sec INTEGER NULL,
+			memory_limit_mb INTEGER NULL,
+			cpu_limit_sec INTEGER NULL,
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		);
+	`)
+	dir := t.TempDir()
+	writeQAScript(t, dir, "force.sh", `echo '{"passed":true}'`)
+
+	h := NewEpisodeQAHandler(db, nil, "", &config.QAConfig{ScriptDir: dir})
+	router := gin.New()
+	h.RegisterScriptRoutes(router.Group("/api/v1"))
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/qa/scripts", map[string]any{"name": "force", "version": "1.0.0", "executable": "../force.sh"}); rec.Code != http.StatusBadRequest {
+		t.Fatalf("traversal status = %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, version := range []string{"1.0.0", "1.1.0"} {
+		rec := do(http.MethodPost, "/api/v1/qa/scripts", map[string]any{"name": "force", "version": version, "executable": "force.sh", "args": []string{"--strict"}})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create %s status = %d: %s", version, rec.Code, rec.Body.String())
+		}
+		var created QAScriptResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(created.SHA256) != 64 || created.CheckName != "script:force@"+version || created.InputMode != qaScriptInputFile {
+			t.Fatalf("created = %+v", created)
+		}
+	}
+	if rec := do(http.MethodPost, "/api/v1/qa/scripts", map[string]any{"name": "force", "version": "1.0.0", "executable": "force.sh"}); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate status = %d", rec.Code)
+	}
+
+	check, ok := h.checkRegistry().Lookup("script:force")
+	if !ok || check.(qaScriptCheck).script.Version != "1.1.0" || check.Blocking() {
+		t.Fatalf("latest lookup = %+v, %v", check, ok)
+	}
+	if check, ok := h.checkRegistry().Lookup("script:force@1.0.0"); !ok || check.(qaScriptCheck).script.Version != "1.0.0" {
+		t.Fatalf("pinned lookup = %+v, %v", check, ok)
+	}
+
+	if rec := do(http.MethodPut, "/api/v1/qa/scripts/2", map[string]any{"enabled": false, "timeout_sec": 30}); rec.Code != http.StatusOK {
+		t.Fatalf("update status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if check, ok := h.checkRegistry().Lookup("script:force"); !ok || check.(qaScriptCheck).script.Version != "1.0.0" {
+		t.Fatalf("lookup after disable = %+v, %v", check, ok)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/qa/scripts/1", nil); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete status = %d", rec.Code)
+	}
+	if _, ok := h.checkRegistry().Lookup("script:force"); ok {
+		t.Fatal("deleted script still resolves")
+	}
+	if rec := do(http.MethodGet, "/api/v1/qa/scripts/1", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/qa/scripts?name=force", nil); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"total":1`) {
+		t.Fatalf("list = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+//go:build unix
+
+package handlers
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// qaScriptLimitShell applies the memory and CPU limits with ulimit and then
+// execs the script, which inherits them.
+const qaScriptLimitShell = `ulimit -v "$1" && ulimit -t "$2" && shift 2 && exec "$@"`
+
+// newQAScriptCommand starts the script in its own process group so a
+// timeout kills every process it spawned.
+func newQAScriptCommand(ctx context.Context, path string, args []string, limits qaScriptLimits) (*exec.Cmd, error) {
+	shArgs := []string{"-c", qaScriptLimitShell, "keystone-qa-script", ulimitValue(limits.MemoryMB * 1024), ulimitValue(limits.CPUSec), path}
+	cmd := exec.CommandContext(ctx, "/bin/sh", append(shArgs, args...)...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd, nil
+}
+
+func ulimitValue(v int) string {
+	if v <= 0 {
+		return "unlimited"
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,375 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+const (
+	episodeQACheckTimestamps = "timestamp_consistency"
+
+	defaultEpisodeQAMaxBackwardJumps  = 0
+	defaultEpisodeQAMaxPublishSkewSec = 1.0
+	defaultEpisodeQAMaxStartOffsetSec = 300.0
+
+	// timestampStartOffsetScore caps the score when only the recording start
+	// disagrees with the task start.
+	timestampStartOffsetScore = 0.5
+)
+
+// timestampThresholds are the pass criteria for the timestamp check.
+type timestampThresholds struct {
+	// MaxBackwardJumps is the number of log_time regressions tolerated per channel.
+	MaxBackwardJumps  int64
+	MaxPublishSkewSec float64
+	MaxStartOffsetSec float64
+}
+
+// timestampParams are QA profile parameters for timestamp_consistency.
+type timestampParams struct {
+	MaxBackwardJumps  *int64   `json:"max_backward_jumps"`
+	MaxPublishSkewSec *float64 `json:"max_publish_skew_sec"`
+	MaxStartOffsetSec *float64 `json:"max_start_offset_sec"`
+}
+
+func validateTimestampParams(params json.RawMessage) error {
+	var p timestampParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return err
+	}
+	if p.MaxBackwardJumps != nil && *p.MaxBackwardJumps < 0 {
+		return fmt.Errorf("max_backward_jumps must not be negative")
+	}
+	if p.MaxPublishSkewSec != nil && *p.MaxPublishSkewSec <= 0 {
+		return fmt.Errorf("max_publish_skew_sec must be greater than 0")
+	}
+	if p.MaxStartOffsetSec != nil && *p.MaxStartOffsetSec <= 0 {
+		return fmt.Errorf("max_start_offset_sec must be greater than 0")
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) timestampThresholds(params json.RawMessage) (timestampThresholds, error) {
+	thresholds := timestampThresholds{
+		MaxBackwardJumps:  defaultEpisodeQAMaxBackwardJumps,
+		MaxPublishSkewSec: defaultEpisodeQAMaxPublishSkewSec,
+		MaxStartOffsetSec: defaultEpisodeQAMaxStartOffsetSec,
+	}
+	if h.qaCfg != nil {
+		if h.qaCfg.TimestampMaxBackwardJumps > 0 {
+			thresholds.MaxBackwardJumps = int64(h.qaCfg.TimestampMaxBackwardJumps)
+		}
+		if h.qaCfg.TimestampMaxPublishSkewSec > 0 {
+			thresholds.MaxPublishSkewSec = h.qaCfg.TimestampMaxPublishSkewSec
+		}
+		if h.qaCfg.TimestampMaxStartOffsetSec > 0 {
+			thresholds.MaxStartOffsetSec = h.qaCfg.TimestampMaxStartOffsetSec
+		}
+	}
+
+	if err := validateTimestampParams(params); err != nil {
+		return thresholds, err
+	}
+	var p timestampParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return thresholds, err
+	}
+	if p.MaxBackwardJumps != nil {
+		thresholds.MaxBackwardJumps = *p.MaxBackwardJumps
+	}
+	if p.MaxPublishSkewSec != nil {
+		thresholds.MaxPublishSkewSec = *p.MaxPublishSkewSec
+	}
+	if p.MaxStartOffsetSec != nil {
+		thresholds.MaxStartOffsetSec = *p.MaxStartOffsetSec
+	}
+	return thresholds, nil
+}
+
+// channelClockStats summarizes log/publish time behaviour on one channel.
+type channelClockStats struct {
+	Topic         string
+	MessageCount  int64
+	FirstLogTime  uint64
+	BackwardJumps int64
+	// MaxBackwardNs is the largest log_time regression and MaxBackwardAt the
+	// log_time it regressed to.
+	MaxBackwardNs uint64
+	MaxBackwardAt uint64
+	// SkewedMessages counts messages whose |log_time - publish_time| exceeds
+	// the threshold; MaxSkewNs is the signed log-minus-publish offset with the
+	// largest magnitude. Messages without publish_time are not compared.
+	SkewedMessages int64
+	MaxSkewNs      int64
+	MaxSkewAt      uint64
+	hasPrev        bool
+	prev           uint64
+}
+
+func (s *channelClockStats) add(m *mcap.Message, maxSkewNs int64) {
+	s.MessageCount++
+	if s.MessageCount == 1 || m.LogTime < s.FirstLogTime {
+		s.FirstLogTime = m.LogTime
+	}
+	if s.hasPrev && m.LogTime < s.prev {
+		s.BackwardJumps++
+		if back := s.prev - m.LogTime; back > s.MaxBackwardNs {
+			s.MaxBackwardNs = back
+			s.MaxBackwardAt = m.LogTime
+		}
+	}
+	s.prev, s.hasPrev = m.LogTime, true
+
+	if m.PublishTime == 0 {
+		return
+	}
+	skew := int64(m.LogTime - m.PublishTime) // wraps to the signed offset
+	if absInt64(skew) > absInt64(s.MaxSkewNs) {
+		s.MaxSkewNs = skew
+		s.MaxSkewAt = m.LogTime
+	}
+	if absInt64(skew) > maxSkewNs {
+		s.SkewedMessages++
+	}
+}
+
+func absInt64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// channelClockCollector accumulates per-channel clock statistics during a
+// scan. Corrupt chunks are skipped and counted.
+type channelClockCollector struct {
+	maxSkewNs     int64
+	topics        map[uint16]string
+	channels      map[uint16]*channelClockStats
+	corruptChunks int
+}
+
+func newChannelClockCollector(maxSkewSec float64) *channelClockCollector {
+	return &channelClockCollector{
+		maxSkewNs: int64(maxSkewSec * 1e9),
+		topics:    map[uint16]string{},
+		channels:  map[uint16]*channelClockStats{},
+	}
+}
+
+func (c *channelClockCollector) visitor() mcap.Visitor {
+	return mcap.Visitor{
+		Channel: func(ch *mcap.Channel) error {
+			c.topics[ch.ID] = ch.Topic
+			return nil
+		},
+		Message: func(m *mcap.Message) error {
+			s, ok := c.channels[m.ChannelID]
+			if !ok {
+				topic, known := c.topics[m.ChannelID]
+				if !known {
+					topic = fmt.Sprintf("channel:%d", m.ChannelID)
+				}
+				s = &channelClockStats{Topic: topic}
+				c.channels[m.ChannelID] = s
+			}
+			s.add(m, c.maxSkewNs)
+			return nil
+		},
+		Chunk: func(_ *mcap.Chunk, chunkErr error) error {
+			if chunkErr != nil {
+				c.corruptChunks++
+			}
+			return nil
+		},
+	}
+}
+
+func (c *channelClockCollector) stats() []channelClockStats {
+	out := make([]channelClockStats, 0, len(c.channels))
+	for _, s := range c.channels {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Topic < out[j].Topic })
+	return out
+}
+
+// episodeClockContext is what the episode row knows about when and where the
+// recording was made.
+type episodeClockContext struct {
+	TaskID        sql.NullInt64  `db:"task_id"`
+	TaskStartedAt sql.NullTime   `db:"task_started_at"`
+	DeviceID      sql.NullString `db:"device_id"`
+}
+
+func (h *EpisodeQAHandler) loadEpisodeClockContext(ctx context.Context, episodeID int64) (episodeClockContext, error) {
+	var out episodeClockContext
+	if h.db == nil {
+		return out, nil
+	}
+	err := h.db.GetContext(ctx, &out, `
+		SELECT e.task_id AS task_id, t.started_at AS task_started_at, r.device_id AS device_id
+		FROM episodes e
+		LEFT JOIN tasks t ON t.id = e.task_id
+		LEFT JOIN workstations w ON w.id = e.workstation_id
+		LEFT JOIN robots r ON r.id = w.robot_id
+		WHERE e.id = ? AND e.deleted_at IS NULL
+		LIMIT 1
+	`, episodeID)
+	if err != nil && err != sql.ErrNoRows {
+		return out, fmt.Errorf("query episode clock context: %w", err)
+	}
+	return out, nil
+}
+
+func (h *EpisodeQAHandler) prepareTimestampsScan(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (*mcapScanConsumer, error) {
+	thresholds, err := h.timestampThresholds(params)
+	if err != nil {
+		return nil, err
+	}
+	clock, err := h.loadEpisodeClockContext(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	collector := newChannelClockCollector(thresholds.MaxPublishSkewSec)
+	return &mcapScanConsumer{
+		visitor: func(*mcap.Summary) mcap.Visitor { return collector.visitor() },
+		finish: func(pass *mcapScanPass) (episodeQACheckOutcome, error) {
+			metadata := pass.checkMetadata()
+			if pass.reason != "" {
+				return timestampsFailure("Timestamp check failed: "+pass.reason, 0, metadata), nil
+			}
+			metadata["corrupt_chunk_count"] = collector.corruptChunks
+			if pass.scanErr != nil {
+				metadata["scan_error"] = pass.scanErr.Error()
+				return timestampsFailure("Timestamp check failed: "+pass.scanErr.Error(), 0, metadata), nil
+			}
+			return evaluateTimestampsCheck(collector.stats(), clock, thresholds, metadata), nil
+		},
+	}, nil
+}
+
+func formatNanos(ns uint64) string {
+	return time.Unix(0, int64(ns)).UTC().Format(time.RFC3339Nano)
+}
+
+// evaluateTimestampsCheck fails channels whose log_time regresses or whose
+// publish_time drifts from log_time, and flags a recording that starts far
+// from the task's started_at. Offsets are reported in seconds, positive when
+// the recording clock is ahead.
+func evaluateTimestampsCheck(stats []channelClockStats, clock episodeClockContext, thresholds timestampThresholds, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["max_backward_jumps"] = thresholds.MaxBackwardJumps
+	metadata["max_publish_skew_sec"] = thresholds.MaxPublishSkewSec
+	metadata["max_start_offset_sec"] = thresholds.MaxStartOffsetSec
+	if clock.DeviceID.Valid {
+		metadata["device_id"] = clock.DeviceID.String
+	}
+	if clock.TaskID.Valid {
+		metadata["task_id"] = clock.TaskID.Int64
+	}
+
+	if len(stats) == 0 {
+		return timestampsFailure("Timestamp check failed: no messages found", 0, metadata)
+	}
+
+	items := make([]map[string]any, 0, len(stats))
+	failedTopics := []string{}
+	var recordingStart uint64
+	for i, s := range stats {
+		if i == 0 || s.FirstLogTime < recordingStart {
+			recordingStart = s.FirstLogTime
+		}
+		item := map[string]any{
+			"topic":           s.Topic,
+			"message_count":   s.MessageCount,
+			"backward_jumps":  s.BackwardJumps,
+			"skewed_messages": s.SkewedMessages,
+		}
+		if s.BackwardJumps > 0 {
+			item["max_backward_jump_sec"] = roundQAFloat(float64(s.MaxBackwardNs) / 1e9)
+			item["max_backward_jump_at"] = s.MaxBackwardAt
+		}
+		if s.MaxSkewNs != 0 {
+			item["max_publish_skew_sec"] = roundQAFloat(float64(s.MaxSkewNs) / 1e9)
+			item["max_publish_skew_at"] = s.MaxSkewAt
+		}
+		items = append(items, item)
+
+		reasons := []string{}
+		if s.BackwardJumps > thresholds.MaxBackwardJumps {
+			reasons = append(reasons, "log_time_not_monotonic")
+		}
+		if s.SkewedMessages > 0 {
+			reasons = append(reasons, "publish_log_skew")
+		}
+		if len(reasons) > 0 {
+			item["status"] = "failed"
+			item["reasons"] = reasons
+			failedTopics = append(failedTopics, s.Topic)
+			continue
+		}
+		item["status"] = "ok"
+	}
+	metadata["channels"] = items
+	metadata["failed_topics"] = failedTopics
+	metadata["recording_start_time"] = formatNanos(recordingStart)
+
+	problems := []string{}
+	score := float64(len(stats)-len(failedTopics)) / float64(len(stats))
+	if len(failedTopics) > 0 {
+		problems = append(problems, fmt.Sprintf("%d of %d channels have bad timestamps: %s", len(failedTopics), len(stats), strings.Join(failedTopics, ", ")))
+	}
+	if clock.TaskStartedAt.Valid {
+		startedAt := clock.TaskStartedAt.Time.UTC()
+		offsetSec := float64(int64(recordingStart)-startedAt.UnixNano()) / 1e9
+		metadata["task_started_at"] = startedAt.Format(time.RFC3339Nano)
+		metadata["start_offset_sec"] = roundQAFloat(offsetSec)
+		if math.Abs(offsetSec) > thresholds.MaxStartOffsetSec {
+			problems = append(problems, fmt.Sprintf("recording starts %.3fs from task started_at", offsetSec))
+			score = math.Min(score, timestampStartOffsetScore)
+		}
+	}
+
+	if len(problems) > 0 {
+		details := "Timestamp check failed: " + strings.Join(problems, "; ")
+		if clock.DeviceID.Valid {
+			details += " (device " + clock.DeviceID.String + ")"
+		}
+		return timestampsFailure(details, roundQAFloat(score), metadata)
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckTimestamps,
+		Passed:    true,
+		Score:     1,
+		Details:   fmt.Sprintf("Timestamps monotonic and consistent on %d channels", len(stats)),
+		Metadata:  metadata,
+	}
+}
+
+func timestampsFailure(details string, score float64, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckTimestamps,
+		Passed:    false,
+		Score:     score,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+func clockStatsFor(topic string, maxSkewSec float64, times ...[2]uint64) channelClockStats {
+	s := channelClockStats{Topic: topic}
+	for _, lt := range times {
+		s.add(&mcap.Message{LogTime: lt[0], PublishTime: lt[1]}, int64(maxSkewSec*1e9))
+	}
+	return s
+}
+
+func TestChannelClockStats(t *testing.T) {
+	s := clockStatsFor("/joint_states", 1,
+		[2]uint64{10e9, 10e9},
+		[2]uint64{11e9, 11e9 - 2e9},
+		[2]uint64{9e9, 0},
+		[2]uint64{12e9, 12e9 + 500e6},
+		[2]uint64{11.5e9, 11.5e9},
+	)
+	if s.MessageCount != 5 || s.FirstLogTime != 9e9 || s.BackwardJumps != 2 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if s.MaxBackwardNs != 2e9 || s.MaxBackwardAt != 9e9 {
+		t.Fatalf("backward = %d at %d", s.MaxBackwardNs, s.MaxBackwardAt)
+	}
+	if s.SkewedMessages != 1 || s.MaxSkewNs != 2e9 || s.MaxSkewAt != 11e9 {
+		t.Fatalf("skew = %d (%d messages) at %d", s.MaxSkewNs, s.SkewedMessages, s.MaxSkewAt)
+	}
+
+	ahead := clockStatsFor("/camera", 1, [2]uint64{5e9, 8e9})
+	if ahead.MaxSkewNs != -3e9 || ahead.SkewedMessages != 1 {
+		t.Fatalf("negative skew = %+v", ahead)
+	}
+}
+
+func TestEvaluateTimestampsCheck(t *testing.T) {
+	thresholds := timestampThresholds{MaxPublishSkewSec: 1, MaxStartOffsetSec: 60}
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	startNs := uint64(start.UnixNano())
+	good := clockStatsFor("/camera", 1, [2]uint64{startNs + 2e9, startNs + 2e9}, [2]uint64{startNs + 3e9, startNs + 3e9})
+	backward := clockStatsFor("/joint_states", 1, [2]uint64{startNs + 5e9, 0}, [2]uint64{startNs + 4e9, 0})
+	clock := episodeClockContext{
+		TaskID:        sql.NullInt64{Int64: 9, Valid: true},
+		TaskStartedAt: sql.NullTime{Time: start, Valid: true},
+		DeviceID:      sql.NullString{String: "robot-007", Valid: true},
+	}
+
+	outcome := evaluateTimestampsCheck([]channelClockStats{good}, clock, thresholds, nil)
+	if !outcome.Passed || outcome.Score != 1 || outcome.Metadata["start_offset_sec"] != 2.0 || outcome.Metadata["device_id"] != "robot-007" {
+		t.Fatalf("passing outcome = %+v", outcome)
+	}
+
+	outcome = evaluateTimestampsCheck([]channelClockStats{good, backward}, clock, thresholds, nil)
+	if outcome.Passed || outcome.Score != 0.5 || !strings.Contains(outcome.Details, "/joint_states") || !strings.Contains(outcome.Details, "robot-007") {
+		t.Fatalf("backward outcome = %+v", outcome)
+	}
+
+	skewedStart := clock
+	skewedStart.TaskStartedAt.Time = start.Add(-10 * time.Minute)
+	outcome = evaluateTimestampsCheck([]channelClockStats{good}, skewedStart, thresholds, nil)
+	if outcome.Passed || outcome.Score != timestampStartOffsetScore || outcome.Metadata["start_offset_sec"] != 602.0 {
+		t.Fatalf("start offset outcome = %+v", outcome)
+	}
+
+	noTask := evaluateTimestampsCheck([]channelClockStats{good}, episodeClockContext{}, thresholds, nil)
+	if !noTask.Passed {
+		t.Fatalf("episode without task start should pass: %+v", noTask)
+	}
+	if _, ok := noTask.Metadata["start_offset_sec"]; ok {
+		t.Fatalf("start offset reported without task start: %+v", noTask.Metadata)
+	}
+
+	if empty := evaluateTimestampsCheck(nil, clock, thresholds, nil); empty.Passed {
+		t.Fatalf("no messages should fail: %+v", empty)
+	}
+}
@@ -0,0 +1,299 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+const (
+	episodeQACheckRequiredTopics = "required_topics"
+
+	requiredTopicsSourceMcapSummary = "mcap_summary"
+	requiredTopicsSourceMcapScan    = "mcap_scan"
+	requiredTopicsSourceSidecar     = "sidecar"
+)
+
+// episodeQARobotTopicsRow is the robot type that recorded an episode.
+type episodeQARobotTopicsRow struct {
+	RobotType string `db:"robot_type"`
+	ROSTopics string `db:"ros_topics"`
+}
+
+// requiredTopicsParams are QA profile parameters for required_topics.
+type requiredTopicsParams struct {
+	// Topics replaces the robot type's ros_topics when set.
+	Topics []string `json:"topics"`
+}
+
+func validateRequiredTopicsParams(params json.RawMessage) error {
+	var p requiredTopicsParams
+	return decodeQACheckParams(params, &p)
+}
+
+// recordedTopicSet is the set of topics found in a recording and where they came from.
+type recordedTopicSet struct {
+	Source string
+	Topics []string
+	// Empty lists topics that have a channel but zero messages according to MCAP statistics.
+	Empty []string
+}
+
+// loadEpisodeRequiredTopics returns the robot type name and ros_topics for an episode.
+// found is false when the episode has no workstation, robot or robot type.
+func (h *EpisodeQAHandler) loadEpisodeRequiredTopics(ctx context.Context, episodeID int64) (string, []string, bool, error) {
+	var row episodeQARobotTopicsRow
+	err := h.db.GetContext(ctx, &row, `
+		SELECT
+			COALESCE(rt.name, rt.model, '') AS robot_type,
+			COALESCE(rt.ros_topics, '[]') AS ros_topics
+		FROM episodes e
+		JOIN workstations ws ON ws.id = e.workstation_id
+		JOIN robots r ON r.id = ws.robot_id
+		JOIN robot_types rt ON rt.id = r.robot_type_id
+		WHERE e.id = ? AND e.deleted_at IS NULL
+		LIMIT 1
+	`, episodeID)
+	if err == sql.ErrNoRows {
+		return "", nil, false, nil
+	}
+	if err != nil {
+		return "", nil, false, fmt.Errorf("query episode robot type topics: %w", err)
+	}
+	return row.RobotType, parseJSONArray(row.ROSTopics), true, nil
+}
+
+func (h *EpisodeQAHandler) runRequiredTopicsQACheck(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	if h.s3 == nil {
+		return episodeQACheckOutcome{}, fmt.Errorf("storage is not configured")
+	}
+	var p requiredTopicsParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+
+	robotType, required, found, err := h.loadEpisodeRequiredTopics(ctx, row.ID)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	metadata := map[string]any{"robot_type": robotType, "topics_source": "robot_type"}
+	if countNonEmptyStrings(p.Topics) > 0 {
+		required, found = p.Topics, true
+		metadata["topics_source"] = "profile"
+	}
+	if !found || countNonEmptyStrings(required) == 0 {
+		metadata["skipped"] = true
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckRequiredTopics,
+			Passed:    true,
+			Score:     1,
+			Details:   "Required topics check skipped: robot type has no ros_topics",
+			Metadata:  metadata,
+		}, nil
+	}
+
+	recorded, reason, err := h.loadRecordedTopics(ctx, row)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	if reason != "" {
+		metadata["required_topics"] = normalizeTopicList(required)
+		return requiredTopicsFailure("Required topics check failed: "+reason, 0, metadata), nil
+	}
+	return evaluateRequiredTopicsCheck(required, recorded, metadata), nil
+}
+
+// loadRecordedTopics prefers channels from the MCAP summary section, falls back
+// to the sidecar topic lists, and finally scans the MCAP data section for
+// channel records when neither is available.
+func (h *EpisodeQAHandler) loadRecordedTopics(ctx context.Context, row episodeQACheckRow) (recordedTopicSet, string, error) {
+	reader, _, reason, err := h.openEpisodeMcapReader(ctx, row)
+	if err != nil {
+		return recordedTopicSet{}, "", err
+	}
+
+	if reader != nil {
+		summary, err := reader.ReadSummary()
+		if err != nil && !isMcapFormatError(err) {
+			return recordedTopicSet{}, "", fmt.Errorf("read mcap summary: %w", err)
+		}
+		if err == nil && len(summary.Channels) > 0 {
+			return recordedTopicsFromSummary(summary), "", nil
+		}
+	}
+
+	if sidecar, ok := h.readEpisodeSidecar(ctx, row); ok {
+		topics := append([]string(nil), sidecar.Recording.TopicsRecorded...)
+		for _, t := range sidecar.TopicsSummary {
+			topics = append(topics, t.Topic)
+		}
+		if countNonEmptyStrings(topics) > 0 {
+			return recordedTopicSet{Source: requiredTopicsSourceSidecar, Topics: topics}, "", nil
+		}
+	}
+
+	if reader == nil {
+		return recordedTopicSet{}, "mcap " + reason + " and sidecar lists no topics", nil
+	}
+	var topics []string
+	err = reader.Scan(mcap.Visitor{
+		Channel: func(c *mcap.Channel) error {
+			topics = append(topics, c.Topic)
+			return nil
+		},
+	})
+	if err != nil {
+		if !isMcapFormatError(err) {
+			return recordedTopicSet{}, "", fmt.Errorf("scan mcap channels: %w", err)
+		}
+		if len(topics) == 0 {
+			return recordedTopicSet{}, "mcap is unreadable: " + err.Error(), nil
+		}
+	}
+	return recordedTopicSet{Source: requiredTopicsSourceMcapScan, Topics: topics}, "", nil
+}
+
+func recordedTopicsFromSummary(summary *mcap.Summary) recordedTopicSet {
+	set := recordedTopicSet{Source: requiredTopicsSourceMcapSummary}
+	for id, channel := range summary.Channels {
+		if summary.Statistics != nil && summary.Statistics.ChannelMessageCounts != nil {
+			if summary.Statistics.ChannelMessageCounts[id] == 0 {
+				set.Empty = append(set.Empty, channel.Topic)
+				continue
+			}
+		}
+		set.Topics = append(set.Topics, channel.Topic)
+	}
+	return set
+}
+
+// readEpisodeSidecar reads the sidecar JSON for best-effort fallbacks. It
+// returns false when the sidecar is missing, oversized or invalid.
+func (h *EpisodeQAHandler) readEpisodeSidecar(ctx context.Context, row episodeQACheckRow) (*sidecarJSON, bool) {
+	bucket, objectName, ok := resolveEpisodeMcapLocation(h.bucket, row.SidecarPath)
+	if !ok {
+		return nil, false
+	}
+	data, err := h.readS3Object(ctx, bucket, objectName, maxEpisodeQASidecarBytes)
+	if err != nil {
+		return nil, false
+	}
+	var sidecar sidecarJSON
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, false
+	}
+	return &sidecar, true
+}
+
+// normalizeTopicName makes "/camera/x" and "camera/x" compare equal.
+func normalizeTopicName(topic string) string {
+	topic = strings.TrimSpace(topic)
+	if topic == "" {
+		return ""
+	}
+	return "/" + strings.TrimLeft(topic, "/")
+}
+
+func normalizeTopicList(topics []string) []string {
+	seen := map[string]struct{}{}
+	out := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		name := normalizeTopicName(topic)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func evaluateRequiredTopicsCheck(required []string, recorded recordedTopicSet, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	requiredTopics := normalizeTopicList(required)
+	recordedTopics := normalizeTopicList(recorded.Topics)
+	emptyTopics := normalizeTopicList(recorded.Empty)
+
+	present := make(map[string]struct{}, len(recordedTopics))
+	for _, topic := range recordedTopics {
+		present[topic] = struct{}{}
+	}
+	empty := make(map[string]struct{}, len(emptyTopics))
+	for _, topic := range emptyTopics {
+		empty[topic] = struct{}{}
+	}
+
+	missing := []string{}
+	withoutMessages := []string{}
+	for _, topic := range requiredTopics {
+		if _, ok := present[topic]; ok {
+			continue
+		}
+		if _, ok := empty[topic]; ok {
+			withoutMessages = append(withoutMessages, topic)
+			continue
+		}
+		missing = append(missing, topic)
+	}
+
+	covered := len(requiredTopics) - len(missing) - len(withoutMessages)
+	coverage := 1.0
+	if len(requiredTopics) > 0 {
+		coverage = float64(covered) / float64(len(requiredTopics))
+	}
+
+	metadata["source"] = recorded.Source
+	metadata["required_topics"] = requiredTopics
+	metadata["recorded_topics"] = recordedTopics
+	metadata["missing_topics"] = missing
+	metadata["empty_topics"] = withoutMessages
+	metadata["coverage"] = coverage
+
+	if len(missing) == 0 && len(withoutMessages) == 0 {
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckRequiredTopics,
+			Passed:    true,
+			Score:     1,
+			Details:   fmt.Sprintf("All %d required topics recorded", len(requiredTopics)),
+			Metadata:  metadata,
+		}
+	}
+
+	parts := make([]string, 0, 2)
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(withoutMessages) > 0 {
+		parts = append(parts, "no messages on "+strings.Join(withoutMessages, ", "))
+	}
+	return requiredTopicsFailure(fmt.Sprintf("Required topics check failed: %s", strings.Join(parts, "; ")), coverage, metadata)
+}
+
+func requiredTopicsFailure(details string, score float64, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckRequiredTopics,
+		Passed:    false,
+		Score:     score,
+		Details:   details,
+		Metadata:  metadata,
+	}
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/mcap"
+)
+
+func TestEvaluateRequiredTopicsCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		required    []string
+		recorded    recordedTopicSet
+		wantPassed  bool
+		wantScore   float64
+		wantMissing []string
+		wantEmpty   []string
+		wantDetail  string
+	}{
+		{
+			name:        "all topics present with slash normalization",
+			required:    []string{"/camera/color", "joint_states"},
+			recorded:    recordedTopicSet{Source: requiredTopicsSourceMcapSummary, Topics: []string{"camera/color", "/joint_states", "/tf"}},
+			wantPassed:  true,
+			wantScore:   1,
+			wantMissing: []string{},
+			wantEmpty:   []string{},
+			wantDetail:  "All 2 required topics recorded",
+		},
+		{
+			name:        "missing topics scale score",
+			required:    []string{"/camera/color", "/camera/depth", "/joint_states", "/gripper"},
+			recorded:    recordedTopicSet{Source: requiredTopicsSourceSidecar, Topics: []string{"/camera/color", "/joint_states"}},
+			wantPassed:  false,
+			wantScore:   0.5,
+			wantMissing: []string{"/camera/depth", "/gripper"},
+			wantEmpty:   []string{},
+			wantDetail:  "Required topics check failed: missing /camera/depth, /gripper",
+		},
+		{
+			name:     "channel without messages counts as missing",
+			required: []string{"/camera/color", "/joint_states"},
+			recorded: recordedTopicSet{
+				Source: requiredTopicsSourceMcapSummary,
+				Topics: []string{"/joint_states"},
+				Empty:  []string{"/camera/color"},
+			},
+			wantPassed:  false,
+			wantScore:   0.5,
+			wantMissing: []string{},
+			wantEmpty:   []string{"/camera/color"},
+			wantDetail:  "Required topics check failed: no messages on /camera/color",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateRequiredTopicsCheck(tt.required, tt.recorded, nil)
+			if got.CheckName != episodeQACheckRequiredTopics {
+				t.Fatalf("check name = %q", got.CheckName)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Fatalf("passed = %v, want %v", got.Passed, tt.wantPassed)
+			}
+			if got.Score != tt.wantScore {
+				t.Fatalf("score = %v, want %v", got.Score, tt.wantScore)
+			}
+			if got.Details != tt.wantDetail {
+				t.Fatalf("details = %q, want %q", got.Details, tt.wantDetail)
+			}
+			if !reflect.DeepEqual(got.Metadata["missing_topics"], tt.wantMissing) {
+				t.Fatalf("missing_topics = %v, want %v", got.Metadata["missing_topics"], tt.wantMissing)
+			}
+			if !reflect.DeepEqual(got.Metadata["empty_topics"], tt.wantEmpty) {
+				t.Fatalf("empty_topics = %v, want %v", got.Metadata["empty_topics"], tt.wantEmpty)
+			}
+			if got.Metadata["source"] != tt.recorded.Source {
+				t.Fatalf("source = %v, want %q", got.Metadata["source"], tt.recorded.Source)
+			}
+		})
+	}
+}
+
+func TestRecordedTopicsFromSummarySplitsEmptyChannels(t *testing.T) {
+	got := recordedTopicsFromSummary(&mcap.Summary{
+		Channels: map[uint16]*mcap.Channel{
+			1: {ID: 1, Topic: "/camera/color"},
+			2: {ID: 2, Topic: "/joint_states"},
+		},
+		Statistics: &mcap.Statistics{ChannelMessageCounts: map[uint16]uint64{1: 0, 2: 120}},
+	})
+	if !reflect.DeepEqual(got.Topics, []string{"/joint_states"}) {
+		t.Fatalf("topics = %v", got.Topics)
+	}
+	if !reflect.DeepEqual(got.Empty, []string{"/camera/color"}) {
+		t.Fatalf("empty = %v", got.Empty)
+	}
+}
+
+func TestLoadEpisodeRequiredTopics(t *testing.T) {
+	db := setupEpisodeQACheckTestDB(t)
+	if _, err := db.Exec(`
+		ALTER TABLE episodes ADD COLUMN workstation_id INTEGER;
+		CREATE TABLE workstations (id INTEGER PRIMARY KEY, robot_id INTEGER);
+		CREATE TABLE robots (id INTEGER PRIMARY KEY, robot_type_id INTEGER);
+		CREATE TABLE robot_types (id INTEGER PRIMARY KEY, name TEXT, model TEXT, ros_topics TEXT);
+		INSERT INTO robot_types (id, name, model, ros_topics) VALUES (1, 'arm-a', 'A1', '["/camera/color","/joint_states"]');
+		INSERT INTO robots (id, robot_type_id) VALUES (1, 1);
+		INSERT INTO workstations (id, robot_id) VALUES (1, 1);
+		INSERT INTO episodes (id, mcap_path, workstation_id) VALUES (1, 'a.mcap', 1);
+		INSERT INTO episodes (id, mcap_path, workstation_id) VALUES (2, 'b.mcap', NULL);
+	`); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	handler := &EpisodeQAHandler{db: db}
+
+	robotType, topics, found, err := handler.loadEpisodeRequiredTopics(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("load topics: %v", err)
+	}
+	if !found || robotType != "arm-a" {
+		t.Fatalf("found = %v, robot type = %q", found, robotType)
+	}
+	if !reflect.DeepEqual(topics, []string{"/camera/color", "/joint_states"}) {
+		t.Fatalf("topics = %v", topics)
+	}
+
+	if _, _, found, err := handler.loadEpisodeRequiredTopics(context.Background(), 2); err != nil || found {
+		t.Fatalf("episode without workstation: found = %v, err = %v", found, err)
+	}
+}
@@ -0,0 +1,270 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	episodeQACheckWriterHealth = "writer_health"
+
+	// writerHealthFailScore caps the score of an episode the recorder itself
+	// flagged: a critical state, a reported error or a writer stall.
+	writerHealthFailScore = 0.5
+	// writerHealthWarningScore caps the score of an episode whose recorder
+	// reported a warning state; the check still passes.
+	writerHealthWarningScore = 0.8
+
+	writerHealthStateCritical = "critical"
+	writerHealthStateWarning  = "warning"
+)
+
+// writerHealthThresholds are the pass criteria for the writer_health check.
+// Counters above their maximum fail the episode.
+type writerHealthThresholds struct {
+	MaxPartialFailures int64
+	MaxQueueOverflows  int64
+	FailOnStall        bool
+}
+
+// writerHealthParams are QA profile parameters for writer_health; bind a
+// profile to a robot type to tune them per robot type.
+type writerHealthParams struct {
+	MaxPartialFailures *int64 `json:"max_partial_failures"`
+	MaxQueueOverflows  *int64 `json:"max_queue_overflows"`
+	FailOnStall        *bool  `json:"fail_on_stall"`
+}
+
+func validateWriterHealthParams(params json.RawMessage) error {
+	var p writerHealthParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return err
+	}
+	for field, v := range map[string]*int64{
+		"max_partial_failures": p.MaxPartialFailures,
+		"max_queue_overflows":  p.MaxQueueOverflows,
+	} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%s must not be negative", field)
+		}
+	}
+	return nil
+}
+
+func (h *EpisodeQAHandler) writerHealthThresholds(params json.RawMessage) (writerHealthThresholds, error) {
+	thresholds := writerHealthThresholds{FailOnStall: true}
+	if h.qaCfg != nil {
+		thresholds = writerHealthThresholds{
+			MaxPartialFailures: int64(max(h.qaCfg.WriterMaxPartialFailures, 0)),
+			MaxQueueOverflows:  int64(max(h.qaCfg.WriterMaxQueueOverflows, 0)),
+			FailOnStall:        h.qaCfg.WriterFailOnStall,
+		}
+	}
+
+	if err := validateWriterHealthParams(params); err != nil {
+		return thresholds, err
+	}
+	var p writerHealthParams
+	if err := decodeQACheckParams(params, &p); err != nil {
+		return thresholds, err
+	}
+	if p.MaxPartialFailures != nil {
+		thresholds.MaxPartialFailures = *p.MaxPartialFailures
+	}
+	if p.MaxQueueOverflows != nil {
+		thresholds.MaxQueueOverflows = *p.MaxQueueOverflows
+	}
+	if p.FailOnStall != nil {
+		thresholds.FailOnStall = *p.FailOnStall
+	}
+	return thresholds, nil
+}
+
+// loadEpisodeWriterHealth returns the recorder writer_health block saved in
+// episode metadata at upload time, falling back to the sidecar for episodes
+// uploaded before it was persisted.
+func (h *EpisodeQAHandler) loadEpisodeWriterHealth(ctx context.Context, row episodeQACheckRow) (map[string]any, string, error) {
+	if h.db != nil {
+		var raw sql.NullString
+		err := h.db.GetContext(ctx, &raw, "SELECT metadata FROM episodes WHERE id = ? AND deleted_at IS NULL", row.ID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, "", fmt.Errorf("query episode metadata: %w", err)
+		}
+		if writerHealth, ok := writerHealthFromEpisodeMetadata(raw); ok {
+			return writerHealth, "episode_metadata", nil
+		}
+	}
+	if h.s3 != nil {
+		if sidecar, ok := h.readEpisodeSidecar(ctx, row); ok {
+			if writerHealth, ok := sidecarWriterHealthMetadata(sidecar); ok {
+				return writerHealth, "sidecar", nil
+			}
+		}
+	}
+	return nil, "", nil
+}
+
+// writerHealthFromEpisodeMetadata reads metadata.recorder.writer_health.
+func writerHealthFromEpisodeMetadata(raw sql.NullString) (map[string]any, bool) {
+	if !raw.Valid || strings.TrimSpace(raw.String) == "" {
+		return nil, false
+	}
+	var metadata map[string]any
+	if err := json.Unmarshal([]byte(raw.String), &metadata); err != nil {
+		return nil, false
+	}
+	recorder, _ := metadata["recorder"].(map[string]any)
+	writerHealth, _ := recorder["writer_health"].(map[string]any)
+	return writerHealth, writerHealth != nil
+}
+
+func (h *EpisodeQAHandler) runWriterHealthQACheck(ctx context.Context, row episodeQACheckRow, params json.RawMessage) (episodeQACheckOutcome, error) {
+	thresholds, err := h.writerHealthThresholds(params)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	writerHealth, source, err := h.loadEpisodeWriterHealth(ctx, row)
+	if err != nil {
+		return episodeQACheckOutcome{}, err
+	}
+	metadata := map[string]any{}
+	if source != "" {
+		metadata["source"] = source
+	}
+	return evaluateWriterHealthCheck(writerHealth, thresholds, metadata), nil
+}
+
+// writerHealthCount reads a recorder counter; missing or malformed counters
+// count as zero.
+func writerHealthCount(writerHealth map[string]any, key string) int64 {
+	switch v := writerHealth[key].(type) {
+	case float64:
+		return int64(math.Max(v, 0))
+	case json.Number:
+		n, _ := v.Int64()
+		return max(n, 0)
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return max(n, 0)
+	}
+	return 0
+}
+
+// writerHealthCounterScore degrades smoothly once count exceeds limit, so an
+// episode with one overflow too many scores better than one with hundreds.
+func writerHealthCounterScore(count, limit int64) float64 {
+	if count <= limit {
+		return 1
+	}
+	return float64(limit+1) / float64(count+1)
+}
+
+// evaluateWriterHealthCheck scores recorder-side write-path health. A
+// critical state, a recorder error, a suspected or critical writer stall and
+// counters above their maxima fail the episode; a warning state only lowers
+// its score. Episodes without writer_health (older recorders) pass as
+// skipped.
+func evaluateWriterHealthCheck(writerHealth map[string]any, thresholds writerHealthThresholds, metadata map[string]any) episodeQACheckOutcome {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["max_partial_failures"] = thresholds.MaxPartialFailures
+	metadata["max_queue_overflows"] = thresholds.MaxQueueOverflows
+	metadata["fail_on_stall"] = thresholds.FailOnStall
+	if writerHealth == nil {
+		metadata["status"] = "skipped"
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckWriterHealth,
+			Passed:    true,
+			Score:     1,
+			Details:   "No recorder writer_health reported; writer health check skipped",
+			Metadata:  metadata,
+		}
+	}
+
+	partialFailures := writerHealthCount(writerHealth, "writer_partial_failures")
+	queueOverflows := writerHealthCount(writerHealth, "writer_queue_overflows")
+	stallSuspected, _ := writerHealth["writer_stall_suspected"].(bool)
+	state, _ := writerHealth["state"].(string)
+	stallState, _ := writerHealth["writer_stall_state"].(string)
+	recorderError, _ := writerHealth["error"].(string)
+	state = strings.ToLower(strings.TrimSpace(state))
+	stallState = strings.ToLower(strings.TrimSpace(stallState))
+	recorderError = strings.TrimSpace(recorderError)
+
+	metadata["state"] = state
+	metadata["writer_stall_state"] = stallState
+	metadata["writer_partial_failures"] = partialFailures
+	metadata["writer_queue_overflows"] = queueOverflows
+	metadata["writer_stall_suspected"] = stallSuspected
+	if recorderError != "" {
+		metadata["error"] = recorderError
+	}
+
+	score := 1.0
+	reasons := []string{}
+	for _, counter := range []struct {
+		reason string
+		count  int64
+		limit  int64
+	}{
+		{"writer_partial_failures", partialFailures, thresholds.MaxPartialFailures},
+		{"writer_queue_overflows", queueOverflows, thresholds.MaxQueueOverflows},
+	} {
+		if counter.count > counter.limit {
+			reasons = append(reasons, fmt.Sprintf("%s=%d (max %d)", counter.reason, counter.count, counter.limit))
+			score = math.Min(score, writerHealthCounterScore(counter.count, counter.limit))
+		}
+	}
+	if state == writerHealthStateCritical {
+		reasons = append(reasons, "state=critical")
+		score = math.Min(score, writerHealthFailScore)
+	}
+	if recorderError != "" {
+		reasons = append(reasons, "error="+recorderError)
+		score = math.Min(score, writerHealthFailScore)
+	}
+	if thresholds.FailOnStall && (stallSuspected || stallState == writerHealthStateCritical) {
+		reasons = append(reasons, "writer_stall_suspected")
+		score = math.Min(score, writerHealthFailScore)
+	}
+	metadata["reasons"] = reasons
+
+	if len(reasons) > 0 {
+		metadata["status"] = "failed"
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckWriterHealth,
+			Passed:    false,
+			Score:     roundQAFloat(score),
+			Details:   "Recorder writer health check failed: " + strings.Join(reasons, ", "),
+			Metadata:  metadata,
+		}
+	}
+	if state == writerHealthStateWarning || stallState == writerHealthStateWarning {
+		metadata["status"] = "warning"
+		return episodeQACheckOutcome{
+			CheckName: episodeQACheckWriterHealth,
+			Passed:    true,
+			Score:     writerHealthWarningScore,
+			Details:   "Recorder reported write-path warnings; counters within thresholds",
+			Metadata:  metadata,
+		}
+	}
+	metadata["status"] = "ok"
+	return episodeQACheckOutcome{
+		CheckName: episodeQACheckWriterHealth,
+		Passed:    true,
+		Score:     1,
+		Details:   "Recorder writer health within thresholds",
+		Metadata:  metadata,
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestEvaluateWriterHealthCheck(t *testing.T) {
+	tests := []struct {
+		name         string
+		writerHealth string
+		thresholds   writerHealthThresholds
+		wantPassed   bool
+		wantScore    float64
+		wantDetail   string
+	}{
+		{
+			name:         "normal passes",
+			writerHealth: `{"state":"normal","writer_stall_suspected":false,"writer_partial_failures":0,"writer_queue_overflows":0,"error":null}`,
+			wantPassed:   true,
+			wantScore:    1,
+			wantDetail:   "within thresholds",
+		},
+		{
+			name:       "missing block is skipped",
+			wantPassed: true,
+			wantScore:  1,
+			wantDetail: "skipped",
+		},
+		{
+			name:         "queue overflow fails with graded score",
+			writerHealth: `{"state":"critical","writer_partial_failures":0,"writer_queue_overflows":3}`,
+			wantScore:    0.25,
+			wantDetail:   "writer_queue_overflows=3 (max 0)",
+		},
+		{
+			name:         "warning within raised limits passes with reduced score",
+			writerHealth: `{"state":"warning","writer_partial_failures":1,"writer_queue_overflows":"2"}`,
+			thresholds:   writerHealthThresholds{MaxPartialFailures: 1, MaxQueueOverflows: 2},
+			wantPassed:   true,
+			wantScore:    writerHealthWarningScore,
+			wantDetail:   "warnings",
+		},
+		{
+			name:         "critical state fails",
+			writerHealth: `{"state":"critical"}`,
+			wantScore:    writerHealthFailScore,
+			wantDetail:   "state=critical",
+		},
+		{
+			name:         "recorder error fails",
+			writerHealth: `{"state":"normal","error":"mcap writer closed"}`,
+			wantScore:    writerHealthFailScore,
+			wantDetail:   "error=mcap writer closed",
+		},
+		{
+			name:         "stall fails by default",
+			writerHealth: `{"writer_stall_suspected":true}`,
+			thresholds:   writerHealthThresholds{FailOnStall: true},
+			wantScore:    writerHealthFailScore,
+			wantDetail:   "writer_stall_suspected",
+		},
+		{
+			name:         "critical stall state fails",
+			writerHealth: `{"writer_stall_state":"critical"}`,
+			thresholds:   writerHealthThresholds{FailOnStall: true},
+			wantScore:    writerHealthFailScore,
+			wantDetail:   "writer_stall_suspected",
+		},
+		{
+			name:         "stall ignored when disabled",
+			writerHealth: `{"writer_stall_suspected":true}`,
+			wantPassed:   true,
+			wantScore:    1,
+			wantDetail:   "within thresholds",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writerHealth map[string]any
+			if tt.writerHealth != "" {
+				if err := json.Unmarshal([]byte(tt.writerHealth), &writerHealth); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+			}
+			outcome := evaluateWriterHealthCheck(writerHealth, tt.thresholds, nil)
+			if outcome.CheckName != episodeQACheckWriterHealth || outcome.Passed != tt.wantPassed || outcome.Score != tt.wantScore {
+				t.Fatalf("outcome = %+v", outcome)
+			}
+			if !strings.Contains(outcome.Details, tt.wantDetail) {
+				t.Fatalf("details = %q, want %q", outcome.Details, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestWriterHealthThresholdsParams(t *testing.T) {
+	h := &EpisodeQAHandler{qaCfg: &config.QAConfig{WriterMaxQueueOverflows: 2, WriterFailOnStall: true}}
+	thresholds, err := h.writerHealthThresholds(json.RawMessage(`{"max_queue_overflows":5,"fail_on_stall":false}`))
+	if err != nil {
+		t.Fatalf("thresholds: %v", err)
+	}
+	if thresholds.MaxQueueOverflows != 5 || thresholds.FailOnStall || thresholds.MaxPartialFailures != 0 {
+		t.Fatalf("thresholds = %+v", thresholds)
+	}
+	if defaults, err := (&EpisodeQAHandler{}).writerHealthThresholds(nil); err != nil || !defaults.FailOnStall {
+		t.Fatalf("default thresholds = %+v, %v", defaults, err)
+	}
+	for _, params := range []string{`{"max_partial_failures":-1}`, `{"unknown":1}`} {
+		if _, err := h.writerHealthThresholds(json.RawMessage(params)); err == nil {
+			t.Fatalf("params %s accepted", params)
+		}
+	}
+}
+
+func TestRunWriterHealthQACheckReadsEpisodeMetadata(t *testing.T) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := db.Exec(`
+		CREATE TABLE episodes (id INTEGER PRIMARY KEY, metadata TEXT, deleted_at TIMESTAMP NULL);
+		INSERT INTO episodes (id, metadata) VALUES
+			(1, '{"asset_id":"a-1","recorder":{"writer_health":{"state":"critical","writer_partial_failures":2,"error":"writer_partial_failures=2"}}}');
+	`); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	h := &EpisodeQAHandler{db: db, qaCfg: &config.QAConfig{}}
+	outcome, err := h.runWriterHealthQACheck(context.Background(), episodeQACheckRow{ID: 1}, nil)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if outcome.Passed || outcome.Score != 0.333 || !strings.Contains(outcome.Details, "state=critical") || outcome.Metadata["source"] != "episode_metadata" || outcome.Metadata["error"] != "writer_partial_failures=2" {
+		t.Fatalf("outcome = %+v", outcome)
+	}
+}
@@ -827,7 +827,9 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 		args = append(args, priority)
 	}
 
+	newStatus := ""
 	if autoStatusFromTarget != nil {
+		newStatus = *autoStatusFromTarget
 		updates = append(updates, "status = ?")
 		args = append(args, *autoStatusFromTarget)
 	} else if req.Status != nil {
@@ -840,6 +842,7 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
 			return
 		}
+		newStatus = status
 		updates = append(updates, "status = ?")
 		args = append(args, status)
 	}
@@ -854,45 +857,52 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	args = append(args, now, id)
 
 	query := fmt.Sprintf("UPDATE orders SET %s WHERE id = ? AND deleted_at IS NULL", strings.Join(updates, ", "))
-	if autoStatusFromTarget != nil && *autoStatusFromTarget == "completed" {
-		tx, err := h.db.Beginx()
-		if err != nil {
-			logger.Printf("[ORDER] Failed to begin tx for order update: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
-			return
-		}
-		defer func() { _ = tx.Rollback() }()
-		if _, err := tx.Exec(query, args...); err != nil {
-			logger.Printf("[ORDER] Failed to update order: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
-			return
+	tx, err := h.db.Beginx()
+	if err != nil {
+		logger.Printf("[ORDER] Failed to begin tx for order update: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
+		return
+	}
+	defer func() { _ = tx.Rollback() }()
+	actor := requestTransitionActor(c)
+	if newStatus != "" {
+		metadata := map[string]any{"reason": "order_update"}
+		if autoStatusFromTarget != nil {
+			metadata["reason"] = "target_count_update"
 		}
-		orderFinalizeRecorderNotifies, finErr := finalizeOpenBatchesAfterOrderCompletedTx(tx, id, now)
+		_, err = transitionStateRows(tx, stateEntityOrder, newStatus, actor, metadata,
+			"id = ? AND deleted_at IS NULL", []any{id}, execAffected(tx, query, args...))
+	} else {
+		_, err = tx.Exec(query, args...)
+	}
+	if err != nil {
+		logger.Printf("[ORDER] Failed to update order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
+		return
+	}
+	var orderFinalizeRecorderNotifies []orderCompletionRecorderNotify
+	if autoStatusFromTarget != nil && *autoStatusFromTarget == "completed" {
+		var finErr error
+		orderFinalizeRecorderNotifies, finErr = finalizeOpenBatchesAfterOrderCompletedTx(tx, id, now, actor)
 		if finErr != nil {
 			logger.Printf("[ORDER] Failed to finalize open batches after order completed via target_count: %v", finErr)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
 			return
 		}
-		if err := tx.Commit(); err != nil {
-			logger.Printf("[ORDER] Failed to commit order update: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
-			return
-		}
-		if len(orderFinalizeRecorderNotifies) > 0 && h.recorderHub != nil {
-			notifies := orderFinalizeRecorderNotifies
-			go func() {
-				ctx := context.Background()
-				for _, n := range notifies {
-					notifyRecorderCancelTasksWithHub(ctx, h.recorderHub, h.recorderRPCTimeout, n.BatchID, n.Rows)
-				}
-			}()
-		}
-	} else {
-		if _, err := h.db.Exec(query, args...); err != nil {
-			logger.Printf("[ORDER] Failed to update order: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
-			return
-		}
+	}
+	if err := tx.Commit(); err != nil {
+		logger.Printf("[ORDER] Failed to commit order update: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order"})
+		return
+	}
+	if len(orderFinalizeRecorderNotifies) > 0 && h.recorderHub != nil {
+		notifies := orderFinalizeRecorderNotifies
+		go func() {
+			ctx := context.Background()
+			for _, n := range notifies {
+				notifyRecorderCancelTasksWithHub(ctx, h.recorderHub, h.recorderRPCTimeout, n.BatchID, n.Rows)
+			}
+		}()
 	}
 
 	h.GetOrder(c)
@@ -970,7 +980,7 @@ func (h *OrderHandler) DeleteOrder(c *gin.Context) {
 //
 // This helper uses its own transaction and is safe to call after task updates commit.
 // recorderHub may be nil (skips Axon clear/cancel RPCs after finalizing open batches).
-func tryAdvanceOrderStatus(db *sqlx.DB, orderID int64, recorderHub *services.RecorderHub, recorderRPCTimeout time.Duration) {
+func tryAdvanceOrderStatus(db *sqlx.DB, orderID int64, recorderHub *services.RecorderHub, recorderRPCTimeout time.Duration, actor stateTransitionActor) {
 	tx, err := db.Beginx()
 	if err != nil {
 		logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to begin tx for order %d: %v", orderID, err)
@@ -1019,6 +1029,10 @@ func tryAdvanceOrderStatus(db *sqlx.DB, orderID int64, recorderHub *services.Rec
 			logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to advance order %d created->in_progress: %v", orderID, err)
 			return
 		}
+		if err := recordStateTransitions(tx, stateEntityOrder, []stateRow{{ID: orderID, State: "created"}}, "in_progress", actor, map[string]any{"reason": "first_task_completed"}); err != nil {
+			logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to record order %d transition: %v", orderID, err)
+			return
+		}
 		info.Status = "in_progress"
 	}
 
@@ -1030,8 +1044,12 @@ func tryAdvanceOrderStatus(db *sqlx.DB, orderID int64, recorderHub *services.Rec
 			logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to advance order %d in_progress->completed: %v", orderID, err)
 			return
 		}
+		if err := recordStateTransitions(tx, stateEntityOrder, []stateRow{{ID: orderID, State: "in_progress"}}, "completed", actor, map[string]any{"reason": "target_reached"}); err != nil {
+			logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to record order %d transition: %v", orderID, err)
+			return
+		}
 		var finErr error
-		orderFinalizeRecorderNotifies, finErr = finalizeOpenBatchesAfterOrderCompletedTx(tx, orderID, now)
+		orderFinalizeRecorderNotifies, finErr = finalizeOpenBatchesAfterOrderCompletedTx(tx, orderID, now, actor)
 		if finErr != nil {
 			logger.Printf("[ORDER] tryAdvanceOrderStatus: failed to finalize open batches for completed order %d: %v", orderID, finErr)
 			return
@@ -5,12 +5,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
 	_ "modernc.org/sqlite"
@@ -57,6 +62,67 @@ func TestOrderHandlerListOrdersRejectsInvalidStatus(t *testing.T) {
 	}
 }
 
+// scopedGrantResolver grants a fixed permission set regardless of the caller.
+type scopedGrantResolver []middleware.PermissionGrant
+
+func (r scopedGrantResolver) ResolvePermissions(context.Context, *auth.Claims) ([]middleware.PermissionGrant, error) {
+	return r, nil
+}
+
+func TestOrderWritesHonorOrganizationScopedGrants(t *testing.T) {
+	db := newTestOrderHandlerDB(t)
+	defer db.Close()
+	seedOrderListFixtures(t, db)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.ClaimsKey, auth.NewCollectorClaims(3, "op-3"))
+		c.Next()
+	})
+	routes := map[string]string{
+		"POST /api/v1/orders":       middleware.PermissionOrderWrite,
+		"PUT /api/v1/orders/:id":    middleware.PermissionOrderWrite,
+		"DELETE /api/v1/orders/:id": middleware.PermissionOrderWrite,
+	}
+	scopes := NewRequestScopes(db)
+	r.Use(middleware.RoutePermissions(&config.AuthConfig{}, nil, scopedGrantResolver{
+		{Permission: middleware.PermissionOrderWrite, ScopeType: middleware.PermissionScopeOrganization, ScopeID: 1},
+	}, routes, map[string]middleware.RequestScopeFunc{
+		"POST /api/v1/orders":       scopes.OrderBody,
+		"PUT /api/v1/orders/:id":    scopes.Order,
+		"DELETE /api/v1/orders/:id": scopes.Order,
+	}))
+	NewOrderHandler(db, nil, 0).RegisterRoutes(r.Group("/api/v1"))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The body names the organization; the handler still sees the full body.
+	if w := do(http.MethodPost, "/api/v1/orders", `{"organization_id":"1","scene_id":"10","name":"scoped","target_count":5}`); w.Code != http.StatusCreated {
+		t.Fatalf("create in granted organization = %d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPost, "/api/v1/orders", `{"organization_id":2,"scene_id":"11","name":"other","target_count":5}`); w.Code != http.StatusForbidden {
+		t.Fatalf("create in other organization = %d, want 403", w.Code)
+	}
+
+	// Updates and deletes are scoped by the order they target.
+	if w := do(http.MethodPut, "/api/v1/orders/1", `{"name":"renamed"}`); w.Code != http.StatusOK {
+		t.Fatalf("update order of granted organization = %d, body=%s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodPut, "/api/v1/orders/2", `{"name":"renamed"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("update order of other organization = %d, want 403", w.Code)
+	}
+	if w := do(http.MethodDelete, "/api/v1/orders/2", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("delete order of other organization = %d, want 403", w.Code)
+	}
+}
+
 func newTestOrderRouter(t *testing.T, db *sqlx.DB) *gin.Engine {
 	t.Helper()
 	gin.SetMode(gin.TestMode)
@@ -79,11 +145,13 @@ func newTestOrderHandlerDB(t *testing.T) *sqlx.DB {
 	schema := []string{
 		`CREATE TABLE organizations (
 			id INTEGER PRIMARY KEY,
+			factory_id INTEGER NOT NULL DEFAULT 0,
 			name TEXT NOT NULL,
 			deleted_at TIMESTAMP NULL
 		)`,
 		`CREATE TABLE scenes (
 			id INTEGER PRIMARY KEY,
+			factory_id INTEGER NOT NULL DEFAULT 0,
 			name TEXT NOT NULL,
 			deleted_at TIMESTAMP NULL
 		)`,
@@ -114,6 +182,7 @@ func newTestOrderHandlerDB(t *testing.T) *sqlx.DB {
 		}
 	}
 
+	createStateTransitionsTestTable(t, db)
 	return db
 }
 
@@ -6,6 +6,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -387,6 +388,10 @@ func (h *ProductionDashboardHandler) GetSnapshot(c *gin.Context) {
 	}
 
 	scope, err := h.resolveProductionDashboardScope(c, claims, q)
+	if errors.Is(err, errDashboardOutOfScope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Printf("[DASHBOARD] scope query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get production dashboard"})
@@ -521,6 +526,10 @@ func (h *ProductionDashboardHandler) GetOverview(c *gin.Context) {
 	}
 
 	scope, err := h.resolveProductionDashboardScope(c, claims, q)
+	if errors.Is(err, errDashboardOutOfScope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Printf("[DASHBOARD] overview scope query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get production dashboard overview"})
@@ -647,6 +656,10 @@ func (h *ProductionDashboardHandler) GetBatchTaskSummary(c *gin.Context) {
 	}
 
 	scope, err := h.resolveProductionDashboardScope(c, claims, productionDashboardQuery{})
+	if errors.Is(err, errDashboardOutOfScope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		logger.Printf("[DASHBOARD] scope query failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get batch task summary"})
@@ -809,10 +822,7 @@ func (h *ProductionDashboardHandler) resolveProductionDashboardScope(c *gin.Cont
 		collectorID:    claims.CollectorID,
 	}
 
-	switch claims.Role {
-	case "admin", "display":
-		return scope, nil
-	case "data_collector":
+	if claims.Role == "data_collector" {
 		var workstationID string
 		err := h.db.GetContext(c.Request.Context(), &workstationID, `
 			SELECT CAST(id AS CHAR)
@@ -833,9 +843,48 @@ func (h *ProductionDashboardHandler) resolveProductionDashboardScope(c *gin.Cont
 		scope.FactoryID = ""
 		scope.OrganizationID = ""
 		return scope, nil
+	}
+	if scopes := middleware.GetPermissionScopes(c); len(scopes) > 0 {
+		return narrowProductionDashboardScope(scope, scopes)
+	}
+	return scope, nil
+}
+
+// errDashboardOutOfScope rejects a caller with scoped dashboard:read grants
+// whose filters do not select one of those scopes.
+var errDashboardOutOfScope = errors.New("factory_id or organization_id must select one of your permission scopes")
+
+// narrowProductionDashboardScope confines scope to the organizations and
+// factories the caller's grants cover. A filter naming one of them is kept;
+// a caller with a single scope gets it applied when it names none.
+func narrowProductionDashboardScope(scope productionDashboardScope, granted []middleware.PermissionScope) (productionDashboardScope, error) {
+	seen := make(map[middleware.PermissionScope]bool, len(granted))
+	var distinct []middleware.PermissionScope
+	for _, g := range granted {
+		id := strconv.FormatInt(g.ID, 10)
+		if (g.Type == middleware.PermissionScopeFactory && scope.FactoryID == id) ||
+			(g.Type == middleware.PermissionScopeOrganization && scope.OrganizationID == id) {
+			return scope, nil
+		}
+		if !seen[g] {
+			seen[g] = true
+			distinct = append(distinct, g)
+		}
+	}
+	if len(distinct) != 1 {
+		return productionDashboardScope{}, errDashboardOutOfScope
+	}
+	only := distinct[0]
+	id := strconv.FormatInt(only.ID, 10)
+	switch {
+	case only.Type == middleware.PermissionScopeFactory && scope.FactoryID == "":
+		scope.FactoryID = id
+	case only.Type == middleware.PermissionScopeOrganization && scope.OrganizationID == "":
+		scope.OrganizationID = id
 	default:
-		return productionDashboardScope{}, fmt.Errorf("unsupported role")
+		return productionDashboardScope{}, errDashboardOutOfScope
 	}
+	return scope, nil
 }
 
 func emptyProductionDashboardSnapshot(scope productionDashboardScope) productionDashboardSnapshotResponse {
@@ -5,12 +5,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -124,6 +126,34 @@ func TestResolveProductionDashboardScopeAllowsDisplayRole(t *testing.T) {
 	}
 }
 
+func TestResolveProductionDashboardScopeNarrowsScopedGrants(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &ProductionDashboardHandler{}
+	resolve := func(granted []middleware.PermissionScope, q productionDashboardQuery) (productionDashboardScope, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/dashboard/overview", nil)
+		c.Set(middleware.PermissionScopeKey, granted)
+		return h.resolveProductionDashboardScope(c, &auth.Claims{Role: "factory_manager"}, q)
+	}
+	factory7 := middleware.PermissionScope{Type: middleware.PermissionScopeFactory, ID: 7}
+	org3 := middleware.PermissionScope{Type: middleware.PermissionScopeOrganization, ID: 3}
+
+	scope, err := resolve([]middleware.PermissionScope{factory7}, productionDashboardQuery{OrganizationID: "9"})
+	if err != nil || scope.FactoryID != "7" || scope.OrganizationID != "9" {
+		t.Fatalf("single scope = %+v, %v; want factory 7 applied", scope, err)
+	}
+	if _, err := resolve([]middleware.PermissionScope{factory7}, productionDashboardQuery{FactoryID: "8"}); !errors.Is(err, errDashboardOutOfScope) {
+		t.Fatalf("other factory error = %v, want out of scope", err)
+	}
+	if _, err := resolve([]middleware.PermissionScope{factory7, org3}, productionDashboardQuery{}); !errors.Is(err, errDashboardOutOfScope) {
+		t.Fatalf("ambiguous scopes error = %v, want out of scope", err)
+	}
+	scope, err = resolve([]middleware.PermissionScope{factory7, org3}, productionDashboardQuery{OrganizationID: "3"})
+	if err != nil || scope.OrganizationID != "3" || scope.FactoryID != "" {
+		t.Fatalf("selected organization = %+v, %v", scope, err)
+	}
+}
+
 func TestDashboardActiveBatchesQueryLimitsBeforeTaskAggregation(t *testing.T) {
 	query, args := buildDashboardActiveBatchesQuery(
 		productionDashboardScope{
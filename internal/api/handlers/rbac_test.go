@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupRBACTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE permissions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE roles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			description TEXT,
+			is_system BOOLEAN NOT NULL DEFAULT 0,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE role_permissions (
+			role_id INTEGER NOT NULL,
+			permission_id INTEGER NOT NULL,
+			PRIMARY KEY (role_id, permission_id)
+		)`,
+		`CREATE TABLE user_roles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id TEXT NOT NULL,
+			role_id INTEGER NOT NULL,
+			scope_type TEXT NOT NULL DEFAULT 'global',
+			scope_id INTEGER NULL,
+			created_by TEXT,
+			created_at TIMESTAMP
+		)`,
+		`CREATE TABLE data_collectors (id INTEGER PRIMARY KEY AUTOINCREMENT, operator_id TEXT NOT NULL, deleted_at TIMESTAMP NULL)`,
+		`CREATE TABLE factories (id INTEGER PRIMARY KEY AUTOINCREMENT, deleted_at TIMESTAMP NULL)`,
+		`CREATE TABLE organizations (id INTEGER PRIMARY KEY AUTOINCREMENT, deleted_at TIMESTAMP NULL)`,
+		`INSERT INTO data_collectors (operator_id) VALUES ('op-1')`,
+		`INSERT INTO factories (id) VALUES (5)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("create rbac schema: %v", err)
+		}
+	}
+	return db
+}
+
+func hasGrant(grants []middleware.PermissionGrant, want middleware.PermissionGrant) bool {
+	for _, g := range grants {
+		if g == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRBACRolesAssignmentsAndResolution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupRBACTestDB(t)
+	h := NewRBACHandler(db)
+	ctx := context.Background()
+	if err := h.EnsureSystemRoles(ctx); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := h.EnsureSystemRoles(ctx); err != nil {
+		t.Fatalf("reseed: %v", err)
+	}
+	router := gin.New()
+	h.RegisterRoutes(router.Group("/api/v1"))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	var roles RoleListResponse
+	if rec := do(http.MethodGet, "/api/v1/admin/rbac/roles", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &roles) != nil {
+		t.Fatalf("list roles = %d %s", rec.Code, rec.Body.String())
+	}
+	if roles.Total != 3 {
+		t.Fatalf("system roles = %+v", roles)
+	}
+	adminID := ""
+	for _, r := range roles.Items {
+		if r.Name == "admin" {
+			adminID = r.ID
+		}
+	}
+	if rec := do(http.MethodPut, "/api/v1/admin/rbac/roles/"+adminID, `{"permissions":[]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("edit admin permissions = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/admin/rbac/roles/"+adminID, ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("delete admin = %d, want 400", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/rbac/roles", `{"name":"x","permissions":["order:fly"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown permission = %d, want 400", rec.Code)
+	}
+
+	rec := do(http.MethodPost, "/api/v1/admin/rbac/roles", `{"name":"planner","permissions":["order:write","sync:trigger","order:write"]}`)
+	var planner RoleResponse
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &planner) != nil {
+		t.Fatalf("create role = %d %s", rec.Code, rec.Body.String())
+	}
+	if len(planner.Permissions) != 2 || planner.IsSystem {
+		t.Fatalf("planner = %+v", planner)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/rbac/roles", `{"name":"planner"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate role = %d, want 409", rec.Code)
+	}
+
+	claims := auth.NewCollectorClaims(1, "op-1")
+	grants, err := h.ResolvePermissions(ctx, claims)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if hasGrant(grants, middleware.PermissionGrant{Permission: middleware.PermissionOrderWrite, ScopeType: middleware.PermissionScopeFactory, ScopeID: 5}) ||
+		!hasGrant(grants, middleware.PermissionGrant{Permission: middleware.PermissionTaskCollect, ScopeType: middleware.PermissionScopeGlobal}) {
+		t.Fatalf("default grants = %+v", grants)
+	}
+
+	for body, want := range map[string]int{
+		`{"role_id":` + planner.ID + `,"scope_type":"factory"}`:              http.StatusBadRequest,
+		`{"role_id":` + planner.ID + `,"scope_type":"factory","scope_id":9}`: http.StatusNotFound,
+		`{"role_id":` + adminID + `}`:                                        http.StatusBadRequest,
+	} {
+		if rec := do(http.MethodPost, "/api/v1/admin/rbac/users/op-1/roles", body); rec.Code != want {
+			t.Fatalf("assign %s = %d, want %d", body, rec.Code, want)
+		}
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/rbac/users/ghost/roles", `{"role_id":`+planner.ID+`}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("assign unknown user = %d, want 404", rec.Code)
+	}
+	rec = do(http.MethodPost, "/api/v1/admin/rbac/users/op-1/roles", `{"role_id":`+planner.ID+`,"scope_type":"factory","scope_id":5}`)
+	var assignment RoleAssignmentResponse
+	if rec.Code != http.StatusCreated || json.Unmarshal(rec.Body.Bytes(), &assignment) != nil {
+		t.Fatalf("assign = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/rbac/users/op-1/roles", `{"role_id":`+planner.ID+`,"scope_type":"factory","scope_id":5}`); rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate assignment = %d, want 409", rec.Code)
+	}
+
+	grants, err = h.ResolvePermissions(ctx, claims)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if !hasGrant(grants, middleware.PermissionGrant{Permission: middleware.PermissionOrderWrite, ScopeType: middleware.PermissionScopeFactory, ScopeID: 5}) {
+		t.Fatalf("scoped grants = %+v", grants)
+	}
+
+	var assigned RoleAssignmentListResponse
+	if rec := do(http.MethodGet, "/api/v1/admin/rbac/users/op-1/roles", ""); rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &assigned) != nil {
+		t.Fatalf("list assignments = %d %s", rec.Code, rec.Body.String())
+	}
+	if len(assigned.Items) != 1 || assigned.Items[0].RoleName != "planner" || *assigned.Items[0].ScopeID != 5 {
+		t.Fatalf("assignments = %+v", assigned)
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/rbac/roles/"+planner.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete role = %d", rec.Code)
+	}
+	grants, err = h.ResolvePermissions(ctx, claims)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if hasGrant(grants, middleware.PermissionGrant{Permission: middleware.PermissionOrderWrite, ScopeType: middleware.PermissionScopeFactory, ScopeID: 5}) {
+		t.Fatalf("grants after role delete = %+v", grants)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/admin/rbac/users/op-1/roles/"+assignment.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("unassign removed = %d, want 404", rec.Code)
+	}
+}
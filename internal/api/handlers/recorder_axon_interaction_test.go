@@ -1061,6 +1061,7 @@ func newRecorderInteractionDB(t *testing.T) *sqlx.DB {
 	)`); err != nil {
 		t.Fatalf("create tasks schema: %v", err)
 	}
+	createStateTransitionsTestTable(t, db)
 	return db
 }
 
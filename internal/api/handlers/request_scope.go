@@ -0,0 +1,157 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// RequestScopes resolves the organization and factory of the entity a write
+// targets, named by its path or JSON body, so organization- and
+// factory-scoped grants apply to it.
+// Each method is a middleware.RequestScopeFunc; unknown entities resolve to an
+// empty scope and are left to the handler to reject.
+type RequestScopes struct {
+	db *sqlx.DB
+}
+
+// NewRequestScopes creates a RequestScopes.
+func NewRequestScopes(db *sqlx.DB) *RequestScopes {
+	return &RequestScopes{db: db}
+}
+
+// OrderBody resolves the organization_id in the body of POST /orders.
+func (r *RequestScopes) OrderBody(c *gin.Context) (middleware.RequestScope, error) {
+	var body struct {
+		OrganizationID json.RawMessage `json:"organization_id"`
+	}
+	if err := peekJSONBody(c, &body); err != nil {
+		return middleware.RequestScope{}, err
+	}
+	return r.organizationScope(c, parseScopeID(string(body.OrganizationID)))
+}
+
+// Order resolves the order named by the :id path parameter.
+func (r *RequestScopes) Order(c *gin.Context) (middleware.RequestScope, error) {
+	id := parseScopeID(c.Param("id"))
+	if id == 0 {
+		return middleware.RequestScope{}, nil
+	}
+	var scope struct {
+		OrganizationID int64 `db:"organization_id"`
+		FactoryID      int64 `db:"factory_id"`
+	}
+	err := r.db.GetContext(c.Request.Context(), &scope, `
+		SELECT o.organization_id, COALESCE(org.factory_id, 0) AS factory_id
+		FROM orders o
+		LEFT JOIN organizations org ON org.id = o.organization_id
+		WHERE o.id = ? AND o.deleted_at IS NULL
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return middleware.RequestScope{}, nil
+	}
+	if err != nil {
+		return middleware.RequestScope{}, fmt.Errorf("resolve scope of order %d: %w", id, err)
+	}
+	return middleware.RequestScope{OrganizationID: scope.OrganizationID, FactoryID: scope.FactoryID}, nil
+}
+
+// Episode resolves the episode named by the :id path parameter.
+func (r *RequestScopes) Episode(c *gin.Context) (middleware.RequestScope, error) {
+	return r.episodeScope(c, parseScopeID(c.Param("id")))
+}
+
+// LeaseEpisode resolves the episode named by the :episode_id path parameter of
+// the inspection lease routes.
+func (r *RequestScopes) LeaseEpisode(c *gin.Context) (middleware.RequestScope, error) {
+	return r.episodeScope(c, parseScopeID(c.Param("episode_id")))
+}
+
+// EpisodeBody resolves the episode_id in the JSON body, as sent to
+// POST /qa/inspections.
+func (r *RequestScopes) EpisodeBody(c *gin.Context) (middleware.RequestScope, error) {
+	var body struct {
+		EpisodeID json.RawMessage `json:"episode_id"`
+	}
+	if err := peekJSONBody(c, &body); err != nil {
+		return middleware.RequestScope{}, err
+	}
+	return r.episodeScope(c, parseScopeID(string(body.EpisodeID)))
+}
+
+func (r *RequestScopes) organizationScope(c *gin.Context, id int64) (middleware.RequestScope, error) {
+	if id == 0 {
+		return middleware.RequestScope{}, nil
+	}
+	var factoryID int64
+	err := r.db.GetContext(c.Request.Context(), &factoryID, "SELECT factory_id FROM organizations WHERE id = ? AND deleted_at IS NULL", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return middleware.RequestScope{}, nil
+	}
+	if err != nil {
+		return middleware.RequestScope{}, fmt.Errorf("resolve scope of organization %d: %w", id, err)
+	}
+	return middleware.RequestScope{OrganizationID: id, FactoryID: factoryID}, nil
+}
+
+func (r *RequestScopes) episodeScope(c *gin.Context, id int64) (middleware.RequestScope, error) {
+	if id == 0 {
+		return middleware.RequestScope{}, nil
+	}
+	var scope struct {
+		OrganizationID int64 `db:"organization_id"`
+		FactoryID      int64 `db:"factory_id"`
+	}
+	err := r.db.GetContext(c.Request.Context(), &scope, `
+		SELECT COALESCE(organization_id, 0) AS organization_id, COALESCE(factory_id, 0) AS factory_id
+		FROM episodes
+		WHERE id = ? AND deleted_at IS NULL
+	`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return middleware.RequestScope{}, nil
+	}
+	if err != nil {
+		return middleware.RequestScope{}, fmt.Errorf("resolve scope of episode %d: %w", id, err)
+	}
+	return middleware.RequestScope{OrganizationID: scope.OrganizationID, FactoryID: scope.FactoryID}, nil
+}
+
+// peekJSONBody decodes the request body into v and puts the bytes back for
+// the handler. A body that is not a JSON object leaves v unset; the handler
+// reports it.
+func peekJSONBody(c *gin.Context, v any) error {
+	if c.Request.Body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return fmt.Errorf("read request body: %w", err)
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+	_ = json.Unmarshal(data, v)
+	return nil
+}
+
+// parseScopeID accepts an ID from the path or sent as a JSON number or
+// string, returning 0 when it is missing or invalid.
+func parseScopeID(raw string) int64 {
+	s := strings.Trim(strings.TrimSpace(raw), `"`)
+	id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Entity types recorded in state_transitions.
+const (
+	stateEntityTask        = "task"
+	stateEntityEpisode     = "episode"
+	stateEntityBatch       = "batch"
+	stateEntityOrder       = "order"
+	stateEntityWorkstation = "workstation"
+)
+
+// Values of state_transitions.triggered_by.
+const (
+	transitionTriggerUser   = "user"
+	transitionTriggerAPI    = "api"
+	transitionTriggerAxon   = "axon_callback"
+	transitionTriggerSystem = "system"
+)
+
+// stateEntityColumns maps an entity type to the table and column holding its state.
+var stateEntityColumns = map[string]struct{ table, column string }{
+	stateEntityTask:        {"tasks", "status"},
+	stateEntityEpisode:     {"episodes", "qa_status"},
+	stateEntityBatch:       {"batches", "status"},
+	stateEntityOrder:       {"orders", "status"},
+	stateEntityWorkstation: {"workstations", "status"},
+}
+
+// stateTransitionActor identifies who caused a transition.
+type stateTransitionActor struct {
+	TriggeredBy   string
+	TriggeredByID string
+}
+
+// requestTransitionActor attributes a transition to the JWT identity of the
+// request when present, otherwise to the anonymous API caller.
+func requestTransitionActor(c *gin.Context) stateTransitionActor {
+	if claims := middleware.GetClaims(c); claims != nil {
+		id := strings.TrimSpace(claims.OperatorID)
+		if id == "" {
+			id = claims.Role
+		}
+		return stateTransitionActor{TriggeredBy: transitionTriggerUser, TriggeredByID: id}
+	}
+	return stateTransitionActor{TriggeredBy: transitionTriggerAPI, TriggeredByID: c.ClientIP()}
+}
+
+// inspectorTransitionActor attributes a transition to an inspector.
+func inspectorTransitionActor(inspectorID int64) stateTransitionActor {
+	return stateTransitionActor{TriggeredBy: transitionTriggerUser, TriggeredByID: fmt.Sprintf("inspector:%d", inspectorID)}
+}
+
+// axonTransitionActor attributes a transition to an Axon device callback or RPC.
+func axonTransitionActor(deviceID string) stateTransitionActor {
+	return stateTransitionActor{TriggeredBy: transitionTriggerAxon, TriggeredByID: strings.TrimSpace(deviceID)}
+}
+
+// systemTransitionActor attributes a transition to a background process.
+func systemTransitionActor(process string) stateTransitionActor {
+	return stateTransitionActor{TriggeredBy: transitionTriggerSystem, TriggeredByID: process}
+}
+
+// stateRow is the locked state of one entity row.
+type stateRow struct {
+	ID    int64  `db:"id"`
+	State string `db:"state"`
+}
+
+// lockStateRows locks the rows of entity matching where and returns their
+// current state. Callers run their UPDATE with the same filter afterwards so
+// the locked rows are exactly the updated rows.
+func lockStateRows(tx *sqlx.Tx, entity, where string, args ...any) ([]stateRow, error) {
+	cols, ok := stateEntityColumns[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown state entity %q", entity)
+	}
+	var rows []stateRow
+	// #nosec G202 -- table and column come from stateEntityColumns; where is a static filter.
+	query := "SELECT id, COALESCE(" + cols.column + ", '') AS state FROM " + cols.table + " WHERE " + where + forUpdateClause(tx)
+	if err := tx.Select(&rows, query, args...); err != nil {
+		return nil, fmt.Errorf("lock %s states: %w", entity, err)
+	}
+	return rows, nil
+}
+
+// recordStateTransitions writes one state_transitions row per locked row that
+// moved to toState. Rows already in toState are skipped.
+func recordStateTransitions(tx *sqlx.Tx, entity string, rows []stateRow, toState string, actor stateTransitionActor, metadata map[string]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	metadataJSON := sql.NullString{}
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("marshal transition metadata: %w", err)
+		}
+		metadataJSON = sql.NullString{String: string(encoded), Valid: true}
+	}
+	now := time.Now().UTC()
+	for _, row := range rows {
+		if row.State == toState {
+			continue
+		}
+		// #nosec G701 -- static SQL with placeholder-bound transition values.
+		if _, err := tx.Exec(`
+			INSERT INTO state_transitions (entity_type, entity_id, from_state, to_state, triggered_by, triggered_by_id, transition_metadata, occurred_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, entity, row.ID, sql.NullString{String: row.State, Valid: row.State != ""}, toState,
+			actor.TriggeredBy, sql.NullString{String: actor.TriggeredByID, Valid: actor.TriggeredByID != ""},
+			metadataJSON, now); err != nil {
+			return fmt.Errorf("insert %s %d transition: %w", entity, row.ID, err)
+		}
+	}
+	return nil
+}
+
+// transitionStateRows locks the rows of entity matching where, runs update
+// and records a transition for each locked row. update must filter with the
+// same where clause; it returns the number of affected rows.
+func transitionStateRows(tx *sqlx.Tx, entity, toState string, actor stateTransitionActor, metadata map[string]any, where string, whereArgs []any, update func() (int64, error)) (int64, error) {
+	rows, err := lockStateRows(tx, entity, where, whereArgs...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := update()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, nil
+	}
+	if err := recordStateTransitions(tx, entity, rows, toState, actor, metadata); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// execAffected adapts a tx.Exec call to transitionStateRows.
+func execAffected(tx *sqlx.Tx, query string, args ...any) func() (int64, error) {
+	return func() (int64, error) {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+}
+
+// execStateTransition runs "UPDATE <table> SET set WHERE where" for entity in
+// its own transaction and records the transitions alongside it. It returns
+// the number of updated rows.
+func execStateTransition(ctx context.Context, db *sqlx.DB, entity, toState string, actor stateTransitionActor, metadata map[string]any, set string, setArgs []any, where string, whereArgs ...any) (int64, error) {
+	cols, ok := stateEntityColumns[entity]
+	if !ok {
+		return 0, fmt.Errorf("unknown state entity %q", entity)
+	}
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	// #nosec G202 -- table comes from stateEntityColumns; set and where are static SQL.
+	query := "UPDATE " + cols.table + " SET " + set + " WHERE " + where
+	affected, err := transitionStateRows(tx, entity, toState, actor, metadata, where, whereArgs,
+		execAffected(tx, query, append(append([]any{}, setArgs...), whereArgs...)...))
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
+// StateTransitionResponse is one recorded state change.
+type StateTransitionResponse struct {
+	ID            int64  `json:"id"`
+	EntityType    string `json:"entity_type"`
+	EntityID      int64  `json:"entity_id"`
+	FromState     string `json:"from_state,omitempty"`
+	ToState       string `json:"to_state"`
+	TriggeredBy   string `json:"triggered_by"`
+	TriggeredByID string `json:"triggered_by_id,omitempty"`
+	Metadata      any    `json:"metadata,omitempty"`
+	OccurredAt    string `json:"occurred_at"`
+}
+
+// StateTransitionListResponse is the history response for one entity.
+type StateTransitionListResponse struct {
+	Items   []StateTransitionResponse `json:"items"`
+	Total   int                       `json:"total"`
+	Limit   int                       `json:"limit"`
+	Offset  int                       `json:"offset"`
+	HasNext bool                      `json:"hasNext,omitempty"`
+	HasPrev bool                      `json:"hasPrev,omitempty"`
+}
+
+type stateTransitionRow struct {
+	ID            int64          `db:"id"`
+	EntityType    string         `db:"entity_type"`
+	EntityID      int64          `db:"entity_id"`
+	FromState     sql.NullString `db:"from_state"`
+	ToState       string         `db:"to_state"`
+	TriggeredBy   string         `db:"triggered_by"`
+	TriggeredByID sql.NullString `db:"triggered_by_id"`
+	Metadata      sql.NullString `db:"transition_metadata"`
+	OccurredAt    time.Time      `db:"occurred_at"`
+}
+
+// StateTransitionHandler serves entity state history.
+type StateTransitionHandler struct {
+	db *sqlx.DB
+}
+
+// NewStateTransitionHandler creates a new StateTransitionHandler.
+func NewStateTransitionHandler(db *sqlx.DB) *StateTransitionHandler {
+	return &StateTransitionHandler{db: db}
+}
+
+// RegisterRoutes registers GET /{entity}/{id}/history for every recorded entity.
+func (h *StateTransitionHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/tasks/:id/history", h.history(stateEntityTask))
+	apiV1.GET("/episodes/:id/history", h.history(stateEntityEpisode))
+	apiV1.GET("/batches/:id/history", h.history(stateEntityBatch))
+	apiV1.GET("/orders/:id/history", h.history(stateEntityOrder))
+	apiV1.GET("/stations/:id/history", h.history(stateEntityWorkstation))
+}
+
+func (h *StateTransitionHandler) history(entity string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h.ListStateTransitions(c, entity)
+	}
+}
+
+// ListStateTransitions returns the state history of one entity, oldest first.
+//
+// @Summary      Entity state history
+// @Description  Lists recorded state transitions with who triggered them. entity is one of tasks, episodes, batches, orders or stations.
+// @Tags         history
+// @Produce      json
+// @Param        id     path  int true  "Entity ID"
+// @Param        limit  query int false "Max results (default 50, max 100)"
+// @Param        offset query int false "Pagination offset (default 0)"
+// @Success      200 {object} StateTransitionListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /tasks/{id}/history [get]
+// @Router       /episodes/{id}/history [get]
+// @Router       /batches/{id}/history [get]
+// @Router       /orders/{id}/history [get]
+// @Router       /stations/{id}/history [get]
+func (h *StateTransitionHandler) ListStateTransitions(c *gin.Context, entity string) {
+	entityID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || entityID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entity + " id"})
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(1) FROM state_transitions WHERE entity_type = ? AND entity_id = ?", entity, entityID); err != nil {
+		logger.Printf("[HISTORY] Failed to count %s %d transitions: %v", entity, entityID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list state history"})
+		return
+	}
+	var rows []stateTransitionRow
+	if err := h.db.Select(&rows, `
+		SELECT id, entity_type, entity_id, from_state, to_state, triggered_by, triggered_by_id, transition_metadata, occurred_at
+		FROM state_transitions
+		WHERE entity_type = ? AND entity_id = ?
+		ORDER BY occurred_at ASC, id ASC
+		LIMIT ? OFFSET ?
+	`, entity, entityID, pagination.Limit, pagination.Offset); err != nil {
+		logger.Printf("[HISTORY] Failed to query %s %d transitions: %v", entity, entityID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list state history"})
+		return
+	}
+
+	items := make([]StateTransitionResponse, 0, len(rows))
+	for _, row := range rows {
+		item := StateTransitionResponse{
+			ID:            row.ID,
+			EntityType:    row.EntityType,
+			EntityID:      row.EntityID,
+			FromState:     row.FromState.String,
+			ToState:       row.ToState,
+			TriggeredBy:   row.TriggeredBy,
+			TriggeredByID: row.TriggeredByID.String,
+			OccurredAt:    row.OccurredAt.UTC().Format(time.RFC3339),
+		}
+		if row.Metadata.Valid {
+			item.Metadata = parseJSONRaw(row.Metadata.String)
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, StateTransitionListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
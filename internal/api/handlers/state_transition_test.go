@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func createStateTransitionsTestTable(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS state_transitions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_type TEXT NOT NULL,
+		entity_id INTEGER NOT NULL,
+		from_state TEXT,
+		to_state TEXT NOT NULL,
+		triggered_by TEXT NOT NULL,
+		triggered_by_id TEXT,
+		transition_metadata TEXT,
+		occurred_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		t.Fatalf("create state_transitions schema: %v", err)
+	}
+}
+
+func TestStateTransitionHistoryRecordsCompletedTasks(t *testing.T) {
+	db := newTestBatchHandlerDB(t)
+	defer db.Close()
+	seedBatchCompleteNextFixtures(t, db)
+
+	r := newTestCollectorBatchRouter(t, db, auth.NewCollectorClaims(100, "op-100"))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/batches/1/complete-tasks", bytes.NewBufferString(`{"quantity":1,"sop_id":40,"subscene_id":50}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("complete status=%d body=%s", w.Code, w.Body.String())
+	}
+
+	history := gin.New()
+	NewStateTransitionHandler(db).RegisterRoutes(history.Group("/api/v1"))
+	get := func(path string) (int, StateTransitionListResponse) {
+		rec := httptest.NewRecorder()
+		history.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var resp StateTransitionListResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode %s: %v", path, err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	code, tasks := get("/api/v1/tasks/1/history")
+	if code != http.StatusOK || tasks.Total != 1 {
+		t.Fatalf("task history = %d %+v", code, tasks)
+	}
+	got := tasks.Items[0]
+	if got.FromState != "pending" || got.ToState != "completed" || got.TriggeredBy != transitionTriggerUser || got.TriggeredByID != "op-100" {
+		t.Fatalf("task transition = %+v", got)
+	}
+	if meta, ok := got.Metadata.(map[string]any); !ok || meta["reason"] != "batch_complete_tasks" {
+		t.Fatalf("task transition metadata = %#v", got.Metadata)
+	}
+
+	if code, batches := get("/api/v1/batches/1/history"); code != http.StatusOK || batches.Total != 1 || batches.Items[0].ToState != "active" {
+		t.Fatalf("batch history = %d %+v", code, batches)
+	}
+	if code, other := get("/api/v1/tasks/3/history"); code != http.StatusOK || other.Total != 0 || len(other.Items) != 0 {
+		t.Fatalf("untouched task history = %d %+v", code, other)
+	}
+	if code, _ := get("/api/v1/orders/abc/history"); code != http.StatusBadRequest {
+		t.Fatalf("invalid id = %d, want 400", code)
+	}
+}
+
+func TestRecordStateTransitionsSkipsUnchangedRows(t *testing.T) {
+	db := newTestBatchHandlerDB(t)
+	defer db.Close()
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows := []stateRow{{ID: 1, State: "active"}, {ID: 2, State: "offline"}}
+	if err := recordStateTransitions(tx, stateEntityWorkstation, rows, "offline", systemTransitionActor("test"), nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	var recorded []stateTransitionRow
+	if err := tx.Select(&recorded, "SELECT * FROM state_transitions"); err != nil {
+		t.Fatalf("query transitions: %v", err)
+	}
+	if len(recorded) != 1 || recorded[0].EntityID != 1 || recorded[0].FromState.String != "active" || recorded[0].TriggeredByID.String != "test" || recorded[0].Metadata.Valid {
+		t.Fatalf("transitions = %+v", recorded)
+	}
+}
@@ -341,7 +341,8 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 	`, robotInfo.ID, dcInfo.ID)
 	switch err {
 	case nil:
-		if _, err := tx.Exec(`
+		if _, err := transitionStateRows(tx, stateEntityWorkstation, "offline", requestTransitionActor(c), map[string]any{"reason": "station_rebound"},
+			"id = ? AND is_current = FALSE AND deleted_at IS NULL", []any{stationID}, execAffected(tx, `
 			UPDATE workstations
 			SET
 				robot_name = ?,
@@ -358,17 +359,17 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 				updated_at = ?
 			WHERE id = ? AND is_current = FALSE AND deleted_at IS NULL
 		`,
-			robotType.Name,
-			robotInfo.DeviceID,
-			dcInfo.Name,
-			dcInfo.OperatorID,
-			robotInfo.FactoryID,
-			dcInfo.OrganizationID,
-			"offline",
-			metadataStr,
-			now,
-			stationID,
-		); err != nil {
+				robotType.Name,
+				robotInfo.DeviceID,
+				dcInfo.Name,
+				dcInfo.OperatorID,
+				robotInfo.FactoryID,
+				dcInfo.OrganizationID,
+				"offline",
+				metadataStr,
+				now,
+				stationID,
+			)); err != nil {
 			logger.Printf("[STATION] Failed to reactivate workstation: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create station"})
 			return
@@ -1151,6 +1152,7 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 		}
 	}()
 
+	actor := requestTransitionActor(c)
 	responseID := stationID
 	if bindingChanged {
 		if _, err := tx.Exec(`
@@ -1176,7 +1178,8 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 		`, effectiveRobotID, effectiveDCID)
 		switch err {
 		case nil:
-			if _, err := tx.Exec(`
+			if _, err := transitionStateRows(tx, stateEntityWorkstation, effectiveStatus, actor, map[string]any{"reason": "station_rebound"},
+				"id = ? AND is_current = FALSE AND deleted_at IS NULL", []any{reusedID}, execAffected(tx, `
 				UPDATE workstations
 				SET
 					robot_name = ?,
@@ -1193,17 +1196,17 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 					updated_at = ?
 				WHERE id = ? AND is_current = FALSE AND deleted_at IS NULL
 			`,
-				effectiveRobotName,
-				effectiveRobotSerial,
-				effectiveCollectorName,
-				effectiveCollectorOperatorID,
-				effectiveFactoryID,
-				effectiveOrganizationID,
-				effectiveStatus,
-				effectiveMetadata,
-				now,
-				reusedID,
-			); err != nil {
+					effectiveRobotName,
+					effectiveRobotSerial,
+					effectiveCollectorName,
+					effectiveCollectorOperatorID,
+					effectiveFactoryID,
+					effectiveOrganizationID,
+					effectiveStatus,
+					effectiveMetadata,
+					now,
+					reusedID,
+				)); err != nil {
 				logger.Printf("[STATION] Failed to reactivate station binding: %v", err)
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update station"})
 				return
@@ -1271,11 +1274,12 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 			return
 		}
 	} else {
-		if _, err := tx.Exec(`
+		if _, err := transitionStateRows(tx, stateEntityWorkstation, effectiveStatus, actor, nil,
+			"id = ? AND is_current = TRUE AND deleted_at IS NULL", []any{stationID}, execAffected(tx, `
 			UPDATE workstations
 			SET status = ?, metadata = ?, updated_at = ?
 			WHERE id = ? AND is_current = TRUE AND deleted_at IS NULL
-		`, effectiveStatus, effectiveMetadata, now, stationID); err != nil {
+		`, effectiveStatus, effectiveMetadata, now, stationID)); err != nil {
 			logger.Printf("[STATION] Failed to update station: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update station"})
 			return
@@ -527,5 +527,6 @@ func newTestStationHandlerDB(t *testing.T) *sqlx.DB {
 		}
 	}
 
+	createStateTransitionsTestTable(t, db)
 	return db
 }
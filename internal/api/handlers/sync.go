@@ -9,11 +9,13 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"archebase.com/keystone-edge/internal/config"
 	"archebase.com/keystone-edge/internal/logger"
 	"archebase.com/keystone-edge/internal/services"
 	"github.com/gin-gonic/gin"
@@ -35,6 +37,7 @@ func NewSyncHandler(db *sqlx.DB, syncWorker *services.SyncWorker) *SyncHandler {
 func (h *SyncHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
 	apiV1.POST("/sync/episodes", h.TriggerBatchSync)
 	apiV1.POST("/sync/episodes/:id/resync", h.TriggerEpisodeResync)
+	apiV1.POST("/sync/episodes/:id/priority", h.SetEpisodeSyncPriority)
 	apiV1.POST("/sync/episodes/:id", h.TriggerEpisodeSync)
 	apiV1.GET("/sync/episodes", h.ListSyncJobs)
 	apiV1.GET("/sync/episodes/summary", h.ListEpisodeSyncSummaries)
@@ -42,6 +45,11 @@ func (h *SyncHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
 	apiV1.GET("/sync/episodes/:id/logs", h.ListEpisodeSyncLogs)
 	apiV1.GET("/sync/episodes/:id/status", h.GetSyncStatus)
 	apiV1.GET("/sync/config", h.GetSyncConfig)
+	apiV1.PUT("/sync/config", h.UpdateSyncConfig)
+	apiV1.GET("/sync/control", h.GetSyncControl)
+	apiV1.POST("/sync/control/pause", h.PauseSync)
+	apiV1.POST("/sync/control/resume", h.ResumeSync)
+	apiV1.POST("/sync/control/drain", h.DrainSync)
 }
 
 type syncEpisodeActionRow struct {
@@ -214,6 +222,39 @@ type SyncJobResponse struct {
 	NextRetryAt      *string `json:"next_retry_at,omitempty"`
 	StartedAt        *string `json:"started_at,omitempty"`
 	CompletedAt      *string `json:"completed_at,omitempty"`
+	// Objects is the per-object status of the episode bundle (MCAP, sidecar,
+	// attachments and manifest) uploaded by this sync.
+	Objects []SyncObjectResponse `json:"objects,omitempty"`
+}
+
+// SyncObjectResponse represents one object of a sync bundle.
+type SyncObjectResponse struct {
+	Role            string  `json:"role"`
+	Name            string  `json:"name"`
+	SourcePath      string  `json:"source_path"`
+	DestinationPath *string `json:"destination_path,omitempty"`
+	SizeBytes       *int64  `json:"size_bytes,omitempty"`
+	SHA256          *string `json:"sha256,omitempty"`
+	ETag            *string `json:"etag,omitempty"`
+	Status          string  `json:"status"`
+	ErrorMessage    *string `json:"error_message,omitempty"`
+	StartedAt       *string `json:"started_at,omitempty"`
+	CompletedAt     *string `json:"completed_at,omitempty"`
+}
+
+type syncLogObjectRow struct {
+	SyncLogID       int64          `db:"sync_log_id"`
+	Role            string         `db:"role"`
+	Name            string         `db:"name"`
+	SourcePath      string         `db:"source_path"`
+	DestinationPath sql.NullString `db:"destination_path"`
+	SizeBytes       sql.NullInt64  `db:"size_bytes"`
+	SHA256          sql.NullString `db:"sha256"`
+	ETag            sql.NullString `db:"etag"`
+	Status          string         `db:"status"`
+	ErrorMessage    sql.NullString `db:"error_message"`
+	StartedAt       sql.NullTime   `db:"started_at"`
+	CompletedAt     sql.NullTime   `db:"completed_at"`
 }
 
 // SyncProgressResponse represents runtime-only upload progress for an in-progress sync.
@@ -231,6 +272,10 @@ type EpisodeSyncStatusResponse struct {
 	CloudSyncedAt  *string               `json:"cloud_synced_at,omitempty"`
 	CloudProcessed bool                  `json:"cloud_processed"`
 	Progress       *SyncProgressResponse `json:"progress,omitempty"`
+	// QueuePosition is the 1-based dispatch position of a pending sync.
+	QueuePosition *int `json:"queue_position,omitempty"`
+	// SyncPriority is the dispatch priority score of a pending sync.
+	SyncPriority *int `json:"sync_priority,omitempty"`
 }
 
 // EpisodeSyncStatusError describes one missing or invalid episode in a batch status request.
@@ -483,6 +528,11 @@ func (h *SyncHandler) ListSyncJobs(c *gin.Context) {
 	for i, r := range rows {
 		items[i] = syncJobResponseFromRow(r)
 	}
+	if err := h.attachSyncLogObjects(c.Request.Context(), items); err != nil {
+		logger.Printf("[SYNC] Failed to query sync log objects: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sync jobs"})
+		return
+	}
 
 	hasNext := (pagination.Offset + pagination.Limit) < total
 	hasPrev := pagination.Offset > 0
@@ -670,6 +720,11 @@ func (h *SyncHandler) ListEpisodeSyncLogs(c *gin.Context) {
 	for i, r := range rows {
 		items[i] = syncJobResponseFromRow(r)
 	}
+	if err := h.attachSyncLogObjects(c.Request.Context(), items); err != nil {
+		logger.Printf("[SYNC] Failed to query sync log objects for episode %d: %v", episodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sync logs"})
+		return
+	}
 
 	c.JSON(http.StatusOK, SyncJobListResponse{
 		Items:   items,
@@ -749,6 +804,97 @@ func (h *SyncHandler) GetSyncStatus(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "episode not found"})
 		return
 	}
+	h.attachSyncQueuePosition(c.Request.Context(), &status)
+
+	c.JSON(http.StatusOK, status)
+}
+
+func (h *SyncHandler) attachSyncQueuePosition(ctx context.Context, resp *EpisodeSyncStatusResponse) {
+	if h.syncWorker == nil || resp.Status != "pending" {
+		return
+	}
+	position, priority, ok, err := h.syncWorker.SyncQueuePosition(ctx, resp.EpisodeID)
+	if err != nil {
+		logger.Printf("[SYNC] Failed to compute queue position for episode %d: %v", resp.EpisodeID, err)
+		return
+	}
+	if !ok {
+		return
+	}
+	resp.QueuePosition = &position
+	resp.SyncPriority = &priority
+}
+
+// SetEpisodeSyncPriorityRequest sets or clears an episode's sync priority override.
+type SetEpisodeSyncPriorityRequest struct {
+	// Priority replaces the computed priority score; null clears the override.
+	Priority *int `json:"priority"`
+}
+
+// SetEpisodeSyncPriority overrides the sync dispatch priority of an episode.
+//
+// @Summary      Override episode sync priority
+// @Description  Replaces the computed sync priority (order priority, deadline, manual trigger, age) of an episode with a fixed score from 0 to 1000; 1000 always dispatches first. A null priority restores the computed priority.
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        id    path      int                            true  "Episode ID"
+// @Param        body  body      SetEpisodeSyncPriorityRequest  true  "Priority override"
+// @Success      200   {object}  EpisodeSyncStatusResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /sync/episodes/{id}/priority [post]
+func (h *SyncHandler) SetEpisodeSyncPriority(c *gin.Context) {
+	episodeID, ok := parseEpisodeIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req SetEpisodeSyncPriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if req.Priority != nil && (*req.Priority < 0 || *req.Priority > services.MaxSyncPriorityOverride) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("priority must be between 0 and %d", services.MaxSyncPriorityOverride)})
+		return
+	}
+
+	var override interface{}
+	if req.Priority != nil {
+		override = *req.Priority
+	}
+	// #nosec G701 -- static SQL with placeholder-bound values.
+	res, err := h.db.ExecContext(c.Request.Context(), `
+		UPDATE episodes
+		SET sync_priority_override = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, override, episodeID)
+	if err != nil {
+		logger.Printf("[SYNC] Failed to set sync priority for episode %d: %v", episodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set sync priority"})
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "episode not found"})
+		return
+	}
+	logger.Printf("[SYNC] Episode %d sync priority override set to %v", episodeID, override)
+	h.syncWorker.InvalidateSyncQueue()
+
+	statuses, err := h.loadEpisodeSyncStatuses(c.Request.Context(), []int64{episodeID})
+	if err != nil {
+		logger.Printf("[SYNC] Failed to query episode %d for sync status: %v", episodeID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get sync status"})
+		return
+	}
+	status, ok := statuses[episodeID]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "episode not found"})
+		return
+	}
+	h.attachSyncQueuePosition(c.Request.Context(), &status)
 
 	c.JSON(http.StatusOK, status)
 }
@@ -913,7 +1059,7 @@ func (h *SyncHandler) attachSyncProgress(resp *EpisodeSyncStatusResponse) {
 // GetSyncConfig returns the current sync configuration (sanitized).
 //
 // @Summary      Get sync configuration
-// @Description  Returns current cloud sync configuration (secrets redacted)
+// @Description  Returns current cloud sync configuration (secrets redacted), including bandwidth throttling and sync windows
 // @Tags         sync
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
@@ -922,18 +1068,265 @@ func (h *SyncHandler) GetSyncConfig(c *gin.Context) {
 	workerRunning := false
 	autoScanEnabled := false
 	maxRetries := 0
+	throttle := services.SyncThrottle{Schedule: []config.SyncWindow{}}
+	windowOpen := true
+	var currentLimit int64
+	control := services.SyncControlState{Mode: services.SyncModeRunning}
+	destination := config.SyncDestinationDataPlatform
 	if h.syncWorker != nil {
+		control = h.syncWorker.SyncControl()
+		destination = h.syncWorker.Destination()
 		workerRunning = h.syncWorker.IsRunning()
 		autoScanEnabled = h.syncWorker.AutoScanEnabled()
 		maxRetries = h.syncWorker.MaxRetries()
+		throttle = h.syncWorker.SyncThrottle()
+		if throttle.Schedule == nil {
+			throttle.Schedule = []config.SyncWindow{}
+		}
+		windowOpen, currentLimit = h.syncWorker.SyncWindowStatus(time.Now())
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"worker_running":    workerRunning,
-		"auto_scan_enabled": autoScanEnabled,
-		"max_retries":       maxRetries,
+		"worker_running":               workerRunning,
+		"auto_scan_enabled":            autoScanEnabled,
+		"max_retries":                  maxRetries,
+		"bandwidth_limit_kbps":         throttle.BandwidthLimitKBps,
+		"schedule":                     throttle.Schedule,
+		"sync_window_open":             windowOpen,
+		"current_bandwidth_limit_kbps": currentLimit,
+		"mode":                         control.Mode,
+		"in_flight_uploads":            control.InFlightUploads,
+		"destination":                  destination,
 	})
 }
 
+// UpdateSyncConfigRequest changes runtime sync throttling. Omitted fields keep
+// their current value; an empty schedule allows sync at any time.
+type UpdateSyncConfigRequest struct {
+	BandwidthLimitKBps *int64               `json:"bandwidth_limit_kbps"`
+	Schedule           *[]config.SyncWindow `json:"schedule"`
+}
+
+// UpdateSyncConfig changes the upload bandwidth limit and sync windows at runtime.
+//
+// @Summary      Update sync configuration
+// @Description  Updates the global upload bandwidth limit (KiB/s, 0 = unlimited, otherwise >= 128) and the daily sync windows. Takes effect for running uploads without a restart; not persisted across restarts.
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body      UpdateSyncConfigRequest  true  "Throttle settings"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /sync/config [put]
+func (h *SyncHandler) UpdateSyncConfig(c *gin.Context) {
+	if h.syncWorker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "sync worker is not configured"})
+		return
+	}
+
+	var req UpdateSyncConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+
+	throttle := h.syncWorker.SyncThrottle()
+	if req.BandwidthLimitKBps != nil {
+		throttle.BandwidthLimitKBps = *req.BandwidthLimitKBps
+	}
+	if req.Schedule != nil {
+		throttle.Schedule = *req.Schedule
+	}
+	if err := h.syncWorker.SetSyncThrottle(throttle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.GetSyncConfig(c)
+}
+
+const maxSyncControlReasonLength = 255
+
+// SyncControlRequest optionally records why the sync mode was changed.
+type SyncControlRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SyncControlResponse describes the sync worker control mode.
+type SyncControlResponse struct {
+	Mode            string  `json:"mode"`
+	Reason          *string `json:"reason,omitempty"`
+	UpdatedBy       *string `json:"updated_by,omitempty"`
+	UpdatedAt       *string `json:"updated_at,omitempty"`
+	InFlightUploads int64   `json:"in_flight_uploads"`
+	Drained         bool    `json:"drained"`
+}
+
+func syncControlResponse(state services.SyncControlState) SyncControlResponse {
+	resp := SyncControlResponse{
+		Mode:            state.Mode,
+		InFlightUploads: state.InFlightUploads,
+		Drained:         state.Drained(),
+	}
+	if state.Reason != "" {
+		resp.Reason = &state.Reason
+	}
+	if state.UpdatedBy != "" {
+		resp.UpdatedBy = &state.UpdatedBy
+	}
+	if !state.UpdatedAt.IsZero() {
+		updatedAt := state.UpdatedAt.UTC().Format(time.RFC3339)
+		resp.UpdatedAt = &updatedAt
+	}
+	return resp
+}
+
+// GetSyncControl returns the sync worker control mode.
+//
+// @Summary      Get sync control mode
+// @Description  Returns whether the sync worker is running, paused or draining, and how many uploads are in flight
+// @Tags         sync
+// @Produce      json
+// @Success      200  {object}  SyncControlResponse
+// @Failure      503  {object}  map[string]string
+// @Router       /sync/control [get]
+func (h *SyncHandler) GetSyncControl(c *gin.Context) {
+	if h.syncWorker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "sync worker is not configured"})
+		return
+	}
+	c.JSON(http.StatusOK, syncControlResponse(h.syncWorker.SyncControl()))
+}
+
+// PauseSync stops dispatching new uploads and holds in-flight uploads between parts.
+//
+// @Summary      Pause cloud sync
+// @Description  Stops dispatching queued episodes. In-flight uploads wait between parts and keep their multipart state; they continue after resume, also across restarts. The mode is persisted.
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SyncControlRequest  false  "Reason"
+// @Success      200   {object}  SyncControlResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /sync/control/pause [post]
+func (h *SyncHandler) PauseSync(c *gin.Context) {
+	h.setSyncMode(c, services.SyncModePaused)
+}
+
+// ResumeSync resumes normal dispatching after a pause or drain.
+//
+// @Summary      Resume cloud sync
+// @Description  Returns the sync worker to normal operation; paused uploads continue and queued episodes are dispatched on the next poll
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SyncControlRequest  false  "Reason"
+// @Success      200   {object}  SyncControlResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /sync/control/resume [post]
+func (h *SyncHandler) ResumeSync(c *gin.Context) {
+	h.setSyncMode(c, services.SyncModeRunning)
+}
+
+// DrainSync lets in-flight uploads finish without dispatching new ones.
+//
+// @Summary      Drain cloud sync
+// @Description  Finishes in-flight uploads and dispatches nothing new; drained=true once no uploads remain. The mode is persisted until resume.
+// @Tags         sync
+// @Accept       json
+// @Produce      json
+// @Param        body  body      SyncControlRequest  false  "Reason"
+// @Success      200   {object}  SyncControlResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      503   {object}  map[string]string
+// @Router       /sync/control/drain [post]
+func (h *SyncHandler) DrainSync(c *gin.Context) {
+	h.setSyncMode(c, services.SyncModeDraining)
+}
+
+func (h *SyncHandler) setSyncMode(c *gin.Context, mode string) {
+	if h.syncWorker == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "sync worker is not configured"})
+		return
+	}
+
+	var req SyncControlRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if len(reason) > maxSyncControlReasonLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("reason must be at most %d characters", maxSyncControlReasonLength)})
+		return
+	}
+
+	state, err := h.syncWorker.SetSyncMode(c.Request.Context(), mode, reason, requestTransitionActor(c).TriggeredByID)
+	if err != nil {
+		logger.Printf("[SYNC] Failed to set sync mode %s: %v", mode, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set sync mode"})
+		return
+	}
+	c.JSON(http.StatusOK, syncControlResponse(state))
+}
+
+// attachSyncLogObjects fills the bundle objects of each listed sync log.
+func (h *SyncHandler) attachSyncLogObjects(ctx context.Context, items []SyncJobResponse) error {
+	if len(items) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(items))
+	index := make(map[int64]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+		index[item.ID] = i
+	}
+	query, args, err := sqlx.In(`
+		SELECT
+			sync_log_id,
+			role,
+			name,
+			source_path,
+			destination_path,
+			size_bytes,
+			sha256,
+			etag,
+			status,
+			error_message,
+			started_at,
+			completed_at
+		FROM sync_log_objects
+		WHERE sync_log_id IN (?)
+		ORDER BY sync_log_id, id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	var rows []syncLogObjectRow
+	if err := h.db.SelectContext(ctx, &rows, h.db.Rebind(query), args...); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		i := index[r.SyncLogID]
+		items[i].Objects = append(items[i].Objects, SyncObjectResponse{
+			Role:            r.Role,
+			Name:            r.Name,
+			SourcePath:      r.SourcePath,
+			DestinationPath: nullableString(r.DestinationPath),
+			SizeBytes:       nullableInt64(r.SizeBytes),
+			SHA256:          nullableString(r.SHA256),
+			ETag:            nullableString(r.ETag),
+			Status:          r.Status,
+			ErrorMessage:    nullableString(r.ErrorMessage),
+			StartedAt:       nullableTime(r.StartedAt),
+			CompletedAt:     nullableTime(r.CompletedAt),
+		})
+	}
+	return nil
+}
+
 func syncJobResponseFromRow(r syncLogRow) SyncJobResponse {
 	return SyncJobResponse{
 		ID:               r.ID,
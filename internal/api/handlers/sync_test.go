@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -48,9 +49,10 @@ func TestGetSyncConfigIncludesAutoScanEnabled(t *testing.T) {
 	}
 
 	var got struct {
-		WorkerRunning   bool `json:"worker_running"`
-		AutoScanEnabled bool `json:"auto_scan_enabled"`
-		MaxRetries      int  `json:"max_retries"`
+		WorkerRunning   bool   `json:"worker_running"`
+		AutoScanEnabled bool   `json:"auto_scan_enabled"`
+		MaxRetries      int    `json:"max_retries"`
+		Destination     string `json:"destination"`
 	}
 	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
 		t.Fatalf("decode response: %v", err)
@@ -64,6 +66,117 @@ func TestGetSyncConfigIncludesAutoScanEnabled(t *testing.T) {
 	if got.MaxRetries != 5 {
 		t.Fatalf("max_retries = %d, want 5", got.MaxRetries)
 	}
+	if got.Destination != "dataplatform" {
+		t.Fatalf("destination = %q, want dataplatform", got.Destination)
+	}
+}
+
+func TestUpdateSyncConfigChangesThrottle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	worker := services.NewSyncWorker(nil, nil, nil, "", services.SyncWorkerConfig{}, nil)
+	router := gin.New()
+	handler := NewSyncHandler(nil, worker)
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	body := `{"bandwidth_limit_kbps":1024,"schedule":[{"start":"00:00","end":"23:59","bandwidth_limit_kbps":256}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/sync/config", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var got struct {
+		BandwidthLimitKBps int64 `json:"bandwidth_limit_kbps"`
+		Schedule           []struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		} `json:"schedule"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.BandwidthLimitKBps != 1024 || len(got.Schedule) != 1 || got.Schedule[0].Start != "00:00" {
+		t.Fatalf("response = %+v", got)
+	}
+	if throttle := worker.SyncThrottle(); throttle.BandwidthLimitKBps != 1024 || len(throttle.Schedule) != 1 {
+		t.Fatalf("worker throttle = %+v", throttle)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/sync/config", strings.NewReader(`{"bandwidth_limit_kbps":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400; body = %s", rec.Code, rec.Body.String())
+	}
+	if throttle := worker.SyncThrottle(); throttle.BandwidthLimitKBps != 1024 {
+		t.Fatalf("rejected update changed limit to %d", throttle.BandwidthLimitKBps)
+	}
+}
+
+func TestSyncControlPauseDrainResume(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	worker := services.NewSyncWorker(nil, nil, nil, "", services.SyncWorkerConfig{}, nil)
+	router := gin.New()
+	handler := NewSyncHandler(nil, worker)
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	post := func(path, body string) SyncControlResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, body = %s", path, rec.Code, rec.Body.String())
+		}
+		var got SyncControlResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return got
+	}
+
+	got := post("/api/v1/sync/control/pause", `{"reason":"network maintenance"}`)
+	if got.Mode != services.SyncModePaused || got.Reason == nil || *got.Reason != "network maintenance" {
+		t.Fatalf("pause response = %+v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/sync/config", nil))
+	var cfg struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &cfg); err != nil {
+		t.Fatalf("decode config: %v", err)
+	}
+	if cfg.Mode != services.SyncModePaused {
+		t.Fatalf("config mode = %q, want paused", cfg.Mode)
+	}
+
+	got = post("/api/v1/sync/control/drain", "")
+	if got.Mode != services.SyncModeDraining || !got.Drained {
+		t.Fatalf("drain response = %+v, want draining and drained with nothing in flight", got)
+	}
+
+	got = post("/api/v1/sync/control/resume", "")
+	if got.Mode != services.SyncModeRunning || got.Drained {
+		t.Fatalf("resume response = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/control/pause", strings.NewReader(`{"reason":`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("malformed body: status = %d, want 400", rec.Code)
+	}
 }
 
 func TestListEpisodeSyncSummariesGroupsByEpisode(t *testing.T) {
@@ -128,6 +241,65 @@ func TestListEpisodeSyncSummariesGroupsByEpisode(t *testing.T) {
 	}
 }
 
+func TestListEpisodeSyncLogsIncludesBundleObjects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupSyncHandlerTestDB(t)
+
+	if _, err := db.Exec(`INSERT INTO episodes (id, episode_id, deleted_at) VALUES (1, 'episode-a', NULL)`); err != nil {
+		t.Fatalf("insert episode: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO sync_logs (id, episode_id, source_path, status, attempt_count)
+		VALUES (1, 1, 'local/a.mcap', 'failed', 1), (2, 1, 'local/a.mcap', 'in_progress', 1)
+	`); err != nil {
+		t.Fatalf("insert sync logs: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO sync_log_objects (id, sync_log_id, episode_id, role, name, source_path, destination_path, size_bytes, sha256, status, error_message)
+		VALUES
+			(1, 2, 1, 'mcap', 'a.mcap', 'local/a.mcap', 'cloud/a.mcap', 10, 'abc', 'completed', NULL),
+			(2, 2, 1, 'sidecar', 'a.json', 'local/a.json', NULL, 2, NULL, 'failed', 'gateway unavailable'),
+			(3, 2, 1, 'attachment', 'thumb.jpg', 'local/a/attachments/thumb.jpg', NULL, 5, NULL, 'pending', NULL)
+	`); err != nil {
+		t.Fatalf("insert sync log objects: %v", err)
+	}
+
+	router := gin.New()
+	handler := NewSyncHandler(db, nil)
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/sync/episodes/1/logs", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	var got SyncJobListResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(got.Items) != 2 || got.Items[0].ID != 2 {
+		t.Fatalf("items = %+v, want latest sync log first", got.Items)
+	}
+	if len(got.Items[1].Objects) != 0 {
+		t.Fatalf("sync log 1 objects = %+v, want none", got.Items[1].Objects)
+	}
+	objects := got.Items[0].Objects
+	if len(objects) != 3 {
+		t.Fatalf("objects = %+v, want 3", objects)
+	}
+	if objects[0].Role != "mcap" || objects[0].Status != "completed" || objects[0].SHA256 == nil || *objects[0].SHA256 != "abc" {
+		t.Fatalf("mcap object = %+v", objects[0])
+	}
+	if objects[1].Status != "failed" || objects[1].ErrorMessage == nil || *objects[1].ErrorMessage != "gateway unavailable" {
+		t.Fatalf("sidecar object = %+v", objects[1])
+	}
+	if objects[2].Role != "attachment" || objects[2].Status != "pending" {
+		t.Fatalf("attachment object = %+v", objects[2])
+	}
+}
+
 func TestGetSyncStatusReturnsNotStartedWhenEpisodeHasNoSyncLog(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupSyncHandlerTestDB(t)
@@ -172,6 +344,95 @@ func TestGetSyncStatusReturnsNotStartedWhenEpisodeHasNoSyncLog(t *testing.T) {
 	}
 }
 
+func TestSetEpisodeSyncPriorityMovesEpisodeToFrontOfQueue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupSyncHandlerTestDB(t)
+
+	now := time.Now().UTC()
+	if _, err := db.Exec(`
+		INSERT INTO orders (id, priority, deadline) VALUES (1, 'urgent', ?), (2, 'low', NULL)
+	`, now.Add(time.Hour)); err != nil {
+		t.Fatalf("insert orders: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO episodes (id, episode_id, order_id) VALUES (1, 'episode-urgent', 1), (2, 'episode-low', 2)
+	`); err != nil {
+		t.Fatalf("insert episodes: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO sync_logs (id, episode_id, status, started_at) VALUES (1, 1, 'pending', ?), (2, 2, 'pending', ?)
+	`, now, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("insert sync logs: %v", err)
+	}
+
+	worker := services.NewSyncWorker(db, nil, nil, "", services.SyncWorkerConfig{BatchSize: 10}, nil)
+	router := gin.New()
+	handler := NewSyncHandler(db, worker)
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	getStatus := func(id string) EpisodeSyncStatusResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/sync/episodes/"+id+"/status", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var got EpisodeSyncStatusResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return got
+	}
+
+	if got := getStatus("2"); got.QueuePosition == nil || *got.QueuePosition != 2 {
+		t.Fatalf("low priority queue_position = %v, want 2", got.QueuePosition)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sync/episodes/2/priority", strings.NewReader(`{"priority":1000}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var got EpisodeSyncStatusResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.QueuePosition == nil || *got.QueuePosition != 1 || got.SyncPriority == nil || *got.SyncPriority != 1000 {
+		t.Fatalf("queue_position = %v sync_priority = %v, want 1 and 1000", got.QueuePosition, got.SyncPriority)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sync/episodes/2/priority", strings.NewReader(`{"priority":null}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if got := getStatus("2"); got.QueuePosition == nil || *got.QueuePosition != 2 {
+		t.Fatalf("queue_position after clearing override = %v, want 2", got.QueuePosition)
+	}
+
+	for _, body := range []string{`{"priority":1001}`, `{"priority":-1}`} {
+		rec = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/sync/episodes/2/priority", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: status = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/sync/episodes/99/priority", strings.NewReader(`{"priority":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing episode: status = %d, want 404", rec.Code)
+	}
+}
+
 func TestGetSyncStatusReturnsNotFoundWhenEpisodeDoesNotExist(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupSyncHandlerTestDB(t)
@@ -309,9 +570,23 @@ func setupSyncHandlerTestDB(t *testing.T) *sqlx.DB {
 		`CREATE TABLE episodes (
 			id INTEGER PRIMARY KEY,
 			episode_id TEXT,
+			task_id INTEGER NULL,
+			order_id INTEGER NULL,
 			cloud_synced BOOLEAN DEFAULT FALSE,
 			cloud_processed BOOLEAN DEFAULT FALSE,
 			cloud_synced_at TIMESTAMP NULL,
+			sync_priority_override INTEGER NULL,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY,
+			order_id INTEGER NULL,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE orders (
+			id INTEGER PRIMARY KEY,
+			priority TEXT NOT NULL DEFAULT 'normal',
+			deadline TIMESTAMP NULL,
 			deleted_at TIMESTAMP NULL
 		)`,
 		`CREATE TABLE sync_logs (
@@ -325,10 +600,27 @@ func setupSyncHandlerTestDB(t *testing.T) *sqlx.DB {
 			duration_sec INTEGER,
 			error_message TEXT,
 			attempt_count INTEGER NOT NULL DEFAULT 0,
+			manual BOOLEAN NOT NULL DEFAULT FALSE,
 			next_retry_at TIMESTAMP NULL,
 			started_at TIMESTAMP NULL,
 			completed_at TIMESTAMP NULL
 		)`,
+		`CREATE TABLE sync_log_objects (
+			id INTEGER PRIMARY KEY,
+			sync_log_id INTEGER NOT NULL,
+			episode_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			name TEXT NOT NULL,
+			source_path TEXT NOT NULL,
+			destination_path TEXT,
+			size_bytes INTEGER,
+			sha256 TEXT,
+			etag TEXT,
+			status TEXT NOT NULL DEFAULT 'pending',
+			error_message TEXT,
+			started_at TIMESTAMP NULL,
+			completed_at TIMESTAMP NULL
+		)`,
 	}
 	for _, stmt := range schema {
 		if _, err := db.Exec(stmt); err != nil {
@@ -813,7 +813,7 @@ func (h *TaskHandler) OnRecordingStart(c *gin.Context) {
 
 	taskStatus := "unknown"
 	if h.db != nil {
-		rowsAffected, previousStatus, err := advanceTaskPendingOrReadyToInProgress(h.db, callback.TaskID)
+		rowsAffected, previousStatus, err := advanceTaskPendingOrReadyToInProgress(h.db, axonTransitionActor(callback.DeviceID), callback.TaskID)
 		if err != nil {
 			logger.Printf("%s failed to advance task pending/ready->in_progress after start callback: err=%v", recorderTaskLogPrefix(callback.DeviceID, callback.TaskID), err)
 		} else if rowsAffected > 0 {
@@ -920,12 +920,12 @@ func (h *TaskHandler) OnRecordingFinish(c *gin.Context) {
 			return
 		}
 
-		res, err := markOwnedTaskUploading(c.Request.Context(), h.db, deviceID, callback.TaskID)
+		n, err := markOwnedTaskUploading(c.Request.Context(), h.db, axonTransitionActor(deviceID), deviceID, callback.TaskID)
 		if err != nil {
 			logger.Printf("%s failed to mark task uploading after finish callback: err=%v", recorderTaskLogPrefix(deviceID, callback.TaskID), err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error_msg": "Failed to update task status"})
 			return
-		} else if n, _ := res.RowsAffected(); n > 0 {
+		} else if n > 0 {
 			logger.Printf("%s task status updated: %s -> uploading reason=finish_callback", recorderTaskLogPrefix(deviceID, callback.TaskID), taskStatusLogValue(previousStatus, "unknown"))
 		} else {
 			currentStatus, _, statusErr := currentOwnedTaskStatus(c.Request.Context(), h.db, deviceID, callback.TaskID)
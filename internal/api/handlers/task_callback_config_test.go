@@ -132,5 +132,6 @@ func newTestTaskConfigCallbackDB(t *testing.T) *sqlx.DB {
 			t.Fatalf("seed data: %v", err)
 		}
 	}
+	createStateTransitionsTestTable(t, db)
 	return db
 }
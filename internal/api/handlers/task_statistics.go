@@ -12,6 +12,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"archebase.com/keystone-edge/internal/auth"
 	"archebase.com/keystone-edge/internal/logger"
 	"archebase.com/keystone-edge/internal/middleware"
 )
@@ -77,7 +78,7 @@ func (h *TaskHandler) GetTaskBreakdown(c *gin.Context) {
 					AND ws_scope.deleted_at IS NULL
 			)`
 		args = append(args, claims.CollectorID)
-	case "admin":
+	case "admin", auth.RoleAPIKey:
 		if q.WorkstationID != "" {
 			whereClause += " AND CAST(tasks.workstation_id AS CHAR) = ?"
 			args = append(args, q.WorkstationID)
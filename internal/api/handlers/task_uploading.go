@@ -9,6 +9,8 @@ import (
 	"database/sql"
 	"strings"
 	"time"
+
+	"github.com/jmoiron/sqlx"
 )
 
 type taskStateExecutor interface {
@@ -51,19 +53,9 @@ func taskStatusLogValue(status, fallback string) string {
 	return status
 }
 
-func markOwnedTaskUploading(ctx context.Context, exec taskStateExecutor, deviceID, taskID string) (sql.Result, error) {
-	if exec == nil {
-		return nil, nil
-	}
-	now := time.Now().UTC()
-	return exec.ExecContext(ctx, `
-		UPDATE tasks
-		SET
-			status = 'uploading',
-			updated_at = ?,
-			error_message = NULL
-		WHERE task_id = ?
-		  AND status IN ('pending', 'ready', 'in_progress')
+// ownedTaskWhere limits a task update to the given task_id when it is
+// assigned to a workstation whose robot is deviceID.
+const ownedTaskWhere = `task_id = ?
 		  AND deleted_at IS NULL
 		  AND EXISTS (
 			SELECT 1
@@ -72,34 +64,33 @@ func markOwnedTaskUploading(ctx context.Context, exec taskStateExecutor, deviceI
 			WHERE ws.id = tasks.workstation_id
 			  AND ws.deleted_at IS NULL
 			  AND r.device_id = ?
-		  )
-	`, now, strings.TrimSpace(taskID), strings.TrimSpace(deviceID))
+		  )`
+
+func markOwnedTaskUploading(ctx context.Context, db *sqlx.DB, actor stateTransitionActor, deviceID, taskID string) (int64, error) {
+	if db == nil {
+		return 0, nil
+	}
+	now := time.Now().UTC()
+	return execStateTransition(ctx, db, stateEntityTask, "uploading", actor, nil,
+		`status = 'uploading',
+			updated_at = ?,
+			error_message = NULL`, []any{now},
+		"status IN ('pending', 'ready', 'in_progress') AND "+ownedTaskWhere,
+		strings.TrimSpace(taskID), strings.TrimSpace(deviceID))
 }
 
-func failOwnedUploadingTask(ctx context.Context, exec taskStateExecutor, deviceID, taskID, reason string) (sql.Result, error) {
-	if exec == nil {
-		return nil, nil
+func failOwnedUploadingTask(ctx context.Context, db *sqlx.DB, actor stateTransitionActor, deviceID, taskID, reason string) (int64, error) {
+	if db == nil {
+		return 0, nil
 	}
 	now := time.Now().UTC()
-	return exec.ExecContext(ctx, `
-		UPDATE tasks
-		SET
-			status = 'failed',
+	return execStateTransition(ctx, db, stateEntityTask, "failed", actor, map[string]any{"reason": strings.TrimSpace(reason)},
+		`status = 'failed',
 			completed_at = CASE WHEN completed_at IS NULL THEN ? ELSE completed_at END,
 			error_message = ?,
-			updated_at = ?
-		WHERE task_id = ?
-		  AND status IN ('in_progress', 'uploading')
-		  AND deleted_at IS NULL
-		  AND EXISTS (
-			SELECT 1
-			FROM workstations ws
-			JOIN robots r ON r.id = ws.robot_id AND r.deleted_at IS NULL
-			WHERE ws.id = tasks.workstation_id
-			  AND ws.deleted_at IS NULL
-			  AND r.device_id = ?
-		  )
-	`, now, strings.TrimSpace(reason), now, strings.TrimSpace(taskID), strings.TrimSpace(deviceID))
+			updated_at = ?`, []any{now, strings.TrimSpace(reason), now},
+		"status IN ('in_progress', 'uploading') AND "+ownedTaskWhere,
+		strings.TrimSpace(taskID), strings.TrimSpace(deviceID))
 }
 
 func writeOwnedUploadingTaskError(ctx context.Context, exec taskStateExecutor, deviceID, taskID, message string) (sql.Result, error) {
@@ -362,10 +362,10 @@ func (h *TransferHandler) onUploadStarted(ctx context.Context, dc *services.Tran
 	taskID := stringVal(data, "task_id")
 	if h.db != nil && taskID != "" {
 		previousStatus, _, _ := currentOwnedTaskStatus(ctx, h.db, dc.DeviceID, taskID)
-		res, err := markOwnedTaskUploading(ctx, h.db, dc.DeviceID, taskID)
+		n, err := markOwnedTaskUploading(ctx, h.db, axonTransitionActor(dc.DeviceID), dc.DeviceID, taskID)
 		if err != nil {
 			logger.Printf("%s failed to mark task uploading after upload_started: err=%v", transferTaskLogPrefix(dc.DeviceID, taskID), err)
-		} else if n, _ := res.RowsAffected(); n > 0 {
+		} else if n > 0 {
 			logger.Printf("%s task status updated: %s -> uploading reason=upload_started", transferTaskLogPrefix(dc.DeviceID, taskID), taskStatusLogValue(previousStatus, "unknown"))
 		}
 	}
@@ -884,30 +884,27 @@ func (h *TransferHandler) onUploadComplete(ctx context.Context, dc *services.Tra
 	// for legacy weak-network recovery; uploading is the normal post-recording path.
 	// Best-effort: do not affect the already-sent acknowledgement.
 	now := time.Now().UTC()
-	res, err := h.db.ExecContext(ctx, `
-		UPDATE tasks
-		SET
+	actor := axonTransitionActor(dc.DeviceID)
+	rowsAffected, err := execStateTransition(ctx, h.db, stateEntityTask, "completed", actor, map[string]any{"reason": "upload_ack"}, `
 			status = 'completed',
 			completed_at = CASE WHEN completed_at IS NULL THEN ? ELSE completed_at END,
 			error_message = NULL,
-			updated_at = ?
-		WHERE id = ? AND status IN ('pending', 'ready', 'in_progress', 'uploading', 'completed') AND deleted_at IS NULL
-	`, now, now, taskPK)
+			updated_at = ?`, []any{now, now},
+		"id = ? AND status IN ('pending', 'ready', 'in_progress', 'uploading', 'completed') AND deleted_at IS NULL", taskPK)
 	if err != nil {
 		// #nosec G706 -- Set aside for now
 		logger.Printf("%s failed to mark task completed after upload_ack: err=%v", transferTaskLogPrefix(dc.DeviceID, taskID), err)
 	} else {
-		rowsAffected, _ := res.RowsAffected()
 		shouldAdvance := ownedTask.Status != "completed" && rowsAffected > 0
 		if shouldAdvance {
 			logger.Printf("%s task status updated: %s -> completed reason=upload_ack", transferTaskLogPrefix(dc.DeviceID, taskID), taskStatusLogValue(ownedTask.Status, "unknown"))
 		}
 		if shouldAdvance && batchIDForAdvance > 0 {
 			// Must run after the task row is terminal: tryAdvanceBatchStatus counts tasks in DB.
-			go tryAdvanceBatchStatus(h.db, batchIDForAdvance)
+			go tryAdvanceBatchStatus(h.db, batchIDForAdvance, actor)
 		}
 		if shouldAdvance && orderIDForAdvance > 0 {
-			go tryAdvanceOrderStatus(h.db, orderIDForAdvance, h.recorderHub, h.recorderRPCTimeout)
+			go tryAdvanceOrderStatus(h.db, orderIDForAdvance, h.recorderHub, h.recorderRPCTimeout, actor)
 		}
 	}
 }
@@ -950,13 +947,14 @@ func (h *TransferHandler) onUploadFailed(ctx context.Context, dc *services.Trans
 	if message == "" {
 		message = "upload failed"
 	}
-	result, err := failOwnedUploadingTask(ctx, h.db, dc.DeviceID, taskID, message)
+	actor := axonTransitionActor(dc.DeviceID)
+	rows, err := failOwnedUploadingTask(ctx, h.db, actor, dc.DeviceID, taskID, message)
 	if err != nil {
 		// #nosec G706 -- Set aside for now
 		logger.Printf("%s failed to mark task failed on upload_failed: err=%v", transferTaskLogPrefix(dc.DeviceID, taskID), err)
 		return
 	}
-	if rows, _ := result.RowsAffected(); rows > 0 {
+	if rows > 0 {
 		// #nosec G706 -- Set aside for now
 		logger.Printf("%s marked as failed due to upload_failed", transferTaskLogPrefix(dc.DeviceID, taskID))
 		// Trigger batch status advancement since the task reached a terminal state.
@@ -964,7 +962,7 @@ func (h *TransferHandler) onUploadFailed(ctx context.Context, dc *services.Trans
 		if err := h.db.QueryRowContext(ctx,
 			"SELECT batch_id FROM tasks WHERE task_id = ? AND deleted_at IS NULL", taskID,
 		).Scan(&batchID); err == nil && batchID > 0 {
-			go tryAdvanceBatchStatus(h.db, batchID)
+			go tryAdvanceBatchStatus(h.db, batchID, actor)
 		}
 	}
 }
@@ -1064,24 +1062,22 @@ func revertRunnableTasksOnDeviceDisconnect(db *sqlx.DB, deviceID string, recorde
 		}
 	}
 
+	actor := axonTransitionActor(deviceID)
 	for _, ref := range toRevert {
-		result, err := db.ExecContext(ctx, `
-			UPDATE tasks
-			SET
+		affected, err := execStateTransition(ctx, db, stateEntityTask, "pending", actor, map[string]any{"reason": "device_disconnect"}, `
 				status = 'pending',
 				ready_at = NULL,
 				started_at = NULL,
 				completed_at = NULL,
 				error_message = NULL,
-				updated_at = ?
-			WHERE id = ? AND status IN ('ready', 'in_progress') AND deleted_at IS NULL
-		`, now, ref.id)
+				updated_at = ?`, []any{now},
+			"id = ? AND status IN ('ready', 'in_progress') AND deleted_at IS NULL", ref.id)
 		if err != nil {
 			// #nosec G706 -- Set aside for now
 			logger.Printf("%s failed to revert to pending on disconnect: %v", deviceTaskLogPrefix(deviceID, ref.taskID), err)
 			continue
 		}
-		if affected, _ := result.RowsAffected(); affected > 0 {
+		if affected > 0 {
 			// #nosec G706 -- Set aside for now
 			logger.Printf("%s reverted to pending due to device disconnect", deviceTaskLogPrefix(deviceID, ref.taskID))
 		}
@@ -104,6 +104,7 @@ func newTransferTakeoverDB(t *testing.T) *sqlx.DB {
 	if _, err := db.Exec(`INSERT INTO workstations (id, robot_id) VALUES (10, 1)`); err != nil {
 		t.Fatalf("seed workstation: %v", err)
 	}
+	createStateTransitionsTestTable(t, db)
 	return db
 }
 
@@ -0,0 +1,732 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	maxWebhookNameLength   = 255
+	maxWebhookURLLength    = 2048
+	maxWebhookSecretLength = 255
+	minWebhookSecretLength = 16
+)
+
+// WebhookHandler manages webhook subscriptions and serves their delivery log.
+// Deliveries themselves are made by services.WebhookDispatcher.
+type WebhookHandler struct {
+	db *sqlx.DB
+}
+
+// NewWebhookHandler creates a new WebhookHandler.
+func NewWebhookHandler(db *sqlx.DB) *WebhookHandler {
+	return &WebhookHandler{db: db}
+}
+
+// WebhookResponse represents a webhook subscription. The secret is only
+// returned when the subscription is created.
+type WebhookResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Enabled    bool     `json:"enabled"`
+	Secret     string   `json:"secret,omitempty"`
+	CreatedAt  string   `json:"created_at,omitempty"`
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+}
+
+// WebhookListResponse represents the response for listing webhook subscriptions.
+type WebhookListResponse struct {
+	Items   []WebhookResponse `json:"items"`
+	Total   int               `json:"total"`
+	Limit   int               `json:"limit"`
+	Offset  int               `json:"offset"`
+	HasNext bool              `json:"hasNext,omitempty"`
+	HasPrev bool              `json:"hasPrev,omitempty"`
+}
+
+// CreateWebhookRequest represents the request body for creating a webhook
+// subscription. A random secret is generated when Secret is empty.
+type CreateWebhookRequest struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// UpdateWebhookRequest represents the request body for updating a webhook
+// subscription. Omitted fields are unchanged; setting Secret rotates it.
+type UpdateWebhookRequest struct {
+	Name       *string  `json:"name,omitempty"`
+	URL        *string  `json:"url,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+	Secret     *string  `json:"secret,omitempty"`
+	Enabled    *bool    `json:"enabled,omitempty"`
+}
+
+// WebhookDeliveryAttemptResponse is one HTTP attempt of a delivery.
+type WebhookDeliveryAttemptResponse struct {
+	Attempt     int    `json:"attempt"`
+	StatusCode  *int64 `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+	DurationMs  int64  `json:"duration_ms"`
+	AttemptedAt string `json:"attempted_at"`
+}
+
+// WebhookDeliveryResponse is one outbox entry with its attempt log.
+type WebhookDeliveryResponse struct {
+	ID             string                           `json:"id"`
+	EventID        string                           `json:"event_id"`
+	EventType      string                           `json:"event_type"`
+	Status         string                           `json:"status"`
+	Attempts       int                              `json:"attempts"`
+	NextAttemptAt  string                           `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int64                           `json:"last_status_code,omitempty"`
+	LastError      string                           `json:"last_error,omitempty"`
+	DeliveredAt    string                           `json:"delivered_at,omitempty"`
+	CreatedAt      string                           `json:"created_at,omitempty"`
+	Payload        interface{}                      `json:"payload,omitempty"`
+	AttemptLog     []WebhookDeliveryAttemptResponse `json:"attempt_log"`
+}
+
+// WebhookDeliveryListResponse represents the delivery log of a subscription.
+type WebhookDeliveryListResponse struct {
+	Items   []WebhookDeliveryResponse `json:"items"`
+	Total   int                       `json:"total"`
+	Limit   int                       `json:"limit"`
+	Offset  int                       `json:"offset"`
+	HasNext bool                      `json:"hasNext,omitempty"`
+	HasPrev bool                      `json:"hasPrev,omitempty"`
+}
+
+// RegisterRoutes registers webhook admin routes.
+func (h *WebhookHandler) RegisterRoutes(apiV1 *gin.RouterGroup) {
+	apiV1.GET("/admin/webhooks", h.ListWebhooks)
+	apiV1.POST("/admin/webhooks", h.CreateWebhook)
+	apiV1.GET("/admin/webhooks/:id", h.GetWebhook)
+	apiV1.PUT("/admin/webhooks/:id", h.UpdateWebhook)
+	apiV1.DELETE("/admin/webhooks/:id", h.DeleteWebhook)
+	apiV1.GET("/admin/webhooks/:id/deliveries", h.ListWebhookDeliveries)
+	apiV1.POST("/admin/webhooks/:id/deliveries/:delivery_id/retry", h.RetryWebhookDelivery)
+}
+
+type webhookRow struct {
+	ID         int64        `db:"id"`
+	Name       string       `db:"name"`
+	URL        string       `db:"url"`
+	EventTypes string       `db:"event_types"`
+	Enabled    bool         `db:"enabled"`
+	CreatedAt  sql.NullTime `db:"created_at"`
+	UpdatedAt  sql.NullTime `db:"updated_at"`
+}
+
+const webhookColumns = "id, name, url, event_types, enabled, created_at, updated_at"
+
+func webhookResponseFromRow(row webhookRow) WebhookResponse {
+	var eventTypes []string
+	if err := json.Unmarshal([]byte(row.EventTypes), &eventTypes); err != nil || eventTypes == nil {
+		eventTypes = []string{}
+	}
+	resp := WebhookResponse{
+		ID:         fmt.Sprintf("%d", row.ID),
+		Name:       row.Name,
+		URL:        row.URL,
+		EventTypes: eventTypes,
+		Enabled:    row.Enabled,
+	}
+	if row.CreatedAt.Valid {
+		resp.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if row.UpdatedAt.Valid {
+		resp.UpdatedAt = row.UpdatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// normalizeWebhookEventTypes trims, de-duplicates and sorts event types,
+// rejecting unknown ones.
+func normalizeWebhookEventTypes(eventTypes []string) ([]string, error) {
+	seen := make(map[string]bool, len(eventTypes))
+	out := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		if !services.IsWebhookEventType(t) {
+			return nil, fmt.Errorf("unknown event type %q; must be one of: %s", t, strings.Join(services.WebhookEventTypes, ", "))
+		}
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("event_types must contain at least one event type")
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	if len(raw) > maxWebhookURLLength {
+		return fmt.Errorf("url must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func validateWebhookSecret(secret string) error {
+	if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		return fmt.Errorf("secret must be between %d and %d characters", minWebhookSecretLength, maxWebhookSecretLength)
+	}
+	return nil
+}
+
+func newWebhookSecret() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func parseWebhookID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// ListWebhooks handles webhook subscription listing requests.
+//
+// @Summary      List webhooks
+// @Description  Lists webhook subscriptions
+// @Tags         webhooks
+// @Produce      json
+// @Param        limit  query int false "Max results (default 50, max 100)"
+// @Param        offset query int false "Pagination offset (default 0)"
+// @Success      200 {object} WebhookListResponse
+// @Failure      400 {object} map[string]string
+// @Failure      500 {object} map[string]string
+// @Router       /admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(*) FROM webhook_subscriptions WHERE deleted_at IS NULL"); err != nil {
+		logger.Printf("[WEBHOOK] Failed to count webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks"})
+		return
+	}
+	var rows []webhookRow
+	if err := h.db.Select(&rows, "SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?",
+		pagination.Limit, pagination.Offset); err != nil {
+		logger.Printf("[WEBHOOK] Failed to query webhooks: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks"})
+		return
+	}
+
+	items := make([]WebhookResponse, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, webhookResponseFromRow(row))
+	}
+	c.JSON(http.StatusOK, WebhookListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// GetWebhook handles getting a single webhook subscription.
+//
+// @Summary      Get webhook
+// @Description  Gets a webhook subscription by ID
+// @Tags         webhooks
+// @Produce      json
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      200  {object}  WebhookResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c)
+	if !ok {
+		return
+	}
+	row, ok := h.loadWebhook(c, id, "failed to get webhook")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, webhookResponseFromRow(row))
+}
+
+func (h *WebhookHandler) loadWebhook(c *gin.Context, id int64, failure string) (webhookRow, bool) {
+	var row webhookRow
+	err := h.db.Get(&row, "SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE id = ? AND deleted_at IS NULL", id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return row, false
+	}
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to query webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": failure})
+		return row, false
+	}
+	return row, true
+}
+
+// CreateWebhook handles webhook subscription creation requests.
+//
+// @Summary      Create webhook
+// @Description  Creates a webhook subscription. The signing secret is returned only in this response.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        body  body      CreateWebhookRequest  true  "Webhook payload"
+// @Success      201   {object}  WebhookResponse
+// @Failure      400   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.URL = strings.TrimSpace(req.URL)
+	req.Secret = strings.TrimSpace(req.Secret)
+
+	if req.Name == "" || len(req.Name) > maxWebhookNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name is required and must be at most %d characters", maxWebhookNameLength)})
+		return
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Secret == "" {
+		req.Secret = newWebhookSecret()
+	} else if err := validateWebhookSecret(req.Secret); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	eventTypesJSON, _ := json.Marshal(eventTypes)
+	now := time.Now().UTC()
+	result, err := h.db.Exec(`
+		INSERT INTO webhook_subscriptions (name, url, secret, event_types, enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, req.Name, req.URL, req.Secret, string(eventTypesJSON), enabled, now, now)
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to insert webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to fetch inserted id: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, WebhookResponse{
+		ID:         fmt.Sprintf("%d", id),
+		Name:       req.Name,
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Enabled:    enabled,
+		Secret:     req.Secret,
+		CreatedAt:  now.Format(time.RFC3339),
+		UpdatedAt:  now.Format(time.RFC3339),
+	})
+}
+
+// UpdateWebhook handles webhook subscription updates.
+//
+// @Summary      Update webhook
+// @Description  Updates a webhook subscription; setting secret rotates it
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string                true  "Webhook ID"
+// @Param        body body      UpdateWebhookRequest  true  "Webhook payload"
+// @Success      200  {object}  WebhookResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c)
+	if !ok {
+		return
+	}
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	updates := []string{}
+	args := []interface{}{}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > maxWebhookNameLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("name cannot be empty and must be at most %d characters", maxWebhookNameLength)})
+			return
+		}
+		updates = append(updates, "name = ?")
+		args = append(args, name)
+	}
+	if req.URL != nil {
+		u := strings.TrimSpace(*req.URL)
+		if err := validateWebhookURL(u); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates = append(updates, "url = ?")
+		args = append(args, u)
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		eventTypesJSON, _ := json.Marshal(eventTypes)
+		updates = append(updates, "event_types = ?")
+		args = append(args, string(eventTypesJSON))
+	}
+	if req.Secret != nil {
+		secret := strings.TrimSpace(*req.Secret)
+		if err := validateWebhookSecret(secret); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates = append(updates, "secret = ?")
+		args = append(args, secret)
+	}
+	if req.Enabled != nil {
+		updates = append(updates, "enabled = ?")
+		args = append(args, *req.Enabled)
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no fields to update"})
+		return
+	}
+
+	updates = append(updates, "updated_at = ?")
+	args = append(args, time.Now().UTC(), id)
+	// #nosec G202 -- updates are static column assignments; all values are bound.
+	query := "UPDATE webhook_subscriptions SET " + strings.Join(updates, ", ") + " WHERE id = ? AND deleted_at IS NULL"
+	result, err := h.db.Exec(query, args...)
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to update webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update webhook"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	row, ok := h.loadWebhook(c, id, "failed to update webhook")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, webhookResponseFromRow(row))
+}
+
+// DeleteWebhook handles webhook subscription deletion. Pending deliveries
+// are no longer attempted; the delivery log is kept.
+//
+// @Summary      Delete webhook
+// @Description  Soft deletes a webhook subscription
+// @Tags         webhooks
+// @Param        id   path      string  true  "Webhook ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	result, err := h.db.Exec("UPDATE webhook_subscriptions SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL", now, now, id)
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to delete webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook"})
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+type webhookDeliveryRow struct {
+	ID             int64          `db:"id"`
+	EventID        string         `db:"event_id"`
+	EventType      string         `db:"event_type"`
+	Payload        sql.NullString `db:"payload"`
+	Status         string         `db:"status"`
+	Attempts       int            `db:"attempts"`
+	NextAttemptAt  sql.NullTime   `db:"next_attempt_at"`
+	LastStatusCode sql.NullInt64  `db:"last_status_code"`
+	LastError      sql.NullString `db:"last_error"`
+	DeliveredAt    sql.NullTime   `db:"delivered_at"`
+	CreatedAt      sql.NullTime   `db:"created_at"`
+}
+
+type webhookDeliveryAttemptRow struct {
+	DeliveryID  int64          `db:"delivery_id"`
+	Attempt     int            `db:"attempt"`
+	StatusCode  sql.NullInt64  `db:"status_code"`
+	Error       sql.NullString `db:"error"`
+	DurationMs  int64          `db:"duration_ms"`
+	AttemptedAt time.Time      `db:"attempted_at"`
+}
+
+// ListWebhookDeliveries serves the delivery log of a subscription, newest first.
+//
+// @Summary      List webhook deliveries
+// @Description  Lists outbox deliveries of a webhook subscription with their attempts
+// @Tags         webhooks
+// @Produce      json
+// @Param        id          path   string  true   "Webhook ID"
+// @Param        status      query  string  false  "Filter by status: pending, delivered, dead"
+// @Param        event_type  query  string  false  "Filter by event type"
+// @Param        limit       query  int     false  "Max results (default 50, max 100)"
+// @Param        offset      query  int     false  "Pagination offset (default 0)"
+// @Success      200  {object}  WebhookDeliveryListResponse
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	id, ok := parseWebhookID(c)
+	if !ok {
+		return
+	}
+	pagination, err := ParsePagination(c)
+	if err != nil {
+		PaginationErrorResponse(c, err)
+		return
+	}
+
+	where := "WHERE subscription_id = ?"
+	args := []any{id}
+	if status := strings.TrimSpace(c.Query("status")); status != "" {
+		switch status {
+		case services.WebhookDeliveryPending, services.WebhookDeliveryDelivered, services.WebhookDeliveryDead:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of: pending, delivered, dead"})
+			return
+		}
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+	if eventType := strings.TrimSpace(c.Query("event_type")); eventType != "" {
+		where += " AND event_type = ?"
+		args = append(args, eventType)
+	}
+
+	var exists bool
+	if err := h.db.Get(&exists, "SELECT EXISTS(SELECT 1 FROM webhook_subscriptions WHERE id = ?)", id); err != nil {
+		logger.Printf("[WEBHOOK] Failed to check webhook %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+
+	var total int
+	if err := h.db.Get(&total, "SELECT COUNT(*) FROM webhook_deliveries "+where, args...); err != nil {
+		logger.Printf("[WEBHOOK] Failed to count deliveries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+	var rows []webhookDeliveryRow
+	query := `
+		SELECT id, event_id, event_type, payload, status, attempts, next_attempt_at,
+		       last_status_code, last_error, delivered_at, created_at
+		FROM webhook_deliveries
+		` + where + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`
+	if err := h.db.Select(&rows, query, append(args, pagination.Limit, pagination.Offset)...); err != nil {
+		logger.Printf("[WEBHOOK] Failed to query deliveries: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+
+	attemptsByDelivery := map[int64][]WebhookDeliveryAttemptResponse{}
+	if len(rows) > 0 {
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		attemptQuery, attemptArgs, err := sqlx.In(`
+			SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at
+			FROM webhook_delivery_attempts
+			WHERE delivery_id IN (?)
+			ORDER BY delivery_id, id
+		`, ids)
+		if err == nil {
+			var attempts []webhookDeliveryAttemptRow
+			err = h.db.Select(&attempts, h.db.Rebind(attemptQuery), attemptArgs...)
+			for _, a := range attempts {
+				attemptsByDelivery[a.DeliveryID] = append(attemptsByDelivery[a.DeliveryID], WebhookDeliveryAttemptResponse{
+					Attempt:     a.Attempt,
+					StatusCode:  nullableInt64(a.StatusCode),
+					Error:       a.Error.String,
+					DurationMs:  a.DurationMs,
+					AttemptedAt: a.AttemptedAt.UTC().Format(time.RFC3339),
+				})
+			}
+		}
+		if err != nil {
+			logger.Printf("[WEBHOOK] Failed to query delivery attempts: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+			return
+		}
+	}
+
+	items := make([]WebhookDeliveryResponse, 0, len(rows))
+	for _, row := range rows {
+		item := WebhookDeliveryResponse{
+			ID:             fmt.Sprintf("%d", row.ID),
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			Status:         row.Status,
+			Attempts:       row.Attempts,
+			LastStatusCode: nullableInt64(row.LastStatusCode),
+			LastError:      row.LastError.String,
+			Payload:        parseJSONRaw(row.Payload.String),
+			AttemptLog:     attemptsByDelivery[row.ID],
+		}
+		if item.AttemptLog == nil {
+			item.AttemptLog = []WebhookDeliveryAttemptResponse{}
+		}
+		if row.NextAttemptAt.Valid && row.Status == services.WebhookDeliveryPending {
+			item.NextAttemptAt = row.NextAttemptAt.Time.UTC().Format(time.RFC3339)
+		}
+		if row.DeliveredAt.Valid {
+			item.DeliveredAt = row.DeliveredAt.Time.UTC().Format(time.RFC3339)
+		}
+		if row.CreatedAt.Valid {
+			item.CreatedAt = row.CreatedAt.Time.UTC().Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, WebhookDeliveryListResponse{
+		Items:   items,
+		Total:   total,
+		Limit:   pagination.Limit,
+		Offset:  pagination.Offset,
+		HasNext: pagination.Offset+pagination.Limit < total,
+		HasPrev: pagination.Offset > 0,
+	})
+}
+
+// RetryWebhookDelivery requeues a dead-lettered delivery for immediate delivery
+// with a fresh attempt budget.
+//
+// @Summary      Retry webhook delivery
+// @Description  Requeues a dead webhook delivery
+// @Tags         webhooks
+// @Param        id           path  string  true  "Webhook ID"
+// @Param        delivery_id  path  string  true  "Delivery ID"
+// @Success      204
+// @Failure      400  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) RetryWebhookDelivery(c *gin.Context) {
+	id, ok := parseWebhookID(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	var status string
+	err = h.db.Get(&status, "SELECT status FROM webhook_deliveries WHERE id = ? AND subscription_id = ?", deliveryID, id)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return
+	}
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to query delivery %d: %v", deliveryID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retry delivery"})
+		return
+	}
+	if status != services.WebhookDeliveryDead {
+		c.JSON(http.StatusConflict, gin.H{"error": "only dead deliveries can be retried"})
+		return
+	}
+
+	now := time.Now().UTC()
+	if _, err := h.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, services.WebhookDeliveryPending, now, now, deliveryID, services.WebhookDeliveryDead); err != nil {
+		logger.Printf("[WEBHOOK] Failed to requeue delivery %d: %v", deliveryID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retry delivery"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
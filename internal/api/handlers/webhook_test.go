@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+func setupWebhookTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range []string{
+		`CREATE TABLE webhook_subscriptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			event_types TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			subscription_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL,
+			last_status_code INTEGER NULL,
+			last_error TEXT,
+			delivered_at TIMESTAMP NULL,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP
+		)`,
+		`CREATE TABLE webhook_delivery_attempts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			delivery_id INTEGER NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER NULL,
+			error TEXT,
+			duration_ms INTEGER NOT NULL,
+			attempted_at TIMESTAMP NOT NULL
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("create webhook schema: %v", err)
+		}
+	}
+	return db
+}
+
+func TestWebhookCRUDAndDeliveryLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupWebhookTestDB(t)
+	router := gin.New()
+	NewWebhookHandler(db).RegisterRoutes(router.Group("/api/v1"))
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, body := range []string{
+		`{"name":"x","url":"ftp://example.com","event_types":["sync.completed"]}`,
+		`{"name":"x","url":"https://example.com/hook","event_types":["sync.unknown"]}`,
+		`{"name":"x","url":"https://example.com/hook","event_types":[]}`,
+		`{"name":"x","url":"https://example.com/hook","event_types":["sync.failed"],"secret":"short"}`,
+	} {
+		if rec := do(http.MethodPost, "/api/v1/admin/webhooks", body); rec.Code != http.StatusBadRequest {
+			t.Fatalf("create %s = %d, want 400", body, rec.Code)
+		}
+	}
+
+	rec := do(http.MethodPost, "/api/v1/admin/webhooks", `{"name":"ops","url":"https://example.com/hook","event_types":["sync.failed","task.status_changed","sync.failed"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create = %d %s", rec.Code, rec.Body.String())
+	}
+	var created WebhookResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if len(created.Secret) != 64 || len(created.EventTypes) != 2 || created.EventTypes[0] != "sync.failed" || !created.Enabled {
+		t.Fatalf("created = %+v", created)
+	}
+
+	rec = do(http.MethodPut, "/api/v1/admin/webhooks/"+created.ID, `{"enabled":false,"event_types":["device.offline"]}`)
+	var updated WebhookResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &updated) != nil {
+		t.Fatalf("update = %d %s", rec.Code, rec.Body.String())
+	}
+	if updated.Enabled || updated.Secret != "" || len(updated.EventTypes) != 1 || updated.EventTypes[0] != "device.offline" {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	now := time.Now().UTC()
+	if _, err := db.Exec(`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error)
+		VALUES (?, 'evt-1', 'device.offline', '{"id":"evt-1"}', 'dead', 2, ?, 500, 'unexpected status 500')`, created.ID, now); err != nil {
+		t.Fatalf("insert delivery: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES (1, 1, NULL, 'connection refused', 3, ?), (1, 2, 500, 'unexpected status 500', 4, ?)`, now, now); err != nil {
+		t.Fatalf("insert attempts: %v", err)
+	}
+	rec = do(http.MethodGet, "/api/v1/admin/webhooks/"+created.ID+"/deliveries?status=dead", "")
+	var deliveries WebhookDeliveryListResponse
+	if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &deliveries) != nil {
+		t.Fatalf("deliveries = %d %s", rec.Code, rec.Body.String())
+	}
+	if deliveries.Total != 1 || len(deliveries.Items[0].AttemptLog) != 2 || deliveries.Items[0].AttemptLog[0].StatusCode != nil || *deliveries.Items[0].LastStatusCode != 500 {
+		t.Fatalf("deliveries = %+v", deliveries)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/webhooks/"+created.ID+"/deliveries?status=bogus", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad status filter = %d", rec.Code)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/admin/webhooks/"+created.ID+"/deliveries/1/retry", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("retry = %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/webhooks/"+created.ID+"/deliveries/1/retry", ""); rec.Code != http.StatusConflict {
+		t.Fatalf("retry pending = %d, want 409", rec.Code)
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/webhooks/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/admin/webhooks/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted = %d, want 404", rec.Code)
+	}
+}
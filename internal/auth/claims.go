@@ -5,13 +5,22 @@
 // Package auth provides JWT claim types and helpers for authentication.
 package auth
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RoleAPIKey is the role of claims derived from an API key rather than a JWT.
+const RoleAPIKey = "api_key"
 
 // Claims represents JWT claims for collector authentication.
 type Claims struct {
 	CollectorID int64  `json:"collector_id"`
 	OperatorID  string `json:"operator_id"`
 	Role        string `json:"role"`
+	// Scopes lists the permissions of an API key; unused for JWT roles.
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -31,3 +40,16 @@ func NewAdminClaims() *Claims {
 		Role: "admin",
 	}
 }
+
+// NewAPIKeyClaims creates claims for a request authenticated by an API key.
+// OperatorID identifies the key so audit trails and rate limits attribute
+// requests to it.
+func NewAPIKeyClaims(keyID int64, scopes []string) *Claims {
+	id := fmt.Sprintf("api_key:%d", keyID)
+	return &Claims{
+		OperatorID:       id,
+		Role:             RoleAPIKey,
+		Scopes:           scopes,
+		RegisteredClaims: jwt.RegisteredClaims{Subject: id},
+	}
+}
@@ -0,0 +1,246 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// bandwidthChunkBytes is the largest read a throttled reader hands out at
+	// once, so rate changes take effect within a part.
+	bandwidthChunkBytes = 32 * 1024
+	// bandwidthPollInterval is how often WaitAllowed re-checks the policy.
+	bandwidthPollInterval = time.Second
+)
+
+// BandwidthPolicy reports whether uploads may start at t and the upload rate
+// in bytes per second (0 = unlimited). The rate also applies to parts already
+// in flight when uploads stop being allowed.
+type BandwidthPolicy func(t time.Time) (allowed bool, bytesPerSec int64)
+
+// BandwidthLimiter is a token-bucket byte-rate limiter shared by all uploads
+// of a process. Its policy is consulted on every wait, so rate and schedule
+// changes apply without recreating uploaders. The bucket holds one second of
+// traffic.
+type BandwidthLimiter struct {
+	policy BandwidthPolicy
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+
+	mu     sync.Mutex
+	rate   int64
+	tokens float64
+	last   time.Time
+}
+
+// NewBandwidthLimiter creates a limiter governed by policy.
+func NewBandwidthLimiter(policy BandwidthPolicy) *BandwidthLimiter {
+	return &BandwidthLimiter{policy: policy, now: time.Now, sleep: sleepContext}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitAllowed blocks until the policy allows uploads to start. Callers use it
+// between parts so that a closed window never stalls an open HTTP request.
+func (l *BandwidthLimiter) WaitAllowed(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if allowed, _ := l.policy(l.now()); allowed {
+			return nil
+		}
+		if err := l.sleep(ctx, bandwidthPollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitN blocks until n more bytes may be sent.
+func (l *BandwidthLimiter) WaitN(ctx context.Context, n int) error {
+	for n > 0 {
+		chunk := n
+		if chunk > bandwidthChunkBytes {
+			chunk = bandwidthChunkBytes
+		}
+		wait, limited := l.reserve(chunk)
+		if !limited {
+			return ctx.Err()
+		}
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// reserve takes n tokens, possibly going into debt, and returns how long the
+// caller must wait for the debt to be repaid.
+func (l *BandwidthLimiter) reserve(n int) (time.Duration, bool) {
+	now := l.now()
+	_, rate := l.policy(now)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if rate <= 0 {
+		l.rate = 0
+		return 0, false
+	}
+	burst := float64(rate)
+	if l.rate == 0 || l.last.IsZero() {
+		// Coming from unlimited: start with a full bucket.
+		l.tokens = burst
+	} else if elapsed := now.Sub(l.last).Seconds(); elapsed > 0 {
+		l.tokens = math.Min(burst, l.tokens+elapsed*float64(rate))
+	}
+	l.tokens = math.Min(l.tokens, burst)
+	l.rate = rate
+	l.last = now
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0, true
+	}
+	return time.Duration(-l.tokens / float64(rate) * float64(time.Second)), true
+}
+
+// Reader returns a reader that paces reads from r through the limiter.
+func (l *BandwidthLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &throttledReader{ctx: ctx, r: r, limiter: l}
+}
+
+// errThrottledRequestTimeout is the cancel cause of a request that ran past
+// its timeout outside bandwidth waits.
+var errThrottledRequestTimeout = fmt.Errorf("request timeout excluding bandwidth waits: %w", context.DeadlineExceeded)
+
+// RequestBody paces body through the limiter for one HTTP request and returns
+// the context to send it with. The context is cancelled once the request has
+// run for timeout, not counting the time spent waiting for the limiter: the
+// bucket is shared by every upload, so how long a part is held back depends on
+// the other uploads and cannot be folded into a fixed network timeout.
+// Requests sent this way must not also set http.Client.Timeout. release stops
+// the deadline and must be called once the response has been consumed. A
+// timeout of 0 disables the deadline.
+func (l *BandwidthLimiter) RequestBody(ctx context.Context, body io.Reader, timeout time.Duration) (reqCtx context.Context, r io.Reader, release func()) {
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	reader := &throttledReader{ctx: reqCtx, r: body, limiter: l}
+	if timeout <= 0 {
+		return reqCtx, reader, func() { cancel(context.Canceled) }
+	}
+	d := &throttleDeadline{reader: reader, timeout: timeout, start: l.now(), cancel: cancel}
+	d.mu.Lock()
+	d.timer = time.AfterFunc(timeout, d.check)
+	d.mu.Unlock()
+	return reqCtx, reader, d.release
+}
+
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *BandwidthLimiter
+	// waited is the time spent in finished WaitN calls and waitStart the
+	// start of the current one (0 when not waiting), in nanoseconds.
+	waited    atomic.Int64
+	waitStart atomic.Int64
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > bandwidthChunkBytes {
+		p = p[:bandwidthChunkBytes]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		start := t.limiter.now()
+		t.waitStart.Store(start.UnixNano())
+		waitErr := t.limiter.WaitN(t.ctx, n)
+		t.waited.Add(int64(t.limiter.now().Sub(start)))
+		t.waitStart.Store(0)
+		if waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// throttleDeadline cancels a throttled request once its elapsed time minus
+// its bandwidth waits reaches timeout, re-arming itself while waits push the
+// deadline out.
+type throttleDeadline struct {
+	reader  *throttledReader
+	timeout time.Duration
+	start   time.Time
+	cancel  context.CancelCauseFunc
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	released bool
+}
+
+func (d *throttleDeadline) check() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.released {
+		return
+	}
+	now := d.reader.limiter.now()
+	waited := time.Duration(d.reader.waited.Load())
+	if start := d.reader.waitStart.Load(); start != 0 {
+		waited += now.Sub(time.Unix(0, start))
+	}
+	remaining := d.timeout + waited - now.Sub(d.start)
+	if remaining <= 0 {
+		d.cancel(errThrottledRequestTimeout)
+		return
+	}
+	d.timer = time.AfterFunc(remaining, d.check)
+}
+
+func (d *throttleDeadline) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.released = true
+	d.timer.Stop()
+	d.cancel(context.Canceled)
+}
+
+// releaseOnClose calls release once the response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
+
+// untimedClient returns a copy of client without its overall timeout, for
+// requests whose deadline comes from RequestBody.
+func untimedClient(client *http.Client) *http.Client {
+	c := *client
+	c.Timeout = 0
+	return &c
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeBandwidthClock advances virtual time whenever the limiter sleeps.
+type fakeBandwidthClock struct {
+	now   time.Time
+	slept time.Duration
+}
+
+func newTestBandwidthLimiter(policy BandwidthPolicy) (*BandwidthLimiter, *fakeBandwidthClock) {
+	clock := &fakeBandwidthClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	l := NewBandwidthLimiter(policy)
+	l.now = func() time.Time { return clock.now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		clock.now = clock.now.Add(d)
+		clock.slept += d
+		return nil
+	}
+	return l, clock
+}
+
+func TestBandwidthLimiterPacesReads(t *testing.T) {
+	const rate = 64 * 1024
+	l, clock := newTestBandwidthLimiter(func(time.Time) (bool, int64) { return true, rate })
+
+	data := bytes.Repeat([]byte{1}, 4*rate)
+	n, err := io.Copy(io.Discard, l.Reader(context.Background(), bytes.NewReader(data)))
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if n != int64(len(data)) {
+		t.Fatalf("copied %d bytes, want %d", n, len(data))
+	}
+	// The first second of traffic is covered by the initial burst.
+	if clock.slept < 3*time.Second-time.Millisecond || clock.slept > 3*time.Second+time.Millisecond {
+		t.Fatalf("slept %v, want about 3s", clock.slept)
+	}
+}
+
+func TestBandwidthLimiterUnlimitedDoesNotSleep(t *testing.T) {
+	l, clock := newTestBandwidthLimiter(func(time.Time) (bool, int64) { return true, 0 })
+
+	if err := l.WaitN(context.Background(), 10*1024*1024); err != nil {
+		t.Fatalf("WaitN: %v", err)
+	}
+	if clock.slept != 0 {
+		t.Fatalf("slept %v, want 0", clock.slept)
+	}
+}
+
+func TestBandwidthLimiterWaitAllowed(t *testing.T) {
+	opensAt := time.Date(2026, 1, 1, 12, 0, 5, 0, time.UTC)
+	l, clock := newTestBandwidthLimiter(func(t time.Time) (bool, int64) { return !t.Before(opensAt), 0 })
+
+	if err := l.WaitAllowed(context.Background()); err != nil {
+		t.Fatalf("WaitAllowed: %v", err)
+	}
+	if clock.now.Before(opensAt) {
+		t.Fatalf("WaitAllowed returned at %v, before window opened at %v", clock.now, opensAt)
+	}
+
+	closed, _ := newTestBandwidthLimiter(func(time.Time) (bool, int64) { return false, 0 })
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := closed.WaitAllowed(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitAllowed error = %v, want context.Canceled", err)
+	}
+}
+
+func TestBandwidthRequestBodyExcludesWaitsFromTimeout(t *testing.T) {
+	const rate = 64 * 1024
+	l := NewBandwidthLimiter(func(time.Time) (bool, int64) { return true, rate })
+
+	// The burst covers the first 64 KiB; the rest waits about 500ms, well
+	// past the 150ms timeout.
+	ctx, body, release := l.RequestBody(context.Background(), bytes.NewReader(make([]byte, rate+rate/2)), 150*time.Millisecond)
+	defer release()
+	start := time.Now()
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Fatalf("copy took %v, want the limiter to hold it back", elapsed)
+	}
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("request context cancelled while paced: %v", context.Cause(ctx))
+	}
+}
+
+func TestBandwidthRequestBodyTimesOutWithoutWaits(t *testing.T) {
+	l := NewBandwidthLimiter(func(time.Time) (bool, int64) { return true, 0 })
+
+	ctx, _, release := l.RequestBody(context.Background(), bytes.NewReader(nil), 50*time.Millisecond)
+	defer release()
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("request context not cancelled after its timeout")
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, context.DeadlineExceeded) {
+		t.Fatalf("cause = %v, want a deadline", cause)
+	}
+}
@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package cloud
+
+import "context"
+
+// Destination is a cloud target that episode objects are synced to. Each
+// Upload streams one object from edge MinIO (UploadRequest.McapKey) to the
+// target and reports where it landed.
+//
+// Uploader implements it for the data-platform gateway with Aliyun OSS, and
+// S3Uploader for any S3-compatible bucket.
+type Destination interface {
+	Upload(ctx context.Context, req UploadRequest) (*UploadResult, error)
+}
+
+var (
+	_ Destination = (*Uploader)(nil)
+	_ Destination = (*S3Uploader)(nil)
+)
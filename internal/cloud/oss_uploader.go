@@ -39,6 +39,8 @@ var ErrOSSETagMissing = errors.New("oss head object returned no ETag header")
 // This is a direct Go translation of data-platform/aliyun/ram/src/oss.rs.
 type OSSUploader struct {
 	httpClient *http.Client
+	// bandwidth throttles request bodies of part uploads when set.
+	bandwidth *BandwidthLimiter
 }
 
 // UploadedPart tracks a successfully uploaded OSS multipart part.
@@ -201,12 +203,20 @@ func (u *OSSUploader) sendRequest(ctx context.Context, session *UploadSession, m
 	requestURL := buildRequestURL(session.Endpoint, session.Bucket, objectKey, query)
 
 	var bodyReader io.Reader
+	reqCtx, client, release := ctx, u.httpClient, func() {}
 	if body != nil {
 		bodyReader = bytes.NewReader(body)
+		if u.bandwidth != nil && method == http.MethodPut {
+			// Time held back by the shared limiter does not count against
+			// the part's timeout.
+			reqCtx, bodyReader, release = u.bandwidth.RequestBody(ctx, bodyReader, u.httpClient.Timeout)
+			client = untimedClient(u.httpClient)
+		}
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+	req, err := http.NewRequestWithContext(reqCtx, method, requestURL, bodyReader)
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	if body != nil {
@@ -223,8 +233,12 @@ func (u *OSSUploader) sendRequest(ctx context.Context, session *UploadSession, m
 	}
 
 	startedAt := time.Now()
-	resp, err := u.httpClient.Do(req) //nolint:gosec // G704: URL is OSS endpoint from authenticated DataGateway gRPC upload session
+	resp, err := client.Do(req) //nolint:gosec // G704: URL is OSS endpoint from authenticated DataGateway gRPC upload session
 	if err != nil {
+		release()
+		if cause := context.Cause(reqCtx); errors.Is(cause, errThrottledRequestTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
 		if isTimeoutError(err) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			timeout := timeoutDuration(ctx, startedAt, u.httpClient.Timeout)
 			logger.Printf("[OSS] %s %s timeout after %s (timeout_ms=%d): %v", method, objectKey, timeoutLogValue(timeout), timeoutLogMilliseconds(timeout), err)
@@ -233,6 +247,7 @@ func (u *OSSUploader) sendRequest(ctx context.Context, session *UploadSession, m
 		return nil, fmt.Errorf("http request: %w", err)
 	}
 
+	resp.Body = releaseOnClose{ReadCloser: resp.Body, release: release}
 	if resp.StatusCode == http.StatusNotFound {
 		_ = resp.Body.Close()
 		return nil, ErrOSSNotFound
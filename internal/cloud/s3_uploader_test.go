@@ -0,0 +1,334 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //#nosec G501 -- S3 part ETags are MD5 digests
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an in-memory path-style S3 endpoint implementing the multipart
+// calls used by S3Uploader.
+type fakeS3 struct {
+	t      *testing.T
+	bucket string
+
+	mu        sync.Mutex
+	uploads   map[string]*fakeS3Upload // by upload ID
+	objects   map[string][]byte
+	partPuts  []int
+	nextID    int
+	authFails int
+}
+
+type fakeS3Upload struct {
+	key       string
+	initiated time.Time
+	parts     map[int][]byte
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{t: t, bucket: bucket, uploads: map[string]*fakeS3Upload{}, objects: map[string][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) addUpload(key string, parts map[int][]byte) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	id := fmt.Sprintf("upload-%d", f.nextID)
+	f.uploads[id] = &fakeS3Upload{key: key, initiated: time.Date(2026, 1, 1, 0, 0, f.nextID, 0, time.UTC), parts: parts}
+	return id
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKTEST/") || r.Header.Get("X-Amz-Content-Sha256") != hexSHA256(body) {
+		f.authFails++
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+	q := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && q.Has("uploads"):
+		result := listMultipartUploadsResult{}
+		for id, up := range f.uploads {
+			if strings.HasPrefix(up.key, q.Get("prefix")) {
+				result.Uploads = append(result.Uploads, s3MultipartUpload{Key: up.key, UploadID: id, Initiated: up.initiated})
+			}
+		}
+		writeFakeS3XML(w, result)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.nextID++
+		id := fmt.Sprintf("upload-%d", f.nextID)
+		f.uploads[id] = &fakeS3Upload{key: key, initiated: time.Now(), parts: map[int][]byte{}}
+		writeFakeS3XML(w, initiateMultipartUploadResult{UploadID: id})
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		up, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		up.parts[n] = body
+		f.partPuts = append(f.partPuts, n)
+		w.Header().Set("ETag", fakeS3ETag(body))
+	case r.Method == http.MethodGet && q.Has("uploadId"):
+		up, ok := f.uploads[q.Get("uploadId")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		result := listPartsResult{}
+		numbers := make([]int, 0, len(up.parts))
+		for n := range up.parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		for _, n := range numbers {
+			result.Parts = append(result.Parts, struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+				Size       int64  `xml:"Size"`
+			}{n, fakeS3ETag(up.parts[n]), int64(len(up.parts[n]))})
+		}
+		writeFakeS3XML(w, result)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		id := q.Get("uploadId")
+		up, ok := f.uploads[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		if err := xml.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var object []byte
+		for _, p := range req.Parts {
+			data := up.parts[p.PartNumber]
+			if fakeS3ETag(data) != p.ETag {
+				writeFakeS3XML(w, s3ErrorResponse{Code: "InvalidPart", Message: "etag mismatch"})
+				return
+			}
+			object = append(object, data...)
+		}
+		f.objects[up.key] = object
+		delete(f.uploads, id)
+		writeFakeS3XML(w, completeMultipartUploadResult{ETag: `"final"`})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeFakeS3XML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(v)
+}
+
+func fakeS3ETag(data []byte) string {
+	sum := md5.Sum(data) //#nosec G401 -- S3 part ETags are MD5 digests
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func newTestS3Uploader(endpoint, bucket string) *S3Uploader {
+	return &S3Uploader{
+		cfg: S3UploaderConfig{
+			Endpoint:        endpoint,
+			Region:          "us-east-1",
+			AccessKeyID:     "AKTEST",
+			SecretAccessKey: "secret",
+			PathStyle:       true,
+			Bucket:          bucket,
+			KeyPrefix:       "factory-a/",
+			PartSizeBytes:   4,
+		},
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		now:        time.Now,
+	}
+}
+
+func bytesPartStream(payload []byte) partStreamFactory {
+	return func(_ context.Context, offset, length int64) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload[offset : offset+length])), nil
+	}
+}
+
+func TestS3UploaderUploadsMultipartObject(t *testing.T) {
+	fake, srv := newFakeS3(t, "edge-factory-a")
+	u := newTestS3Uploader(srv.URL, "edge-factory-a")
+	payload := []byte("0123456789")
+	var lastProgress int64
+
+	result, err := u.uploadObject(context.Background(), "factory-a/dev 1/task(1).mcap", int64(len(payload)), bytesPartStream(payload), func(uploaded, _ int64) {
+		lastProgress = uploaded
+	})
+	if err != nil {
+		t.Fatalf("uploadObject() error = %v", err)
+	}
+	if fake.authFails != 0 {
+		t.Fatalf("requests with bad signature headers = %d", fake.authFails)
+	}
+	if got := fake.objects["factory-a/dev 1/task(1).mcap"]; !bytes.Equal(got, payload) {
+		t.Fatalf("stored object = %q, want %q", got, payload)
+	}
+	if len(fake.partPuts) != 3 {
+		t.Fatalf("part uploads = %v, want 3 parts", fake.partPuts)
+	}
+	if result.Bucket != "edge-factory-a" || result.ObjectKey != "factory-a/dev 1/task(1).mcap" || result.FileSize != 10 || result.OSSObjectETag != `"final"` {
+		t.Fatalf("result = %+v", result)
+	}
+	if lastProgress != 10 {
+		t.Fatalf("last progress = %d, want 10", lastProgress)
+	}
+	if want := sha256.Sum256(payload); result.SHA256 != hex.EncodeToString(want[:]) {
+		t.Fatalf("sha256 = %q, want digest of the payload", result.SHA256)
+	}
+}
+
+func TestS3UploaderResumesThroughListParts(t *testing.T) {
+	fake, srv := newFakeS3(t, "edge-factory-a")
+	u := newTestS3Uploader(srv.URL, "edge-factory-a")
+	payload := []byte("0123456789")
+	key := "factory-a/dev/task.mcap"
+
+	fake.addUpload("factory-a/dev/task.mcap.json", map[int][]byte{1: []byte("zzzz")})
+	// Part 1 is intact, part 2 was cut short by the interruption.
+	id := fake.addUpload(key, map[int][]byte{1: []byte("0123"), 2: []byte("45")})
+
+	result, err := u.uploadObject(context.Background(), key, int64(len(payload)), bytesPartStream(payload), nil)
+	if err != nil {
+		t.Fatalf("uploadObject() error = %v", err)
+	}
+	if got := fake.objects[key]; !bytes.Equal(got, payload) {
+		t.Fatalf("stored object = %q, want %q", got, payload)
+	}
+	// Reused parts are still read and hashed.
+	if want := sha256.Sum256(payload); result.SHA256 != hex.EncodeToString(want[:]) {
+		t.Fatalf("sha256 = %q, want digest of the whole payload", result.SHA256)
+	}
+	if len(fake.partPuts) != 2 || fake.partPuts[0] != 2 || fake.partPuts[1] != 3 {
+		t.Fatalf("part uploads = %v, want [2 3]", fake.partPuts)
+	}
+	if _, ok := fake.uploads[id]; ok {
+		t.Fatalf("resumed upload %s was not completed", id)
+	}
+	if len(fake.uploads) != 1 {
+		t.Fatalf("open uploads = %d, want only the unrelated one", len(fake.uploads))
+	}
+}
+
+func TestS3UploaderKeepsMultipartUploadOnFailure(t *testing.T) {
+	fake, srv := newFakeS3(t, "edge-factory-a")
+	u := newTestS3Uploader(srv.URL, "edge-factory-a")
+	payload := []byte("0123456789")
+	failing := func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		if offset >= 8 {
+			return nil, fmt.Errorf("minio unavailable")
+		}
+		return bytesPartStream(payload)(ctx, offset, length)
+	}
+
+	if _, err := u.uploadObject(context.Background(), "factory-a/dev/task.mcap", int64(len(payload)), failing, nil); err == nil {
+		t.Fatal("uploadObject() error = nil, want read failure")
+	}
+	if len(fake.uploads) != 1 {
+		t.Fatalf("open uploads = %d, want the interrupted upload kept for resume", len(fake.uploads))
+	}
+
+	fake.partPuts = nil
+	if _, err := u.uploadObject(context.Background(), "factory-a/dev/task.mcap", int64(len(payload)), bytesPartStream(payload), nil); err != nil {
+		t.Fatalf("resumed uploadObject() error = %v", err)
+	}
+	if len(fake.partPuts) != 1 || fake.partPuts[0] != 3 {
+		t.Fatalf("resumed part uploads = %v, want [3]", fake.partPuts)
+	}
+}
+
+func TestS3UploaderRequestURL(t *testing.T) {
+	u := &S3Uploader{cfg: S3UploaderConfig{Region: "eu-west-1", UseTLS: true, Bucket: "edge-factory-a"}}
+	got, err := u.requestURL("factory-a/a b.mcap", []queryParam{{Key: "uploads"}})
+	if err != nil {
+		t.Fatalf("requestURL() error = %v", err)
+	}
+	if got.String() != "https://edge-factory-a.s3.eu-west-1.amazonaws.com/factory-a/a%20b.mcap?uploads=" {
+		t.Fatalf("virtual-hosted URL = %s", got.String())
+	}
+
+	u.cfg.Endpoint = "http://ceph.local:7480"
+	u.cfg.PathStyle = true
+	got, err = u.requestURL("k", nil)
+	if err != nil {
+		t.Fatalf("requestURL() error = %v", err)
+	}
+	if got.String() != "http://ceph.local:7480/edge-factory-a/k" {
+		t.Fatalf("path-style URL = %s", got.String())
+	}
+}
+
+func TestS3PartSizeStaysWithinPartLimit(t *testing.T) {
+	if got := s3PartSize(100*1024*1024, DefaultS3PartSizeBytes); got != DefaultS3PartSizeBytes {
+		t.Fatalf("part size = %d, want default", got)
+	}
+	size := int64(200) * 1024 * 1024 * 1024
+	got := s3PartSize(size, DefaultS3PartSizeBytes)
+	if (size+got-1)/got > s3MaxParts || got%(1024*1024) != 0 {
+		t.Fatalf("part size %d for %d bytes exceeds the part limit or is not MiB aligned", got, size)
+	}
+}
+
+func TestNewS3UploaderValidatesConfig(t *testing.T) {
+	valid := S3UploaderConfig{Region: "us-east-1", AccessKeyID: "a", SecretAccessKey: "b", Bucket: "bucket"}
+	u, err := NewS3Uploader(nil, "edge", valid)
+	if err != nil {
+		t.Fatalf("NewS3Uploader() error = %v", err)
+	}
+	if u.cfg.PartSizeBytes != DefaultS3PartSizeBytes {
+		t.Fatalf("part size = %d, want default", u.cfg.PartSizeBytes)
+	}
+	small := valid
+	small.PartSizeBytes = 1024
+	if _, err := NewS3Uploader(nil, "edge", small); err == nil {
+		t.Fatal("NewS3Uploader() accepted a part size below the S3 minimum")
+	}
+	noBucket := valid
+	noBucket.Bucket = ""
+	if _, err := NewS3Uploader(nil, "edge", noBucket); err == nil {
+		t.Fatal("NewS3Uploader() accepted an empty bucket")
+	}
+}
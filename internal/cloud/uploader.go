@@ -6,9 +6,12 @@ package cloud
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"math"
 	"os"
@@ -27,7 +30,8 @@ import (
 type UploaderConfig struct {
 	// RequestTimeout is the per-RPC/HTTP deadline for gateway calls.
 	RequestTimeout time.Duration
-	// OSSTimeout is the HTTP timeout for individual OSS part uploads.
+	// OSSTimeout is the HTTP timeout for individual OSS part uploads; time a
+	// part spends held back by Bandwidth does not count against it.
 	OSSTimeout time.Duration
 	// PersistRootDir is the directory used to persist active upload state for recovery.
 	// If empty, persistence is disabled.
@@ -35,6 +39,10 @@ type UploaderConfig struct {
 	// MaxRestartCount limits the number of times an upload may be restarted before permanent failure.
 	// Defaults to 3 if zero.
 	MaxRestartCount uint32
+	// Bandwidth throttles part uploads and holds new parts while uploads are
+	// not allowed. It is shared by all uploaders of a process; nil disables
+	// throttling.
+	Bandwidth *BandwidthLimiter
 }
 
 // UploadProgressFunc is called after bytes are uploaded successfully.
@@ -64,6 +72,10 @@ type UploadResult struct {
 	ObjectKey       string
 	FileSize        int64
 	OSSObjectETag   string
+	// SHA256 is the hex-encoded SHA-256 of the bytes read from MinIO during
+	// the upload. It is empty when the object was already present at the
+	// destination and no bytes were transferred.
+	SHA256 string
 }
 
 // persistedUploadState is the JSON-serialisable snapshot written to disk for recovery.
@@ -149,9 +161,11 @@ func NewUploader(gateway *GatewayClient, minioClient *s3.Client, minioBucket str
 	if cfg.MaxRestartCount == 0 {
 		cfg.MaxRestartCount = 3
 	}
+	oss := NewOSSUploader(cfg.OSSTimeout)
+	oss.bandwidth = cfg.Bandwidth
 	u := &Uploader{
 		gateway:     gateway,
-		oss:         NewOSSUploader(cfg.OSSTimeout),
+		oss:         oss,
 		minioClient: minioClient,
 		minioBucket: minioBucket,
 		cfg:         cfg,
@@ -293,7 +307,8 @@ func (u *Uploader) Upload(ctx context.Context, req UploadRequest) (*UploadResult
 	// InitiateMultipartUpload succeeds, before streaming any parts. This requires splitting
 	// uploadParts into an initiate step (called here, result persisted) and a stream step.
 	// The Rust SDK has the same gap; defer fixing until the upstream SDK is updated.
-	session, multipartUploadID, parts, partMD5s, err := u.uploadParts(ctx, req, session, fileSize)
+	digest := sha256.New()
+	session, multipartUploadID, parts, partMD5s, err := u.uploadParts(ctx, req, session, fileSize, digest)
 	if err != nil {
 		return nil, err
 	}
@@ -352,6 +367,7 @@ func (u *Uploader) Upload(ctx context.Context, req UploadRequest) (*UploadResult
 		ObjectKey:       session.ObjectKey,
 		FileSize:        fileSize,
 		OSSObjectETag:   localETag,
+		SHA256:          hex.EncodeToString(digest.Sum(nil)),
 	}, nil
 }
 
@@ -572,15 +588,35 @@ func (u *Uploader) reconcileCompletedObject(ctx context.Context, session *Upload
 // kept idle across part uploads.
 type partStreamFactory func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
 
+// hashPartStreams feeds every byte read through newPartStream to digest.
+// Uploads read each part once and in order, so after the last part digest
+// covers the whole object without a separate read.
+func hashPartStreams(newPartStream partStreamFactory, digest hash.Hash) partStreamFactory {
+	return func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		stream, err := newPartStream(ctx, offset, length)
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(stream, digest), stream}, nil
+	}
+}
+
 // minioRangeReader returns a partStreamFactory that reads byte ranges from
 // MinIO using independent ranged GetObject requests.
 func (u *Uploader) minioRangeReader(key string) partStreamFactory {
+	return minioRangeStream(u.minioClient, u.minioBucket, key)
+}
+
+func minioRangeStream(minioClient *s3.Client, bucket, key string) partStreamFactory {
 	return func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
 		opts := minio.GetObjectOptions{}
 		if err := opts.SetRange(offset, offset+length-1); err != nil {
 			return nil, fmt.Errorf("set range %d-%d: %w", offset, offset+length-1, err)
 		}
-		obj, err := u.minioClient.GetObject(ctx, u.minioBucket, key, opts)
+		obj, err := minioClient.GetObject(ctx, bucket, key, opts)
 		if err != nil {
 			return nil, fmt.Errorf("get minio object range %d-%d: %w", offset, offset+length-1, err)
 		}
@@ -588,9 +624,10 @@ func (u *Uploader) minioRangeReader(key string) partStreamFactory {
 	}
 }
 
-// uploadParts streams the MCAP from MinIO and uploads it to OSS in parts.
+// uploadParts streams the MCAP from MinIO and uploads it to OSS in parts,
+// feeding every byte read to digest.
 // Returns the OSS multipart upload ID, the list of uploaded parts, per-part MD5 digests, and any error.
-func (u *Uploader) uploadParts(ctx context.Context, req UploadRequest, session *UploadSession, fileSize int64) (*UploadSession, string, []UploadedPart, [][16]byte, error) {
+func (u *Uploader) uploadParts(ctx context.Context, req UploadRequest, session *UploadSession, fileSize int64, digest hash.Hash) (*UploadSession, string, []UploadedPart, [][16]byte, error) {
 	fixedPartSizeBytes := normalizedPartSizeBytes(session.PartSizeBytes)
 	session, err := u.ensureFreshUploadCredentials(ctx, session)
 	if err != nil {
@@ -610,7 +647,7 @@ func (u *Uploader) uploadParts(ctx context.Context, req UploadRequest, session *
 	// connection is not left idle during OSS part uploads. A single streaming
 	// response would risk idle connection timeout (~20-25s on MinIO or network
 	// intermediaries) when upload speed is slow.
-	session, parts, partMD5s, err := u.streamMultipartParts(ctx, req.EpisodeID, session, multipartUploadID, fileSize, fixedPartSizeBytes, u.minioRangeReader(req.McapKey), req.Progress)
+	session, parts, partMD5s, err := u.streamMultipartParts(ctx, req.EpisodeID, session, multipartUploadID, fileSize, fixedPartSizeBytes, hashPartStreams(u.minioRangeReader(req.McapKey), digest), req.Progress)
 	if err != nil {
 		u.abortMultipartUpload(session, multipartUploadID)
 		return nil, "", nil, nil, err
@@ -655,6 +692,11 @@ func (u *Uploader) streamMultipartParts(ctx context.Context, episodeID string, s
 		if err := ctx.Err(); err != nil {
 			return session, nil, nil, err
 		}
+		if u.cfg.Bandwidth != nil {
+			if err := u.cfg.Bandwidth.WaitAllowed(ctx); err != nil {
+				return session, nil, nil, fmt.Errorf("wait for sync window before part %d: %w", partNumber, err)
+			}
+		}
 
 		remaining := fileSize - offset
 		readSize := partSizeBytes
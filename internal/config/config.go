@@ -7,11 +7,14 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Config represents the complete configuration for Keystone Edge
@@ -24,6 +27,8 @@ type Config struct {
 	Auth         AuthConfig
 	Features     FeaturesConfig
 	Monitoring   MonitoringConfig
+	Webhooks     WebhookConfig
+	RateLimit    RateLimitConfig
 	Resources    ResourceLimitsConfig
 	AxonTransfer TransferConfig
 	AxonRecorder RecorderConfig
@@ -37,6 +42,9 @@ type ServerConfig struct {
 	ReadTimeout           int // seconds
 	WriteTimeout          int // seconds
 	ShutdownTimeout       int // seconds
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For is
+	// honored for the client IP. Empty trusts no proxy and uses the peer address.
+	TrustedProxies []string
 }
 
 // DatabaseConfig database configuration
@@ -58,13 +66,76 @@ type StorageConfig struct {
 	UseSSL    bool
 }
 
+// DefaultQAChecks is the documented KEYSTONE_QA_CHECKS default. The other
+// built-in checks are opt-in through KEYSTONE_QA_CHECKS or QA profiles.
+var DefaultQAChecks = []string{"topics", "duration", "gaps", "images"}
+
 // QAConfig QA engine configuration
 type QAConfig struct {
 	Enabled              bool
 	AutoApproveThreshold float64
 	MaxWorkers           int
 	TimeoutPerEpisode    int // seconds
-	Checks               []string
+	// Checks is the suite run for every episode; defaults to DefaultQAChecks.
+	Checks []string
+	// CheckWeights weights each check in the episode qa_score; unlisted checks weigh 1.
+	CheckWeights map[string]float64
+
+	// Message gap check: a topic fails when its effective rate drops below
+	// GapMinRateRatio of the expected rate or any gap exceeds GapMaxSeconds.
+	GapMinRateRatio float64
+	GapMaxSeconds   float64
+	// ExpectedTopicHz overrides the rate inferred from the recording, keyed by topic.
+	ExpectedTopicHz map[string]float64
+
+	// Image integrity check: frames decoded per CompressedImage topic and the
+	// decode failure rate tolerated before the topic fails. Frames whose
+	// header claims more than ImageMaxPixels pixels fail without decoding.
+	ImageSampleSize          int
+	ImageMaxFailureRate      float64
+	ImageTopicMaxFailureRate map[string]float64
+	ImageMaxPixels           int
+
+	// External QA scripts: executables must live under ScriptDir (empty
+	// disables script checks). The limits apply to scripts that set none.
+	ScriptDir           string
+	ScriptTimeoutSec    int
+	ScriptMemoryLimitMB int
+	ScriptCPULimitSec   int
+
+	// Timestamp check: log_time regressions tolerated per channel, maximum
+	// |log_time - publish_time| and maximum recording start offset from the
+	// task's started_at, in seconds.
+	TimestampMaxBackwardJumps  int
+	TimestampMaxPublishSkewSec float64
+	TimestampMaxStartOffsetSec float64
+
+	// Writer health check: recorder write-path counters above these maxima
+	// fail the episode, as do a critical recorder state or a reported error;
+	// WriterFailOnStall (default on) also fails suspected writer stalls.
+	// QA profile params override them per robot type.
+	WriterMaxPartialFailures int
+	WriterMaxQueueOverflows  int
+	WriterFailOnStall        bool
+
+	// Integrity scrubber: every ScrubIntervalSec, re-hash up to ScrubBatchSize
+	// stored MCAPs not verified in the last ScrubRecheckDays.
+	ScrubEnabled     bool
+	ScrubIntervalSec int
+	ScrubBatchSize   int
+	ScrubRecheckDays int
+
+	// Inspection queue: claimed episodes stay leased to one inspector for
+	// InspectionLeaseTTLSec unless renewed. Episodes scoring below
+	// InspectionSeniorScore are only assigned to senior inspectors.
+	InspectionLeaseTTLSec int
+	InspectionSeniorScore float64
+
+	// Audit sampling: when AuditEnabled, auto-approved episodes are sent to
+	// second review at the rate of the most specific qa_audit_policies row,
+	// or AuditSampleRate when no policy matches.
+	AuditEnabled    bool
+	AuditSampleRate float64
 }
 
 // SyncConfig synchronization configuration
@@ -91,6 +162,141 @@ type SyncConfig struct {
 	PersistRootDir     string // root directory for persisting upload state across restarts; empty disables persistence
 	MaxRestartCount    int    // max number of upload restarts before permanent failure; 0 uses uploader default (3)
 	DPConfigPath       string // data-platform config path for direct device-profile uploads
+
+	// Upload throttling; both can be changed at runtime through PUT /sync/config.
+	BandwidthLimitKBps int64  // global upload rate limit in KiB/s; 0 disables the limit
+	Schedule           string // allowed sync windows, e.g. "19:00-07:00,07:00-19:00@512"; empty allows sync at any time
+
+	// Destination selects where episodes are uploaded: SyncDestinationDataPlatform
+	// (default) or SyncDestinationS3, which uses the S3 settings below.
+	Destination string
+	FactoryID   string // edge factory ID substituted into S3.Target
+	S3          SyncS3Config
+}
+
+// Sync destinations.
+const (
+	SyncDestinationDataPlatform = "dataplatform"
+	SyncDestinationS3           = "s3"
+)
+
+// SyncS3Config configures the generic S3-compatible sync destination.
+type SyncS3Config struct {
+	Target       string // s3://bucket/prefix/; {factory_id} is replaced with the edge factory ID
+	Endpoint     string // empty uses AWS s3.<region>.amazonaws.com
+	Region       string
+	AccessKey    string `json:"-"`
+	SecretKey    string `json:"-"`
+	SessionToken string `json:"-"`
+	UseTLS       bool
+	PathStyle    bool // path-style addressing, needed by most non-AWS S3 servers
+	PartSizeMB   int  // multipart part size; at least 5
+}
+
+// factoryIDPlaceholder is replaced with the edge factory ID in S3 targets.
+const factoryIDPlaceholder = "{factory_id}"
+
+// ResolveTarget expands the {factory_id} placeholder in Target and splits it
+// into a bucket and a key prefix ending in "/" (or empty).
+func (c SyncS3Config) ResolveTarget(factoryID string) (bucket, prefix string, err error) {
+	raw := strings.TrimSpace(c.Target)
+	if !strings.HasPrefix(raw, "s3://") {
+		return "", "", fmt.Errorf("target %q must start with s3://", c.Target)
+	}
+	if strings.Contains(raw, factoryIDPlaceholder) && strings.TrimSpace(factoryID) == "" {
+		return "", "", fmt.Errorf("target %q uses %s but the factory ID is empty", c.Target, factoryIDPlaceholder)
+	}
+	raw = strings.ReplaceAll(strings.TrimPrefix(raw, "s3://"), factoryIDPlaceholder, strings.TrimSpace(factoryID))
+	bucket, prefix, _ = strings.Cut(raw, "/")
+	if !s3BucketNamePattern.MatchString(bucket) || strings.Contains(bucket, "..") {
+		return "", "", fmt.Errorf("target %q has invalid bucket name %q", c.Target, bucket)
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return bucket, prefix, nil
+}
+
+var s3BucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// MinSyncBandwidthLimitKBps is the lowest non-zero upload rate limit. Lower
+// rates cannot push an 8 MiB part within the OSS part timeout.
+const MinSyncBandwidthLimitKBps = 128
+
+// SyncWindow is a daily window of local time in which cloud sync may upload.
+// A window whose end is not after its start wraps past midnight.
+type SyncWindow struct {
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+	// BandwidthLimitKBps is the upload rate limit within the window in KiB/s;
+	// 0 applies the global limit.
+	BandwidthLimitKBps int64 `json:"bandwidth_limit_kbps"`
+}
+
+// ParseSyncSchedule parses a comma-separated list of HH:MM-HH:MM windows,
+// each optionally followed by @<KiB/s>.
+func ParseSyncSchedule(raw string) ([]SyncWindow, error) {
+	var windows []SyncWindow
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var w SyncWindow
+		span, limit, hasLimit := strings.Cut(item, "@")
+		if hasLimit {
+			v, err := strconv.ParseInt(strings.TrimSpace(limit), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("window %q: invalid bandwidth limit", item)
+			}
+			w.BandwidthLimitKBps = v
+		}
+		start, end, ok := strings.Cut(span, "-")
+		if !ok {
+			return nil, fmt.Errorf("window %q: expected HH:MM-HH:MM", item)
+		}
+		w.Start = strings.TrimSpace(start)
+		w.End = strings.TrimSpace(end)
+		if err := w.Validate(); err != nil {
+			return nil, fmt.Errorf("window %q: %w", item, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// Validate checks the window times and bandwidth limit.
+func (w SyncWindow) Validate() error {
+	start, err := ParseClockMinutes(w.Start)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	end, err := ParseClockMinutes(w.End)
+	if err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+	if start == end {
+		return fmt.Errorf("start and end must differ")
+	}
+	return ValidateSyncBandwidthLimit(w.BandwidthLimitKBps)
+}
+
+// ValidateSyncBandwidthLimit checks an upload rate limit in KiB/s.
+func ValidateSyncBandwidthLimit(kbps int64) error {
+	if kbps < 0 || (kbps > 0 && kbps < MinSyncBandwidthLimitKBps) {
+		return fmt.Errorf("bandwidth limit must be 0 (unlimited) or at least %d KiB/s", MinSyncBandwidthLimitKBps)
+	}
+	return nil
+}
+
+// ParseClockMinutes parses HH:MM into minutes after midnight.
+func ParseClockMinutes(v string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(v))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", v)
+	}
+	return t.Hour()*60 + t.Minute(), nil
 }
 
 // FeaturesConfig feature flags configuration
@@ -109,6 +315,39 @@ type MonitoringConfig struct {
 	HealthCheckInterval int // seconds
 	LogLevel            string
 	LogOutput           string
+
+	// API audit log: requests are written to api_logs in batches of up to
+	// APILogBatchSize or every APILogFlushIntervalSec, and rows older than
+	// APILogRetentionDays are purged.
+	APILogEnabled          bool
+	APILogBatchSize        int
+	APILogFlushIntervalSec int
+	APILogRetentionDays    int
+}
+
+// WebhookConfig outbound webhook delivery configuration
+type WebhookConfig struct {
+	Enabled      bool
+	IntervalSec  int // outbox poll interval in seconds
+	BatchSize    int // deliveries attempted per poll
+	TimeoutSec   int // per-request timeout in seconds
+	MaxAttempts  int // attempts before a delivery is dead-lettered
+	RetryBaseSec int // base retry backoff in seconds, doubled per attempt
+	RetryMaxSec  int // max retry backoff in seconds
+}
+
+// RateLimitConfig API rate limiting configuration. Each route group has its
+// own token buckets per client (JWT subject, API key, display token or IP):
+// reporting covers the production dashboard and statistics routes, device
+// covers Axon callbacks and device registration, default covers the rest.
+type RateLimitConfig struct {
+	Enabled        bool    // off by default; existing deployments opt in
+	DefaultRPS     float64 // sustained requests per second
+	DefaultBurst   int
+	ReportingRPS   float64
+	ReportingBurst int
+	DeviceRPS      float64
+	DeviceBurst    int
 }
 
 // ResourceLimitsConfig resource limits configuration
@@ -161,6 +400,7 @@ func Load() (*Config, error) {
 			ReadTimeout:           getEnvInt("KEYSTONE_READ_TIMEOUT", 30),
 			WriteTimeout:          getEnvInt("KEYSTONE_WRITE_TIMEOUT", 30),
 			ShutdownTimeout:       getEnvInt("KEYSTONE_SHUTDOWN_TIMEOUT", 10),
+			TrustedProxies:        getEnvList("KEYSTONE_TRUSTED_PROXIES", nil),
 		},
 		Database: DatabaseConfig{
 			Driver: "mysql",
@@ -183,11 +423,37 @@ func Load() (*Config, error) {
 			UseSSL:    getEnvBool("KEYSTONE_MINIO_USE_SSL", false),
 		},
 		QA: QAConfig{
-			Enabled:              getEnvBool("KEYSTONE_QA_ENABLED", true),
-			AutoApproveThreshold: getEnvFloat("KEYSTONE_QA_AUTO_APPROVE_THRESHOLD", 0.90),
-			MaxWorkers:           getEnvInt("KEYSTONE_QA_MAX_WORKERS", 4),
-			TimeoutPerEpisode:    getEnvInt("KEYSTONE_QA_TIMEOUT", 300),
-			Checks:               []string{"topics", "duration", "gaps", "images"},
+			Enabled:                    getEnvBool("KEYSTONE_QA_ENABLED", true),
+			AutoApproveThreshold:       getEnvFloat("KEYSTONE_QA_AUTO_APPROVE_THRESHOLD", 0.90),
+			MaxWorkers:                 getEnvInt("KEYSTONE_QA_MAX_WORKERS", 4),
+			TimeoutPerEpisode:          getEnvInt("KEYSTONE_QA_TIMEOUT", 300),
+			Checks:                     getEnvList("KEYSTONE_QA_CHECKS", append([]string(nil), DefaultQAChecks...)),
+			CheckWeights:               getEnvFloatMap("KEYSTONE_QA_CHECK_WEIGHTS"),
+			GapMinRateRatio:            getEnvFloat("KEYSTONE_QA_GAP_MIN_RATE_RATIO", 0.8),
+			GapMaxSeconds:              getEnvFloat("KEYSTONE_QA_GAP_MAX_SECONDS", 1.0),
+			ExpectedTopicHz:            getEnvFloatMap("KEYSTONE_QA_TOPIC_EXPECTED_HZ"),
+			ImageSampleSize:            getEnvInt("KEYSTONE_QA_IMAGE_SAMPLE_SIZE", 20),
+			ImageMaxFailureRate:        getEnvFloat("KEYSTONE_QA_IMAGE_MAX_FAILURE_RATE", 0),
+			ImageTopicMaxFailureRate:   getEnvFloatMap("KEYSTONE_QA_IMAGE_TOPIC_MAX_FAILURE_RATE"),
+			ImageMaxPixels:             getEnvInt("KEYSTONE_QA_IMAGE_MAX_PIXELS", 40000000),
+			ScriptDir:                  getEnv("KEYSTONE_QA_SCRIPT_DIR", ""),
+			ScriptTimeoutSec:           getEnvInt("KEYSTONE_QA_SCRIPT_TIMEOUT", 120),
+			ScriptMemoryLimitMB:        getEnvInt("KEYSTONE_QA_SCRIPT_MEMORY_LIMIT_MB", 2048),
+			ScriptCPULimitSec:          getEnvInt("KEYSTONE_QA_SCRIPT_CPU_LIMIT", 120),
+			TimestampMaxBackwardJumps:  getEnvInt("KEYSTONE_QA_TIMESTAMP_MAX_BACKWARD_JUMPS", 0),
+			TimestampMaxPublishSkewSec: getEnvFloat("KEYSTONE_QA_TIMESTAMP_MAX_PUBLISH_SKEW", 1.0),
+			TimestampMaxStartOffsetSec: getEnvFloat("KEYSTONE_QA_TIMESTAMP_MAX_START_OFFSET", 300),
+			WriterMaxPartialFailures:   getEnvInt("KEYSTONE_QA_WRITER_MAX_PARTIAL_FAILURES", 0),
+			WriterMaxQueueOverflows:    getEnvInt("KEYSTONE_QA_WRITER_MAX_QUEUE_OVERFLOWS", 0),
+			WriterFailOnStall:          getEnvBool("KEYSTONE_QA_WRITER_FAIL_ON_STALL", true),
+			ScrubEnabled:               getEnvBool("KEYSTONE_QA_SCRUB_ENABLED", false),
+			ScrubIntervalSec:           getEnvInt("KEYSTONE_QA_SCRUB_INTERVAL", 3600),
+			ScrubBatchSize:             getEnvInt("KEYSTONE_QA_SCRUB_BATCH_SIZE", 10),
+			ScrubRecheckDays:           getEnvInt("KEYSTONE_QA_SCRUB_RECHECK_DAYS", 30),
+			InspectionLeaseTTLSec:      getEnvInt("KEYSTONE_QA_INSPECTION_LEASE_TTL", 600),
+			InspectionSeniorScore:      getEnvFloat("KEYSTONE_QA_INSPECTION_SENIOR_SCORE", 0.5),
+			AuditEnabled:               getEnvBool("KEYSTONE_QA_AUDIT_ENABLED", false),
+			AuditSampleRate:            getEnvFloat("KEYSTONE_QA_AUDIT_SAMPLE_RATE", 0.05),
 		},
 		Sync: SyncConfig{
 			Enabled:            getEnvBool("KEYSTONE_SYNC_ENABLED", true),
@@ -210,6 +476,21 @@ func Load() (*Config, error) {
 			PersistRootDir:     getEnv("KEYSTONE_SYNC_PERSIST_ROOT_DIR", ""),
 			MaxRestartCount:    getEnvInt("KEYSTONE_SYNC_MAX_RESTART_COUNT", 3),
 			DPConfigPath:       getEnv("KEYSTONE_SYNC_DP_CONFIG", defaultDPConfigPath()),
+			BandwidthLimitKBps: int64(getEnvInt("KEYSTONE_SYNC_BANDWIDTH_LIMIT_KBPS", 0)),
+			Schedule:           getEnv("KEYSTONE_SYNC_SCHEDULE", ""),
+			Destination:        getEnv("KEYSTONE_SYNC_DESTINATION", SyncDestinationDataPlatform),
+			FactoryID:          getEnv("KEYSTONE_FACTORY_ID", "factory-default"),
+			S3: SyncS3Config{
+				Target:       getEnv("KEYSTONE_SYNC_S3_TARGET", ""),
+				Endpoint:     getEnv("KEYSTONE_SYNC_S3_ENDPOINT", ""),
+				Region:       getEnv("KEYSTONE_SYNC_S3_REGION", "us-east-1"),
+				AccessKey:    getEnv("KEYSTONE_SYNC_S3_ACCESS_KEY", ""),
+				SecretKey:    getEnv("KEYSTONE_SYNC_S3_SECRET_KEY", ""),
+				SessionToken: getEnv("KEYSTONE_SYNC_S3_SESSION_TOKEN", ""),
+				UseTLS:       getEnvBool("KEYSTONE_SYNC_S3_USE_TLS", true),
+				PathStyle:    getEnvBool("KEYSTONE_SYNC_S3_PATH_STYLE", false),
+				PartSizeMB:   getEnvInt("KEYSTONE_SYNC_S3_PART_SIZE_MB", 16),
+			},
 		},
 		Auth: AuthConfig{
 			JWTSecret:             getEnv("KEYSTONE_JWT_SECRET", ""),
@@ -232,6 +513,29 @@ func Load() (*Config, error) {
 			HealthCheckInterval: getEnvInt("KEYSTONE_HEALTH_CHECK_INTERVAL", 10),
 			LogLevel:            getEnv("KEYSTONE_LOG_LEVEL", "info"),
 			LogOutput:           getEnv("KEYSTONE_LOG_OUTPUT", "/var/log/keystone-edge/"),
+
+			APILogEnabled:          getEnvBool("KEYSTONE_API_LOG_ENABLED", true),
+			APILogBatchSize:        getEnvInt("KEYSTONE_API_LOG_BATCH_SIZE", 100),
+			APILogFlushIntervalSec: getEnvInt("KEYSTONE_API_LOG_FLUSH_INTERVAL", 2),
+			APILogRetentionDays:    getEnvInt("KEYSTONE_API_LOG_RETENTION_DAYS", 90),
+		},
+		Webhooks: WebhookConfig{
+			Enabled:      getEnvBool("KEYSTONE_WEBHOOK_ENABLED", true),
+			IntervalSec:  getEnvInt("KEYSTONE_WEBHOOK_INTERVAL_SEC", 5),
+			BatchSize:    getEnvInt("KEYSTONE_WEBHOOK_BATCH_SIZE", 50),
+			TimeoutSec:   getEnvInt("KEYSTONE_WEBHOOK_TIMEOUT_SEC", 10),
+			MaxAttempts:  getEnvInt("KEYSTONE_WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBaseSec: getEnvInt("KEYSTONE_WEBHOOK_RETRY_BASE_SEC", 30),
+			RetryMaxSec:  getEnvInt("KEYSTONE_WEBHOOK_RETRY_MAX_SEC", 3600),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        getEnvBool("KEYSTONE_RATE_LIMIT_ENABLED", false),
+			DefaultRPS:     getEnvFloat("KEYSTONE_RATE_LIMIT_DEFAULT_RPS", 20),
+			DefaultBurst:   getEnvInt("KEYSTONE_RATE_LIMIT_DEFAULT_BURST", 40),
+			ReportingRPS:   getEnvFloat("KEYSTONE_RATE_LIMIT_REPORTING_RPS", 1),
+			ReportingBurst: getEnvInt("KEYSTONE_RATE_LIMIT_REPORTING_BURST", 5),
+			DeviceRPS:      getEnvFloat("KEYSTONE_RATE_LIMIT_DEVICE_RPS", 50),
+			DeviceBurst:    getEnvInt("KEYSTONE_RATE_LIMIT_DEVICE_BURST", 100),
 		},
 		Resources: ResourceLimitsConfig{
 			MaxMemoryMB:       getEnvInt("KEYSTONE_MAX_MEMORY_MB", 6144),
@@ -272,6 +576,13 @@ func (c *Config) Validate() error {
 		return err
 	}
 	c.Server.CallbackPublicBaseURL = callbackPublicBaseURL
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("KEYSTONE_TRUSTED_PROXIES entry %q is not an IP or CIDR", proxy)
+			}
+		}
+	}
 	if c.Database.DSN == "" {
 		return fmt.Errorf("database DSN is required")
 	}
@@ -287,15 +598,26 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("KEYSTONE_ADMIN_USERNAME and KEYSTONE_ADMIN_PASSWORD must both be set or both be empty")
 	}
 	if c.Sync.Enabled {
-		c.Sync.DPConfigPath = strings.TrimSpace(c.Sync.DPConfigPath)
-		if c.Sync.DPConfigPath == "" {
-			return fmt.Errorf("KEYSTONE_SYNC_DP_CONFIG is required when sync is enabled")
+		c.Sync.Destination = strings.ToLower(strings.TrimSpace(c.Sync.Destination))
+		switch c.Sync.Destination {
+		case "", SyncDestinationDataPlatform:
+			c.Sync.Destination = SyncDestinationDataPlatform
+			c.Sync.DPConfigPath = strings.TrimSpace(c.Sync.DPConfigPath)
+			if c.Sync.DPConfigPath == "" {
+				return fmt.Errorf("KEYSTONE_SYNC_DP_CONFIG is required when sync is enabled")
+			}
+			expandedDPConfigPath, err := expandHomePath(c.Sync.DPConfigPath)
+			if err != nil {
+				return fmt.Errorf("KEYSTONE_SYNC_DP_CONFIG %q is invalid: %w", c.Sync.DPConfigPath, err)
+			}
+			c.Sync.DPConfigPath = expandedDPConfigPath
+		case SyncDestinationS3:
+			if err := c.Sync.validateS3(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("KEYSTONE_SYNC_DESTINATION %q is invalid, must be %q or %q", c.Sync.Destination, SyncDestinationDataPlatform, SyncDestinationS3)
 		}
-		expandedDPConfigPath, err := expandHomePath(c.Sync.DPConfigPath)
-		if err != nil {
-			return fmt.Errorf("KEYSTONE_SYNC_DP_CONFIG %q is invalid: %w", c.Sync.DPConfigPath, err)
-		}
-		c.Sync.DPConfigPath = expandedDPConfigPath
 		c.Sync.AuthEndpoint = strings.TrimSpace(c.Sync.AuthEndpoint)
 		c.Sync.GatewayEndpoint = strings.TrimSpace(c.Sync.GatewayEndpoint)
 		c.Sync.APIKey = strings.TrimSpace(c.Sync.APIKey)
@@ -326,6 +648,12 @@ func (c *Config) Validate() error {
 		if c.Sync.RetryJitterSec < 0 {
 			return fmt.Errorf("sync retry jitter seconds must be greater than or equal to 0 when sync is enabled")
 		}
+		if err := ValidateSyncBandwidthLimit(c.Sync.BandwidthLimitKBps); err != nil {
+			return fmt.Errorf("KEYSTONE_SYNC_BANDWIDTH_LIMIT_KBPS: %w", err)
+		}
+		if _, err := ParseSyncSchedule(c.Sync.Schedule); err != nil {
+			return fmt.Errorf("KEYSTONE_SYNC_SCHEDULE: %w", err)
+		}
 		if c.Sync.RetryMaxSec < c.Sync.RetryBaseSec {
 			return fmt.Errorf("sync retry max seconds must be greater than or equal to retry base seconds when sync is enabled")
 		}
@@ -336,6 +664,32 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateS3 checks the S3 destination settings and trims them in place.
+func (c *SyncConfig) validateS3() error {
+	c.S3.Target = strings.TrimSpace(c.S3.Target)
+	c.S3.Endpoint = strings.TrimSpace(c.S3.Endpoint)
+	c.S3.Region = strings.TrimSpace(c.S3.Region)
+	c.S3.AccessKey = strings.TrimSpace(c.S3.AccessKey)
+	c.S3.SecretKey = strings.TrimSpace(c.S3.SecretKey)
+	c.S3.SessionToken = strings.TrimSpace(c.S3.SessionToken)
+	if c.S3.Target == "" {
+		return fmt.Errorf("KEYSTONE_SYNC_S3_TARGET is required when the sync destination is s3")
+	}
+	if _, _, err := c.S3.ResolveTarget(c.FactoryID); err != nil {
+		return fmt.Errorf("KEYSTONE_SYNC_S3_TARGET: %w", err)
+	}
+	if c.S3.Region == "" {
+		return fmt.Errorf("KEYSTONE_SYNC_S3_REGION is required when the sync destination is s3")
+	}
+	if c.S3.AccessKey == "" || c.S3.SecretKey == "" {
+		return fmt.Errorf("KEYSTONE_SYNC_S3_ACCESS_KEY and KEYSTONE_SYNC_S3_SECRET_KEY are required when the sync destination is s3")
+	}
+	if c.S3.PartSizeMB < 5 {
+		return fmt.Errorf("KEYSTONE_SYNC_S3_PART_SIZE_MB must be at least 5")
+	}
+	return nil
+}
+
 func normalizeCallbackPublicBaseURL(raw string) (string, error) {
 	value := strings.TrimSpace(raw)
 	if value == "" {
@@ -419,3 +773,37 @@ func getEnvBool(key string, fallback bool) bool {
 	}
 	return fallback
 }
+
+// getEnvList parses a comma-separated list, dropping empty entries.
+func getEnvList(key string, fallback []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+	out := make([]string, 0)
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	if len(out) == 0 {
+		return fallback
+	}
+	return out
+}
+
+// getEnvFloatMap parses "key=value,key=value" pairs; malformed entries are skipped.
+func getEnvFloatMap(key string) map[string]float64 {
+	out := map[string]float64{}
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, raw, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64); err == nil {
+			out[name] = f
+		}
+	}
+	return out
+}
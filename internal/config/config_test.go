@@ -8,6 +8,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -103,6 +104,10 @@ func TestLoad(t *testing.T) {
 		t.Errorf("Load().QA.MaxWorkers = %v, want 4", cfg.QA.MaxWorkers)
 	}
 
+	if !reflect.DeepEqual(cfg.QA.Checks, DefaultQAChecks) {
+		t.Errorf("Load().QA.Checks = %v, want %v", cfg.QA.Checks, DefaultQAChecks)
+	}
+
 	// Verify feature flags (edge version should have these disabled)
 	if cfg.Features.StrataEnabled {
 		t.Error("Load().Features.StrataEnabled should be false")
@@ -122,6 +127,8 @@ func TestLoadWithCustomEnv(t *testing.T) {
 		"KEYSTONE_MINIO_ACCESS_KEY":           os.Getenv("KEYSTONE_MINIO_ACCESS_KEY"),
 		"KEYSTONE_MINIO_SECRET_KEY":           os.Getenv("KEYSTONE_MINIO_SECRET_KEY"),
 		"KEYSTONE_QA_MAX_WORKERS":             os.Getenv("KEYSTONE_QA_MAX_WORKERS"),
+		"KEYSTONE_QA_CHECKS":                  os.Getenv("KEYSTONE_QA_CHECKS"),
+		"KEYSTONE_QA_CHECK_WEIGHTS":           os.Getenv("KEYSTONE_QA_CHECK_WEIGHTS"),
 		"KEYSTONE_MAX_MEMORY_MB":              os.Getenv("KEYSTONE_MAX_MEMORY_MB"),
 		"KEYSTONE_DASHBOARD_DISPLAY_TOKEN":    os.Getenv("KEYSTONE_DASHBOARD_DISPLAY_TOKEN"),
 		"KEYSTONE_SYNC_AUTO_SCAN_ENABLED":     os.Getenv("KEYSTONE_SYNC_AUTO_SCAN_ENABLED"),
@@ -145,6 +152,8 @@ func TestLoadWithCustomEnv(t *testing.T) {
 	os.Setenv("KEYSTONE_MINIO_ACCESS_KEY", "custom-access")
 	os.Setenv("KEYSTONE_MINIO_SECRET_KEY", "custom-secret")
 	os.Setenv("KEYSTONE_QA_MAX_WORKERS", "8")
+	os.Setenv("KEYSTONE_QA_CHECKS", "mcap_magic, gaps")
+	os.Setenv("KEYSTONE_QA_CHECK_WEIGHTS", "gaps=2")
 	os.Setenv("KEYSTONE_MAX_MEMORY_MB", "8192")
 	os.Setenv("KEYSTONE_DASHBOARD_DISPLAY_TOKEN", "display-secret")
 	os.Setenv("KEYSTONE_SYNC_AUTO_SCAN_ENABLED", "true")
@@ -164,6 +173,14 @@ func TestLoadWithCustomEnv(t *testing.T) {
 		t.Errorf("Load().QA.MaxWorkers = %v, want 8", cfg.QA.MaxWorkers)
 	}
 
+	if len(cfg.QA.Checks) != 2 || cfg.QA.Checks[0] != "mcap_magic" || cfg.QA.Checks[1] != "gaps" {
+		t.Errorf("Load().QA.Checks = %v, want [mcap_magic gaps]", cfg.QA.Checks)
+	}
+
+	if cfg.QA.CheckWeights["gaps"] != 2 {
+		t.Errorf("Load().QA.CheckWeights = %v, want gaps=2", cfg.QA.CheckWeights)
+	}
+
 	if cfg.Resources.MaxMemoryMB != 8192 {
 		t.Errorf("Load().Resources.MaxMemoryMB = %v, want 8192", cfg.Resources.MaxMemoryMB)
 	}
@@ -288,6 +305,26 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Valid trusted proxies",
+			cfg: &Config{
+				Server:   ServerConfig{Mode: "edge", CallbackPublicBaseURL: "http://127.0.0.1:9999", TrustedProxies: []string{"10.0.0.1", "172.16.0.0/12"}},
+				Database: DatabaseConfig{DSN: "user:pass@tcp(localhost:3306)/db"},
+				Storage:  StorageConfig{AccessKey: "key", SecretKey: "secret"},
+				Auth:     AuthConfig{JWTSecret: "secret"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Invalid trusted proxy",
+			cfg: &Config{
+				Server:   ServerConfig{Mode: "edge", CallbackPublicBaseURL: "http://127.0.0.1:9999", TrustedProxies: []string{"proxy.local"}},
+				Database: DatabaseConfig{DSN: "user:pass@tcp(localhost:3306)/db"},
+				Storage:  StorageConfig{AccessKey: "key", SecretKey: "secret"},
+				Auth:     AuthConfig{JWTSecret: "secret"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -537,3 +574,158 @@ func TestGetEnvBool(t *testing.T) {
 		t.Errorf("getEnvBool() = %v, want true (default)", got)
 	}
 }
+
+func TestGetEnvFloatMap(t *testing.T) {
+	if got := getEnvFloatMap("NONEXISTENT_ENV_FLOAT_MAP_12345"); len(got) != 0 {
+		t.Errorf("getEnvFloatMap() = %v, want empty", got)
+	}
+
+	os.Setenv("TEST_GET_ENV_FLOAT_MAP", " /camera/color = 30 ,/joint_states=100,bad,/imu=x")
+	defer os.Unsetenv("TEST_GET_ENV_FLOAT_MAP")
+	got := getEnvFloatMap("TEST_GET_ENV_FLOAT_MAP")
+	if len(got) != 2 || got["/camera/color"] != 30 || got["/joint_states"] != 100 {
+		t.Errorf("getEnvFloatMap() = %v, want /camera/color=30 and /joint_states=100", got)
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	fallback := []string{"mcap_magic"}
+	if got := getEnvList("NONEXISTENT_ENV_LIST_12345", fallback); len(got) != 1 || got[0] != "mcap_magic" {
+		t.Errorf("getEnvList() = %v, want fallback", got)
+	}
+
+	os.Setenv("TEST_GET_ENV_LIST", " topics, ,gaps ")
+	defer os.Unsetenv("TEST_GET_ENV_LIST")
+	got := getEnvList("TEST_GET_ENV_LIST", fallback)
+	if len(got) != 2 || got[0] != "topics" || got[1] != "gaps" {
+		t.Errorf("getEnvList() = %v, want [topics gaps]", got)
+	}
+}
+
+func TestParseSyncSchedule(t *testing.T) {
+	windows, err := ParseSyncSchedule(" 19:00-07:00 , 07:00-19:00@512")
+	if err != nil {
+		t.Fatalf("ParseSyncSchedule() error = %v", err)
+	}
+	want := []SyncWindow{
+		{Start: "19:00", End: "07:00"},
+		{Start: "07:00", End: "19:00", BandwidthLimitKBps: 512},
+	}
+	if len(windows) != len(want) {
+		t.Fatalf("windows = %+v, want %+v", windows, want)
+	}
+	for i := range want {
+		if windows[i] != want[i] {
+			t.Fatalf("windows[%d] = %+v, want %+v", i, windows[i], want[i])
+		}
+	}
+
+	if windows, err := ParseSyncSchedule(""); err != nil || len(windows) != 0 {
+		t.Fatalf("ParseSyncSchedule(\"\") = %+v, %v; want no windows", windows, err)
+	}
+
+	for _, raw := range []string{"19:00", "25:00-07:00", "07:00-07:00", "07:00-19:00@64", "07:00-19:00@fast"} {
+		if _, err := ParseSyncSchedule(raw); err == nil {
+			t.Errorf("ParseSyncSchedule(%q) error = nil, want error", raw)
+		}
+	}
+}
+
+func TestValidateSyncBandwidthLimit(t *testing.T) {
+	for _, kbps := range []int64{0, MinSyncBandwidthLimitKBps, 10240} {
+		if err := ValidateSyncBandwidthLimit(kbps); err != nil {
+			t.Errorf("ValidateSyncBandwidthLimit(%d) error = %v", kbps, err)
+		}
+	}
+	for _, kbps := range []int64{-1, 1, MinSyncBandwidthLimitKBps - 1} {
+		if err := ValidateSyncBandwidthLimit(kbps); err == nil {
+			t.Errorf("ValidateSyncBandwidthLimit(%d) error = nil, want error", kbps)
+		}
+	}
+}
+
+func TestValidateSyncS3Destination(t *testing.T) {
+	base := Config{
+		Server:   ServerConfig{Mode: "edge", CallbackPublicBaseURL: "http://127.0.0.1:9999"},
+		Database: DatabaseConfig{DSN: "user:pass@tcp(localhost:3306)/db"},
+		Storage:  StorageConfig{AccessKey: "key", SecretKey: "secret"},
+		Auth:     AuthConfig{JWTSecret: "jwt-secret"},
+	}
+	validSync := func() SyncConfig {
+		return SyncConfig{
+			Enabled:           true,
+			Destination:       " S3 ",
+			FactoryID:         "factory-a",
+			BatchSize:         10,
+			MaxRetries:        5,
+			MaxConcurrent:     2,
+			WorkerIntervalSec: 60,
+			RequestTimeoutSec: 30,
+			OSSTimeoutSec:     300,
+			RetryBaseSec:      30,
+			RetryMaxSec:       1800,
+			S3: SyncS3Config{
+				Target:     "s3://edge-{factory_id}/",
+				Region:     "us-east-1",
+				AccessKey:  "AKIA",
+				SecretKey:  "secret",
+				PartSizeMB: 16,
+			},
+		}
+	}
+
+	t.Run("s3 destination does not require DP config", func(t *testing.T) {
+		cfg := base
+		cfg.Sync = validSync()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("Validate() unexpected error = %v", err)
+		}
+		if cfg.Sync.Destination != SyncDestinationS3 {
+			t.Fatalf("Destination = %q, want normalized s3", cfg.Sync.Destination)
+		}
+	})
+
+	for name, mutate := range map[string]func(*SyncConfig){
+		"missing target":      func(s *SyncConfig) { s.S3.Target = "" },
+		"missing credentials": func(s *SyncConfig) { s.S3.SecretKey = "" },
+		"small part size":     func(s *SyncConfig) { s.S3.PartSizeMB = 4 },
+		"unknown destination": func(s *SyncConfig) { s.Destination = "gcs" },
+		"empty factory id":    func(s *SyncConfig) { s.FactoryID = "" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			cfg := base
+			cfg.Sync = validSync()
+			mutate(&cfg.Sync)
+			if err := cfg.Validate(); err == nil {
+				t.Fatal("Validate() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSyncS3ConfigResolveTarget(t *testing.T) {
+	cases := []struct {
+		target, bucket, prefix string
+	}{
+		{"s3://edge-{factory_id}/", "edge-factory-a", ""},
+		{"s3://edge-{factory_id}", "edge-factory-a", ""},
+		{"s3://archive/edge/{factory_id}", "archive", "edge/factory-a/"},
+		{" s3://archive/raw/ ", "archive", "raw/"},
+	}
+	for _, tc := range cases {
+		bucket, prefix, err := SyncS3Config{Target: tc.target}.ResolveTarget("factory-a")
+		if err != nil {
+			t.Errorf("ResolveTarget(%q) error = %v", tc.target, err)
+			continue
+		}
+		if bucket != tc.bucket || prefix != tc.prefix {
+			t.Errorf("ResolveTarget(%q) = %q, %q; want %q, %q", tc.target, bucket, prefix, tc.bucket, tc.prefix)
+		}
+	}
+
+	for _, target := range []string{"edge-factory-a", "s3://", "s3://Edge_Bucket/", "s3://a/"} {
+		if _, _, err := (SyncS3Config{Target: target}).ResolveTarget("factory-a"); err == nil {
+			t.Errorf("ResolveTarget(%q) error = nil, want error", target)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package mcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	lz4FrameMagic         = 0x184D2204
+	lz4SkippableMagicMask = 0xFFFFFFF0
+	lz4SkippableMagic     = 0x184D2A50
+	lz4WindowSize         = 64 * 1024
+	lz4MaxBlockSize       = 4 * 1024 * 1024
+)
+
+var errLZ4Corrupt = errors.New("mcap: corrupt lz4 data")
+
+// lz4FrameReader decodes the LZ4 frame format used for MCAP "lz4" chunks.
+// Block and content checksums are skipped: chunk integrity is verified by the
+// MCAP uncompressed CRC instead.
+type lz4FrameReader struct {
+	r            io.Reader
+	inFrame      bool
+	blockCheck   bool
+	contentCheck bool
+	maxBlock     int
+	window       []byte
+	pending      []byte
+	block        []byte
+	err          error
+}
+
+func newLZ4FrameReader(r io.Reader) io.Reader {
+	return &lz4FrameReader{r: r}
+}
+
+func (z *lz4FrameReader) Read(p []byte) (int, error) {
+	for len(z.pending) == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		z.err = z.nextBlock()
+	}
+	n := copy(p, z.pending)
+	z.pending = z.pending[n:]
+	return n, nil
+}
+
+func (z *lz4FrameReader) nextBlock() error {
+	if !z.inFrame {
+		ok, err := z.readFrameHeader()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return io.EOF
+		}
+	}
+
+	var sizeBuf [4]byte
+	if _, err := io.ReadFull(z.r, sizeBuf[:]); err != nil {
+		return unexpectedEOF(err)
+	}
+	size := binary.LittleEndian.Uint32(sizeBuf[:])
+	if size == 0 {
+		z.inFrame = false
+		if z.contentCheck {
+			if _, err := io.ReadFull(z.r, sizeBuf[:]); err != nil {
+				return unexpectedEOF(err)
+			}
+		}
+		return nil
+	}
+
+	uncompressed := size&0x80000000 != 0
+	size &= 0x7FFFFFFF
+	if int(size) > z.maxBlock {
+		return fmt.Errorf("%w: block size %d exceeds max %d", errLZ4Corrupt, size, z.maxBlock)
+	}
+	if cap(z.block) < int(size) {
+		z.block = make([]byte, size)
+	}
+	block := z.block[:size]
+	if _, err := io.ReadFull(z.r, block); err != nil {
+		return unexpectedEOF(err)
+	}
+	if z.blockCheck {
+		if _, err := io.ReadFull(z.r, sizeBuf[:]); err != nil {
+			return unexpectedEOF(err)
+		}
+	}
+
+	// Decoded output is appended after the retained window so matches in
+	// linked blocks can reference the previous 64 KiB.
+	base := len(z.window)
+	out := z.window
+	if uncompressed {
+		out = append(out, block...)
+	} else {
+		var err error
+		out, err = decodeLZ4Block(out, block, z.maxBlock)
+		if err != nil {
+			return err
+		}
+	}
+	z.pending = append([]byte(nil), out[base:]...)
+	if len(out) > lz4WindowSize {
+		out = append(out[:0], out[len(out)-lz4WindowSize:]...)
+	}
+	z.window = out
+	return nil
+}
+
+// readFrameHeader reads the next frame descriptor, skipping skippable frames.
+// It returns false at a clean end of stream.
+func (z *lz4FrameReader) readFrameHeader() (bool, error) {
+	for {
+		var magicBuf [4]byte
+		if _, err := io.ReadFull(z.r, magicBuf[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, unexpectedEOF(err)
+		}
+		magic := binary.LittleEndian.Uint32(magicBuf[:])
+		if magic&lz4SkippableMagicMask == lz4SkippableMagic {
+			if _, err := io.ReadFull(z.r, magicBuf[:]); err != nil {
+				return false, unexpectedEOF(err)
+			}
+			if _, err := io.CopyN(io.Discard, z.r, int64(binary.LittleEndian.Uint32(magicBuf[:]))); err != nil {
+				return false, unexpectedEOF(err)
+			}
+			continue
+		}
+		if magic != lz4FrameMagic {
+			return false, fmt.Errorf("%w: frame magic %08x", errLZ4Corrupt, magic)
+		}
+		break
+	}
+
+	var desc [2]byte
+	if _, err := io.ReadFull(z.r, desc[:]); err != nil {
+		return false, unexpectedEOF(err)
+	}
+	flg, bd := desc[0], desc[1]
+	if flg>>6 != 1 {
+		return false, fmt.Errorf("%w: frame version %d", errLZ4Corrupt, flg>>6)
+	}
+	switch (bd >> 4) & 0x7 {
+	case 4:
+		z.maxBlock = 64 * 1024
+	case 5:
+		z.maxBlock = 256 * 1024
+	case 6:
+		z.maxBlock = 1024 * 1024
+	case 7:
+		z.maxBlock = lz4MaxBlockSize
+	default:
+		return false, fmt.Errorf("%w: block max size id %d", errLZ4Corrupt, (bd>>4)&0x7)
+	}
+	z.blockCheck = flg&0x10 != 0
+	z.contentCheck = flg&0x04 != 0
+
+	// Optional content size and dictionary id, then the header checksum byte.
+	skip := 1
+	if flg&0x08 != 0 {
+		skip += 8
+	}
+	if flg&0x01 != 0 {
+		skip += 4
+	}
+	if _, err := io.CopyN(io.Discard, z.r, int64(skip)); err != nil {
+		return false, unexpectedEOF(err)
+	}
+
+	z.window = z.window[:0]
+	z.inFrame = true
+	return true, nil
+}
+
+// decodeLZ4Block appends the decoded contents of one LZ4 block to dst.
+// Matches may reference bytes already in dst.
+func decodeLZ4Block(dst, src []byte, maxOut int) ([]byte, error) {
+	start := len(dst)
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			for {
+				if i >= len(src) {
+					return nil, errLZ4Corrupt
+				}
+				b := src[i]
+				i++
+				litLen += int(b)
+				if b != 255 {
+					break
+				}
+			}
+		}
+		if litLen > len(src)-i {
+			return nil, errLZ4Corrupt
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+		if i == len(src) {
+			break
+		}
+
+		if len(src)-i < 2 {
+			return nil, errLZ4Corrupt
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errLZ4Corrupt
+		}
+
+		matchLen := int(token & 0xF)
+		if matchLen == 15 {
+			for {
+				if i >= len(src) {
+					return nil, errLZ4Corrupt
+				}
+				b := src[i]
+				i++
+				matchLen += int(b)
+				if b != 255 {
+					break
+				}
+			}
+		}
+		matchLen += 4
+		if len(dst)-start+matchLen > maxOut {
+			return nil, errLZ4Corrupt
+		}
+		pos := len(dst) - offset
+		for k := 0; k < matchLen; k++ {
+			dst = append(dst, dst[pos+k])
+		}
+	}
+	if len(dst)-start > maxOut {
+		return nil, errLZ4Corrupt
+	}
+	return dst, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
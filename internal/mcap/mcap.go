@@ -0,0 +1,419 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+// Package mcap provides a streaming MCAP reader used by the QA engine.
+//
+// The reader never loads a whole recording into memory: it reads the fixed
+// size header and footer records directly, the summary section as one bounded
+// range, and walks the data section through a single streaming range so chunk
+// CRCs can be verified while the bytes are decompressed.
+package mcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Record opcodes defined by the MCAP specification.
+const (
+	OpHeader          byte = 0x01
+	OpFooter          byte = 0x02
+	OpSchema          byte = 0x03
+	OpChannel         byte = 0x04
+	OpMessage         byte = 0x05
+	OpChunk           byte = 0x06
+	OpMessageIndex    byte = 0x07
+	OpChunkIndex      byte = 0x08
+	OpAttachment      byte = 0x09
+	OpAttachmentIndex byte = 0x0A
+	OpStatistics      byte = 0x0B
+	OpMetadata        byte = 0x0C
+	OpMetadataIndex   byte = 0x0D
+	OpSummaryOffset   byte = 0x0E
+	OpDataEnd         byte = 0x0F
+)
+
+// Supported chunk compression values.
+const (
+	CompressionNone = ""
+	CompressionZSTD = "zstd"
+	CompressionLZ4  = "lz4"
+)
+
+const (
+	recordPrefixLen = 1 + 8
+	// footerContentLen is summary_start + summary_offset_start + summary_crc.
+	footerContentLen = 8 + 8 + 4
+	footerRecordLen  = recordPrefixLen + footerContentLen
+)
+
+// Magic is the 8-byte sequence that opens and closes every MCAP file.
+var Magic = []byte{0x89, 'M', 'C', 'A', 'P', 0x30, '\r', '\n'}
+
+var (
+	// ErrBadMagic is returned when the leading or trailing magic bytes do not match.
+	ErrBadMagic = errors.New("mcap: invalid magic")
+	// ErrTruncated is returned when a record extends past the available bytes.
+	ErrTruncated = errors.New("mcap: truncated record")
+	// ErrUnexpectedOpcode is returned when a record is not the one the layout requires.
+	ErrUnexpectedOpcode = errors.New("mcap: unexpected opcode")
+	// ErrCRCMismatch is returned when a computed CRC32 does not match the stored value.
+	ErrCRCMismatch = errors.New("mcap: crc mismatch")
+	// ErrUnsupportedCompression is returned for chunk compression schemes the reader cannot decode.
+	ErrUnsupportedCompression = errors.New("mcap: unsupported compression")
+	// ErrRecordTooLarge is returned when a record exceeds the reader's configured bounds.
+	ErrRecordTooLarge = errors.New("mcap: record too large")
+)
+
+// Header is the first record of an MCAP file.
+type Header struct {
+	Profile string
+	Library string
+}
+
+// Footer is the last record of an MCAP file.
+type Footer struct {
+	SummaryStart       uint64
+	SummaryOffsetStart uint64
+	SummaryCRC         uint32
+}
+
+// Schema describes the encoding of messages on one or more channels.
+type Schema struct {
+	ID       uint16
+	Name     string
+	Encoding string
+	Data     []byte
+}
+
+// Channel describes a topic recorded in the file.
+type Channel struct {
+	ID              uint16
+	SchemaID        uint16
+	Topic           string
+	MessageEncoding string
+	Metadata        map[string]string
+}
+
+// Message is one recorded message.
+type Message struct {
+	ChannelID   uint16
+	Sequence    uint32
+	LogTime     uint64
+	PublishTime uint64
+	Data        []byte
+}
+
+// ChunkIndex locates a chunk record in the data section.
+type ChunkIndex struct {
+	MessageStartTime    uint64
+	MessageEndTime      uint64
+	ChunkStartOffset    uint64
+	ChunkLength         uint64
+	MessageIndexOffsets map[uint16]uint64
+	MessageIndexLength  uint64
+	Compression         string
+	CompressedSize      uint64
+	UncompressedSize    uint64
+}
+
+// Statistics summarizes the file contents.
+type Statistics struct {
+	MessageCount         uint64
+	SchemaCount          uint16
+	ChannelCount         uint32
+	AttachmentCount      uint32
+	MetadataCount        uint32
+	ChunkCount           uint32
+	MessageStartTime     uint64
+	MessageEndTime       uint64
+	ChannelMessageCounts map[uint16]uint64
+}
+
+// Chunk describes one chunk record encountered while scanning the data section.
+type Chunk struct {
+	Offset           int64
+	Length           int64
+	MessageStartTime uint64
+	MessageEndTime   uint64
+	UncompressedSize uint64
+	UncompressedCRC  uint32
+	Compression      string
+	CompressedSize   uint64
+}
+
+// fieldReader decodes little-endian MCAP fields from a record body and
+// remembers the first decoding error so callers can check once at the end.
+type fieldReader struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (f *fieldReader) take(n int) []byte {
+	if f.err != nil {
+		return nil
+	}
+	if n < 0 || len(f.buf)-f.off < n {
+		f.err = ErrTruncated
+		return nil
+	}
+	out := f.buf[f.off : f.off+n]
+	f.off += n
+	return out
+}
+
+func (f *fieldReader) uint8() uint8 {
+	b := f.take(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (f *fieldReader) uint16() uint16 {
+	b := f.take(2)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(b)
+}
+
+func (f *fieldReader) uint32() uint32 {
+	b := f.take(4)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (f *fieldReader) uint64() uint64 {
+	b := f.take(8)
+	if b == nil {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+func (f *fieldReader) string() string {
+	n := f.uint32()
+	return string(f.take(int(n)))
+}
+
+func (f *fieldReader) prefixedBytes() []byte {
+	n := f.uint32()
+	b := f.take(int(n))
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
+
+func (f *fieldReader) stringMap() map[string]string {
+	n := f.uint32()
+	body := f.take(int(n))
+	if f.err != nil {
+		return nil
+	}
+	inner := &fieldReader{buf: body}
+	out := map[string]string{}
+	for inner.err == nil && inner.off < len(inner.buf) {
+		k := inner.string()
+		v := inner.string()
+		if inner.err == nil {
+			out[k] = v
+		}
+	}
+	if inner.err != nil {
+		f.err = inner.err
+	}
+	return out
+}
+
+func (f *fieldReader) uint16Uint64Map() map[uint16]uint64 {
+	n := f.uint32()
+	body := f.take(int(n))
+	if f.err != nil {
+		return nil
+	}
+	inner := &fieldReader{buf: body}
+	out := map[uint16]uint64{}
+	for inner.err == nil && inner.off < len(inner.buf) {
+		k := inner.uint16()
+		v := inner.uint64()
+		if inner.err == nil {
+			out[k] = v
+		}
+	}
+	if inner.err != nil {
+		f.err = inner.err
+	}
+	return out
+}
+
+func (f *fieldReader) rest() []byte {
+	if f.err != nil {
+		return nil
+	}
+	out := f.buf[f.off:]
+	f.off = len(f.buf)
+	return out
+}
+
+func recordError(op byte, err error) error {
+	return fmt.Errorf("parse %s record: %w", OpName(op), err)
+}
+
+// OpName returns the specification name of a record opcode.
+func OpName(op byte) string {
+	switch op {
+	case OpHeader:
+		return "header"
+	case OpFooter:
+		return "footer"
+	case OpSchema:
+		return "schema"
+	case OpChannel:
+		return "channel"
+	case OpMessage:
+		return "message"
+	case OpChunk:
+		return "chunk"
+	case OpMessageIndex:
+		return "message_index"
+	case OpChunkIndex:
+		return "chunk_index"
+	case OpAttachment:
+		return "attachment"
+	case OpAttachmentIndex:
+		return "attachment_index"
+	case OpStatistics:
+		return "statistics"
+	case OpMetadata:
+		return "metadata"
+	case OpMetadataIndex:
+		return "metadata_index"
+	case OpSummaryOffset:
+		return "summary_offset"
+	case OpDataEnd:
+		return "data_end"
+	default:
+		return fmt.Sprintf("opcode_0x%02x", op)
+	}
+}
+
+func parseHeader(body []byte) (*Header, error) {
+	f := &fieldReader{buf: body}
+	h := &Header{Profile: f.string(), Library: f.string()}
+	if f.err != nil {
+		return nil, recordError(OpHeader, f.err)
+	}
+	return h, nil
+}
+
+func parseFooter(body []byte) (*Footer, error) {
+	f := &fieldReader{buf: body}
+	footer := &Footer{
+		SummaryStart:       f.uint64(),
+		SummaryOffsetStart: f.uint64(),
+		SummaryCRC:         f.uint32(),
+	}
+	if f.err != nil {
+		return nil, recordError(OpFooter, f.err)
+	}
+	return footer, nil
+}
+
+func parseSchema(body []byte) (*Schema, error) {
+	f := &fieldReader{buf: body}
+	s := &Schema{
+		ID:       f.uint16(),
+		Name:     f.string(),
+		Encoding: f.string(),
+		Data:     f.prefixedBytes(),
+	}
+	if f.err != nil {
+		return nil, recordError(OpSchema, f.err)
+	}
+	return s, nil
+}
+
+func parseChannel(body []byte) (*Channel, error) {
+	f := &fieldReader{buf: body}
+	c := &Channel{
+		ID:              f.uint16(),
+		SchemaID:        f.uint16(),
+		Topic:           f.string(),
+		MessageEncoding: f.string(),
+		Metadata:        f.stringMap(),
+	}
+	if f.err != nil {
+		return nil, recordError(OpChannel, f.err)
+	}
+	return c, nil
+}
+
+func parseMessage(body []byte) (*Message, error) {
+	f := &fieldReader{buf: body}
+	m := &Message{
+		ChannelID:   f.uint16(),
+		Sequence:    f.uint32(),
+		LogTime:     f.uint64(),
+		PublishTime: f.uint64(),
+	}
+	m.Data = f.rest()
+	if f.err != nil {
+		return nil, recordError(OpMessage, f.err)
+	}
+	return m, nil
+}
+
+func parseChunkIndex(body []byte) (*ChunkIndex, error) {
+	f := &fieldReader{buf: body}
+	idx := &ChunkIndex{
+		MessageStartTime:    f.uint64(),
+		MessageEndTime:      f.uint64(),
+		ChunkStartOffset:    f.uint64(),
+		ChunkLength:         f.uint64(),
+		MessageIndexOffsets: f.uint16Uint64Map(),
+		MessageIndexLength:  f.uint64(),
+		Compression:         f.string(),
+		CompressedSize:      f.uint64(),
+		UncompressedSize:    f.uint64(),
+	}
+	if f.err != nil {
+		return nil, recordError(OpChunkIndex, f.err)
+	}
+	return idx, nil
+}
+
+func parseStatistics(body []byte) (*Statistics, error) {
+	f := &fieldReader{buf: body}
+	s := &Statistics{
+		MessageCount:         f.uint64(),
+		SchemaCount:          f.uint16(),
+		ChannelCount:         f.uint32(),
+		AttachmentCount:      f.uint32(),
+		MetadataCount:        f.uint32(),
+		ChunkCount:           f.uint32(),
+		MessageStartTime:     f.uint64(),
+		MessageEndTime:       f.uint64(),
+		ChannelMessageCounts: f.uint16Uint64Map(),
+	}
+	if f.err != nil {
+		return nil, recordError(OpStatistics, f.err)
+	}
+	return s, nil
+}
+
+// readRecordPrefix reads an opcode and record length from r.
+func readRecordPrefix(r io.Reader) (byte, uint64, error) {
+	var prefix [recordPrefixLen]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return 0, 0, err
+	}
+	return prefix[0], binary.LittleEndian.Uint64(prefix[1:]), nil
+}
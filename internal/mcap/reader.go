@@ -0,0 +1,652 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package mcap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// DefaultMaxSummaryBytes bounds the summary section, which is read into memory in one range.
+	DefaultMaxSummaryBytes int64 = 64 * 1024 * 1024
+	// DefaultMaxRecordBytes bounds any single schema, channel or message record.
+	DefaultMaxRecordBytes int64 = 256 * 1024 * 1024
+
+	scanBufferSize       = 1024 * 1024
+	chunkBufferSize      = 64 * 1024
+	maxCompressionLength = 64
+)
+
+// RangeReader opens a streaming view over a byte range of an MCAP object.
+// Implementations typically issue one ranged GET per call.
+type RangeReader interface {
+	ReadRange(offset, length int64) (io.ReadCloser, error)
+}
+
+// ReaderAtRange adapts an io.ReaderAt such as *os.File or *bytes.Reader to a RangeReader.
+func ReaderAtRange(r io.ReaderAt) RangeReader {
+	return readerAtRange{r: r}
+}
+
+type readerAtRange struct {
+	r io.ReaderAt
+}
+
+func (r readerAtRange) ReadRange(offset, length int64) (io.ReadCloser, error) {
+	return io.NopCloser(io.NewSectionReader(r.r, offset, length)), nil
+}
+
+// Reader reads MCAP structure from a RangeReader of known size.
+type Reader struct {
+	src  RangeReader
+	size int64
+
+	// MaxSummaryBytes bounds the summary section read by ReadSummary.
+	MaxSummaryBytes int64
+	// MaxRecordBytes bounds individual records decoded by Scan.
+	MaxRecordBytes int64
+}
+
+// NewReader creates a Reader over size bytes served by src.
+func NewReader(src RangeReader, size int64) *Reader {
+	return &Reader{
+		src:             src,
+		size:            size,
+		MaxSummaryBytes: DefaultMaxSummaryBytes,
+		MaxRecordBytes:  DefaultMaxRecordBytes,
+	}
+}
+
+// Size returns the object size the reader was created with.
+func (r *Reader) Size() int64 {
+	return r.size
+}
+
+// Summary is the decoded summary section together with the header and footer.
+type Summary struct {
+	Header       *Header
+	Footer       *Footer
+	Schemas      map[uint16]*Schema
+	Channels     map[uint16]*Channel
+	ChunkIndexes []*ChunkIndex
+	Statistics   *Statistics
+}
+
+// HasSummarySection reports whether the footer points at a summary section.
+func (s *Summary) HasSummarySection() bool {
+	return s != nil && s.Footer != nil && s.Footer.SummaryStart != 0
+}
+
+func (r *Reader) readFull(offset, length int64) ([]byte, error) {
+	if offset < 0 || length < 0 || offset+length > r.size {
+		return nil, fmt.Errorf("%w: range %d+%d exceeds file size %d", ErrTruncated, offset, length, r.size)
+	}
+	rc, err := r.src.ReadRange(offset, length)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: short read at offset %d", ErrTruncated, offset)
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readHeaderPrefix validates the leading magic and returns the header record length.
+func (r *Reader) readHeaderPrefix() (uint64, error) {
+	if r.size < int64(len(Magic)+recordPrefixLen) {
+		return 0, fmt.Errorf("%w: file is %d bytes", ErrTruncated, r.size)
+	}
+	prefix, err := r.readFull(0, int64(len(Magic)+recordPrefixLen))
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(prefix[:len(Magic)], Magic) {
+		return 0, fmt.Errorf("%w: leading magic", ErrBadMagic)
+	}
+	if op := prefix[len(Magic)]; op != OpHeader {
+		return 0, fmt.Errorf("%w: expected header, found %s", ErrUnexpectedOpcode, OpName(op))
+	}
+	length := binary.LittleEndian.Uint64(prefix[len(Magic)+1:])
+	if length > uint64(r.size) {
+		return 0, fmt.Errorf("%w: header length %d", ErrTruncated, length)
+	}
+	return length, nil
+}
+
+func (r *Reader) dataStart(headerLength uint64) int64 {
+	return int64(len(Magic)+recordPrefixLen) + int64(headerLength)
+}
+
+// ReadHeader reads and decodes the header record.
+func (r *Reader) ReadHeader() (*Header, error) {
+	length, err := r.readHeaderPrefix()
+	if err != nil {
+		return nil, err
+	}
+	if int64(length) > r.MaxRecordBytes {
+		return nil, fmt.Errorf("%w: header length %d", ErrRecordTooLarge, length)
+	}
+	body, err := r.readFull(int64(len(Magic)+recordPrefixLen), int64(length))
+	if err != nil {
+		return nil, err
+	}
+	return parseHeader(body)
+}
+
+func (r *Reader) footerOffset() int64 {
+	return r.size - int64(len(Magic)) - footerRecordLen
+}
+
+// ReadFooter reads and decodes the footer record and validates the trailing magic.
+func (r *Reader) ReadFooter() (*Footer, error) {
+	if r.size < int64(len(Magic))*2+footerRecordLen {
+		return nil, fmt.Errorf("%w: file is %d bytes", ErrTruncated, r.size)
+	}
+	tail, err := r.readFull(r.footerOffset(), footerRecordLen+int64(len(Magic)))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(tail[footerRecordLen:], Magic) {
+		return nil, fmt.Errorf("%w: trailing magic", ErrBadMagic)
+	}
+	if tail[0] != OpFooter {
+		return nil, fmt.Errorf("%w: expected footer, found %s", ErrUnexpectedOpcode, OpName(tail[0]))
+	}
+	if length := binary.LittleEndian.Uint64(tail[1:recordPrefixLen]); length != footerContentLen {
+		return nil, fmt.Errorf("%w: footer length %d", ErrTruncated, length)
+	}
+	return parseFooter(tail[recordPrefixLen:footerRecordLen])
+}
+
+// ReadSummary reads the header, footer and summary section.
+//
+// When the stored summary CRC does not match, the decoded summary is returned
+// together with an error wrapping ErrCRCMismatch so callers can still report on it.
+func (r *Reader) ReadSummary() (*Summary, error) {
+	header, err := r.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	footer, err := r.ReadFooter()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Summary{
+		Header:   header,
+		Footer:   footer,
+		Schemas:  map[uint16]*Schema{},
+		Channels: map[uint16]*Channel{},
+	}
+	if footer.SummaryStart == 0 {
+		return s, nil
+	}
+
+	footerOffset := r.footerOffset()
+	if footer.SummaryStart < uint64(len(Magic)) || footer.SummaryStart > uint64(footerOffset) {
+		return nil, fmt.Errorf("%w: summary_start %d outside data range", ErrTruncated, footer.SummaryStart)
+	}
+	start := int64(footer.SummaryStart)
+	length := footerOffset - start
+	if length > r.MaxSummaryBytes {
+		return nil, fmt.Errorf("%w: summary section is %d bytes", ErrRecordTooLarge, length)
+	}
+
+	// The summary CRC covers the summary section plus the footer's opcode,
+	// length, summary_start and summary_offset_start fields.
+	crcSpan := int64(recordPrefixLen + 8 + 8)
+	data, err := r.readFull(start, length+crcSpan)
+	if err != nil {
+		return nil, err
+	}
+
+	var crcErr error
+	if footer.SummaryCRC != 0 {
+		if got := crc32.ChecksumIEEE(data); got != footer.SummaryCRC {
+			crcErr = fmt.Errorf("%w: summary crc stored %08x computed %08x", ErrCRCMismatch, footer.SummaryCRC, got)
+		}
+	}
+	if err := s.decodeRecords(data[:length]); err != nil {
+		if crcErr != nil {
+			return nil, crcErr
+		}
+		return nil, err
+	}
+	return s, crcErr
+}
+
+func (s *Summary) decodeRecords(data []byte) error {
+	for off := 0; off < len(data); {
+		if len(data)-off < recordPrefixLen {
+			return fmt.Errorf("%w: summary record prefix at %d", ErrTruncated, off)
+		}
+		op := data[off]
+		length := binary.LittleEndian.Uint64(data[off+1 : off+recordPrefixLen])
+		off += recordPrefixLen
+		if length > uint64(len(data)-off) {
+			return fmt.Errorf("%w: summary %s record length %d", ErrTruncated, OpName(op), length)
+		}
+		body := data[off : off+int(length)]
+		off += int(length)
+
+		switch op {
+		case OpSchema:
+			schema, err := parseSchema(body)
+			if err != nil {
+				return err
+			}
+			s.Schemas[schema.ID] = schema
+		case OpChannel:
+			channel, err := parseChannel(body)
+			if err != nil {
+				return err
+			}
+			s.Channels[channel.ID] = channel
+		case OpChunkIndex:
+			idx, err := parseChunkIndex(body)
+			if err != nil {
+				return err
+			}
+			s.ChunkIndexes = append(s.ChunkIndexes, idx)
+		case OpStatistics:
+			stats, err := parseStatistics(body)
+			if err != nil {
+				return err
+			}
+			s.Statistics = stats
+		}
+	}
+	return nil
+}
+
+// Visitor receives records while Scan walks the data section. Nil callbacks
+// are skipped, and records inside chunks are only decoded when Schema,
+// Channel or Message is set. Records inside a chunk are delivered after the
+// chunk's size and CRC verify, so a corrupt chunk delivers none of them.
+type Visitor struct {
+	Schema  func(*Schema) error
+	Channel func(*Channel) error
+	Message func(*Message) error
+	// Chunk is called after each chunk record has been consumed. chunkErr
+	// reports a decompression, size or CRC failure for that chunk only; the
+	// scan continues with the next record unless the callback returns an error.
+	Chunk func(chunk *Chunk, chunkErr error) error
+}
+
+// MergeVisitors returns a Visitor that calls every visitor's callbacks in
+// order, so one Scan can serve several consumers. The first callback error
+// stops the scan.
+func MergeVisitors(visitors ...Visitor) Visitor {
+	var merged Visitor
+	var schemas []func(*Schema) error
+	var channels []func(*Channel) error
+	var messages []func(*Message) error
+	var chunks []func(*Chunk, error) error
+	for _, v := range visitors {
+		if v.Schema != nil {
+			schemas = append(schemas, v.Schema)
+		}
+		if v.Channel != nil {
+			channels = append(channels, v.Channel)
+		}
+		if v.Message != nil {
+			messages = append(messages, v.Message)
+		}
+		if v.Chunk != nil {
+			chunks = append(chunks, v.Chunk)
+		}
+	}
+	if len(schemas) > 0 {
+		merged.Schema = func(s *Schema) error {
+			for _, fn := range schemas {
+				if err := fn(s); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if len(channels) > 0 {
+		merged.Channel = func(c *Channel) error {
+			for _, fn := range channels {
+				if err := fn(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if len(messages) > 0 {
+		merged.Message = func(m *Message) error {
+			for _, fn := range messages {
+				if err := fn(m); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if len(chunks) > 0 {
+		merged.Chunk = func(c *Chunk, chunkErr error) error {
+			for _, fn := range chunks {
+				if err := fn(c, chunkErr); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	return merged
+}
+
+func (v *Visitor) decodesRecords() bool {
+	return v.Schema != nil || v.Channel != nil || v.Message != nil
+}
+
+// visitorError marks errors returned by Visitor callbacks so they are not
+// mistaken for chunk corruption.
+type visitorError struct {
+	err error
+}
+
+func (e *visitorError) Error() string { return e.err.Error() }
+func (e *visitorError) Unwrap() error { return e.err }
+
+// chunkRecord is a decoded chunk record held back until its chunk verifies.
+type chunkRecord struct {
+	schema  *Schema
+	channel *Channel
+	message *Message
+}
+
+func (v *Visitor) visit(op byte, body []byte) error {
+	rec, err := decodeRecord(op, body)
+	if err != nil {
+		return err
+	}
+	return v.deliver(rec)
+}
+
+func decodeRecord(op byte, body []byte) (chunkRecord, error) {
+	var rec chunkRecord
+	var err error
+	switch op {
+	case OpSchema:
+		rec.schema, err = parseSchema(body)
+	case OpChannel:
+		rec.channel, err = parseChannel(body)
+	case OpMessage:
+		rec.message, err = parseMessage(body)
+	}
+	return rec, err
+}
+
+func (v *Visitor) deliver(rec chunkRecord) error {
+	var err error
+	switch {
+	case rec.schema != nil && v.Schema != nil:
+		err = v.Schema(rec.schema)
+	case rec.channel != nil && v.Channel != nil:
+		err = v.Channel(rec.channel)
+	case rec.message != nil && v.Message != nil:
+		err = v.Message(rec.message)
+	}
+	if err != nil {
+		return &visitorError{err: err}
+	}
+	return nil
+}
+
+func wantsRecord(v *Visitor, op byte) bool {
+	switch op {
+	case OpSchema:
+		return v.Schema != nil
+	case OpChannel:
+		return v.Channel != nil
+	case OpMessage:
+		return v.Message != nil
+	default:
+		return false
+	}
+}
+
+// Scan streams the data section through a single range, from the end of the
+// header record up to the DataEnd record (or the footer when DataEnd is absent).
+//
+// Chunk failures are reported through Visitor.Chunk and do not stop the scan.
+// Scan returns an error when the data section itself is truncated or malformed,
+// or when a callback returns an error.
+func (r *Reader) Scan(v Visitor) error {
+	headerLength, err := r.readHeaderPrefix()
+	if err != nil {
+		return err
+	}
+	start := r.dataStart(headerLength)
+	if start > r.size {
+		return fmt.Errorf("%w: header extends past end of file", ErrTruncated)
+	}
+
+	rc, err := r.src.ReadRange(start, r.size-start)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	br := bufio.NewReaderSize(rc, scanBufferSize)
+
+	offset := start
+	for {
+		op, length, err := readRecordPrefix(br)
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return fmt.Errorf("%w: data section ended at offset %d without data_end record", ErrTruncated, offset)
+			}
+			return err
+		}
+		if length > uint64(r.size-offset-recordPrefixLen) {
+			return fmt.Errorf("%w: %s record at offset %d has length %d", ErrTruncated, OpName(op), offset, length)
+		}
+
+		switch op {
+		case OpDataEnd, OpFooter:
+			return nil
+		case OpChunk:
+			body := &io.LimitedReader{R: br, N: int64(length)}
+			chunk, chunkErr := r.readChunk(body, offset, length, &v)
+			var vErr *visitorError
+			if errors.As(chunkErr, &vErr) {
+				return vErr.err
+			}
+			if _, err := io.Copy(io.Discard, body); err != nil {
+				return err
+			}
+			if body.N > 0 {
+				return fmt.Errorf("%w: chunk at offset %d", ErrTruncated, offset)
+			}
+			if v.Chunk != nil {
+				if err := v.Chunk(chunk, chunkErr); err != nil {
+					return err
+				}
+			}
+		default:
+			if wantsRecord(&v, op) {
+				if int64(length) > r.MaxRecordBytes {
+					return fmt.Errorf("%w: %s record at offset %d has length %d", ErrRecordTooLarge, OpName(op), offset, length)
+				}
+				body := make([]byte, length)
+				if _, err := io.ReadFull(br, body); err != nil {
+					return fmt.Errorf("%w: %s record at offset %d", ErrTruncated, OpName(op), offset)
+				}
+				if err := v.visit(op, body); err != nil {
+					var vErr *visitorError
+					if errors.As(err, &vErr) {
+						return vErr.err
+					}
+					return fmt.Errorf("record at offset %d: %w", offset, err)
+				}
+			} else if _, err := io.CopyN(io.Discard, br, int64(length)); err != nil {
+				return fmt.Errorf("%w: %s record at offset %d", ErrTruncated, OpName(op), offset)
+			}
+		}
+		offset += recordPrefixLen + int64(length)
+	}
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readChunk consumes a chunk record body, decompressing the records and
+// verifying the uncompressed size and CRC before the visitor sees any of
+// them. Errors returned by visitor callbacks are wrapped in *visitorError.
+func (r *Reader) readChunk(body io.Reader, offset int64, length uint64, v *Visitor) (*Chunk, error) {
+	chunk := &Chunk{Offset: offset, Length: recordPrefixLen + int64(length)}
+
+	fixed := make([]byte, 8+8+8+4+4)
+	if _, err := io.ReadFull(body, fixed); err != nil {
+		return chunk, fmt.Errorf("%w: chunk header", ErrTruncated)
+	}
+	f := &fieldReader{buf: fixed}
+	chunk.MessageStartTime = f.uint64()
+	chunk.MessageEndTime = f.uint64()
+	chunk.UncompressedSize = f.uint64()
+	chunk.UncompressedCRC = f.uint32()
+	compressionLength := f.uint32()
+	if compressionLength > maxCompressionLength {
+		return chunk, fmt.Errorf("%w: compression name length %d", ErrTruncated, compressionLength)
+	}
+	tail := make([]byte, int(compressionLength)+8)
+	if _, err := io.ReadFull(body, tail); err != nil {
+		return chunk, fmt.Errorf("%w: chunk header", ErrTruncated)
+	}
+	chunk.Compression = string(tail[:compressionLength])
+	chunk.CompressedSize = binary.LittleEndian.Uint64(tail[compressionLength:])
+
+	headerLength := uint64(len(fixed) + len(tail))
+	if chunk.CompressedSize > length || headerLength+chunk.CompressedSize != length {
+		return chunk, fmt.Errorf("%w: chunk records length %d does not fit record length %d", ErrTruncated, chunk.CompressedSize, length)
+	}
+
+	compressed := io.LimitReader(body, int64(chunk.CompressedSize))
+	decompressed, closeFn, err := newDecompressor(chunk.Compression, compressed)
+	if err != nil {
+		return chunk, err
+	}
+	defer closeFn()
+
+	crc := crc32.NewIEEE()
+	// Read one byte past the declared size so oversized chunks are detected
+	// without decompressing unbounded data.
+	counter := &countingReader{r: io.LimitReader(decompressed, int64(chunk.UncompressedSize)+1)}
+	records := io.TeeReader(bufio.NewReaderSize(counter, chunkBufferSize), crc)
+
+	// Records are buffered until the chunk verifies; the buffer is bounded
+	// by the chunk's uncompressed size.
+	var pending []chunkRecord
+	if v != nil && v.decodesRecords() {
+		if pending, err = r.decodeChunkRecords(records, v); err != nil {
+			return chunk, err
+		}
+	}
+	if _, err := io.Copy(io.Discard, records); err != nil {
+		return chunk, fmt.Errorf("decompress %s chunk: %w", compressionLabel(chunk.Compression), err)
+	}
+
+	if uint64(counter.n) != chunk.UncompressedSize {
+		return chunk, fmt.Errorf("%w: chunk uncompressed size %d, expected %d", ErrTruncated, counter.n, chunk.UncompressedSize)
+	}
+	if chunk.UncompressedCRC != 0 {
+		if got := crc.Sum32(); got != chunk.UncompressedCRC {
+			return chunk, fmt.Errorf("%w: chunk crc stored %08x computed %08x", ErrCRCMismatch, chunk.UncompressedCRC, got)
+		}
+	}
+	for _, rec := range pending {
+		if err := v.deliver(rec); err != nil {
+			return chunk, err
+		}
+	}
+	return chunk, nil
+}
+
+// decodeChunkRecords decodes the records v wants from a chunk's
+// decompressed records.
+func (r *Reader) decodeChunkRecords(records io.Reader, v *Visitor) ([]chunkRecord, error) {
+	var pending []chunkRecord
+	for {
+		op, length, err := readRecordPrefix(records)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return pending, nil
+			}
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("%w: chunk record prefix", ErrTruncated)
+			}
+			return nil, err
+		}
+		if !wantsRecord(v, op) {
+			if _, err := io.CopyN(io.Discard, records, int64(length)); err != nil {
+				return nil, fmt.Errorf("%w: chunk %s record", ErrTruncated, OpName(op))
+			}
+			continue
+		}
+		if int64(length) > r.MaxRecordBytes {
+			return nil, fmt.Errorf("%w: chunk %s record length %d", ErrRecordTooLarge, OpName(op), length)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(records, body); err != nil {
+			return nil, fmt.Errorf("%w: chunk %s record", ErrTruncated, OpName(op))
+		}
+		rec, err := decodeRecord(op, body)
+		if err != nil {
+			return nil, err
+		}
+		pending = append(pending, rec)
+	}
+}
+
+func newDecompressor(compression string, r io.Reader) (io.Reader, func(), error) {
+	switch compression {
+	case CompressionNone:
+		return r, func() {}, nil
+	case CompressionZSTD:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			return nil, nil, fmt.Errorf("open zstd decoder: %w", err)
+		}
+		return dec, dec.Close, nil
+	case CompressionLZ4:
+		return newLZ4FrameReader(r), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnsupportedCompression, compression)
+	}
+}
+
+func compressionLabel(compression string) string {
+	if compression == CompressionNone {
+		return "uncompressed"
+	}
+	return compression
+}
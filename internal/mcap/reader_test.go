@@ -0,0 +1,404 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package mcap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// testFile assembles small MCAP files for reader tests.
+type testFile struct {
+	buf          bytes.Buffer
+	chunkIndexes [][]byte
+	channels     [][]byte
+	schemas      [][]byte
+}
+
+func putU16(b []byte, v uint16) []byte { return binary.LittleEndian.AppendUint16(b, v) }
+func putU32(b []byte, v uint32) []byte { return binary.LittleEndian.AppendUint32(b, v) }
+func putU64(b []byte, v uint64) []byte { return binary.LittleEndian.AppendUint64(b, v) }
+
+func putString(b []byte, s string) []byte {
+	b = putU32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+func record(op byte, body []byte) []byte {
+	out := []byte{op}
+	out = putU64(out, uint64(len(body)))
+	return append(out, body...)
+}
+
+func schemaRecord(id uint16, name string) []byte {
+	body := putU16(nil, id)
+	body = putString(body, name)
+	body = putString(body, "ros2msg")
+	body = putString(body, "")
+	return record(OpSchema, body)
+}
+
+func channelRecord(id, schemaID uint16, topic string) []byte {
+	body := putU16(nil, id)
+	body = putU16(body, schemaID)
+	body = putString(body, topic)
+	body = putString(body, "cdr")
+	body = putU32(body, 0)
+	return record(OpChannel, body)
+}
+
+func messageRecord(channelID uint16, seq uint32, logTime uint64, data []byte) []byte {
+	body := putU16(nil, channelID)
+	body = putU32(body, seq)
+	body = putU64(body, logTime)
+	body = putU64(body, logTime)
+	body = append(body, data...)
+	return record(OpMessage, body)
+}
+
+func newTestFile() *testFile {
+	f := &testFile{}
+	f.buf.Write(Magic)
+	body := putString(nil, "ros2")
+	body = putString(body, "keystone-test")
+	f.buf.Write(record(OpHeader, body))
+	return f
+}
+
+func (f *testFile) addSchemaAndChannel(schemaID, channelID uint16, name, topic string) {
+	s := schemaRecord(schemaID, name)
+	c := channelRecord(channelID, schemaID, topic)
+	f.schemas = append(f.schemas, s)
+	f.channels = append(f.channels, c)
+	f.buf.Write(s)
+	f.buf.Write(c)
+}
+
+func (f *testFile) addChunk(t *testing.T, compression string, records []byte, start, end uint64) int64 {
+	t.Helper()
+	compressed := records
+	switch compression {
+	case CompressionZSTD:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatalf("zstd writer: %v", err)
+		}
+		compressed = enc.EncodeAll(records, nil)
+		_ = enc.Close()
+	case CompressionNone:
+	default:
+		t.Fatalf("unsupported test compression %q", compression)
+	}
+
+	body := putU64(nil, start)
+	body = putU64(body, end)
+	body = putU64(body, uint64(len(records)))
+	body = putU32(body, crc32.ChecksumIEEE(records))
+	body = putString(body, compression)
+	body = putU64(body, uint64(len(compressed)))
+	body = append(body, compressed...)
+
+	offset := int64(f.buf.Len())
+	rec := record(OpChunk, body)
+	f.buf.Write(rec)
+
+	idx := putU64(nil, start)
+	idx = putU64(idx, end)
+	idx = putU64(idx, uint64(offset))
+	idx = putU64(idx, uint64(len(rec)))
+	idx = putU32(idx, 0)
+	idx = putU64(idx, 0)
+	idx = putString(idx, compression)
+	idx = putU64(idx, uint64(len(compressed)))
+	idx = putU64(idx, uint64(len(records)))
+	f.chunkIndexes = append(f.chunkIndexes, record(OpChunkIndex, idx))
+	return offset
+}
+
+func (f *testFile) finish(withSummary bool) []byte {
+	f.buf.Write(record(OpDataEnd, putU32(nil, 0)))
+
+	summaryStart := uint64(0)
+	var summary []byte
+	if withSummary {
+		summaryStart = uint64(f.buf.Len())
+		for _, s := range f.schemas {
+			summary = append(summary, s...)
+		}
+		for _, c := range f.channels {
+			summary = append(summary, c...)
+		}
+		for _, idx := range f.chunkIndexes {
+			summary = append(summary, idx...)
+		}
+		f.buf.Write(summary)
+	}
+
+	footerPrefix := []byte{OpFooter}
+	footerPrefix = putU64(footerPrefix, footerContentLen)
+	footerPrefix = putU64(footerPrefix, summaryStart)
+	footerPrefix = putU64(footerPrefix, 0)
+	crc := uint32(0)
+	if withSummary {
+		crc = crc32.ChecksumIEEE(append(append([]byte(nil), summary...), footerPrefix...))
+	}
+	f.buf.Write(putU32(footerPrefix, crc))
+	f.buf.Write(Magic)
+	return f.buf.Bytes()
+}
+
+func chunkRecords(channelID uint16, logTimes ...uint64) []byte {
+	var out []byte
+	for i, ts := range logTimes {
+		out = append(out, messageRecord(channelID, uint32(i), ts, []byte{byte(i), 0xAA, 0xBB})...)
+	}
+	return out
+}
+
+func readerFor(data []byte) *Reader {
+	return NewReader(ReaderAtRange(bytes.NewReader(data)), int64(len(data)))
+}
+
+func TestReadSummaryDecodesChannelsAndChunkIndexes(t *testing.T) {
+	f := newTestFile()
+	f.addSchemaAndChannel(1, 1, "sensor_msgs/msg/CompressedImage", "/camera/image/compressed")
+	f.addChunk(t, CompressionNone, chunkRecords(1, 100, 200, 300), 100, 300)
+	f.addChunk(t, CompressionZSTD, chunkRecords(1, 400, 500), 400, 500)
+	data := f.finish(true)
+
+	summary, err := readerFor(data).ReadSummary()
+	if err != nil {
+		t.Fatalf("read summary: %v", err)
+	}
+	if summary.Header.Profile != "ros2" {
+		t.Fatalf("profile = %q, want ros2", summary.Header.Profile)
+	}
+	if !summary.HasSummarySection() {
+		t.Fatalf("expected summary section")
+	}
+	if got := summary.Channels[1]; got == nil || got.Topic != "/camera/image/compressed" {
+		t.Fatalf("channel 1 = %+v", got)
+	}
+	if got := summary.Schemas[1]; got == nil || got.Name != "sensor_msgs/msg/CompressedImage" {
+		t.Fatalf("schema 1 = %+v", got)
+	}
+	if len(summary.ChunkIndexes) != 2 {
+		t.Fatalf("chunk index count = %d, want 2", len(summary.ChunkIndexes))
+	}
+	if summary.ChunkIndexes[1].Compression != CompressionZSTD {
+		t.Fatalf("chunk 1 compression = %q", summary.ChunkIndexes[1].Compression)
+	}
+}
+
+func TestReadSummaryDetectsSummaryCRCMismatch(t *testing.T) {
+	f := newTestFile()
+	f.addSchemaAndChannel(1, 1, "std_msgs/msg/String", "/chatter")
+	data := f.finish(true)
+
+	footer, err := readerFor(data).ReadFooter()
+	if err != nil {
+		t.Fatalf("read footer: %v", err)
+	}
+	// Flip a byte inside the channel topic in the summary section.
+	data[int(footer.SummaryStart)+len(f.schemas[0])+20] ^= 0xFF
+
+	summary, err := readerFor(data).ReadSummary()
+	if !errors.Is(err, ErrCRCMismatch) {
+		t.Fatalf("err = %v, want ErrCRCMismatch", err)
+	}
+	if summary == nil {
+		t.Fatalf("expected decoded summary alongside crc error")
+	}
+}
+
+func TestReadFooterRejectsTruncatedFile(t *testing.T) {
+	f := newTestFile()
+	f.addChunk(t, CompressionNone, chunkRecords(1, 1, 2), 1, 2)
+	data := f.finish(true)
+
+	_, err := readerFor(data[:len(data)-10]).ReadFooter()
+	if !errors.Is(err, ErrBadMagic) {
+		t.Fatalf("err = %v, want ErrBadMagic", err)
+	}
+}
+
+func TestScanVerifiesChunksAndVisitsMessages(t *testing.T) {
+	f := newTestFile()
+	f.addSchemaAndChannel(1, 1, "std_msgs/msg/String", "/chatter")
+	f.addChunk(t, CompressionNone, chunkRecords(1, 10, 20), 10, 20)
+	f.addChunk(t, CompressionZSTD, chunkRecords(1, 30, 40, 50), 30, 50)
+	data := f.finish(false)
+
+	var logTimes []uint64
+	var channels []string
+	var chunks int
+	err := readerFor(data).Scan(Visitor{
+		Channel: func(c *Channel) error {
+			channels = append(channels, c.Topic)
+			return nil
+		},
+		Message: func(m *Message) error {
+			logTimes = append(logTimes, m.LogTime)
+			return nil
+		},
+		Chunk: func(c *Chunk, chunkErr error) error {
+			if chunkErr != nil {
+				t.Fatalf("chunk at %d: %v", c.Offset, chunkErr)
+			}
+			chunks++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if chunks != 2 {
+		t.Fatalf("chunks = %d, want 2", chunks)
+	}
+	if len(channels) != 1 || channels[0] != "/chatter" {
+		t.Fatalf("channels = %v", channels)
+	}
+	want := []uint64{10, 20, 30, 40, 50}
+	if len(logTimes) != len(want) {
+		t.Fatalf("log times = %v, want %v", logTimes, want)
+	}
+	for i := range want {
+		if logTimes[i] != want[i] {
+			t.Fatalf("log times = %v, want %v", logTimes, want)
+		}
+	}
+}
+
+func TestMergeVisitorsFeedsEveryConsumer(t *testing.T) {
+	f := newTestFile()
+	f.addSchemaAndChannel(1, 1, "std_msgs/msg/String", "/chatter")
+	f.addChunk(t, CompressionZSTD, chunkRecords(1, 10, 20), 10, 20)
+	data := f.finish(false)
+
+	var messagesA, messagesB, chunks int
+	var topic string
+	err := readerFor(data).Scan(MergeVisitors(
+		Visitor{Message: func(*Message) error { messagesA++; return nil }},
+		Visitor{
+			Channel: func(c *Channel) error { topic = c.Topic; return nil },
+			Message: func(*Message) error { messagesB++; return nil },
+		},
+		Visitor{Chunk: func(*Chunk, error) error { chunks++; return nil }},
+	))
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if messagesA != 2 || messagesB != 2 || chunks != 1 || topic != "/chatter" {
+		t.Fatalf("messages = %d/%d, chunks = %d, topic = %q", messagesA, messagesB, chunks, topic)
+	}
+
+	merged := MergeVisitors(Visitor{Chunk: func(*Chunk, error) error { return nil }})
+	if merged.decodesRecords() {
+		t.Fatal("merged chunk-only visitor decodes records")
+	}
+}
+
+func TestScanReportsCorruptChunkAndContinues(t *testing.T) {
+	f := newTestFile()
+	first := f.addChunk(t, CompressionNone, chunkRecords(1, 10, 20), 10, 20)
+	f.addChunk(t, CompressionNone, chunkRecords(1, 30, 40), 30, 40)
+	data := f.finish(true)
+
+	// Corrupt a message payload byte inside the first (uncompressed) chunk.
+	data[first+int64(len(data[first:first+80]))-3] ^= 0xFF
+
+	var failures, ok int
+	var logTimes []uint64
+	err := readerFor(data).Scan(Visitor{
+		Message: func(m *Message) error {
+			logTimes = append(logTimes, m.LogTime)
+			return nil
+		},
+		Chunk: func(c *Chunk, chunkErr error) error {
+			if chunkErr != nil {
+				if !errors.Is(chunkErr, ErrCRCMismatch) {
+					t.Fatalf("chunk error = %v, want ErrCRCMismatch", chunkErr)
+				}
+				if c.Offset != first {
+					t.Fatalf("failed chunk offset = %d, want %d", c.Offset, first)
+				}
+				failures++
+				return nil
+			}
+			ok++
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if failures != 1 || ok != 1 {
+		t.Fatalf("failures = %d, ok = %d, want 1 and 1", failures, ok)
+	}
+	// Messages of the corrupt chunk are never delivered.
+	if len(logTimes) != 2 || logTimes[0] != 30 || logTimes[1] != 40 {
+		t.Fatalf("delivered log times = %v, want [30 40]", logTimes)
+	}
+}
+
+func TestScanDetectsTruncatedDataSection(t *testing.T) {
+	f := newTestFile()
+	f.addChunk(t, CompressionNone, chunkRecords(1, 10, 20, 30), 10, 30)
+	data := f.finish(false)
+
+	err := readerFor(data[:len(data)/2]).Scan(Visitor{})
+	if !errors.Is(err, ErrTruncated) {
+		t.Fatalf("err = %v, want ErrTruncated", err)
+	}
+}
+
+func TestScanRejectsUnsupportedCompression(t *testing.T) {
+	records := chunkRecords(1, 10)
+	body := putU64(nil, 10)
+	body = putU64(body, 10)
+	body = putU64(body, uint64(len(records)))
+	body = putU32(body, crc32.ChecksumIEEE(records))
+	body = putString(body, "bz2")
+	body = putU64(body, uint64(len(records)))
+	body = append(body, records...)
+
+	f := newTestFile()
+	f.buf.Write(record(OpChunk, body))
+	data := f.finish(false)
+
+	var chunkErr error
+	if err := readerFor(data).Scan(Visitor{Chunk: func(_ *Chunk, err error) error {
+		chunkErr = err
+		return nil
+	}}); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !errors.Is(chunkErr, ErrUnsupportedCompression) {
+		t.Fatalf("chunk error = %v, want ErrUnsupportedCompression", chunkErr)
+	}
+}
+
+func TestLZ4FrameReaderDecodesLinkedMatches(t *testing.T) {
+	// Literals "abc", then a match of 9 bytes at offset 3, then the final literal "d".
+	block := []byte{0x35, 'a', 'b', 'c', 0x03, 0x00, 0x10, 'd'}
+	frame := putU32(nil, lz4FrameMagic)
+	frame = append(frame, 0x60, 0x40, 0x00) // version 1, independent blocks, 64 KiB max, header checksum
+	frame = putU32(frame, uint32(len(block)))
+	frame = append(frame, block...)
+	frame = putU32(frame, 0)
+
+	var out bytes.Buffer
+	if _, err := out.ReadFrom(newLZ4FrameReader(bytes.NewReader(frame))); err != nil {
+		t.Fatalf("decode lz4 frame: %v", err)
+	}
+	if got := out.String(); got != "abcabcabcabcd" {
+		t.Fatalf("decoded = %q, want %q", got, "abcabcabcabcd")
+	}
+}
@@ -6,13 +6,16 @@
 package middleware
 
 import (
+	"context"
 	"crypto/sha256"
 	"crypto/subtle"
+	"errors"
 	"net/http"
 	"strings"
 
 	"archebase.com/keystone-edge/internal/auth"
 	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/logger"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,36 +26,43 @@ const ClaimsKey = "auth_claims"
 // dashboard display token rather than a normal user JWT.
 const DashboardDisplayKey = "dashboard_display_auth"
 
-// JWTAuth validates JWT tokens.
+// APIKeyValidator authenticates Authorization: ApiKey <key> credentials and
+// returns the claims the key acts with.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key, clientIP string) (*auth.Claims, error)
+}
+
+var (
+	// ErrAPIKeyInvalid indicates an unknown, revoked or expired API key.
+	ErrAPIKeyInvalid = errors.New("invalid or expired api key")
+	// ErrAPIKeyIPNotAllowed indicates a valid API key used from an address
+	// outside its allowlist.
+	ErrAPIKeyIPNotAllowed = errors.New("api key not allowed from this address")
+)
+
+// JWTAuth validates JWT tokens, and API keys when apiKeys is non-nil.
+// Requests already authenticated by RoutePermissions keep their claims.
 // Header: Authorization: Bearer <jwt_token>
-func JWTAuth(cfg *config.AuthConfig) gin.HandlerFunc {
+// Header: Authorization: ApiKey <api_key>
+func JWTAuth(cfg *config.AuthConfig, apiKeys APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
-			return
-		}
-
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+		if GetClaims(c) != nil && !c.GetBool(DashboardDisplayKey) {
+			c.Next()
 			return
 		}
-
-		claims, err := auth.ParseToken(parts[1], cfg)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		claims, ok := authenticateRequest(c, cfg, apiKeys)
+		if !ok {
 			return
 		}
-
 		c.Set(ClaimsKey, claims)
 		c.Next()
 	}
 }
 
-// DashboardAuth allows a normal Bearer JWT or the optional production dashboard
-// display token. It must only be mounted on production-dashboard read routes.
-func DashboardAuth(cfg *config.AuthConfig) gin.HandlerFunc {
+// DashboardAuth allows a normal Bearer JWT, an API key or the optional
+// production dashboard display token. It must only be mounted on
+// production-dashboard read routes.
+func DashboardAuth(cfg *config.AuthConfig, apiKeys APIKeyValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if IsDashboardDisplayRequest(c) {
 			if !IsDashboardDisplayToken(c, cfg) {
@@ -65,27 +75,52 @@ func DashboardAuth(cfg *config.AuthConfig) gin.HandlerFunc {
 			return
 		}
 
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		claims, ok := authenticateRequest(c, cfg, apiKeys)
+		if !ok {
 			return
 		}
+		c.Set(ClaimsKey, claims)
+		c.Next()
+	}
+}
 
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			return
-		}
+// authenticateRequest resolves the claims of a Bearer JWT or ApiKey
+// Authorization header, aborting the request when the credentials are missing
+// or invalid.
+func authenticateRequest(c *gin.Context, cfg *config.AuthConfig, apiKeys APIKeyValidator) (*auth.Claims, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return nil, false
+	}
 
-		claims, err := auth.ParseToken(parts[1], cfg)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			return
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) == 2 && strings.EqualFold(parts[0], "ApiKey") && apiKeys != nil {
+		claims, err := apiKeys.ValidateAPIKey(c.Request.Context(), strings.TrimSpace(parts[1]), c.ClientIP())
+		switch {
+		case err == nil:
+			return claims, true
+		case errors.Is(err, ErrAPIKeyInvalid):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": ErrAPIKeyInvalid.Error()})
+		case errors.Is(err, ErrAPIKeyIPNotAllowed):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": ErrAPIKeyIPNotAllowed.Error()})
+		default:
+			logger.Printf("[AUTH] Failed to validate api key: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "service unavailable"})
 		}
+		return nil, false
+	}
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+		return nil, false
+	}
 
-		c.Set(ClaimsKey, claims)
-		c.Next()
+	claims, err := auth.ParseToken(parts[1], cfg)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
+		return nil, false
 	}
+	return claims, true
 }
 
 // IsDashboardDisplayRequest reports whether the request uses the Display auth
@@ -5,10 +5,13 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"archebase.com/keystone-edge/internal/auth"
 	"archebase.com/keystone-edge/internal/config"
 	"github.com/gin-gonic/gin"
 )
@@ -47,7 +50,7 @@ func TestIsDashboardDisplayToken(t *testing.T) {
 func TestDashboardAuthAcceptsDisplayToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.GET("/dashboard", DashboardAuth(&config.AuthConfig{DashboardDisplayToken: "display-secret"}), func(c *gin.Context) {
+	router.GET("/dashboard", DashboardAuth(&config.AuthConfig{DashboardDisplayToken: "display-secret"}, nil), func(c *gin.Context) {
 		claims := GetClaims(c)
 		if claims == nil || claims.Role != "display" {
 			t.Fatalf("claims = %#v, want display claims", claims)
@@ -67,3 +70,88 @@ func TestDashboardAuthAcceptsDisplayToken(t *testing.T) {
 		t.Fatalf("status = %d, want %d; body=%s", w.Code, http.StatusNoContent, w.Body.String())
 	}
 }
+
+type stubAPIKeyValidator map[string]error
+
+func (v stubAPIKeyValidator) ValidateAPIKey(_ context.Context, key, _ string) (*auth.Claims, error) {
+	err, ok := v[key]
+	if !ok {
+		return nil, ErrAPIKeyInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return auth.NewAPIKeyClaims(1, []string{PermissionOrderWrite}), nil
+}
+
+func TestJWTAuthAcceptsAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator := stubAPIKeyValidator{
+		"good":     nil,
+		"wrong-ip": ErrAPIKeyIPNotAllowed,
+		"db-down":  errors.New("connection refused"),
+	}
+	router := gin.New()
+	router.GET("/orders", JWTAuth(&config.AuthConfig{JWTSecret: "secret"}, validator), RequirePermission(nil, PermissionOrderWrite), func(c *gin.Context) {
+		if claims := GetClaims(c); claims == nil || claims.Role != auth.RoleAPIKey {
+			t.Fatalf("claims = %#v, want api key claims", claims)
+		}
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/sync", JWTAuth(&config.AuthConfig{JWTSecret: "secret"}, validator), RequirePermission(nil, PermissionSyncTrigger), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, tt := range []struct {
+		path, authorization string
+		want                int
+	}{
+		{"/orders", "ApiKey good", http.StatusNoContent},
+		{"/sync", "ApiKey good", http.StatusForbidden},
+		{"/orders", "ApiKey unknown", http.StatusUnauthorized},
+		{"/orders", "ApiKey wrong-ip", http.StatusForbidden},
+		{"/orders", "ApiKey db-down", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Authorization", tt.authorization)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Fatalf("%s with %q = %d, want %d", tt.path, tt.authorization, w.Code, tt.want)
+		}
+	}
+}
+
+func TestJWTAuthKeepsClaimsFromRoutePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	calls := 0
+	validator := countingAPIKeyValidator{stubAPIKeyValidator{"good": nil}, &calls}
+	cfg := &config.AuthConfig{JWTSecret: "secret"}
+	router := gin.New()
+	router.Use(RoutePermissions(cfg, validator, nil, map[string]string{"POST /qa/inspections": PermissionOrderWrite}, nil))
+	group := router.Group("", JWTAuth(cfg, validator))
+	group.POST("/qa/inspections", func(c *gin.Context) {
+		if claims := GetClaims(c); claims == nil || claims.Role != auth.RoleAPIKey {
+			t.Fatalf("claims = %#v, want api key claims", claims)
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/qa/inspections", nil)
+	req.Header.Set("Authorization", "ApiKey good")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent || calls != 1 {
+		t.Fatalf("status = %d, validations = %d; want 204 and 1", w.Code, calls)
+	}
+}
+
+type countingAPIKeyValidator struct {
+	stubAPIKeyValidator
+	calls *int
+}
+
+func (v countingAPIKeyValidator) ValidateAPIKey(ctx context.Context, key, ip string) (*auth.Claims, error) {
+	*v.calls++
+	return v.stubAPIKeyValidator.ValidateAPIKey(ctx, key, ip)
+}
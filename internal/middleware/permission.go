@@ -0,0 +1,296 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// Permissions checked by RequirePermission and RoutePermissions.
+const (
+	PermissionAll                = "*"
+	PermissionOrderWrite         = "order:write"
+	PermissionEpisodeInspect     = "episode:inspect"
+	PermissionSyncTrigger        = "sync:trigger"
+	PermissionSyncConfigure      = "sync:configure"
+	PermissionQAConfigure        = "qa:configure"
+	PermissionRobotConfigure     = "robot:configure"
+	PermissionDeviceManage       = "device:manage"
+	PermissionStatisticsRead     = "statistics:read"
+	PermissionStatisticsReadOwn  = "statistics:read_own"
+	PermissionTaskStatisticsRead = "task_statistics:read"
+	PermissionTaskCollect        = "task:collect"
+	PermissionDataOpsManage      = "data_ops:manage"
+	PermissionAuditLogRead       = "audit_log:read"
+	PermissionWebhookManage      = "webhook:manage"
+	PermissionRBACManage         = "rbac:manage"
+	PermissionAPIKeyManage       = "api_key:manage"
+	PermissionDashboardRead      = "dashboard:read"
+)
+
+// Scope types of a PermissionGrant.
+const (
+	PermissionScopeGlobal       = "global"
+	PermissionScopeOrganization = "organization"
+	PermissionScopeFactory      = "factory"
+)
+
+// PermissionScopeKey is the gin.Context key holding the scopes that authorized
+// a request through scoped grants.
+const PermissionScopeKey = "permission_scopes"
+
+// PermissionDescriptions lists every known permission with its description.
+var PermissionDescriptions = map[string]string{
+	PermissionAll:                "All permissions",
+	PermissionOrderWrite:         "Create, update and delete orders",
+	PermissionEpisodeInspect:     "Claim and submit episode inspections and annotations",
+	PermissionSyncTrigger:        "Trigger episode cloud sync and resync",
+	PermissionSyncConfigure:      "Change sync bandwidth limits and windows; pause, resume and drain sync",
+	PermissionQAConfigure:        "Manage QA profiles, scripts and audit policies",
+	PermissionRobotConfigure:     "Manage robot type config templates",
+	PermissionDeviceManage:       "Manage device credentials",
+	PermissionStatisticsRead:     "Read and export data production statistics",
+	PermissionStatisticsReadOwn:  "Read own data production statistics",
+	PermissionTaskStatisticsRead: "Read task statistics",
+	PermissionTaskCollect:        "Operate a workstation and complete collection tasks",
+	PermissionDataOpsManage:      "Run data operations",
+	PermissionAuditLogRead:       "Read the API audit log",
+	PermissionWebhookManage:      "Manage webhook subscriptions",
+	PermissionRBACManage:         "Manage roles and role assignments",
+	PermissionAPIKeyManage:       "Create, rotate and revoke API keys",
+	PermissionDashboardRead:      "Read the production dashboard",
+}
+
+// DefaultRolePermissions are the permissions of the system roles carried in
+// the JWT role claim. They seed the roles table and back the resolver when no
+// database is configured.
+var DefaultRolePermissions = map[string][]string{
+	"admin": {PermissionAll},
+	"data_collector": {
+		PermissionTaskCollect,
+		PermissionStatisticsReadOwn,
+		PermissionTaskStatisticsRead,
+		PermissionDashboardRead,
+	},
+	"display": {PermissionDashboardRead},
+}
+
+// PermissionGrant is one permission held by a caller, either globally or
+// within a single organization or factory.
+type PermissionGrant struct {
+	Permission string
+	ScopeType  string
+	ScopeID    int64
+}
+
+// PermissionScope is an organization or factory a scoped grant applies to.
+type PermissionScope struct {
+	Type string
+	ID   int64
+}
+
+// RequestScope is the organization and factory a request acts on. Zero IDs
+// are unknown.
+type RequestScope struct {
+	OrganizationID int64
+	FactoryID      int64
+}
+
+// RequestScopeFunc resolves the scope of a request from the entity it
+// targets, named by its path or its body.
+type RequestScopeFunc func(c *gin.Context) (RequestScope, error)
+
+// PermissionResolver returns the permissions granted to an authenticated caller.
+type PermissionResolver interface {
+	ResolvePermissions(ctx context.Context, claims *auth.Claims) ([]PermissionGrant, error)
+}
+
+// StaticPermissionResolver grants each JWT role a fixed global permission set.
+type StaticPermissionResolver map[string][]string
+
+// ResolvePermissions implements PermissionResolver.
+func (r StaticPermissionResolver) ResolvePermissions(_ context.Context, claims *auth.Claims) ([]PermissionGrant, error) {
+	var grants []PermissionGrant
+	for _, p := range r[claims.Role] {
+		grants = append(grants, PermissionGrant{Permission: p, ScopeType: PermissionScopeGlobal})
+	}
+	return grants, nil
+}
+
+// RequirePermission allows requests whose caller holds permission. JWTAuth
+// (or DashboardAuth) must run before this middleware. A grant scoped to an
+// organization or factory only applies when the route names that scope via
+// an organization_id or factory_id path parameter; the query string never
+// confers scope. The matching scopes are stored under PermissionScopeKey.
+func RequirePermission(resolver PermissionResolver, permission string) gin.HandlerFunc {
+	return requirePermission(resolver, permission, false)
+}
+
+// RequireFilteredPermission is RequirePermission for list and report
+// handlers that narrow their results to GetPermissionScopes: a scoped grant
+// authorizes any request, with every scope the caller holds for permission
+// stored under PermissionScopeKey.
+func RequireFilteredPermission(resolver PermissionResolver, permission string) gin.HandlerFunc {
+	return requirePermission(resolver, permission, true)
+}
+
+func requirePermission(resolver PermissionResolver, permission string, filtered bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetClaims(c)
+		if claims == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+			return
+		}
+		if !authorizePermission(c, resolver, claims, permission, nil, filtered) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RoutePermissions enforces a permission on individual routes, keyed by
+// "METHOD /full/route/path". Matched routes require a Bearer JWT or API key;
+// routes not in the table pass through unchanged. scopes, keyed the same way,
+// resolves the organization or factory of the entity a route acts on, so
+// scoped grants also apply to it; it is only consulted for callers without a
+// global grant. Scoped grants are denied on routes with neither a resolver
+// nor a scope path parameter.
+func RoutePermissions(cfg *config.AuthConfig, apiKeys APIKeyValidator, resolver PermissionResolver, routes map[string]string, scopes map[string]RequestScopeFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.Request.Method + " " + c.FullPath()
+		permission, ok := routes[route]
+		if !ok {
+			c.Next()
+			return
+		}
+		claims := GetClaims(c)
+		if claims == nil {
+			if claims, ok = authenticateRequest(c, cfg, apiKeys); !ok {
+				return
+			}
+			c.Set(ClaimsKey, claims)
+		}
+		if !authorizePermission(c, resolver, claims, permission, scopes[route], false) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetPermissionScopes returns the organization/factory scopes that authorized
+// the request, or nil when it was authorized by a global grant.
+func GetPermissionScopes(c *gin.Context) []PermissionScope {
+	if v, ok := c.Get(PermissionScopeKey); ok {
+		return v.([]PermissionScope)
+	}
+	return nil
+}
+
+// ResolvePermissionGrants returns the grants claims act with: an API key's
+// scopes, or what resolver grants the caller's roles. A nil resolver falls
+// back to DefaultRolePermissions.
+func ResolvePermissionGrants(ctx context.Context, resolver PermissionResolver, claims *auth.Claims) ([]PermissionGrant, error) {
+	if claims.Role == auth.RoleAPIKey {
+		// API keys carry their scopes and hold no roles.
+		grants := make([]PermissionGrant, 0, len(claims.Scopes))
+		for _, p := range claims.Scopes {
+			grants = append(grants, PermissionGrant{Permission: p, ScopeType: PermissionScopeGlobal})
+		}
+		return grants, nil
+	}
+	if resolver == nil {
+		resolver = StaticPermissionResolver(DefaultRolePermissions)
+	}
+	return resolver.ResolvePermissions(ctx, claims)
+}
+
+// authorizePermission checks permission against the caller's grants. A
+// scoped grant matches the scope named by the route's path or resolved by
+// scopeFn; with filtered every scoped grant matches and the handler narrows
+// its results instead.
+func authorizePermission(c *gin.Context, resolver PermissionResolver, claims *auth.Claims, permission string, scopeFn RequestScopeFunc, filtered bool) bool {
+	grants, err := ResolvePermissionGrants(c.Request.Context(), resolver, claims)
+	if err != nil {
+		logger.Printf("[RBAC] Failed to resolve permissions for role=%s operator=%s: %v", claims.Role, claims.OperatorID, err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve permissions"})
+		return false
+	}
+
+	var scopes []PermissionScope
+	var target *RequestScope
+	for _, g := range grants {
+		if g.Permission != permission && g.Permission != PermissionAll {
+			continue
+		}
+		if g.ScopeType == "" || g.ScopeType == PermissionScopeGlobal {
+			return true
+		}
+		if filtered {
+			scopes = append(scopes, PermissionScope{Type: g.ScopeType, ID: g.ScopeID})
+			continue
+		}
+		id := requestScopeID(c, g.ScopeType)
+		if id == 0 && scopeFn != nil {
+			if target == nil {
+				resolved, err := scopeFn(c)
+				if err != nil {
+					logger.Printf("[RBAC] Failed to resolve request scope of %s %s: %v", c.Request.Method, c.FullPath(), err)
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve permissions"})
+					return false
+				}
+				target = &resolved
+			}
+			id = target.id(g.ScopeType)
+		}
+		if id != 0 && id == g.ScopeID {
+			scopes = append(scopes, PermissionScope{Type: g.ScopeType, ID: g.ScopeID})
+		}
+	}
+	if len(scopes) == 0 {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return false
+	}
+	c.Set(PermissionScopeKey, scopes)
+	return true
+}
+
+func (s RequestScope) id(scopeType string) int64 {
+	switch scopeType {
+	case PermissionScopeOrganization:
+		return s.OrganizationID
+	case PermissionScopeFactory:
+		return s.FactoryID
+	default:
+		return 0
+	}
+}
+
+// requestScopeID returns the organization or factory ID named by a path
+// parameter of the route, or 0. Query parameters are caller-controlled on
+// any route and are deliberately not consulted.
+func requestScopeID(c *gin.Context, scopeType string) int64 {
+	var key string
+	switch scopeType {
+	case PermissionScopeOrganization:
+		key = "organization_id"
+	case PermissionScopeFactory:
+		key = "factory_id"
+	default:
+		return 0
+	}
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param(key)), 10, 64)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
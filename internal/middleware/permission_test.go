@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+type grantsResolver []PermissionGrant
+
+func (r grantsResolver) ResolvePermissions(context.Context, *auth.Claims) ([]PermissionGrant, error) {
+	return r, nil
+}
+
+type failingResolver struct{}
+
+func (failingResolver) ResolvePermissions(context.Context, *auth.Claims) ([]PermissionGrant, error) {
+	return nil, errors.New("database down")
+}
+
+func permissionTestRouter(claims *auth.Claims, resolver PermissionResolver) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if claims != nil {
+			c.Set(ClaimsKey, claims)
+		}
+		c.Next()
+	})
+	router.GET("/orders", RequirePermission(resolver, PermissionOrderWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"scopes": GetPermissionScopes(c)})
+	})
+	router.GET("/factories/:factory_id/orders", RequirePermission(resolver, PermissionOrderWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"scopes": GetPermissionScopes(c)})
+	})
+	return router
+}
+
+func serveStatus(router *gin.Engine, method, path, authorization string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRequirePermissionDefaultRoles(t *testing.T) {
+	cases := []struct {
+		name   string
+		claims *auth.Claims
+		want   int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"admin wildcard", auth.NewAdminClaims(), http.StatusOK},
+		{"collector lacks permission", auth.NewCollectorClaims(1, "op-1"), http.StatusForbidden},
+		{"unknown role", &auth.Claims{Role: "guest"}, http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := serveStatus(permissionTestRouter(tc.claims, nil), http.MethodGet, "/orders", ""); got != tc.want {
+				t.Fatalf("status = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermissionScopedGrant(t *testing.T) {
+	resolver := grantsResolver{{Permission: PermissionOrderWrite, ScopeType: PermissionScopeFactory, ScopeID: 7}}
+	router := permissionTestRouter(auth.NewCollectorClaims(1, "op-1"), resolver)
+
+	if got := serveStatus(router, http.MethodGet, "/factories/7/orders", ""); got != http.StatusOK {
+		t.Fatalf("matching factory = %d, want 200", got)
+	}
+	// The query string is caller-controlled and never confers scope.
+	if got := serveStatus(router, http.MethodGet, "/orders?factory_id=7", ""); got != http.StatusForbidden {
+		t.Fatalf("factory named only in the query = %d, want 403", got)
+	}
+	if got := serveStatus(router, http.MethodGet, "/factories/8/orders", ""); got != http.StatusForbidden {
+		t.Fatalf("other factory = %d, want 403", got)
+	}
+	if got := serveStatus(router, http.MethodGet, "/orders", ""); got != http.StatusForbidden {
+		t.Fatalf("unscoped request = %d, want 403", got)
+	}
+
+	failing := permissionTestRouter(auth.NewCollectorClaims(1, "op-1"), failingResolver{})
+	if got := serveStatus(failing, http.MethodGet, "/orders", ""); got != http.StatusInternalServerError {
+		t.Fatalf("resolver error = %d, want 500", got)
+	}
+}
+
+func TestRequireFilteredPermissionPassesScopesToHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(ClaimsKey, auth.NewCollectorClaims(1, "op-1"))
+		c.Next()
+	})
+	resolver := grantsResolver{{Permission: PermissionDashboardRead, ScopeType: PermissionScopeFactory, ScopeID: 7}}
+	var got []PermissionScope
+	router.GET("/dashboard", RequireFilteredPermission(resolver, PermissionDashboardRead), func(c *gin.Context) {
+		got = GetPermissionScopes(c)
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/rbac", RequirePermission(resolver, PermissionDashboardRead), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	if code := serveStatus(router, http.MethodGet, "/dashboard", ""); code != http.StatusNoContent || len(got) != 1 || got[0].ID != 7 {
+		t.Fatalf("filtered route = %d with scopes %v, want 204 with factory 7", code, got)
+	}
+	if code := serveStatus(router, http.MethodGet, "/rbac?factory_id=7", ""); code != http.StatusForbidden {
+		t.Fatalf("unfiltered route with scope query = %d, want 403", code)
+	}
+}
+
+func TestRoutePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{JWTSecret: "permission-test-secret", Issuer: "keystone-edge", JWTExpiryHours: 1}
+	router := gin.New()
+	router.Use(RoutePermissions(authCfg, nil, nil, map[string]string{"POST /orders": PermissionOrderWrite}, nil))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.GET("/orders", ok)
+	router.POST("/orders", ok)
+
+	adminToken, err := auth.GenerateToken(auth.NewAdminClaims(), authCfg)
+	if err != nil {
+		t.Fatalf("generate admin token: %v", err)
+	}
+	collectorToken, err := auth.GenerateToken(auth.NewCollectorClaims(3, "op-3"), authCfg)
+	if err != nil {
+		t.Fatalf("generate collector token: %v", err)
+	}
+
+	for _, tc := range []struct {
+		method, authorization string
+		want                  int
+	}{
+		{http.MethodGet, "", http.StatusNoContent},
+		{http.MethodPost, "", http.StatusUnauthorized},
+		{http.MethodPost, "Bearer not-a-token", http.StatusUnauthorized},
+		{http.MethodPost, "Bearer " + collectorToken, http.StatusForbidden},
+		{http.MethodPost, "Bearer " + adminToken, http.StatusNoContent},
+	} {
+		if got := serveStatus(router, tc.method, "/orders", tc.authorization); got != tc.want {
+			t.Fatalf("%s /orders with %q = %d, want %d", tc.method, tc.authorization, got, tc.want)
+		}
+	}
+}
+
+func TestRoutePermissionsResolvesRequestScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	lookups := 0
+	scopeOf := func(c *gin.Context) (RequestScope, error) {
+		lookups++
+		if c.Param("id") == "5" {
+			return RequestScope{OrganizationID: 2, FactoryID: 7}, nil
+		}
+		return RequestScope{}, nil
+	}
+	newRouter := func(claims *auth.Claims, resolver PermissionResolver) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(ClaimsKey, claims)
+			c.Next()
+		})
+		router.Use(RoutePermissions(&config.AuthConfig{}, nil, resolver,
+			map[string]string{"PUT /orders/:id": PermissionOrderWrite},
+			map[string]RequestScopeFunc{"PUT /orders/:id": scopeOf}))
+		router.PUT("/orders/:id", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"scopes": GetPermissionScopes(c)})
+		})
+		return router
+	}
+
+	scoped := newRouter(auth.NewCollectorClaims(1, "op-1"), grantsResolver{
+		{Permission: PermissionOrderWrite, ScopeType: PermissionScopeFactory, ScopeID: 7},
+		{Permission: PermissionOrderWrite, ScopeType: PermissionScopeOrganization, ScopeID: 2},
+	})
+	if got := serveStatus(scoped, http.MethodPut, "/orders/5", ""); got != http.StatusOK || lookups != 1 {
+		t.Fatalf("order in granted scope = %d after %d lookups, want 200 after 1", got, lookups)
+	}
+	if got := serveStatus(scoped, http.MethodPut, "/orders/6", ""); got != http.StatusForbidden {
+		t.Fatalf("order outside granted scope = %d, want 403", got)
+	}
+
+	lookups = 0
+	if got := serveStatus(newRouter(auth.NewAdminClaims(), nil), http.MethodPut, "/orders/6", ""); got != http.StatusOK || lookups != 0 {
+		t.Fatalf("global grant = %d after %d lookups, want 200 without a lookup", got, lookups)
+	}
+}
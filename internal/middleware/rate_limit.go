@@ -0,0 +1,180 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitIdleTTL is how long an untouched bucket is kept; a full bucket
+// that has been idle this long is indistinguishable from a new one.
+const rateLimitIdleTTL = 10 * time.Minute
+
+// TokenBucketLimiter keeps one token bucket per client key. Buckets refill at
+// rate tokens per second up to burst.
+type TokenBucketLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter creates a limiter allowing rate requests per second
+// with bursts of up to burst. A burst below 1 is raised to 1.
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns
+// false and how long until a token is available.
+func (l *TokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= rateLimitIdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) >= rateLimitIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, rateLimitIdleTTL
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// RateLimitRule applies Limiter to requests whose route (or path, for
+// unmatched requests) starts with one of PathPrefixes.
+type RateLimitRule struct {
+	Name         string
+	PathPrefixes []string
+	Limiter      *TokenBucketLimiter
+}
+
+func (r RateLimitRule) matches(path string) bool {
+	for _, prefix := range r.PathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RateLimit rejects requests over their client's budget with 429 and a
+// Retry-After header. The first matching rule applies; requests matching no
+// rule use fallback. Rules have independent buckets, so traffic in one group
+// never consumes another group's budget. A nil Limiter disables a rule.
+// apiKeys may be nil, in which case API key requests are keyed by IP.
+func RateLimit(authCfg *config.AuthConfig, apiKeys APIKeyValidator, rules []RateLimitRule, fallback RateLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			path = c.Request.URL.Path
+		}
+		rule := fallback
+		for _, r := range rules {
+			if r.matches(path) {
+				rule = r
+				break
+			}
+		}
+		if rule.Limiter == nil {
+			c.Next()
+			return
+		}
+
+		ok, wait := rule.Limiter.Allow(RateLimitClientKey(c, authCfg, apiKeys))
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(math.Max(wait.Seconds(), 1)))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RateLimitClientKey identifies the caller for rate limiting: the JWT subject
+// or API key ID, the valid display token, or the client IP for anonymous or
+// invalid credentials and for JWTs without a subject (admin tokens), so
+// rotating bogus tokens cannot mint fresh budgets and callers never share a
+// role-wide bucket. The display token itself is never used as a key, only a
+// hash prefix of it. A validated API key's claims are kept on the context so
+// route authentication does not look the key up again.
+func RateLimitClientKey(c *gin.Context, authCfg *config.AuthConfig, apiKeys APIKeyValidator) string {
+	if claims := GetClaims(c); claims != nil {
+		return rateLimitClaimsKey(c, claims)
+	}
+	scheme, token, ok := splitAuthorization(c.GetHeader("Authorization"))
+	if ok && strings.EqualFold(scheme, "Bearer") && authCfg != nil {
+		if claims, err := auth.ParseToken(token, authCfg); err == nil {
+			return rateLimitClaimsKey(c, claims)
+		}
+	}
+	if ok && strings.EqualFold(scheme, "ApiKey") && apiKeys != nil {
+		if claims, err := apiKeys.ValidateAPIKey(c.Request.Context(), strings.TrimSpace(token), c.ClientIP()); err == nil {
+			c.Set(ClaimsKey, claims)
+			return rateLimitClaimsKey(c, claims)
+		}
+	}
+	if ok && strings.EqualFold(scheme, "Display") && IsDashboardDisplayToken(c, authCfg) {
+		sum := sha256.Sum256([]byte(token))
+		return "display:" + hex.EncodeToString(sum[:8])
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func rateLimitClaimsKey(c *gin.Context, claims *auth.Claims) string {
+	if id := strings.TrimSpace(claims.OperatorID); id != "" {
+		return "sub:" + id
+	}
+	if claims.Subject != "" {
+		return "sub:" + claims.Subject
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestTokenBucketLimiterRefills(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := NewTokenBucketLimiter(2, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d denied within burst", i)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("over burst = %v, wait %s; want denied, 500ms", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatal("other key shares the bucket")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("bucket did not refill")
+	}
+}
+
+func TestRateLimitSeparatesRouteGroupsAndClients(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{JWTSecret: "rate-limit-test-secret", Issuer: "keystone-edge", JWTExpiryHours: 1, DashboardDisplayToken: "display-secret"}
+	router := gin.New()
+	router.Use(RateLimit(authCfg, nil, []RateLimitRule{
+		{Name: "device", PathPrefixes: []string{"/api/v1/callbacks/"}, Limiter: NewTokenBucketLimiter(0.001, 1)},
+	}, RateLimitRule{Name: "default", Limiter: NewTokenBucketLimiter(0.001, 1)}))
+	ok := func(c *gin.Context) { c.Status(http.StatusNoContent) }
+	router.GET("/api/v1/production/dashboard/snapshot", ok)
+	router.POST("/api/v1/callbacks/finish", ok)
+
+	do := func(method, path, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "10.0.0.5:1234"
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("first = %d", rec.Code)
+	}
+	rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("over budget = %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	// Exhausted UI budget from the same IP does not touch the callback budget.
+	if rec := do(http.MethodPost, "/api/v1/callbacks/finish", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("callback = %d, want 204", rec.Code)
+	}
+	// An invalid display token is keyed by IP and gets no fresh budget.
+	if rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", "Display wrong"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("bogus display token = %d, want 429", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", "Display display-secret"); rec.Code != http.StatusNoContent {
+		t.Fatalf("display token = %d, want 204", rec.Code)
+	}
+	token, err := auth.GenerateToken(auth.NewCollectorClaims(7, "op-7"), authCfg)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", "Bearer "+token); rec.Code != http.StatusNoContent {
+		t.Fatalf("jwt subject = %d, want 204", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/v1/production/dashboard/snapshot", "Bearer "+token); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("jwt subject over budget = %d, want 429", rec.Code)
+	}
+}
+
+func TestRateLimitKeysAPIKeysByKeyAndAdminsByIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authCfg := &config.AuthConfig{JWTSecret: "rate-limit-test-secret", Issuer: "keystone-edge", JWTExpiryHours: 1}
+	calls := 0
+	validator := countingAPIKeyValidator{stubAPIKeyValidator{"good": nil}, &calls}
+	router := gin.New()
+	router.Use(RateLimit(authCfg, validator, nil, RateLimitRule{Name: "default", Limiter: NewTokenBucketLimiter(0.001, 1)}))
+	router.Use(RoutePermissions(authCfg, validator, nil, map[string]string{"GET /api/v1/orders": PermissionOrderWrite}, nil))
+	router.GET("/api/v1/orders", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	do := func(remoteAddr, authorization string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// One API key used from two addresses shares one budget, and the key is
+	// looked up once per request.
+	if code := do("10.0.0.5:1234", "ApiKey good"); code != http.StatusNoContent || calls != 1 {
+		t.Fatalf("api key = %d after %d lookups, want 204 after 1", code, calls)
+	}
+	if code := do("10.0.0.6:1234", "ApiKey good"); code != http.StatusTooManyRequests {
+		t.Fatalf("api key from another address = %d, want 429", code)
+	}
+
+	// Admin tokens carry no subject, so each address gets its own budget
+	// instead of one bucket shared by every admin.
+	token, err := auth.GenerateToken(auth.NewAdminClaims(), authCfg)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	if code := do("10.0.1.1:1234", "Bearer "+token); code != http.StatusNoContent {
+		t.Fatalf("first admin = %d, want 204", code)
+	}
+	if code := do("10.0.1.2:1234", "Bearer "+token); code != http.StatusNoContent {
+		t.Fatalf("second admin = %d, want 204", code)
+	}
+	if code := do("10.0.1.1:1234", "Bearer "+token); code != http.StatusTooManyRequests {
+		t.Fatalf("first admin over budget = %d, want 429", code)
+	}
+}
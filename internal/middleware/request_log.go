@@ -0,0 +1,107 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the caller's correlation ID in requests and the
+// server-assigned request ID in responses.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the gin.Context key holding the request ID.
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength matches api_logs.client_request_id.
+const maxRequestIDLength = 100
+
+// APILogEntry is one served API request as written to api_logs.
+type APILogEntry struct {
+	RequestID       string
+	ClientRequestID string
+	Method          string
+	Path            string
+	StatusCode      int
+	ResponseTimeMs  int64
+	UserID          string
+	UserRole        string
+	IPAddress       string
+	UserAgent       string
+	ErrorMessage    string
+	OccurredAt      time.Time
+}
+
+// RequestLog assigns each request a server-generated ID, returns it in the
+// response and hands an APILogEntry to record once the request completes. A
+// well-formed X-Request-ID from the caller is kept as the entry's
+// ClientRequestID; callers may reuse it, so it never becomes the request ID.
+// record must not block; a nil record only assigns request IDs.
+func RequestLog(record func(APILogEntry)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		clientRequestID := strings.TrimSpace(c.GetHeader(RequestIDHeader))
+		if !validRequestID(clientRequestID) {
+			clientRequestID = ""
+		}
+		requestID := newRequestID()
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+
+		if record == nil {
+			return
+		}
+		entry := APILogEntry{
+			RequestID:       requestID,
+			ClientRequestID: clientRequestID,
+			Method:          c.Request.Method,
+			Path:            c.Request.URL.Path,
+			StatusCode:      c.Writer.Status(),
+			ResponseTimeMs:  time.Since(started).Milliseconds(),
+			IPAddress:       c.ClientIP(),
+			UserAgent:       c.Request.UserAgent(),
+			ErrorMessage:    c.Errors.ByType(gin.ErrorTypeAny).String(),
+			OccurredAt:      started.UTC(),
+		}
+		if claims := GetClaims(c); claims != nil {
+			entry.UserID = strings.TrimSpace(claims.OperatorID)
+			if entry.UserID == "" {
+				entry.UserID = claims.Role
+			}
+			entry.UserRole = claims.Role
+		}
+		record(entry)
+	}
+}
+
+// GetRequestID returns the ID assigned by RequestLog, or "" when it did not run.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
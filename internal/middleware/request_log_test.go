@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLogAssignsRequestIDAndRecordsClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var entries []APILogEntry
+	router := gin.New()
+	router.Use(RequestLog(func(e APILogEntry) { entries = append(entries, e) }))
+	withClaims := func(c *gin.Context) {
+		c.Set(ClaimsKey, auth.NewCollectorClaims(7, "op-7"))
+		c.Next()
+	}
+	router.DELETE("/api/v1/batches/:id", withClaims, func(c *gin.Context) {
+		if GetRequestID(c) == "" {
+			t.Fatal("request id not set on context")
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/batches/5", nil)
+	req.Header.Set(RequestIDHeader, "req-abc")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if got := rec.Header().Get(RequestIDHeader); got == "req-abc" || len(got) != 32 {
+		t.Fatalf("request id = %q, want a generated one", got)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.RequestID != rec.Header().Get(RequestIDHeader) || e.ClientRequestID != "req-abc" || e.Method != http.MethodDelete || e.Path != "/api/v1/batches/5" || e.StatusCode != http.StatusNoContent || e.UserID != "op-7" || e.UserRole != "data_collector" {
+		t.Fatalf("entry = %+v", e)
+	}
+
+	for _, header := range []string{"", "has space", strings.Repeat("x", 101)} {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		req.Header.Set(RequestIDHeader, header)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		got := rec.Header().Get(RequestIDHeader)
+		if got == "" || got == header || len(got) != 32 {
+			t.Fatalf("header %q: generated request id = %q", header, got)
+		}
+	}
+	if last := entries[len(entries)-1]; last.StatusCode != http.StatusNotFound || last.UserID != "" || last.ClientRequestID != "" {
+		t.Fatalf("anonymous entry = %+v", last)
+	}
+}
@@ -41,6 +41,14 @@ type Server struct {
 	deviceState         *handlers.DeviceStateHandler
 	episode             *handlers.EpisodeHandler
 	qa                  *handlers.EpisodeQAHandler
+	integrityScrubber   *handlers.EpisodeIntegrityScrubber
+	apiLogs             *handlers.APILogHandler
+	webhooks            *handlers.WebhookHandler
+	webhookDispatcher   *services.WebhookDispatcher
+	rbac                *handlers.RBACHandler
+	permissions         middleware.PermissionResolver
+	apiKeyHandler       *handlers.APIKeyHandler
+	apiKeys             middleware.APIKeyValidator
 	task                *handlers.TaskHandler
 	batch               *handlers.BatchHandler
 	robotType           *handlers.RobotTypeHandler
@@ -59,6 +67,7 @@ type Server struct {
 	dataOps             *handlers.DataOpsHandler
 	dataStats           *handlers.DataProductionStatisticsHandler
 	productionDashboard *handlers.ProductionDashboardHandler
+	stateTransitions    *handlers.StateTransitionHandler
 	syncHandler         *handlers.SyncHandler
 	syncWorker          *services.SyncWorker
 	httpServer          *http.Server
@@ -76,6 +85,84 @@ func axonTransferWriteTimeout(cfg *config.TransferConfig) time.Duration {
 	return time.Duration(cfg.WriteTimeout) * time.Second
 }
 
+// newRateLimitMiddleware builds the per-client API rate limiter. Device
+// callbacks and reporting routes get their own buckets so dashboard or script
+// traffic can never exhaust the budget of /callbacks/finish.
+func newRateLimitMiddleware(cfg *config.Config, apiKeys middleware.APIKeyValidator) gin.HandlerFunc {
+	rl := cfg.RateLimit
+	rules := []middleware.RateLimitRule{
+		{
+			Name: "device",
+			PathPrefixes: []string{
+				"/api/v1/callbacks/",
+				"/api/v1/devices/register",
+				"/api/v1/tasks/:id/config",
+			},
+			Limiter: middleware.NewTokenBucketLimiter(rl.DeviceRPS, rl.DeviceBurst),
+		},
+		{
+			Name: "reporting",
+			PathPrefixes: []string{
+				"/api/v1/production/dashboard/",
+				"/api/v1/admin/statistics/data-production/",
+				"/api/v1/operator/statistics/data-production/",
+			},
+			Limiter: middleware.NewTokenBucketLimiter(rl.ReportingRPS, rl.ReportingBurst),
+		},
+	}
+	fallback := middleware.RateLimitRule{
+		Name:    "default",
+		Limiter: middleware.NewTokenBucketLimiter(rl.DefaultRPS, rl.DefaultBurst),
+	}
+	logger.Printf("[SERVER] API rate limits: default=%.2f/s burst %d, reporting=%.2f/s burst %d, device=%.2f/s burst %d",
+		rl.DefaultRPS, rl.DefaultBurst, rl.ReportingRPS, rl.ReportingBurst, rl.DeviceRPS, rl.DeviceBurst)
+	return middleware.RateLimit(&cfg.Auth, apiKeys, rules, fallback)
+}
+
+// routePermissions guards individual routes registered on shared groups,
+// keyed by "METHOD /full/route/path".
+var routePermissions = map[string]string{
+	"POST /api/v1/orders":                                       middleware.PermissionOrderWrite,
+	"PUT /api/v1/orders/:id":                                    middleware.PermissionOrderWrite,
+	"DELETE /api/v1/orders/:id":                                 middleware.PermissionOrderWrite,
+	"POST /api/v1/sync/episodes":                                middleware.PermissionSyncTrigger,
+	"POST /api/v1/sync/episodes/:id":                            middleware.PermissionSyncTrigger,
+	"POST /api/v1/sync/episodes/:id/resync":                     middleware.PermissionSyncTrigger,
+	"POST /api/v1/sync/episodes/:id/priority":                   middleware.PermissionSyncTrigger,
+	"PUT /api/v1/sync/config":                                   middleware.PermissionSyncConfigure,
+	"POST /api/v1/sync/control/pause":                           middleware.PermissionSyncConfigure,
+	"POST /api/v1/sync/control/resume":                          middleware.PermissionSyncConfigure,
+	"POST /api/v1/sync/control/drain":                           middleware.PermissionSyncConfigure,
+	"POST /api/v1/qa/inspections":                               middleware.PermissionEpisodeInspect,
+	"POST /api/v1/qa/inspections/claim":                         middleware.PermissionEpisodeInspect,
+	"POST /api/v1/qa/inspections/leases/:episode_id/renew":      middleware.PermissionEpisodeInspect,
+	"POST /api/v1/qa/inspections/leases/:episode_id/release":    middleware.PermissionEpisodeInspect,
+	"POST /api/v1/qa/audits/:id/review":                         middleware.PermissionEpisodeInspect,
+	"POST /api/v1/qa/episodes/:id/annotations":                  middleware.PermissionEpisodeInspect,
+	"PUT /api/v1/qa/episodes/:id/annotations/:annotation_id":    middleware.PermissionEpisodeInspect,
+	"DELETE /api/v1/qa/episodes/:id/annotations/:annotation_id": middleware.PermissionEpisodeInspect,
+}
+
+// newRouteScopes maps routePermissions entries to the lookup that finds the
+// organization and factory of the entity they act on, so scoped grants apply
+// to them. Scoped grants are denied on routes missing here.
+func newRouteScopes(scopes *handlers.RequestScopes) map[string]middleware.RequestScopeFunc {
+	return map[string]middleware.RequestScopeFunc{
+		"POST /api/v1/orders":                                       scopes.OrderBody,
+		"PUT /api/v1/orders/:id":                                    scopes.Order,
+		"DELETE /api/v1/orders/:id":                                 scopes.Order,
+		"POST /api/v1/sync/episodes/:id":                            scopes.Episode,
+		"POST /api/v1/sync/episodes/:id/resync":                     scopes.Episode,
+		"POST /api/v1/sync/episodes/:id/priority":                   scopes.Episode,
+		"POST /api/v1/qa/inspections":                               scopes.EpisodeBody,
+		"POST /api/v1/qa/inspections/leases/:episode_id/renew":      scopes.LeaseEpisode,
+		"POST /api/v1/qa/inspections/leases/:episode_id/release":    scopes.LeaseEpisode,
+		"POST /api/v1/qa/episodes/:id/annotations":                  scopes.Episode,
+		"PUT /api/v1/qa/episodes/:id/annotations/:annotation_id":    scopes.Episode,
+		"DELETE /api/v1/qa/episodes/:id/annotations/:annotation_id": scopes.Episode,
+	}
+}
+
 // New creates a new server instance.
 // db and s3Client are optional; pass nil to disable Verified ACK.
 // syncWorker is optional; pass nil to disable cloud sync APIs.
@@ -83,9 +170,47 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 	// Create Gin engine
 	gin.SetMode(gin.ReleaseMode)
 	engine := gin.New()
+	// Client IPs feed API key allowlists, rate limiting and the audit log, so
+	// X-Forwarded-For is only honored from configured proxies.
+	if err := engine.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Printf("[SERVER] Invalid trusted proxies %v, trusting none: %v", cfg.Server.TrustedProxies, err)
+		_ = engine.SetTrustedProxies(nil)
+	}
 	engine.Use(gin.Recovery())
 	engine.Use(gin.Logger())
 
+	// API audit log: every request gets an X-Request-ID; with a database the
+	// request is also written to api_logs asynchronously.
+	var apiLogHandler *handlers.APILogHandler
+	var recordAPILog func(middleware.APILogEntry)
+	if db != nil && cfg.Monitoring.APILogEnabled {
+		apiLogHandler = handlers.NewAPILogHandler(db, &cfg.Monitoring)
+		recordAPILog = apiLogHandler.Record
+	}
+	engine.Use(middleware.RequestLog(recordAPILog))
+
+	// Permissions: with a database roles and assignments come from the RBAC
+	// tables; without one the built-in role defaults apply.
+	var rbacHandler *handlers.RBACHandler
+	var permissionResolver middleware.PermissionResolver
+	var apiKeyHandler *handlers.APIKeyHandler
+	var apiKeyValidator middleware.APIKeyValidator
+	var routeScopes map[string]middleware.RequestScopeFunc
+	if db != nil {
+		rbacHandler = handlers.NewRBACHandler(db)
+		if err := rbacHandler.EnsureSystemRoles(context.Background()); err != nil {
+			logger.Printf("[RBAC] Failed to seed system roles: %v", err)
+		}
+		permissionResolver = rbacHandler
+		apiKeyHandler = handlers.NewAPIKeyHandler(db, permissionResolver)
+		apiKeyValidator = apiKeyHandler
+		routeScopes = newRouteScopes(handlers.NewRequestScopes(db))
+	}
+	if cfg.RateLimit.Enabled {
+		engine.Use(newRateLimitMiddleware(cfg, apiKeyValidator))
+	}
+	engine.Use(middleware.RoutePermissions(&cfg.Auth, apiKeyValidator, permissionResolver, routePermissions, routeScopes))
+
 	// Create handlers
 	healthHandler := handlers.NewHealthHandler(nil, nil)
 	var authHandler *handlers.AuthHandler
@@ -113,8 +238,13 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 
 	// Create EpisodeHandler for episode listing
 	episodeHandler := handlers.NewEpisodeHandler(db, s3Client, cfg.Storage.Bucket, &cfg.Auth)
-	qaHandler := handlers.NewEpisodeQAHandler(db, s3Client, cfg.Storage.Bucket, &cfg.Auth)
+	qaHandler := handlers.NewEpisodeQAHandler(db, s3Client, cfg.Storage.Bucket, &cfg.QA)
 	transferHandler.SetEpisodeQAEnqueuer(qaHandler)
+	qaHandler.SetSyncWorker(syncWorker)
+	var integrityScrubber *handlers.EpisodeIntegrityScrubber
+	if db != nil && s3Client != nil && cfg.QA.ScrubEnabled {
+		integrityScrubber = handlers.NewEpisodeIntegrityScrubber(qaHandler, &cfg.QA)
+	}
 
 	transferWriteTimeout := axonTransferWriteTimeout(&cfg.AxonTransfer)
 
@@ -141,6 +271,9 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 		dataOpsHandler             *handlers.DataOpsHandler
 		dataStatsHandler           *handlers.DataProductionStatisticsHandler
 		productionDashboardHandler *handlers.ProductionDashboardHandler
+		stateTransitionHandler     *handlers.StateTransitionHandler
+		webhookHandler             *handlers.WebhookHandler
+		webhookDispatcher          *services.WebhookDispatcher
 	)
 	if db != nil {
 		batchHandler = handlers.NewBatchHandler(db, recorderHub, recorderRPCTimeout)
@@ -164,6 +297,13 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 		}
 		dataStatsHandler = handlers.NewDataProductionStatisticsHandler(db)
 		productionDashboardHandler = handlers.NewProductionDashboardHandler(db, recorderHub, transferHub)
+		stateTransitionHandler = handlers.NewStateTransitionHandler(db)
+		webhookHandler = handlers.NewWebhookHandler(db)
+		if cfg.Webhooks.Enabled {
+			webhookDispatcher = services.NewWebhookDispatcher(db, &cfg.Webhooks)
+			webhookDispatcher.SetDeviceStateBroker(stateBroker)
+			syncWorker.SetWebhookDispatcher(webhookDispatcher)
+		}
 	}
 
 	// Create SyncHandler for cloud sync API
@@ -182,6 +322,14 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 		deviceState:         deviceStateHandler,
 		episode:             episodeHandler,
 		qa:                  qaHandler,
+		integrityScrubber:   integrityScrubber,
+		apiLogs:             apiLogHandler,
+		webhooks:            webhookHandler,
+		webhookDispatcher:   webhookDispatcher,
+		rbac:                rbacHandler,
+		permissions:         permissionResolver,
+		apiKeyHandler:       apiKeyHandler,
+		apiKeys:             apiKeyValidator,
 		task:                taskHandler,
 		batch:               batchHandler,
 		robotType:           robotTypeHandler,
@@ -200,6 +348,7 @@ func New(cfg *config.Config, db *sqlx.DB, s3Client *s3.Client, syncWorker *servi
 		dataOps:             dataOpsHandler,
 		dataStats:           dataStatsHandler,
 		productionDashboard: productionDashboardHandler,
+		stateTransitions:    stateTransitionHandler,
 		syncHandler:         syncHandler,
 		syncWorker:          syncWorker,
 		engine:              engine,
@@ -256,10 +405,10 @@ func (s *Server) buildRoutes() http.Handler {
 
 		// Authenticated auth routes:
 		//   GET  /auth/me          — any valid token (admin or data_collector)
-		//   POST /auth/me/station/* — data_collector only
-		jwtMw := middleware.JWTAuth(&s.cfg.Auth)
+		//   POST /auth/me/station/* — task:collect permission
+		jwtMw := middleware.JWTAuth(&s.cfg.Auth, s.apiKeys)
 		meGroup := v1Routes.Group("/auth/me", jwtMw)
-		stationGroup := v1Routes.Group("/auth/me/station", jwtMw, middleware.RequireRole("data_collector"))
+		stationGroup := v1Routes.Group("/auth/me/station", jwtMw, middleware.RequirePermission(s.permissions, middleware.PermissionTaskCollect))
 		s.auth.RegisterAuthenticatedRoutes(meGroup, stationGroup)
 	}
 	if s.storage != nil {
@@ -273,24 +422,34 @@ func (s *Server) buildRoutes() http.Handler {
 	// Episodes API
 	v1Episodes := v1Routes.Group("/episodes")
 	s.episode.RegisterRoutes(v1Episodes)
+	s.episode.RegisterPresignRoutes(v1Routes.Group("/episodes", middleware.DashboardAuth(&s.cfg.Auth, s.apiKeys)))
 	if s.qa != nil {
-		s.qa.RegisterRoutes(v1Routes)
+		// Writes are further gated by routePermissions.
+		qaRoutes := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys))
+		s.qa.RegisterRoutes(qaRoutes)
+		s.qa.RegisterInspectionRoutes(qaRoutes)
+		s.qa.RegisterAuditRoutes(qaRoutes)
+		s.qa.RegisterAnnotationRoutes(qaRoutes)
+		adminQA := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionQAConfigure))
+		s.qa.RegisterProfileRoutes(adminQA)
+		s.qa.RegisterScriptRoutes(adminQA)
+		s.qa.RegisterAuditPolicyRoutes(adminQA)
 	}
 
 	// Tasks API
 	v1Tasks := v1Routes.Group("")
-	taskStats := v1Routes.Group("/tasks/statistics", middleware.JWTAuth(&s.cfg.Auth), middleware.RequireAnyRole("admin", "data_collector"))
+	taskStats := v1Routes.Group("/tasks/statistics", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionTaskStatisticsRead))
 	taskStats.GET("/breakdown", s.task.GetTaskBreakdown)
 	s.task.RegisterRoutes(v1Tasks)
 	if s.batch != nil {
 		s.batch.RegisterRoutes(v1Tasks)
-		collectorBatches := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth), middleware.RequireRole("data_collector"))
+		collectorBatches := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionTaskCollect))
 		s.batch.RegisterCollectorRoutes(collectorBatches)
 	}
 	if s.robotType != nil {
 		s.robotType.RegisterRoutes(v1Tasks)
 		s.robotType.RegisterConfigTemplatePublicRoutes(v1Tasks)
-		adminRobotTypes := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth), middleware.RequireRole("admin"))
+		adminRobotTypes := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionRobotConfigure))
 		s.robotType.RegisterConfigTemplateAdminRoutes(adminRobotTypes)
 	}
 	if s.robot != nil {
@@ -298,7 +457,7 @@ func (s *Server) buildRoutes() http.Handler {
 	}
 	if s.deviceRegistration != nil {
 		s.deviceRegistration.RegisterRoutes(v1Tasks)
-		adminDeviceCredentials := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth), middleware.RequireRole("admin"))
+		adminDeviceCredentials := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionDeviceManage))
 		s.deviceRegistration.RegisterAdminRoutes(adminDeviceCredentials)
 	}
 	if s.factory != nil {
@@ -332,19 +491,38 @@ func (s *Server) buildRoutes() http.Handler {
 		s.order.RegisterRoutes(v1Tasks)
 	}
 	if s.dataStats != nil {
-		jwtMw := middleware.JWTAuth(&s.cfg.Auth)
-		adminStats := v1Routes.Group("/admin/statistics/data-production", jwtMw, middleware.RequireRole("admin"))
+		jwtMw := middleware.JWTAuth(&s.cfg.Auth, s.apiKeys)
+		adminStats := v1Routes.Group("/admin/statistics/data-production", jwtMw, middleware.RequirePermission(s.permissions, middleware.PermissionStatisticsRead))
 		s.dataStats.RegisterRoutes(adminStats)
-		operatorStats := v1Routes.Group("/operator/statistics/data-production", jwtMw, middleware.RequireRole("data_collector"))
+		operatorStats := v1Routes.Group("/operator/statistics/data-production", jwtMw, middleware.RequirePermission(s.permissions, middleware.PermissionStatisticsReadOwn))
 		s.dataStats.RegisterOperatorRoutes(operatorStats)
 	}
 	if s.dataOps != nil {
-		jwtMw := middleware.JWTAuth(&s.cfg.Auth)
-		adminDataOps := v1Routes.Group("/data-ops", jwtMw, middleware.RequireRole("admin"))
+		jwtMw := middleware.JWTAuth(&s.cfg.Auth, s.apiKeys)
+		adminDataOps := v1Routes.Group("/data-ops", jwtMw, middleware.RequirePermission(s.permissions, middleware.PermissionDataOpsManage))
 		s.dataOps.RegisterRoutes(adminDataOps)
 	}
+	if s.stateTransitions != nil {
+		s.stateTransitions.RegisterRoutes(v1Tasks)
+	}
+	if s.apiLogs != nil {
+		adminAPILogs := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionAuditLogRead))
+		s.apiLogs.RegisterRoutes(adminAPILogs)
+	}
+	if s.webhooks != nil {
+		adminWebhooks := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionWebhookManage))
+		s.webhooks.RegisterRoutes(adminWebhooks)
+	}
+	if s.apiKeyHandler != nil {
+		adminAPIKeys := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionAPIKeyManage))
+		s.apiKeyHandler.RegisterRoutes(adminAPIKeys)
+	}
+	if s.rbac != nil {
+		adminRBAC := v1Routes.Group("", middleware.JWTAuth(&s.cfg.Auth, s.apiKeys), middleware.RequirePermission(s.permissions, middleware.PermissionRBACManage))
+		s.rbac.RegisterRoutes(adminRBAC)
+	}
 	if s.productionDashboard != nil {
-		dashboard := v1Routes.Group("/production/dashboard", middleware.DashboardAuth(&s.cfg.Auth), middleware.RequireAnyRole("admin", "data_collector", "display"))
+		dashboard := v1Routes.Group("/production/dashboard", middleware.DashboardAuth(&s.cfg.Auth, s.apiKeys), middleware.RequireFilteredPermission(s.permissions, middleware.PermissionDashboardRead))
 		s.productionDashboard.RegisterRoutes(dashboard)
 	}
 
@@ -446,6 +624,16 @@ func (s *Server) Start() error {
 		}
 	}
 
+	if s.integrityScrubber != nil {
+		s.integrityScrubber.Start()
+	}
+	if s.apiLogs != nil {
+		s.apiLogs.Start()
+	}
+	if s.webhookDispatcher != nil {
+		s.webhookDispatcher.Start()
+	}
+
 	return nil
 }
 
@@ -504,6 +692,34 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if s.integrityScrubber != nil {
+		if err := s.integrityScrubber.Stop(ctx); err != nil {
+			logShutdownError("Integrity scrubber", err)
+			if shutdownErr == nil {
+				shutdownErr = fmt.Errorf("integrity scrubber shutdown: %w", err)
+			}
+		}
+	}
+
+	// Stop after the HTTP server so requests served during shutdown are flushed.
+	if s.apiLogs != nil {
+		if err := s.apiLogs.Stop(ctx); err != nil {
+			logShutdownError("API log writer", err)
+			if shutdownErr == nil {
+				shutdownErr = fmt.Errorf("api log writer shutdown: %w", err)
+			}
+		}
+	}
+
+	if s.webhookDispatcher != nil {
+		if err := s.webhookDispatcher.Stop(ctx); err != nil {
+			logShutdownError("Webhook dispatcher", err)
+			if shutdownErr == nil {
+				shutdownErr = fmt.Errorf("webhook dispatcher shutdown: %w", err)
+			}
+		}
+	}
+
 	// Stop sync worker
 	if s.syncWorker != nil {
 		if err := s.syncWorker.Stop(ctx); err != nil {
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	// sidecarAnnotationsTagKey carries inspector annotations in the exported
+	// sidecar tags, JSON-encoded like other sidecar arrays.
+	sidecarAnnotationsTagKey = "inspection.annotations"
+
+	// syncBundleAnnotationsName is the bundle attachment holding the
+	// annotations, so that destinations without raw tags receive them too.
+	syncBundleAnnotationsName = "annotations.json"
+)
+
+// sidecarAnnotationsAttachment is the content of the annotations attachment.
+type sidecarAnnotationsAttachment struct {
+	EpisodeID   string              `json:"episode_id"`
+	Annotations []sidecarAnnotation `json:"annotations"`
+}
+
+// sidecarAnnotation is one inspector annotation as exported with the sidecar.
+// Offsets are seconds from the episode start; end_sec is exclusive.
+type sidecarAnnotation struct {
+	StartSec float64 `json:"start_sec"`
+	EndSec   float64 `json:"end_sec"`
+	Tag      string  `json:"tag"`
+	Note     string  `json:"note,omitempty"`
+	Severity string  `json:"severity"`
+}
+
+// loadSidecarAnnotations returns an episode's annotations ordered by start offset.
+func loadSidecarAnnotations(ctx context.Context, db *sqlx.DB, episodeID int64) ([]sidecarAnnotation, error) {
+	var rows []struct {
+		StartSec float64        `db:"start_sec"`
+		EndSec   float64        `db:"end_sec"`
+		Tag      string         `db:"tag"`
+		Note     sql.NullString `db:"note"`
+		Severity string         `db:"severity"`
+	}
+	if err := db.SelectContext(ctx, &rows, `
+		SELECT start_sec, end_sec, tag, note, severity
+		FROM episode_annotations
+		WHERE episode_id = ? AND deleted_at IS NULL
+		ORDER BY start_sec ASC, id ASC
+	`, episodeID); err != nil {
+		return nil, fmt.Errorf("query episode annotations: %w", err)
+	}
+	annotations := make([]sidecarAnnotation, 0, len(rows))
+	for _, row := range rows {
+		annotations = append(annotations, sidecarAnnotation{
+			StartSec: row.StartSec,
+			EndSec:   row.EndSec,
+			Tag:      row.Tag,
+			Note:     row.Note.String,
+			Severity: row.Severity,
+		})
+	}
+	return annotations, nil
+}
+
+// addSidecarAnnotationTags sets the annotations tag so downstream training
+// can trim flagged segments. The edge copy replaces any value in the sidecar.
+func addSidecarAnnotationTags(tags map[string]string, annotations []sidecarAnnotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	encoded, err := json.Marshal(annotations)
+	if err != nil {
+		return fmt.Errorf("marshal sidecar annotations: %w", err)
+	}
+	tags[sidecarAnnotationsTagKey] = string(encoded)
+	return nil
+}
+
+// buildAnnotationsAttachment renders the annotations attachment. Like the
+// manifest it holds no timestamps, so a retry writes the same bytes.
+func buildAnnotationsAttachment(episodeUUID string, annotations []sidecarAnnotation) ([]byte, error) {
+	data, err := json.MarshalIndent(sidecarAnnotationsAttachment{
+		EpisodeID:   episodeUUID,
+		Annotations: annotations,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal annotations attachment: %w", err)
+	}
+	return data, nil
+}
+
+// writeAnnotationsAttachment stores the annotations under the bundle's
+// attachments/ prefix in edge MinIO, where syncBundleObjects picks them up
+// for every destination. An episode without annotations gets no attachment;
+// one left by an earlier sync is removed.
+func (w *SyncWorker) writeAnnotationsAttachment(ctx context.Context, mcapKey, episodeUUID string, annotations []sidecarAnnotation) error {
+	key := syncBundleAttachmentPrefix(mcapKey) + syncBundleAnnotationsName
+	if len(annotations) == 0 {
+		if err := w.minioClient.RemoveObject(ctx, w.minioBucket, key, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("remove annotations attachment %s: %w", key, err)
+		}
+		return nil
+	}
+	data, err := buildAnnotationsAttachment(episodeUUID, annotations)
+	if err != nil {
+		return err
+	}
+	if err := w.putMinioObject(ctx, key, data); err != nil {
+		return fmt.Errorf("write annotations attachment %s: %w", key, err)
+	}
+	return nil
+}
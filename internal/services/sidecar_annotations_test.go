@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
+)
+
+func TestSidecarAnnotationsExportedAsTag(t *testing.T) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`
+		CREATE TABLE episode_annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			start_sec REAL NOT NULL,
+			end_sec REAL NOT NULL,
+			tag TEXT NOT NULL,
+			note TEXT,
+			severity TEXT NOT NULL,
+			deleted_at TIMESTAMP NULL
+		);
+		INSERT INTO episode_annotations (episode_id, start_sec, end_sec, tag, note, severity, deleted_at) VALUES
+			(1, 40, 42.5, 'dropout', NULL, 'major', NULL),
+			(1, 3, 5, 'blur', 'hand over lens', 'minor', NULL),
+			(1, 0, 1, 'deleted', NULL, 'info', '2026-06-24 00:00:00'),
+			(2, 0, 1, 'other', NULL, 'info', NULL);
+	`); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	annotations, err := loadSidecarAnnotations(context.Background(), db, 1)
+	if err != nil {
+		t.Fatalf("load annotations: %v", err)
+	}
+	tags, err := flattenSidecar([]byte(testSidecarJSON))
+	if err != nil {
+		t.Fatalf("flatten sidecar: %v", err)
+	}
+	if err := addSidecarAnnotationTags(tags, annotations); err != nil {
+		t.Fatalf("add annotation tags: %v", err)
+	}
+
+	var exported []sidecarAnnotation
+	if err := json.Unmarshal([]byte(tags[sidecarAnnotationsTagKey]), &exported); err != nil {
+		t.Fatalf("decode %s=%q: %v", sidecarAnnotationsTagKey, tags[sidecarAnnotationsTagKey], err)
+	}
+	if len(exported) != 2 || exported[0].Tag != "blur" || exported[0].Note != "hand over lens" || exported[1].EndSec != 42.5 {
+		t.Fatalf("exported annotations = %+v", exported)
+	}
+	if tags["device.device_id"] != "robot_01" {
+		t.Fatalf("sidecar tags lost: %v", tags)
+	}
+
+	empty := map[string]string{}
+	if err := addSidecarAnnotationTags(empty, nil); err != nil || len(empty) != 0 {
+		t.Fatalf("empty annotations added tags: %v, %v", empty, err)
+	}
+}
@@ -0,0 +1,477 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/cloud"
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/jmoiron/sqlx"
+	"github.com/minio/minio-go/v7"
+)
+
+// Sync bundle object roles. An episode is synced as a bundle: the MCAP, its
+// sidecar, any attachments, and last a manifest describing all of them.
+const (
+	SyncObjectRoleMCAP       = "mcap"
+	SyncObjectRoleSidecar    = "sidecar"
+	SyncObjectRoleAttachment = "attachment"
+	SyncObjectRoleManifest   = "manifest"
+)
+
+// Sync bundle object statuses.
+const (
+	SyncObjectStatusPending   = "pending"
+	SyncObjectStatusUploading = "uploading"
+	SyncObjectStatusCompleted = "completed"
+	SyncObjectStatusFailed    = "failed"
+)
+
+const (
+	syncBundleManifestVersion = 1
+	syncBundleManifestName    = "manifest.json"
+)
+
+// syncBundleObject is one object of an episode bundle in edge MinIO. SHA256
+// is the digest the object is expected to have, when one is recorded.
+type syncBundleObject struct {
+	Role      string
+	Name      string
+	SourceKey string
+	Size      int64
+	SHA256    string
+}
+
+// syncLogObjectRow is a row of sync_log_objects.
+type syncLogObjectRow struct {
+	ID              int64          `db:"id"`
+	Role            string         `db:"role"`
+	Name            string         `db:"name"`
+	SourcePath      string         `db:"source_path"`
+	DestinationPath sql.NullString `db:"destination_path"`
+	SizeBytes       sql.NullInt64  `db:"size_bytes"`
+	SHA256          sql.NullString `db:"sha256"`
+	ETag            sql.NullString `db:"etag"`
+	Status          string         `db:"status"`
+}
+
+// syncBundleResult describes a fully uploaded bundle.
+type syncBundleResult struct {
+	// MCAP is the upload result of the MCAP object, kept for logging.
+	MCAP             *cloud.UploadResult
+	MCAPObjectKey    string
+	SidecarObjectKey string
+	ManifestKey      string
+	BytesTransferred int64
+	ObjectCount      int
+}
+
+// syncBundleManifest is the JSON manifest uploaded last with every bundle.
+type syncBundleManifest struct {
+	Version   int                      `json:"version"`
+	EpisodeID string                   `json:"episode_id"`
+	Objects   []syncBundleManifestItem `json:"objects"`
+}
+
+type syncBundleManifestItem struct {
+	Role      string `json:"role"`
+	Name      string `json:"name"`
+	SourceKey string `json:"source_key"`
+	ObjectKey string `json:"object_key"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+	ETag      string `json:"etag,omitempty"`
+}
+
+// syncBundlePrefix is the MinIO prefix holding the attachments and manifest
+// of the episode whose MCAP is mcapKey: "dir/task.mcap" -> "dir/task/".
+func syncBundlePrefix(mcapKey string) string {
+	return strings.TrimSuffix(mcapKey, path.Ext(mcapKey)) + "/"
+}
+
+func syncBundleAttachmentPrefix(mcapKey string) string {
+	return syncBundlePrefix(mcapKey) + "attachments/"
+}
+
+func syncBundleManifestKey(mcapKey string) string {
+	return syncBundlePrefix(mcapKey) + syncBundleManifestName
+}
+
+// syncBundleUpload uploads the objects of one bundle and records their status
+// under the sync_log, skipping objects an earlier attempt of the same
+// sync_log already completed. The MinIO and cloud calls are function fields so
+// that tests can replace them.
+type syncBundleUpload struct {
+	db          *sqlx.DB
+	syncLogID   int64
+	episodeID   int64
+	episodeUUID string
+	objects     []syncBundleObject
+	manifestKey string
+
+	checksum    func(ctx context.Context, key string) (string, error)
+	upload      func(ctx context.Context, obj syncBundleObject, progress cloud.UploadProgressFunc) (*cloud.UploadResult, error)
+	putManifest func(ctx context.Context, key string, data []byte) error
+	progress    cloud.UploadProgressFunc
+}
+
+// run uploads every object, then the manifest. It returns an error as soon as
+// one object fails; completed objects stay recorded for the next attempt.
+func (b *syncBundleUpload) run(ctx context.Context) (*syncBundleResult, error) {
+	rows, err := b.ensureRows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalBytes int64
+	for _, obj := range b.objects {
+		totalBytes += obj.Size
+	}
+	result := &syncBundleResult{ManifestKey: b.manifestKey}
+	var uploadedBytes int64
+	items := make([]syncBundleManifestItem, 0, len(b.objects))
+	for _, obj := range b.objects {
+		row := rows[syncObjectRowKey(obj.Role, obj.Name)]
+		base := uploadedBytes
+		item, mcap, err := b.uploadObject(ctx, obj, row, func(uploaded, _ int64) {
+			b.reportProgress(base+uploaded, totalBytes)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if mcap != nil {
+			result.MCAP = mcap
+		}
+		uploadedBytes += item.SizeBytes
+		b.reportProgress(uploadedBytes, totalBytes)
+		items = append(items, item)
+		switch obj.Role {
+		case SyncObjectRoleMCAP:
+			result.MCAPObjectKey = item.ObjectKey
+		case SyncObjectRoleSidecar:
+			result.SidecarObjectKey = item.ObjectKey
+		}
+		result.BytesTransferred += item.SizeBytes
+	}
+
+	data, err := buildSyncBundleManifest(b.episodeUUID, items)
+	if err != nil {
+		return nil, wrapNonRetryableSyncError(err, "build manifest for episode %d", b.episodeID)
+	}
+	if err := b.putManifest(ctx, b.manifestKey, data); err != nil {
+		return nil, fmt.Errorf("write manifest %s: %w", b.manifestKey, err)
+	}
+	manifestSum := sha256.Sum256(data)
+	manifest := syncBundleObject{
+		Role:      SyncObjectRoleManifest,
+		Name:      syncBundleManifestName,
+		SourceKey: b.manifestKey,
+		Size:      int64(len(data)),
+		SHA256:    hex.EncodeToString(manifestSum[:]),
+	}
+	manifestRow, err := b.ensureRow(ctx, manifest, rows)
+	if err != nil {
+		return nil, err
+	}
+	item, _, err := b.uploadObject(ctx, manifest, manifestRow, nil)
+	if err != nil {
+		return nil, err
+	}
+	result.BytesTransferred += item.SizeBytes
+	result.ObjectCount = len(items) + 1
+	return result, nil
+}
+
+func (b *syncBundleUpload) reportProgress(uploaded, total int64) {
+	if b.progress != nil {
+		b.progress(uploaded, total)
+	}
+}
+
+// uploadObject uploads obj unless row is already completed. The MCAP upload
+// result is returned when it was uploaded in this attempt. An upload whose
+// digest differs from obj.SHA256 fails.
+func (b *syncBundleUpload) uploadObject(ctx context.Context, obj syncBundleObject, row syncLogObjectRow, progress cloud.UploadProgressFunc) (syncBundleManifestItem, *cloud.UploadResult, error) {
+	completed := row.Status == SyncObjectStatusCompleted && row.DestinationPath.Valid && row.SHA256.Valid
+	if completed && (obj.Role != SyncObjectRoleManifest || row.SHA256.String == obj.SHA256) {
+		// The manifest is rewritten on every attempt; it only needs another
+		// upload when its content changed.
+		return syncManifestItemFromRow(row), nil, nil
+	}
+
+	if err := b.markObjectUploading(ctx, row.ID); err != nil {
+		return syncBundleManifestItem{}, nil, err
+	}
+	uploaded, err := b.upload(ctx, obj, progress)
+	if err != nil {
+		b.markObjectFailed(row.ID, err)
+		return syncBundleManifestItem{}, nil, fmt.Errorf("upload %s %s: %w", obj.Role, obj.Name, err)
+	}
+	checksum := uploaded.SHA256
+	if checksum == "" {
+		checksum = obj.SHA256
+	}
+	if checksum == "" {
+		if checksum, err = b.checksum(ctx, obj.SourceKey); err != nil {
+			b.markObjectFailed(row.ID, err)
+			return syncBundleManifestItem{}, nil, fmt.Errorf("checksum %s %s: %w", obj.Role, obj.Name, err)
+		}
+	}
+	if obj.SHA256 != "" && checksum != obj.SHA256 {
+		err := fmt.Errorf("checksum mismatch: uploaded sha256 %s, expected %s", checksum, obj.SHA256)
+		b.markObjectFailed(row.ID, err)
+		return syncBundleManifestItem{}, nil, fmt.Errorf("upload %s %s: %w", obj.Role, obj.Name, err)
+	}
+	item := syncBundleManifestItem{
+		Role:      obj.Role,
+		Name:      obj.Name,
+		SourceKey: obj.SourceKey,
+		ObjectKey: uploaded.ObjectKey,
+		SizeBytes: uploaded.FileSize,
+		SHA256:    checksum,
+		ETag:      uploaded.OSSObjectETag,
+	}
+	if err := b.markObjectCompleted(ctx, row.ID, item); err != nil {
+		return syncBundleManifestItem{}, nil, err
+	}
+	logger.Printf("[SYNC-WORKER] Episode %d bundle object uploaded: role=%s name=%s object_key=%s size=%d",
+		b.episodeID, obj.Role, obj.Name, item.ObjectKey, item.SizeBytes)
+	if obj.Role == SyncObjectRoleMCAP {
+		return item, uploaded, nil
+	}
+	return item, nil, nil
+}
+
+func syncManifestItemFromRow(row syncLogObjectRow) syncBundleManifestItem {
+	return syncBundleManifestItem{
+		Role:      row.Role,
+		Name:      row.Name,
+		SourceKey: row.SourcePath,
+		ObjectKey: row.DestinationPath.String,
+		SizeBytes: row.SizeBytes.Int64,
+		SHA256:    row.SHA256.String,
+		ETag:      row.ETag.String,
+	}
+}
+
+// buildSyncBundleManifest renders the manifest. It holds no timestamps, so a
+// retry renders the same bytes and can resume the persisted manifest upload.
+func buildSyncBundleManifest(episodeUUID string, items []syncBundleManifestItem) ([]byte, error) {
+	return json.MarshalIndent(syncBundleManifest{
+		Version:   syncBundleManifestVersion,
+		EpisodeID: episodeUUID,
+		Objects:   items,
+	}, "", "  ")
+}
+
+func syncObjectRowKey(role, name string) string {
+	return role + "/" + name
+}
+
+// ensureRows loads the object rows of the sync_log and inserts pending rows
+// for objects it does not have yet.
+func (b *syncBundleUpload) ensureRows(ctx context.Context) (map[string]syncLogObjectRow, error) {
+	var existing []syncLogObjectRow
+	if err := b.db.SelectContext(ctx, &existing, `
+		SELECT id, role, name, source_path, destination_path, size_bytes, sha256, etag, status
+		FROM sync_log_objects
+		WHERE sync_log_id = ?
+	`, b.syncLogID); err != nil {
+		return nil, fmt.Errorf("query sync log objects: %w", err)
+	}
+	rows := make(map[string]syncLogObjectRow, len(existing)+len(b.objects)+1)
+	for _, row := range existing {
+		rows[syncObjectRowKey(row.Role, row.Name)] = row
+	}
+	for _, obj := range b.objects {
+		if _, err := b.ensureRow(ctx, obj, rows); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (b *syncBundleUpload) ensureRow(ctx context.Context, obj syncBundleObject, rows map[string]syncLogObjectRow) (syncLogObjectRow, error) {
+	key := syncObjectRowKey(obj.Role, obj.Name)
+	if row, ok := rows[key]; ok {
+		return row, nil
+	}
+	// #nosec G701 -- static SQL with placeholder-bound values.
+	res, err := b.db.ExecContext(ctx, `
+		INSERT INTO sync_log_objects (sync_log_id, episode_id, role, name, source_path, size_bytes, status)
+		VALUES (?, ?, ?, ?, ?, ?, 'pending')
+	`, b.syncLogID, b.episodeID, obj.Role, obj.Name, obj.SourceKey, obj.Size)
+	if err != nil {
+		return syncLogObjectRow{}, fmt.Errorf("insert sync log object %s: %w", key, err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return syncLogObjectRow{}, fmt.Errorf("sync log object last insert id: %w", err)
+	}
+	row := syncLogObjectRow{
+		ID:         id,
+		Role:       obj.Role,
+		Name:       obj.Name,
+		SourcePath: obj.SourceKey,
+		SizeBytes:  sql.NullInt64{Int64: obj.Size, Valid: true},
+		Status:     SyncObjectStatusPending,
+	}
+	rows[key] = row
+	return row, nil
+}
+
+func (b *syncBundleUpload) markObjectUploading(ctx context.Context, id int64) error {
+	// #nosec G701 -- static SQL with placeholder-bound values.
+	if _, err := b.db.ExecContext(ctx, `
+		UPDATE sync_log_objects
+		SET status = 'uploading', error_message = NULL, started_at = ?, completed_at = NULL
+		WHERE id = ?
+	`, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("mark sync log object %d uploading: %w", id, err)
+	}
+	return nil
+}
+
+func (b *syncBundleUpload) markObjectCompleted(ctx context.Context, id int64, item syncBundleManifestItem) error {
+	// #nosec G701 -- static SQL with placeholder-bound values.
+	if _, err := b.db.ExecContext(ctx, `
+		UPDATE sync_log_objects
+		SET status = 'completed',
+		    destination_path = ?,
+		    size_bytes = ?,
+		    sha256 = ?,
+		    etag = ?,
+		    error_message = NULL,
+		    completed_at = ?
+		WHERE id = ?
+	`, item.ObjectKey, item.SizeBytes, item.SHA256, item.ETag, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("mark sync log object %d completed: %w", id, err)
+	}
+	return nil
+}
+
+// markObjectFailed records the failure with a fresh context so that a
+// cancelled upload is still visible in the sync logs API.
+func (b *syncBundleUpload) markObjectFailed(id int64, uploadErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncControlTimeout)
+	defer cancel()
+	// #nosec G701 -- static SQL with placeholder-bound values.
+	if _, err := b.db.ExecContext(ctx, `
+		UPDATE sync_log_objects
+		SET status = 'failed', error_message = ?, completed_at = ?
+		WHERE id = ?
+	`, uploadErr.Error(), time.Now().UTC(), id); err != nil {
+		logger.Printf("[SYNC-WORKER] Failed to mark sync log object %d failed: %v", id, err)
+	}
+}
+
+// syncBundleObjects lists the MCAP, sidecar and attachments of an episode in
+// upload order. mcapSHA256 is the recorded digest of the MCAP, if any. Attachments are the non-empty objects under the bundle's
+// attachments/ prefix, ordered by name.
+func (w *SyncWorker) syncBundleObjects(ctx context.Context, mcapKey, mcapSHA256, sidecarKey string) ([]syncBundleObject, error) {
+	if w.minioClient == nil {
+		return nil, fmt.Errorf("minio client not available")
+	}
+	objects := make([]syncBundleObject, 0, 2)
+	for _, obj := range []syncBundleObject{
+		{Role: SyncObjectRoleMCAP, Name: path.Base(mcapKey), SourceKey: mcapKey, SHA256: mcapSHA256},
+		{Role: SyncObjectRoleSidecar, Name: path.Base(sidecarKey), SourceKey: sidecarKey},
+	} {
+		if obj.SourceKey == "" {
+			continue
+		}
+		info, err := w.minioClient.StatObject(ctx, w.minioBucket, obj.SourceKey, minio.StatObjectOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("stat %s object %s: %w", obj.Role, obj.SourceKey, err)
+		}
+		obj.Size = info.Size
+		objects = append(objects, obj)
+	}
+
+	prefix := syncBundleAttachmentPrefix(mcapKey)
+	var attachments []syncBundleObject
+	for info := range w.minioClient.ListObjects(ctx, w.minioBucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return nil, fmt.Errorf("list attachments under %s: %w", prefix, info.Err)
+		}
+		if strings.HasSuffix(info.Key, "/") || info.Size == 0 {
+			continue
+		}
+		attachments = append(attachments, syncBundleObject{
+			Role:      SyncObjectRoleAttachment,
+			Name:      strings.TrimPrefix(info.Key, prefix),
+			SourceKey: info.Key,
+			Size:      info.Size,
+		})
+	}
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].Name < attachments[j].Name })
+	return append(objects, attachments...), nil
+}
+
+// normalizeSyncSHA256 returns a recorded SHA-256 as lowercase hex, or "" when
+// it is not one.
+func normalizeSyncSHA256(raw string) string {
+	digest := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(raw)), "sha256:")
+	if len(digest) != sha256.Size*2 {
+		return ""
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return ""
+	}
+	return digest
+}
+
+// minioObjectSHA256 streams an object from edge MinIO and returns its
+// hex-encoded SHA-256.
+func (w *SyncWorker) minioObjectSHA256(ctx context.Context, key string) (string, error) {
+	obj, err := w.minioClient.GetObject(ctx, w.minioBucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get object %s: %w", key, err)
+	}
+	defer func() {
+		_ = obj.Close()
+	}()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, obj); err != nil {
+		return "", fmt.Errorf("read object %s: %w", key, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (w *SyncWorker) putMinioObject(ctx context.Context, key string, data []byte) error {
+	_, err := w.minioClient.PutObject(ctx, w.minioBucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	return err
+}
+
+// syncBundleRawTags returns the raw tags of one bundle object. The MCAP keeps
+// the episode tags unchanged; other objects name themselves in raw_file and
+// carry their bundle role.
+func syncBundleRawTags(episodeTags map[string]string, obj syncBundleObject) map[string]string {
+	if obj.Role == SyncObjectRoleMCAP {
+		return episodeTags
+	}
+	tags := make(map[string]string, len(episodeTags)+2)
+	for k, v := range episodeTags {
+		tags[k] = v
+	}
+	tags[dpReservedRawFileTagKey] = path.Base(obj.SourceKey)
+	tags["bundle_role"] = obj.Role
+	tags["bundle_object"] = obj.Name
+	return tags
+}
@@ -0,0 +1,229 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"archebase.com/keystone-edge/internal/cloud"
+	"github.com/jmoiron/sqlx"
+)
+
+type fakeBundleStore struct {
+	failRole  string
+	uploads   []string
+	manifest  []byte
+	checksums int
+}
+
+// digest is what the fake destination hashes while uploading obj: the
+// manifest bytes, or the source key standing in for object content.
+func (f *fakeBundleStore) digest(obj syncBundleObject) string {
+	data := []byte(obj.SourceKey)
+	if obj.Role == SyncObjectRoleManifest {
+		data = f.manifest
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeBundleStore) bundle(db *sqlx.DB, syncLogID int64) *syncBundleUpload {
+	return &syncBundleUpload{
+		db:          db,
+		syncLogID:   syncLogID,
+		episodeID:   30,
+		episodeUUID: "episode-uuid-30",
+		objects: []syncBundleObject{
+			{Role: SyncObjectRoleMCAP, Name: "task.mcap", SourceKey: "f/d/task.mcap", Size: 1000},
+			{Role: SyncObjectRoleSidecar, Name: "task.json", SourceKey: "f/d/task.json", Size: 100},
+			{Role: SyncObjectRoleAttachment, Name: "thumb.jpg", SourceKey: "f/d/task/attachments/thumb.jpg", Size: 50},
+		},
+		manifestKey: syncBundleManifestKey("f/d/task.mcap"),
+		checksum: func(context.Context, string) (string, error) {
+			f.checksums++
+			return "", errors.New("object read twice")
+		},
+		upload: func(_ context.Context, obj syncBundleObject, progress cloud.UploadProgressFunc) (*cloud.UploadResult, error) {
+			if obj.Role == f.failRole {
+				return nil, errors.New("gateway unavailable")
+			}
+			f.uploads = append(f.uploads, obj.Name)
+			if progress != nil {
+				progress(obj.Size, obj.Size)
+			}
+			return &cloud.UploadResult{ObjectKey: "cloud/" + obj.SourceKey, FileSize: obj.Size, OSSObjectETag: "etag-" + obj.Name, SHA256: f.digest(obj)}, nil
+		},
+		putManifest: func(_ context.Context, _ string, data []byte) error {
+			f.manifest = append([]byte(nil), data...)
+			return nil
+		},
+	}
+}
+
+func syncLogObjectsForTest(t *testing.T, db *sqlx.DB, syncLogID int64) map[string]syncLogObjectRow {
+	t.Helper()
+	var rows []syncLogObjectRow
+	if err := db.Select(&rows, `
+		SELECT id, role, name, source_path, destination_path, size_bytes, sha256, etag, status
+		FROM sync_log_objects
+		WHERE sync_log_id = ?
+	`, syncLogID); err != nil {
+		t.Fatalf("query sync log objects: %v", err)
+	}
+	byKey := make(map[string]syncLogObjectRow, len(rows))
+	for _, row := range rows {
+		byKey[syncObjectRowKey(row.Role, row.Name)] = row
+	}
+	return byKey
+}
+
+func TestSyncBundleUploadRecordsObjectsAndManifest(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	store := &fakeBundleStore{}
+	bundle := store.bundle(db, 7)
+	var lastUploaded, lastTotal int64
+	bundle.progress = func(uploaded, total int64) { lastUploaded, lastTotal = uploaded, total }
+
+	result, err := bundle.run(context.Background())
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if result.MCAPObjectKey != "cloud/f/d/task.mcap" || result.SidecarObjectKey != "cloud/f/d/task.json" {
+		t.Fatalf("result keys = %+v", result)
+	}
+	if result.ObjectCount != 4 || result.BytesTransferred != 1150+int64(len(store.manifest)) {
+		t.Fatalf("result = %+v, manifest size %d", result, len(store.manifest))
+	}
+	if lastUploaded != 1150 || lastTotal != 1150 {
+		t.Fatalf("progress = %d/%d, want 1150/1150", lastUploaded, lastTotal)
+	}
+
+	var manifest syncBundleManifest
+	if err := json.Unmarshal(store.manifest, &manifest); err != nil {
+		t.Fatalf("decode manifest: %v", err)
+	}
+	if manifest.EpisodeID != "episode-uuid-30" || len(manifest.Objects) != 3 {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	attachment := manifest.Objects[2]
+	wantSum := sha256.Sum256([]byte("f/d/task/attachments/thumb.jpg"))
+	if attachment.Role != SyncObjectRoleAttachment || attachment.SHA256 != hex.EncodeToString(wantSum[:]) ||
+		attachment.ObjectKey != "cloud/f/d/task/attachments/thumb.jpg" || attachment.SizeBytes != 50 {
+		t.Fatalf("attachment manifest entry = %+v", attachment)
+	}
+
+	rows := syncLogObjectsForTest(t, db, 7)
+	if len(rows) != 4 {
+		t.Fatalf("sync log objects = %d, want 4", len(rows))
+	}
+	for key, row := range rows {
+		if row.Status != SyncObjectStatusCompleted || !row.SHA256.Valid || !row.DestinationPath.Valid {
+			t.Fatalf("object %s = %+v, want completed with checksum", key, row)
+		}
+	}
+	if store.checksums != 0 {
+		t.Fatalf("separate checksum reads = %d, want none", store.checksums)
+	}
+}
+
+func TestSyncBundleUploadRejectsChecksumMismatch(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	store := &fakeBundleStore{}
+	bundle := store.bundle(db, 9)
+	bundle.objects[0].SHA256 = strings.Repeat("0", 64)
+
+	_, err := bundle.run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("run() error = %v, want checksum mismatch", err)
+	}
+	if got := syncLogObjectsForTest(t, db, 9)["mcap/task.mcap"].Status; got != SyncObjectStatusFailed {
+		t.Fatalf("mcap status = %q, want failed", got)
+	}
+
+	// The recorded digest matching what was streamed passes.
+	bundle = store.bundle(db, 9)
+	bundle.objects[0].SHA256 = store.digest(bundle.objects[0])
+	if _, err := bundle.run(context.Background()); err != nil {
+		t.Fatalf("run() with matching checksum error = %v", err)
+	}
+}
+
+func TestSyncBundleUploadRetryResumesAfterFailedObject(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	store := &fakeBundleStore{failRole: SyncObjectRoleSidecar}
+
+	if _, err := store.bundle(db, 8).run(context.Background()); err == nil {
+		t.Fatal("run() error = nil, want sidecar failure")
+	}
+	rows := syncLogObjectsForTest(t, db, 8)
+	if got := rows["mcap/task.mcap"].Status; got != SyncObjectStatusCompleted {
+		t.Fatalf("mcap status = %q, want completed", got)
+	}
+	if got := rows["sidecar/task.json"].Status; got != SyncObjectStatusFailed {
+		t.Fatalf("sidecar status = %q, want failed", got)
+	}
+	if got := rows["attachment/thumb.jpg"].Status; got != SyncObjectStatusPending {
+		t.Fatalf("attachment status = %q, want pending", got)
+	}
+	if _, ok := rows["manifest/manifest.json"]; ok {
+		t.Fatal("manifest row exists before all objects completed")
+	}
+
+	store.failRole = ""
+	store.uploads = nil
+	result, err := store.bundle(db, 8).run(context.Background())
+	if err != nil {
+		t.Fatalf("retry run() error = %v", err)
+	}
+	want := []string{"task.json", "thumb.jpg", "manifest.json"}
+	if len(store.uploads) != len(want) {
+		t.Fatalf("retry uploads = %v, want %v", store.uploads, want)
+	}
+	for i := range want {
+		if store.uploads[i] != want[i] {
+			t.Fatalf("retry uploads = %v, want %v", store.uploads, want)
+		}
+	}
+	if result.MCAPObjectKey != "cloud/f/d/task.mcap" || result.MCAP != nil {
+		t.Fatalf("retry result = %+v, want MCAP reused from first attempt", result)
+	}
+
+	// A third pass over a complete bundle re-renders the same manifest and
+	// uploads nothing.
+	store.uploads = nil
+	if _, err := store.bundle(db, 8).run(context.Background()); err != nil {
+		t.Fatalf("third run() error = %v", err)
+	}
+	if len(store.uploads) != 0 {
+		t.Fatalf("third run uploads = %v, want none", store.uploads)
+	}
+}
+
+func TestSyncBundleKeysAndRawTags(t *testing.T) {
+	if got := syncBundleAttachmentPrefix("factory/dev/2026-01-01/task.mcap"); got != "factory/dev/2026-01-01/task/attachments/" {
+		t.Fatalf("attachment prefix = %q", got)
+	}
+	if got := syncBundleManifestKey("factory/dev/2026-01-01/task.mcap"); got != "factory/dev/2026-01-01/task/manifest.json" {
+		t.Fatalf("manifest key = %q", got)
+	}
+
+	episodeTags := map[string]string{dpReservedRawFileTagKey: "task.mcap", "episode_id": "e-1"}
+	if got := syncBundleRawTags(episodeTags, syncBundleObject{Role: SyncObjectRoleMCAP, SourceKey: "d/task.mcap"}); got[dpReservedRawFileTagKey] != "task.mcap" || got["bundle_role"] != "" {
+		t.Fatalf("mcap tags = %v, want episode tags unchanged", got)
+	}
+	got := syncBundleRawTags(episodeTags, syncBundleObject{Role: SyncObjectRoleAttachment, Name: "imgs/a.png", SourceKey: "d/task/attachments/imgs/a.png"})
+	if got[dpReservedRawFileTagKey] != "a.png" || got["bundle_role"] != SyncObjectRoleAttachment || got["bundle_object"] != "imgs/a.png" || got["episode_id"] != "e-1" {
+		t.Fatalf("attachment tags = %v", got)
+	}
+	if episodeTags[dpReservedRawFileTagKey] != "task.mcap" {
+		t.Fatal("episode tags were modified")
+	}
+}
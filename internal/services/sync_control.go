@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Sync worker control modes.
+const (
+	// SyncModeRunning dispatches and uploads normally.
+	SyncModeRunning = "running"
+	// SyncModePaused dispatches nothing and holds in-flight uploads between
+	// parts, keeping their multipart state for resume.
+	SyncModePaused = "paused"
+	// SyncModeDraining lets in-flight uploads finish but dispatches nothing new.
+	SyncModeDraining = "draining"
+)
+
+// ErrInvalidSyncMode is returned by SetSyncMode for an unknown mode.
+var ErrInvalidSyncMode = errors.New("invalid sync mode")
+
+const syncControlTimeout = 10 * time.Second
+
+// SyncControlState is the operator-controlled mode of the sync worker.
+type SyncControlState struct {
+	Mode      string
+	Reason    string
+	UpdatedBy string
+	UpdatedAt time.Time
+	// InFlightUploads counts uploads currently transferring.
+	InFlightUploads int64
+}
+
+// Drained reports whether a drain has finished.
+func (s SyncControlState) Drained() bool {
+	return s.Mode == SyncModeDraining && s.InFlightUploads == 0
+}
+
+type syncControl struct {
+	mode      string
+	reason    string
+	updatedBy string
+	updatedAt time.Time
+}
+
+// SyncControl returns the current control mode.
+func (w *SyncWorker) SyncControl() SyncControlState {
+	w.controlMu.RLock()
+	defer w.controlMu.RUnlock()
+	mode := w.control.mode
+	if mode == "" {
+		mode = SyncModeRunning
+	}
+	return SyncControlState{
+		Mode:            mode,
+		Reason:          w.control.reason,
+		UpdatedBy:       w.control.updatedBy,
+		UpdatedAt:       w.control.updatedAt,
+		InFlightUploads: w.activeUploads.Load(),
+	}
+}
+
+func (w *SyncWorker) syncMode() string {
+	w.controlMu.RLock()
+	defer w.controlMu.RUnlock()
+	if w.control.mode == "" {
+		return SyncModeRunning
+	}
+	return w.control.mode
+}
+
+// SetSyncMode switches the control mode and persists it so that it survives
+// restarts. Pausing and draining never cancel in-flight uploads.
+func (w *SyncWorker) SetSyncMode(ctx context.Context, mode, reason, updatedBy string) (SyncControlState, error) {
+	switch mode {
+	case SyncModeRunning, SyncModePaused, SyncModeDraining:
+	default:
+		return SyncControlState{}, fmt.Errorf("%w %q", ErrInvalidSyncMode, mode)
+	}
+	reason = strings.TrimSpace(reason)
+	now := time.Now().UTC()
+
+	if w.db != nil {
+		if err := saveSyncControl(ctx, w.db, mode, reason, updatedBy, now); err != nil {
+			return SyncControlState{}, err
+		}
+	}
+
+	w.controlMu.Lock()
+	previous := w.control.mode
+	w.control = syncControl{mode: mode, reason: reason, updatedBy: updatedBy, updatedAt: now}
+	w.controlMu.Unlock()
+	if previous == "" {
+		previous = SyncModeRunning
+	}
+	logger.Printf("[SYNC-WORKER] Sync mode changed: %s -> %s by=%s reason=%q in_flight=%d",
+		previous, mode, updatedBy, reason, w.activeUploads.Load())
+	return w.SyncControl(), nil
+}
+
+func saveSyncControl(ctx context.Context, db *sqlx.DB, mode, reason, updatedBy string, now time.Time) error {
+	reasonValue := sql.NullString{String: reason, Valid: reason != ""}
+	updatedByValue := sql.NullString{String: updatedBy, Valid: updatedBy != ""}
+	res, err := db.ExecContext(ctx, `
+		UPDATE sync_control
+		SET mode = ?, reason = ?, updated_by = ?, updated_at = ?
+		WHERE id = 1
+	`, mode, reasonValue, updatedByValue, now)
+	if err != nil {
+		return fmt.Errorf("update sync control: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO sync_control (id, mode, reason, updated_by, updated_at)
+		VALUES (1, ?, ?, ?, ?)
+	`, mode, reasonValue, updatedByValue, now); err != nil {
+		return fmt.Errorf("insert sync control: %w", err)
+	}
+	return nil
+}
+
+// loadSyncControl restores the persisted mode. Errors keep the worker running
+// so a missing table never blocks sync.
+func (w *SyncWorker) loadSyncControl(ctx context.Context) {
+	if w.db == nil {
+		return
+	}
+	var row struct {
+		Mode      string         `db:"mode"`
+		Reason    sql.NullString `db:"reason"`
+		UpdatedBy sql.NullString `db:"updated_by"`
+		UpdatedAt sql.NullTime   `db:"updated_at"`
+	}
+	err := w.db.GetContext(ctx, &row, `
+		SELECT mode, reason, updated_by, updated_at
+		FROM sync_control
+		WHERE id = 1
+	`)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		logger.Printf("[SYNC-WORKER] Failed to load sync control mode, assuming %s: %v", SyncModeRunning, err)
+		return
+	}
+	switch row.Mode {
+	case SyncModeRunning, SyncModePaused, SyncModeDraining:
+	default:
+		logger.Printf("[SYNC-WORKER] Ignoring unknown persisted sync mode %q", row.Mode)
+		return
+	}
+
+	w.controlMu.Lock()
+	w.control = syncControl{
+		mode:      row.Mode,
+		reason:    row.Reason.String,
+		updatedBy: row.UpdatedBy.String,
+		updatedAt: row.UpdatedAt.Time,
+	}
+	w.controlMu.Unlock()
+	if row.Mode != SyncModeRunning {
+		logger.Printf("[SYNC-WORKER] Restored sync mode %s (reason=%q)", row.Mode, row.Reason.String)
+	}
+}
+
+// recoverInterruptedSyncLogs returns sync_logs left in_progress by a previous
+// process to pending. The attempt that was interrupted is not counted, and
+// the upload resumes from the state persisted under PersistRootDir.
+func (w *SyncWorker) recoverInterruptedSyncLogs(ctx context.Context) {
+	if w.db == nil {
+		return
+	}
+	res, err := w.db.ExecContext(ctx, `
+		UPDATE sync_logs
+		SET status = 'pending',
+		    attempt_count = CASE WHEN attempt_count > 0 THEN attempt_count - 1 ELSE 0 END,
+		    error_message = NULL,
+		    duration_sec = NULL,
+		    completed_at = NULL,
+		    next_retry_at = NULL
+		WHERE status = 'in_progress'
+	`)
+	if err != nil {
+		logger.Printf("[SYNC-WORKER] Failed to recover interrupted sync logs: %v", err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		logger.Printf("[SYNC-WORKER] Requeued %d sync(s) interrupted by the previous shutdown", n)
+	}
+}
+
+// requeueInterruptedSyncLog returns a sync_log whose upload was cut short by
+// Stop to pending instead of recording a failure, so the next start resumes
+// it without using up a retry.
+func (w *SyncWorker) requeueInterruptedSyncLog(syncLogID, episodeID int64, attemptCount int) {
+	ctx, cancel := context.WithTimeout(context.Background(), syncControlTimeout)
+	defer cancel()
+	if _, err := w.db.ExecContext(ctx, `
+		UPDATE sync_logs
+		SET status = 'pending',
+		    attempt_count = ?
+		WHERE id = ?
+		  AND status = 'in_progress'
+	`, max(0, attemptCount-1), syncLogID); err != nil {
+		logger.Printf("[SYNC-WORKER] Failed to requeue interrupted sync log %d: %v", syncLogID, err)
+		return
+	}
+	logger.Printf("[SYNC-WORKER] Episode %d upload interrupted by shutdown, requeued", episodeID)
+}
+
+// dispatchAllowed reports whether new uploads may start now.
+func (w *SyncWorker) dispatchAllowed() bool {
+	return w.syncMode() == SyncModeRunning && w.syncWindowOpen()
+}
+
+func (w *SyncWorker) dispatchBlockedReason() string {
+	if mode := w.syncMode(); mode != SyncModeRunning {
+		return "sync is " + mode
+	}
+	return "outside the sync windows"
+}
+
+// restoreSyncState reloads the persisted mode and requeues uploads cut short
+// by the previous shutdown. It runs in Start before any job is dispatched.
+func (w *SyncWorker) restoreSyncState() {
+	ctx, cancel := context.WithTimeout(context.Background(), syncControlTimeout)
+	defer cancel()
+	w.loadSyncControl(ctx)
+	w.recoverInterruptedSyncLogs(ctx)
+}
@@ -0,0 +1,135 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func createSyncControlTableForTest(t *testing.T, w *SyncWorker) {
+	t.Helper()
+	if _, err := w.db.Exec(`
+		CREATE TABLE sync_control (
+			id INTEGER PRIMARY KEY,
+			mode TEXT NOT NULL DEFAULT 'running',
+			reason TEXT,
+			updated_by TEXT,
+			updated_at TIMESTAMP NULL
+		)
+	`); err != nil {
+		t.Fatalf("create sync_control: %v", err)
+	}
+}
+
+func TestSetSyncModePersistsAcrossRestart(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{db: db, cfg: SyncWorkerConfig{BatchSize: 10, MaxRetries: 3}}
+	createSyncControlTableForTest(t, w)
+
+	if _, err := w.SetSyncMode(context.Background(), "stopped", "", "admin"); !errors.Is(err, ErrInvalidSyncMode) {
+		t.Fatalf("SetSyncMode(stopped) error = %v, want ErrInvalidSyncMode", err)
+	}
+	state, err := w.SetSyncMode(context.Background(), SyncModePaused, " network maintenance ", "admin")
+	if err != nil {
+		t.Fatalf("SetSyncMode: %v", err)
+	}
+	if state.Mode != SyncModePaused || state.Reason != "network maintenance" || state.UpdatedBy != "admin" {
+		t.Fatalf("state = %+v", state)
+	}
+
+	restarted := &SyncWorker{db: db, cfg: w.cfg}
+	restarted.loadSyncControl(context.Background())
+	if got := restarted.SyncControl(); got.Mode != SyncModePaused || got.Reason != "network maintenance" {
+		t.Fatalf("restored state = %+v, want paused", got)
+	}
+}
+
+func TestPollAndProcess_DispatchesNothingWhilePausedOrDraining(t *testing.T) {
+	for _, mode := range []string{SyncModePaused, SyncModeDraining} {
+		t.Run(mode, func(t *testing.T) {
+			db := newTestSyncWorkerDB(t)
+			w := &SyncWorker{
+				db:              db,
+				cfg:             SyncWorkerConfig{BatchSize: 10, MaxRetries: 3, AutoScanEnabled: true},
+				jobCh:           make(chan syncEnqueueRequest, 2),
+				enqueuedEpisode: make(map[int64]struct{}),
+				control:         syncControl{mode: mode},
+			}
+			createSyncControlTableForTest(t, w)
+			insertEpisodeForSyncWorkerTest(t, db, 1, "approved", false)
+			insertSyncLogForSyncWorkerTest(t, db, 1, "pending", 0)
+
+			w.pollAndProcess(context.Background())
+			select {
+			case got := <-w.jobCh:
+				t.Fatalf("dispatched %+v while %s", got, mode)
+			default:
+			}
+
+			if allowed, _ := w.bandwidthPolicy(time.Now()); allowed != (mode == SyncModeDraining) {
+				t.Fatalf("bandwidthPolicy allowed = %v while %s", allowed, mode)
+			}
+
+			if _, err := w.SetSyncMode(context.Background(), SyncModeRunning, "", ""); err != nil {
+				t.Fatalf("SetSyncMode(running): %v", err)
+			}
+			w.pollAndProcess(context.Background())
+			select {
+			case got := <-w.jobCh:
+				if got.episodeID != 1 {
+					t.Fatalf("dispatched %+v, want episode 1", got)
+				}
+			default:
+				t.Fatal("expected pending row to be dispatched after resume")
+			}
+		})
+	}
+}
+
+func TestProcessEnqueuedEpisode_DefersBufferedJobsWhilePaused(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{
+		db:              db,
+		cfg:             SyncWorkerConfig{BatchSize: 10, MaxRetries: 3},
+		enqueuedEpisode: map[int64]struct{}{5: {}},
+		control:         syncControl{mode: SyncModePaused},
+	}
+	insertEpisodeForSyncWorkerTest(t, db, 5, "approved", false)
+
+	called := false
+	w.processEnqueuedEpisodeWith(context.Background(), syncEnqueueRequest{episodeID: 5}, func(context.Context, int64, bool, bool) {
+		called = true
+	})
+	if called {
+		t.Fatal("buffered job started while paused")
+	}
+	if latest := latestSyncLogForSyncWorkerTest(t, db, 5); latest.Status != "pending" {
+		t.Fatalf("latest status = %q, want pending", latest.Status)
+	}
+	if _, queued := w.enqueuedEpisode[5]; queued {
+		t.Fatal("episode still marked as enqueued")
+	}
+}
+
+func TestRecoverInterruptedSyncLogs_RequeuesInProgressRows(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{db: db, cfg: SyncWorkerConfig{BatchSize: 10, MaxRetries: 3}}
+	insertEpisodeForSyncWorkerTest(t, db, 7, "approved", false)
+	insertSyncLogForSyncWorkerTest(t, db, 7, "in_progress", 2)
+	insertEpisodeForSyncWorkerTest(t, db, 8, "approved", false)
+	insertSyncLogForSyncWorkerTest(t, db, 8, "failed", 1)
+
+	w.recoverInterruptedSyncLogs(context.Background())
+
+	if latest := latestSyncLogForSyncWorkerTest(t, db, 7); latest.Status != "pending" || latest.AttemptCount != 1 {
+		t.Fatalf("interrupted row = %+v, want pending with attempt_count 1", latest)
+	}
+	if latest := latestSyncLogForSyncWorkerTest(t, db, 8); latest.Status != "failed" {
+		t.Fatalf("failed row status = %q, want unchanged", latest.Status)
+	}
+}
@@ -0,0 +1,264 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sync priority scores. Queued episodes are dispatched highest score first;
+// ties go to the episode that has waited longest.
+const (
+	// MaxSyncPriorityOverride is the highest score an operator override may
+	// set. It is above any computed score, so it always jumps the queue.
+	MaxSyncPriorityOverride = 1000
+
+	syncPriorityManualBoost = 150
+	// syncPriorityMaxAgeBoost caps the one point per hour queued that keeps
+	// low-priority work from starving.
+	syncPriorityMaxAgeBoost = 100
+)
+
+var syncOrderPriorityScores = map[string]int{
+	"low":    0,
+	"normal": 100,
+	"high":   200,
+	"urgent": 300,
+}
+
+// syncDeadlineTiers score an order deadline: the first tier whose window
+// contains the time remaining applies. Overdue orders fall in the first.
+var syncDeadlineTiers = []struct {
+	within time.Duration
+	score  int
+}{
+	{0, 300},
+	{24 * time.Hour, 200},
+	{72 * time.Hour, 100},
+	{7 * 24 * time.Hour, 50},
+}
+
+// syncQueueEntry is a pending sync_logs row with the inputs of its priority.
+type syncQueueEntry struct {
+	SyncLogID     int64          `db:"sync_log_id"`
+	EpisodeID     int64          `db:"episode_id"`
+	CloudSynced   bool           `db:"cloud_synced"`
+	Manual        bool           `db:"manual"`
+	QueuedAt      sql.NullTime   `db:"queued_at"`
+	Override      sql.NullInt64  `db:"sync_priority_override"`
+	OrderPriority sql.NullString `db:"order_priority"`
+	OrderDeadline sql.NullTime   `db:"order_deadline"`
+	// StaticScore is syncPriority without the age boost, as ranked in SQL.
+	StaticScore int64 `db:"static_score"`
+
+	priority int
+}
+
+// syncPriority computes the dispatch score of a queued episode at now. An
+// operator override replaces the computed score.
+func syncPriority(e syncQueueEntry, now time.Time) int {
+	if e.Override.Valid {
+		return int(e.Override.Int64)
+	}
+
+	score, ok := syncOrderPriorityScores[e.OrderPriority.String]
+	if !ok {
+		score = syncOrderPriorityScores["normal"]
+	}
+	if e.OrderDeadline.Valid {
+		score += syncDeadlineScore(e.OrderDeadline.Time.Sub(now))
+	}
+	if e.Manual {
+		score += syncPriorityManualBoost
+	}
+	if e.QueuedAt.Valid {
+		if hours := int(now.Sub(e.QueuedAt.Time).Hours()); hours > 0 {
+			score += min(hours, syncPriorityMaxAgeBoost)
+		}
+	}
+	return score
+}
+
+// syncDeadlineScore rises as the order deadline approaches.
+func syncDeadlineScore(remaining time.Duration) int {
+	for _, tier := range syncDeadlineTiers {
+		if remaining <= tier.within {
+			return tier.score
+		}
+	}
+	return 0
+}
+
+// syncStaticScoreSQL is syncPriority without the age boost as a SQL
+// expression over episodes e and orders o, so queries can rank and limit in
+// the database. manualExpr is true for a manual trigger. The expression takes
+// the syncDeadlineArgs placeholders.
+func syncStaticScoreSQL(manualExpr string) string {
+	var deadline strings.Builder
+	for _, tier := range syncDeadlineTiers {
+		fmt.Fprintf(&deadline, " WHEN o.deadline <= ? THEN %d", tier.score)
+	}
+	return fmt.Sprintf(`CASE WHEN e.sync_priority_override IS NOT NULL THEN e.sync_priority_override ELSE
+			(CASE o.priority WHEN 'low' THEN %d WHEN 'high' THEN %d WHEN 'urgent' THEN %d ELSE %d END)
+			+ (CASE WHEN o.deadline IS NULL THEN 0%s ELSE 0 END)
+			+ (CASE WHEN %s THEN %d ELSE 0 END)
+		END`,
+		syncOrderPriorityScores["low"], syncOrderPriorityScores["high"], syncOrderPriorityScores["urgent"], syncOrderPriorityScores["normal"],
+		deadline.String(), manualExpr, syncPriorityManualBoost)
+}
+
+// syncDeadlineArgs binds the syncStaticScoreSQL deadline placeholders at now.
+func syncDeadlineArgs(now time.Time) []any {
+	args := make([]any, len(syncDeadlineTiers))
+	for i, tier := range syncDeadlineTiers {
+		args[i] = now.Add(tier.within)
+	}
+	return args
+}
+
+// syncQueueQuery selects every pending sync_logs row with its priority
+// inputs. It takes the syncDeadlineArgs placeholders.
+func syncQueueQuery() string {
+	return `
+		SELECT
+			latest_log.id AS sync_log_id,
+			latest_log.episode_id,
+			e.cloud_synced,
+			latest_log.manual,
+			latest_log.started_at AS queued_at,
+			e.sync_priority_override,
+			o.priority AS order_priority,
+			o.deadline AS order_deadline,
+			` + syncStaticScoreSQL("latest_log.manual = TRUE") + ` AS static_score
+		FROM sync_logs latest_log
+		INNER JOIN (
+		  SELECT episode_id, MAX(id) AS latest_id
+		  FROM sync_logs
+		  GROUP BY episode_id
+		) latest ON latest_log.episode_id = latest.episode_id AND latest_log.id = latest.latest_id
+		INNER JOIN episodes e ON e.id = latest_log.episode_id
+		LEFT JOIN tasks t ON t.id = e.task_id AND t.deleted_at IS NULL
+		LEFT JOIN orders o ON o.id = COALESCE(e.order_id, t.order_id) AND o.deleted_at IS NULL
+		WHERE latest_log.status = 'pending'
+		  AND e.deleted_at IS NULL`
+}
+
+// loadSyncQueue returns the next BatchSize pending sync_logs in dispatch
+// order. SQL ranks the queue by static score and queue time; the batch is
+// then re-sorted with the age boost.
+func (w *SyncWorker) loadSyncQueue(ctx context.Context) ([]syncQueueEntry, error) {
+	now := time.Now().UTC()
+	var entries []syncQueueEntry
+	// #nosec G202 -- syncQueueQuery is built from constants; values are placeholder-bound.
+	if err := w.db.SelectContext(ctx, &entries, syncQueueQuery()+`
+		ORDER BY static_score DESC, queued_at ASC, sync_log_id ASC
+		LIMIT ?
+	`, append(syncDeadlineArgs(now), w.cfg.BatchSize)...); err != nil {
+		return nil, fmt.Errorf("query sync queue: %w", err)
+	}
+
+	sortSyncQueue(entries, now)
+
+	w.queueMu.Lock()
+	w.queue, w.queueLoadedAt = entries, time.Now()
+	w.queueMu.Unlock()
+	return entries, nil
+}
+
+// sortSyncQueue scores entries at now and orders them for dispatch.
+func sortSyncQueue(entries []syncQueueEntry, now time.Time) {
+	for i := range entries {
+		entries[i].priority = syncPriority(entries[i], now)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.priority != b.priority {
+			return a.priority > b.priority
+		}
+		if a.QueuedAt.Time.Equal(b.QueuedAt.Time) {
+			return a.SyncLogID < b.SyncLogID
+		}
+		return a.QueuedAt.Time.Before(b.QueuedAt.Time)
+	})
+}
+
+// cachedSyncQueue returns the queue from the last scan while it is younger
+// than one poll interval, or nil.
+func (w *SyncWorker) cachedSyncQueue() []syncQueueEntry {
+	ttl := time.Duration(w.cfg.IntervalSec) * time.Second
+	if ttl <= 0 {
+		ttl = 60 * time.Second
+	}
+	w.queueMu.Lock()
+	defer w.queueMu.Unlock()
+	if w.queue == nil || time.Since(w.queueLoadedAt) >= ttl {
+		return nil
+	}
+	return w.queue
+}
+
+// InvalidateSyncQueue drops the cached queue order, e.g. after a priority
+// override changed, so the next SyncQueuePosition rescans it.
+func (w *SyncWorker) InvalidateSyncQueue() {
+	if w == nil {
+		return
+	}
+	w.queueMu.Lock()
+	w.queue = nil
+	w.queueMu.Unlock()
+}
+
+// SyncQueuePosition returns the 1-based dispatch position and priority score
+// of a pending episode. ok is false when the episode is not queued. Episodes
+// in the last dispatch batch, if it is at most one poll interval old, take
+// their position from it; others are placed by counting the pending rows SQL
+// ranks ahead of them.
+func (w *SyncWorker) SyncQueuePosition(ctx context.Context, episodeID int64) (position int, priority int, ok bool, err error) {
+	if w == nil || w.db == nil {
+		return 0, 0, false, nil
+	}
+	if position, priority, ok := syncQueueIndex(w.cachedSyncQueue(), episodeID); ok {
+		return position, priority, true, nil
+	}
+
+	now := time.Now().UTC()
+	deadlineArgs := syncDeadlineArgs(now)
+	var entry syncQueueEntry
+	// #nosec G202 -- syncQueueQuery is built from constants; values are placeholder-bound.
+	err = w.db.GetContext(ctx, &entry, `SELECT q.* FROM (`+syncQueueQuery()+`) q WHERE q.episode_id = ?`,
+		append(deadlineArgs, episodeID)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, false, nil
+	}
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("query sync queue entry: %w", err)
+	}
+
+	var ahead int
+	// #nosec G202 -- syncQueueQuery is built from constants; values are placeholder-bound.
+	if err := w.db.GetContext(ctx, &ahead, `
+		SELECT COUNT(*) FROM (`+syncQueueQuery()+`) q
+		WHERE q.static_score > ?
+		   OR (q.static_score = ? AND (q.queued_at < ? OR (q.queued_at = ? AND q.sync_log_id < ?)))
+	`, append(deadlineArgs, entry.StaticScore, entry.StaticScore, entry.QueuedAt, entry.QueuedAt, entry.SyncLogID)...); err != nil {
+		return 0, 0, false, fmt.Errorf("count sync queue ahead: %w", err)
+	}
+	return ahead + 1, syncPriority(entry, now), true, nil
+}
+
+func syncQueueIndex(entries []syncQueueEntry, episodeID int64) (position int, priority int, ok bool) {
+	for i, entry := range entries {
+		if entry.EpisodeID == episodeID {
+			return i + 1, entry.priority, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestSyncPriority(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	queued := sql.NullTime{Time: now, Valid: true}
+
+	tests := []struct {
+		name  string
+		entry syncQueueEntry
+		want  int
+	}{
+		{"no order defaults to normal", syncQueueEntry{QueuedAt: queued}, 100},
+		{"urgent order", syncQueueEntry{QueuedAt: queued, OrderPriority: sql.NullString{String: "urgent", Valid: true}}, 300},
+		{"overdue deadline", syncQueueEntry{QueuedAt: queued, OrderPriority: sql.NullString{String: "low", Valid: true}, OrderDeadline: sql.NullTime{Time: now.Add(-time.Minute), Valid: true}}, 300},
+		{"deadline within a day", syncQueueEntry{QueuedAt: queued, OrderDeadline: sql.NullTime{Time: now.Add(6 * time.Hour), Valid: true}}, 300},
+		{"manual trigger", syncQueueEntry{QueuedAt: queued, Manual: true}, 250},
+		{"age adds one per hour", syncQueueEntry{QueuedAt: sql.NullTime{Time: now.Add(-5 * time.Hour), Valid: true}}, 105},
+		{"age boost is capped", syncQueueEntry{QueuedAt: sql.NullTime{Time: now.Add(-30 * 24 * time.Hour), Valid: true}}, 100 + syncPriorityMaxAgeBoost},
+		{"override replaces computed score", syncQueueEntry{QueuedAt: queued, Manual: true, Override: sql.NullInt64{Int64: 5, Valid: true}}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syncPriority(tt.entry, now); got != tt.want {
+				t.Fatalf("syncPriority() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchPendingSyncLogs_DispatchesByPriority(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{
+		db:              db,
+		cfg:             SyncWorkerConfig{BatchSize: 2, MaxRetries: 3},
+		jobCh:           make(chan syncEnqueueRequest, 3),
+		enqueuedEpisode: make(map[int64]struct{}),
+	}
+
+	if _, err := db.Exec(`
+		INSERT INTO orders (id, priority, deadline) VALUES (1, 'low', NULL), (2, 'urgent', ?)
+	`, time.Now().UTC().Add(2*time.Hour)); err != nil {
+		t.Fatalf("insert orders: %v", err)
+	}
+	// Episode 1 has waited longest but belongs to a low-priority order.
+	insertEpisodeForSyncWorkerTest(t, db, 1, "approved", false)
+	insertEpisodeForSyncWorkerTest(t, db, 2, "approved", false)
+	insertEpisodeForSyncWorkerTest(t, db, 3, "approved", false)
+	if _, err := db.Exec(`UPDATE episodes SET order_id = 1 WHERE id = 1`); err != nil {
+		t.Fatalf("assign order: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO tasks (id, order_id) VALUES (30, 2)`); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE episodes SET task_id = 30 WHERE id = 3`); err != nil {
+		t.Fatalf("assign task: %v", err)
+	}
+	for _, id := range []int64{1, 2, 3} {
+		insertSyncLogForSyncWorkerTest(t, db, id, "pending", 0)
+	}
+	if _, err := db.Exec(`UPDATE sync_logs SET manual = TRUE WHERE episode_id = 2`); err != nil {
+		t.Fatalf("mark manual: %v", err)
+	}
+
+	w.dispatchPendingSyncLogs(context.Background())
+
+	first := <-w.jobCh
+	second := <-w.jobCh
+	if first.episodeID != 3 || second.episodeID != 2 || !second.manual {
+		t.Fatalf("dispatch order = %+v, %+v; want urgent episode 3 then manual episode 2", first, second)
+	}
+	select {
+	case got := <-w.jobCh:
+		t.Fatalf("dispatched %+v beyond batch size", got)
+	default:
+	}
+
+	position, priority, ok, err := w.SyncQueuePosition(context.Background(), 1)
+	if err != nil || !ok || position != 3 {
+		t.Fatalf("SyncQueuePosition(1) = %d, %d, %v, %v; want position 3", position, priority, ok, err)
+	}
+}
+
+func TestFindPendingEpisodes_TakesHighestSyncPriorityFirst(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{db: db, cfg: SyncWorkerConfig{BatchSize: 2, MaxRetries: 3}}
+
+	if _, err := db.Exec(`INSERT INTO orders (id, priority, deadline) VALUES (1, 'low', ?)`, time.Now().UTC().Add(time.Hour)); err != nil {
+		t.Fatalf("insert order: %v", err)
+	}
+	for _, id := range []int64{1, 2, 3, 4} {
+		insertEpisodeForSyncWorkerTest(t, db, id, "approved", false)
+	}
+	// Episode 4 is the newest, but its order is due within the hour;
+	// episode 3 has an operator override.
+	if _, err := db.Exec(`UPDATE episodes SET order_id = 1 WHERE id = 4`); err != nil {
+		t.Fatalf("assign order: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE episodes SET sync_priority_override = 900 WHERE id = 3`); err != nil {
+		t.Fatalf("set override: %v", err)
+	}
+
+	ids, err := w.findPendingEpisodes(context.Background(), false)
+	if err != nil {
+		t.Fatalf("findPendingEpisodes: %v", err)
+	}
+	assertEpisodeIDs(t, ids, []int64{3, 4})
+}
+
+func TestSyncQueuePosition_ReusesLastScanUntilInvalidated(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{db: db, cfg: SyncWorkerConfig{BatchSize: 10, MaxRetries: 3, IntervalSec: 60}}
+
+	for _, id := range []int64{1, 2} {
+		insertEpisodeForSyncWorkerTest(t, db, id, "approved", false)
+		insertSyncLogForSyncWorkerTest(t, db, id, "pending", 0)
+	}
+	if _, err := w.loadSyncQueue(context.Background()); err != nil {
+		t.Fatalf("loadSyncQueue: %v", err)
+	}
+
+	// A status read between scans is served from the last scan.
+	if _, err := db.Exec(`UPDATE episodes SET sync_priority_override = 900 WHERE id = 2`); err != nil {
+		t.Fatalf("set override: %v", err)
+	}
+	if position, _, ok, err := w.SyncQueuePosition(context.Background(), 2); err != nil || !ok || position != 2 {
+		t.Fatalf("cached SyncQueuePosition(2) = %d, %v, %v; want 2", position, ok, err)
+	}
+
+	w.InvalidateSyncQueue()
+	position, priority, ok, err := w.SyncQueuePosition(context.Background(), 2)
+	if err != nil || !ok || position != 1 || priority != 900 {
+		t.Fatalf("rescanned SyncQueuePosition(2) = %d, %d, %v, %v; want 1, 900", position, priority, ok, err)
+	}
+	if _, _, ok, err := w.SyncQueuePosition(context.Background(), 3); err != nil || ok {
+		t.Fatalf("SyncQueuePosition(3) = %v, %v; want not queued", ok, err)
+	}
+}
+
+func TestLoadSyncQueue_LimitsToBatchInSQL(t *testing.T) {
+	db := newTestSyncWorkerDB(t)
+	w := &SyncWorker{db: db, cfg: SyncWorkerConfig{BatchSize: 1, MaxRetries: 3, IntervalSec: 60}}
+
+	for _, id := range []int64{1, 2, 3} {
+		insertEpisodeForSyncWorkerTest(t, db, id, "approved", false)
+		insertSyncLogForSyncWorkerTest(t, db, id, "pending", 0)
+	}
+	if _, err := db.Exec(`UPDATE sync_logs SET manual = TRUE WHERE episode_id = 3`); err != nil {
+		t.Fatalf("mark manual: %v", err)
+	}
+
+	entries, err := w.loadSyncQueue(context.Background())
+	if err != nil {
+		t.Fatalf("loadSyncQueue: %v", err)
+	}
+	if len(entries) != 1 || entries[0].EpisodeID != 3 {
+		t.Fatalf("batch = %+v, want only manual episode 3", entries)
+	}
+	// Episodes outside the cached batch are placed by the count query.
+	if position, priority, ok, err := w.SyncQueuePosition(context.Background(), 2); err != nil || !ok || position != 3 || priority < 100 {
+		t.Fatalf("SyncQueuePosition(2) = %d, %d, %v, %v; want 3", position, priority, ok, err)
+	}
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/logger"
+)
+
+// SyncThrottle is the runtime upload throttling configuration of the sync worker.
+type SyncThrottle struct {
+	// BandwidthLimitKBps is the global upload rate limit in KiB/s; 0 is unlimited.
+	BandwidthLimitKBps int64 `json:"bandwidth_limit_kbps"`
+	// Schedule lists the daily windows in which uploads may run; empty allows
+	// uploads at any time.
+	Schedule []config.SyncWindow `json:"schedule"`
+}
+
+// Validate checks the limit and every window.
+func (t SyncThrottle) Validate() error {
+	if err := config.ValidateSyncBandwidthLimit(t.BandwidthLimitKBps); err != nil {
+		return err
+	}
+	for i, window := range t.Schedule {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("schedule[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// syncThrottleFromConfig builds the initial throttle from the environment
+// configuration. An invalid schedule is logged and ignored; Config.Validate
+// rejects it before the worker is created in production.
+func syncThrottleFromConfig(syncCfg *config.SyncConfig) SyncThrottle {
+	if syncCfg == nil {
+		return SyncThrottle{}
+	}
+	throttle := SyncThrottle{BandwidthLimitKBps: syncCfg.BandwidthLimitKBps}
+	schedule, err := config.ParseSyncSchedule(syncCfg.Schedule)
+	if err != nil {
+		logger.Printf("[SYNC-WORKER] Ignoring invalid sync schedule %q: %v", syncCfg.Schedule, err)
+		return throttle
+	}
+	throttle.Schedule = schedule
+	return throttle
+}
+
+// evaluate reports whether uploads are allowed at now and the applicable rate
+// limit in KiB/s. Inside a window without its own limit the global limit
+// applies; outside all windows the global limit still paces parts already in
+// flight.
+func (t SyncThrottle) evaluate(now time.Time) (bool, int64) {
+	if len(t.Schedule) == 0 {
+		return true, t.BandwidthLimitKBps
+	}
+	minute := now.Hour()*60 + now.Minute()
+	for _, window := range t.Schedule {
+		if !syncWindowContains(window, minute) {
+			continue
+		}
+		if window.BandwidthLimitKBps > 0 {
+			return true, window.BandwidthLimitKBps
+		}
+		return true, t.BandwidthLimitKBps
+	}
+	return false, t.BandwidthLimitKBps
+}
+
+// SyncThrottle returns a copy of the current throttling configuration.
+func (w *SyncWorker) SyncThrottle() SyncThrottle {
+	w.throttleMu.RLock()
+	defer w.throttleMu.RUnlock()
+	throttle := w.throttle
+	throttle.Schedule = append([]config.SyncWindow(nil), w.throttle.Schedule...)
+	return throttle
+}
+
+// SetSyncThrottle replaces the throttling configuration. Running uploads pick
+// up the new rate on their next chunk and the new schedule before their next
+// part.
+func (w *SyncWorker) SetSyncThrottle(throttle SyncThrottle) error {
+	if err := throttle.Validate(); err != nil {
+		return err
+	}
+	throttle.Schedule = append([]config.SyncWindow(nil), throttle.Schedule...)
+	w.throttleMu.Lock()
+	w.throttle = throttle
+	w.throttleMu.Unlock()
+	logger.Printf("[SYNC-WORKER] Sync throttle updated: bandwidth_limit_kbps=%d windows=%d",
+		throttle.BandwidthLimitKBps, len(throttle.Schedule))
+	return nil
+}
+
+// SyncWindowStatus reports whether uploads are allowed at now and the
+// effective rate limit in KiB/s (0 = unlimited).
+func (w *SyncWorker) SyncWindowStatus(now time.Time) (bool, int64) {
+	w.throttleMu.RLock()
+	defer w.throttleMu.RUnlock()
+	return w.throttle.evaluate(now)
+}
+
+// bandwidthPolicy also holds in-flight uploads between parts while paused.
+func (w *SyncWorker) bandwidthPolicy(now time.Time) (bool, int64) {
+	allowed, kbps := w.SyncWindowStatus(now)
+	if w.syncMode() == SyncModePaused {
+		allowed = false
+	}
+	return allowed, kbps * 1024
+}
+
+func (w *SyncWorker) syncWindowOpen() bool {
+	allowed, _ := w.SyncWindowStatus(time.Now())
+	return allowed
+}
+
+// deferEnqueuedEpisode drops an enqueue request that arrived while dispatch
+// is blocked by the control mode or the sync windows. Requests are persisted
+// as pending sync_logs first so that polling dispatches them later.
+func (w *SyncWorker) deferEnqueuedEpisode(ctx context.Context, req syncEnqueueRequest) {
+	defer w.unmarkEnqueued(req.episodeID)
+	if req.resync {
+		return
+	}
+	if err := w.persistPendingSyncLog(ctx, req.episodeID, req.manual, false); err != nil && !isSkippablePendingError(err) {
+		logger.Printf("[SYNC-WORKER] Failed to persist deferred sync for episode %d: %v", req.episodeID, err)
+		return
+	}
+	logger.Printf("[SYNC-WORKER] Episode %d deferred: %s", req.episodeID, w.dispatchBlockedReason())
+}
+
+// syncWindowContains reports whether minute (after local midnight) falls in
+// window, wrapping past midnight when the end is not after the start.
+func syncWindowContains(window config.SyncWindow, minute int) bool {
+	start, err := config.ParseClockMinutes(window.Start)
+	if err != nil {
+		return false
+	}
+	end, err := config.ParseClockMinutes(window.End)
+	if err != nil {
+		return false
+	}
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+)
+
+func TestSyncThrottleEvaluate(t *testing.T) {
+	throttle := SyncThrottle{
+		BandwidthLimitKBps: 2048,
+		Schedule: []config.SyncWindow{
+			{Start: "19:00", End: "07:00"},
+			{Start: "12:00", End: "13:00", BandwidthLimitKBps: 256},
+		},
+	}
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 3, 1, hour, minute, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantAllowed bool
+		wantKBps    int64
+	}{
+		{"before midnight in wrapping window", at(23, 30), true, 2048},
+		{"after midnight in wrapping window", at(6, 59), true, 2048},
+		{"window end is exclusive", at(7, 0), false, 2048},
+		{"window with own limit", at(12, 30), true, 256},
+		{"outside all windows", at(15, 0), false, 2048},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, kbps := throttle.evaluate(tt.now)
+			if allowed != tt.wantAllowed || kbps != tt.wantKBps {
+				t.Fatalf("evaluate() = (%v, %d), want (%v, %d)", allowed, kbps, tt.wantAllowed, tt.wantKBps)
+			}
+		})
+	}
+
+	allowed, kbps := SyncThrottle{}.evaluate(at(15, 0))
+	if !allowed || kbps != 0 {
+		t.Fatalf("empty throttle evaluate() = (%v, %d), want (true, 0)", allowed, kbps)
+	}
+}
+
+func TestSetSyncThrottleValidates(t *testing.T) {
+	w := NewSyncWorker(nil, nil, nil, "", SyncWorkerConfig{}, &config.SyncConfig{BandwidthLimitKBps: 1024, Schedule: "19:00-07:00"})
+
+	if got := w.SyncThrottle(); got.BandwidthLimitKBps != 1024 || len(got.Schedule) != 1 {
+		t.Fatalf("initial throttle = %+v", got)
+	}
+	if err := w.SetSyncThrottle(SyncThrottle{BandwidthLimitKBps: 16}); err == nil {
+		t.Fatal("SetSyncThrottle() accepted limit below minimum")
+	}
+	if err := w.SetSyncThrottle(SyncThrottle{Schedule: []config.SyncWindow{{Start: "7am", End: "19:00"}}}); err == nil {
+		t.Fatal("SetSyncThrottle() accepted invalid window")
+	}
+	if err := w.SetSyncThrottle(SyncThrottle{BandwidthLimitKBps: 512}); err != nil {
+		t.Fatalf("SetSyncThrottle() error = %v", err)
+	}
+	allowed, bytesPerSec := w.bandwidthPolicy(time.Now())
+	if !allowed || bytesPerSec != 512*1024 {
+		t.Fatalf("bandwidthPolicy() = (%v, %d), want (true, %d)", allowed, bytesPerSec, 512*1024)
+	}
+}
@@ -48,6 +48,7 @@ type syncEpisodeUploadRow struct {
 	EpisodeUUID             string         `db:"episode_id"`
 	McapPath                string         `db:"mcap_path"`
 	SidecarPath             string         `db:"sidecar_path"`
+	Checksum                sql.NullString `db:"checksum"`
 	CloudSynced             bool           `db:"cloud_synced"`
 	Metadata                sql.NullString `db:"metadata"`
 	WorkstationID           sql.NullInt64  `db:"workstation_id"`
@@ -79,6 +80,19 @@ type SyncWorker struct {
 	minioBucket string
 	cfg         SyncWorkerConfig
 	syncCfg     *config.SyncConfig
+	webhooks    *WebhookDispatcher
+
+	// throttle holds the runtime bandwidth limit and sync windows; bandwidth
+	// is the limiter shared by every uploader the worker creates.
+	throttleMu sync.RWMutex
+	throttle   SyncThrottle
+	bandwidth  *cloud.BandwidthLimiter
+
+	// control is the operator-selected mode (running, paused, draining);
+	// activeUploads counts uploads currently transferring.
+	controlMu     sync.RWMutex
+	control       syncControl
+	activeUploads atomic.Int64
 
 	mu              sync.Mutex
 	enqueuedEpisode map[int64]struct{}
@@ -87,6 +101,12 @@ type SyncWorker struct {
 	progressMu        sync.RWMutex
 	progressByEpisode map[int64]SyncProgressSnapshot
 
+	// queue is the dispatch order from the last scan of pending sync_logs,
+	// kept so status reads do not rescan and sort the whole queue.
+	queueMu       sync.Mutex
+	queue         []syncQueueEntry
+	queueLoadedAt time.Time
+
 	running  atomic.Bool
 	stopping atomic.Bool
 	wg       sync.WaitGroup
@@ -121,17 +141,28 @@ var (
 
 // NewSyncWorker creates a new sync worker. Call Start() to begin background processing.
 func NewSyncWorker(db *sqlx.DB, uploader *cloud.Uploader, minioClient *s3.Client, minioBucket string, cfg SyncWorkerConfig, syncCfg *config.SyncConfig) *SyncWorker {
-	return &SyncWorker{
+	w := &SyncWorker{
 		db:                db,
 		uploader:          uploader,
 		minioClient:       minioClient,
 		minioBucket:       minioBucket,
 		cfg:               cfg,
 		syncCfg:           syncCfg,
+		throttle:          syncThrottleFromConfig(syncCfg),
 		enqueueCh:         make(chan syncEnqueueRequest, 100),
 		enqueuedEpisode:   make(map[int64]struct{}),
 		progressByEpisode: make(map[int64]SyncProgressSnapshot),
 	}
+	w.bandwidth = cloud.NewBandwidthLimiter(w.bandwidthPolicy)
+	return w
+}
+
+// SetWebhookDispatcher enables sync.completed/sync.failed webhook events.
+func (w *SyncWorker) SetWebhookDispatcher(d *WebhookDispatcher) {
+	if w == nil {
+		return
+	}
+	w.webhooks = d
 }
 
 func (w *SyncWorker) setEpisodeProgress(episodeID int64, uploadedBytes int64, totalBytes int64) {
@@ -196,6 +227,8 @@ func (w *SyncWorker) Start() {
 	runCtx := w.runCtx
 	w.mu.Unlock()
 
+	w.restoreSyncState()
+
 	workerCount := max(1, w.cfg.MaxConcurrent)
 	for i := 0; i < workerCount; i++ {
 		w.workersWg.Add(1)
@@ -415,7 +448,7 @@ func (w *SyncWorker) persistPendingSyncLog(ctx context.Context, episodeID int64,
 		LIMIT 1
 	`+lockClause, episodeID)
 	if err == sql.ErrNoRows {
-		if err := insertPendingSyncLog(ctx, tx, episodeID, time.Now().UTC(), 0); err != nil {
+		if err := insertPendingSyncLog(ctx, tx, episodeID, time.Now().UTC(), 0, manual); err != nil {
 			return err
 		}
 		return tx.Commit()
@@ -433,7 +466,7 @@ func (w *SyncWorker) persistPendingSyncLog(ctx context.Context, episodeID int64,
 	case "failed":
 		retryDue := latest.NextRetry.Valid && !latest.NextRetry.Time.After(now)
 		if latest.AttemptCount < w.cfg.MaxRetries && retryDue {
-			if err := promoteFailedSyncLogToPending(ctx, tx, latest.ID, now); err != nil {
+			if err := promoteFailedSyncLogToPending(ctx, tx, latest.ID, now, manual); err != nil {
 				return err
 			}
 			return tx.Commit()
@@ -447,7 +480,7 @@ func (w *SyncWorker) persistPendingSyncLog(ctx context.Context, episodeID int64,
 		if !manual && !retryDue {
 			return fmt.Errorf("%w for episode %d", errSyncRetryBackoffActive, episodeID)
 		}
-		if err := insertPendingSyncLog(ctx, tx, episodeID, now, 0); err != nil {
+		if err := insertPendingSyncLog(ctx, tx, episodeID, now, 0, manual); err != nil {
 			return err
 		}
 		return tx.Commit()
@@ -503,26 +536,27 @@ func (w *SyncWorker) persistResyncSyncLog(ctx context.Context, episodeID int64)
 		return fmt.Errorf("%w for episode %d", ErrSyncAlreadyInProgress, episodeID)
 	}
 
-	if err := insertPendingSyncLog(ctx, tx, episodeID, time.Now().UTC(), 0); err != nil {
+	if err := insertPendingSyncLog(ctx, tx, episodeID, time.Now().UTC(), 0, true); err != nil {
 		return err
 	}
 	return tx.Commit()
 }
 
-func insertPendingSyncLog(ctx context.Context, tx *sqlx.Tx, episodeID int64, queuedAt time.Time, attemptCount int) error {
+func insertPendingSyncLog(ctx context.Context, tx *sqlx.Tx, episodeID int64, queuedAt time.Time, attemptCount int, manual bool) error {
 	if _, err := tx.ExecContext(ctx, `
-		INSERT INTO sync_logs (episode_id, status, attempt_count, started_at)
-		VALUES (?, 'pending', ?, ?)
-	`, episodeID, attemptCount, queuedAt); err != nil {
+		INSERT INTO sync_logs (episode_id, status, attempt_count, manual, started_at)
+		VALUES (?, 'pending', ?, ?, ?)
+	`, episodeID, attemptCount, manual, queuedAt); err != nil {
 		return fmt.Errorf("insert pending sync_log: %w", err)
 	}
 	return nil
 }
 
-func promoteFailedSyncLogToPending(ctx context.Context, tx *sqlx.Tx, syncLogID int64, queuedAt time.Time) error {
+func promoteFailedSyncLogToPending(ctx context.Context, tx *sqlx.Tx, syncLogID int64, queuedAt time.Time, manual bool) error {
 	res, err := tx.ExecContext(ctx, `
 		UPDATE sync_logs
 		SET status = 'pending',
+		    manual = ?,
 		    started_at = ?,
 		    error_message = NULL,
 		    duration_sec = NULL,
@@ -530,7 +564,7 @@ func promoteFailedSyncLogToPending(ctx context.Context, tx *sqlx.Tx, syncLogID i
 		    next_retry_at = NULL
 		WHERE id = ?
 		  AND status = 'failed'
-	`, queuedAt, syncLogID)
+	`, manual, queuedAt, syncLogID)
 	if err != nil {
 		return fmt.Errorf("promote failed sync_log to pending: %w", err)
 	}
@@ -605,6 +639,10 @@ func (w *SyncWorker) run(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case req := <-w.enqueueCh:
+			if !w.dispatchAllowed() {
+				w.deferEnqueuedEpisode(ctx, req)
+				continue
+			}
 			w.dispatchJob(ctx, req)
 		case <-ticker.C:
 			w.pollAndProcess(ctx)
@@ -647,6 +685,11 @@ func (w *SyncWorker) processEnqueuedEpisode(ctx context.Context, req syncEnqueue
 
 func (w *SyncWorker) processEnqueuedEpisodeWith(ctx context.Context, req syncEnqueueRequest, process func(context.Context, int64, bool, bool)) {
 	defer w.unmarkEnqueued(req.episodeID)
+	// Jobs buffered before a pause or drain must not start.
+	if !w.dispatchAllowed() {
+		w.deferEnqueuedEpisode(ctx, req)
+		return
+	}
 	process(ctx, req.episodeID, req.manual, req.resync)
 }
 
@@ -714,17 +757,26 @@ func (w *SyncWorker) finalizeRun() {
 }
 
 func (w *SyncWorker) pollAndProcess(ctx context.Context) {
-	// Recover persisted queued rows first; enqueueCh is only an acceleration path.
-	w.dispatchPendingSyncLogs(ctx)
+	// While paused, draining or outside the sync windows, queued work stays
+	// persisted until dispatch is allowed again.
+	if !w.dispatchAllowed() {
+		return
+	}
 
-	// Then, retry any failed episodes that are due.
+	// Queue failed episodes whose retry is due.
 	w.retryFailedEpisodes(ctx)
 
-	if !w.cfg.AutoScanEnabled {
-		return
+	// Persist newly eligible episodes as queued work.
+	if w.cfg.AutoScanEnabled {
+		w.queuePendingEpisodes(ctx)
 	}
 
-	// Finally, find newly eligible episodes and persist them as queued work.
+	// Dispatch persisted queued rows in priority order; enqueueCh is only an
+	// acceleration path.
+	w.dispatchPendingSyncLogs(ctx)
+}
+
+func (w *SyncWorker) queuePendingEpisodes(ctx context.Context) {
 	ids, err := w.findPendingEpisodes(ctx, false)
 	if err != nil {
 		logger.Printf("[SYNC-WORKER] Failed to find pending episodes: %v", err)
@@ -743,9 +795,7 @@ func (w *SyncWorker) pollAndProcess(ctx context.Context) {
 				continue
 			}
 			logger.Printf("[SYNC-WORKER] Failed to persist pending sync for episode %d: %v", id, err)
-			continue
 		}
-		w.dispatchPersistedJob(ctx, syncEnqueueRequest{episodeID: id, manual: false})
 	}
 }
 
@@ -767,40 +817,35 @@ func (w *SyncWorker) dispatchPersistedJob(ctx context.Context, req syncEnqueueRe
 	w.dispatchJob(ctx, req)
 }
 
+// findPendingSyncLogEpisodes returns the highest-priority batch of queued rows.
 func (w *SyncWorker) findPendingSyncLogEpisodes(ctx context.Context) ([]syncEnqueueRequest, error) {
-	var rows []struct {
-		EpisodeID   int64 `db:"episode_id"`
-		CloudSynced bool  `db:"cloud_synced"`
-	}
-	if err := w.db.SelectContext(ctx, &rows, `
-		SELECT latest_log.episode_id, e.cloud_synced
-		FROM sync_logs latest_log
-		INNER JOIN (
-		  SELECT episode_id, MAX(id) AS latest_id
-		  FROM sync_logs
-		  GROUP BY episode_id
-		) latest ON latest_log.episode_id = latest.episode_id AND latest_log.id = latest.latest_id
-		INNER JOIN episodes e ON e.id = latest_log.episode_id
-		WHERE latest_log.status = 'pending'
-		  AND e.deleted_at IS NULL
-		ORDER BY latest_log.started_at ASC, latest_log.id ASC
-		LIMIT ?
-	`, w.cfg.BatchSize); err != nil {
-		return nil, fmt.Errorf("query pending sync logs: %w", err)
+	entries, err := w.loadSyncQueue(ctx)
+	if err != nil {
+		return nil, err
 	}
-	reqs := make([]syncEnqueueRequest, len(rows))
-	for i, row := range rows {
-		reqs[i] = syncEnqueueRequest{episodeID: row.EpisodeID, resync: row.CloudSynced}
+	reqs := make([]syncEnqueueRequest, len(entries))
+	for i, entry := range entries {
+		reqs[i] = syncEnqueueRequest{episodeID: entry.EpisodeID, manual: entry.Manual, resync: entry.CloudSynced}
 	}
 	return reqs, nil
 }
 
+// findPendingEpisodes returns up to BatchSize approved episodes that are not
+// queued or synced yet, highest sync priority first.
 func (w *SyncWorker) findPendingEpisodes(ctx context.Context, includeExhaustedFailures bool) ([]int64, error) {
-	var ids []int64
-	var err error
+	var entries []syncQueueEntry
 	query := `
-		SELECT e.id
+		SELECT
+			e.id AS episode_id,
+			e.cloud_synced,
+			e.created_at AS queued_at,
+			e.sync_priority_override,
+			o.priority AS order_priority,
+			o.deadline AS order_deadline,
+			%s AS static_score
 		FROM episodes e
+		LEFT JOIN tasks t ON t.id = e.task_id AND t.deleted_at IS NULL
+		LEFT JOIN orders o ON o.id = COALESCE(e.order_id, t.order_id) AND o.deleted_at IS NULL
 		WHERE e.qa_status IN ('approved', 'inspector_approved')
 		  AND e.cloud_synced = FALSE
 		  AND e.deleted_at IS NULL
@@ -821,11 +866,13 @@ func (w *SyncWorker) findPendingEpisodes(ctx context.Context, includeExhaustedFa
 		      AND sl.status IN ('pending', 'in_progress')
 		  )
 		  %s
-		ORDER BY e.created_at ASC
+		ORDER BY static_score DESC, e.created_at ASC, e.id ASC
 		LIMIT ?
 	`
+	now := time.Now().UTC()
+	args := syncDeadlineArgs(now)
 	if !includeExhaustedFailures {
-		query = fmt.Sprintf(query, `
+		query = fmt.Sprintf(query, syncStaticScoreSQL("FALSE"), `
 		  AND NOT EXISTS (
 		    SELECT 1 FROM sync_logs sl
 		    INNER JOIN (
@@ -848,17 +895,27 @@ func (w *SyncWorker) findPendingEpisodes(ctx context.Context, includeExhaustedFa
 		      AND sl.status = 'failed'
 		      AND sl.next_retry_at IS NULL
 		  )`)
-		err = w.db.SelectContext(ctx, &ids, query, w.cfg.MaxRetries, w.cfg.BatchSize)
+		args = append(args, w.cfg.MaxRetries)
 	} else {
-		query = fmt.Sprintf(query, "")
-		err = w.db.SelectContext(ctx, &ids, query, w.cfg.BatchSize)
+		query = fmt.Sprintf(query, syncStaticScoreSQL("FALSE"), "")
 	}
-	if err != nil {
+	// #nosec G201 -- query is built from constants; values are placeholder-bound.
+	if err := w.db.SelectContext(ctx, &entries, query, append(args, w.cfg.BatchSize)...); err != nil {
 		return nil, fmt.Errorf("query pending episodes: %w", err)
 	}
+
+	// SQL takes the batch by static score; within it episodes are queued in
+	// dispatch order, aged from their creation.
+	sortSyncQueue(entries, now)
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.EpisodeID
+	}
 	return ids, nil
 }
 
+// retryFailedEpisodes promotes failed episodes whose retry is due back to
+// pending; dispatchPendingSyncLogs sends them in priority order.
 func (w *SyncWorker) retryFailedEpisodes(ctx context.Context) {
 	var rows []struct {
 		EpisodeID   int64 `db:"episode_id"`
@@ -902,9 +959,7 @@ func (w *SyncWorker) retryFailedEpisodes(ctx context.Context) {
 				continue
 			}
 			logger.Printf("[SYNC-WORKER] Failed to queue retry for episode %d: %v", row.EpisodeID, err)
-			continue
 		}
-		w.dispatchPersistedJob(ctx, syncEnqueueRequest{episodeID: row.EpisodeID, manual: false, resync: row.CloudSynced})
 	}
 }
 
@@ -916,6 +971,7 @@ func (w *SyncWorker) processEpisodeWithMode(ctx context.Context, episodeID int64
 			e.episode_id,
 			e.mcap_path,
 			e.sidecar_path,
+			e.checksum,
 			e.cloud_synced,
 			e.metadata,
 			e.workstation_id,
@@ -964,7 +1020,15 @@ func (w *SyncWorker) processEpisodeWithMode(ctx context.Context, episodeID int64
 
 	startTime := time.Now()
 
-	result, err := w.uploadEpisodeDirect(ctx, ep)
+	w.activeUploads.Add(1)
+	result, err := w.uploadEpisodeDirect(ctx, syncLogID, ep)
+	w.activeUploads.Add(-1)
+	if err != nil && ctx.Err() != nil && w.db != nil {
+		// Stopped mid-upload: keep the row queued for the next start.
+		w.requeueInterruptedSyncLog(syncLogID, episodeID, attemptCount)
+		w.finishEpisodeProgress(episodeID)
+		return
+	}
 	if err != nil {
 		duration := int64(time.Since(startTime).Seconds())
 		w.markSyncFailed(ctx, syncLogID, episodeID, duration, err, attemptCount)
@@ -978,12 +1042,118 @@ func (w *SyncWorker) processEpisodeWithMode(ctx context.Context, episodeID int64
 	w.finishEpisodeProgress(episodeID)
 }
 
-func (w *SyncWorker) uploadEpisodeDirect(ctx context.Context, ep syncEpisodeUploadRow) (*cloud.UploadResult, error) {
+func (w *SyncWorker) uploadEpisodeDirect(ctx context.Context, syncLogID int64, ep syncEpisodeUploadRow) (*syncBundleResult, error) {
 	mcapKey := stripBucketPrefix(ep.McapPath)
 	if mcapKey == "" {
 		return nil, newNonRetryableSyncError("episode %d has empty mcap_path", ep.ID)
 	}
 
+	if w.minioClient == nil {
+		return nil, fmt.Errorf("minio client not available")
+	}
+	annotations, err := loadSidecarAnnotations(ctx, w.db, ep.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.writeAnnotationsAttachment(ctx, mcapKey, ep.EpisodeUUID, annotations); err != nil {
+		return nil, err
+	}
+
+	dest, err := w.newSyncDestination(ctx, ep, mcapKey, annotations)
+	if err != nil {
+		return nil, err
+	}
+	defer dest.close()
+
+	objects, err := w.syncBundleObjects(ctx, mcapKey, normalizeSyncSHA256(ep.Checksum.String), stripBucketPrefix(ep.SidecarPath))
+	if err != nil {
+		return nil, err
+	}
+	bundle := &syncBundleUpload{
+		db:          w.db,
+		syncLogID:   syncLogID,
+		episodeID:   ep.ID,
+		episodeUUID: ep.EpisodeUUID,
+		objects:     objects,
+		manifestKey: syncBundleManifestKey(mcapKey),
+		checksum:    w.minioObjectSHA256,
+		putManifest: w.putMinioObject,
+		upload: func(ctx context.Context, obj syncBundleObject, progress cloud.UploadProgressFunc) (*cloud.UploadResult, error) {
+			return dest.Upload(ctx, cloud.UploadRequest{
+				EpisodeID: ep.EpisodeUUID,
+				McapKey:   obj.SourceKey,
+				AssetID:   dest.assetID,
+				RawTags:   syncBundleRawTags(dest.rawTags, obj),
+				ClientHints: map[string]string{
+					"bundle_role":   obj.Role,
+					"bundle_object": obj.Name,
+				},
+				Progress: progress,
+			})
+		},
+		progress: func(uploadedBytes int64, totalBytes int64) {
+			w.setEpisodeProgress(ep.ID, uploadedBytes, totalBytes)
+		},
+	}
+	return bundle.run(ctx)
+}
+
+// syncDestination is the configured upload target for one episode, with the
+// data-platform asset and raw tags when the target is the data platform.
+type syncDestination struct {
+	cloud.Destination
+	assetID string
+	rawTags map[string]string
+	close   func()
+}
+
+// Destination returns the configured sync destination name.
+func (w *SyncWorker) Destination() string {
+	if w.syncCfg != nil && w.syncCfg.Destination == config.SyncDestinationS3 {
+		return config.SyncDestinationS3
+	}
+	return config.SyncDestinationDataPlatform
+}
+
+// newSyncDestination creates the configured destination. The data platform
+// also receives the annotations as raw tags.
+func (w *SyncWorker) newSyncDestination(ctx context.Context, ep syncEpisodeUploadRow, mcapKey string, annotations []sidecarAnnotation) (*syncDestination, error) {
+	if w.Destination() == config.SyncDestinationS3 {
+		return w.newS3Destination(ep)
+	}
+	return w.newDataPlatformDestination(ctx, ep, mcapKey, annotations)
+}
+
+func (w *SyncWorker) newS3Destination(ep syncEpisodeUploadRow) (*syncDestination, error) {
+	s3Cfg := w.syncCfg.S3
+	bucket, prefix, err := s3Cfg.ResolveTarget(w.syncCfg.FactoryID)
+	if err != nil {
+		return nil, wrapNonRetryableSyncError(err, "resolve KEYSTONE_SYNC_S3_TARGET")
+	}
+	uploader, err := cloud.NewS3Uploader(w.minioClient, w.minioBucket, cloud.S3UploaderConfig{
+		Endpoint:        s3Cfg.Endpoint,
+		Region:          s3Cfg.Region,
+		AccessKeyID:     s3Cfg.AccessKey,
+		SecretAccessKey: s3Cfg.SecretKey,
+		SessionToken:    s3Cfg.SessionToken,
+		UseTLS:          s3Cfg.UseTLS,
+		PathStyle:       s3Cfg.PathStyle,
+		Bucket:          bucket,
+		KeyPrefix:       prefix,
+		PartSizeBytes:   int64(s3Cfg.PartSizeMB) * 1024 * 1024,
+		RequestTimeout:  w.syncOSSTimeout(),
+		Bandwidth:       w.bandwidth,
+	})
+	if err != nil {
+		return nil, wrapNonRetryableSyncError(err, "create s3 uploader")
+	}
+
+	logger.Printf("[SYNC-WORKER] Episode %d s3 sync config resolved: bucket=%s prefix=%q endpoint=%s path_style=%t",
+		ep.ID, bucket, prefix, s3Cfg.Endpoint, s3Cfg.PathStyle)
+	return &syncDestination{Destination: uploader, close: func() {}}, nil
+}
+
+func (w *SyncWorker) newDataPlatformDestination(ctx context.Context, ep syncEpisodeUploadRow, mcapKey string, annotations []sidecarAnnotation) (*syncDestination, error) {
 	assetID, err := resolveAssetIDForEpisode(ctx, w.db, ep.ID, ep.Metadata, ep.WorkstationID)
 	if err != nil {
 		return nil, wrapNonRetryableSyncError(err, "resolve asset_id for episode %d", ep.ID)
@@ -1001,6 +1171,9 @@ func (w *SyncWorker) uploadEpisodeDirect(ctx context.Context, ep syncEpisodeUplo
 	if err != nil {
 		return nil, err
 	}
+	if err := addSidecarAnnotationTags(sidecarTags, annotations); err != nil {
+		return nil, wrapNonRetryableSyncError(err, "export annotations for episode %d", ep.ID)
+	}
 
 	rawTags, err := buildDPDirectRawTags(dpRawTagsInput{
 		Profile:         dpConfig.Profile,
@@ -1028,20 +1201,10 @@ func (w *SyncWorker) uploadEpisodeDirect(ctx context.Context, ep syncEpisodeUplo
 	if err != nil {
 		return nil, fmt.Errorf("create direct uploader for asset_id %s: %w", assetID, err)
 	}
-	defer cleanup()
 
 	logger.Printf("[SYNC-WORKER] Episode %d direct sync config resolved: asset_id=%s auth=%s auth_tls=%t gateway=%s gateway_tls=%t",
 		ep.ID, assetID, dpConfig.Auth.Target, dpConfig.Auth.UseTLS, dpConfig.Gateway.Target, dpConfig.Gateway.UseTLS)
-
-	return uploader.Upload(ctx, cloud.UploadRequest{
-		EpisodeID: ep.EpisodeUUID,
-		McapKey:   mcapKey,
-		AssetID:   assetID,
-		RawTags:   rawTags,
-		Progress: func(uploadedBytes int64, totalBytes int64) {
-			w.setEpisodeProgress(ep.ID, uploadedBytes, totalBytes)
-		},
-	})
+	return &syncDestination{Destination: uploader, assetID: assetID, rawTags: rawTags, close: cleanup}, nil
 }
 
 func (w *SyncWorker) newDirectUploader(dpConfig *DPDeviceUploadConfig) (*cloud.Uploader, func(), error) {
@@ -1075,6 +1238,7 @@ func (w *SyncWorker) newDirectUploader(dpConfig *DPDeviceUploadConfig) (*cloud.U
 		OSSTimeout:      w.syncOSSTimeout(),
 		PersistRootDir:  w.syncPersistRootDir(),
 		MaxRestartCount: uint32(w.syncMaxRestartCount()), //nolint:gosec // non-negative by helper
+		Bandwidth:       w.bandwidth,
 	})
 	if err != nil {
 		cleanup()
@@ -1259,7 +1423,9 @@ func (w *SyncWorker) acquireSyncLogWithMode(ctx context.Context, episodeID int64
 	return id, 1, nil
 }
 
-func (w *SyncWorker) markSyncCompleted(ctx context.Context, syncLogID, episodeID int64, result *cloud.UploadResult, durationSec int64) {
+// markSyncCompleted marks the episode cloud_synced once its whole bundle is
+// uploaded. destination_path and cloud_mcap_path keep pointing at the MCAP.
+func (w *SyncWorker) markSyncCompleted(ctx context.Context, syncLogID, episodeID int64, result *syncBundleResult, durationSec int64) {
 	now := time.Now().UTC()
 
 	tx, err := w.db.BeginTxx(ctx, nil)
@@ -1278,7 +1444,7 @@ func (w *SyncWorker) markSyncCompleted(ctx context.Context, syncLogID, episodeID
 		    duration_sec = ?,
 		    completed_at = ?
 		WHERE id = ?
-	`, result.ObjectKey, result.FileSize, durationSec, now, syncLogID); err != nil {
+	`, result.MCAPObjectKey, result.BytesTransferred, durationSec, now, syncLogID); err != nil {
 		logger.Printf("[SYNC-WORKER] Failed to update sync log %d: %v", syncLogID, err)
 		return
 	}
@@ -1289,20 +1455,33 @@ func (w *SyncWorker) markSyncCompleted(ctx context.Context, syncLogID, episodeID
 		SET cloud_synced = TRUE,
 		    cloud_synced_at = ?,
 		    cloud_mcap_path = ?,
+		    cloud_sidecar_path = ?,
 		    cloud_processed = FALSE
 		WHERE id = ? AND deleted_at IS NULL
-	`, now, result.ObjectKey, episodeID); err != nil {
+	`, now, result.MCAPObjectKey, sql.NullString{String: result.SidecarObjectKey, Valid: result.SidecarObjectKey != ""}, episodeID); err != nil {
 		logger.Printf("[SYNC-WORKER] Failed to update episode %d cloud status: %v", episodeID, err)
 		return
 	}
 
+	w.publishSyncEvent(ctx, tx, WebhookEventSyncCompleted, episodeID, map[string]any{
+		"object_key":        result.MCAPObjectKey,
+		"manifest_key":      result.ManifestKey,
+		"object_count":      result.ObjectCount,
+		"bytes_transferred": result.BytesTransferred,
+		"duration_sec":      durationSec,
+	})
+
 	if err := tx.Commit(); err != nil {
 		logger.Printf("[SYNC-WORKER] Failed to commit sync completion for episode %d: %v", episodeID, err)
 		return
 	}
 
-	logger.Printf("[SYNC-WORKER] Episode %d synced successfully: logical_upload_id=%s upload_id=%s object_key=%s duration=%ds",
-		episodeID, result.LogicalUploadID, result.UploadID, result.ObjectKey, durationSec)
+	if result.MCAP != nil {
+		logger.Printf("[SYNC-WORKER] Episode %d MCAP upload: logical_upload_id=%s upload_id=%s",
+			episodeID, result.MCAP.LogicalUploadID, result.MCAP.UploadID)
+	}
+	logger.Printf("[SYNC-WORKER] Episode %d synced successfully: object_key=%s objects=%d bytes=%d duration=%ds",
+		episodeID, result.MCAPObjectKey, result.ObjectCount, result.BytesTransferred, durationSec)
 }
 
 func (w *SyncWorker) markSyncFailed(ctx context.Context, syncLogID, episodeID, durationSec int64, uploadErr error, attemptCount int) {
@@ -1327,6 +1506,17 @@ func (w *SyncWorker) markSyncFailed(ctx context.Context, syncLogID, episodeID, d
 		logger.Printf("[SYNC-WORKER] Failed to update sync log %d as failed: %v", syncLogID, err)
 	}
 
+	failure := map[string]any{
+		"error":         errMsg,
+		"attempt_count": attemptCount,
+		"retryable":     nextRetry.Valid,
+		"duration_sec":  durationSec,
+	}
+	if nextRetry.Valid {
+		failure["next_retry_at"] = nextRetry.Time
+	}
+	w.publishSyncEvent(ctx, w.db, WebhookEventSyncFailed, episodeID, failure)
+
 	if nextRetry.Valid {
 		logger.Printf("[SYNC-WORKER] Episode %d sync failed: %v (attempt=%d, next_retry=%v)",
 			episodeID, uploadErr, attemptCount, nextRetry.Time.Format(time.RFC3339))
@@ -1336,6 +1526,23 @@ func (w *SyncWorker) markSyncFailed(ctx context.Context, syncLogID, episodeID, d
 		episodeID, uploadErr, attemptCount)
 }
 
+// publishSyncEvent queues a sync webhook event for episodeID, adding its
+// identifiers to data. Failures are logged and never fail the sync.
+func (w *SyncWorker) publishSyncEvent(ctx context.Context, q sqlx.ExtContext, eventType string, episodeID int64, data map[string]any) {
+	if w.webhooks == nil {
+		return
+	}
+	var episodeUUID string
+	if err := sqlx.GetContext(ctx, q, &episodeUUID, "SELECT episode_id FROM episodes WHERE id = ?", episodeID); err != nil && err != sql.ErrNoRows {
+		logger.Printf("[SYNC-WORKER] Failed to load episode %d for %s webhook: %v", episodeID, eventType, err)
+	}
+	data["id"] = episodeID
+	data["episode_id"] = episodeUUID
+	if err := w.webhooks.PublishTx(ctx, q, eventType, data); err != nil {
+		logger.Printf("[SYNC-WORKER] Failed to queue %s webhook for episode %d: %v", eventType, episodeID, err)
+	}
+}
+
 func (w *SyncWorker) nextRetryDelay(attemptCount int) time.Duration {
 	baseSec := w.cfg.RetryBaseSec
 	if baseSec <= 0 {
@@ -8,15 +8,29 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"archebase.com/keystone-edge/internal/cloud"
+	"archebase.com/keystone-edge/internal/config"
 	"archebase.com/keystone-edge/internal/logger"
+	"archebase.com/keystone-edge/internal/storage/s3"
 	"github.com/jmoiron/sqlx"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	_ "modernc.org/sqlite"
 )
 
@@ -590,6 +604,7 @@ func TestRetryFailedEpisodes_PromotesDueFailureToPendingBeforeDispatch(t *testin
 	if latest.AttemptCount != 1 {
 		t.Fatalf("latest attempt_count = %d, want completed attempt count 1", latest.AttemptCount)
 	}
+	w.dispatchPendingSyncLogs(context.Background())
 	select {
 	case got := <-w.jobCh:
 		if got.episodeID != 17 {
@@ -638,6 +653,8 @@ func TestRetryFailedEpisodes_IgnoresMissingDeletedAndRetriesSyncedEpisodesAsResy
 		}
 	}
 
+	w.dispatchPendingSyncLogs(context.Background())
+
 	gotSynced := <-w.jobCh
 	if gotSynced.episodeID != 4 || !gotSynced.resync {
 		t.Fatalf("unexpected synced retry dispatch: got %+v want episode 4 resync", gotSynced)
@@ -852,22 +869,30 @@ func TestMarkSyncCompleted_WritesExistingCloudFields(t *testing.T) {
 		t.Fatalf("query sync log id: %v", err)
 	}
 
-	w.markSyncCompleted(context.Background(), syncLogID, 26, &cloud.UploadResult{
-		LogicalUploadID: "logical-26",
-		UploadID:        "upload-26",
-		ObjectKey:       "cloud/object.mcap",
-		FileSize:        12345,
+	w.markSyncCompleted(context.Background(), syncLogID, 26, &syncBundleResult{
+		MCAP: &cloud.UploadResult{
+			LogicalUploadID: "logical-26",
+			UploadID:        "upload-26",
+			ObjectKey:       "cloud/object.mcap",
+			FileSize:        12000,
+		},
+		MCAPObjectKey:    "cloud/object.mcap",
+		SidecarObjectKey: "cloud/object.json",
+		ManifestKey:      "object/manifest.json",
+		BytesTransferred: 12345,
+		ObjectCount:      3,
 	}, 3)
 
 	var ep struct {
-		CloudSynced    bool   `db:"cloud_synced"`
-		CloudMcapPath  string `db:"cloud_mcap_path"`
-		CloudProcessed bool   `db:"cloud_processed"`
+		CloudSynced      bool   `db:"cloud_synced"`
+		CloudMcapPath    string `db:"cloud_mcap_path"`
+		CloudSidecarPath string `db:"cloud_sidecar_path"`
+		CloudProcessed   bool   `db:"cloud_processed"`
 	}
-	if err := db.Get(&ep, "SELECT cloud_synced, cloud_mcap_path, cloud_processed FROM episodes WHERE id = ?", 26); err != nil {
+	if err := db.Get(&ep, "SELECT cloud_synced, cloud_mcap_path, cloud_sidecar_path, cloud_processed FROM episodes WHERE id = ?", 26); err != nil {
 		t.Fatalf("query episode cloud fields: %v", err)
 	}
-	if !ep.CloudSynced || ep.CloudMcapPath != "cloud/object.mcap" || ep.CloudProcessed {
+	if !ep.CloudSynced || ep.CloudMcapPath != "cloud/object.mcap" || ep.CloudSidecarPath != "cloud/object.json" || ep.CloudProcessed {
 		t.Fatalf("episode cloud fields = %+v", ep)
 	}
 
@@ -884,6 +909,259 @@ func TestMarkSyncCompleted_WritesExistingCloudFields(t *testing.T) {
 	}
 }
 
+func TestNewSyncDestination_SelectsS3FromConfig(t *testing.T) {
+	syncCfg := &config.SyncConfig{
+		Destination: config.SyncDestinationS3,
+		FactoryID:   "factory-a",
+		S3: config.SyncS3Config{
+			Target:     "s3://edge-{factory_id}/raw",
+			Region:     "us-east-1",
+			AccessKey:  "AKIA",
+			SecretKey:  "secret",
+			PartSizeMB: 8,
+		},
+	}
+	worker := NewSyncWorker(nil, nil, nil, "edge-factory-a", SyncWorkerConfig{}, syncCfg)
+	if got := worker.Destination(); got != config.SyncDestinationS3 {
+		t.Fatalf("Destination() = %q, want s3", got)
+	}
+
+	dest, err := worker.newSyncDestination(context.Background(), syncEpisodeUploadRow{ID: 1}, "factory-a/dev/task.mcap", nil)
+	if err != nil {
+		t.Fatalf("newSyncDestination() error = %v", err)
+	}
+	defer dest.close()
+	if _, ok := dest.Destination.(*cloud.S3Uploader); !ok {
+		t.Fatalf("destination = %T, want *cloud.S3Uploader", dest.Destination)
+	}
+	if dest.assetID != "" || dest.rawTags != nil {
+		t.Fatalf("s3 destination carries data-platform fields: %+v", dest)
+	}
+
+	syncCfg.S3.Target = "s3://Bad_Bucket/"
+	if _, err := worker.newSyncDestination(context.Background(), syncEpisodeUploadRow{ID: 1}, "k", nil); !isNonRetryableSyncError(err) {
+		t.Fatalf("newSyncDestination() error = %v, want non-retryable target error", err)
+	}
+
+	if got := NewSyncWorker(nil, nil, nil, "", SyncWorkerConfig{}, nil).Destination(); got != config.SyncDestinationDataPlatform {
+		t.Fatalf("default Destination() = %q, want dataplatform", got)
+	}
+}
+
+// fakeObjectStore is an in-memory path-style S3 endpoint serving both the
+// edge MinIO bucket and the S3 sync target: single-object reads and writes,
+// ListObjectsV2 and the multipart calls used by cloud.S3Uploader.
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte // by bucket/key
+	uploads map[string]map[int][]byte
+	nextID  int
+}
+
+func newFakeObjectStore(t *testing.T) (*fakeObjectStore, *httptest.Server) {
+	f := &fakeObjectStore{objects: map[string][]byte{}, uploads: map[string]map[int][]byte{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		body = decodeAWSChunked(body)
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	name := bucket + "/" + key
+	q := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodGet && key == "" && q.Get("list-type") == "2":
+		f.listObjects(w, bucket, q.Get("prefix"))
+	case r.Method == http.MethodGet && q.Has("uploads"):
+		fmt.Fprint(w, `<ListMultipartUploadsResult></ListMultipartUploadsResult>`)
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		f.nextID++
+		id := "upload-" + strconv.Itoa(f.nextID)
+		f.uploads[id] = map[int][]byte{}
+		fmt.Fprintf(w, `<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>`, id)
+	case r.Method == http.MethodPut && q.Has("partNumber"):
+		n, _ := strconv.Atoi(q.Get("partNumber"))
+		f.uploads[q.Get("uploadId")][n] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"part-%d"`, n))
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		parts := f.uploads[q.Get("uploadId")]
+		numbers := make([]int, 0, len(parts))
+		for n := range parts {
+			numbers = append(numbers, n)
+		}
+		sort.Ints(numbers)
+		var object []byte
+		for _, n := range numbers {
+			object = append(object, parts[n]...)
+		}
+		f.objects[name] = object
+		delete(f.uploads, q.Get("uploadId"))
+		fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"final"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodPut:
+		f.objects[name] = body
+		w.Header().Set("ETag", `"put"`)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		data, ok := f.objects[name]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprintf(w, `<Error><Code>NoSuchKey</Code><Key>%s</Key></Error>`, key)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"object"`)
+		w.Header().Set("Last-Modified", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		start, end := 0, len(data)-1
+		if rng := strings.TrimPrefix(r.Header.Get("Range"), "bytes="); rng != "" {
+			from, to, _ := strings.Cut(rng, "-")
+			start, _ = strconv.Atoi(from)
+			end, _ = strconv.Atoi(to)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data[start : end+1])
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked strips the aws-chunked framing minio-go uses for PUTs.
+func decodeAWSChunked(body []byte) []byte {
+	var data []byte
+	for {
+		header, rest, ok := strings.Cut(string(body), "\r\n")
+		if !ok {
+			return data
+		}
+		sizeHex, _, _ := strings.Cut(header, ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			return data
+		}
+		data = append(data, rest[:size]...)
+		body = []byte(strings.TrimPrefix(rest[size:], "\r\n"))
+	}
+}
+
+func (f *fakeObjectStore) listObjects(w http.ResponseWriter, bucket, prefix string) {
+	type content struct {
+		Key          string `xml:"Key"`
+		Size         int    `xml:"Size"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+	}
+	result := struct {
+		XMLName     xml.Name  `xml:"ListBucketResult"`
+		Name        string    `xml:"Name"`
+		Prefix      string    `xml:"Prefix"`
+		IsTruncated bool      `xml:"IsTruncated"`
+		Contents    []content `xml:"Contents"`
+	}{Name: bucket, Prefix: prefix}
+	for name, data := range f.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{Key: key, Size: len(data), LastModified: "2026-01-01T00:00:00.000Z", ETag: `"object"`})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func TestUploadEpisodeDirectSendsAnnotationsToS3(t *testing.T) {
+	store, srv := newFakeObjectStore(t)
+	endpoint, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse fake endpoint: %v", err)
+	}
+	minioClient, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:  credentials.NewStaticV4("edge", "edge-secret", ""),
+		Region: "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("create minio client: %v", err)
+	}
+
+	db := newTestSyncWorkerDB(t)
+	if _, err := db.Exec(`
+		CREATE TABLE episode_annotations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			episode_id INTEGER NOT NULL,
+			start_sec REAL NOT NULL,
+			end_sec REAL NOT NULL,
+			tag TEXT NOT NULL,
+			note TEXT,
+			severity TEXT NOT NULL,
+			deleted_at TIMESTAMP NULL
+		);
+		INSERT INTO episode_annotations (episode_id, start_sec, end_sec, tag, note, severity) VALUES
+			(5, 3, 5, 'blur', 'hand over lens', 'minor');
+	`); err != nil {
+		t.Fatalf("create annotations: %v", err)
+	}
+
+	store.objects["edge/f/d/task.mcap"] = []byte("mcap-bytes")
+	store.objects["edge/f/d/task.json"] = []byte(`{"episode":"e-5"}`)
+	syncCfg := &config.SyncConfig{
+		Destination: config.SyncDestinationS3,
+		FactoryID:   "factory-a",
+		S3: config.SyncS3Config{
+			Target:     "s3://archive/{factory_id}",
+			Endpoint:   srv.URL,
+			Region:     "us-east-1",
+			AccessKey:  "AKTEST",
+			SecretKey:  "secret",
+			PathStyle:  true,
+			PartSizeMB: 5,
+		},
+	}
+	worker := NewSyncWorker(db, nil, &s3.Client{Client: minioClient}, "edge", SyncWorkerConfig{}, syncCfg)
+	ep := syncEpisodeUploadRow{ID: 5, EpisodeUUID: "e-5", McapPath: "edge/f/d/task.mcap", SidecarPath: "edge/f/d/task.json"}
+
+	result, err := worker.uploadEpisodeDirect(context.Background(), 11, ep)
+	if err != nil {
+		t.Fatalf("uploadEpisodeDirect() error = %v", err)
+	}
+	if result.ObjectCount != 4 {
+		t.Fatalf("object count = %d, want mcap, sidecar, annotations and manifest", result.ObjectCount)
+	}
+	var exported sidecarAnnotationsAttachment
+	if err := json.Unmarshal(store.objects["archive/factory-a/f/d/task/attachments/annotations.json"], &exported); err != nil {
+		t.Fatalf("decode uploaded annotations: %v", err)
+	}
+	if exported.EpisodeID != "e-5" || len(exported.Annotations) != 1 || exported.Annotations[0].Tag != "blur" {
+		t.Fatalf("uploaded annotations = %+v", exported)
+	}
+
+	// Once the annotations are deleted, a resync no longer ships them.
+	if _, err := db.Exec(`UPDATE episode_annotations SET deleted_at = CURRENT_TIMESTAMP`); err != nil {
+		t.Fatalf("delete annotations: %v", err)
+	}
+	result, err = worker.uploadEpisodeDirect(context.Background(), 12, ep)
+	if err != nil {
+		t.Fatalf("resync uploadEpisodeDirect() error = %v", err)
+	}
+	if _, ok := store.objects["edge/f/d/task/attachments/annotations.json"]; ok || result.ObjectCount != 3 {
+		t.Fatalf("resync object count = %d, edge attachment present = %t; want 3 and removed", result.ObjectCount, ok)
+	}
+}
+
 func newTestSyncWorkerDB(t *testing.T) *sqlx.DB {
 	t.Helper()
 
@@ -895,14 +1173,29 @@ func newTestSyncWorkerDB(t *testing.T) *sqlx.DB {
 	schema := []string{
 		`CREATE TABLE episodes (
 			id INTEGER PRIMARY KEY,
+			task_id INTEGER NULL,
+			order_id INTEGER NULL,
 			qa_status TEXT NOT NULL,
 			cloud_synced BOOLEAN NOT NULL DEFAULT 0,
 			cloud_synced_at TIMESTAMP NULL,
 			cloud_mcap_path TEXT,
+			cloud_sidecar_path TEXT,
 			cloud_processed BOOLEAN NOT NULL DEFAULT 0,
+			sync_priority_override INTEGER NULL,
 			deleted_at TIMESTAMP NULL,
 			created_at TIMESTAMP NOT NULL
 		)`,
+		`CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY,
+			order_id INTEGER NULL,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE orders (
+			id INTEGER PRIMARY KEY,
+			priority TEXT NOT NULL DEFAULT 'normal',
+			deadline TIMESTAMP NULL,
+			deleted_at TIMESTAMP NULL
+		)`,
 		`CREATE TABLE sync_logs (
 				id INTEGER PRIMARY KEY AUTOINCREMENT,
 				episode_id INTEGER NOT NULL,
@@ -913,10 +1206,28 @@ func newTestSyncWorkerDB(t *testing.T) *sqlx.DB {
 				duration_sec INTEGER,
 				error_message TEXT,
 				attempt_count INTEGER NOT NULL DEFAULT 0,
+				manual BOOLEAN NOT NULL DEFAULT 0,
 				next_retry_at TIMESTAMP NULL,
 				started_at TIMESTAMP NULL,
 			completed_at TIMESTAMP NULL
 		)`,
+		`CREATE TABLE sync_log_objects (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sync_log_id INTEGER NOT NULL,
+			episode_id INTEGER NOT NULL,
+			role TEXT NOT NULL,
+			name TEXT NOT NULL,
+			source_path TEXT NOT NULL,
+			destination_path TEXT,
+			size_bytes INTEGER,
+			sha256 TEXT,
+			etag TEXT,
+			status TEXT NOT NULL DEFAULT 'pending',
+			error_message TEXT,
+			started_at TIMESTAMP NULL,
+			completed_at TIMESTAMP NULL,
+			UNIQUE (sync_log_id, role, name)
+		)`,
 	}
 
 	for _, stmt := range schema {
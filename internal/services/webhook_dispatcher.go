@@ -0,0 +1,759 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"archebase.com/keystone-edge/internal/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// Webhook event types a subscription can filter on.
+const (
+	WebhookEventTaskStatusChanged = "task.status_changed"
+	WebhookEventEpisodeQAResult   = "episode.qa_result"
+	WebhookEventSyncCompleted     = "sync.completed"
+	WebhookEventSyncFailed        = "sync.failed"
+	WebhookEventDeviceOnline      = "device.online"
+	WebhookEventDeviceOffline     = "device.offline"
+)
+
+// WebhookEventTypes lists every event type in a stable order.
+var WebhookEventTypes = []string{
+	WebhookEventTaskStatusChanged,
+	WebhookEventEpisodeQAResult,
+	WebhookEventSyncCompleted,
+	WebhookEventSyncFailed,
+	WebhookEventDeviceOnline,
+	WebhookEventDeviceOffline,
+}
+
+// IsWebhookEventType reports whether eventType is a known webhook event type.
+func IsWebhookEventType(eventType string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Values of webhook_deliveries.status.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// Headers sent with every webhook request.
+const (
+	WebhookEventHeader     = "X-Keystone-Event"
+	WebhookDeliveryHeader  = "X-Keystone-Delivery"
+	WebhookTimestampHeader = "X-Keystone-Timestamp"
+	WebhookSignatureHeader = "X-Keystone-Signature"
+)
+
+const (
+	defaultWebhookInterval     = 5 * time.Second
+	defaultWebhookBatchSize    = 50
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookRetryBase    = 30 * time.Second
+	defaultWebhookRetryMax     = time.Hour
+	webhookTransitionCursor    = "state_transitions"
+	webhookErrorBodyLimit      = 512
+	webhookDeviceEventsBuffer  = 256
+	webhookLastErrorMaxLength  = 1000
+	webhookTransitionRelaySize = 500
+	// webhookTransitionRelayLag is how long a transition may take to commit
+	// after it was recorded. The relay cursor only moves past transitions
+	// older than this, so a lower id committed late is still picked up.
+	webhookTransitionRelayLag = 2 * time.Minute
+)
+
+// episodeQAResultStates are the qa_status values reported as episode.qa_result.
+var episodeQAResultStates = map[string]bool{
+	"approved":           true,
+	"needs_inspection":   true,
+	"inspector_approved": true,
+	"rejected":           true,
+	"failed":             true,
+}
+
+// WebhookEvent is the JSON body POSTed to subscribers.
+type WebhookEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// SignWebhookPayload returns the X-Keystone-Signature value for body:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by
+// the subscription secret.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDispatcher queues webhook events in the webhook_deliveries outbox and
+// delivers them with HMAC signatures, retrying failures with exponential
+// backoff until they are delivered or dead-lettered.
+//
+// Task and episode events are relayed from state_transitions so they are only
+// emitted for committed changes; sync events are queued by the SyncWorker in
+// its own transaction; device events come from the DeviceStateBroker.
+type WebhookDispatcher struct {
+	db          *sqlx.DB
+	client      *http.Client
+	broker      *DeviceStateBroker
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	retryBase   time.Duration
+	retryMax    time.Duration
+
+	// deviceOnline tracks the last reported connection state per device so
+	// only changes are emitted; it is owned by the device event loop.
+	deviceOnline map[string]bool
+
+	// relayLag, relayedThrough and relayed hold the transitions read past the
+	// durable cursor but not yet settled; they are owned by the run loop.
+	relayLag       time.Duration
+	relayedThrough int64
+	relayed        map[int64]bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhookDispatcher creates a WebhookDispatcher. Call Start to begin delivering.
+func NewWebhookDispatcher(db *sqlx.DB, cfg *config.WebhookConfig) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		db:           db,
+		interval:     defaultWebhookInterval,
+		batchSize:    defaultWebhookBatchSize,
+		maxAttempts:  defaultWebhookMaxAttempts,
+		retryBase:    defaultWebhookRetryBase,
+		retryMax:     defaultWebhookRetryMax,
+		deviceOnline: make(map[string]bool),
+		relayLag:     webhookTransitionRelayLag,
+		relayed:      make(map[int64]bool),
+	}
+	timeout := defaultWebhookTimeout
+	if cfg != nil {
+		if cfg.IntervalSec > 0 {
+			d.interval = time.Duration(cfg.IntervalSec) * time.Second
+		}
+		if cfg.BatchSize > 0 {
+			d.batchSize = cfg.BatchSize
+		}
+		if cfg.TimeoutSec > 0 {
+			timeout = time.Duration(cfg.TimeoutSec) * time.Second
+		}
+		if cfg.MaxAttempts > 0 {
+			d.maxAttempts = cfg.MaxAttempts
+		}
+		if cfg.RetryBaseSec > 0 {
+			d.retryBase = time.Duration(cfg.RetryBaseSec) * time.Second
+		}
+		if cfg.RetryMaxSec > 0 {
+			d.retryMax = time.Duration(cfg.RetryMaxSec) * time.Second
+		}
+	}
+	if d.retryMax < d.retryBase {
+		d.retryMax = d.retryBase
+	}
+	d.client = &http.Client{Timeout: timeout}
+	return d
+}
+
+// SetDeviceStateBroker enables device.online/device.offline events. Call before Start.
+func (d *WebhookDispatcher) SetDeviceStateBroker(broker *DeviceStateBroker) {
+	if d == nil {
+		return
+	}
+	d.broker = broker
+}
+
+// Start launches the relay and delivery loop. It is a no-op when already running.
+func (d *WebhookDispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	var deviceEvents <-chan DeviceStateEvent
+	unsubscribe := func() {}
+	if d.broker != nil {
+		deviceEvents, unsubscribe = d.broker.Subscribe(webhookDeviceEventsBuffer)
+	}
+	go d.run(ctx, d.done, deviceEvents, unsubscribe)
+	logger.Printf("[WEBHOOK] Started (interval=%s, batch=%d, max_attempts=%d, retry_base=%s, retry_max=%s)",
+		d.interval, d.batchSize, d.maxAttempts, d.retryBase, d.retryMax)
+}
+
+// Stop ends the loop after the in-flight delivery, or when ctx ends.
+func (d *WebhookDispatcher) Stop(ctx context.Context) error {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("webhook dispatcher stop: %w", ctx.Err())
+	}
+}
+
+func (d *WebhookDispatcher) run(ctx context.Context, done chan struct{}, deviceEvents <-chan DeviceStateEvent, unsubscribe func()) {
+	defer close(done)
+	// Device events are queued on their own goroutine: a delivery pass can
+	// block for batchSize request timeouts, and the broker drops events once
+	// a subscriber's buffer is full.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		d.runDeviceEvents(ctx, deviceEvents)
+	}()
+	defer wg.Wait()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.processOnce(ctx)
+		}
+	}
+}
+
+// runDeviceEvents queues device.online/device.offline events until ctx ends
+// or the broker closes the subscription.
+func (d *WebhookDispatcher) runDeviceEvents(ctx context.Context, deviceEvents <-chan DeviceStateEvent) {
+	if deviceEvents == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-deviceEvents:
+			if !ok {
+				return
+			}
+			d.handleDeviceEvent(ctx, event)
+		}
+	}
+}
+
+// processOnce relays new state transitions into the outbox and attempts due deliveries.
+func (d *WebhookDispatcher) processOnce(ctx context.Context) {
+	if n, err := d.relayStateTransitions(ctx); err != nil && ctx.Err() == nil {
+		logger.Printf("[WEBHOOK] State transition relay failed: %v", err)
+	} else if n > 0 {
+		logger.Printf("[WEBHOOK] Relayed %d state transitions", n)
+	}
+	if err := d.deliverDue(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+		logger.Printf("[WEBHOOK] Delivery pass failed: %v", err)
+	}
+}
+
+// Publish queues eventType for every enabled subscription filtering on it.
+// It is safe to call on a nil dispatcher.
+func (d *WebhookDispatcher) Publish(ctx context.Context, eventType string, data any) error {
+	if d == nil || d.db == nil {
+		return nil
+	}
+	return d.PublishTx(ctx, d.db, eventType, data)
+}
+
+// PublishTx is Publish within the caller's transaction, so the event is only
+// queued if the change it reports commits.
+func (d *WebhookDispatcher) PublishTx(ctx context.Context, q sqlx.ExtContext, eventType string, data any) error {
+	if d == nil || q == nil {
+		return nil
+	}
+	subs, err := loadWebhookSubscriptions(ctx, q)
+	if err != nil {
+		return err
+	}
+	return enqueueWebhookEvent(ctx, q, subs, WebhookEvent{
+		ID:         newWebhookEventID(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+}
+
+type webhookSubscriptionFilter struct {
+	ID         int64
+	EventTypes map[string]bool
+}
+
+func loadWebhookSubscriptions(ctx context.Context, q sqlx.QueryerContext) ([]webhookSubscriptionFilter, error) {
+	var rows []struct {
+		ID         int64  `db:"id"`
+		EventTypes string `db:"event_types"`
+	}
+	if err := sqlx.SelectContext(ctx, q, &rows, `
+		SELECT id, event_types
+		FROM webhook_subscriptions
+		WHERE enabled = TRUE AND deleted_at IS NULL
+	`); err != nil {
+		return nil, fmt.Errorf("load webhook subscriptions: %w", err)
+	}
+	subs := make([]webhookSubscriptionFilter, 0, len(rows))
+	for _, row := range rows {
+		var types []string
+		if err := json.Unmarshal([]byte(row.EventTypes), &types); err != nil {
+			logger.Printf("[WEBHOOK] Subscription %d has invalid event_types: %v", row.ID, err)
+			continue
+		}
+		filter := webhookSubscriptionFilter{ID: row.ID, EventTypes: make(map[string]bool, len(types))}
+		for _, t := range types {
+			filter.EventTypes[t] = true
+		}
+		subs = append(subs, filter)
+	}
+	return subs, nil
+}
+
+// enqueueWebhookEvent queues event for every subscription that wants its
+// type. An event already queued for a subscription is left alone, so the
+// transition relay can safely read a transition again.
+func enqueueWebhookEvent(ctx context.Context, q sqlx.ExecerContext, subs []webhookSubscriptionFilter, event WebhookEvent) error {
+	var payload []byte
+	for _, sub := range subs {
+		if !sub.EventTypes[event.Type] {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("encode webhook event %s: %w", event.Type, err)
+			}
+		}
+		// #nosec G701 -- static SQL with placeholder-bound delivery values.
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at)
+			SELECT s.id, ?, ?, ?, ?, 0, ?
+			FROM webhook_subscriptions s
+			WHERE s.id = ? AND NOT EXISTS (
+				SELECT 1 FROM webhook_deliveries d WHERE d.subscription_id = s.id AND d.event_id = ?
+			)
+		`, event.ID, event.Type, string(payload), WebhookDeliveryPending, event.OccurredAt, sub.ID, event.ID); err != nil {
+			return fmt.Errorf("queue webhook %s for subscription %d: %w", event.Type, sub.ID, err)
+		}
+	}
+	return nil
+}
+
+func newWebhookEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+type webhookTransitionRow struct {
+	ID            int64          `db:"id"`
+	EntityType    string         `db:"entity_type"`
+	EntityID      int64          `db:"entity_id"`
+	FromState     sql.NullString `db:"from_state"`
+	ToState       string         `db:"to_state"`
+	TriggeredBy   string         `db:"triggered_by"`
+	TriggeredByID sql.NullString `db:"triggered_by_id"`
+	OccurredAt    time.Time      `db:"occurred_at"`
+	TaskID        string         `db:"task_public_id"`
+	EpisodeID     string         `db:"episode_public_id"`
+}
+
+// relayStateTransitions turns task and episode state transitions recorded
+// since the cursor into outbox rows. Transition ids are allocated before their
+// transaction commits, so a lower id can become visible after a higher one:
+// the durable cursor only advances past transitions older than relayLag, and
+// the unsettled ones above it are read again each pass. Deliveries are
+// deduplicated on the transition event id, so a transition read again (or
+// after a restart) is queued once. On first run the cursor starts at the
+// newest transition rather than replaying history. It returns the number of
+// transitions relayed for the first time.
+func (d *WebhookDispatcher) relayStateTransitions(ctx context.Context) (int, error) {
+	if d.db == nil {
+		return 0, nil
+	}
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin relay: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var lastID int64
+	err = tx.GetContext(ctx, &lastID, "SELECT last_id FROM webhook_event_cursors WHERE name = ?", webhookTransitionCursor)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := tx.GetContext(ctx, &lastID, "SELECT COALESCE(MAX(id), 0) FROM state_transitions"); err != nil {
+			return 0, fmt.Errorf("read latest state transition: %w", err)
+		}
+		// #nosec G701 -- static SQL with placeholder-bound cursor values.
+		if _, err := tx.ExecContext(ctx, "INSERT INTO webhook_event_cursors (name, last_id) VALUES (?, ?)", webhookTransitionCursor, lastID); err != nil {
+			return 0, fmt.Errorf("create relay cursor: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("commit relay: %w", err)
+		}
+		d.relayedThrough = lastID
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read relay cursor: %w", err)
+	}
+	if d.relayedThrough < lastID {
+		d.relayedThrough = lastID
+	}
+
+	// Unsettled transitions this dispatcher already read, which may now have
+	// late-committed neighbours, then transitions it has not read yet.
+	unsettled, err := readWebhookTransitions(ctx, tx, lastID, d.relayedThrough)
+	if err != nil {
+		return 0, err
+	}
+	fresh, err := readWebhookTransitions(ctx, tx, d.relayedThrough, 0)
+	if err != nil {
+		return 0, err
+	}
+	rows := append(unsettled, fresh...)
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	var subs []webhookSubscriptionFilter
+	var relayed []int64
+	for _, row := range rows {
+		if d.relayed[row.ID] {
+			continue
+		}
+		relayed = append(relayed, row.ID)
+		event, ok := webhookEventFromTransition(row)
+		if !ok {
+			continue
+		}
+		if subs == nil {
+			if subs, err = loadWebhookSubscriptions(ctx, tx); err != nil {
+				return 0, err
+			}
+		}
+		if err := enqueueWebhookEvent(ctx, tx, subs, event); err != nil {
+			return 0, err
+		}
+	}
+
+	// A truncated unsettled read leaves a gap before the fresh rows, so the
+	// cursor must not cross it.
+	settledRows := rows
+	if len(unsettled) == webhookTransitionRelaySize {
+		settledRows = unsettled
+	}
+	cursor := lastID
+	settled := time.Now().UTC().Add(-d.relayLag)
+	for _, row := range settledRows {
+		if row.OccurredAt.After(settled) {
+			break
+		}
+		cursor = row.ID
+	}
+	if cursor != lastID {
+		// #nosec G701 -- static SQL with placeholder-bound cursor values.
+		if _, err := tx.ExecContext(ctx, "UPDATE webhook_event_cursors SET last_id = ?, updated_at = ? WHERE name = ?",
+			cursor, time.Now().UTC(), webhookTransitionCursor); err != nil {
+			return 0, fmt.Errorf("advance relay cursor: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit relay: %w", err)
+	}
+
+	for _, id := range relayed {
+		d.relayed[id] = true
+	}
+	for id := range d.relayed {
+		if id <= cursor {
+			delete(d.relayed, id)
+		}
+	}
+	if last := rows[len(rows)-1].ID; last > d.relayedThrough {
+		d.relayedThrough = last
+	}
+	return len(relayed), nil
+}
+
+// readWebhookTransitions reads up to webhookTransitionRelaySize task and
+// episode transitions with afterID < id <= throughID, or with no upper bound
+// when throughID is 0.
+func readWebhookTransitions(ctx context.Context, tx *sqlx.Tx, afterID, throughID int64) ([]webhookTransitionRow, error) {
+	if throughID != 0 && throughID <= afterID {
+		return nil, nil
+	}
+	upper := throughID
+	if upper == 0 {
+		upper = math.MaxInt64
+	}
+	var rows []webhookTransitionRow
+	if err := tx.SelectContext(ctx, &rows, `
+		SELECT
+			st.id, st.entity_type, st.entity_id, st.from_state, st.to_state,
+			st.triggered_by, st.triggered_by_id, st.occurred_at,
+			COALESCE(t.task_id, '') AS task_public_id,
+			COALESCE(e.episode_id, '') AS episode_public_id
+		FROM state_transitions st
+		LEFT JOIN tasks t ON st.entity_type = 'task' AND t.id = st.entity_id
+		LEFT JOIN episodes e ON st.entity_type = 'episode' AND e.id = st.entity_id
+		WHERE st.id > ? AND st.id <= ? AND st.entity_type IN ('task', 'episode')
+		ORDER BY st.id
+		LIMIT ?
+	`, afterID, upper, webhookTransitionRelaySize); err != nil {
+		return nil, fmt.Errorf("read state transitions: %w", err)
+	}
+	return rows, nil
+}
+
+// webhookEventFromTransition maps a state transition to its webhook event.
+// The event ID is derived from the transition ID so it is stable.
+func webhookEventFromTransition(row webhookTransitionRow) (WebhookEvent, bool) {
+	event := WebhookEvent{ID: fmt.Sprintf("transition-%d", row.ID), OccurredAt: row.OccurredAt.UTC()}
+	data := map[string]any{
+		"triggered_by":    row.TriggeredBy,
+		"triggered_by_id": row.TriggeredByID.String,
+	}
+	switch row.EntityType {
+	case "task":
+		event.Type = WebhookEventTaskStatusChanged
+		data["id"] = row.EntityID
+		data["task_id"] = row.TaskID
+		data["from_status"] = row.FromState.String
+		data["status"] = row.ToState
+	case "episode":
+		if !episodeQAResultStates[row.ToState] {
+			return WebhookEvent{}, false
+		}
+		event.Type = WebhookEventEpisodeQAResult
+		data["id"] = row.EntityID
+		data["episode_id"] = row.EpisodeID
+		data["from_qa_status"] = row.FromState.String
+		data["qa_status"] = row.ToState
+	default:
+		return WebhookEvent{}, false
+	}
+	event.Data = data
+	return event, true
+}
+
+// handleDeviceEvent emits device.online/device.offline when a device's
+// combined recorder and transfer connection state changes.
+func (d *WebhookDispatcher) handleDeviceEvent(ctx context.Context, event DeviceStateEvent) {
+	if eventType, _ := event["type"].(string); eventType != "device_connection" {
+		return
+	}
+	deviceID, _ := event["device_id"].(string)
+	connected, _ := event["connected"].(bool)
+	if deviceID == "" {
+		return
+	}
+	previous, seen := d.deviceOnline[deviceID]
+	d.deviceOnline[deviceID] = connected
+	if previous == connected && seen {
+		return
+	}
+	if !seen && !connected {
+		return
+	}
+	webhookType := WebhookEventDeviceOffline
+	if connected {
+		webhookType = WebhookEventDeviceOnline
+	}
+	data := map[string]any{
+		"device_id":          deviceID,
+		"recorder_connected": event["recorder_connected"],
+		"transfer_connected": event["transfer_connected"],
+	}
+	if err := d.Publish(ctx, webhookType, data); err != nil && ctx.Err() == nil {
+		logger.Printf("[WEBHOOK] Failed to queue %s for device %s: %v", webhookType, deviceID, err)
+	}
+}
+
+type webhookDeliveryRow struct {
+	ID        int64  `db:"id"`
+	EventID   string `db:"event_id"`
+	EventType string `db:"event_type"`
+	Payload   string `db:"payload"`
+	Attempts  int    `db:"attempts"`
+	URL       string `db:"url"`
+	Secret    string `db:"secret"`
+}
+
+// deliverDue attempts up to batchSize pending deliveries whose retry time has
+// come. Deliveries of disabled subscriptions stay pending until re-enabled.
+func (d *WebhookDispatcher) deliverDue(ctx context.Context, now time.Time) error {
+	if d.db == nil {
+		return nil
+	}
+	var due []webhookDeliveryRow
+	if err := d.db.SelectContext(ctx, &due, `
+		SELECT d.id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		  AND s.enabled = TRUE AND s.deleted_at IS NULL
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT ?
+	`, WebhookDeliveryPending, now, d.batchSize); err != nil {
+		return fmt.Errorf("load due deliveries: %w", err)
+	}
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			return nil
+		}
+		d.attemptDelivery(ctx, delivery)
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) attemptDelivery(ctx context.Context, delivery webhookDeliveryRow) {
+	started := time.Now()
+	statusCode, sendErr := d.send(ctx, delivery)
+	finished := time.Now().UTC()
+	attempt := delivery.Attempts + 1
+
+	var statusArg sql.NullInt64
+	if statusCode > 0 {
+		statusArg = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	var errorArg sql.NullString
+	if sendErr != nil {
+		errorArg = sql.NullString{String: truncateWebhookError(sendErr.Error()), Valid: true}
+	}
+
+	// Record the outcome even if Stop cancelled the request mid-flight.
+	dbCtx := context.WithoutCancel(ctx)
+	// #nosec G701 -- static SQL with placeholder-bound attempt values.
+	if _, err := d.db.ExecContext(dbCtx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, delivery.ID, attempt, statusArg, errorArg, time.Since(started).Milliseconds(), finished); err != nil {
+		logger.Printf("[WEBHOOK] Failed to log attempt %d of delivery %d: %v", attempt, delivery.ID, err)
+	}
+
+	var err error
+	switch {
+	case sendErr == nil:
+		// #nosec G701 -- static SQL with placeholder-bound delivery values.
+		_, err = d.db.ExecContext(dbCtx, `
+			UPDATE webhook_deliveries
+			SET status = ?, attempts = ?, last_status_code = ?, last_error = NULL, delivered_at = ?, updated_at = ?
+			WHERE id = ?
+		`, WebhookDeliveryDelivered, attempt, statusArg, finished, finished, delivery.ID)
+	case attempt >= d.maxAttempts:
+		logger.Printf("[WEBHOOK] Delivery %d (%s) dead after %d attempts: %v", delivery.ID, delivery.EventType, attempt, sendErr)
+		// #nosec G701 -- static SQL with placeholder-bound delivery values.
+		_, err = d.db.ExecContext(dbCtx, `
+			UPDATE webhook_deliveries
+			SET status = ?, attempts = ?, last_status_code = ?, last_error = ?, updated_at = ?
+			WHERE id = ?
+		`, WebhookDeliveryDead, attempt, statusArg, errorArg, finished, delivery.ID)
+	default:
+		// #nosec G701 -- static SQL with placeholder-bound delivery values.
+		_, err = d.db.ExecContext(dbCtx, `
+			UPDATE webhook_deliveries
+			SET attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, updated_at = ?
+			WHERE id = ?
+		`, attempt, finished.Add(d.retryDelay(attempt)), statusArg, errorArg, finished, delivery.ID)
+	}
+	if err != nil {
+		logger.Printf("[WEBHOOK] Failed to update delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send POSTs the delivery payload. Any non-2xx response is an error.
+func (d *WebhookDispatcher) send(ctx context.Context, delivery webhookDeliveryRow) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "keystone-edge-webhook")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyLimit))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg := fmt.Sprintf("unexpected status %d", resp.StatusCode)
+		if s := strings.TrimSpace(string(snippet)); s != "" {
+			msg += ": " + s
+		}
+		return resp.StatusCode, errors.New(msg)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay is retryBase doubled for each attempt already made, capped at retryMax.
+func (d *WebhookDispatcher) retryDelay(attempt int) time.Duration {
+	delay := d.retryBase
+	for i := 1; i < attempt && delay < d.retryMax; i++ {
+		delay *= 2
+	}
+	if delay > d.retryMax {
+		delay = d.retryMax
+	}
+	return delay
+}
+
+func truncateWebhookError(msg string) string {
+	if len(msg) <= webhookLastErrorMaxLength {
+		return msg
+	}
+	return msg[:webhookLastErrorMaxLength]
+}
@@ -0,0 +1,412 @@
+// SPDX-FileCopyrightText: 2026 ArcheBase
+//
+// SPDX-License-Identifier: MulanPSL-2.0
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"archebase.com/keystone-edge/internal/config"
+	"github.com/jmoiron/sqlx"
+)
+
+func newTestWebhookDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("open sqlite db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	schema := []string{
+		`CREATE TABLE webhook_subscriptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			event_types TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT 1,
+			created_at TIMESTAMP,
+			updated_at TIMESTAMP,
+			deleted_at TIMESTAMP NULL
+		)`,
+		`CREATE TABLE webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			subscription_id INTEGER NOT NULL,
+			event_id TEXT NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL,
+			last_status_code INTEGER NULL,
+			last_error TEXT,
+			delivered_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (subscription_id, event_id)
+		)`,
+		`CREATE TABLE webhook_delivery_attempts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			delivery_id INTEGER NOT NULL,
+			attempt INTEGER NOT NULL,
+			status_code INTEGER NULL,
+			error TEXT,
+			duration_ms INTEGER NOT NULL,
+			attempted_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE webhook_event_cursors (
+			name TEXT PRIMARY KEY,
+			last_id INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP
+		)`,
+		`CREATE TABLE state_transitions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			from_state TEXT,
+			to_state TEXT NOT NULL,
+			triggered_by TEXT NOT NULL,
+			triggered_by_id TEXT,
+			transition_metadata TEXT,
+			occurred_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE tasks (id INTEGER PRIMARY KEY, task_id TEXT NOT NULL)`,
+		`CREATE TABLE episodes (id INTEGER PRIMARY KEY, episode_id TEXT NOT NULL)`,
+	}
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+	return db
+}
+
+func insertTestWebhookSubscription(t *testing.T, db *sqlx.DB, url, secret, eventTypes string) int64 {
+	t.Helper()
+	res, err := db.Exec("INSERT INTO webhook_subscriptions (name, url, secret, event_types, enabled) VALUES ('test', ?, ?, ?, 1)", url, secret, eventTypes)
+	if err != nil {
+		t.Fatalf("insert subscription: %v", err)
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+type webhookTestReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookTestReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookDispatcherSignsRetriesAndDeadLetters(t *testing.T) {
+	db := newTestWebhookDB(t)
+	ctx := context.Background()
+
+	// The flaky receiver fails once then accepts; the broken one always fails.
+	flaky := &webhookTestReceiver{statuses: []int{http.StatusInternalServerError}}
+	flakySrv := httptest.NewServer(flaky)
+	defer flakySrv.Close()
+	broken := &webhookTestReceiver{statuses: []int{http.StatusBadGateway, http.StatusBadGateway}}
+	brokenSrv := httptest.NewServer(broken)
+	defer brokenSrv.Close()
+
+	flakyID := insertTestWebhookSubscription(t, db, flakySrv.URL, "flaky-secret-0123456789", `["sync.completed","sync.failed"]`)
+	brokenID := insertTestWebhookSubscription(t, db, brokenSrv.URL, "broken-secret-0123456789", `["sync.completed"]`)
+	insertTestWebhookSubscription(t, db, brokenSrv.URL, "other-secret-0123456789", `["device.online"]`)
+
+	d := NewWebhookDispatcher(db, &config.WebhookConfig{MaxAttempts: 2, RetryBaseSec: 60, RetryMaxSec: 600})
+	if err := d.Publish(ctx, WebhookEventSyncCompleted, map[string]any{"episode_id": "ep-1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	var queued int
+	if err := db.Get(&queued, "SELECT COUNT(1) FROM webhook_deliveries"); err != nil || queued != 2 {
+		t.Fatalf("queued = %d, %v; want 2 (filtered by event type)", queued, err)
+	}
+
+	now := time.Now().UTC()
+	if err := d.deliverDue(ctx, now); err != nil {
+		t.Fatalf("first pass: %v", err)
+	}
+	// Nothing is due again until the backoff elapses.
+	if err := d.deliverDue(ctx, now); err != nil {
+		t.Fatalf("early pass: %v", err)
+	}
+	if len(flaky.requests) != 1 || len(broken.requests) != 1 {
+		t.Fatalf("requests before backoff = %d/%d, want 1/1", len(flaky.requests), len(broken.requests))
+	}
+	if err := d.deliverDue(ctx, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("retry pass: %v", err)
+	}
+
+	req, body := flaky.requests[1], flaky.bodies[1]
+	want := SignWebhookPayload("flaky-secret-0123456789", req.Header.Get(WebhookTimestampHeader), body)
+	if got := req.Header.Get(WebhookSignatureHeader); got != want {
+		t.Fatalf("signature = %q, want %q", got, want)
+	}
+	if req.Header.Get(WebhookEventHeader) != WebhookEventSyncCompleted || req.Header.Get(WebhookDeliveryHeader) == "" {
+		t.Fatalf("headers = %v", req.Header)
+	}
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != WebhookEventSyncCompleted || event.ID != req.Header.Get(WebhookDeliveryHeader) {
+		t.Fatalf("event = %+v, %v", event, err)
+	}
+
+	type deliveryState struct {
+		Status   string `db:"status"`
+		Attempts int    `db:"attempts"`
+		LastCode *int64 `db:"last_status_code"`
+	}
+	var flakyState, brokenState deliveryState
+	if err := db.Get(&flakyState, "SELECT status, attempts, last_status_code FROM webhook_deliveries WHERE subscription_id = ?", flakyID); err != nil {
+		t.Fatalf("flaky delivery: %v", err)
+	}
+	if flakyState.Status != WebhookDeliveryDelivered || flakyState.Attempts != 2 || flakyState.LastCode == nil || *flakyState.LastCode != 200 {
+		t.Fatalf("flaky delivery = %+v", flakyState)
+	}
+	if err := db.Get(&brokenState, "SELECT status, attempts, last_status_code FROM webhook_deliveries WHERE subscription_id = ?", brokenID); err != nil {
+		t.Fatalf("broken delivery: %v", err)
+	}
+	if brokenState.Status != WebhookDeliveryDead || brokenState.Attempts != 2 {
+		t.Fatalf("broken delivery = %+v", brokenState)
+	}
+	var attempts int
+	if err := db.Get(&attempts, "SELECT COUNT(1) FROM webhook_delivery_attempts"); err != nil || attempts != 4 {
+		t.Fatalf("attempt log = %d, %v; want 4", attempts, err)
+	}
+	if err := d.deliverDue(ctx, now.Add(time.Hour)); err != nil || len(broken.requests) != 2 {
+		t.Fatalf("dead delivery retried: %d requests, %v", len(broken.requests), err)
+	}
+}
+
+func TestWebhookDispatcherRelaysStateTransitionsOnce(t *testing.T) {
+	db := newTestWebhookDB(t)
+	ctx := context.Background()
+	subID := insertTestWebhookSubscription(t, db, "http://127.0.0.1:1", "relay-secret-0123456789", `["task.status_changed","episode.qa_result"]`)
+	if _, err := db.Exec("INSERT INTO tasks (id, task_id) VALUES (1, 'task-1')"); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO episodes (id, episode_id) VALUES (2, 'ep-2')"); err != nil {
+		t.Fatalf("insert episode: %v", err)
+	}
+	now := time.Now().UTC()
+	insert := func(entity string, id int64, from, to string) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO state_transitions (entity_type, entity_id, from_state, to_state, triggered_by, occurred_at)
+			VALUES (?, ?, ?, ?, 'system', ?)`, entity, id, from, to, now); err != nil {
+			t.Fatalf("insert transition: %v", err)
+		}
+	}
+	insert("task", 1, "pending", "ready")
+
+	d := NewWebhookDispatcher(db, nil)
+	// The first run only positions the cursor; history is not replayed.
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 0 {
+		t.Fatalf("initial relay = %d, %v", n, err)
+	}
+
+	insert("task", 1, "ready", "in_progress")
+	insert("episode", 2, "pending_qa", "qa_running")
+	insert("episode", 2, "qa_running", "approved")
+	insert("batch", 3, "pending", "active")
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 3 {
+		t.Fatalf("relay = %d, %v; want 3 task/episode transitions", n, err)
+	}
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 0 {
+		t.Fatalf("second relay = %d, %v; want 0", n, err)
+	}
+
+	var rows []struct {
+		EventID   string `db:"event_id"`
+		EventType string `db:"event_type"`
+		Payload   string `db:"payload"`
+	}
+	if err := db.Select(&rows, "SELECT event_id, event_type, payload FROM webhook_deliveries WHERE subscription_id = ? ORDER BY id", subID); err != nil {
+		t.Fatalf("query deliveries: %v", err)
+	}
+	if len(rows) != 2 || rows[0].EventType != WebhookEventTaskStatusChanged || rows[1].EventType != WebhookEventEpisodeQAResult || rows[0].EventID != "transition-2" {
+		t.Fatalf("deliveries = %+v", rows)
+	}
+	var event struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(rows[1].Payload), &event); err != nil || event.Data["episode_id"] != "ep-2" || event.Data["qa_status"] != "approved" {
+		t.Fatalf("episode payload = %s, %v", rows[1].Payload, err)
+	}
+}
+
+func TestWebhookDispatcherEmitsDeviceConnectionChanges(t *testing.T) {
+	db := newTestWebhookDB(t)
+	ctx := context.Background()
+	insertTestWebhookSubscription(t, db, "http://127.0.0.1:1", "device-secret-0123456789", `["device.online","device.offline"]`)
+
+	d := NewWebhookDispatcher(db, nil)
+	for _, connected := range []bool{false, true, true, false} {
+		d.handleDeviceEvent(ctx, DeviceStateEvent{"type": "device_connection", "device_id": "dev-1", "connected": connected})
+	}
+	d.handleDeviceEvent(ctx, DeviceStateEvent{"type": "recorder_state", "device_id": "dev-1"})
+
+	var types []string
+	if err := db.Select(&types, "SELECT event_type FROM webhook_deliveries ORDER BY id"); err != nil {
+		t.Fatalf("query deliveries: %v", err)
+	}
+	if len(types) != 2 || types[0] != WebhookEventDeviceOnline || types[1] != WebhookEventDeviceOffline {
+		t.Fatalf("device events = %v", types)
+	}
+}
+
+func TestWebhookDispatcherQueuesDeviceEventsDuringSlowDelivery(t *testing.T) {
+	db := newTestWebhookDB(t)
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		select {
+		case <-release:
+		case <-req.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	insertTestWebhookSubscription(t, db, server.URL, "slow-secret-0123456789", `["device.online","device.offline"]`)
+
+	broker := NewDeviceStateBroker()
+	d := NewWebhookDispatcher(db, nil)
+	d.interval = 10 * time.Millisecond
+	d.SetDeviceStateBroker(broker)
+	d.Start()
+	defer func() { _ = d.Stop(context.Background()) }()
+
+	countDeliveries := func() int {
+		t.Helper()
+		var n int
+		if err := db.Get(&n, "SELECT COUNT(*) FROM webhook_deliveries"); err != nil {
+			t.Fatalf("count deliveries: %v", err)
+		}
+		return n
+	}
+	waitFor := func(cond func() bool) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if cond() {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !waitFor(func() bool {
+		broker.Publish("dev-1", DeviceStateEvent{"type": "device_connection", "connected": true})
+		return countDeliveries() > 0
+	}) {
+		t.Fatal("first device event was not queued")
+	}
+	select {
+	case <-entered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("delivery did not start")
+	}
+
+	// The delivery pass is now blocked on the receiver; device events must
+	// still be queued.
+	broker.Publish("dev-2", DeviceStateEvent{"type": "device_connection", "connected": true})
+	if !waitFor(func() bool { return countDeliveries() == 2 }) {
+		t.Fatalf("deliveries = %d while a delivery was in flight, want 2", countDeliveries())
+	}
+}
+
+func TestWebhookDispatcherRelaysLateCommittedTransitions(t *testing.T) {
+	db := newTestWebhookDB(t)
+	ctx := context.Background()
+	subID := insertTestWebhookSubscription(t, db, "http://127.0.0.1:1", "late-secret-0123456789", `["task.status_changed"]`)
+	if _, err := db.Exec("INSERT INTO tasks (id, task_id) VALUES (1, 'task-1')"); err != nil {
+		t.Fatalf("insert task: %v", err)
+	}
+	insert := func(id int64, to string, at time.Time) {
+		t.Helper()
+		if _, err := db.Exec(`INSERT INTO state_transitions (id, entity_type, entity_id, from_state, to_state, triggered_by, occurred_at)
+			VALUES (?, 'task', 1, 'ready', ?, 'system', ?)`, id, to, at); err != nil {
+			t.Fatalf("insert transition: %v", err)
+		}
+	}
+	cursor := func() int64 {
+		t.Helper()
+		var id int64
+		if err := db.Get(&id, "SELECT last_id FROM webhook_event_cursors WHERE name = ?", webhookTransitionCursor); err != nil {
+			t.Fatalf("read cursor: %v", err)
+		}
+		return id
+	}
+	now := time.Now().UTC()
+	insert(1, "ready", now.Add(-time.Hour))
+
+	d := NewWebhookDispatcher(db, nil)
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 0 {
+		t.Fatalf("initial relay = %d, %v", n, err)
+	}
+
+	// Transition 3 commits before transition 2.
+	insert(3, "in_progress", now)
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 1 {
+		t.Fatalf("relay = %d, %v; want 1", n, err)
+	}
+	insert(2, "paused", now)
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 1 {
+		t.Fatalf("late relay = %d, %v; want 1", n, err)
+	}
+	if got := cursor(); got != 1 {
+		t.Fatalf("cursor = %d, want 1 while transitions are unsettled", got)
+	}
+
+	// Once settled the cursor moves past both without relaying them again.
+	if _, err := db.Exec("UPDATE state_transitions SET occurred_at = ?", now.Add(-time.Hour)); err != nil {
+		t.Fatalf("age transitions: %v", err)
+	}
+	if n, err := d.relayStateTransitions(ctx); err != nil || n != 0 {
+		t.Fatalf("settled relay = %d, %v; want 0", n, err)
+	}
+	if got := cursor(); got != 3 {
+		t.Fatalf("cursor = %d, want 3", got)
+	}
+
+	// A restarted dispatcher reading the same transitions queues nothing new.
+	if _, err := db.Exec("UPDATE webhook_event_cursors SET last_id = 1"); err != nil {
+		t.Fatalf("rewind cursor: %v", err)
+	}
+	if _, err := NewWebhookDispatcher(db, nil).relayStateTransitions(ctx); err != nil {
+		t.Fatalf("restarted relay: %v", err)
+	}
+	var events []string
+	if err := db.Select(&events, "SELECT event_id FROM webhook_deliveries WHERE subscription_id = ? ORDER BY id", subID); err != nil {
+		t.Fatalf("query deliveries: %v", err)
+	}
+	if len(events) != 2 || events[0] != "transition-3" || events[1] != "transition-2" {
+		t.Fatalf("deliveries = %v", events)
+	}
+}
@@ -0,0 +1,5 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+DROP TABLE IF EXISTS qa_profiles;
@@ -0,0 +1,20 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS qa_profiles (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    robot_type_id BIGINT NULL COMMENT 'Bind to a robot type; NULL matches any',
+    scene_id BIGINT NULL COMMENT 'Bind to a scene; NULL matches any',
+    sop_id BIGINT NULL COMMENT 'Bind to a SOP; NULL matches any',
+    checks JSON NOT NULL COMMENT 'Array of {name, weight, params}',
+    auto_approve_threshold DECIMAL(4, 3) NULL COMMENT 'Overrides KEYSTONE_QA_AUTO_APPROVE_THRESHOLD',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_qa_profiles_binding (robot_type_id, scene_id, sop_id),
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
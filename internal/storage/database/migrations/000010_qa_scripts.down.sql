@@ -0,0 +1,10 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+ALTER TABLE qa_checks
+    DROP INDEX idx_script,
+    DROP COLUMN script_id,
+    DROP COLUMN script_version;
+
+DROP TABLE IF EXISTS qa_scripts;
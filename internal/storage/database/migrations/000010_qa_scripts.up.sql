@@ -0,0 +1,30 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS qa_scripts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(64) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    description TEXT,
+    executable VARCHAR(1024) NOT NULL COMMENT 'Path relative to KEYSTONE_QA_SCRIPT_DIR',
+    args JSON DEFAULT NULL COMMENT 'Extra command-line arguments',
+    sha256 CHAR(64) NOT NULL COMMENT 'Executable digest pinned at registration',
+    input_mode ENUM('file', 'url') NOT NULL DEFAULT 'file' COMMENT 'Local temp copy or presigned URL',
+    timeout_sec INT NULL,
+    memory_limit_mb INT NULL,
+    cpu_limit_sec INT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    _name_version_unique VARCHAR(200) GENERATED ALWAYS AS (CONCAT(name, '|', version, '|', IFNULL(deleted_at, ''))) STORED,
+    UNIQUE INDEX idx_name_version_del (_name_version_unique),
+    INDEX idx_name (name),
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE qa_checks
+    ADD COLUMN script_id BIGINT NULL COMMENT 'qa_scripts row for external script checks',
+    ADD COLUMN script_version VARCHAR(50) NULL,
+    ADD INDEX idx_script (script_id);
@@ -0,0 +1,7 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+ALTER TABLE episodes
+    DROP INDEX idx_integrity_checked,
+    DROP COLUMN integrity_checked_at;
@@ -0,0 +1,7 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+ALTER TABLE episodes
+    ADD COLUMN integrity_checked_at TIMESTAMP NULL COMMENT 'Last integrity scrub of the stored MCAP against checksum',
+    ADD INDEX idx_integrity_checked (integrity_checked_at);
@@ -0,0 +1,15 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS inspection_leases (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    episode_id BIGINT NOT NULL,
+    inspector_id BIGINT NOT NULL,
+    claimed_at TIMESTAMP NOT NULL COMMENT 'Start of the review, used for inspections.duration_sec',
+    expires_at TIMESTAMP NOT NULL COMMENT 'Lease is lost after this time unless renewed',
+    renewed_at TIMESTAMP NULL,
+    UNIQUE INDEX idx_episode (episode_id),
+    INDEX idx_inspector_expires (inspector_id, expires_at),
+    INDEX idx_expires (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+DROP TABLE IF EXISTS qa_audits;
+DROP TABLE IF EXISTS qa_audit_policies;
@@ -0,0 +1,39 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS qa_audit_policies (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    factory_id BIGINT NULL COMMENT 'NULL matches every factory',
+    robot_type_id BIGINT NULL COMMENT 'NULL matches every robot type',
+    sample_rate DECIMAL(5, 4) NOT NULL COMMENT 'Fraction of auto-approved episodes sent to second review',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_factory (factory_id),
+    INDEX idx_robot_type (robot_type_id),
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS qa_audits (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    episode_id BIGINT NOT NULL UNIQUE,
+    policy_id BIGINT NULL COMMENT 'NULL when sampled at KEYSTONE_QA_AUDIT_SAMPLE_RATE',
+    factory_id BIGINT NULL,
+    robot_type_id BIGINT NULL,
+    sample_rate DECIMAL(5, 4) NOT NULL COMMENT 'Rate the episode was sampled at, for weighting estimates',
+    qa_score DECIMAL(4, 3),
+    check_results JSON COMMENT 'Snapshot of the approving QA run: [{check_name, passed, score}]',
+    status ENUM('pending', 'agreed', 'disagreed') NOT NULL DEFAULT 'pending',
+    inspector_id BIGINT NULL,
+    decision ENUM('approved', 'rejected') NULL,
+    reason TEXT,
+    failed_tags JSON,
+    sampled_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP NULL,
+    INDEX idx_status_sampled (status, sampled_at),
+    INDEX idx_factory (factory_id),
+    INDEX idx_robot_type (robot_type_id),
+    INDEX idx_inspector (inspector_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
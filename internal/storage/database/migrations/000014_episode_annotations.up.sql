@@ -0,0 +1,20 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS episode_annotations (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    episode_id BIGINT NOT NULL,
+    inspector_id BIGINT NOT NULL,
+    start_sec DECIMAL(10, 3) NOT NULL COMMENT 'Offset from episode start',
+    end_sec DECIMAL(10, 3) NOT NULL COMMENT 'Offset from episode start, exclusive',
+    tag VARCHAR(64) NOT NULL,
+    note TEXT,
+    severity ENUM('info', 'minor', 'major', 'critical') NOT NULL DEFAULT 'minor',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_episode_start (episode_id, start_sec),
+    INDEX idx_inspector (inspector_id),
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,54 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    url VARCHAR(2048) NOT NULL,
+    secret VARCHAR(255) NOT NULL COMMENT 'HMAC-SHA256 key for X-Keystone-Signature',
+    event_types JSON NOT NULL COMMENT 'Subscribed event types, e.g. ["task.status_changed"]',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Outbox: one row per event and subscription, retried until delivered or dead.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    subscription_id BIGINT NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSON NOT NULL,
+    status ENUM('pending', 'delivered', 'dead') NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_status_code INT NULL,
+    last_error TEXT,
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_subscription_event (subscription_id, event_id),
+    INDEX idx_status_next_attempt (status, next_attempt_at),
+    INDEX idx_subscription_created (subscription_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    delivery_id BIGINT NOT NULL,
+    attempt INT NOT NULL,
+    status_code INT NULL COMMENT 'NULL when no response was received',
+    error TEXT,
+    duration_ms INT NOT NULL,
+    attempted_at TIMESTAMP NOT NULL,
+    INDEX idx_delivery (delivery_id, attempt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Last state_transitions id relayed to the outbox, per relay.
+CREATE TABLE IF NOT EXISTS webhook_event_cursors (
+    name VARCHAR(64) PRIMARY KEY,
+    last_id BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
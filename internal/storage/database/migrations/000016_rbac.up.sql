@@ -0,0 +1,49 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- Role-based access control. System roles (admin, data_collector, display)
+-- match the JWT role claim and are seeded by the server on startup; custom
+-- roles are assigned to users, optionally scoped to an organization or factory.
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT 'e.g. order:write; * grants every permission',
+    description VARCHAR(500),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_permission_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500),
+    is_system BOOLEAN NOT NULL DEFAULT FALSE COMMENT 'Built-in role bound to the JWT role claim',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    _role_unique VARCHAR(200) GENERATED ALWAYS AS (CONCAT(IFNULL(name, ''), '|', IFNULL(deleted_at, ''))) STORED,
+    UNIQUE KEY uk_role_name (_role_unique),
+    INDEX idx_deleted (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (role_id, permission_id),
+    INDEX idx_permission (permission_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id VARCHAR(100) NOT NULL COMMENT 'JWT operator_id of the user',
+    role_id BIGINT NOT NULL,
+    scope_type ENUM('global', 'organization', 'factory') NOT NULL DEFAULT 'global',
+    scope_id BIGINT NULL COMMENT 'organizations.id or factories.id; NULL for global',
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    _assignment_unique VARCHAR(300) GENERATED ALWAYS AS (CONCAT(user_id, '|', role_id, '|', scope_type, '|', IFNULL(scope_id, 0))) STORED,
+    UNIQUE KEY uk_user_role_scope (_assignment_unique),
+    INDEX idx_user (user_id),
+    INDEX idx_role (role_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,25 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- API keys for service integrations. Like ws_client_auth_tokens only the
+-- SHA-256 hash of a key is stored; the key itself is shown once on create
+-- and rotate.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    key_prefix VARCHAR(20) NOT NULL COMMENT 'Leading characters of the key, for identification only',
+    key_hash CHAR(64) NOT NULL,
+    key_version VARCHAR(16) NOT NULL DEFAULT 'kak_v1',
+    scopes JSON NOT NULL COMMENT 'Permission names granted to the key',
+    allowed_ips JSON NULL COMMENT 'IP addresses or CIDR ranges; NULL allows any address',
+    expires_at TIMESTAMP NULL,
+    created_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_rotated_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP NULL,
+    UNIQUE INDEX idx_api_key_hash (key_hash),
+    INDEX idx_api_key_revoked (revoked_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
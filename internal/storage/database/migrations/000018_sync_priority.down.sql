@@ -0,0 +1,9 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+ALTER TABLE episodes
+    DROP COLUMN sync_priority_override;
+
+ALTER TABLE sync_logs
+    DROP COLUMN manual;
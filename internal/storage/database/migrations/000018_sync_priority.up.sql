@@ -0,0 +1,12 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- manual records whether a queued sync was triggered by an operator so the
+-- dispatcher can rank it and keep manual semantics after a restart.
+ALTER TABLE sync_logs
+    ADD COLUMN manual BOOLEAN NOT NULL DEFAULT FALSE AFTER attempt_count;
+
+-- sync_priority_override replaces the computed dispatch priority of an episode.
+ALTER TABLE episodes
+    ADD COLUMN sync_priority_override INT NULL AFTER cloud_synced_at;
@@ -0,0 +1,15 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- Operator-selected sync worker mode. A single row (id = 1) survives restarts
+-- so a paused or draining worker stays that way until resumed.
+CREATE TABLE IF NOT EXISTS sync_control (
+    id TINYINT PRIMARY KEY,
+    mode ENUM('running', 'paused', 'draining') NOT NULL DEFAULT 'running',
+    reason VARCHAR(255),
+    updated_by VARCHAR(100),
+    updated_at TIMESTAMP NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO sync_control (id, mode) VALUES (1, 'running');
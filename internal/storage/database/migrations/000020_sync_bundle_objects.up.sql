@@ -0,0 +1,25 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- Per-object status of an episode sync bundle: the MCAP, its sidecar, any
+-- attachments and the manifest listing them. An episode is only marked
+-- cloud_synced once every object of its sync_log is completed.
+CREATE TABLE IF NOT EXISTS sync_log_objects (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    sync_log_id BIGINT NOT NULL,
+    episode_id BIGINT NOT NULL,
+    role ENUM('mcap', 'sidecar', 'attachment', 'manifest') NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    source_path VARCHAR(1024) NOT NULL,
+    destination_path VARCHAR(1024),
+    size_bytes BIGINT,
+    sha256 CHAR(64),
+    etag VARCHAR(255),
+    status ENUM('pending', 'uploading', 'completed', 'failed') NOT NULL DEFAULT 'pending',
+    error_message TEXT,
+    started_at TIMESTAMP NULL,
+    completed_at TIMESTAMP NULL,
+    UNIQUE KEY uk_sync_log_object (sync_log_id, role, name),
+    INDEX idx_sync_log_objects_episode (episode_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,7 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+ALTER TABLE api_logs
+    DROP INDEX idx_client_request_id,
+    DROP COLUMN client_request_id;
@@ -0,0 +1,9 @@
+-- SPDX-FileCopyrightText: 2026 ArcheBase
+--
+-- SPDX-License-Identifier: MulanPSL-2.0
+
+-- request_id is always generated by the server; client_request_id keeps the
+-- caller's X-Request-ID, which clients may reuse, for correlation.
+ALTER TABLE api_logs
+    ADD COLUMN client_request_id VARCHAR(100) NULL AFTER request_id,
+    ADD INDEX idx_client_request_id (client_request_id);